@@ -19,8 +19,8 @@ func attrHandler(a slog.Attr) slog.Attr {
 	return a
 }
 
-func newLogger(attr bool) *log.Logger {
-	h := log.NewCLIHandler(&bytes.Buffer{},
+func newLogger(attr bool, opts ...log.CLIHandlerOption) *log.Logger {
+	h := log.NewCLIHandler(&bytes.Buffer{}, append([]log.CLIHandlerOption{
 		log.WithLevel(slog.LevelDebug),
 		log.WithLabel("APP"),
 		log.WithTime(true),
@@ -28,7 +28,7 @@ func newLogger(attr bool) *log.Logger {
 		log.WithCaller(true),
 		log.WithAttrHandler(attrHandler),
 		log.WithStyle(log.Style1()),
-	)
+	}, opts...)...)
 	if !attr {
 		return log.NewLogger(h)
 	}
@@ -71,6 +71,16 @@ func BenchmarkCLIHandler_Basic_Parallel(b *testing.B) {
 	})
 }
 
+func BenchmarkCLIHandler_Basic_ParallelFormat(b *testing.B) {
+	l := newLogger(true, log.WithParallelFormat(true))
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			l.Info("test message.")
+		}
+	})
+}
+
 func BenchmarkCLIHandler_Attr(b *testing.B) {
 	l := newLogger(false)
 	b.ReportAllocs()
@@ -120,3 +130,42 @@ func BenchmarkCLIHandler_Attr_Parallel(b *testing.B) {
 		}
 	})
 }
+
+func BenchmarkDiscardHandler(b *testing.B) {
+	l := log.NewLogger(log.NewDiscardHandler())
+	b.ReportAllocs()
+	for b.Loop() {
+		l.Info("test message.")
+	}
+}
+
+func BenchmarkDiscardHandler_Formatting(b *testing.B) {
+	l := log.NewLogger(log.NewDiscardHandler(
+		log.WithFormatting(true),
+		log.WithLevel(slog.LevelDebug),
+		log.WithLabel("APP"),
+		log.WithTime(true),
+		log.WithTimeFormat(time.RFC3339),
+		log.WithCaller(true),
+		log.WithAttrHandler(attrHandler),
+		log.WithStyle(log.Style1()),
+	))
+	b.ReportAllocs()
+	for b.Loop() {
+		l.Info("test message.")
+	}
+}
+
+func BenchmarkAsyncHandler_Basic_Parallel(b *testing.B) {
+	h := log.NewAsyncHandler(newLogger(true).Handler())
+	l := log.NewLogger(h)
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			l.Info("test message.")
+		}
+	})
+	if err := h.Close(); err != nil {
+		b.Fatal(err)
+	}
+}
@@ -39,6 +39,7 @@ func main() {
 	s2 := log.Style2()
 	s3 := log.Style3()
 	s4 := log.Style4()
+	s5 := log.Style5()
 
 	println()
 
@@ -152,7 +153,29 @@ func main() {
 	l.Error(errMsg)
 	println()
 
-	// Style5: original logging style
+	// Style5: logging style with icons in place of the level texts.
+	h = log.NewCLIHandler(os.Stdout,
+		withLevel,
+		log.WithLabel("ICONS:"),
+		withTime,
+		withTimeFormat,
+		withCaller,
+		withAttrHandler,
+		log.WithStyle(s5),
+	)
+	h = h.WithGroup("style5").WithAttrs(
+		[]slog.Attr{
+			slog.String("version", "1.0.0"),
+			slog.String("password", "p@ssw0rd"),
+		})
+	l = log.NewLogger(h)
+	l.Debug(dbgMsg)
+	l.Info(infMsg)
+	l.Warn(wrnMsg)
+	l.Error(errMsg)
+	println()
+
+	// Custom: original logging style
 	s := log.NewStyle(
 		log.WithLevelStyle(map[slog.Level]log.LevelStyle{
 			slog.LevelDebug: {
@@ -241,7 +264,7 @@ func main() {
 		withAttrHandler,
 		log.WithStyle(s),
 	)
-	h = h.WithGroup("style5").WithAttrs(
+	h = h.WithGroup("custom").WithAttrs(
 		[]slog.Attr{
 			slog.String("version", "1.0.0"),
 			slog.String("password", "p@ssw0rd"),
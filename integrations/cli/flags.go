@@ -0,0 +1,84 @@
+package cli
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/nekrassov01/logger/log"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// Names of the flags registered by RegisterFlags.
+const (
+	FlagLevel   = "log-level"
+	FlagFormat  = "log-format"
+	FlagNoColor = "no-color"
+	FlagQuiet   = "quiet"
+	FlagVerbose = "verbose"
+)
+
+// RegisterFlags registers the logging flags as persistent flags of cmd, so that
+// they are accepted by cmd and all of its subcommands:
+//
+//	--log-level   the minimum level, e.g. debug or warn+2 (default info)
+//	--log-format  cli, json or logfmt (default cli)
+//	--no-color    disable colors
+//	-q, --quiet   log errors only
+//	-v, --verbose log at debug, or at trace if repeated
+func RegisterFlags(cmd *cobra.Command) {
+	fs := cmd.PersistentFlags()
+	fs.Var(log.NewLevelFlag(slog.LevelInfo), FlagLevel, "minimum log level: trace, debug, info, notice, warn, error or fatal")
+	fs.String(FlagFormat, log.FormatCLI.String(), "log format: cli, json or logfmt")
+	fs.Bool(FlagNoColor, false, "disable colors in log output")
+	fs.BoolP(FlagQuiet, "q", false, "log errors only")
+	fs.CountP(FlagVerbose, "v", "log at debug, or at trace if repeated")
+}
+
+// BuildLogger creates a new logger writing to the error output of cmd, configured
+// from the values of the flags registered by RegisterFlags. The options are applied
+// before the flags that are set, which take precedence; --log-level takes precedence
+// over --quiet and --verbose. Call it from the Run or PersistentPreRun function of
+// cmd, once the flags are parsed.
+func BuildLogger(cmd *cobra.Command, opts ...log.CLIHandlerOption) (*log.Logger, error) {
+	flagOpts, err := options(cmd.Flags())
+	if err != nil {
+		return nil, err
+	}
+	return log.NewLogger(log.New(cmd.ErrOrStderr(), append(opts, flagOpts...)...)), nil
+}
+
+// options returns the handler options of the flags set in fs. Flags that are
+// not set or not registered are ignored.
+func options(fs *pflag.FlagSet) ([]log.CLIHandlerOption, error) {
+	var opts []log.CLIHandlerOption
+	quiet, _ := fs.GetBool(FlagQuiet)
+	verbose, _ := fs.GetCount(FlagVerbose)
+	if quiet && verbose > 0 {
+		return nil, fmt.Errorf("--%s and --%s cannot be used together", FlagQuiet, FlagVerbose)
+	}
+	switch {
+	case quiet:
+		opts = append(opts, log.WithVerbosity(-1))
+	case verbose > 0:
+		opts = append(opts, log.WithVerbosity(verbose))
+	}
+	if f := fs.Lookup(FlagLevel); f != nil && f.Changed {
+		level, err := log.ParseLevel(f.Value.String())
+		if err != nil {
+			return nil, fmt.Errorf("invalid --%s: %w", FlagLevel, err)
+		}
+		opts = append(opts, log.WithLevel(level))
+	}
+	if f := fs.Lookup(FlagFormat); f != nil && f.Changed {
+		format, err := log.ParseFormat(f.Value.String())
+		if err != nil {
+			return nil, fmt.Errorf("invalid --%s %q: %w", FlagFormat, f.Value, err)
+		}
+		opts = append(opts, log.WithFormat(format))
+	}
+	if noColor, _ := fs.GetBool(FlagNoColor); noColor {
+		opts = append(opts, log.WithColorMode(log.ColorNever))
+	}
+	return opts, nil
+}
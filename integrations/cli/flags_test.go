@@ -0,0 +1,153 @@
+package cli
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/nekrassov01/logger/log"
+	"github.com/spf13/cobra"
+)
+
+// run executes a command with the logging flags and the given arguments, logging
+// a record at each level from its subcommand, and returns the log output.
+func run(t *testing.T, args []string, opts ...log.CLIHandlerOption) (string, error) {
+	t.Helper()
+	buf := &bytes.Buffer{}
+	root := &cobra.Command{Use: "app", SilenceUsage: true, SilenceErrors: true}
+	RegisterFlags(root)
+	sub := &cobra.Command{
+		Use: "sub",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			l, err := BuildLogger(cmd, opts...)
+			if err != nil {
+				return err
+			}
+			l.Trace("trace")
+			l.Debug("debug")
+			l.Info("info")
+			l.Error("error")
+			return nil
+		},
+	}
+	root.AddCommand(sub)
+	root.SetOut(io.Discard)
+	root.SetErr(buf)
+	root.SetArgs(append([]string{"sub"}, args...))
+	err := root.Execute()
+	return buf.String(), err
+}
+
+func TestBuildLogger(t *testing.T) {
+	style := log.WithStyle(log.Style0())
+	tests := []struct {
+		name string
+		args []string
+		opts []log.CLIHandlerOption
+		want string
+	}{
+		{
+			name: "default",
+			opts: []log.CLIHandlerOption{style},
+			want: "[INF] info\n[ERR] error\n",
+		},
+		{
+			name: "level",
+			args: []string{"--log-level", "debug"},
+			opts: []log.CLIHandlerOption{style},
+			want: "[DBG] debug\n[INF] info\n[ERR] error\n",
+		},
+		{
+			name: "quiet",
+			args: []string{"-q"},
+			opts: []log.CLIHandlerOption{style},
+			want: "[ERR] error\n",
+		},
+		{
+			name: "verbose",
+			args: []string{"--verbose"},
+			opts: []log.CLIHandlerOption{style},
+			want: "[DBG] debug\n[INF] info\n[ERR] error\n",
+		},
+		{
+			name: "very verbose",
+			args: []string{"-vv"},
+			opts: []log.CLIHandlerOption{style},
+			want: "[TRC] trace\n[DBG] debug\n[INF] info\n[ERR] error\n",
+		},
+		{
+			name: "level over verbose",
+			args: []string{"-v", "--log-level", "error"},
+			opts: []log.CLIHandlerOption{style},
+			want: "[ERR] error\n",
+		},
+		{
+			name: "flags over options",
+			args: []string{"-q"},
+			opts: []log.CLIHandlerOption{style, log.WithLevel(log.LevelTrace)},
+			want: "[ERR] error\n",
+		},
+		{
+			name: "options without flags",
+			opts: []log.CLIHandlerOption{style, log.WithLevel(log.LevelTrace)},
+			want: "[TRC] trace\n[DBG] debug\n[INF] info\n[ERR] error\n",
+		},
+		{
+			name: "no color",
+			args: []string{"--no-color"},
+			opts: []log.CLIHandlerOption{log.WithColorMode(log.ColorAlways)},
+			want: "INF info\nERR error\n",
+		},
+		{
+			name: "format",
+			args: []string{"--log-format", "json", "-q"},
+			want: `"level":"ERROR","msg":"error"}` + "\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := run(t, tt.args, tt.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.HasSuffix(got, tt.want) || strings.Count(got, "\n") != strings.Count(tt.want, "\n") {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildLogger_error(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{name: "level", args: []string{"--log-level", "verbose"}, want: "log-level"},
+		{name: "format", args: []string{"--log-format", "xml"}, want: "--log-format"},
+		{name: "quiet and verbose", args: []string{"-q", "-v"}, want: "cannot be used together"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := run(t, tt.args)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("err = %v, want it to contain %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildLogger_unregistered(t *testing.T) {
+	buf := &bytes.Buffer{}
+	cmd := &cobra.Command{Use: "app"}
+	cmd.SetErr(buf)
+	l, err := BuildLogger(cmd, log.WithStyle(log.Style0()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.Info("msg")
+	if got := buf.String(); got != "[INF] msg\n" {
+		t.Errorf("got %q, want %q", got, "[INF] msg\n")
+	}
+}
@@ -0,0 +1,215 @@
+package cloudwatch
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+)
+
+// Error codes returned by the CloudWatch Logs API that the Handler acts on.
+const (
+	CodeResourceNotFound      = "ResourceNotFoundException"
+	CodeResourceAlreadyExists = "ResourceAlreadyExistsException"
+	CodeInvalidSequenceToken  = "InvalidSequenceTokenException"
+	CodeDataAlreadyAccepted   = "DataAlreadyAcceptedException"
+	CodeThrottling            = "ThrottlingException"
+	CodeServiceUnavailable    = "ServiceUnavailableException"
+)
+
+// Client is the subset of the CloudWatch Logs API used by a Handler.
+type Client interface {
+	CreateLogGroup(ctx context.Context, group string) error
+	CreateLogStream(ctx context.Context, group, stream string) error
+	PutLogEvents(ctx context.Context, in *PutLogEventsInput) (*PutLogEventsOutput, error)
+}
+
+// InputLogEvent is a log event sent to CloudWatch Logs.
+type InputLogEvent struct {
+	Timestamp int64  `json:"timestamp"`
+	Message   string `json:"message"`
+}
+
+// PutLogEventsInput is the input of Client.PutLogEvents.
+type PutLogEventsInput struct {
+	LogGroupName  string          `json:"logGroupName"`
+	LogStreamName string          `json:"logStreamName"`
+	LogEvents     []InputLogEvent `json:"logEvents"`
+	SequenceToken string          `json:"sequenceToken,omitempty"`
+}
+
+// PutLogEventsOutput is the output of Client.PutLogEvents.
+type PutLogEventsOutput struct {
+	NextSequenceToken     string                 `json:"nextSequenceToken,omitempty"`
+	RejectedLogEventsInfo *RejectedLogEventsInfo `json:"rejectedLogEventsInfo,omitempty"`
+}
+
+// RejectedLogEventsInfo describes the events rejected by CloudWatch Logs.
+type RejectedLogEventsInfo struct {
+	ExpiredLogEventEndIndex  *int `json:"expiredLogEventEndIndex,omitempty"`
+	TooNewLogEventStartIndex *int `json:"tooNewLogEventStartIndex,omitempty"`
+	TooOldLogEventEndIndex   *int `json:"tooOldLogEventEndIndex,omitempty"`
+}
+
+// APIError is an error returned by the CloudWatch Logs API.
+type APIError struct {
+	Code                  string
+	Message               string
+	ExpectedSequenceToken string
+}
+
+// Error returns the error code and message.
+func (e *APIError) Error() string {
+	return fmt.Sprintf("cloudwatch: %s: %s", e.Code, e.Message)
+}
+
+// errorCode returns the code of the APIError in err's tree.
+func errorCode(err error) string {
+	var e *APIError
+	if errors.As(err, &e) {
+		return e.Code
+	}
+	return ""
+}
+
+// client is a Client calling the CloudWatch Logs JSON API. Requests are signed
+// by the SigV4 signer of the SDK; only the three JSON protocol calls are written
+// here, so that the package depends on the SDK core rather than on the
+// cloudwatchlogs service module. A client of that module can be used instead
+// through a small adapter implementing Client.
+type client struct {
+	cfg      aws.Config
+	endpoint string
+	signer   *v4.Signer
+	http     aws.HTTPClient
+	now      func() time.Time
+}
+
+// NewClient creates a new Client from the AWS configuration, typically loaded
+// with config.LoadDefaultConfig. Config.BaseEndpoint overrides the regional endpoint.
+func NewClient(cfg aws.Config) Client {
+	endpoint := fmt.Sprintf("https://logs.%s.amazonaws.com", cfg.Region)
+	if cfg.BaseEndpoint != nil {
+		endpoint = *cfg.BaseEndpoint
+	}
+	hc := cfg.HTTPClient
+	if hc == nil {
+		hc = http.DefaultClient
+	}
+	return &client{
+		cfg:      cfg,
+		endpoint: strings.TrimSuffix(endpoint, "/"),
+		signer:   v4.NewSigner(),
+		http:     hc,
+		now:      time.Now,
+	}
+}
+
+// CreateLogGroup creates a log group.
+func (c *client) CreateLogGroup(ctx context.Context, group string) error {
+	in := struct {
+		LogGroupName string `json:"logGroupName"`
+	}{group}
+	return c.call(ctx, "CreateLogGroup", in, nil)
+}
+
+// CreateLogStream creates a log stream in a log group.
+func (c *client) CreateLogStream(ctx context.Context, group, stream string) error {
+	in := struct {
+		LogGroupName  string `json:"logGroupName"`
+		LogStreamName string `json:"logStreamName"`
+	}{group, stream}
+	return c.call(ctx, "CreateLogStream", in, nil)
+}
+
+// PutLogEvents uploads a batch of log events to a log stream.
+func (c *client) PutLogEvents(ctx context.Context, in *PutLogEventsInput) (*PutLogEventsOutput, error) {
+	out := &PutLogEventsOutput{}
+	if err := c.call(ctx, "PutLogEvents", in, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// call sends a signed request for the operation and decodes the response into out.
+func (c *client) call(ctx context.Context, op string, in, out any) error {
+	body, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint+"/", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "Logs_20140328."+op)
+	if err := c.sign(ctx, req, body, "logs"); err != nil {
+		return err
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode >= 300 {
+		return decodeError(resp.StatusCode, b)
+	}
+	if out == nil || len(b) == 0 {
+		return nil
+	}
+	return json.Unmarshal(b, out)
+}
+
+// sign signs the request with the body for the service using SigV4 and the
+// credentials of the configuration.
+func (c *client) sign(ctx context.Context, req *http.Request, body []byte, service string) error {
+	if c.cfg.Credentials == nil {
+		return errors.New("cloudwatch: no credentials provider")
+	}
+	creds, err := c.cfg.Credentials.Retrieve(ctx)
+	if err != nil {
+		return err
+	}
+	sum := sha256.Sum256(body)
+	return c.signer.SignHTTP(ctx, creds, req, hex.EncodeToString(sum[:]), service, c.cfg.Region, c.now())
+}
+
+// decodeError decodes a JSON protocol error response.
+func decodeError(status int, b []byte) error {
+	var v struct {
+		Type                  string `json:"__type"`
+		Message               string `json:"message"`
+		MessageUpper          string `json:"Message"`
+		ExpectedSequenceToken string `json:"expectedSequenceToken"`
+	}
+	_ = json.Unmarshal(b, &v)
+	e := &APIError{
+		Code:                  v.Type,
+		Message:               v.Message,
+		ExpectedSequenceToken: v.ExpectedSequenceToken,
+	}
+	if i := strings.LastIndexByte(e.Code, '#'); i >= 0 {
+		e.Code = e.Code[i+1:]
+	}
+	if e.Code == "" {
+		e.Code = http.StatusText(status)
+	}
+	if e.Message == "" {
+		e.Message = v.MessageUpper
+	}
+	return e
+}
@@ -0,0 +1,157 @@
+package cloudwatch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+)
+
+func testConfig(url string) aws.Config {
+	return aws.Config{
+		Region:       "ap-northeast-1",
+		BaseEndpoint: aws.String(url),
+		Credentials: aws.CredentialsProviderFunc(func(context.Context) (aws.Credentials, error) {
+			return aws.Credentials{AccessKeyID: "AKID", SecretAccessKey: "SECRET"}, nil
+		}),
+	}
+}
+
+func TestClient_PutLogEvents(t *testing.T) {
+	var target, auth string
+	var body map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		target = r.Header.Get("X-Amz-Target")
+		auth = r.Header.Get("Authorization")
+		b, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(b, &body)
+		w.Write([]byte(`{"nextSequenceToken":"next"}`))
+	}))
+	defer srv.Close()
+
+	c := NewClient(testConfig(srv.URL))
+	out, err := c.PutLogEvents(context.Background(), &PutLogEventsInput{
+		LogGroupName:  "g",
+		LogStreamName: "s",
+		LogEvents:     []InputLogEvent{{Timestamp: 1, Message: "m"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.NextSequenceToken != "next" {
+		t.Errorf("NextSequenceToken = %q, want %q", out.NextSequenceToken, "next")
+	}
+	if target != "Logs_20140328.PutLogEvents" {
+		t.Errorf("X-Amz-Target = %q", target)
+	}
+	if !strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=AKID/") || !strings.Contains(auth, "/ap-northeast-1/logs/aws4_request") {
+		t.Errorf("Authorization = %q", auth)
+	}
+	if body["logGroupName"] != "g" || body["logStreamName"] != "s" {
+		t.Errorf("body = %v", body)
+	}
+	if _, ok := body["sequenceToken"]; ok {
+		t.Error("empty sequenceToken sent")
+	}
+}
+
+func TestClient_error(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		want   APIError
+	}{
+		{
+			name:   "typed",
+			status: http.StatusBadRequest,
+			body:   `{"__type":"com.amazonaws.logs#InvalidSequenceTokenException","message":"bad","expectedSequenceToken":"x"}`,
+			want:   APIError{Code: CodeInvalidSequenceToken, Message: "bad", ExpectedSequenceToken: "x"},
+		},
+		{
+			name:   "upper message",
+			status: http.StatusBadRequest,
+			body:   `{"__type":"ResourceNotFoundException","Message":"missing"}`,
+			want:   APIError{Code: CodeResourceNotFound, Message: "missing"},
+		},
+		{
+			name:   "no body",
+			status: http.StatusServiceUnavailable,
+			want:   APIError{Code: "Service Unavailable"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer srv.Close()
+			err := NewClient(testConfig(srv.URL)).CreateLogStream(context.Background(), "g", "s")
+			var got *APIError
+			if !errors.As(err, &got) {
+				t.Fatalf("error = %v, want *APIError", err)
+			}
+			if *got != tt.want {
+				t.Errorf("error = %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClient_noCredentials(t *testing.T) {
+	cfg := testConfig("http://127.0.0.1:0")
+	cfg.Credentials = nil
+	if err := NewClient(cfg).CreateLogGroup(context.Background(), "g"); err == nil {
+		t.Error("error = nil, want error")
+	}
+}
+
+// TestClient_sign checks the signing against the get-vanilla and post-vanilla
+// cases of the AWS Signature Version 4 test suite.
+func TestClient_sign(t *testing.T) {
+	tests := []struct {
+		method string
+		want   string
+	}{
+		{
+			method: http.MethodGet,
+			want:   "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, SignedHeaders=host;x-amz-date, Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31",
+		},
+		{
+			method: http.MethodPost,
+			want:   "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, SignedHeaders=host;x-amz-date, Signature=5da7c1a2acd57cee7505fc6676e4e544621c30862966e37dddb68e92efbe5d6b",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method, func(t *testing.T) {
+			c := NewClient(aws.Config{
+				Region: "us-east-1",
+				Credentials: aws.CredentialsProviderFunc(func(context.Context) (aws.Credentials, error) {
+					return aws.Credentials{AccessKeyID: "AKIDEXAMPLE", SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY"}, nil
+				}),
+			}).(*client)
+			c.now = func() time.Time { return time.Date(2015, time.August, 30, 12, 36, 0, 0, time.UTC) }
+			req, err := http.NewRequest(tt.method, "https://example.amazonaws.com/", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := c.sign(context.Background(), req, nil, "service"); err != nil {
+				t.Fatal(err)
+			}
+			if got := req.Header.Get("X-Amz-Date"); got != "20150830T123600Z" {
+				t.Errorf("X-Amz-Date = %q", got)
+			}
+			if got := req.Header.Get("Authorization"); got != tt.want {
+				t.Errorf("Authorization = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,433 @@
+package cloudwatch
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/nekrassov01/logger/log"
+)
+
+var (
+	_ slog.Handler = (*Handler)(nil)
+	_ log.Flusher  = (*Handler)(nil)
+)
+
+// Limits of a PutLogEvents call.
+const (
+	MaxBatchEvents = 10000
+	MaxBatchBytes  = 1048576
+	MaxEventBytes  = 262144 - eventOverhead
+
+	eventOverhead = 26
+)
+
+// ErrHandlerClosed is returned when a record is handled after the handler is closed.
+var ErrHandlerClosed = errors.New("cloudwatch: handler closed")
+
+// Handler is a slog.Handler that batches records and uploads them to a
+// CloudWatch Logs stream. A batch is uploaded by a background goroutine when it
+// reaches the size limits or the flush interval elapses, so that Handle neither
+// waits for uploads nor lets the context of a record cancel them. Close must be
+// called to upload the remaining records before the program exits.
+type Handler struct {
+	inner slog.Handler
+	state *state
+}
+
+// state is the state shared by a Handler and its derived handlers.
+type state struct {
+	client        Client
+	group         string
+	stream        string
+	newHandler    func(w io.Writer) slog.Handler
+	level         slog.Leveler
+	flushInterval time.Duration
+	maxEvents     int
+	maxBytes      int
+	maxRetries    int
+	backoff       time.Duration
+	createGroup   bool
+	createStream  bool
+	sleep         func(ctx context.Context, d time.Duration) error
+	now           func() time.Time
+
+	mu     sync.Mutex
+	buf    bytes.Buffer
+	events []InputLogEvent
+	size   int
+	full   [][]InputLogEvent
+	closed bool
+	err    error
+
+	putMu sync.Mutex
+	token string
+
+	once sync.Once
+	kick chan struct{}
+	stop chan struct{}
+	done chan struct{}
+}
+
+// Option defines a function type for configuring a Handler.
+type Option func(*state)
+
+// WithHandler returns an Option that sets the constructor of the handler
+// formatting each record into an event message. The default writes JSON.
+func WithHandler(fn func(w io.Writer) slog.Handler) Option {
+	return func(s *state) {
+		if fn != nil {
+			s.newHandler = fn
+		}
+	}
+}
+
+// WithLevel returns an Option that sets the minimum level of the default handler.
+func WithLevel(level slog.Leveler) Option {
+	return func(s *state) {
+		s.level = level
+	}
+}
+
+// WithFlushInterval returns an Option that sets the maximum age of a batch
+// before it is uploaded. The default is 5 seconds.
+func WithFlushInterval(d time.Duration) Option {
+	return func(s *state) {
+		if d > 0 {
+			s.flushInterval = d
+		}
+	}
+}
+
+// WithBatchSize returns an Option that sets the maximum number of events and
+// bytes of a batch. Values outside the PutLogEvents limits are ignored.
+func WithBatchSize(events, bytes int) Option {
+	return func(s *state) {
+		if events > 0 && events <= MaxBatchEvents {
+			s.maxEvents = events
+		}
+		if bytes > 0 && bytes <= MaxBatchBytes {
+			s.maxBytes = bytes
+		}
+	}
+}
+
+// WithRetry returns an Option that sets the number of retries of a failed
+// upload and the initial backoff, doubled on each retry. The default is 3 retries from 200ms.
+func WithRetry(n int, backoff time.Duration) Option {
+	return func(s *state) {
+		if n >= 0 {
+			s.maxRetries = n
+		}
+		if backoff > 0 {
+			s.backoff = backoff
+		}
+	}
+}
+
+// WithCreateGroup returns an Option that sets whether the log group is created
+// when it does not exist.
+func WithCreateGroup(create bool) Option {
+	return func(s *state) {
+		s.createGroup = create
+	}
+}
+
+// WithCreateStream returns an Option that sets whether the log stream is
+// created when it does not exist. It is enabled by default.
+func WithCreateStream(create bool) Option {
+	return func(s *state) {
+		s.createStream = create
+	}
+}
+
+// NewHandler creates a new Handler uploading to the stream of the log group
+// and starts its background goroutine.
+func NewHandler(client Client, group, stream string, opts ...Option) *Handler {
+	s := &state{
+		client:        client,
+		group:         group,
+		stream:        stream,
+		flushInterval: 5 * time.Second,
+		maxEvents:     MaxBatchEvents,
+		maxBytes:      MaxBatchBytes,
+		maxRetries:    3,
+		backoff:       200 * time.Millisecond,
+		createStream:  true,
+		sleep:         sleep,
+		now:           time.Now,
+		kick:          make(chan struct{}, 1),
+		stop:          make(chan struct{}),
+		done:          make(chan struct{}),
+	}
+	s.newHandler = func(w io.Writer) slog.Handler {
+		return slog.NewJSONHandler(w, &slog.HandlerOptions{Level: s.level})
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	h := &Handler{inner: s.newHandler(&s.buf), state: s}
+	go s.run()
+	return h
+}
+
+// sleep waits for d or until ctx is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// run uploads the full batches as they are handed over, and the pending batch
+// every flush interval, until the handler is closed. Uploads use their own
+// context rather than that of the record filling a batch.
+func (s *state) run() {
+	defer close(s.done)
+	t := time.NewTicker(s.flushInterval)
+	defer t.Stop()
+	for {
+		var err error
+		select {
+		case <-s.stop:
+			return
+		case <-s.kick:
+			err = s.putAll(context.Background(), s.takeFull(false))
+		case <-t.C:
+			err = s.flush(context.Background())
+		}
+		if err != nil {
+			s.setErr(err)
+		}
+	}
+}
+
+// Enabled reports whether the inner handler is enabled for the given level.
+func (h *Handler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.inner.Enabled(ctx, level)
+}
+
+// Handle formats the record and adds it to the pending batch, handing the batch
+// over to the background goroutine first if the record does not fit in it.
+// Errors of the upload are returned by Flush and Close.
+func (h *Handler) Handle(ctx context.Context, r slog.Record) error {
+	s := h.state
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return ErrHandlerClosed
+	}
+	s.buf.Reset()
+	if err := h.inner.Handle(ctx, r); err != nil {
+		s.mu.Unlock()
+		return err
+	}
+	msg := bytes.TrimSuffix(s.buf.Bytes(), []byte("\n"))
+	if len(msg) > MaxEventBytes {
+		n := MaxEventBytes
+		for n > 0 && !utf8.RuneStart(msg[n]) {
+			n--
+		}
+		msg = msg[:n]
+	}
+	t := r.Time
+	if t.IsZero() {
+		t = s.now()
+	}
+	ev := InputLogEvent{Timestamp: t.UnixMilli(), Message: string(msg)}
+	n := len(ev.Message) + eventOverhead
+	full := len(s.events) >= s.maxEvents || s.size+n > s.maxBytes
+	if full {
+		s.full = append(s.full, s.take())
+	}
+	s.events = append(s.events, ev)
+	s.size += n
+	s.mu.Unlock()
+	if full {
+		select {
+		case s.kick <- struct{}{}:
+		default:
+		}
+	}
+	return nil
+}
+
+// WithAttrs returns a new Handler whose inner handler has the given attributes.
+// The batch is shared with the receiver.
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	return &Handler{inner: h.inner.WithAttrs(attrs), state: h.state}
+}
+
+// WithGroup returns a new Handler whose inner handler has the given group.
+// The batch is shared with the receiver.
+func (h *Handler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return &Handler{inner: h.inner.WithGroup(name), state: h.state}
+}
+
+// Flush uploads the pending batch and returns the first error of a background
+// upload since the last call, if any.
+func (h *Handler) Flush() error {
+	err := h.state.flush(context.Background())
+	return errors.Join(h.state.takeErr(), err)
+}
+
+// Close stops the background goroutine and uploads the pending batch.
+// Records handled afterwards return ErrHandlerClosed.
+func (h *Handler) Close() error {
+	s := h.state
+	s.mu.Lock()
+	s.closed = true
+	s.mu.Unlock()
+	s.once.Do(func() { close(s.stop) })
+	<-s.done
+	return h.Flush()
+}
+
+// take returns the pending batch and resets it. It must be called with the mutex held.
+func (s *state) take() []InputLogEvent {
+	batch := s.events
+	s.events = nil
+	s.size = 0
+	return batch
+}
+
+// takeFull returns the full batches handed over by Handle, followed by the
+// pending batch if pending is true, and resets them.
+func (s *state) takeFull(pending bool) [][]InputLogEvent {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	batches := s.full
+	s.full = nil
+	if pending && len(s.events) > 0 {
+		batches = append(batches, s.take())
+	}
+	return batches
+}
+
+// flush uploads the full batches and the pending batch.
+func (s *state) flush(ctx context.Context) error {
+	return s.putAll(ctx, s.takeFull(true))
+}
+
+// putAll uploads the batches in order and joins their errors.
+func (s *state) putAll(ctx context.Context, batches [][]InputLogEvent) error {
+	var errs []error
+	for _, batch := range batches {
+		if err := s.put(ctx, batch); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// put uploads a batch in chronological order, creating the group and stream
+// when allowed, following the sequence token and retrying transient errors.
+func (s *state) put(ctx context.Context, batch []InputLogEvent) error {
+	s.putMu.Lock()
+	defer s.putMu.Unlock()
+
+	slices.SortStableFunc(batch, func(a, b InputLogEvent) int {
+		switch {
+		case a.Timestamp < b.Timestamp:
+			return -1
+		case a.Timestamp > b.Timestamp:
+			return 1
+		}
+		return 0
+	})
+	backoff := s.backoff
+	created := false
+	for retry := 0; ; retry++ {
+		out, err := s.client.PutLogEvents(ctx, &PutLogEventsInput{
+			LogGroupName:  s.group,
+			LogStreamName: s.stream,
+			LogEvents:     batch,
+			SequenceToken: s.token,
+		})
+		if err == nil {
+			s.token = out.NextSequenceToken
+			return nil
+		}
+		var apiErr *APIError
+		errors.As(err, &apiErr)
+		switch errorCode(err) {
+		case CodeDataAlreadyAccepted:
+			s.token = apiErr.ExpectedSequenceToken
+			return nil
+		case CodeInvalidSequenceToken:
+			s.token = apiErr.ExpectedSequenceToken
+			if retry < s.maxRetries {
+				continue
+			}
+			return err
+		case CodeResourceNotFound:
+			if created || !s.createStream {
+				return err
+			}
+			if cerr := s.create(ctx); cerr != nil {
+				return errors.Join(err, cerr)
+			}
+			created = true
+			s.token = ""
+			retry--
+			continue
+		case CodeThrottling, CodeServiceUnavailable, "":
+		default:
+			return err
+		}
+		if retry >= s.maxRetries {
+			return err
+		}
+		if serr := s.sleep(ctx, backoff); serr != nil {
+			return errors.Join(err, serr)
+		}
+		backoff *= 2
+	}
+}
+
+// create creates the log stream, and the log group first if allowed.
+func (s *state) create(ctx context.Context) error {
+	if s.createGroup {
+		if err := s.client.CreateLogGroup(ctx, s.group); err != nil && errorCode(err) != CodeResourceAlreadyExists {
+			return err
+		}
+	}
+	if err := s.client.CreateLogStream(ctx, s.group, s.stream); err != nil && errorCode(err) != CodeResourceAlreadyExists {
+		return err
+	}
+	return nil
+}
+
+// setErr records the first error of a background upload.
+func (s *state) setErr(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err == nil {
+		s.err = err
+	}
+}
+
+// takeErr returns and clears the recorded background error.
+func (s *state) takeErr() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	err := s.err
+	s.err = nil
+	return err
+}
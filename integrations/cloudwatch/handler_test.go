@@ -0,0 +1,289 @@
+package cloudwatch
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nekrassov01/logger/log"
+)
+
+// fakeClient records calls and returns scripted PutLogEvents errors.
+type fakeClient struct {
+	mu      sync.Mutex
+	calls   []string
+	puts    []PutLogEventsInput
+	putErrs []error
+	token   int
+}
+
+func (c *fakeClient) CreateLogGroup(_ context.Context, group string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.calls = append(c.calls, "CreateLogGroup")
+	return &APIError{Code: CodeResourceAlreadyExists}
+}
+
+func (c *fakeClient) CreateLogStream(_ context.Context, group, stream string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.calls = append(c.calls, "CreateLogStream")
+	return nil
+}
+
+func (c *fakeClient) PutLogEvents(ctx context.Context, in *PutLogEventsInput) (*PutLogEventsOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.calls = append(c.calls, "PutLogEvents:"+in.SequenceToken)
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if len(c.putErrs) > 0 {
+		err := c.putErrs[0]
+		c.putErrs = c.putErrs[1:]
+		if err != nil {
+			return nil, err
+		}
+	}
+	c.puts = append(c.puts, *in)
+	c.token++
+	return &PutLogEventsOutput{NextSequenceToken: "t" + strings.Repeat("+", c.token)}, nil
+}
+
+func newTestHandler(c *fakeClient, opts ...Option) *Handler {
+	opts = append([]Option{
+		WithFlushInterval(time.Hour),
+		WithHandler(func(w io.Writer) slog.Handler {
+			return log.NewLogfmtHandler(w, log.WithReplaceAttr(func(_ []string, a slog.Attr) slog.Attr {
+				if a.Key == slog.TimeKey {
+					return slog.Attr{}
+				}
+				return a
+			}))
+		}),
+	}, opts...)
+	h := NewHandler(c, "group", "stream", opts...)
+	h.state.sleep = func(context.Context, time.Duration) error { return nil }
+	return h
+}
+
+// waitPuts waits until the client has received n batches.
+func waitPuts(t *testing.T, c *fakeClient, n int) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		c.mu.Lock()
+		got := len(c.puts)
+		c.mu.Unlock()
+		if got >= n {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("%d batches not uploaded", n)
+}
+
+func messages(in PutLogEventsInput) []string {
+	var s []string
+	for _, ev := range in.LogEvents {
+		s = append(s, ev.Message)
+	}
+	return s
+}
+
+func TestHandler_Flush(t *testing.T) {
+	c := &fakeClient{}
+	h := newTestHandler(c)
+	defer h.Close()
+	l := slog.New(h)
+	base := time.Date(2025, time.April, 1, 0, 0, 0, 0, time.UTC)
+	for i, msg := range []string{"b", "a"} {
+		r := slog.NewRecord(base.Add(time.Duration(1-i)*time.Second), slog.LevelInfo, msg, 0)
+		if err := l.Handler().Handle(context.Background(), r); err != nil {
+			t.Fatal(err)
+		}
+	}
+	l.With("k", "v").Warn("c")
+	if len(c.puts) != 0 {
+		t.Fatalf("puts = %d before flush, want 0", len(c.puts))
+	}
+	if err := h.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if len(c.puts) != 1 {
+		t.Fatalf("puts = %d, want 1", len(c.puts))
+	}
+	got := strings.Join(messages(c.puts[0]), "|")
+	if want := "level=INFO msg=a|level=INFO msg=b|level=WARN msg=c k=v"; got != want {
+		t.Errorf("messages = %q, want %q", got, want)
+	}
+	if c.puts[0].LogGroupName != "group" || c.puts[0].LogStreamName != "stream" {
+		t.Errorf("destination = %s/%s", c.puts[0].LogGroupName, c.puts[0].LogStreamName)
+	}
+	if err := h.Flush(); err != nil || len(c.puts) != 1 {
+		t.Errorf("empty flush: err = %v, puts = %d", err, len(c.puts))
+	}
+}
+
+func TestHandler_batchSize(t *testing.T) {
+	c := &fakeClient{}
+	h := newTestHandler(c, WithBatchSize(2, 0))
+	l := slog.New(h)
+	for range 5 {
+		l.Info("m")
+	}
+	waitPuts(t, c, 2)
+	if err := h.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if len(c.puts) != 3 || len(c.puts[2].LogEvents) != 1 {
+		t.Errorf("puts after close = %d, want 3", len(c.puts))
+	}
+	if err := h.Handle(context.Background(), slog.NewRecord(time.Now(), slog.LevelInfo, "m", 0)); !errors.Is(err, ErrHandlerClosed) {
+		t.Errorf("Handle() after Close error = %v, want %v", err, ErrHandlerClosed)
+	}
+}
+
+func TestHandler_batchBytes(t *testing.T) {
+	c := &fakeClient{}
+	h := newTestHandler(c, WithBatchSize(0, 2*(eventOverhead+len("level=INFO msg=m"))))
+	defer h.Close()
+	l := slog.New(h)
+	for range 3 {
+		l.Info("m")
+	}
+	waitPuts(t, c, 1)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.puts) != 1 || len(c.puts[0].LogEvents) != 2 {
+		t.Errorf("puts = %v, want one batch of 2", c.puts)
+	}
+}
+
+func TestHandler_canceledContext(t *testing.T) {
+	c := &fakeClient{}
+	h := newTestHandler(c, WithBatchSize(2, 0))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	l := slog.New(h)
+	l.InfoContext(context.Background(), "a")
+	l.InfoContext(context.Background(), "b")
+	l.InfoContext(ctx, "c")
+	if err := h.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if len(c.puts) != 2 {
+		t.Fatalf("puts = %d, want 2", len(c.puts))
+	}
+	if got := strings.Join(messages(c.puts[0]), "|"); got != "level=INFO msg=a|level=INFO msg=b" {
+		t.Errorf("first batch = %q, want the batch filled before the canceled record", got)
+	}
+}
+
+func TestHandler_interval(t *testing.T) {
+	c := &fakeClient{}
+	h := newTestHandler(c, WithFlushInterval(10*time.Millisecond))
+	defer h.Close()
+	slog.New(h).Info("m")
+	waitPuts(t, c, 1)
+}
+
+func TestHandler_put(t *testing.T) {
+	errThrottle := &APIError{Code: CodeThrottling}
+	tests := []struct {
+		name      string
+		opts      []Option
+		putErrs   []error
+		wantCalls []string
+		wantErr   bool
+	}{
+		{
+			name:      "sequence token",
+			putErrs:   []error{&APIError{Code: CodeInvalidSequenceToken, ExpectedSequenceToken: "x"}},
+			wantCalls: []string{"PutLogEvents:", "PutLogEvents:x"},
+		},
+		{
+			name:      "already accepted",
+			putErrs:   []error{&APIError{Code: CodeDataAlreadyAccepted, ExpectedSequenceToken: "x"}},
+			wantCalls: []string{"PutLogEvents:"},
+		},
+		{
+			name:      "retry throttling",
+			putErrs:   []error{errThrottle, errThrottle},
+			wantCalls: []string{"PutLogEvents:", "PutLogEvents:", "PutLogEvents:"},
+		},
+		{
+			name:      "retries exhausted",
+			opts:      []Option{WithRetry(1, 0)},
+			putErrs:   []error{errThrottle, errThrottle},
+			wantCalls: []string{"PutLogEvents:", "PutLogEvents:"},
+			wantErr:   true,
+		},
+		{
+			name:      "not retryable",
+			putErrs:   []error{&APIError{Code: "AccessDeniedException"}},
+			wantCalls: []string{"PutLogEvents:"},
+			wantErr:   true,
+		},
+		{
+			name:      "create stream",
+			putErrs:   []error{&APIError{Code: CodeResourceNotFound}},
+			wantCalls: []string{"PutLogEvents:", "CreateLogStream", "PutLogEvents:"},
+		},
+		{
+			name:      "create group and stream",
+			opts:      []Option{WithCreateGroup(true)},
+			putErrs:   []error{&APIError{Code: CodeResourceNotFound}},
+			wantCalls: []string{"PutLogEvents:", "CreateLogGroup", "CreateLogStream", "PutLogEvents:"},
+		},
+		{
+			name:      "no create",
+			opts:      []Option{WithCreateStream(false)},
+			putErrs:   []error{&APIError{Code: CodeResourceNotFound}},
+			wantCalls: []string{"PutLogEvents:"},
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &fakeClient{putErrs: tt.putErrs}
+			h := newTestHandler(c, tt.opts...)
+			defer h.Close()
+			slog.New(h).Info("m")
+			err := h.Flush()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Flush() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := strings.Join(c.calls, ","); got != strings.Join(tt.wantCalls, ",") {
+				t.Errorf("calls = %v, want %v", c.calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestHandler_truncate(t *testing.T) {
+	c := &fakeClient{}
+	h := newTestHandler(c, WithHandler(func(w io.Writer) slog.Handler {
+		return log.NewLogfmtHandler(w)
+	}))
+	defer h.Close()
+	msg := strings.Repeat("あ", MaxEventBytes/3+1)
+	if err := h.Handle(context.Background(), slog.NewRecord(time.Time{}, slog.LevelInfo, msg, 0)); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	ev := c.puts[0].LogEvents[0]
+	if len(ev.Message) > MaxEventBytes || !strings.HasSuffix(ev.Message, "あ") {
+		t.Errorf("message length = %d, want <= %d and rune boundary", len(ev.Message), MaxEventBytes)
+	}
+	if ev.Timestamp == 0 {
+		t.Error("timestamp = 0, want current time")
+	}
+}
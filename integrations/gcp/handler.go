@@ -0,0 +1,236 @@
+package gcp
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+
+	"github.com/nekrassov01/logger/log"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var (
+	_ slog.Handler    = (*Handler)(nil)
+	_ log.LevelSetter = (*Handler)(nil)
+)
+
+// Keys of the special fields recognized by Cloud Logging.
+// See: https://cloud.google.com/logging/docs/structured-logging
+const (
+	SeverityKey       = "severity"
+	MessageKey        = "message"
+	SourceLocationKey = "logging.googleapis.com/sourceLocation"
+	TraceKey          = "logging.googleapis.com/trace"
+	SpanIDKey         = "logging.googleapis.com/spanId"
+	TraceSampledKey   = "logging.googleapis.com/trace_sampled"
+	LabelsKey         = "logging.googleapis.com/labels"
+)
+
+// Severity returns the Cloud Logging severity of a slog level. Levels between
+// slog.LevelInfo and slog.LevelWarn map to NOTICE, and log.LevelFatal and above to CRITICAL.
+func Severity(level slog.Level) string {
+	switch {
+	case level >= log.LevelFatal:
+		return "CRITICAL"
+	case level >= slog.LevelError:
+		return "ERROR"
+	case level >= slog.LevelWarn:
+		return "WARNING"
+	case level > slog.LevelInfo:
+		return "NOTICE"
+	case level >= slog.LevelInfo:
+		return "INFO"
+	default:
+		return "DEBUG"
+	}
+}
+
+// Labels returns an attribute holding Cloud Logging labels. Label values are
+// written as strings.
+func Labels(attrs ...slog.Attr) slog.Attr {
+	args := make([]any, len(attrs))
+	for i, a := range attrs {
+		args[i] = a
+	}
+	return slog.Group(LabelsKey, args...)
+}
+
+// Handler is a slog.Handler that writes JSON lines in the Cloud Logging
+// structured format. The trace and span of the span carried by the context are
+// written as top-level fields regardless of the open groups.
+type Handler struct {
+	base      slog.Handler
+	inner     slog.Handler
+	goas      []groupOrAttrs
+	level     *slog.LevelVar
+	projectID string
+}
+
+// groupOrAttrs is either a group name or a list of attributes added to a Handler.
+type groupOrAttrs struct {
+	group string
+	attrs []slog.Attr
+}
+
+// config holds the options of a Handler.
+type config struct {
+	level       slog.Level
+	projectID   string
+	hasSource   bool
+	replaceAttr func(groups []string, a slog.Attr) slog.Attr
+}
+
+// Option defines a function type for configuring a Handler.
+type Option func(*config)
+
+// WithLevel returns an Option that sets the minimum level. The default is slog.LevelInfo.
+func WithLevel(level slog.Level) Option {
+	return func(c *config) {
+		c.level = level
+	}
+}
+
+// WithProjectID returns an Option that sets the project used to qualify trace IDs.
+// The default is the GOOGLE_CLOUD_PROJECT environment variable.
+func WithProjectID(id string) Option {
+	return func(c *config) {
+		c.projectID = id
+	}
+}
+
+// WithSource returns an Option that sets whether the source location is written.
+func WithSource(has bool) Option {
+	return func(c *config) {
+		c.hasSource = has
+	}
+}
+
+// WithReplaceAttr returns an Option that sets the function rewriting non-special
+// attributes, with the semantics of slog.HandlerOptions.ReplaceAttr.
+func WithReplaceAttr(fn func(groups []string, a slog.Attr) slog.Attr) Option {
+	return func(c *config) {
+		c.replaceAttr = fn
+	}
+}
+
+// NewHandler creates a new Handler writing to w.
+func NewHandler(w io.Writer, opts ...Option) *Handler {
+	if w == nil {
+		w = os.Stderr
+	}
+	c := &config{projectID: os.Getenv("GOOGLE_CLOUD_PROJECT")}
+	for _, opt := range opts {
+		opt(c)
+	}
+	level := &slog.LevelVar{}
+	level.Set(c.level)
+	base := slog.NewJSONHandler(w, &slog.HandlerOptions{
+		AddSource:   c.hasSource,
+		Level:       level,
+		ReplaceAttr: c.replace,
+	})
+	return &Handler{
+		base:      base,
+		inner:     base,
+		level:     level,
+		projectID: c.projectID,
+	}
+}
+
+// replace renames the built-in attributes to the Cloud Logging fields and
+// converts label values to strings.
+func (c *config) replace(groups []string, a slog.Attr) slog.Attr {
+	if len(groups) == 0 {
+		switch a.Key {
+		case slog.LevelKey:
+			if l, ok := a.Value.Any().(slog.Level); ok {
+				return slog.String(SeverityKey, Severity(l))
+			}
+		case slog.MessageKey:
+			return slog.Attr{Key: MessageKey, Value: a.Value}
+		case slog.SourceKey:
+			if _, ok := a.Value.Any().(*slog.Source); ok {
+				return slog.Attr{Key: SourceLocationKey, Value: a.Value}
+			}
+		case TraceKey, SpanIDKey, TraceSampledKey:
+			return a
+		}
+	}
+	if len(groups) > 0 && groups[0] == LabelsKey && a.Value.Kind() != slog.KindGroup {
+		return slog.String(a.Key, a.Value.String())
+	}
+	if c.replaceAttr != nil {
+		return c.replaceAttr(groups, a)
+	}
+	return a
+}
+
+// Enabled reports whether the handler is enabled for the given level.
+func (h *Handler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.inner.Enabled(ctx, level)
+}
+
+// Handle writes the record as a JSON line.
+func (h *Handler) Handle(ctx context.Context, r slog.Record) error {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return h.inner.Handle(ctx, r)
+	}
+	tid := sc.TraceID().String()
+	if h.projectID != "" {
+		tid = fmt.Sprintf("projects/%s/traces/%s", h.projectID, tid)
+	}
+	attrs := []slog.Attr{
+		slog.String(TraceKey, tid),
+		slog.String(SpanIDKey, sc.SpanID().String()),
+		slog.Bool(TraceSampledKey, sc.IsSampled()),
+	}
+	if len(h.goas) == 0 {
+		r = r.Clone()
+		r.AddAttrs(attrs...)
+		return h.inner.Handle(ctx, r)
+	}
+	inner := h.base.WithAttrs(attrs)
+	for _, goa := range h.goas {
+		if goa.group != "" {
+			inner = inner.WithGroup(goa.group)
+		} else {
+			inner = inner.WithAttrs(goa.attrs)
+		}
+	}
+	return inner.Handle(ctx, r)
+}
+
+// WithAttrs returns a new Handler with the given attributes.
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	return h.with(groupOrAttrs{attrs: attrs}, h.inner.WithAttrs(attrs))
+}
+
+// WithGroup returns a new Handler with the given group.
+func (h *Handler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return h.with(groupOrAttrs{group: name}, h.inner.WithGroup(name))
+}
+
+// with returns a copy of the handler with goa appended and the given inner handler.
+func (h *Handler) with(goa groupOrAttrs, inner slog.Handler) *Handler {
+	h2 := *h
+	h2.inner = inner
+	h2.goas = append(h.goas[:len(h.goas):len(h.goas)], goa)
+	return &h2
+}
+
+// SetLevel changes the minimum level of the handler and its derived handlers.
+// A nil level is ignored.
+func (h *Handler) SetLevel(level slog.Leveler) {
+	if level != nil {
+		h.level.Set(level.Level())
+	}
+}
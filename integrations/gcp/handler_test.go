@@ -0,0 +1,175 @@
+package gcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nekrassov01/logger/log"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestSeverity(t *testing.T) {
+	tests := []struct {
+		level slog.Level
+		want  string
+	}{
+		{level: slog.LevelDebug, want: "DEBUG"},
+		{level: slog.LevelInfo, want: "INFO"},
+		{level: slog.LevelInfo + 2, want: "NOTICE"},
+		{level: slog.LevelWarn, want: "WARNING"},
+		{level: slog.LevelError, want: "ERROR"},
+		{level: log.LevelFatal, want: "CRITICAL"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.level.String(), func(t *testing.T) {
+			if got := Severity(tt.level); got != tt.want {
+				t.Errorf("Severity(%v) = %q, want %q", tt.level, got, tt.want)
+			}
+		})
+	}
+}
+
+func testSpanContext(t *testing.T, sampled bool) context.Context {
+	t.Helper()
+	tid, err := trace.TraceIDFromHex("0102030405060708090a0b0c0d0e0f10")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sid, err := trace.SpanIDFromHex("0102030405060708")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var flags trace.TraceFlags
+	if sampled {
+		flags = trace.FlagsSampled
+	}
+	sc := trace.NewSpanContext(trace.SpanContextConfig{TraceID: tid, SpanID: sid, TraceFlags: flags})
+	return trace.ContextWithSpanContext(context.Background(), sc)
+}
+
+func TestHandler_Handle(t *testing.T) {
+	tests := []struct {
+		name   string
+		opts   []Option
+		ctx    func(*testing.T) context.Context
+		handle func(slog.Handler) slog.Handler
+		level  slog.Level
+		attrs  []slog.Attr
+		want   string
+	}{
+		{
+			name:  "default",
+			level: slog.LevelWarn,
+			attrs: []slog.Attr{slog.Int("n", 1)},
+			want:  `{"time":"2025-04-01T00:00:00Z","severity":"WARNING","message":"hello","n":1}`,
+		},
+		{
+			name:  "labels",
+			level: slog.LevelInfo,
+			attrs: []slog.Attr{Labels(slog.Int("n", 1), slog.Bool("ok", true))},
+			want:  `{"time":"2025-04-01T00:00:00Z","severity":"INFO","message":"hello","logging.googleapis.com/labels":{"n":"1","ok":"true"}}`,
+		},
+		{
+			name:  "labels group",
+			level: slog.LevelInfo,
+			handle: func(h slog.Handler) slog.Handler {
+				return h.WithGroup(LabelsKey).WithAttrs([]slog.Attr{slog.Int("n", 1)})
+			},
+			want: `{"time":"2025-04-01T00:00:00Z","severity":"INFO","message":"hello","logging.googleapis.com/labels":{"n":"1"}}`,
+		},
+		{
+			name:  "trace with project",
+			opts:  []Option{WithProjectID("p")},
+			ctx:   func(t *testing.T) context.Context { return testSpanContext(t, true) },
+			level: slog.LevelInfo,
+			want:  `{"time":"2025-04-01T00:00:00Z","severity":"INFO","message":"hello","logging.googleapis.com/trace":"projects/p/traces/0102030405060708090a0b0c0d0e0f10","logging.googleapis.com/spanId":"0102030405060708","logging.googleapis.com/trace_sampled":true}`,
+		},
+		{
+			name:  "trace in group",
+			opts:  []Option{WithProjectID("")},
+			ctx:   func(t *testing.T) context.Context { return testSpanContext(t, false) },
+			level: slog.LevelInfo,
+			handle: func(h slog.Handler) slog.Handler {
+				return h.WithAttrs([]slog.Attr{slog.String("app", "x")}).WithGroup("req")
+			},
+			attrs: []slog.Attr{slog.String("method", "GET")},
+			want:  `{"time":"2025-04-01T00:00:00Z","severity":"INFO","message":"hello","logging.googleapis.com/trace":"0102030405060708090a0b0c0d0e0f10","logging.googleapis.com/spanId":"0102030405060708","logging.googleapis.com/trace_sampled":false,"app":"x","req":{"method":"GET"}}`,
+		},
+		{
+			name: "replace attr",
+			opts: []Option{WithReplaceAttr(func(_ []string, a slog.Attr) slog.Attr {
+				if a.Key == slog.TimeKey {
+					return slog.Attr{}
+				}
+				return a
+			})},
+			level: slog.LevelError,
+			want:  `{"severity":"ERROR","message":"hello"}`,
+		},
+		{
+			name:  "below level",
+			opts:  []Option{WithLevel(slog.LevelWarn)},
+			level: slog.LevelInfo,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			var h slog.Handler = NewHandler(buf, tt.opts...)
+			if tt.handle != nil {
+				h = tt.handle(h)
+			}
+			ctx := context.Background()
+			if tt.ctx != nil {
+				ctx = tt.ctx(t)
+			}
+			if h.Enabled(ctx, tt.level) {
+				r := slog.NewRecord(time.Date(2025, time.April, 1, 0, 0, 0, 0, time.UTC), tt.level, "hello", 0)
+				r.AddAttrs(tt.attrs...)
+				if err := h.Handle(ctx, r); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if got := strings.TrimSuffix(buf.String(), "\n"); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHandler_source(t *testing.T) {
+	buf := &bytes.Buffer{}
+	slog.New(NewHandler(buf, WithSource(true))).Info("hello")
+	var m map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &m); err != nil {
+		t.Fatal(err)
+	}
+	loc, ok := m[SourceLocationKey].(map[string]any)
+	if !ok {
+		t.Fatalf("%s = %v, want object", SourceLocationKey, m[SourceLocationKey])
+	}
+	if f, _ := loc["function"].(string); !strings.HasSuffix(f, "TestHandler_source") {
+		t.Errorf("function = %v, want suffix TestHandler_source", loc["function"])
+	}
+	if _, ok := loc["line"].(float64); !ok {
+		t.Errorf("line = %v, want number", loc["line"])
+	}
+}
+
+func TestHandler_SetLevel(t *testing.T) {
+	h := NewHandler(nil)
+	h2 := h.WithGroup("g")
+	h.SetLevel(slog.LevelError)
+	if h2.Enabled(context.Background(), slog.LevelWarn) {
+		t.Error("Enabled(WARN) = true, want false")
+	}
+	h.SetLevel(nil)
+	if !h2.Enabled(context.Background(), slog.LevelError) {
+		t.Error("Enabled(ERROR) = false, want true")
+	}
+}
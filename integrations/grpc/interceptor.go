@@ -0,0 +1,242 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nekrassov01/logger/log"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// Attribute keys written by the interceptors, inside the GroupKey group.
+const (
+	GroupKey    = "grpc"
+	ServiceKey  = "service"
+	MethodKey   = "method"
+	CodeKey     = "code"
+	LatencyKey  = "latency"
+	PeerKey     = "peer"
+	MetadataKey = "metadata"
+)
+
+// DefaultCodeToLevel maps a status code to a level: codes caused by the caller
+// are logged at info, transient failures at warn and server faults at error.
+func DefaultCodeToLevel(code codes.Code) slog.Level {
+	switch code {
+	case codes.OK, codes.Canceled, codes.InvalidArgument, codes.NotFound,
+		codes.AlreadyExists, codes.Unauthenticated:
+		return slog.LevelInfo
+	case codes.DeadlineExceeded, codes.PermissionDenied, codes.ResourceExhausted,
+		codes.FailedPrecondition, codes.Aborted, codes.OutOfRange, codes.Unavailable:
+		return slog.LevelWarn
+	default:
+		return slog.LevelError
+	}
+}
+
+// config holds the options of the interceptors.
+type config struct {
+	levelOf      func(codes.Code) slog.Level
+	metadataKeys []string
+	now          func() time.Time
+}
+
+// Option defines a function type for configuring the interceptors.
+type Option func(*config)
+
+// WithCodeToLevel returns an Option that sets the mapping from status codes to
+// levels. The default is DefaultCodeToLevel.
+func WithCodeToLevel(fn func(codes.Code) slog.Level) Option {
+	return func(c *config) {
+		if fn != nil {
+			c.levelOf = fn
+		}
+	}
+}
+
+// WithMetadataKeys returns an Option that sets the metadata keys logged with
+// each call. The incoming metadata is read on servers and the outgoing metadata on clients.
+func WithMetadataKeys(keys ...string) Option {
+	return func(c *config) {
+		for _, k := range keys {
+			c.metadataKeys = append(c.metadataKeys, strings.ToLower(k))
+		}
+	}
+}
+
+// newConfig applies the options to the default configuration.
+func newConfig(opts []Option) *config {
+	c := &config{levelOf: DefaultCodeToLevel, now: time.Now}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// UnaryServerInterceptor returns a grpc.UnaryServerInterceptor that logs every call.
+func UnaryServerInterceptor(logger *log.Logger, opts ...Option) grpc.UnaryServerInterceptor {
+	c := newConfig(opts)
+	if logger == nil {
+		logger = log.NewLogger(nil)
+	}
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		start := c.now()
+		resp, err := handler(ctx, req)
+		c.log(ctx, logger, "finished unary call", info.FullMethod, start, peerAddr(ctx), incoming(ctx), err)
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor returns a grpc.StreamServerInterceptor that logs every stream.
+func StreamServerInterceptor(logger *log.Logger, opts ...Option) grpc.StreamServerInterceptor {
+	c := newConfig(opts)
+	if logger == nil {
+		logger = log.NewLogger(nil)
+	}
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := c.now()
+		err := handler(srv, ss)
+		ctx := ss.Context()
+		c.log(ctx, logger, "finished stream call", info.FullMethod, start, peerAddr(ctx), incoming(ctx), err)
+		return err
+	}
+}
+
+// UnaryClientInterceptor returns a grpc.UnaryClientInterceptor that logs every call.
+func UnaryClientInterceptor(logger *log.Logger, opts ...Option) grpc.UnaryClientInterceptor {
+	c := newConfig(opts)
+	if logger == nil {
+		logger = log.NewLogger(nil)
+	}
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, callOpts ...grpc.CallOption) error {
+		start := c.now()
+		var p peer.Peer
+		err := invoker(ctx, method, req, reply, cc, append(callOpts, grpc.Peer(&p))...)
+		c.log(ctx, logger, "finished client unary call", method, start, addr(&p), outgoing(ctx), err)
+		return err
+	}
+}
+
+// StreamClientInterceptor returns a grpc.StreamClientInterceptor that logs every
+// stream once it is finished, that is when receiving fails or returns io.EOF.
+func StreamClientInterceptor(logger *log.Logger, opts ...Option) grpc.StreamClientInterceptor {
+	c := newConfig(opts)
+	if logger == nil {
+		logger = log.NewLogger(nil)
+	}
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, callOpts ...grpc.CallOption) (grpc.ClientStream, error) {
+		start := c.now()
+		p := &peer.Peer{}
+		cs, err := streamer(ctx, desc, cc, method, append(callOpts, grpc.Peer(p))...)
+		if err != nil {
+			c.log(ctx, logger, "finished client stream call", method, start, addr(p), outgoing(ctx), err)
+			return nil, err
+		}
+		return &clientStream{ClientStream: cs, finish: func(err error) {
+			c.log(ctx, logger, "finished client stream call", method, start, addr(p), outgoing(ctx), err)
+		}}, nil
+	}
+}
+
+// clientStream is a grpc.ClientStream that calls finish once when the stream ends.
+type clientStream struct {
+	grpc.ClientStream
+	once   sync.Once
+	finish func(err error)
+}
+
+// RecvMsg receives a message and finishes the stream on error.
+func (s *clientStream) RecvMsg(m any) error {
+	err := s.ClientStream.RecvMsg(m)
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			s.once.Do(func() { s.finish(nil) })
+		} else {
+			s.once.Do(func() { s.finish(err) })
+		}
+	}
+	return err
+}
+
+// log writes the record of a finished call.
+func (c *config) log(ctx context.Context, logger *log.Logger, msg, fullMethod string, start time.Time, remote string, md metadata.MD, err error) {
+	code := status.Code(err)
+	level := c.levelOf(code)
+	if !logger.Enabled(ctx, level) {
+		return
+	}
+	service, method := splitMethod(fullMethod)
+	attrs := make([]any, 0, 6)
+	attrs = append(attrs,
+		slog.String(ServiceKey, service),
+		slog.String(MethodKey, method),
+		slog.String(CodeKey, code.String()),
+		slog.Duration(LatencyKey, c.now().Sub(start)),
+	)
+	if remote != "" {
+		attrs = append(attrs, slog.String(PeerKey, remote))
+	}
+	if mda := c.metadata(md); len(mda) > 0 {
+		attrs = append(attrs, slog.Group(MetadataKey, mda...))
+	}
+	out := []slog.Attr{slog.Group(GroupKey, attrs...)}
+	if err != nil {
+		out = append(out, slog.Any("error", err))
+	}
+	logger.WithCallerSkip(1).LogAttrs(ctx, level, msg, out...)
+}
+
+// metadata returns the configured metadata keys present in md as attributes.
+func (c *config) metadata(md metadata.MD) []any {
+	var attrs []any
+	for _, k := range c.metadataKeys {
+		if v := md.Get(k); len(v) > 0 {
+			attrs = append(attrs, slog.String(k, strings.Join(v, ",")))
+		}
+	}
+	return attrs
+}
+
+// splitMethod splits a full method name of the form /package.Service/Method.
+func splitMethod(fullMethod string) (string, string) {
+	s := strings.TrimPrefix(fullMethod, "/")
+	if i := strings.LastIndexByte(s, '/'); i >= 0 {
+		return s[:i], s[i+1:]
+	}
+	return "", s
+}
+
+// peerAddr returns the address of the peer carried by ctx.
+func peerAddr(ctx context.Context) string {
+	p, _ := peer.FromContext(ctx)
+	return addr(p)
+}
+
+// addr returns the address of p.
+func addr(p *peer.Peer) string {
+	if p == nil || p.Addr == nil {
+		return ""
+	}
+	return p.Addr.String()
+}
+
+// incoming returns the incoming metadata carried by ctx.
+func incoming(ctx context.Context) metadata.MD {
+	md, _ := metadata.FromIncomingContext(ctx)
+	return md
+}
+
+// outgoing returns the outgoing metadata carried by ctx.
+func outgoing(ctx context.Context) metadata.MD {
+	md, _ := metadata.FromOutgoingContext(ctx)
+	return md
+}
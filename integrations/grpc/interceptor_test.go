@@ -0,0 +1,201 @@
+package grpc
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/nekrassov01/logger/log"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// syncBuffer is a bytes.Buffer safe for concurrent use.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// newTestLogger returns a logger writing to buf without the latency attribute.
+func newTestLogger(buf io.Writer) *log.Logger {
+	return log.NewLogger(log.NewCLIHandler(buf,
+		log.WithStyle(log.Style0()),
+		log.WithLevel(slog.LevelDebug),
+		log.WithReplaceAttr(func(groups []string, a slog.Attr) slog.Attr {
+			if len(groups) == 1 && groups[0] == GroupKey && a.Key == LatencyKey {
+				return slog.Attr{}
+			}
+			return a
+		}),
+	))
+}
+
+// dial starts a health server with the interceptors and returns a client.
+func dial(t *testing.T, server, client *log.Logger, opts ...Option) healthpb.HealthClient {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer(
+		grpc.UnaryInterceptor(UnaryServerInterceptor(server, opts...)),
+		grpc.StreamInterceptor(StreamServerInterceptor(server, opts...)),
+	)
+	hs := health.NewServer()
+	hs.SetServingStatus("ok", healthpb.HealthCheckResponse_SERVING)
+	healthpb.RegisterHealthServer(srv, hs)
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return lis.Dial() }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithUnaryInterceptor(UnaryClientInterceptor(client, opts...)),
+		grpc.WithStreamInterceptor(StreamClientInterceptor(client, opts...)),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return healthpb.NewHealthClient(conn)
+}
+
+func TestUnaryInterceptors(t *testing.T) {
+	tests := []struct {
+		name       string
+		service    string
+		opts       []Option
+		md         metadata.MD
+		wantServer string
+		wantClient string
+	}{
+		{
+			name:       "ok",
+			service:    "ok",
+			wantServer: "[INF] finished unary call grpc.service=grpc.health.v1.Health grpc.method=Check grpc.code=OK grpc.peer=bufconn\n",
+			wantClient: "[INF] finished client unary call grpc.service=grpc.health.v1.Health grpc.method=Check grpc.code=OK grpc.peer=bufconn\n",
+		},
+		{
+			name:       "not found",
+			service:    "missing",
+			opts:       []Option{WithCodeToLevel(func(c codes.Code) slog.Level { return slog.LevelDebug })},
+			wantServer: "[DBG] finished unary call grpc.service=grpc.health.v1.Health grpc.method=Check grpc.code=NotFound grpc.peer=bufconn error=\"rpc error: code = NotFound desc = unknown service\"\n",
+			wantClient: "[DBG] finished client unary call grpc.service=grpc.health.v1.Health grpc.method=Check grpc.code=NotFound grpc.peer=bufconn error=\"rpc error: code = NotFound desc = unknown service\"\n",
+		},
+		{
+			name:       "metadata",
+			service:    "ok",
+			opts:       []Option{WithMetadataKeys("X-Request-ID", "missing")},
+			md:         metadata.Pairs("x-request-id", "r1", "x-request-id", "r2"),
+			wantServer: "[INF] finished unary call grpc.service=grpc.health.v1.Health grpc.method=Check grpc.code=OK grpc.peer=bufconn grpc.metadata.x-request-id=r1,r2\n",
+			wantClient: "[INF] finished client unary call grpc.service=grpc.health.v1.Health grpc.method=Check grpc.code=OK grpc.peer=bufconn grpc.metadata.x-request-id=r1,r2\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sbuf, cbuf := &syncBuffer{}, &syncBuffer{}
+			c := dial(t, newTestLogger(sbuf), newTestLogger(cbuf), tt.opts...)
+			ctx := context.Background()
+			if tt.md != nil {
+				ctx = metadata.NewOutgoingContext(ctx, tt.md)
+			}
+			_, _ = c.Check(ctx, &healthpb.HealthCheckRequest{Service: tt.service})
+			if got := sbuf.String(); got != tt.wantServer {
+				t.Errorf("server got %q, want %q", got, tt.wantServer)
+			}
+			if got := cbuf.String(); got != tt.wantClient {
+				t.Errorf("client got %q, want %q", got, tt.wantClient)
+			}
+		})
+	}
+}
+
+func TestStreamInterceptors(t *testing.T) {
+	sbuf, cbuf := &syncBuffer{}, &syncBuffer{}
+	c := dial(t, newTestLogger(sbuf), newTestLogger(cbuf))
+	ctx, cancel := context.WithCancel(context.Background())
+	stream, err := c.Watch(ctx, &healthpb.HealthCheckRequest{Service: "ok"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := stream.Recv(); err != nil {
+		t.Fatal(err)
+	}
+	cancel()
+	if _, err := stream.Recv(); status.Code(err) != codes.Canceled {
+		t.Fatalf("Recv() error = %v, want Canceled", err)
+	}
+	want := "[INF] finished client stream call grpc.service=grpc.health.v1.Health grpc.method=Watch grpc.code=Canceled grpc.peer=bufconn error="
+	if got := cbuf.String(); !strings.HasPrefix(got, want) {
+		t.Errorf("client got %q, want prefix %q", got, want)
+	}
+	if _, err := stream.Recv(); err == nil {
+		t.Fatal("Recv() error = nil after cancel")
+	}
+	if n := strings.Count(cbuf.String(), "\n"); n != 1 {
+		t.Errorf("client lines = %d, want 1", n)
+	}
+}
+
+func TestDefaultCodeToLevel(t *testing.T) {
+	tests := []struct {
+		code codes.Code
+		want slog.Level
+	}{
+		{code: codes.OK, want: slog.LevelInfo},
+		{code: codes.NotFound, want: slog.LevelInfo},
+		{code: codes.Unavailable, want: slog.LevelWarn},
+		{code: codes.Internal, want: slog.LevelError},
+		{code: codes.Unknown, want: slog.LevelError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.code.String(), func(t *testing.T) {
+			if got := DefaultCodeToLevel(tt.code); got != tt.want {
+				t.Errorf("DefaultCodeToLevel(%v) = %v, want %v", tt.code, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitMethod(t *testing.T) {
+	tests := []struct {
+		in, service, method string
+	}{
+		{in: "/pkg.Svc/Call", service: "pkg.Svc", method: "Call"},
+		{in: "Call", service: "", method: "Call"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			s, m := splitMethod(tt.in)
+			if s != tt.service || m != tt.method {
+				t.Errorf("splitMethod(%q) = %q, %q, want %q, %q", tt.in, s, m, tt.service, tt.method)
+			}
+		})
+	}
+}
+
+func TestNilLogger(t *testing.T) {
+	c := dial(t, nil, nil)
+	if _, err := c.Check(context.Background(), &healthpb.HealthCheckRequest{Service: "ok"}); err != nil {
+		t.Fatal(err)
+	}
+}
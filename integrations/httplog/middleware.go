@@ -0,0 +1,207 @@
+package httplog
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"log/slog"
+	"net"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/nekrassov01/logger/log"
+)
+
+// Attribute keys written by the middleware, inside the GroupKey group.
+const (
+	GroupKey    = "http"
+	MethodKey   = "method"
+	PathKey     = "path"
+	StatusKey   = "status"
+	BytesKey    = "bytes"
+	DurationKey = "duration"
+	RemoteKey   = "remote"
+)
+
+// contextKey is the context key for the request-scoped logger.
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying the logger.
+func NewContext(ctx context.Context, logger *log.Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, logger)
+}
+
+// FromContext returns the request-scoped logger carried by ctx, or a logger
+// discarding every record if there is none.
+func FromContext(ctx context.Context) *log.Logger {
+	if l, ok := ctx.Value(contextKey{}).(*log.Logger); ok {
+		return l
+	}
+	return log.NewLogger(nil)
+}
+
+// DefaultStatusToLevel maps a response status to a level: server errors are
+// logged at error, client errors at warn and others at info.
+func DefaultStatusToLevel(status int) slog.Level {
+	switch {
+	case status >= 500:
+		return slog.LevelError
+	case status >= 400:
+		return slog.LevelWarn
+	default:
+		return slog.LevelInfo
+	}
+}
+
+// config holds the options of the middleware.
+type config struct {
+	levelOf   func(status int) slog.Level
+	skip      map[string]bool
+	sampled   map[string]bool
+	sampleN   uint64
+	sampleCnt atomic.Uint64
+	now       func() time.Time
+}
+
+// Option defines a function type for configuring the middleware.
+type Option func(*config)
+
+// WithStatusToLevel returns an Option that sets the mapping from response
+// statuses to levels. The default is DefaultStatusToLevel.
+func WithStatusToLevel(fn func(status int) slog.Level) Option {
+	return func(c *config) {
+		if fn != nil {
+			c.levelOf = fn
+		}
+	}
+}
+
+// WithSkipPaths returns an Option that disables logging of requests to the
+// given paths. The request-scoped logger is still injected.
+func WithSkipPaths(paths ...string) Option {
+	return func(c *config) {
+		for _, p := range paths {
+			c.skip[p] = true
+		}
+	}
+}
+
+// WithSampledPaths returns an Option that logs only one of every n requests to
+// the given paths, such as health checks. Responses with a status of 400 or
+// above are always logged.
+func WithSampledPaths(n int, paths ...string) Option {
+	return func(c *config) {
+		if n < 1 {
+			return
+		}
+		c.sampleN = uint64(n)
+		for _, p := range paths {
+			c.sampled[p] = true
+		}
+	}
+}
+
+// Middleware returns a middleware that logs every request and injects a
+// request-scoped logger, retrievable with FromContext, into the request context.
+func Middleware(logger *log.Logger, opts ...Option) func(http.Handler) http.Handler {
+	if logger == nil {
+		logger = log.NewLogger(nil)
+	}
+	c := &config{
+		levelOf: DefaultStatusToLevel,
+		skip:    make(map[string]bool),
+		sampled: make(map[string]bool),
+		now:     time.Now,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := c.now()
+			path := r.URL.Path
+			rl := log.NewLogger(logger.Handler().WithAttrs([]slog.Attr{
+				slog.Group(GroupKey, slog.String(MethodKey, r.Method), slog.String(PathKey, path)),
+			}))
+			r = r.WithContext(NewContext(r.Context(), rl))
+			rw := &responseWriter{ResponseWriter: w}
+			next.ServeHTTP(rw, r)
+			if c.skip[path] {
+				return
+			}
+			status := rw.status
+			if status == 0 {
+				status = http.StatusOK
+			}
+			if c.sampled[path] && status < 400 && (c.sampleCnt.Add(1)-1)%c.sampleN != 0 {
+				return
+			}
+			level := c.levelOf(status)
+			ctx := r.Context()
+			if !logger.Enabled(ctx, level) {
+				return
+			}
+			logger.LogAttrs(ctx, level, "request", slog.Group(GroupKey,
+				slog.String(MethodKey, r.Method),
+				slog.String(PathKey, path),
+				slog.Int(StatusKey, status),
+				slog.Int64(BytesKey, rw.bytes),
+				slog.Duration(DurationKey, c.now().Sub(start)),
+				slog.String(RemoteKey, r.RemoteAddr),
+			))
+		})
+	}
+}
+
+// responseWriter is an http.ResponseWriter recording the status and the number of bytes written.
+type responseWriter struct {
+	http.ResponseWriter
+	status int
+	bytes  int64
+}
+
+// WriteHeader records the status and writes the header.
+func (w *responseWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+// Write records the number of bytes written.
+func (w *responseWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	n, err := w.ResponseWriter.Write(b)
+	w.bytes += int64(n)
+	return n, err
+}
+
+// Flush flushes the underlying writer if it supports flushing.
+func (w *responseWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		if w.status == 0 {
+			w.status = http.StatusOK
+		}
+		f.Flush()
+	}
+}
+
+// Hijack hijacks the underlying connection if the writer supports it.
+func (w *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("httplog: hijack not supported")
+	}
+	if w.status == 0 {
+		w.status = http.StatusSwitchingProtocols
+	}
+	return h.Hijack()
+}
+
+// Unwrap returns the underlying writer for http.ResponseController.
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
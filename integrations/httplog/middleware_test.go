@@ -0,0 +1,157 @@
+package httplog
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/nekrassov01/logger/log"
+)
+
+// newTestLogger returns a logger writing to buf without the duration attribute.
+func newTestLogger(buf io.Writer) *log.Logger {
+	return log.NewLogger(log.NewCLIHandler(buf,
+		log.WithStyle(log.Style0()),
+		log.WithReplaceAttr(func(groups []string, a slog.Attr) slog.Attr {
+			if len(groups) == 1 && groups[0] == GroupKey && a.Key == DurationKey {
+				return slog.Attr{}
+			}
+			return a
+		}),
+	))
+}
+
+func TestMiddleware(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		FromContext(r.Context()).Info("handling")
+		switch r.URL.Path {
+		case "/missing":
+			http.NotFound(w, r)
+		case "/fail":
+			w.WriteHeader(http.StatusInternalServerError)
+		case "/empty":
+		default:
+			io.WriteString(w, "hello")
+		}
+	})
+	tests := []struct {
+		name string
+		opts []Option
+		path string
+		want string
+	}{
+		{
+			name: "ok",
+			path: "/",
+			want: "[INF] handling http.method=GET http.path=/\n" +
+				"[INF] request http.method=GET http.path=/ http.status=200 http.bytes=5 http.remote=192.0.2.1:1234\n",
+		},
+		{
+			name: "implicit status",
+			path: "/empty",
+			want: "[INF] handling http.method=GET http.path=/empty\n" +
+				"[INF] request http.method=GET http.path=/empty http.status=200 http.bytes=0 http.remote=192.0.2.1:1234\n",
+		},
+		{
+			name: "client error",
+			path: "/missing",
+			want: "[INF] handling http.method=GET http.path=/missing\n" +
+				"[WRN] request http.method=GET http.path=/missing http.status=404 http.bytes=19 http.remote=192.0.2.1:1234\n",
+		},
+		{
+			name: "server error",
+			path: "/fail",
+			want: "[INF] handling http.method=GET http.path=/fail\n" +
+				"[ERR] request http.method=GET http.path=/fail http.status=500 http.bytes=0 http.remote=192.0.2.1:1234\n",
+		},
+		{
+			name: "status to level",
+			opts: []Option{WithStatusToLevel(func(int) slog.Level { return slog.LevelDebug })},
+			path: "/",
+			want: "[INF] handling http.method=GET http.path=/\n",
+		},
+		{
+			name: "skip",
+			opts: []Option{WithSkipPaths("/")},
+			path: "/",
+			want: "[INF] handling http.method=GET http.path=/\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			h := Middleware(newTestLogger(buf), tt.opts...)(handler)
+			h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, tt.path, nil))
+			if got := buf.String(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMiddleware_sampled(t *testing.T) {
+	buf := &bytes.Buffer{}
+	status := http.StatusOK
+	h := Middleware(newTestLogger(buf), WithSampledPaths(3, "/healthz"))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	}))
+	for range 7 {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	}
+	if n := strings.Count(buf.String(), "\n"); n != 3 {
+		t.Errorf("lines = %d, want 3", n)
+	}
+	buf.Reset()
+	status = http.StatusServiceUnavailable
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	if !strings.Contains(buf.String(), "http.status=503") {
+		t.Errorf("got %q, want failed health check logged", buf.String())
+	}
+}
+
+func TestMiddleware_nilLogger(t *testing.T) {
+	var got *log.Logger
+	h := Middleware(nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = FromContext(r.Context())
+	}))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	if got == nil {
+		t.Error("FromContext() = nil")
+	}
+}
+
+func TestFromContext(t *testing.T) {
+	if FromContext(context.Background()) == nil {
+		t.Error("FromContext() = nil, want discarding logger")
+	}
+	l := log.NewLogger(nil)
+	if got := FromContext(NewContext(context.Background(), l)); got != l {
+		t.Errorf("FromContext() = %p, want %p", got, l)
+	}
+}
+
+func TestResponseWriter(t *testing.T) {
+	rec := httptest.NewRecorder()
+	w := &responseWriter{ResponseWriter: rec}
+	w.WriteHeader(http.StatusCreated)
+	w.WriteHeader(http.StatusAccepted)
+	w.Write([]byte("abc"))
+	w.Flush()
+	if w.status != http.StatusCreated || w.bytes != 3 {
+		t.Errorf("status = %d, bytes = %d, want 201, 3", w.status, w.bytes)
+	}
+	if !rec.Flushed {
+		t.Error("Flush() not forwarded")
+	}
+	if w.Unwrap() != rec {
+		t.Error("Unwrap() != underlying writer")
+	}
+	if _, _, err := w.Hijack(); err == nil {
+		t.Error("Hijack() error = nil, want error")
+	}
+}
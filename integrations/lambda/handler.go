@@ -0,0 +1,255 @@
+package lambda
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync/atomic"
+
+	"github.com/aws/aws-lambda-go/lambdacontext"
+	"github.com/nekrassov01/logger/log"
+)
+
+var (
+	_ slog.Handler    = (*Handler)(nil)
+	_ log.LevelSetter = (*Handler)(nil)
+)
+
+// Keys of the Lambda Advanced Logging Controls JSON schema.
+// See: https://docs.aws.amazon.com/lambda/latest/dg/monitoring-cloudwatchlogs-advanced.html
+const (
+	TimestampKey = "timestamp"
+	LevelKey     = "level"
+	MessageKey   = "message"
+	RequestIDKey = "requestId"
+)
+
+// TimeFormat is the layout of the timestamp field.
+const TimeFormat = "2006-01-02T15:04:05.000Z"
+
+// LevelTrace is the slog level written as TRACE.
+const LevelTrace = slog.LevelDebug - 4
+
+// Format is the log format of a Handler.
+type Format int
+
+const (
+	// FormatJSON writes the Advanced Logging Controls JSON schema.
+	FormatJSON Format = iota
+
+	// FormatText writes the CLIHandler format without colors.
+	FormatText
+)
+
+// LevelString returns the Advanced Logging Controls name of a slog level.
+func LevelString(level slog.Level) string {
+	switch {
+	case level >= log.LevelFatal:
+		return "FATAL"
+	case level >= slog.LevelError:
+		return "ERROR"
+	case level >= slog.LevelWarn:
+		return "WARN"
+	case level >= slog.LevelInfo:
+		return "INFO"
+	case level >= slog.LevelDebug:
+		return "DEBUG"
+	default:
+		return "TRACE"
+	}
+}
+
+// ParseLevel parses an Advanced Logging Controls level name case-insensitively.
+func ParseLevel(s string) (slog.Level, bool) {
+	switch strings.ToUpper(s) {
+	case "TRACE":
+		return LevelTrace, true
+	case "DEBUG":
+		return slog.LevelDebug, true
+	case "INFO":
+		return slog.LevelInfo, true
+	case "WARN":
+		return slog.LevelWarn, true
+	case "ERROR":
+		return slog.LevelError, true
+	case "FATAL":
+		return log.LevelFatal, true
+	}
+	return 0, false
+}
+
+// Handler is a slog.Handler for Lambda functions. The request ID of the Lambda
+// context is written as a top-level field regardless of the open groups.
+type Handler struct {
+	base  slog.Handler
+	inner slog.Handler
+	goas  []groupOrAttrs
+	level *slog.LevelVar
+	cache *atomic.Pointer[requestHandler]
+}
+
+// requestHandler is the handler derived for the request ID of an invocation.
+type requestHandler struct {
+	id    string
+	inner slog.Handler
+}
+
+// groupOrAttrs is either a group name or a list of attributes added to a Handler.
+type groupOrAttrs struct {
+	group string
+	attrs []slog.Attr
+}
+
+// config holds the options of a Handler.
+type config struct {
+	w      io.Writer
+	level  slog.Level
+	format Format
+}
+
+// Option defines a function type for configuring a Handler.
+type Option func(*config)
+
+// WithWriter returns an Option that sets the output. The default is os.Stdout.
+func WithWriter(w io.Writer) Option {
+	return func(c *config) {
+		if w != nil {
+			c.w = w
+		}
+	}
+}
+
+// WithLevel returns an Option that sets the minimum level. The default is read
+// from AWS_LAMBDA_LOG_LEVEL, falling back to slog.LevelInfo.
+func WithLevel(level slog.Level) Option {
+	return func(c *config) {
+		c.level = level
+	}
+}
+
+// WithFormat returns an Option that sets the log format. The default is read
+// from AWS_LAMBDA_LOG_FORMAT, falling back to FormatJSON.
+func WithFormat(f Format) Option {
+	return func(c *config) {
+		c.format = f
+	}
+}
+
+// NewHandler creates a new Handler. Colors are always disabled.
+func NewHandler(opts ...Option) *Handler {
+	c := &config{w: os.Stdout, level: slog.LevelInfo}
+	if l, ok := ParseLevel(os.Getenv("AWS_LAMBDA_LOG_LEVEL")); ok {
+		c.level = l
+	}
+	if strings.EqualFold(os.Getenv("AWS_LAMBDA_LOG_FORMAT"), "text") {
+		c.format = FormatText
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	level := &slog.LevelVar{}
+	level.Set(c.level)
+	var base slog.Handler
+	if c.format == FormatText {
+		base = log.NewCLIHandler(c.w,
+			log.WithLevel(level),
+			log.WithTime(true),
+			log.WithColorMode(log.ColorNever),
+		)
+	} else {
+		base = slog.NewJSONHandler(c.w, &slog.HandlerOptions{
+			Level:       level,
+			ReplaceAttr: replace,
+		})
+	}
+	return &Handler{base: base, inner: base, level: level}
+}
+
+// replace renames the built-in attributes to the Advanced Logging Controls fields.
+func replace(groups []string, a slog.Attr) slog.Attr {
+	if len(groups) > 0 {
+		return a
+	}
+	switch a.Key {
+	case slog.TimeKey:
+		if a.Value.Kind() == slog.KindTime {
+			return slog.String(TimestampKey, a.Value.Time().UTC().Format(TimeFormat))
+		}
+	case slog.LevelKey:
+		if l, ok := a.Value.Any().(slog.Level); ok {
+			return slog.String(LevelKey, LevelString(l))
+		}
+	case slog.MessageKey:
+		return slog.Attr{Key: MessageKey, Value: a.Value}
+	}
+	return a
+}
+
+// Enabled reports whether the handler is enabled for the given level.
+func (h *Handler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.inner.Enabled(ctx, level)
+}
+
+// Handle writes the record with the request ID of the Lambda context, if any.
+// Under open groups the handler derived for a request ID is cached, so it is
+// built once per invocation rather than once per record.
+func (h *Handler) Handle(ctx context.Context, r slog.Record) error {
+	lc, ok := lambdacontext.FromContext(ctx)
+	if !ok || lc.AwsRequestID == "" {
+		return h.inner.Handle(ctx, r)
+	}
+	attr := slog.String(RequestIDKey, lc.AwsRequestID)
+	if len(h.goas) == 0 {
+		r = r.Clone()
+		r.AddAttrs(attr)
+		return h.inner.Handle(ctx, r)
+	}
+	if c := h.cache.Load(); c != nil && c.id == lc.AwsRequestID {
+		return c.inner.Handle(ctx, r)
+	}
+	inner := h.base.WithAttrs([]slog.Attr{attr})
+	for _, goa := range h.goas {
+		if goa.group != "" {
+			inner = inner.WithGroup(goa.group)
+		} else {
+			inner = inner.WithAttrs(goa.attrs)
+		}
+	}
+	h.cache.Store(&requestHandler{id: lc.AwsRequestID, inner: inner})
+	return inner.Handle(ctx, r)
+}
+
+// WithAttrs returns a new Handler with the given attributes.
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	return h.with(groupOrAttrs{attrs: attrs}, h.inner.WithAttrs(attrs))
+}
+
+// WithGroup returns a new Handler with the given group.
+func (h *Handler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return h.with(groupOrAttrs{group: name}, h.inner.WithGroup(name))
+}
+
+// with returns a copy of the handler with goa appended and the given inner handler.
+func (h *Handler) with(goa groupOrAttrs, inner slog.Handler) *Handler {
+	h2 := *h
+	h2.inner = inner
+	h2.goas = append(h.goas[:len(h.goas):len(h.goas)], goa)
+	h2.cache = &atomic.Pointer[requestHandler]{}
+	return &h2
+}
+
+// SetLevel changes the minimum level of the handler and its derived handlers.
+// A nil level is ignored.
+func (h *Handler) SetLevel(level slog.Leveler) {
+	if level != nil {
+		h.level.Set(level.Level())
+	}
+}
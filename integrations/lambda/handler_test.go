@@ -0,0 +1,185 @@
+package lambda
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-lambda-go/lambdacontext"
+	"github.com/nekrassov01/logger/log"
+)
+
+func TestLevelString(t *testing.T) {
+	tests := []struct {
+		level slog.Level
+		want  string
+	}{
+		{level: LevelTrace, want: "TRACE"},
+		{level: slog.LevelDebug, want: "DEBUG"},
+		{level: slog.LevelInfo, want: "INFO"},
+		{level: slog.LevelWarn, want: "WARN"},
+		{level: slog.LevelError, want: "ERROR"},
+		{level: log.LevelFatal, want: "FATAL"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			if got := LevelString(tt.level); got != tt.want {
+				t.Errorf("LevelString(%v) = %q, want %q", tt.level, got, tt.want)
+			}
+			if got, ok := ParseLevel(strings.ToLower(tt.want)); !ok || got != tt.level {
+				t.Errorf("ParseLevel(%q) = %v, %v, want %v", tt.want, got, ok, tt.level)
+			}
+		})
+	}
+	if _, ok := ParseLevel("verbose"); ok {
+		t.Error("ParseLevel(verbose) ok = true, want false")
+	}
+}
+
+func requestContext() context.Context {
+	return lambdacontext.NewContext(context.Background(), &lambdacontext.LambdaContext{AwsRequestID: "req-1"})
+}
+
+func TestHandler_Handle(t *testing.T) {
+	tests := []struct {
+		name   string
+		opts   []Option
+		env    map[string]string
+		ctx    context.Context
+		handle func(slog.Handler) slog.Handler
+		level  slog.Level
+		want   string
+	}{
+		{
+			name:  "json",
+			level: slog.LevelWarn,
+			want:  `{"timestamp":"2025-04-01T00:00:00.123Z","level":"WARN","message":"hello","k":"v"}`,
+		},
+		{
+			name:  "request id",
+			ctx:   requestContext(),
+			level: slog.LevelInfo,
+			want:  `{"timestamp":"2025-04-01T00:00:00.123Z","level":"INFO","message":"hello","k":"v","requestId":"req-1"}`,
+		},
+		{
+			name: "request id outside group",
+			ctx:  requestContext(),
+			handle: func(h slog.Handler) slog.Handler {
+				return h.WithGroup("g")
+			},
+			level: slog.LevelInfo,
+			want:  `{"timestamp":"2025-04-01T00:00:00.123Z","level":"INFO","message":"hello","requestId":"req-1","g":{"k":"v"}}`,
+		},
+		{
+			name:  "env level",
+			env:   map[string]string{"AWS_LAMBDA_LOG_LEVEL": "error"},
+			level: slog.LevelWarn,
+		},
+		{
+			name:  "option level",
+			env:   map[string]string{"AWS_LAMBDA_LOG_LEVEL": "error"},
+			opts:  []Option{WithLevel(LevelTrace)},
+			level: LevelTrace,
+			want:  `{"timestamp":"2025-04-01T00:00:00.123Z","level":"TRACE","message":"hello","k":"v"}`,
+		},
+		{
+			name:  "text",
+			env:   map[string]string{"AWS_LAMBDA_LOG_FORMAT": "Text"},
+			ctx:   requestContext(),
+			level: slog.LevelInfo,
+			want:  "INF hello time=2025-04-01T00:00:00Z k=v requestId=req-1",
+		},
+		{
+			name:  "option format",
+			env:   map[string]string{"AWS_LAMBDA_LOG_FORMAT": "Text"},
+			opts:  []Option{WithFormat(FormatJSON)},
+			level: slog.LevelInfo,
+			want:  `{"timestamp":"2025-04-01T00:00:00.123Z","level":"INFO","message":"hello","k":"v"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("AWS_LAMBDA_LOG_LEVEL", "")
+			t.Setenv("AWS_LAMBDA_LOG_FORMAT", "")
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+			buf := &bytes.Buffer{}
+			var h slog.Handler = NewHandler(append([]Option{WithWriter(buf)}, tt.opts...)...)
+			if tt.handle != nil {
+				h = tt.handle(h)
+			}
+			ctx := tt.ctx
+			if ctx == nil {
+				ctx = context.Background()
+			}
+			if h.Enabled(ctx, tt.level) {
+				r := slog.NewRecord(time.Date(2025, time.April, 1, 0, 0, 0, 123456789, time.UTC), tt.level, "hello", 0)
+				r.AddAttrs(slog.String("k", "v"))
+				if err := h.Handle(ctx, r); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if got := strings.TrimSuffix(buf.String(), "\n"); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHandler_SetLevel(t *testing.T) {
+	h := NewHandler(WithWriter(&bytes.Buffer{}))
+	h2 := h.WithAttrs([]slog.Attr{slog.Int("a", 1)})
+	h.SetLevel(slog.LevelError)
+	if h2.Enabled(context.Background(), slog.LevelWarn) {
+		t.Error("Enabled(WARN) = true, want false")
+	}
+}
+
+// countingHandler counts the calls to WithAttrs.
+type countingHandler struct {
+	slog.Handler
+	n *int
+}
+
+func (h countingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	*h.n++
+	return countingHandler{h.Handler.WithAttrs(attrs), h.n}
+}
+
+func (h countingHandler) WithGroup(name string) slog.Handler {
+	return countingHandler{h.Handler.WithGroup(name), h.n}
+}
+
+func TestHandler_Handle_cache(t *testing.T) {
+	buf := &bytes.Buffer{}
+	n := 0
+	h := NewHandler(WithWriter(buf))
+	h.base = countingHandler{h.base, &n}
+	h.inner = h.base
+	l := slog.New(h).WithGroup("g")
+	ctx1 := requestContext()
+	ctx2 := lambdacontext.NewContext(context.Background(), &lambdacontext.LambdaContext{AwsRequestID: "req-2"})
+	for range 3 {
+		l.InfoContext(ctx1, "hello", "k", "v")
+	}
+	if n != 1 {
+		t.Errorf("WithAttrs calls = %d, want 1", n)
+	}
+	l.InfoContext(ctx2, "hello", "k", "v")
+	if n != 2 {
+		t.Errorf("WithAttrs calls = %d, want 2", n)
+	}
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("got %d lines, want 4", len(lines))
+	}
+	for i, want := range []string{"req-1", "req-1", "req-1", "req-2"} {
+		if !strings.Contains(lines[i], `"requestId":"`+want+`","g":{"k":"v"}`) {
+			t.Errorf("line %d = %s, want requestId %s", i, lines[i], want)
+		}
+	}
+}
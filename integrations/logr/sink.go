@@ -0,0 +1,173 @@
+package logr
+
+import (
+	"context"
+	"log/slog"
+	"runtime"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/nekrassov01/logger/log"
+)
+
+var (
+	_ logr.LogSink          = (*LogSink)(nil)
+	_ logr.CallDepthLogSink = (*LogSink)(nil)
+)
+
+// ErrorKey is the key of the error passed to LogSink.Error.
+const ErrorKey = "error"
+
+// DefaultVToLevel maps a logr verbosity to a level: V(0) is slog.LevelInfo and
+// each higher verbosity is one level lower, so V(4) is slog.LevelDebug.
+func DefaultVToLevel(v int) slog.Level {
+	return slog.LevelInfo - slog.Level(v)
+}
+
+// LogSink is a logr.LogSink that passes each message to a slog.Handler as a record.
+// Names open groups and key-value pairs become attributes.
+type LogSink struct {
+	handler slog.Handler
+	config  *config
+	name    string
+	depth   int
+}
+
+// config holds the options of a LogSink.
+type config struct {
+	levelOf func(v int) slog.Level
+	nameKey string
+}
+
+// Option defines a function type for configuring a LogSink.
+type Option func(*config)
+
+// WithVToLevel returns an Option that sets the mapping from logr verbosities to
+// levels. The default is DefaultVToLevel.
+func WithVToLevel(fn func(v int) slog.Level) Option {
+	return func(c *config) {
+		if fn != nil {
+			c.levelOf = fn
+		}
+	}
+}
+
+// WithNameKey returns an Option that writes the logger name as a top-level
+// attribute with the given key instead of opening a group per name. Names are
+// joined with "/" as in the logr convention.
+func WithNameKey(key string) Option {
+	return func(c *config) {
+		c.nameKey = key
+	}
+}
+
+// NewLogSink creates a new LogSink writing to handler. A nil handler discards all messages.
+func NewLogSink(handler slog.Handler, opts ...Option) *LogSink {
+	if handler == nil {
+		handler = log.NewCLIHandler(nil)
+	}
+	c := &config{levelOf: DefaultVToLevel}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return &LogSink{handler: handler, config: c}
+}
+
+// NewLogger creates a new logr.Logger backed by a LogSink writing to handler.
+func NewLogger(handler slog.Handler, opts ...Option) logr.Logger {
+	return logr.New(NewLogSink(handler, opts...))
+}
+
+// Init receives the call depth of the logr.Logger wrapping the sink.
+func (s *LogSink) Init(info logr.RuntimeInfo) {
+	s.depth = info.CallDepth
+}
+
+// Enabled reports whether the handler is enabled for the level of verbosity v.
+func (s *LogSink) Enabled(v int) bool {
+	return s.handler.Enabled(context.Background(), s.config.levelOf(v))
+}
+
+// Info logs a message at the level of verbosity v.
+func (s *LogSink) Info(v int, msg string, keysAndValues ...any) {
+	s.log(s.config.levelOf(v), msg, nil, keysAndValues)
+}
+
+// Error logs an error message at slog.LevelError.
+func (s *LogSink) Error(err error, msg string, keysAndValues ...any) {
+	s.log(slog.LevelError, msg, err, keysAndValues)
+}
+
+// WithValues returns a new LogSink with the key-value pairs added as attributes.
+func (s *LogSink) WithValues(keysAndValues ...any) logr.LogSink {
+	attrs := toAttrs(keysAndValues)
+	if len(attrs) == 0 {
+		return s
+	}
+	s2 := *s
+	s2.handler = s.handler.WithAttrs(attrs)
+	return &s2
+}
+
+// WithName returns a new LogSink with the name appended. The name opens a group
+// unless WithNameKey is set.
+func (s *LogSink) WithName(name string) logr.LogSink {
+	if name == "" {
+		return s
+	}
+	s2 := *s
+	switch {
+	case s.config.nameKey == "":
+		s2.handler = s.handler.WithGroup(name)
+	case s.name == "":
+		s2.name = name
+	default:
+		s2.name = s.name + "/" + name
+	}
+	return &s2
+}
+
+// WithCallDepth returns a new LogSink reporting the caller depth frames further up the stack.
+func (s *LogSink) WithCallDepth(depth int) logr.LogSink {
+	s2 := *s
+	s2.depth += depth
+	return &s2
+}
+
+// log writes a record at level if the handler is enabled for it.
+func (s *LogSink) log(level slog.Level, msg string, err error, keysAndValues []any) {
+	ctx := context.Background()
+	if !s.handler.Enabled(ctx, level) {
+		return
+	}
+	var pcs [1]uintptr
+	runtime.Callers(3+s.depth, pcs[:])
+	r := slog.NewRecord(time.Now(), level, msg, pcs[0])
+	if s.name != "" {
+		r.AddAttrs(slog.String(s.config.nameKey, s.name))
+	}
+	if err != nil {
+		r.AddAttrs(slog.Any(ErrorKey, err))
+	}
+	r.AddAttrs(toAttrs(keysAndValues)...)
+	_ = s.handler.Handle(ctx, r)
+}
+
+// toAttrs converts key-value pairs to attributes with the semantics of
+// slog.Logger.Log, resolving logr.Marshaler values.
+func toAttrs(keysAndValues []any) []slog.Attr {
+	if len(keysAndValues) == 0 {
+		return nil
+	}
+	var r slog.Record
+	r.Add(keysAndValues...)
+	attrs := make([]slog.Attr, 0, r.NumAttrs())
+	r.Attrs(func(a slog.Attr) bool {
+		if m, ok := a.Value.Any().(logr.Marshaler); ok && a.Value.Kind() == slog.KindAny {
+			a.Value = slog.AnyValue(m.MarshalLog())
+		}
+		attrs = append(attrs, a)
+		return true
+	})
+	return attrs
+}
@@ -0,0 +1,124 @@
+package logr
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/nekrassov01/logger/log"
+)
+
+// newTestHandler returns a debug handler writing to buf.
+func newTestHandler(buf io.Writer, opts ...log.CLIHandlerOption) slog.Handler {
+	return log.NewCLIHandler(buf, append([]log.CLIHandlerOption{
+		log.WithStyle(log.Style0()),
+		log.WithLevel(slog.LevelDebug),
+	}, opts...)...)
+}
+
+// marshaler is a logr.Marshaler.
+type marshaler struct{}
+
+func (marshaler) MarshalLog() any { return "marshaled" }
+
+func TestLogSink(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []Option
+		run  func(l logr.Logger)
+		want string
+	}{
+		{
+			name: "info",
+			run:  func(l logr.Logger) { l.Info("hello", "a", 1, "b", "x") },
+			want: "[INF] hello a=1 b=x\n",
+		},
+		{
+			name: "verbosity",
+			run: func(l logr.Logger) {
+				l.V(1).Info("one")
+				l.V(4).Info("four")
+				l.V(5).Info("five")
+			},
+			want: "[DBG] one\n[DBG] four\n",
+		},
+		{
+			name: "v to level",
+			opts: []Option{WithVToLevel(func(v int) slog.Level {
+				if v > 0 {
+					return slog.LevelDebug
+				}
+				return slog.LevelInfo
+			})},
+			run:  func(l logr.Logger) { l.V(1).Info("one") },
+			want: "[DBG] one\n",
+		},
+		{
+			name: "error",
+			run:  func(l logr.Logger) { l.Error(errors.New("boom"), "failed", "a", 1) },
+			want: "[ERR] failed error=boom a=1\n",
+		},
+		{
+			name: "nil error",
+			run:  func(l logr.Logger) { l.Error(nil, "failed") },
+			want: "[ERR] failed\n",
+		},
+		{
+			name: "values",
+			run:  func(l logr.Logger) { l.WithValues("a", 1).Info("hello", "b", 2) },
+			want: "[INF] hello a=1 b=2\n",
+		},
+		{
+			name: "names",
+			run:  func(l logr.Logger) { l.WithName("manager").WithName("controller").Info("hello", "a", 1) },
+			want: "[INF] hello manager.controller.a=1\n",
+		},
+		{
+			name: "name key",
+			opts: []Option{WithNameKey("logger")},
+			run:  func(l logr.Logger) { l.WithName("manager").WithName("controller").Info("hello", "a", 1) },
+			want: "[INF] hello logger=manager/controller a=1\n",
+		},
+		{
+			name: "bad key",
+			run:  func(l logr.Logger) { l.Info("hello", "a") },
+			want: "[INF] hello !BADKEY=a\n",
+		},
+		{
+			name: "marshaler",
+			run:  func(l logr.Logger) { l.Info("hello", "m", marshaler{}) },
+			want: "[INF] hello m=marshaled\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			tt.run(NewLogger(newTestHandler(&buf), tt.opts...))
+			if got := buf.String(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLogSink_caller(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewLogger(newTestHandler(&buf, log.WithCaller(true)))
+	l.Info("hello")
+	l.WithCallDepth(0).Error(nil, "failed")
+	helper := func() { l.WithCallDepth(1).Info("helper") }
+	helper()
+	for _, line := range strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n") {
+		if !strings.Contains(line, "sink_test.go") {
+			t.Errorf("got %q, want caller in sink_test.go", line)
+		}
+	}
+}
+
+func TestNewLogSink_nilHandler(t *testing.T) {
+	NewLogger(nil).Info("hello")
+}
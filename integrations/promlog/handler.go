@@ -0,0 +1,230 @@
+package promlog
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/nekrassov01/logger/log"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	_ slog.Handler         = (*Handler)(nil)
+	_ prometheus.Collector = (*Handler)(nil)
+)
+
+// Names of the metrics, prefixed with the namespace.
+const (
+	RecordsName  = "records_total"
+	DurationName = "handle_duration_seconds"
+	DroppedName  = "dropped_records_total"
+)
+
+// Label names of the metrics.
+const (
+	LevelLabel   = "level"
+	HandlerLabel = "handler"
+)
+
+// DefaultBuckets are the buckets of the Handle latency histogram, from 1µs to about 0.26s.
+var DefaultBuckets = prometheus.ExponentialBuckets(1e-6, 4, 10)
+
+// Dropper is implemented by handlers that count the records they drop, such
+// as log.AsyncHandler and log.SamplingHandler.
+type Dropper interface {
+	Dropped() uint64
+}
+
+// LevelLabelOf returns the value of the level label of a record level.
+func LevelLabelOf(level slog.Level) string {
+	switch {
+	case level >= log.LevelFatal:
+		return "fatal"
+	case level >= slog.LevelError:
+		return "error"
+	case level >= slog.LevelWarn:
+		return "warn"
+	case level >= slog.LevelInfo:
+		return "info"
+	default:
+		return "debug"
+	}
+}
+
+// Handler is a slog.Handler that counts the records passed to the inner handler
+// and measures how long the inner handler takes to handle them. It is also a
+// prometheus.Collector exporting these metrics along with the records dropped
+// by the Dropper handlers it wraps, found through their Unwrap() slog.Handler
+// or Handlers() []slog.Handler methods.
+type Handler struct {
+	inner slog.Handler
+	state *state
+}
+
+// state is the state shared by a Handler and its derived handlers.
+type state struct {
+	root        slog.Handler
+	namespace   string
+	constLabels prometheus.Labels
+	buckets     []float64
+	records     *prometheus.CounterVec
+	duration    prometheus.Histogram
+	dropped     *prometheus.Desc
+}
+
+// Option defines a function type for configuring a Handler.
+type Option func(*state)
+
+// WithNamespace returns an Option that sets the namespace of the metric names.
+// The default is "logger".
+func WithNamespace(ns string) Option {
+	return func(s *state) {
+		s.namespace = ns
+	}
+}
+
+// WithConstLabels returns an Option that sets labels added to every metric,
+// typically to tell apart the loggers of a process.
+func WithConstLabels(labels prometheus.Labels) Option {
+	return func(s *state) {
+		s.constLabels = labels
+	}
+}
+
+// WithBuckets returns an Option that sets the buckets of the Handle latency
+// histogram, in seconds. The default is DefaultBuckets.
+func WithBuckets(buckets []float64) Option {
+	return func(s *state) {
+		if len(buckets) > 0 {
+			s.buckets = buckets
+		}
+	}
+}
+
+// NewHandler creates a new Handler wrapping inner. The handler must be
+// registered with a prometheus.Registerer for its metrics to be exported.
+func NewHandler(inner slog.Handler, opts ...Option) *Handler {
+	if inner == nil {
+		inner = log.NewCLIHandler(nil)
+	}
+	s := &state{root: inner, namespace: "logger", buckets: DefaultBuckets}
+	for _, opt := range opts {
+		opt(s)
+	}
+	s.records = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace:   s.namespace,
+		Name:        RecordsName,
+		Help:        "Number of records handled, by level.",
+		ConstLabels: s.constLabels,
+	}, []string{LevelLabel})
+	s.duration = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace:   s.namespace,
+		Name:        DurationName,
+		Help:        "Time spent by the inner handler handling a record.",
+		ConstLabels: s.constLabels,
+		Buckets:     s.buckets,
+	})
+	s.dropped = prometheus.NewDesc(
+		prometheus.BuildFQName(s.namespace, "", DroppedName),
+		"Number of records dropped by the wrapped handlers, by handler type.",
+		[]string{HandlerLabel},
+		s.constLabels,
+	)
+	return &Handler{inner: inner, state: s}
+}
+
+// Enabled reports whether the inner handler is enabled for the given level.
+func (h *Handler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.inner.Enabled(ctx, level)
+}
+
+// Handle counts the record and passes it to the inner handler.
+func (h *Handler) Handle(ctx context.Context, r slog.Record) error {
+	s := h.state
+	s.records.WithLabelValues(LevelLabelOf(r.Level)).Inc()
+	start := time.Now()
+	err := h.inner.Handle(ctx, r)
+	s.duration.Observe(time.Since(start).Seconds())
+	return err
+}
+
+// WithAttrs returns a new Handler whose inner handler has the given attributes.
+// The metrics are shared with the receiver.
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	return &Handler{inner: h.inner.WithAttrs(attrs), state: h.state}
+}
+
+// WithGroup returns a new Handler whose inner handler has the given group.
+// The metrics are shared with the receiver.
+func (h *Handler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return &Handler{inner: h.inner.WithGroup(name), state: h.state}
+}
+
+// Unwrap returns the inner handler.
+func (h *Handler) Unwrap() slog.Handler {
+	return h.inner
+}
+
+// Describe sends the descriptors of the metrics.
+func (h *Handler) Describe(ch chan<- *prometheus.Desc) {
+	s := h.state
+	s.records.Describe(ch)
+	s.duration.Describe(ch)
+	ch <- s.dropped
+}
+
+// Collect sends the current values of the metrics. The records dropped by
+// handlers of the same type are summed.
+func (h *Handler) Collect(ch chan<- prometheus.Metric) {
+	s := h.state
+	s.records.Collect(ch)
+	s.duration.Collect(ch)
+	for name, n := range droppedByType(s.root) {
+		ch <- prometheus.MustNewConstMetric(s.dropped, prometheus.CounterValue, float64(n), name)
+	}
+}
+
+// droppedByType returns the records dropped by the Dropper handlers in the
+// tree of handler, keyed by handler type.
+func droppedByType(handler slog.Handler) map[string]uint64 {
+	counts := make(map[string]uint64)
+	var walk func(h slog.Handler)
+	walk = func(h slog.Handler) {
+		if h == nil {
+			return
+		}
+		if d, ok := h.(Dropper); ok {
+			counts[handlerType(h)] += d.Dropped()
+		}
+		switch u := h.(type) {
+		case interface{ Unwrap() slog.Handler }:
+			walk(u.Unwrap())
+		case interface{ Handlers() []slog.Handler }:
+			for _, c := range u.Handlers() {
+				walk(c)
+			}
+		}
+	}
+	walk(handler)
+	return counts
+}
+
+// handlerType returns the value of the handler label of a handler.
+func handlerType(h slog.Handler) string {
+	switch h.(type) {
+	case *log.AsyncHandler:
+		return "async"
+	case *log.SamplingHandler:
+		return "sampling"
+	default:
+		return fmt.Sprintf("%T", h)
+	}
+}
@@ -0,0 +1,120 @@
+package promlog
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nekrassov01/logger/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestLevelLabelOf(t *testing.T) {
+	tests := []struct {
+		level slog.Level
+		want  string
+	}{
+		{level: slog.LevelDebug - 4, want: "debug"},
+		{level: slog.LevelDebug, want: "debug"},
+		{level: slog.LevelInfo, want: "info"},
+		{level: slog.LevelWarn, want: "warn"},
+		{level: slog.LevelError, want: "error"},
+		{level: log.LevelFatal, want: "fatal"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.level.String(), func(t *testing.T) {
+			if got := LevelLabelOf(tt.level); got != tt.want {
+				t.Errorf("LevelLabelOf(%v) = %q, want %q", tt.level, got, tt.want)
+			}
+		})
+	}
+}
+
+// dropHandler is a handler reporting a fixed number of dropped records.
+type dropHandler struct {
+	slog.Handler
+	dropped uint64
+}
+
+func (h *dropHandler) Dropped() uint64 {
+	return h.dropped
+}
+
+// errHandler is a handler failing every record.
+type errHandler struct {
+	slog.Handler
+}
+
+func (h *errHandler) Handle(context.Context, slog.Record) error {
+	return errors.New("failed")
+}
+
+func TestHandler_Collect(t *testing.T) {
+	text := slog.NewTextHandler(io.Discard, &slog.HandlerOptions{Level: slog.LevelDebug})
+	sampling := log.NewSamplingHandler(text, log.WithBurst(1), log.WithPerInterval(time.Hour))
+	inner := log.NewMultiHandler(
+		log.NewFilterHandler(sampling, nil),
+		&dropHandler{Handler: text, dropped: 3},
+		&dropHandler{Handler: text, dropped: 4},
+	)
+	h := NewHandler(inner, WithNamespace("app"), WithConstLabels(prometheus.Labels{"name": "cli"}))
+	reg := prometheus.NewPedanticRegistry()
+	if err := reg.Register(h); err != nil {
+		t.Fatal(err)
+	}
+	l := log.NewLogger(h.WithAttrs([]slog.Attr{slog.Int("a", 1)}))
+	l.Debug("d")
+	for range 3 {
+		l.Info("repeated")
+	}
+	l.Error("e")
+
+	want := `
+# HELP app_dropped_records_total Number of records dropped by the wrapped handlers, by handler type.
+# TYPE app_dropped_records_total counter
+app_dropped_records_total{handler="*promlog.dropHandler",name="cli"} 7
+app_dropped_records_total{handler="sampling",name="cli"} 2
+# HELP app_records_total Number of records handled, by level.
+# TYPE app_records_total counter
+app_records_total{level="debug",name="cli"} 1
+app_records_total{level="error",name="cli"} 1
+app_records_total{level="info",name="cli"} 3
+`
+	if err := testutil.GatherAndCompare(reg, strings.NewReader(want), "app_records_total", "app_dropped_records_total"); err != nil {
+		t.Error(err)
+	}
+	mfs, err := reg.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, mf := range mfs {
+		if mf.GetName() != "app_handle_duration_seconds" {
+			continue
+		}
+		if got := mf.GetMetric()[0].GetHistogram().GetSampleCount(); got != 5 {
+			t.Errorf("duration samples = %d, want 5", got)
+		}
+	}
+}
+
+func TestHandler_Handle(t *testing.T) {
+	h := NewHandler(&errHandler{Handler: slog.NewTextHandler(io.Discard, nil)}, WithBuckets([]float64{1}))
+	ctx := context.Background()
+	if h.Enabled(ctx, slog.LevelDebug) || !h.Enabled(ctx, slog.LevelInfo) {
+		t.Error("Enabled does not follow the inner handler")
+	}
+	if err := h.Handle(ctx, slog.NewRecord(time.Now(), slog.LevelWarn, "msg", 0)); err == nil {
+		t.Error("error of the inner handler is not returned")
+	}
+	if got := testutil.ToFloat64(h.state.records.WithLabelValues("warn")); got != 1 {
+		t.Errorf("warn records = %v, want 1", got)
+	}
+	if h.Unwrap() == nil || NewHandler(nil).Unwrap() == nil {
+		t.Error("Unwrap returned nil")
+	}
+}
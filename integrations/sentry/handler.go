@@ -0,0 +1,284 @@
+package sentry
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"math/rand/v2"
+	"slices"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/nekrassov01/logger/log"
+)
+
+var (
+	_ slog.Handler    = (*Handler)(nil)
+	_ log.LevelSetter = (*Handler)(nil)
+	_ log.Flusher     = (*Handler)(nil)
+)
+
+// LoggerName is the logger name set on every event.
+const LoggerName = "slog"
+
+// ErrFlushTimeout is returned when the queued events are not sent within the flush timeout.
+var ErrFlushTimeout = errors.New("sentry: flush timed out")
+
+// LevelOf maps a slog level to a Sentry level. log.LevelFatal and above map to fatal.
+func LevelOf(level slog.Level) sentry.Level {
+	switch {
+	case level >= log.LevelFatal:
+		return sentry.LevelFatal
+	case level >= slog.LevelError:
+		return sentry.LevelError
+	case level >= slog.LevelWarn:
+		return sentry.LevelWarning
+	case level >= slog.LevelInfo:
+		return sentry.LevelInfo
+	default:
+		return sentry.LevelDebug
+	}
+}
+
+// DefaultFingerprint groups events by record message, so that records logged
+// with the same message and different attributes form a single issue.
+func DefaultFingerprint(r slog.Record) []string {
+	return []string{r.Message}
+}
+
+// Handler is a slog.Handler that reports records to Sentry as events. The
+// attributes are sent as extra data, or as tags when their key is registered
+// with WithTagKeys; the first error attribute becomes the event exception.
+// It is typically combined with a console handler through log.NewMultiHandler.
+type Handler struct {
+	prefix string
+	fields []field
+	state  *state
+}
+
+// field is a flattened attribute with its dotted key.
+type field struct {
+	key   string
+	value slog.Value
+}
+
+// state is the state shared by a Handler and its derived handlers.
+type state struct {
+	hub          *sentry.Hub
+	level        *slog.LevelVar
+	tagKeys      map[string]bool
+	fingerprint  func(r slog.Record) []string
+	sampleRate   float64
+	flushTimeout time.Duration
+	random       func() float64
+}
+
+// Option defines a function type for configuring a Handler.
+type Option func(*state)
+
+// WithLevel returns an Option that sets the minimum level. The default is slog.LevelError.
+func WithLevel(level slog.Level) Option {
+	return func(s *state) {
+		s.level.Set(level)
+	}
+}
+
+// WithTagKeys returns an Option that sets the attribute keys sent as tags.
+// Keys of attributes inside groups are dotted, as in "req.method".
+func WithTagKeys(keys ...string) Option {
+	return func(s *state) {
+		for _, k := range keys {
+			s.tagKeys[k] = true
+		}
+	}
+}
+
+// WithFingerprint returns an Option that sets the function computing the
+// fingerprint grouping events into issues. The default is DefaultFingerprint;
+// a function returning nil leaves the grouping to Sentry.
+func WithFingerprint(fn func(r slog.Record) []string) Option {
+	return func(s *state) {
+		if fn != nil {
+			s.fingerprint = fn
+		}
+	}
+}
+
+// WithSampleRate returns an Option that sets the fraction of records reported,
+// between 0 and 1. Records at log.LevelFatal and above are always reported.
+// The default is 1.
+func WithSampleRate(rate float64) Option {
+	return func(s *state) {
+		if rate >= 0 && rate <= 1 {
+			s.sampleRate = rate
+		}
+	}
+}
+
+// WithFlushTimeout returns an Option that sets how long Flush, and Handle for
+// records at log.LevelFatal and above, wait for queued events to be sent.
+// The default is 2 seconds.
+func WithFlushTimeout(d time.Duration) Option {
+	return func(s *state) {
+		if d > 0 {
+			s.flushTimeout = d
+		}
+	}
+}
+
+// NewHandler creates a new Handler reporting to hub. A nil hub is replaced by
+// sentry.CurrentHub. The hub carried by the context of a record, as set by the
+// Sentry HTTP middleware, takes precedence.
+func NewHandler(hub *sentry.Hub, opts ...Option) *Handler {
+	if hub == nil {
+		hub = sentry.CurrentHub()
+	}
+	s := &state{
+		hub:          hub,
+		level:        &slog.LevelVar{},
+		tagKeys:      make(map[string]bool),
+		fingerprint:  DefaultFingerprint,
+		sampleRate:   1,
+		flushTimeout: 2 * time.Second,
+		random:       rand.Float64,
+	}
+	s.level.Set(slog.LevelError)
+	for _, opt := range opts {
+		opt(s)
+	}
+	return &Handler{state: s}
+}
+
+// Enabled reports whether the handler is enabled for the given level.
+func (h *Handler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.state.level.Level()
+}
+
+// Handle reports the record as an event. Records at log.LevelFatal and above
+// are sent before Handle returns, since the program is about to exit.
+func (h *Handler) Handle(ctx context.Context, r slog.Record) error {
+	s := h.state
+	fatal := r.Level >= log.LevelFatal
+	if !fatal && s.sampleRate < 1 && s.random() >= s.sampleRate {
+		return nil
+	}
+	hub := sentry.GetHubFromContext(ctx)
+	if hub == nil {
+		hub = s.hub
+	}
+	hub.CaptureEvent(h.event(r))
+	if fatal {
+		return flush(hub, s.flushTimeout)
+	}
+	return nil
+}
+
+// WithAttrs returns a new Handler with the given attributes.
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	h2 := *h
+	h2.fields = slices.Clip(h.fields)
+	for _, a := range attrs {
+		h2.fields = appendFields(h2.fields, h.prefix, a)
+	}
+	return &h2
+}
+
+// WithGroup returns a new Handler with the given group.
+func (h *Handler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	h2 := *h
+	h2.prefix = h.prefix + name + "."
+	return &h2
+}
+
+// SetLevel changes the minimum level of the handler and its derived handlers.
+// A nil level is ignored.
+func (h *Handler) SetLevel(level slog.Leveler) {
+	if level != nil {
+		h.state.level.Set(level.Level())
+	}
+}
+
+// Flush waits for the queued events to be sent, for at most the flush timeout.
+func (h *Handler) Flush() error {
+	return flush(h.state.hub, h.state.flushTimeout)
+}
+
+// event builds the event of a record.
+func (h *Handler) event(r slog.Record) *sentry.Event {
+	s := h.state
+	ev := sentry.NewEvent()
+	ev.Level = LevelOf(r.Level)
+	ev.Message = r.Message
+	ev.Logger = LoggerName
+	if !r.Time.IsZero() {
+		ev.Timestamp = r.Time
+	}
+	ev.Fingerprint = s.fingerprint(r)
+	fields := h.fields
+	r.Attrs(func(a slog.Attr) bool {
+		fields = appendFields(slices.Clip(fields), h.prefix, a)
+		return true
+	})
+	for _, f := range fields {
+		if err, ok := f.value.Any().(error); ok {
+			if ev.Exception == nil {
+				ev.SetException(err, -1)
+			}
+			ev.Extra[f.key] = err.Error()
+			continue
+		}
+		if s.tagKeys[f.key] {
+			ev.Tags[f.key] = f.value.String()
+			continue
+		}
+		ev.Extra[f.key] = extraValue(f.value)
+	}
+	return ev
+}
+
+// appendFields appends the flattened attribute to fields. Groups are expanded
+// into dotted keys, and empty attributes are skipped.
+func appendFields(fields []field, prefix string, a slog.Attr) []field {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return fields
+	}
+	if a.Value.Kind() == slog.KindGroup {
+		if a.Key != "" {
+			prefix += a.Key + "."
+		}
+		for _, ga := range a.Value.Group() {
+			fields = appendFields(fields, prefix, ga)
+		}
+		return fields
+	}
+	return append(fields, field{key: prefix + a.Key, value: a.Value})
+}
+
+// extraValue returns the value of an extra data entry.
+func extraValue(v slog.Value) any {
+	switch v.Kind() {
+	case slog.KindDuration, slog.KindTime:
+		return v.String()
+	default:
+		return v.Any()
+	}
+}
+
+// flush waits for the queued events of hub to be sent. A hub without a client
+// has nothing to send.
+func flush(hub *sentry.Hub, timeout time.Duration) error {
+	if hub.Client() == nil {
+		return nil
+	}
+	if !hub.Flush(timeout) {
+		return ErrFlushTimeout
+	}
+	return nil
+}
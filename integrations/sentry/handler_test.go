@@ -0,0 +1,240 @@
+package sentry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/nekrassov01/logger/log"
+)
+
+func TestLevelOf(t *testing.T) {
+	tests := []struct {
+		level slog.Level
+		want  sentry.Level
+	}{
+		{level: slog.LevelDebug, want: sentry.LevelDebug},
+		{level: slog.LevelInfo, want: sentry.LevelInfo},
+		{level: slog.LevelWarn, want: sentry.LevelWarning},
+		{level: slog.LevelError, want: sentry.LevelError},
+		{level: log.LevelFatal, want: sentry.LevelFatal},
+	}
+	for _, tt := range tests {
+		t.Run(tt.level.String(), func(t *testing.T) {
+			if got := LevelOf(tt.level); got != tt.want {
+				t.Errorf("LevelOf(%v) = %q, want %q", tt.level, got, tt.want)
+			}
+		})
+	}
+}
+
+// flushTransport is a sentry.Transport recording events and flushes.
+type flushTransport struct {
+	sentry.MockTransport
+	flushes int
+	ok      bool
+}
+
+func (t *flushTransport) Flush(time.Duration) bool {
+	t.flushes++
+	return t.ok
+}
+
+func (t *flushTransport) FlushWithContext(context.Context) bool {
+	return t.Flush(0)
+}
+
+func newTestHub(t *testing.T) (*sentry.Hub, *flushTransport) {
+	t.Helper()
+	tr := &flushTransport{ok: true}
+	client, err := sentry.NewClient(sentry.ClientOptions{Transport: tr})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sentry.NewHub(client, sentry.NewScope()), tr
+}
+
+func TestHandler_Handle(t *testing.T) {
+	errBoom := errors.New("boom")
+	tests := []struct {
+		name   string
+		opts   []Option
+		handle func(slog.Handler) slog.Handler
+		level  slog.Level
+		attrs  []slog.Attr
+		check  func(*testing.T, *sentry.Event)
+	}{
+		{
+			name:  "default",
+			level: slog.LevelError,
+			attrs: []slog.Attr{slog.Int("n", 1), slog.Duration("d", time.Second)},
+			check: func(t *testing.T, ev *sentry.Event) {
+				if ev.Level != sentry.LevelError || ev.Message != "hello" || ev.Logger != LoggerName {
+					t.Errorf("level, message, logger = %q, %q, %q", ev.Level, ev.Message, ev.Logger)
+				}
+				if want := []string{"hello"}; !reflect.DeepEqual(ev.Fingerprint, want) {
+					t.Errorf("fingerprint = %q, want %q", ev.Fingerprint, want)
+				}
+				if want := map[string]any{"n": int64(1), "d": "1s"}; !reflect.DeepEqual(ev.Extra, want) {
+					t.Errorf("extra = %v, want %v", ev.Extra, want)
+				}
+			},
+		},
+		{
+			name:  "tags",
+			opts:  []Option{WithTagKeys("region", "req.method")},
+			level: slog.LevelError,
+			handle: func(h slog.Handler) slog.Handler {
+				return h.WithAttrs([]slog.Attr{slog.String("region", "eu")}).WithGroup("req")
+			},
+			attrs: []slog.Attr{slog.String("method", "GET"), slog.String("path", "/")},
+			check: func(t *testing.T, ev *sentry.Event) {
+				if want := map[string]string{"region": "eu", "req.method": "GET"}; !reflect.DeepEqual(ev.Tags, want) {
+					t.Errorf("tags = %v, want %v", ev.Tags, want)
+				}
+				if want := map[string]any{"req.path": "/"}; !reflect.DeepEqual(ev.Extra, want) {
+					t.Errorf("extra = %v, want %v", ev.Extra, want)
+				}
+			},
+		},
+		{
+			name:  "error",
+			level: slog.LevelError,
+			attrs: []slog.Attr{
+				slog.Any("error", fmt.Errorf("wrapped: %w", errBoom)),
+				slog.Any("other", errors.New("other")),
+			},
+			check: func(t *testing.T, ev *sentry.Event) {
+				if len(ev.Exception) == 0 || ev.Exception[len(ev.Exception)-1].Value != "wrapped: boom" {
+					t.Errorf("exception = %+v, want wrapped: boom", ev.Exception)
+				}
+				if want := map[string]any{"error": "wrapped: boom", "other": "other"}; !reflect.DeepEqual(ev.Extra, want) {
+					t.Errorf("extra = %v, want %v", ev.Extra, want)
+				}
+			},
+		},
+		{
+			name:  "fingerprint",
+			opts:  []Option{WithFingerprint(func(r slog.Record) []string { return []string{"{{ default }}", r.Level.String()} })},
+			level: slog.LevelError,
+			check: func(t *testing.T, ev *sentry.Event) {
+				if want := []string{"{{ default }}", "ERROR"}; !reflect.DeepEqual(ev.Fingerprint, want) {
+					t.Errorf("fingerprint = %q, want %q", ev.Fingerprint, want)
+				}
+			},
+		},
+		{
+			name:  "empty attrs",
+			level: slog.LevelError,
+			handle: func(h slog.Handler) slog.Handler {
+				return h.WithGroup("g")
+			},
+			attrs: []slog.Attr{{}, slog.Group("", slog.Int("a", 1)), slog.Group("empty")},
+			check: func(t *testing.T, ev *sentry.Event) {
+				if want := map[string]any{"g.a": int64(1)}; !reflect.DeepEqual(ev.Extra, want) {
+					t.Errorf("extra = %v, want %v", ev.Extra, want)
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hub, tr := newTestHub(t)
+			var h slog.Handler = NewHandler(hub, tt.opts...)
+			if tt.handle != nil {
+				h = tt.handle(h)
+			}
+			r := slog.NewRecord(time.Date(2025, time.April, 1, 0, 0, 0, 0, time.UTC), tt.level, "hello", 0)
+			r.AddAttrs(tt.attrs...)
+			if err := h.Handle(context.Background(), r); err != nil {
+				t.Fatal(err)
+			}
+			events := tr.Events()
+			if len(events) != 1 {
+				t.Fatalf("events = %d, want 1", len(events))
+			}
+			tt.check(t, events[0])
+		})
+	}
+}
+
+func TestHandler_Enabled(t *testing.T) {
+	hub, _ := newTestHub(t)
+	h := NewHandler(hub)
+	ctx := context.Background()
+	if h.Enabled(ctx, slog.LevelWarn) || !h.Enabled(ctx, slog.LevelError) {
+		t.Error("default level is not error")
+	}
+	h.WithGroup("g").(*Handler).SetLevel(slog.LevelWarn)
+	if !h.Enabled(ctx, slog.LevelWarn) {
+		t.Error("SetLevel is not shared with derived handlers")
+	}
+	if h := NewHandler(hub, WithLevel(slog.LevelInfo)); !h.Enabled(ctx, slog.LevelInfo) {
+		t.Error("WithLevel is not applied")
+	}
+}
+
+func TestHandler_sampling(t *testing.T) {
+	hub, tr := newTestHub(t)
+	h := NewHandler(hub, WithSampleRate(0.5), WithSampleRate(2))
+	values := []float64{0.2, 0.7, 0.9}
+	h.state.random = func() float64 {
+		v := values[0]
+		values = values[1:]
+		return v
+	}
+	l := log.NewLogger(h, log.WithExitFunc(func(int) {}))
+	l.Error("kept")
+	l.Error("dropped")
+	l.Fatal("fatal")
+	var got []string
+	for _, ev := range tr.Events() {
+		got = append(got, ev.Message)
+	}
+	if want := []string{"kept", "fatal"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestHandler_flush(t *testing.T) {
+	hub, tr := newTestHub(t)
+	h := NewHandler(hub, WithFlushTimeout(time.Millisecond))
+	ctx := context.Background()
+	if err := h.Handle(ctx, slog.NewRecord(time.Now(), slog.LevelError, "error", 0)); err != nil {
+		t.Fatal(err)
+	}
+	if tr.flushes != 0 {
+		t.Errorf("flushes = %d after error, want 0", tr.flushes)
+	}
+	if err := h.Handle(ctx, slog.NewRecord(time.Now(), log.LevelFatal, "fatal", 0)); err != nil {
+		t.Fatal(err)
+	}
+	if tr.flushes != 1 {
+		t.Errorf("flushes = %d after fatal, want 1", tr.flushes)
+	}
+	tr.ok = false
+	if err := log.Close(log.NewMultiHandler(h)); !errors.Is(err, ErrFlushTimeout) {
+		t.Errorf("err = %v, want %v", err, ErrFlushTimeout)
+	}
+	if err := NewHandler(sentry.NewHub(nil, sentry.NewScope())).Flush(); err != nil {
+		t.Errorf("err = %v, want nil without a client", err)
+	}
+}
+
+func TestHandler_contextHub(t *testing.T) {
+	hub, tr := newTestHub(t)
+	other, otherTr := newTestHub(t)
+	h := NewHandler(hub)
+	ctx := sentry.SetHubOnContext(context.Background(), other)
+	if err := h.Handle(ctx, slog.NewRecord(time.Now(), slog.LevelError, "msg", 0)); err != nil {
+		t.Fatal(err)
+	}
+	if len(tr.Events()) != 0 || len(otherTr.Events()) != 1 {
+		t.Errorf("events = %d, %d, want the hub of the context", len(tr.Events()), len(otherTr.Events()))
+	}
+}
@@ -0,0 +1,292 @@
+package sqllog
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+
+	"github.com/nekrassov01/logger/log"
+)
+
+var (
+	_ driver.Driver             = (*wrappedDriver)(nil)
+	_ driver.DriverContext      = (*wrappedDriver)(nil)
+	_ driver.Connector          = (*connector)(nil)
+	_ driver.Conn               = (*conn)(nil)
+	_ driver.ConnPrepareContext = (*conn)(nil)
+	_ driver.ConnBeginTx        = (*conn)(nil)
+	_ driver.ExecerContext      = (*conn)(nil)
+	_ driver.QueryerContext     = (*conn)(nil)
+	_ driver.Pinger             = (*conn)(nil)
+	_ driver.SessionResetter    = (*conn)(nil)
+	_ driver.Validator          = (*conn)(nil)
+	_ driver.NamedValueChecker  = (*conn)(nil)
+	_ driver.Stmt               = (*stmt)(nil)
+	_ driver.StmtExecContext    = (*stmt)(nil)
+	_ driver.StmtQueryContext   = (*stmt)(nil)
+)
+
+// Open opens a database with the registered driver whose queries are logged to logger.
+func Open(driverName, dsn string, logger *log.Logger, opts ...Option) (*sql.DB, error) {
+	db, err := sql.Open(driverName, dsn)
+	if err != nil {
+		return nil, err
+	}
+	d := db.Driver()
+	if err := db.Close(); err != nil {
+		return nil, err
+	}
+	c, err := WrapDriver(d, logger, opts...).(*wrappedDriver).OpenConnector(dsn)
+	if err != nil {
+		return nil, err
+	}
+	return sql.OpenDB(c), nil
+}
+
+// WrapDriver returns a driver.Driver whose connections log every query to logger.
+func WrapDriver(d driver.Driver, logger *log.Logger, opts ...Option) driver.Driver {
+	return &wrappedDriver{driver: d, config: newConfig(logger, opts)}
+}
+
+// WrapConnector returns a driver.Connector whose connections log every query
+// to logger. Use it with sql.OpenDB.
+func WrapConnector(c driver.Connector, logger *log.Logger, opts ...Option) driver.Connector {
+	cfg := newConfig(logger, opts)
+	return &connector{
+		connect: c.Connect,
+		driver:  &wrappedDriver{driver: c.Driver(), config: cfg},
+	}
+}
+
+// wrappedDriver is a driver.Driver wrapping the connections of another driver.
+type wrappedDriver struct {
+	driver driver.Driver
+	config *config
+}
+
+// Open opens a wrapped connection.
+func (d *wrappedDriver) Open(name string) (driver.Conn, error) {
+	c, err := d.driver.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return &conn{Conn: c, config: d.config}, nil
+}
+
+// OpenConnector returns a connector of wrapped connections.
+func (d *wrappedDriver) OpenConnector(name string) (driver.Connector, error) {
+	if dc, ok := d.driver.(driver.DriverContext); ok {
+		c, err := dc.OpenConnector(name)
+		if err != nil {
+			return nil, err
+		}
+		return &connector{connect: c.Connect, driver: d}, nil
+	}
+	return &connector{
+		connect: func(context.Context) (driver.Conn, error) { return d.driver.Open(name) },
+		driver:  d,
+	}, nil
+}
+
+// connector is a driver.Connector of wrapped connections.
+type connector struct {
+	connect func(context.Context) (driver.Conn, error)
+	driver  *wrappedDriver
+}
+
+// Connect opens a wrapped connection.
+func (c *connector) Connect(ctx context.Context) (driver.Conn, error) {
+	dc, err := c.connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &conn{Conn: dc, config: c.driver.config}, nil
+}
+
+// Driver returns the wrapping driver.
+func (c *connector) Driver() driver.Driver {
+	return c.driver
+}
+
+// conn is a driver.Conn logging the queries executed on it.
+type conn struct {
+	driver.Conn
+	config *config
+}
+
+// Prepare prepares a wrapped statement.
+func (c *conn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+// PrepareContext prepares a wrapped statement.
+func (c *conn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	var s driver.Stmt
+	var err error
+	if pc, ok := c.Conn.(driver.ConnPrepareContext); ok {
+		s, err = pc.PrepareContext(ctx, query)
+	} else {
+		s, err = c.Conn.Prepare(query)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &stmt{Stmt: s, query: query, config: c.config}, nil
+}
+
+// BeginTx starts a transaction.
+func (c *conn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if bc, ok := c.Conn.(driver.ConnBeginTx); ok {
+		return bc.BeginTx(ctx, opts)
+	}
+	return c.Conn.Begin() //nolint:staticcheck
+}
+
+// ExecContext executes and logs a query, or returns driver.ErrSkip if the
+// wrapped connection does not support it.
+func (c *conn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	ec, ok := c.Conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	start := c.config.now()
+	res, err := ec.ExecContext(ctx, query, args)
+	if errors.Is(err, driver.ErrSkip) {
+		return nil, err
+	}
+	c.config.log(ctx, query, namedArgs(args), start, rowsAffected(res), err)
+	return res, err
+}
+
+// QueryContext executes and logs a query, or returns driver.ErrSkip if the
+// wrapped connection does not support it.
+func (c *conn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	qc, ok := c.Conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	start := c.config.now()
+	rows, err := qc.QueryContext(ctx, query, args)
+	if errors.Is(err, driver.ErrSkip) {
+		return nil, err
+	}
+	c.config.log(ctx, query, namedArgs(args), start, -1, err)
+	return rows, err
+}
+
+// Ping pings the wrapped connection if it supports it.
+func (c *conn) Ping(ctx context.Context) error {
+	if p, ok := c.Conn.(driver.Pinger); ok {
+		return p.Ping(ctx)
+	}
+	return nil
+}
+
+// ResetSession resets the wrapped connection if it supports it.
+func (c *conn) ResetSession(ctx context.Context) error {
+	if r, ok := c.Conn.(driver.SessionResetter); ok {
+		return r.ResetSession(ctx)
+	}
+	return nil
+}
+
+// IsValid reports whether the wrapped connection is valid, if it supports it.
+func (c *conn) IsValid() bool {
+	if v, ok := c.Conn.(driver.Validator); ok {
+		return v.IsValid()
+	}
+	return true
+}
+
+// CheckNamedValue delegates to the wrapped connection if it supports it.
+func (c *conn) CheckNamedValue(nv *driver.NamedValue) error {
+	if nc, ok := c.Conn.(driver.NamedValueChecker); ok {
+		return nc.CheckNamedValue(nv)
+	}
+	return driver.ErrSkip
+}
+
+// stmt is a driver.Stmt logging its executions.
+type stmt struct {
+	driver.Stmt
+	query  string
+	config *config
+}
+
+// Exec executes and logs the statement.
+func (s *stmt) Exec(args []driver.Value) (driver.Result, error) {
+	return s.ExecContext(context.Background(), valueArgs(args))
+}
+
+// Query executes and logs the statement.
+func (s *stmt) Query(args []driver.Value) (driver.Rows, error) {
+	return s.QueryContext(context.Background(), valueArgs(args))
+}
+
+// ExecContext executes and logs the statement.
+func (s *stmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	start := s.config.now()
+	var res driver.Result
+	var err error
+	if ec, ok := s.Stmt.(driver.StmtExecContext); ok {
+		res, err = ec.ExecContext(ctx, args)
+	} else {
+		res, err = s.Stmt.Exec(driverValues(args)) //nolint:staticcheck
+	}
+	s.config.log(ctx, s.query, namedArgs(args), start, rowsAffected(res), err)
+	return res, err
+}
+
+// QueryContext executes and logs the statement.
+func (s *stmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	start := s.config.now()
+	var rows driver.Rows
+	var err error
+	if qc, ok := s.Stmt.(driver.StmtQueryContext); ok {
+		rows, err = qc.QueryContext(ctx, args)
+	} else {
+		rows, err = s.Stmt.Query(driverValues(args)) //nolint:staticcheck
+	}
+	s.config.log(ctx, s.query, namedArgs(args), start, -1, err)
+	return rows, err
+}
+
+// namedArgs converts driver arguments to logged arguments.
+func namedArgs(args []driver.NamedValue) []arg {
+	as := make([]arg, len(args))
+	for i, a := range args {
+		as[i] = arg{name: a.Name, value: a.Value}
+	}
+	return as
+}
+
+// valueArgs converts positional driver values to named values.
+func valueArgs(args []driver.Value) []driver.NamedValue {
+	nvs := make([]driver.NamedValue, len(args))
+	for i, v := range args {
+		nvs[i] = driver.NamedValue{Ordinal: i + 1, Value: v}
+	}
+	return nvs
+}
+
+// driverValues converts named values to positional driver values.
+func driverValues(args []driver.NamedValue) []driver.Value {
+	vs := make([]driver.Value, len(args))
+	for i, a := range args {
+		vs[i] = a.Value
+	}
+	return vs
+}
+
+// rowsAffected returns the number of rows affected by res, or -1 if unknown.
+func rowsAffected(res driver.Result) int64 {
+	if res == nil {
+		return -1
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return -1
+	}
+	return n
+}
@@ -0,0 +1,174 @@
+package sqllog
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+)
+
+// fakeDriver is a driver whose connections return canned results.
+type fakeDriver struct{}
+
+func (fakeDriver) Open(string) (driver.Conn, error) { return fakeConn{}, nil }
+
+type fakeConnector struct{}
+
+func (fakeConnector) Connect(context.Context) (driver.Conn, error) { return fakeConn{}, nil }
+func (fakeConnector) Driver() driver.Driver                        { return fakeDriver{} }
+
+type fakeConn struct{}
+
+func (fakeConn) Prepare(query string) (driver.Stmt, error) { return fakeStmt{query: query}, nil }
+func (fakeConn) Close() error                              { return nil }
+func (fakeConn) Begin() (driver.Tx, error)                 { return fakeTx{}, nil }
+
+func (fakeConn) ExecContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Result, error) {
+	if query == "fail" {
+		return nil, errors.New("boom")
+	}
+	return driver.RowsAffected(2), nil
+}
+
+func (fakeConn) QueryContext(context.Context, string, []driver.NamedValue) (driver.Rows, error) {
+	return &fakeRows{}, nil
+}
+
+type fakeStmt struct{ query string }
+
+func (fakeStmt) Close() error  { return nil }
+func (fakeStmt) NumInput() int { return -1 }
+
+func (fakeStmt) Exec([]driver.Value) (driver.Result, error) { return driver.RowsAffected(1), nil }
+func (fakeStmt) Query([]driver.Value) (driver.Rows, error)  { return &fakeRows{}, nil }
+
+type fakeTx struct{}
+
+func (fakeTx) Commit() error   { return nil }
+func (fakeTx) Rollback() error { return nil }
+
+type fakeRows struct{ done bool }
+
+func (*fakeRows) Columns() []string { return []string{"n"} }
+func (*fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.done {
+		return io.EOF
+	}
+	r.done = true
+	dest[0] = int64(1)
+	return nil
+}
+
+var registerOnce sync.Once
+
+func TestOpen(t *testing.T) {
+	registerOnce.Do(func() { sql.Register("sqllog-fake", fakeDriver{}) })
+	tests := []struct {
+		name string
+		run  func(db *sql.DB) error
+		want string
+	}{
+		{
+			name: "exec",
+			run: func(db *sql.DB) error {
+				_, err := db.Exec("update t set a = ?", 1)
+				return err
+			},
+			want: "[DBG] query sql.query=\"update t set a = ?\" sql.args.1=1 sql.rows=2\n",
+		},
+		{
+			name: "named exec",
+			run: func(db *sql.DB) error {
+				_, err := db.Exec("update t set a = :a", sql.Named("a", "x"))
+				return err
+			},
+			want: "[DBG] query sql.query=\"update t set a = :a\" sql.args.a=x sql.rows=2\n",
+		},
+		{
+			name: "exec error",
+			run: func(db *sql.DB) error {
+				if _, err := db.Exec("fail"); err == nil {
+					return errors.New("want error")
+				}
+				return nil
+			},
+			want: "[ERR] query sql.query=fail error=boom\n",
+		},
+		{
+			name: "query",
+			run: func(db *sql.DB) error {
+				var n int
+				return db.QueryRow("select 1").Scan(&n)
+			},
+			want: "[DBG] query sql.query=\"select 1\"\n",
+		},
+		{
+			name: "prepared",
+			run: func(db *sql.DB) error {
+				stmt, err := db.Prepare("insert into t values (?)")
+				if err != nil {
+					return err
+				}
+				defer stmt.Close()
+				_, err = stmt.Exec(3)
+				return err
+			},
+			want: "[DBG] query sql.query=\"insert into t values (?)\" sql.args.1=3 sql.rows=1\n",
+		},
+		{
+			name: "transaction",
+			run: func(db *sql.DB) error {
+				tx, err := db.Begin()
+				if err != nil {
+					return err
+				}
+				if _, err := tx.Exec("delete from t"); err != nil {
+					return err
+				}
+				return tx.Commit()
+			},
+			want: "[DBG] query sql.query=\"delete from t\" sql.rows=2\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			db, err := Open("sqllog-fake", "", newTestLogger(&buf))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer db.Close()
+			if err := tt.run(db); err != nil {
+				t.Fatal(err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOpen_unknownDriver(t *testing.T) {
+	if _, err := Open("sqllog-unknown", "", nil); err == nil {
+		t.Error("got nil, want error")
+	}
+}
+
+func TestWrapConnector(t *testing.T) {
+	var buf bytes.Buffer
+	db := sql.OpenDB(WrapConnector(fakeConnector{}, newTestLogger(&buf)))
+	defer db.Close()
+	if _, err := db.Exec("delete from t"); err != nil {
+		t.Fatal(err)
+	}
+	want := "[DBG] query sql.query=\"delete from t\" sql.rows=2\n"
+	if got := buf.String(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
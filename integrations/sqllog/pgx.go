@@ -0,0 +1,58 @@
+package sqllog
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/nekrassov01/logger/log"
+)
+
+var _ pgx.QueryTracer = (*Tracer)(nil)
+
+// Tracer is a pgx.QueryTracer that logs every query.
+type Tracer struct {
+	config *config
+}
+
+// traceKey is the context key for the state of a traced query.
+type traceKey struct{}
+
+// traceData is the state of a traced query.
+type traceData struct {
+	start time.Time
+	query string
+	args  []arg
+}
+
+// NewTracer creates a new Tracer logging to logger. Set it as the Tracer of a
+// pgx.ConnConfig.
+func NewTracer(logger *log.Logger, opts ...Option) *Tracer {
+	return &Tracer{config: newConfig(logger, opts)}
+}
+
+// TraceQueryStart records the start of a query.
+func (t *Tracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	args := make([]arg, len(data.Args))
+	for i, v := range data.Args {
+		args[i] = arg{value: v}
+	}
+	return context.WithValue(ctx, traceKey{}, &traceData{
+		start: t.config.now(),
+		query: data.SQL,
+		args:  args,
+	})
+}
+
+// TraceQueryEnd logs the finished query.
+func (t *Tracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	td, ok := ctx.Value(traceKey{}).(*traceData)
+	if !ok {
+		return
+	}
+	rows := data.CommandTag.RowsAffected()
+	if data.Err != nil {
+		rows = -1
+	}
+	t.config.log(ctx, td.query, td.args, td.start, rows, data.Err)
+}
@@ -0,0 +1,53 @@
+package sqllog
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+func TestTracer(t *testing.T) {
+	tests := []struct {
+		name string
+		end  pgx.TraceQueryEndData
+		want string
+	}{
+		{
+			name: "ok",
+			end:  pgx.TraceQueryEndData{CommandTag: pgconn.NewCommandTag("UPDATE 3")},
+			want: "[DBG] query sql.query=\"update t set a = $1\" sql.args.1=42 sql.rows=3\n",
+		},
+		{
+			name: "error",
+			end:  pgx.TraceQueryEndData{Err: errors.New("boom")},
+			want: "[ERR] query sql.query=\"update t set a = $1\" sql.args.1=42 error=boom\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			tr := NewTracer(newTestLogger(&buf))
+			ctx := tr.TraceQueryStart(context.Background(), nil, pgx.TraceQueryStartData{
+				SQL:  "update t set a = $1",
+				Args: []any{42},
+			})
+			tr.TraceQueryEnd(ctx, nil, tt.end)
+			if got := buf.String(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTracer_withoutStart(t *testing.T) {
+	var buf bytes.Buffer
+	tr := NewTracer(newTestLogger(&buf))
+	tr.TraceQueryEnd(context.Background(), nil, pgx.TraceQueryEndData{})
+	if buf.Len() != 0 {
+		t.Errorf("got %q, want empty", buf.String())
+	}
+}
@@ -0,0 +1,130 @@
+package sqllog
+
+import (
+	"context"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/nekrassov01/logger/log"
+)
+
+// Attribute keys written for each query, inside the GroupKey group.
+const (
+	GroupKey    = "sql"
+	QueryKey    = "query"
+	ArgsKey     = "args"
+	DurationKey = "duration"
+	RowsKey     = "rows"
+)
+
+// config holds the options shared by the tracer and the driver wrapper.
+type config struct {
+	logger    *log.Logger
+	level     slog.Level
+	slow      time.Duration
+	slowLevel slog.Level
+	hasArgs   bool
+	redactor  *log.Redactor
+	now       func() time.Time
+}
+
+// Option defines a function type for configuring the query logging.
+type Option func(*config)
+
+// WithLevel returns an Option that sets the level of successful queries.
+// The default is slog.LevelDebug. Failed queries are logged at slog.LevelError.
+func WithLevel(level slog.Level) Option {
+	return func(c *config) {
+		c.level = level
+	}
+}
+
+// WithSlowThreshold returns an Option that logs successful queries taking at
+// least d at level instead.
+func WithSlowThreshold(d time.Duration, level slog.Level) Option {
+	return func(c *config) {
+		c.slow = d
+		c.slowLevel = level
+	}
+}
+
+// WithArgs returns an Option that sets whether query arguments are logged.
+// They are logged by default.
+func WithArgs(has bool) Option {
+	return func(c *config) {
+		c.hasArgs = has
+	}
+}
+
+// WithRedactor returns an Option that redacts query arguments with r. Positional
+// arguments are keyed by their 1-based index and named arguments by their name.
+func WithRedactor(r *log.Redactor) Option {
+	return func(c *config) {
+		c.redactor = r
+	}
+}
+
+// newConfig applies the options to the default configuration.
+func newConfig(logger *log.Logger, opts []Option) *config {
+	if logger == nil {
+		logger = log.NewLogger(nil)
+	}
+	c := &config{
+		logger:  logger,
+		level:   slog.LevelDebug,
+		hasArgs: true,
+		now:     time.Now,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// arg is a query argument with its name, if any.
+type arg struct {
+	name  string
+	value any
+}
+
+// log writes the record of a finished query. rows is negative if unknown.
+func (c *config) log(ctx context.Context, query string, args []arg, start time.Time, rows int64, err error) {
+	d := c.now().Sub(start)
+	level := c.level
+	switch {
+	case err != nil:
+		level = slog.LevelError
+	case c.slow > 0 && d >= c.slow:
+		level = c.slowLevel
+	}
+	if !c.logger.Enabled(ctx, level) {
+		return
+	}
+	attrs := make([]any, 0, 4)
+	attrs = append(attrs, slog.String(QueryKey, query))
+	if c.hasArgs && len(args) > 0 {
+		as := make([]any, len(args))
+		for i, a := range args {
+			key := a.name
+			if key == "" {
+				key = strconv.Itoa(i + 1)
+			}
+			attr := slog.Any(key, a.value)
+			if c.redactor != nil {
+				attr = c.redactor.RedactAttr(attr)
+			}
+			as[i] = attr
+		}
+		attrs = append(attrs, slog.Group(ArgsKey, as...))
+	}
+	attrs = append(attrs, slog.Duration(DurationKey, d))
+	if rows >= 0 {
+		attrs = append(attrs, slog.Int64(RowsKey, rows))
+	}
+	out := []slog.Attr{slog.Group(GroupKey, attrs...)}
+	if err != nil {
+		out = append(out, slog.Any("error", err))
+	}
+	c.logger.LogAttrs(ctx, level, "query", out...)
+}
@@ -0,0 +1,127 @@
+package sqllog
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/nekrassov01/logger/log"
+)
+
+// newTestLogger returns a debug logger writing to buf without the duration attribute.
+func newTestLogger(buf io.Writer) *log.Logger {
+	return log.NewLogger(log.NewCLIHandler(buf,
+		log.WithStyle(log.Style0()),
+		log.WithLevel(slog.LevelDebug),
+		log.WithReplaceAttr(func(groups []string, a slog.Attr) slog.Attr {
+			if len(groups) == 1 && groups[0] == GroupKey && a.Key == DurationKey {
+				return slog.Attr{}
+			}
+			return a
+		}),
+	))
+}
+
+func TestConfig_log(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		opts    []Option
+		args    []arg
+		elapsed time.Duration
+		rows    int64
+		err     error
+		want    string
+	}{
+		{
+			name: "default",
+			args: []arg{{value: 1}, {value: "a"}},
+			rows: 2,
+			want: "[DBG] query sql.query=\"select $1, $2\" sql.args.1=1 sql.args.2=a sql.rows=2\n",
+		},
+		{
+			name: "unknown rows",
+			rows: -1,
+			want: "[DBG] query sql.query=\"select $1, $2\"\n",
+		},
+		{
+			name: "named args",
+			args: []arg{{name: "id", value: 1}},
+			rows: -1,
+			want: "[DBG] query sql.query=\"select $1, $2\" sql.args.id=1\n",
+		},
+		{
+			name: "without args",
+			opts: []Option{WithArgs(false)},
+			args: []arg{{value: 1}},
+			rows: 0,
+			want: "[DBG] query sql.query=\"select $1, $2\" sql.rows=0\n",
+		},
+		{
+			name: "redactor",
+			opts: []Option{WithRedactor(log.NewRedactor(
+				log.WithKeyPatterns("password"),
+				log.WithValueRegexps(regexp.MustCompile(`secret`)),
+			))},
+			args: []arg{{name: "password", value: "p"}, {value: "my secret"}},
+			rows: -1,
+			want: "[DBG] query sql.query=\"select $1, $2\" sql.args.password=*** sql.args.2=\"my ***\"\n",
+		},
+		{
+			name: "level",
+			opts: []Option{WithLevel(slog.LevelInfo)},
+			rows: -1,
+			want: "[INF] query sql.query=\"select $1, $2\"\n",
+		},
+		{
+			name: "error",
+			rows: -1,
+			err:  errors.New("boom"),
+			want: "[ERR] query sql.query=\"select $1, $2\" error=boom\n",
+		},
+		{
+			name:    "slow",
+			opts:    []Option{WithSlowThreshold(time.Second, slog.LevelWarn)},
+			elapsed: 2 * time.Second,
+			rows:    -1,
+			want:    "[WRN] query sql.query=\"select $1, $2\"\n",
+		},
+		{
+			name:    "fast",
+			opts:    []Option{WithSlowThreshold(time.Second, slog.LevelWarn)},
+			elapsed: 500 * time.Millisecond,
+			rows:    -1,
+			want:    "[DBG] query sql.query=\"select $1, $2\"\n",
+		},
+		{
+			name: "disabled",
+			opts: []Option{WithLevel(slog.LevelDebug - 4)},
+			rows: -1,
+			want: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			c := newConfig(newTestLogger(&buf), tt.opts)
+			c.now = func() time.Time { return start.Add(tt.elapsed) }
+			c.log(context.Background(), "select $1, $2", tt.args, start, tt.rows, tt.err)
+			if got := buf.String(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewConfig_nilLogger(t *testing.T) {
+	c := newConfig(nil, nil)
+	if c.logger == nil {
+		t.Fatal("logger is nil")
+	}
+	c.log(context.Background(), "select 1", nil, time.Now(), -1, nil)
+}
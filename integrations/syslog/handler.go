@@ -0,0 +1,423 @@
+package syslog
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/nekrassov01/logger/log"
+)
+
+var (
+	_ slog.Handler    = (*Handler)(nil)
+	_ log.LevelSetter = (*Handler)(nil)
+)
+
+// TimeFormat is the layout of the TIMESTAMP field of a syslog message.
+const TimeFormat = "2006-01-02T15:04:05.000000Z07:00"
+
+// nilValue is written in place of empty header fields.
+const nilValue = "-"
+
+// Facility is a syslog facility.
+type Facility int
+
+// Facilities defined by RFC 5424.
+const (
+	FacilityKern Facility = iota
+	FacilityUser
+	FacilityMail
+	FacilityDaemon
+	FacilityAuth
+	FacilitySyslog
+	FacilityLPR
+	FacilityNews
+	FacilityUUCP
+	FacilityCron
+	FacilityAuthPriv
+	FacilityFTP
+	FacilityNTP
+	FacilityAudit
+	FacilityAlert
+	FacilityClock
+	FacilityLocal0
+	FacilityLocal1
+	FacilityLocal2
+	FacilityLocal3
+	FacilityLocal4
+	FacilityLocal5
+	FacilityLocal6
+	FacilityLocal7
+)
+
+// Severity is a syslog severity.
+type Severity int
+
+// Severities defined by RFC 5424.
+const (
+	SeverityEmergency Severity = iota
+	SeverityAlert
+	SeverityCritical
+	SeverityError
+	SeverityWarning
+	SeverityNotice
+	SeverityInfo
+	SeverityDebug
+)
+
+// SeverityOf maps a slog level to a syslog severity. Levels between slog.LevelInfo
+// and slog.LevelWarn map to notice, and log.LevelFatal and above to critical.
+func SeverityOf(level slog.Level) Severity {
+	switch {
+	case level >= log.LevelFatal:
+		return SeverityCritical
+	case level >= slog.LevelError:
+		return SeverityError
+	case level >= slog.LevelWarn:
+		return SeverityWarning
+	case level > slog.LevelInfo:
+		return SeverityNotice
+	case level >= slog.LevelInfo:
+		return SeverityInfo
+	default:
+		return SeverityDebug
+	}
+}
+
+// Framing defines how messages are delimited on the transport.
+type Framing int
+
+const (
+	// FramingNewline terminates each message with a line feed (RFC 6587 non-transparent framing).
+	FramingNewline Framing = iota
+
+	// FramingOctetCounting prefixes each message with its length (RFC 6587 octet counting).
+	FramingOctetCounting
+
+	// FramingNone writes each message as is. It is used for datagram transports.
+	FramingNone
+)
+
+// Handler is a slog.Handler that writes RFC 5424 syslog messages. The MSG part
+// is the logfmt serialization of the message and attributes; the level is
+// carried by the PRI part and the time by the TIMESTAMP field.
+type Handler struct {
+	inner slog.Handler
+	state *state
+}
+
+// state is the state shared by a Handler and its derived handlers.
+type state struct {
+	mu          sync.Mutex
+	w           io.Writer
+	buf         bytes.Buffer
+	network     string
+	addr        string
+	dialed      bool
+	closed      bool
+	framing     Framing
+	facility    Facility
+	hostname    string
+	appName     string
+	procID      string
+	msgID       string
+	opts        []log.CLIHandlerOption
+	replaceAttr func(groups []string, a slog.Attr) slog.Attr
+}
+
+// Option defines a function type for configuring a Handler.
+type Option func(*state)
+
+// WithFacility returns an Option that sets the facility. The default is FacilityUser.
+func WithFacility(f Facility) Option {
+	return func(s *state) {
+		s.facility = f
+	}
+}
+
+// WithHostname returns an Option that sets the HOSTNAME field. The default is os.Hostname.
+func WithHostname(hostname string) Option {
+	return func(s *state) {
+		s.hostname = hostname
+	}
+}
+
+// WithAppName returns an Option that sets the APP-NAME field. The default is
+// the base name of the executable.
+func WithAppName(name string) Option {
+	return func(s *state) {
+		s.appName = name
+	}
+}
+
+// WithProcID returns an Option that sets the PROCID field. The default is the process ID.
+func WithProcID(id string) Option {
+	return func(s *state) {
+		s.procID = id
+	}
+}
+
+// WithMsgID returns an Option that sets the MSGID field.
+func WithMsgID(id string) Option {
+	return func(s *state) {
+		s.msgID = id
+	}
+}
+
+// WithFraming returns an Option that sets how messages are delimited.
+func WithFraming(f Framing) Option {
+	return func(s *state) {
+		s.framing = f
+	}
+}
+
+// WithHandlerOptions returns an Option that sets the options of the logfmt
+// handler serializing the MSG part, e.g. log.WithLevel or log.WithRedactor.
+func WithHandlerOptions(opts ...log.CLIHandlerOption) Option {
+	return func(s *state) {
+		s.opts = append(s.opts, opts...)
+	}
+}
+
+// WithReplaceAttr returns an Option that sets the function rewriting the
+// attributes of the MSG part. It has the semantics of log.WithReplaceAttr,
+// which must not be passed to WithHandlerOptions.
+func WithReplaceAttr(fn func(groups []string, a slog.Attr) slog.Attr) Option {
+	return func(s *state) {
+		s.replaceAttr = fn
+	}
+}
+
+// NewHandler creates a new Handler writing to w. Messages are newline-delimited by default.
+func NewHandler(w io.Writer, opts ...Option) *Handler {
+	if w == nil {
+		w = io.Discard
+	}
+	s := &state{w: w}
+	return newHandler(s, opts)
+}
+
+// Dial creates a new Handler connected to the syslog server at addr over the
+// given network, e.g. "udp", "tcp" or "unixgram". TCP uses octet counting and
+// other transports send one message per write. If network is empty, the local
+// syslog socket is used.
+func Dial(network, addr string, opts ...Option) (*Handler, error) {
+	s := &state{network: network, addr: addr, dialed: true}
+	switch network {
+	case "tcp", "tcp4", "tcp6":
+		s.framing = FramingOctetCounting
+	default:
+		s.framing = FramingNone
+	}
+	h := newHandler(s, opts)
+	if err := s.connect(); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+// newHandler applies the options to s and creates the handler.
+func newHandler(s *state, opts []Option) *Handler {
+	s.facility = FacilityUser
+	s.appName = filepath.Base(os.Args[0])
+	s.procID = strconv.Itoa(os.Getpid())
+	if hostname, err := os.Hostname(); err == nil {
+		s.hostname = hostname
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	hopts := append(s.opts[:len(s.opts):len(s.opts)], log.WithReplaceAttr(s.replace))
+	return &Handler{
+		inner: log.NewLogfmtHandler(&s.buf, hopts...),
+		state: s,
+	}
+}
+
+// replace drops the level from the logfmt output and applies the user function.
+func (s *state) replace(groups []string, a slog.Attr) slog.Attr {
+	if len(groups) == 0 && a.Key == slog.LevelKey {
+		if _, ok := a.Value.Any().(slog.Level); ok {
+			return slog.Attr{}
+		}
+	}
+	if s.replaceAttr != nil {
+		return s.replaceAttr(groups, a)
+	}
+	return a
+}
+
+// connect dials the syslog server. It must be called with the mutex held or
+// before the handler is shared.
+func (s *state) connect() error {
+	if s.w != nil {
+		if c, ok := s.w.(io.Closer); ok {
+			c.Close()
+		}
+		s.w = nil
+	}
+	if s.network != "" {
+		c, err := net.Dial(s.network, s.addr)
+		if err != nil {
+			return err
+		}
+		s.w = c
+		return nil
+	}
+	var errs []error
+	for _, network := range []string{"unixgram", "unix"} {
+		for _, path := range []string{"/dev/log", "/var/run/syslog", "/var/run/log"} {
+			c, err := net.Dial(network, path)
+			if err == nil {
+				s.w = c
+				return nil
+			}
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Enabled reports whether the handler is enabled for the given level.
+func (h *Handler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.inner.Enabled(ctx, level)
+}
+
+// Handle writes the record as a syslog message. A dialed handler reconnects
+// once if the write fails.
+func (h *Handler) Handle(ctx context.Context, r slog.Record) error {
+	s := h.state
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.buf.Reset()
+	t := r.Time
+	r.Time = time.Time{}
+	if err := h.inner.Handle(ctx, r); err != nil {
+		return err
+	}
+	msg := bytes.TrimSuffix(s.buf.Bytes(), []byte("\n"))
+	b := s.appendMessage(nil, SeverityOf(r.Level), t, msg)
+	if s.closed {
+		return net.ErrClosed
+	}
+	if s.w == nil {
+		if err := s.connect(); err != nil {
+			return err
+		}
+	}
+	_, err := s.w.Write(b)
+	if err != nil && s.dialed {
+		if cerr := s.connect(); cerr != nil {
+			return errors.Join(err, cerr)
+		}
+		_, err = s.w.Write(b)
+	}
+	return err
+}
+
+// WithAttrs returns a new Handler whose attributes are added to every message.
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	return &Handler{inner: h.inner.WithAttrs(attrs), state: h.state}
+}
+
+// WithGroup returns a new Handler that qualifies the attributes of every message with name.
+func (h *Handler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return &Handler{inner: h.inner.WithGroup(name), state: h.state}
+}
+
+// SetLevel changes the minimum level of the handler and its derived handlers.
+func (h *Handler) SetLevel(level slog.Leveler) {
+	if ls, ok := h.inner.(log.LevelSetter); ok {
+		ls.SetLevel(level)
+	}
+}
+
+// Close closes the connection of a handler created by Dial. Records handled
+// afterwards return net.ErrClosed. The writer of NewHandler is left open.
+func (h *Handler) Close() error {
+	s := h.state
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.dialed || s.closed {
+		return nil
+	}
+	s.closed = true
+	if s.w == nil {
+		return nil
+	}
+	err := s.w.(io.Closer).Close()
+	s.w = nil
+	return err
+}
+
+// appendMessage appends a framed RFC 5424 message to b.
+func (s *state) appendMessage(b []byte, sev Severity, t time.Time, msg []byte) []byte {
+	var m []byte
+	m = append(m, '<')
+	m = strconv.AppendInt(m, int64(s.facility)*8+int64(sev), 10)
+	m = append(m, ">1 "...)
+	if t.IsZero() {
+		m = append(m, nilValue...)
+	} else {
+		m = t.AppendFormat(m, TimeFormat)
+	}
+	m = append(m, ' ')
+	m = appendHeader(m, s.hostname, 255)
+	m = append(m, ' ')
+	m = appendHeader(m, s.appName, 48)
+	m = append(m, ' ')
+	m = appendHeader(m, s.procID, 128)
+	m = append(m, ' ')
+	m = appendHeader(m, s.msgID, 32)
+	m = append(m, " -"...)
+	if len(msg) > 0 {
+		m = append(m, ' ')
+		m = append(m, msg...)
+	}
+	switch s.framing {
+	case FramingOctetCounting:
+		b = strconv.AppendInt(b, int64(len(m)), 10)
+		b = append(b, ' ')
+		b = append(b, m...)
+	case FramingNewline:
+		b = append(b, m...)
+		b = append(b, '\n')
+	default:
+		b = append(b, m...)
+	}
+	return b
+}
+
+// appendHeader appends a header field to b. Characters outside printable
+// US-ASCII are replaced with underscores and the field is truncated to max bytes.
+func appendHeader(b []byte, v string, max int) []byte {
+	if v == "" {
+		return append(b, nilValue...)
+	}
+	if len(v) > max {
+		v = v[:max]
+	}
+	for i := 0; i < len(v); i++ {
+		c := v[i]
+		if c < 33 || c > 126 {
+			c = '_'
+		}
+		b = append(b, c)
+	}
+	return b
+}
@@ -0,0 +1,224 @@
+package syslog
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nekrassov01/logger/log"
+)
+
+var testTime = time.Date(2025, time.April, 1, 12, 34, 56, 789000000, time.UTC)
+
+// testOptions returns options fixing the header fields for tests.
+func testOptions(opts ...Option) []Option {
+	return append([]Option{WithHostname("host"), WithAppName("app"), WithProcID("42")}, opts...)
+}
+
+func TestSeverityOf(t *testing.T) {
+	tests := []struct {
+		level slog.Level
+		want  Severity
+	}{
+		{level: slog.LevelDebug - 4, want: SeverityDebug},
+		{level: slog.LevelDebug, want: SeverityDebug},
+		{level: slog.LevelInfo, want: SeverityInfo},
+		{level: slog.LevelInfo + 2, want: SeverityNotice},
+		{level: slog.LevelWarn, want: SeverityWarning},
+		{level: slog.LevelError, want: SeverityError},
+		{level: log.LevelFatal, want: SeverityCritical},
+	}
+	for _, tt := range tests {
+		t.Run(tt.level.String(), func(t *testing.T) {
+			if got := SeverityOf(tt.level); got != tt.want {
+				t.Errorf("SeverityOf(%v) = %v, want %v", tt.level, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHandler_Handle(t *testing.T) {
+	tests := []struct {
+		name   string
+		opts   []Option
+		handle func(slog.Handler) slog.Handler
+		time   time.Time
+		level  slog.Level
+		attrs  []slog.Attr
+		want   string
+	}{
+		{
+			name:  "default",
+			time:  testTime,
+			level: slog.LevelInfo,
+			attrs: []slog.Attr{slog.String("key", "a b")},
+			want:  "<14>1 2025-04-01T12:34:56.789000Z host app 42 - - msg=hello key=\"a b\"\n",
+		},
+		{
+			name:  "facility and severity",
+			opts:  []Option{WithFacility(FacilityLocal0), WithMsgID("ID1")},
+			time:  testTime,
+			level: slog.LevelError,
+			want:  "<131>1 2025-04-01T12:34:56.789000Z host app 42 ID1 - msg=hello\n",
+		},
+		{
+			name:  "zero time",
+			level: slog.LevelWarn,
+			want:  "<12>1 - host app 42 - - msg=hello\n",
+		},
+		{
+			name:  "header sanitized",
+			opts:  []Option{WithHostname(""), WithAppName("my app\t"), WithMsgID(strings.Repeat("x", 40))},
+			level: slog.LevelInfo,
+			want:  "<14>1 - - my_app_ 42 " + strings.Repeat("x", 32) + " - msg=hello\n",
+		},
+		{
+			name:  "octet counting",
+			opts:  []Option{WithFraming(FramingOctetCounting)},
+			level: slog.LevelInfo,
+			want:  "33 <14>1 - host app 42 - - msg=hello",
+		},
+		{
+			name:  "no framing",
+			opts:  []Option{WithFraming(FramingNone)},
+			level: slog.LevelInfo,
+			want:  "<14>1 - host app 42 - - msg=hello",
+		},
+		{
+			name: "attrs and group",
+			handle: func(h slog.Handler) slog.Handler {
+				return h.WithGroup("req").WithAttrs([]slog.Attr{slog.Int("id", 1)})
+			},
+			level: slog.LevelInfo,
+			attrs: []slog.Attr{slog.String("method", "GET")},
+			want:  "<14>1 - host app 42 - - msg=hello req.id=1 req.method=GET\n",
+		},
+		{
+			name:  "level attr kept",
+			level: slog.LevelInfo,
+			attrs: []slog.Attr{slog.String("level", "x")},
+			want:  "<14>1 - host app 42 - - msg=hello level=x\n",
+		},
+		{
+			name: "replace attr",
+			opts: []Option{WithReplaceAttr(func(_ []string, a slog.Attr) slog.Attr {
+				if a.Key == "secret" {
+					return slog.String(a.Key, "***")
+				}
+				return a
+			})},
+			level: slog.LevelInfo,
+			attrs: []slog.Attr{slog.String("secret", "pw")},
+			want:  "<14>1 - host app 42 - - msg=hello secret=***\n",
+		},
+		{
+			name:  "below level",
+			opts:  []Option{WithHandlerOptions(log.WithLevel(slog.LevelWarn))},
+			level: slog.LevelInfo,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			var h slog.Handler = NewHandler(buf, testOptions(tt.opts...)...)
+			if tt.handle != nil {
+				h = tt.handle(h)
+			}
+			l := slog.New(h)
+			r := slog.NewRecord(tt.time, tt.level, "hello", 0)
+			r.AddAttrs(tt.attrs...)
+			if l.Enabled(context.Background(), tt.level) {
+				if err := h.Handle(context.Background(), r); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHandler_SetLevel(t *testing.T) {
+	h := NewHandler(nil)
+	h2 := h.WithAttrs([]slog.Attr{slog.Int("a", 1)})
+	h.SetLevel(slog.LevelError)
+	if h2.Enabled(context.Background(), slog.LevelWarn) {
+		t.Error("Enabled(WARN) = true, want false")
+	}
+}
+
+func TestDial(t *testing.T) {
+	t.Run("udp", func(t *testing.T) {
+		pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+		if err != nil {
+			t.Skip(err)
+		}
+		defer pc.Close()
+		h, err := Dial("udp", pc.LocalAddr().String(), testOptions()...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer h.Close()
+		slog.New(h).Info("hello", "key", "val")
+		b := make([]byte, 1024)
+		pc.SetReadDeadline(time.Now().Add(5 * time.Second))
+		n, _, err := pc.ReadFrom(b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := string(b[:n])
+		if !strings.HasPrefix(got, "<14>1 ") || !strings.HasSuffix(got, " host app 42 - - msg=hello key=val") {
+			t.Errorf("got %q", got)
+		}
+	})
+	t.Run("tcp", func(t *testing.T) {
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Skip(err)
+		}
+		defer ln.Close()
+		lines := make(chan string, 1)
+		go func() {
+			c, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			defer c.Close()
+			s, _ := bufio.NewReader(c).ReadString('\n')
+			lines <- s
+		}()
+		h, err := Dial("tcp", ln.Addr().String(), testOptions(WithFraming(FramingNewline))...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := h.Handle(context.Background(), slog.NewRecord(time.Time{}, slog.LevelInfo, "hello", 0)); err != nil {
+			t.Fatal(err)
+		}
+		select {
+		case got := <-lines:
+			if want := "<14>1 - host app 42 - - msg=hello\n"; got != want {
+				t.Errorf("got %q, want %q", got, want)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("timeout")
+		}
+		if err := h.Close(); err != nil {
+			t.Fatal(err)
+		}
+		if err := h.Handle(context.Background(), slog.NewRecord(time.Time{}, slog.LevelInfo, "hello", 0)); !errors.Is(err, net.ErrClosed) {
+			t.Errorf("Handle() after Close error = %v, want %v", err, net.ErrClosed)
+		}
+	})
+	t.Run("error", func(t *testing.T) {
+		if _, err := Dial("tcp", "127.0.0.1:0"); err == nil {
+			t.Error("Dial() error = nil, want error")
+		}
+	})
+}
@@ -0,0 +1,85 @@
+package log
+
+import (
+	"bytes"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/mattn/go-runewidth"
+)
+
+// StripANSI returns s without its escape sequences, i.e. the SGR sequences of
+// colors and the OSC sequences of hyperlinks, e.g. to compare or pipe output
+// written with colors.
+func StripANSI(s string) string {
+	if strings.IndexByte(s, '\x1b') < 0 {
+		return s
+	}
+	b := []byte(s)
+	var sb strings.Builder
+	sb.Grow(len(b))
+	for len(b) > 0 {
+		if l := max(sgrLen(b), oscLen(b)); l > 0 {
+			b = b[l:]
+			continue
+		}
+		i := len(b)
+		if j := bytes.IndexByte(b[1:], '\x1b'); j >= 0 {
+			i = j + 1
+		}
+		sb.Write(b[:i])
+		b = b[i:]
+	}
+	return sb.String()
+}
+
+// VisibleWidth returns the number of terminal columns s occupies, ignoring its
+// escape sequences like StripANSI and counting wide characters as two columns.
+func VisibleWidth(s string) int {
+	return visibleWidth([]byte(s))
+}
+
+// visibleWidth returns the display width of b without escape sequences.
+func visibleWidth(b []byte) int {
+	n := 0
+	for len(b) > 0 {
+		if l := max(sgrLen(b), oscLen(b)); l > 0 {
+			b = b[l:]
+			continue
+		}
+		r, size := utf8.DecodeRune(b)
+		n += runewidth.RuneWidth(r)
+		b = b[size:]
+	}
+	return n
+}
+
+// oscLen returns the length of the OSC escape sequence at the start of b, such as
+// an OSC 8 hyperlink, or 0. The sequence ends with BEL or ST.
+func oscLen(b []byte) int {
+	if len(b) < 2 || b[0] != '\x1b' || b[1] != ']' {
+		return 0
+	}
+	for i := 2; i < len(b); i++ {
+		switch {
+		case b[i] == '\a':
+			return i + 1
+		case b[i] == '\x1b' && i+1 < len(b) && b[i+1] == '\\':
+			return i + 2
+		}
+	}
+	return 0
+}
+
+// sgrLen returns the length of the CSI escape sequence at the start of b, or 0.
+func sgrLen(b []byte) int {
+	if len(b) < 2 || b[0] != '\x1b' || b[1] != '[' {
+		return 0
+	}
+	for i := 2; i < len(b); i++ {
+		if b[i] >= 0x40 && b[i] <= 0x7e {
+			return i + 1
+		}
+	}
+	return 0
+}
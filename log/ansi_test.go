@@ -0,0 +1,68 @@
+package log
+
+import "testing"
+
+func TestStripANSI(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"", ""},
+		{"abc", "abc"},
+		{"\x1b[1;31mabc\x1b[0m def", "abc def"},
+		{"a\x1b[38;2;1;2;3mb\x1b[mc", "abc"},
+		{"日本\x1b[2m語\x1b[0m", "日本語"},
+		{"\x1b]8;;file:///a.go\x1b\\a.go\x1b]8;;\x1b\\:1", "a.go:1"},
+		{"\x1b]8;;https://example.com\aab\x1b]8;;\a", "ab"},
+		{"\x1b[", "\x1b["},
+		{"a\x1b]8;;unterminated", "a\x1b]8;;unterminated"},
+	}
+	for _, tt := range tests {
+		if got := StripANSI(tt.in); got != tt.want {
+			t.Errorf("StripANSI(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestVisibleWidth(t *testing.T) {
+	tests := []struct {
+		in   string
+		want int
+	}{
+		{"", 0},
+		{"abc", 3},
+		{"\x1b[1;31mabc\x1b[0m", 3},
+		{"日本", 4},
+		{"\x1b[", 1},
+		{"\x1b]8;;file:///a.go\x1b\\a.go\x1b]8;;\x1b\\", 4},
+		{"\x1b]8;;https://example.com\aab\x1b]8;;\a", 2},
+	}
+	for _, tt := range tests {
+		if got := VisibleWidth(tt.in); got != tt.want {
+			t.Errorf("VisibleWidth(%q) = %d, want %d", tt.in, got, tt.want)
+		}
+		if got := VisibleWidth(StripANSI(tt.in)); got != tt.want {
+			t.Errorf("VisibleWidth(StripANSI(%q)) = %d, want %d", tt.in, got, tt.want)
+		}
+	}
+}
+
+func Test_visibleWidth(t *testing.T) {
+	tests := []struct {
+		in   string
+		want int
+	}{
+		{"", 0},
+		{"abc", 3},
+		{"\x1b[1;31mabc\x1b[0m", 3},
+		{"日本", 4},
+		{"\x1b[", 1},
+		{"\x1b]8;;file:///a.go\x1b\\a.go\x1b]8;;\x1b\\", 4},
+		{"\x1b]8;;https://example.com\aab\x1b]8;;\a", 2},
+	}
+	for _, tt := range tests {
+		if got := visibleWidth([]byte(tt.in)); got != tt.want {
+			t.Errorf("visibleWidth(%q) = %d, want %d", tt.in, got, tt.want)
+		}
+	}
+}
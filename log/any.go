@@ -0,0 +1,284 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// AnyFormat selects how maps, slices, arrays and structs in attribute values are rendered.
+type AnyFormat int
+
+const (
+	// AnyFormatDefault renders values with fmt, e.g. map[key1:value1].
+	AnyFormatDefault AnyFormat = iota
+
+	// AnyFormatJSON renders values as JSON.
+	AnyFormatJSON
+
+	// AnyFormatFlatten expands values into dotted keys, e.g. m.key1=value1.
+	AnyFormatFlatten
+
+	// AnyFormatGoString renders values as Go syntax with %#v.
+	AnyFormatGoString
+)
+
+// truncated is appended to values cut by the size limit and replaces values
+// nested deeper than the depth limit.
+const truncated = "..."
+
+// WithAnyFormat returns a CLIHandlerOption that sets how complex attribute values are rendered.
+// Values implementing error or fmt.Stringer keep their own representation.
+func WithAnyFormat(format AnyFormat) CLIHandlerOption {
+	return func(c *CLIHandler) {
+		c.anyFormat = format
+	}
+}
+
+// WithAnyLimits returns a CLIHandlerOption that limits the rendering of complex attribute values.
+// Values nested deeper than depth are elided, and JSON or Go syntax output longer than size
+// bytes is truncated. Zero disables a limit.
+func WithAnyLimits(depth, size int) CLIHandlerOption {
+	return func(c *CLIHandler) {
+		c.anyDepth = max(0, depth)
+		c.anySize = max(0, size)
+	}
+}
+
+// isComplex reports whether v holds a map, slice, array or struct that AnyFormat applies to.
+func isComplex(v slog.Value) bool {
+	return v.Kind() == slog.KindAny && isComplexAny(v.Any())
+}
+
+// isComplexAny reports whether x is a map, slice, array or struct, following pointers.
+// Errors, fmt.Stringers and byte slices are not complex.
+func isComplexAny(x any) bool {
+	switch x.(type) {
+	case nil, error, fmt.Stringer, []byte:
+		return false
+	}
+	switch indirect(reflect.ValueOf(x)).Kind() {
+	case reflect.Map, reflect.Slice, reflect.Array, reflect.Struct:
+		return true
+	default:
+		return false
+	}
+}
+
+// indirect follows pointers and interfaces until a non-nil concrete value.
+func indirect(rv reflect.Value) reflect.Value {
+	for rv.Kind() == reflect.Pointer || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			return rv
+		}
+		rv = rv.Elem()
+	}
+	return rv
+}
+
+// formatAny returns the text of a complex value in the handler's AnyFormat.
+// It reports false if the value is not complex or the default format applies.
+func (h *CLIHandler) formatAny(v slog.Value) (string, bool) {
+	if !isComplex(v) {
+		return "", false
+	}
+	var s string
+	switch h.anyFormat {
+	case AnyFormatJSON:
+		b, err := marshalJSON(v.Any(), h.anyDepth)
+		if err == nil {
+			b, err = h.redactor.redactJSON(b)
+		}
+		if err != nil {
+			return "", false
+		}
+		s = string(b)
+	case AnyFormatGoString:
+		s = h.redactor.RedactString(fmt.Sprintf("%#v", v.Any()))
+	default:
+		return "", false
+	}
+	return truncate(s, h.anySize), true
+}
+
+// flattenAny returns a complex value as a group with one attr per element, with
+// sensitive elements masked by the Redactor. It reports false if the value is not
+// complex, flattening is disabled, or the value is empty.
+func (h *CLIHandler) flattenAny(v slog.Value) (slog.Value, bool) {
+	if h.anyFormat != AnyFormatFlatten || !isComplex(v) {
+		return v, false
+	}
+	rv := reflect.ValueOf(v.Any())
+	path := make(map[ref]bool)
+	for _, r := range refsOf(rv) {
+		path[r] = true
+	}
+	attrs := flatten(indirect(rv), 1, h.anyDepth, path)
+	if len(attrs) == 0 {
+		return v, false
+	}
+	if h.redactor != nil {
+		attrs = h.redactor.redactGroup(attrs, false)
+	}
+	return slog.GroupValue(attrs...), true
+}
+
+// ref identifies the value a pointer, map or slice refers to.
+type ref struct {
+	addr uintptr
+	typ  reflect.Type
+}
+
+// refsOf returns the pointers, maps and slices reached from rv while following
+// pointers and interfaces to its value.
+func refsOf(rv reflect.Value) []ref {
+	var refs []ref
+	for {
+		switch rv.Kind() {
+		case reflect.Pointer, reflect.Map, reflect.Slice:
+			if rv.IsNil() {
+				return refs
+			}
+			refs = append(refs, ref{addr: rv.Pointer(), typ: rv.Type()})
+			if rv.Kind() != reflect.Pointer {
+				return refs
+			}
+			rv = rv.Elem()
+		case reflect.Interface:
+			if rv.IsNil() {
+				return refs
+			}
+			rv = rv.Elem()
+		default:
+			return refs
+		}
+	}
+}
+
+// flatten returns the elements of rv as attrs, descending into complex elements
+// until maxDepth is reached. The values being flattened are tracked in path, and
+// an element referring back to one of them is elided like a value too deep.
+func flatten(rv reflect.Value, depth, maxDepth int, path map[ref]bool) []slog.Attr {
+	var attrs []slog.Attr
+	add := func(key string, ev0 reflect.Value) {
+		ev := indirect(ev0)
+		if !ev.IsValid() || !ev.CanInterface() {
+			attrs = append(attrs, slog.Any(key, nil))
+			return
+		}
+		x := ev.Interface()
+		switch {
+		case !isComplexAny(x):
+			attrs = append(attrs, slog.Any(key, x))
+		case maxDepth > 0 && depth >= maxDepth:
+			attrs = append(attrs, slog.String(key, truncated))
+		default:
+			refs := refsOf(ev0)
+			if slices.ContainsFunc(refs, func(r ref) bool { return path[r] }) {
+				attrs = append(attrs, slog.String(key, truncated))
+				return
+			}
+			for _, r := range refs {
+				path[r] = true
+			}
+			sub := flatten(ev, depth+1, maxDepth, path)
+			for _, r := range refs {
+				delete(path, r)
+			}
+			if len(sub) > 0 {
+				attrs = append(attrs, slog.Attr{Key: key, Value: slog.GroupValue(sub...)})
+			} else {
+				attrs = append(attrs, slog.Any(key, x))
+			}
+		}
+	}
+	switch rv.Kind() {
+	case reflect.Map:
+		keys := rv.MapKeys()
+		names := make([]string, len(keys))
+		for i, k := range keys {
+			names[i] = fmt.Sprint(k.Interface())
+		}
+		idx := make([]int, len(keys))
+		for i := range idx {
+			idx[i] = i
+		}
+		slices.SortFunc(idx, func(a, b int) int { return strings.Compare(names[a], names[b]) })
+		for _, i := range idx {
+			add(names[i], rv.MapIndex(keys[i]))
+		}
+	case reflect.Slice, reflect.Array:
+		for i := range rv.Len() {
+			add(strconv.Itoa(i), rv.Index(i))
+		}
+	case reflect.Struct:
+		t := rv.Type()
+		for i := range t.NumField() {
+			f := t.Field(i)
+			if !f.IsExported() {
+				continue
+			}
+			name := f.Name
+			if tag, _, _ := strings.Cut(f.Tag.Get("json"), ","); tag == "-" {
+				continue
+			} else if tag != "" {
+				name = tag
+			}
+			add(name, rv.Field(i))
+		}
+	}
+	return attrs
+}
+
+// marshalJSON encodes x as JSON, replacing values nested deeper than maxDepth.
+func marshalJSON(x any, maxDepth int) ([]byte, error) {
+	b, err := json.Marshal(x)
+	if err != nil || maxDepth <= 0 {
+		return b, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	var tree any
+	if err := dec.Decode(&tree); err != nil {
+		return nil, err
+	}
+	return json.Marshal(prune(tree, 1, maxDepth))
+}
+
+// prune replaces objects and arrays nested deeper than maxDepth.
+func prune(x any, depth, maxDepth int) any {
+	switch t := x.(type) {
+	case map[string]any:
+		if depth > maxDepth {
+			return truncated
+		}
+		for k, v := range t {
+			t[k] = prune(v, depth+1, maxDepth)
+		}
+	case []any:
+		if depth > maxDepth {
+			return truncated
+		}
+		for i, v := range t {
+			t[i] = prune(v, depth+1, maxDepth)
+		}
+	}
+	return x
+}
+
+// truncate shortens s to at most size bytes on a rune boundary, marking the cut.
+func truncate(s string, size int) string {
+	if size <= 0 || len(s) <= size {
+		return s
+	}
+	for size > 0 && !utf8.RuneStart(s[size]) {
+		size--
+	}
+	return s[:size] + truncated
+}
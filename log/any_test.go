@@ -0,0 +1,261 @@
+package log
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"math"
+	"regexp"
+	"testing"
+	"time"
+)
+
+type anyUser struct {
+	Name    string         `json:"name"`
+	Tags    []string       `json:"tags"`
+	Meta    map[string]int `json:"meta,omitempty"`
+	Secret  string         `json:"-"`
+	private string
+	Extra   map[string]string `json:"extra,omitempty"`
+}
+
+type anyNode struct {
+	Name string
+	Next *anyNode
+}
+
+type anyStringer struct{ A int }
+
+func (anyStringer) String() string { return "stringer" }
+
+func TestCLIHandler_Handle_anyFormat(t *testing.T) {
+	user := anyUser{Name: "alice", Tags: []string{"a", "b"}, Meta: map[string]int{"y": 2, "x": 1}, Secret: "s", private: "p"}
+	node := &anyNode{Name: "a", Next: &anyNode{Name: "b"}}
+	node.Next.Next = node
+	shared := &anyNode{Name: "s"}
+	cyclic := make([]any, 2)
+	cyclic[0], cyclic[1] = 1, cyclic
+	tests := []struct {
+		name string
+		opts []CLIHandlerOption
+		attr slog.Attr
+		want string
+	}{
+		{
+			name: "default map",
+			attr: slog.Any("m", map[string]int{"b": 2, "a": 1}),
+			want: "[INF] msg m=\"map[a:1 b:2]\"\n",
+		},
+		{
+			name: "json map",
+			opts: []CLIHandlerOption{WithAnyFormat(AnyFormatJSON)},
+			attr: slog.Any("m", map[string]int{"b": 2, "a": 1}),
+			want: "[INF] msg m=\"{\\\"a\\\":1,\\\"b\\\":2}\"\n",
+		},
+		{
+			name: "json slice",
+			opts: []CLIHandlerOption{WithAnyFormat(AnyFormatJSON)},
+			attr: slog.Any("s", []int{1, 2, 3}),
+			want: "[INF] msg s=[1,2,3]\n",
+		},
+		{
+			name: "json depth limit",
+			opts: []CLIHandlerOption{WithAnyFormat(AnyFormatJSON), WithAnyLimits(1, 0)},
+			attr: slog.Any("u", user),
+			want: "[INF] msg u=\"{\\\"meta\\\":\\\"...\\\",\\\"name\\\":\\\"alice\\\",\\\"tags\\\":\\\"...\\\"}\"\n",
+		},
+		{
+			name: "json size limit",
+			opts: []CLIHandlerOption{WithAnyFormat(AnyFormatJSON), WithAnyLimits(0, 4)},
+			attr: slog.Any("s", []int{1, 2, 3}),
+			want: "[INF] msg s=[1,2...\n",
+		},
+		{
+			name: "json unsupported value falls back",
+			opts: []CLIHandlerOption{WithAnyFormat(AnyFormatJSON)},
+			attr: slog.Any("m", map[string]float64{"f": math.NaN()}),
+			want: "[INF] msg m=map[f:NaN]\n",
+		},
+		{
+			name: "go string",
+			opts: []CLIHandlerOption{WithAnyFormat(AnyFormatGoString)},
+			attr: slog.Any("s", []string{"a"}),
+			want: "[INF] msg s=\"[]string{\\\"a\\\"}\"\n",
+		},
+		{
+			name: "flatten struct",
+			opts: []CLIHandlerOption{WithAnyFormat(AnyFormatFlatten)},
+			attr: slog.Any("u", &user),
+			want: "[INF] msg u.name=alice u.tags.0=a u.tags.1=b u.meta.x=1 u.meta.y=2 u.extra=map[]\n",
+		},
+		{
+			name: "flatten depth limit",
+			opts: []CLIHandlerOption{WithAnyFormat(AnyFormatFlatten), WithAnyLimits(1, 0)},
+			attr: slog.Any("u", user),
+			want: "[INF] msg u.name=alice u.tags=... u.meta=... u.extra=...\n",
+		},
+		{
+			name: "flatten nested values",
+			opts: []CLIHandlerOption{WithAnyFormat(AnyFormatFlatten)},
+			attr: slog.Any("m", map[int]any{2: nil, 1: time.Second}),
+			want: "[INF] msg m.1=1s m.2=<nil>\n",
+		},
+		{
+			name: "flatten cyclic pointer",
+			opts: []CLIHandlerOption{WithAnyFormat(AnyFormatFlatten)},
+			attr: slog.Any("n", node),
+			want: "[INF] msg n.Name=a n.Next.Name=b n.Next.Next=...\n",
+		},
+		{
+			name: "flatten cyclic slice",
+			opts: []CLIHandlerOption{WithAnyFormat(AnyFormatFlatten)},
+			attr: slog.Any("s", cyclic),
+			want: "[INF] msg s.0=1 s.1=...\n",
+		},
+		{
+			name: "flatten shared pointer",
+			opts: []CLIHandlerOption{WithAnyFormat(AnyFormatFlatten)},
+			attr: slog.Any("p", []*anyNode{shared, shared}),
+			want: "[INF] msg p.0.Name=s p.0.Next=<nil> p.1.Name=s p.1.Next=<nil>\n",
+		},
+		{
+			name: "flatten empty value",
+			opts: []CLIHandlerOption{WithAnyFormat(AnyFormatFlatten)},
+			attr: slog.Any("s", []int{}),
+			want: "[INF] msg s=[]\n",
+		},
+		{
+			name: "stringer is kept",
+			opts: []CLIHandlerOption{WithAnyFormat(AnyFormatJSON)},
+			attr: slog.Any("s", anyStringer{A: 1}),
+			want: "[INF] msg s=stringer\n",
+		},
+		{
+			name: "error is kept",
+			opts: []CLIHandlerOption{WithAnyFormat(AnyFormatFlatten)},
+			attr: slog.Any("err", errors.New("boom")),
+			want: "[INF] msg err=boom\n",
+		},
+		{
+			name: "scalar is kept",
+			opts: []CLIHandlerOption{WithAnyFormat(AnyFormatJSON)},
+			attr: slog.Any("n", 1),
+			want: "[INF] msg n=1\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			h := NewCLIHandler(buf, append([]CLIHandlerOption{WithStyle(Style0())}, tt.opts...)...)
+			r := slog.NewRecord(time.Time{}, slog.LevelInfo, "msg", 0)
+			r.AddAttrs(tt.attr)
+			if err := h.Handle(t.Context(), r); err != nil {
+				t.Fatal(err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCLIHandler_Handle_anyFormat_redactor(t *testing.T) {
+	r := NewRedactor(WithKeyPatterns("password"), WithValueRegexps(regexp.MustCompile(`sk-\w+`)))
+	type account struct {
+		Password string
+		Note     string
+	}
+	m := map[string]any{"password": "hunter2", "k": "sk-abc", "nested": map[string]any{"password": []int{1}}}
+	tests := []struct {
+		name string
+		opts []CLIHandlerOption
+		attr slog.Attr
+		want string
+	}{
+		{
+			name: "flatten map",
+			opts: []CLIHandlerOption{WithAnyFormat(AnyFormatFlatten)},
+			attr: slog.Any("user", m),
+			want: "[INF] msg user.k=*** user.nested.password=*** user.password=***\n",
+		},
+		{
+			name: "flatten struct",
+			opts: []CLIHandlerOption{WithAnyFormat(AnyFormatFlatten)},
+			attr: slog.Any("s", account{Password: "hunter3", Note: "key sk-abc"}),
+			want: "[INF] msg s.Password=*** s.Note=\"key ***\"\n",
+		},
+		{
+			name: "flatten multiline",
+			opts: []CLIHandlerOption{WithAnyFormat(AnyFormatFlatten), WithMultiline(true)},
+			attr: slog.Any("s", account{Password: "hunter3", Note: "sk-abc"}),
+			want: "[INF] msg\n  s.Password=***\n  s.Note    =***\n",
+		},
+		{
+			name: "json",
+			opts: []CLIHandlerOption{WithAnyFormat(AnyFormatJSON)},
+			attr: slog.Any("user", m),
+			want: "[INF] msg user=\"{\\\"k\\\":\\\"***\\\",\\\"nested\\\":{\\\"password\\\":\\\"***\\\"},\\\"password\\\":\\\"***\\\"}\"\n",
+		},
+		{
+			name: "json keeps field order",
+			opts: []CLIHandlerOption{WithAnyFormat(AnyFormatJSON)},
+			attr: slog.Any("s", account{Password: "hunter3", Note: "x"}),
+			want: "[INF] msg s=\"{\\\"Password\\\":\\\"***\\\",\\\"Note\\\":\\\"x\\\"}\"\n",
+		},
+		{
+			name: "go string",
+			opts: []CLIHandlerOption{WithAnyFormat(AnyFormatGoString)},
+			attr: slog.Any("s", []string{"sk-abc"}),
+			want: "[INF] msg s=\"[]string{\\\"***\\\"}\"\n",
+		},
+		{
+			name: "default",
+			attr: slog.Any("s", []string{"sk-abc"}),
+			want: "[INF] msg s=[***]\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			h := NewCLIHandler(buf, append([]CLIHandlerOption{WithStyle(Style0()), WithRedactor(r)}, tt.opts...)...)
+			rec := slog.NewRecord(time.Time{}, slog.LevelInfo, "msg", 0)
+			rec.AddAttrs(tt.attr)
+			if err := h.Handle(t.Context(), rec); err != nil {
+				t.Fatal(err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithAnyLimits(t *testing.T) {
+	h := &CLIHandler{}
+	WithAnyLimits(-1, -2)(h)
+	if h.anyDepth != 0 || h.anySize != 0 {
+		t.Errorf("negative limits = (%d, %d), want (0, 0)", h.anyDepth, h.anySize)
+	}
+	WithAnyLimits(2, 64)(h)
+	if h.anyDepth != 2 || h.anySize != 64 {
+		t.Errorf("limits = (%d, %d), want (2, 64)", h.anyDepth, h.anySize)
+	}
+}
+
+func Test_truncate(t *testing.T) {
+	tests := []struct {
+		s    string
+		size int
+		want string
+	}{
+		{"hello", 0, "hello"},
+		{"hello", 5, "hello"},
+		{"hello", 3, "hel..."},
+		{"日本語", 4, "日..."},
+	}
+	for _, tt := range tests {
+		if got := truncate(tt.s, tt.size); got != tt.want {
+			t.Errorf("truncate(%q, %d) = %q, want %q", tt.s, tt.size, got, tt.want)
+		}
+	}
+}
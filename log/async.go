@@ -0,0 +1,273 @@
+package log
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	_ slog.Handler = (*AsyncHandler)(nil)
+	_ Flusher      = (*AsyncHandler)(nil)
+	_ Closer       = (*AsyncHandler)(nil)
+)
+
+// DropPolicy defines what an AsyncHandler does when its queue is full.
+type DropPolicy int
+
+const (
+	// DropNone blocks the caller until the queue has room.
+	DropNone DropPolicy = iota
+
+	// DropNewest discards the incoming record.
+	DropNewest
+
+	// DropOldest discards the oldest queued record to make room for the incoming one.
+	DropOldest
+)
+
+// AsyncHandler is a slog.Handler that enqueues records and passes them to the
+// inner handler from a background goroutine. Close must be called to drain the
+// queue before the program exits.
+type AsyncHandler struct {
+	inner slog.Handler
+	state *asyncState
+}
+
+// asyncState is the state shared by an AsyncHandler and its derived handlers.
+type asyncState struct {
+	queue         chan asyncEntry
+	queueSize     int
+	flushInterval time.Duration
+	dropPolicy    DropPolicy
+	mu            sync.RWMutex
+	closed        bool
+	once          sync.Once
+	done          chan struct{}
+	dropped       atomic.Uint64
+	errMu         sync.Mutex
+	err           error
+	flusher       Flusher
+}
+
+// asyncEntry is a queued record or a flush request.
+type asyncEntry struct {
+	ctx     context.Context
+	r       slog.Record
+	handler slog.Handler
+	flushed chan error
+}
+
+// NewAsyncHandler creates a new AsyncHandler wrapping inner with the given options
+// and starts its background goroutine.
+func NewAsyncHandler(inner slog.Handler, opts ...AsyncHandlerOption) *AsyncHandler {
+	if inner == nil {
+		inner = NewCLIHandler(nil)
+	}
+	st := &asyncState{
+		queueSize:     1024,
+		flushInterval: time.Second,
+		done:          make(chan struct{}),
+	}
+	h := &AsyncHandler{inner: inner, state: st}
+	for _, opt := range opts {
+		opt(h)
+	}
+	st.queue = make(chan asyncEntry, st.queueSize)
+	if f, ok := inner.(Flusher); ok {
+		st.flusher = f
+	}
+	go st.run()
+	return h
+}
+
+// AsyncHandlerOption defines a function type for configuring an AsyncHandler.
+type AsyncHandlerOption func(*AsyncHandler)
+
+// WithQueueSize returns an AsyncHandlerOption that sets the queue capacity.
+func WithQueueSize(n int) AsyncHandlerOption {
+	return func(h *AsyncHandler) {
+		if n > 0 {
+			h.state.queueSize = n
+		}
+	}
+}
+
+// WithFlushInterval returns an AsyncHandlerOption that sets how often the inner
+// handler is flushed if it implements Flusher. Zero disables periodic flushing.
+func WithFlushInterval(d time.Duration) AsyncHandlerOption {
+	return func(h *AsyncHandler) {
+		if d >= 0 {
+			h.state.flushInterval = d
+		}
+	}
+}
+
+// WithDropPolicy returns an AsyncHandlerOption that sets the behavior when the queue is full.
+func WithDropPolicy(policy DropPolicy) AsyncHandlerOption {
+	return func(h *AsyncHandler) {
+		h.state.dropPolicy = policy
+	}
+}
+
+// Enabled reports whether the inner handler is enabled for the given level.
+func (h *AsyncHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.inner.Enabled(ctx, level)
+}
+
+// Handle enqueues the record. Errors from the inner handler are reported by Flush and Close.
+func (h *AsyncHandler) Handle(ctx context.Context, r slog.Record) error {
+	st := h.state
+	st.mu.RLock()
+	defer st.mu.RUnlock()
+	if st.closed {
+		return ErrHandlerClosed
+	}
+	e := asyncEntry{ctx: ctx, r: r.Clone(), handler: h.inner}
+	switch st.dropPolicy {
+	case DropNewest:
+		select {
+		case st.queue <- e:
+		default:
+			st.dropped.Add(1)
+		}
+	case DropOldest:
+		for {
+			select {
+			case st.queue <- e:
+				return nil
+			default:
+			}
+			select {
+			case old := <-st.queue:
+				if old.flushed != nil {
+					// Never discard a flush request; put it back and retry.
+					st.queue <- old
+					continue
+				}
+				st.dropped.Add(1)
+			default:
+			}
+		}
+	default:
+		st.queue <- e
+	}
+	return nil
+}
+
+// WithAttrs returns a new AsyncHandler whose inner handler has the given attributes.
+// The queue is shared with the receiver.
+func (h *AsyncHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	return &AsyncHandler{inner: h.inner.WithAttrs(attrs), state: h.state}
+}
+
+// WithGroup returns a new AsyncHandler whose inner handler has the given group.
+// The queue is shared with the receiver.
+func (h *AsyncHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return &AsyncHandler{inner: h.inner.WithGroup(name), state: h.state}
+}
+
+// Unwrap returns the inner handler.
+func (h *AsyncHandler) Unwrap() slog.Handler {
+	return h.inner
+}
+
+// Flush blocks until every record enqueued before the call has been handled,
+// then flushes the inner handler. It returns the errors reported since the last
+// Flush or Close.
+func (h *AsyncHandler) Flush() error {
+	st := h.state
+	st.mu.RLock()
+	if st.closed {
+		st.mu.RUnlock()
+		return st.takeErr()
+	}
+	flushed := make(chan error, 1)
+	st.queue <- asyncEntry{flushed: flushed}
+	st.mu.RUnlock()
+	return <-flushed
+}
+
+// Close stops accepting records, drains the queue, flushes the inner handler,
+// and stops the background goroutine. It is safe to call more than once.
+func (h *AsyncHandler) Close() error {
+	st := h.state
+	st.once.Do(func() {
+		st.mu.Lock()
+		st.closed = true
+		close(st.queue)
+		st.mu.Unlock()
+	})
+	<-st.done
+	return st.takeErr()
+}
+
+// Dropped returns the number of records dropped because the queue was full.
+func (h *AsyncHandler) Dropped() uint64 {
+	return h.state.dropped.Load()
+}
+
+// run handles queued entries until the queue is closed.
+func (st *asyncState) run() {
+	defer close(st.done)
+	var tick <-chan time.Time
+	if st.flushInterval > 0 && st.flusher != nil {
+		t := time.NewTicker(st.flushInterval)
+		defer t.Stop()
+		tick = t.C
+	}
+	for {
+		select {
+		case e, ok := <-st.queue:
+			if !ok {
+				st.flush()
+				return
+			}
+			if e.flushed != nil {
+				st.flush()
+				e.flushed <- st.takeErr()
+				continue
+			}
+			if err := e.handler.Handle(e.ctx, e.r); err != nil {
+				st.setErr(err)
+			}
+		case <-tick:
+			st.flush()
+		}
+	}
+}
+
+// flush flushes the inner handler if it implements Flusher.
+func (st *asyncState) flush() {
+	if st.flusher == nil {
+		return
+	}
+	if err := st.flusher.Flush(); err != nil {
+		st.setErr(err)
+	}
+}
+
+// setErr records an error from the inner handler.
+func (st *asyncState) setErr(err error) {
+	st.errMu.Lock()
+	defer st.errMu.Unlock()
+	st.err = errors.Join(st.err, err)
+}
+
+// takeErr returns and clears the recorded errors.
+func (st *asyncState) takeErr() error {
+	st.errMu.Lock()
+	defer st.errMu.Unlock()
+	err := st.err
+	st.err = nil
+	return err
+}
@@ -0,0 +1,291 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// syncBuffer is a bytes.Buffer safe for concurrent use.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// flushHandler is a handler that counts flushes and blocks Handle until released.
+type flushHandler struct {
+	slog.Handler
+	mu      sync.Mutex
+	flushes int
+	release chan struct{}
+	err     error
+}
+
+func (h *flushHandler) Handle(ctx context.Context, r slog.Record) error {
+	if h.release != nil {
+		<-h.release
+	}
+	if err := h.Handler.Handle(ctx, r); err != nil {
+		return err
+	}
+	return h.err
+}
+
+func (h *flushHandler) Flush() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.flushes++
+	return nil
+}
+
+func (h *flushHandler) Flushes() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.flushes
+}
+
+func TestNewAsyncHandler(t *testing.T) {
+	type args struct {
+		inner slog.Handler
+		opts  []AsyncHandlerOption
+	}
+	tests := []struct {
+		name  string
+		args  args
+		check func(*testing.T, *AsyncHandler)
+	}{
+		{
+			name: "default",
+			args: args{inner: NewCLIHandler(io.Discard)},
+			check: func(t *testing.T, h *AsyncHandler) {
+				if cap(h.state.queue) != 1024 {
+					t.Errorf("queue size = %v, want 1024", cap(h.state.queue))
+				}
+				if h.state.flushInterval != time.Second {
+					t.Errorf("flushInterval = %v, want %v", h.state.flushInterval, time.Second)
+				}
+				if h.state.dropPolicy != DropNone {
+					t.Errorf("dropPolicy = %v, want %v", h.state.dropPolicy, DropNone)
+				}
+			},
+		},
+		{
+			name: "nil inner",
+			args: args{inner: nil},
+			check: func(t *testing.T, h *AsyncHandler) {
+				if h.inner == nil {
+					t.Error("inner is nil")
+				}
+			},
+		},
+		{
+			name: "all options",
+			args: args{
+				inner: NewCLIHandler(io.Discard),
+				opts: []AsyncHandlerOption{
+					WithQueueSize(8),
+					WithFlushInterval(0),
+					WithDropPolicy(DropOldest),
+				},
+			},
+			check: func(t *testing.T, h *AsyncHandler) {
+				if cap(h.state.queue) != 8 {
+					t.Errorf("queue size = %v, want 8", cap(h.state.queue))
+				}
+				if h.state.flushInterval != 0 {
+					t.Errorf("flushInterval = %v, want 0", h.state.flushInterval)
+				}
+				if h.state.dropPolicy != DropOldest {
+					t.Errorf("dropPolicy = %v, want %v", h.state.dropPolicy, DropOldest)
+				}
+			},
+		},
+		{
+			name: "invalid values ignored",
+			args: args{
+				inner: NewCLIHandler(io.Discard),
+				opts:  []AsyncHandlerOption{WithQueueSize(0), WithFlushInterval(-time.Second)},
+			},
+			check: func(t *testing.T, h *AsyncHandler) {
+				if cap(h.state.queue) != 1024 {
+					t.Errorf("queue size = %v, want 1024", cap(h.state.queue))
+				}
+				if h.state.flushInterval != time.Second {
+					t.Errorf("flushInterval = %v, want %v", h.state.flushInterval, time.Second)
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NewAsyncHandler(tt.args.inner, tt.args.opts...)
+			defer got.Close()
+			tt.check(t, got)
+		})
+	}
+}
+
+func TestAsyncHandler_Handle(t *testing.T) {
+	buf := &syncBuffer{}
+	h := NewAsyncHandler(NewCLIHandler(buf, WithStyle(Style0())))
+	l := NewLogger(h.WithGroup("g1").WithAttrs([]slog.Attr{slog.String("key", "val")}))
+	l.Info("first", "a", 1)
+	l.Warn("second")
+	if err := h.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	want := "[INF] first g1.key=val g1.a=1\n[WRN] second g1.key=val\n"
+	if got := buf.String(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if err := h.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.Handle(context.Background(), slog.NewRecord(time.Time{}, slog.LevelInfo, "late", 0)); !errors.Is(err, ErrHandlerClosed) {
+		t.Errorf("Handle() after Close error = %v, want %v", err, ErrHandlerClosed)
+	}
+}
+
+func TestAsyncHandler_dropPolicy(t *testing.T) {
+	tests := []struct {
+		name        string
+		policy      DropPolicy
+		want        string
+		wantDropped uint64
+	}{
+		{
+			name:        "drop newest",
+			policy:      DropNewest,
+			want:        "[INF] 0\n[INF] 1\n",
+			wantDropped: 2,
+		},
+		{
+			name:        "drop oldest",
+			policy:      DropOldest,
+			want:        "[INF] 0\n[INF] 3\n",
+			wantDropped: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &syncBuffer{}
+			inner := &flushHandler{
+				Handler: NewCLIHandler(buf, WithStyle(Style0())),
+				release: make(chan struct{}),
+			}
+			h := NewAsyncHandler(inner, WithQueueSize(1), WithDropPolicy(tt.policy))
+			// The first record is taken by the worker, which blocks in the inner handler.
+			_ = h.Handle(context.Background(), slog.NewRecord(time.Time{}, slog.LevelInfo, "0", 0))
+			for len(h.state.queue) != 0 {
+				time.Sleep(time.Millisecond)
+			}
+			for _, msg := range []string{"1", "2", "3"} {
+				_ = h.Handle(context.Background(), slog.NewRecord(time.Time{}, slog.LevelInfo, msg, 0))
+			}
+			close(inner.release)
+			if err := h.Close(); err != nil {
+				t.Fatal(err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+			if got := h.Dropped(); got != tt.wantDropped {
+				t.Errorf("Dropped() = %v, want %v", got, tt.wantDropped)
+			}
+		})
+	}
+}
+
+func TestAsyncHandler_Flush(t *testing.T) {
+	errBoom := errors.New("boom")
+	inner := &flushHandler{Handler: NewCLIHandler(io.Discard), err: errBoom}
+	h := NewAsyncHandler(inner, WithFlushInterval(0))
+	_ = h.Handle(context.Background(), slog.NewRecord(time.Time{}, slog.LevelInfo, "msg", 0))
+	if err := h.Flush(); !errors.Is(err, errBoom) {
+		t.Errorf("Flush() error = %v, want %v", err, errBoom)
+	}
+	if got := inner.Flushes(); got != 1 {
+		t.Errorf("flushes = %v, want 1", got)
+	}
+	if err := h.Flush(); err != nil {
+		t.Errorf("Flush() error = %v, want nil after errors were reported", err)
+	}
+	if err := h.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.Close(); err != nil {
+		t.Errorf("second Close() error = %v", err)
+	}
+	if err := h.Flush(); err != nil {
+		t.Errorf("Flush() after Close error = %v", err)
+	}
+}
+
+func TestAsyncHandler_flushInterval(t *testing.T) {
+	inner := &flushHandler{Handler: NewCLIHandler(io.Discard)}
+	h := NewAsyncHandler(inner, WithFlushInterval(time.Millisecond))
+	defer h.Close()
+	deadline := time.Now().Add(time.Second)
+	for inner.Flushes() < 2 {
+		if time.Now().After(deadline) {
+			t.Fatal("inner handler was not flushed periodically")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestAsyncHandler_concurrent(t *testing.T) {
+	buf := &syncBuffer{}
+	h := NewAsyncHandler(NewCLIHandler(buf, WithStyle(Style0())), WithQueueSize(4))
+	l := NewLogger(h)
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Go(func() {
+			for range 50 {
+				l.Info("msg")
+			}
+		})
+	}
+	wg.Wait()
+	if err := h.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Count(buf.String(), "\n"); got != 400 {
+		t.Errorf("lines = %v, want 400", got)
+	}
+}
+
+func TestAsyncHandler_WithGroup(t *testing.T) {
+	h := NewAsyncHandler(NewCLIHandler(io.Discard))
+	defer h.Close()
+	if got := h.WithGroup(""); got != h {
+		t.Error("want same handler instance for empty name")
+	}
+	if got := h.WithAttrs(nil); got != h {
+		t.Error("want same handler instance for empty attrs")
+	}
+	if got := h.WithGroup("g1").(*AsyncHandler); got.state != h.state {
+		t.Error("want shared state")
+	}
+	if h.Enabled(context.Background(), slog.LevelDebug) {
+		t.Error("Enabled(debug) = true, want false")
+	}
+}
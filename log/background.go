@@ -0,0 +1,182 @@
+package log
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Background describes the brightness of the terminal background.
+type Background int
+
+const (
+	// BackgroundDark is a dark background, such as black.
+	BackgroundDark Background = iota + 1
+
+	// BackgroundLight is a light background, such as white.
+	BackgroundLight
+)
+
+// String returns the name of the background.
+func (b Background) String() string {
+	switch b {
+	case BackgroundDark:
+		return "dark"
+	case BackgroundLight:
+		return "light"
+	default:
+		return "unknown"
+	}
+}
+
+// backgroundTimeout is how long DetectBackground waits for the terminal to
+// answer its query.
+const backgroundTimeout = 100 * time.Millisecond
+
+// DetectBackground reports whether the background of the current terminal is
+// light or dark. COLORFGBG is consulted first; otherwise the terminal is asked
+// for its background color with an OSC 11 query. Terminals that do not answer
+// within a short timeout, and platforms without a controlling terminal, are
+// assumed to be dark. The result is computed once and cached.
+func DetectBackground() Background {
+	return detectBackground()
+}
+
+// detectBackground caches the result of DetectBackground.
+var detectBackground = sync.OnceValue(func() Background {
+	if bg, ok := backgroundFromEnv(os.Getenv("COLORFGBG")); ok {
+		return bg
+	}
+	if r, g, b, ok := queryBackground(backgroundTimeout); ok {
+		return backgroundOf(r, g, b)
+	}
+	return BackgroundDark
+})
+
+// backgroundFromEnv parses a COLORFGBG value such as "15;0", whose last field is
+// the basic color of the background.
+func backgroundFromEnv(v string) (Background, bool) {
+	if v == "" {
+		return 0, false
+	}
+	n, err := strconv.Atoi(v[strings.LastIndexByte(v, ';')+1:])
+	if err != nil || n < 0 || n > 15 {
+		return 0, false
+	}
+	if n == 7 || n > 8 {
+		return BackgroundLight, true
+	}
+	return BackgroundDark, true
+}
+
+// backgroundOf returns the background of the given color by its luminance.
+func backgroundOf(r, g, b uint8) Background {
+	if 0.2126*float64(r)+0.7152*float64(g)+0.0722*float64(b) > 127.5 {
+		return BackgroundLight
+	}
+	return BackgroundDark
+}
+
+// parseOSC11 parses the color of an OSC 11 response such as
+// "\x1b]11;rgb:ffff/ffff/ffff\x1b\\", whose channels have 1 to 4 hex digits.
+func parseOSC11(resp []byte) (r, g, b uint8, ok bool) {
+	_, rest, found := bytes.Cut(resp, []byte("]11;rgb:"))
+	if !found {
+		return 0, 0, 0, false
+	}
+	if i := bytes.IndexAny(rest, "\x07\x1b"); i >= 0 {
+		rest = rest[:i]
+	}
+	parts := strings.Split(string(rest), "/")
+	if len(parts) != 3 {
+		return 0, 0, 0, false
+	}
+	var rgb [3]uint8
+	for i, p := range parts {
+		if len(p) == 0 || len(p) > 4 {
+			return 0, 0, 0, false
+		}
+		v, err := strconv.ParseUint(p, 16, 16)
+		if err != nil {
+			return 0, 0, 0, false
+		}
+		rgb[i] = uint8(v * 255 / (1<<(4*len(p)) - 1))
+	}
+	return rgb[0], rgb[1], rgb[2], true
+}
+
+// hasDeviceAttributes reports whether b holds a primary device attributes
+// response such as "\x1b[?62;22c", which terminals send after answering the
+// queries written before it.
+func hasDeviceAttributes(b []byte) bool {
+	for {
+		i := bytes.Index(b, []byte("\x1b[?"))
+		if i < 0 {
+			return false
+		}
+		b = b[i+3:]
+		j := 0
+		for j < len(b) && (b[j] >= '0' && b[j] <= '9' || b[j] == ';') {
+			j++
+		}
+		if j < len(b) && b[j] == 'c' {
+			return true
+		}
+	}
+}
+
+// background returns the background that adaptive colors written to w are
+// chosen for. An explicit background wins; otherwise terminals are probed with
+// DetectBackground and other writers are assumed to be dark.
+func background(w io.Writer, bg Background) Background {
+	if bg != 0 {
+		return bg
+	}
+	if terminal(w) != nil {
+		return DetectBackground()
+	}
+	return BackgroundDark
+}
+
+// AdaptiveColor holds the colors of a style element for light and dark
+// terminal backgrounds.
+type AdaptiveColor struct {
+	Light *Color
+	Dark  *Color
+}
+
+// Color returns a Color rendered as Light on light backgrounds and as Dark on
+// dark ones. Handlers choose the variant when they are created, according to
+// WithBackground or DetectBackground; written directly, the Color renders as Dark.
+func (a AdaptiveColor) Color() *Color {
+	c := &Color{adaptive: &a}
+	if a.Dark != nil {
+		c.codes, c.prefix, c.reset = a.Dark.codes, a.Dark.prefix, a.Dark.reset
+	}
+	return c
+}
+
+// adapt returns the variant of an adaptive Color for the background returned
+// by bg, which is only called for adaptive colors. Other colors are returned
+// unchanged.
+func (c *Color) adapt(bg func() Background) *Color {
+	if c == nil || c.adaptive == nil {
+		return c
+	}
+	if bg() == BackgroundLight {
+		return c.adaptive.Light.adapt(bg)
+	}
+	return c.adaptive.Dark.adapt(bg)
+}
+
+// adapt returns a copy of the Style with every adaptive Color replaced by its
+// variant for the background returned by bg.
+func (s *Style) adapt(bg func() Background) *Style {
+	return s.mapColors(func(c *Color) *Color {
+		return c.adapt(bg)
+	})
+}
@@ -0,0 +1,10 @@
+//go:build !(darwin || dragonfly || freebsd || linux || netbsd || openbsd)
+
+package log
+
+import "time"
+
+// queryBackground has no terminal query on this platform.
+func queryBackground(time.Duration) (r, g, b uint8, ok bool) {
+	return 0, 0, 0, false
+}
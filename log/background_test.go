@@ -0,0 +1,198 @@
+package log
+
+import (
+	"bytes"
+	"log/slog"
+	"reflect"
+	"testing"
+)
+
+func TestBackground_String(t *testing.T) {
+	tests := []struct {
+		bg   Background
+		want string
+	}{
+		{bg: BackgroundDark, want: "dark"},
+		{bg: BackgroundLight, want: "light"},
+		{bg: 0, want: "unknown"},
+	}
+	for _, tt := range tests {
+		if got := tt.bg.String(); got != tt.want {
+			t.Errorf("String() = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func Test_backgroundFromEnv(t *testing.T) {
+	tests := []struct {
+		in     string
+		want   Background
+		wantOK bool
+	}{
+		{in: "15;0", want: BackgroundDark, wantOK: true},
+		{in: "0;15", want: BackgroundLight, wantOK: true},
+		{in: "0;default;7", want: BackgroundLight, wantOK: true},
+		{in: "7;8", want: BackgroundDark, wantOK: true},
+		{in: "", wantOK: false},
+		{in: "15;default", wantOK: false},
+		{in: "0;16", wantOK: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, ok := backgroundFromEnv(tt.in)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("backgroundFromEnv(%q) = %v, %v, want %v, %v", tt.in, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func Test_backgroundOf(t *testing.T) {
+	tests := []struct {
+		name    string
+		r, g, b uint8
+		want    Background
+	}{
+		{name: "black", r: 0, g: 0, b: 0, want: BackgroundDark},
+		{name: "white", r: 255, g: 255, b: 255, want: BackgroundLight},
+		{name: "solarized dark", r: 0, g: 43, b: 54, want: BackgroundDark},
+		{name: "solarized light", r: 253, g: 246, b: 227, want: BackgroundLight},
+		{name: "pure blue", r: 0, g: 0, b: 255, want: BackgroundDark},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := backgroundOf(tt.r, tt.g, tt.b); got != tt.want {
+				t.Errorf("backgroundOf() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_parseOSC11(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		r, g, b uint8
+		wantOK  bool
+	}{
+		{name: "st", in: "\x1b]11;rgb:ffff/8080/0000\x1b\\", r: 255, g: 128, b: 0, wantOK: true},
+		{name: "bel", in: "\x1b]11;rgb:00/2b/36\x07", r: 0, g: 43, b: 54, wantOK: true},
+		{name: "short channels", in: "\x1b]11;rgb:f/0/8\x07\x1b[?62;22c", r: 255, g: 0, b: 136, wantOK: true},
+		{name: "no answer", in: "\x1b[?1;2c", wantOK: false},
+		{name: "bad channel", in: "\x1b]11;rgb:ff/gg/00\x07", wantOK: false},
+		{name: "two channels", in: "\x1b]11;rgb:ff/00\x07", wantOK: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, g, b, ok := parseOSC11([]byte(tt.in))
+			if ok != tt.wantOK || ok && (r != tt.r || g != tt.g || b != tt.b) {
+				t.Errorf("parseOSC11(%q) = %d, %d, %d, %v, want %d, %d, %d, %v", tt.in, r, g, b, ok, tt.r, tt.g, tt.b, tt.wantOK)
+			}
+		})
+	}
+}
+
+func Test_hasDeviceAttributes(t *testing.T) {
+	tests := []struct {
+		in   string
+		want bool
+	}{
+		{in: "\x1b[?62;22c", want: true},
+		{in: "\x1b]11;rgb:0/0/0\x07\x1b[?1;2c", want: true},
+		{in: "\x1b[?62;22", want: false},
+		{in: "\x1b[?x\x1b[?6c", want: true},
+		{in: "", want: false},
+	}
+	for _, tt := range tests {
+		if got := hasDeviceAttributes([]byte(tt.in)); got != tt.want {
+			t.Errorf("hasDeviceAttributes(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func Test_background(t *testing.T) {
+	if got := background(&bytes.Buffer{}, 0); got != BackgroundDark {
+		t.Errorf("background() = %v, want %v", got, BackgroundDark)
+	}
+	if got := background(&bytes.Buffer{}, BackgroundLight); got != BackgroundLight {
+		t.Errorf("background() = %v, want %v", got, BackgroundLight)
+	}
+}
+
+func TestAdaptiveColor_Color(t *testing.T) {
+	light, dark := NewColor(FgBlue), NewColor(FgHiCyan)
+	c := AdaptiveColor{Light: light, Dark: dark}.Color()
+	buf := &bytes.Buffer{}
+	c.WriteString(buf, "x")
+	if got, want := buf.String(), "\x1b[96mx\x1b[0m"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	tests := []struct {
+		name  string
+		color *Color
+		bg    Background
+		want  *Color
+	}{
+		{name: "light", color: c, bg: BackgroundLight, want: light},
+		{name: "dark", color: c, bg: BackgroundDark, want: dark},
+		{name: "no light variant", color: AdaptiveColor{Dark: dark}.Color(), bg: BackgroundLight, want: nil},
+		{name: "plain", color: light, bg: BackgroundDark, want: light},
+		{name: "nil", color: nil, bg: BackgroundLight, want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.color.adapt(func() Background { return tt.bg }); got != tt.want {
+				t.Errorf("adapt() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStyle_adapt(t *testing.T) {
+	calls := 0
+	bg := func() Background {
+		calls++
+		return BackgroundLight
+	}
+	if Style1().adapt(bg); calls != 0 {
+		t.Errorf("background checked %d times for a style without adaptive colors", calls)
+	}
+	s := Style1()
+	s.Attr.KeyColor = AdaptiveColor{Light: NewColor(FgBlack), Dark: NewColor(FgHiWhite)}.Color()
+	got := s.adapt(bg)
+	if !reflect.DeepEqual(got.Attr.KeyColor, NewColor(FgBlack)) {
+		t.Errorf("key color = %v, want the light variant", got.Attr.KeyColor)
+	}
+	if s.Attr.KeyColor.adaptive == nil {
+		t.Error("original style was modified")
+	}
+}
+
+func TestCLIHandler_background(t *testing.T) {
+	s := Style0()
+	s.Level[slog.LevelInfo] = LevelStyle{
+		Text:  "INF",
+		Color: AdaptiveColor{Light: NewColor(FgBlue), Dark: NewColor(FgHiCyan)}.Color(),
+	}
+	tests := []struct {
+		name string
+		opts []CLIHandlerOption
+		want string
+	}{
+		{name: "default", want: "\x1b[96mINF\x1b[0m msg\n"},
+		{name: "light", opts: []CLIHandlerOption{WithBackground(BackgroundLight)}, want: "\x1b[34mINF\x1b[0m msg\n"},
+		{name: "dark", opts: []CLIHandlerOption{WithBackground(BackgroundDark)}, want: "\x1b[96mINF\x1b[0m msg\n"},
+		{name: "no colors", opts: []CLIHandlerOption{WithBackground(BackgroundLight), WithColorMode(ColorNever)}, want: "INF msg\n"},
+		{name: "downgraded", opts: []CLIHandlerOption{WithColorProfile(ProfileANSI)}, want: "\x1b[96mINF\x1b[0m msg\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			opts := append([]CLIHandlerOption{WithStyle(s), WithColorMode(ColorAlways)}, tt.opts...)
+			NewLogger(NewCLIHandler(buf, opts...)).Info("msg")
+			if got := buf.String(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd
+
+package log
+
+import (
+	"bytes"
+	"os"
+	"time"
+
+	"golang.org/x/sys/unix"
+	"golang.org/x/term"
+)
+
+// queryBackground asks the controlling terminal for its background color with
+// an OSC 11 query followed by a device attributes query, which every terminal
+// answers, so that terminals ignoring the first query are not waited on. The
+// terminal is only queried by a foreground process.
+func queryBackground(timeout time.Duration) (r, g, b uint8, ok bool) {
+	tty, err := os.OpenFile("/dev/tty", os.O_RDWR|unix.O_NOCTTY, 0)
+	if err != nil {
+		return 0, 0, 0, false
+	}
+	defer tty.Close()
+	conn, err := tty.SyscallConn()
+	if err != nil {
+		return 0, 0, 0, false
+	}
+	var state *term.State
+	if err := conn.Control(func(fd uintptr) {
+		pgrp, err := unix.IoctlGetInt(int(fd), unix.TIOCGPGRP)
+		if err != nil || pgrp != unix.Getpgrp() {
+			return
+		}
+		state, _ = term.MakeRaw(int(fd))
+	}); err != nil || state == nil {
+		return 0, 0, 0, false
+	}
+	defer conn.Control(func(fd uintptr) {
+		_ = term.Restore(int(fd), state)
+	})
+	if err := tty.SetReadDeadline(time.Now().Add(timeout)); err != nil {
+		return 0, 0, 0, false
+	}
+	if _, err := tty.WriteString("\x1b]11;?\x1b\\\x1b[c"); err != nil {
+		return 0, 0, 0, false
+	}
+	var resp bytes.Buffer
+	buf := make([]byte, 64)
+	for resp.Len() < 1024 && !hasDeviceAttributes(resp.Bytes()) {
+		n, err := tty.Read(buf)
+		resp.Write(buf[:n])
+		if err != nil {
+			break
+		}
+	}
+	return parseOSC11(resp.Bytes())
+}
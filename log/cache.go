@@ -0,0 +1,78 @@
+package log
+
+import (
+	"container/list"
+	"sync"
+)
+
+// callerCacheSize is the number of callers a CLIHandler keeps formatted.
+// Programs rarely log from more call sites, and the least recently used ones
+// are evicted if they do.
+const callerCacheSize = 4096
+
+// callerCache is a size-bounded LRU cache of formatted callers keyed by
+// program counter. It is safe for concurrent use and shared by the handlers
+// derived from the same CLIHandler. A nil cache caches nothing.
+type callerCache struct {
+	mu      sync.Mutex
+	size    int
+	entries map[uintptr]*list.Element
+	order   list.List // of *callerEntry, most recently used first
+}
+
+// callerEntry is an entry of a callerCache.
+type callerEntry struct {
+	pc uintptr
+	b  []byte
+}
+
+// newCallerCache returns a callerCache holding up to size callers.
+func newCallerCache(size int) *callerCache {
+	return &callerCache{
+		size:    max(size, 1),
+		entries: make(map[uintptr]*list.Element),
+	}
+}
+
+// get returns the cached caller of pc and marks it as recently used.
+func (c *callerCache) get(pc uintptr) ([]byte, bool) {
+	if c == nil {
+		return nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[pc]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(e)
+	return e.Value.(*callerEntry).b, true
+}
+
+// put caches the caller of pc, evicting the least recently used caller if the
+// cache is full.
+func (c *callerCache) put(pc uintptr, b []byte) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.entries[pc]; ok {
+		e.Value.(*callerEntry).b = b
+		c.order.MoveToFront(e)
+		return
+	}
+	if c.order.Len() >= c.size {
+		last := c.order.Back()
+		delete(c.entries, last.Value.(*callerEntry).pc)
+		c.order.Remove(last)
+	}
+	c.entries[pc] = c.order.PushFront(&callerEntry{pc: pc, b: b})
+}
+
+// len returns the number of cached callers.
+func (c *callerCache) len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
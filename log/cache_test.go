@@ -0,0 +1,123 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"runtime"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+func Test_callerCache(t *testing.T) {
+	tests := []struct {
+		name string
+		size int
+		run  func(c *callerCache)
+		want map[uintptr]string
+		len  int
+	}{
+		{
+			name: "hit",
+			size: 2,
+			run: func(c *callerCache) {
+				c.put(1, []byte("a.go:1"))
+			},
+			want: map[uintptr]string{1: "a.go:1"},
+			len:  1,
+		},
+		{
+			name: "evict least recently put",
+			size: 2,
+			run: func(c *callerCache) {
+				c.put(1, []byte("a.go:1"))
+				c.put(2, []byte("b.go:2"))
+				c.put(3, []byte("c.go:3"))
+			},
+			want: map[uintptr]string{1: "", 2: "b.go:2", 3: "c.go:3"},
+			len:  2,
+		},
+		{
+			name: "evict least recently got",
+			size: 2,
+			run: func(c *callerCache) {
+				c.put(1, []byte("a.go:1"))
+				c.put(2, []byte("b.go:2"))
+				c.get(1)
+				c.put(3, []byte("c.go:3"))
+			},
+			want: map[uintptr]string{1: "a.go:1", 2: "", 3: "c.go:3"},
+			len:  2,
+		},
+		{
+			name: "replace",
+			size: 2,
+			run: func(c *callerCache) {
+				c.put(1, []byte("a.go:1"))
+				c.put(1, []byte("a.go:2"))
+			},
+			want: map[uintptr]string{1: "a.go:2"},
+			len:  1,
+		},
+		{
+			name: "zero size",
+			size: 0,
+			run: func(c *callerCache) {
+				c.put(1, []byte("a.go:1"))
+				c.put(2, []byte("b.go:2"))
+			},
+			want: map[uintptr]string{1: "", 2: "b.go:2"},
+			len:  1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newCallerCache(tt.size)
+			tt.run(c)
+			if got := c.len(); got != tt.len {
+				t.Errorf("len = %d, want %d", got, tt.len)
+			}
+			for pc, want := range tt.want {
+				b, ok := c.get(pc)
+				if ok != (want != "") || string(b) != want {
+					t.Errorf("get(%d) = %q, %t, want %q", pc, b, ok, want)
+				}
+			}
+		})
+	}
+}
+
+func Test_callerCache_nil(t *testing.T) {
+	var c *callerCache
+	c.put(1, []byte("a.go:1"))
+	if b, ok := c.get(1); ok {
+		t.Errorf("got %q, want no caller", b)
+	}
+}
+
+func TestCLIHandler_callerCache_concurrent(t *testing.T) {
+	buf := &bytes.Buffer{}
+	h := NewCLIHandler(buf, WithCaller(true)).(*CLIHandler)
+	h.pcCache = newCallerCache(4)
+	var pcs [16]uintptr
+	n := runtime.Callers(0, pcs[:])
+	var wg sync.WaitGroup
+	for i := range 8 {
+		h2 := h.WithAttrs([]slog.Attr{slog.Int("i", i)})
+		wg.Go(func() {
+			for j := range 100 {
+				r := slog.NewRecord(time.Time{}, slog.LevelInfo, "msg"+strconv.Itoa(j), pcs[j%n])
+				_ = h2.Handle(context.Background(), r)
+			}
+		})
+	}
+	wg.Wait()
+	if got := h.pcCache.len(); got > 4 {
+		t.Errorf("len = %d, want at most 4", got)
+	}
+	if got := bytes.Count(buf.Bytes(), []byte("\n")); got != 800 {
+		t.Errorf("got %d lines, want 800", got)
+	}
+}
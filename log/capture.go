@@ -0,0 +1,157 @@
+package log
+
+import (
+	"errors"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+)
+
+// ErrorRecord is a record at slog.LevelError or above remembered by a handler,
+// see WithFirstErrors and WithLastErrors.
+type ErrorRecord struct {
+	Time    time.Time
+	Level   slog.Level
+	Message string
+	// Attrs holds the attributes added by With and those of the record,
+	// qualified by their groups. They and the message are redacted as they
+	// were written.
+	Attrs []slog.Attr
+}
+
+// ErrorRecorder is implemented by handlers that remember error records, as
+// CLIHandler does.
+type ErrorRecorder interface {
+	Errors() []ErrorRecord
+}
+
+// WithFirstErrors returns a CLIHandlerOption that makes the handler remember the
+// first n records at slog.LevelError and above, so that a program can report
+// its failures again at the end. The records are shared with the handlers
+// derived by With and WithGroup. A value of 0 or less disables it.
+//
+//	defer func() {
+//		for _, r := range logger.Errors() {
+//			fmt.Fprintln(os.Stderr, "error:", r.Message)
+//		}
+//	}()
+func WithFirstErrors(n int) CLIHandlerOption {
+	return func(c *CLIHandler) {
+		c.captured = newErrorCapture(n, false)
+	}
+}
+
+// WithLastErrors is like WithFirstErrors, but remembers the last n records at
+// slog.LevelError and above.
+func WithLastErrors(n int) CLIHandlerOption {
+	return func(c *CLIHandler) {
+		c.captured = newErrorCapture(n, true)
+	}
+}
+
+// ErrorsOf returns the error records of the first handler implementing
+// ErrorRecorder among handler and the handlers it wraps, found as by Flush, in
+// the order they were handled.
+func ErrorsOf(handler slog.Handler) []ErrorRecord {
+	var recs []ErrorRecord
+	found := errors.New("found")
+	_ = walk(handler, func(h slog.Handler) error {
+		if er, ok := h.(ErrorRecorder); ok {
+			recs = er.Errors()
+			return found
+		}
+		return nil
+	})
+	return recs
+}
+
+// Errors returns the error records remembered by the underlying handler. See
+// ErrorsOf.
+func (l *Logger) Errors() []ErrorRecord {
+	return ErrorsOf(l.Handler())
+}
+
+// errorCapture holds the error records of a handler, either the first n or, if
+// last is set, the last n in a ring.
+type errorCapture struct {
+	mu   sync.Mutex
+	n    int
+	last bool
+	recs []ErrorRecord
+	next int
+}
+
+// newErrorCapture returns an errorCapture holding n records, or nil if n is 0
+// or less.
+func newErrorCapture(n int, last bool) *errorCapture {
+	if n <= 0 {
+		return nil
+	}
+	return &errorCapture{n: n, last: last}
+}
+
+// full reports whether no more records are taken, so that they are not built.
+func (c *errorCapture) full() bool {
+	if c.last {
+		return false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.recs) >= c.n
+}
+
+// add remembers rec.
+func (c *errorCapture) add(rec ErrorRecord) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	switch {
+	case len(c.recs) < c.n:
+		c.recs = append(c.recs, rec)
+	case c.last:
+		c.recs[c.next] = rec
+		c.next = (c.next + 1) % c.n
+	}
+}
+
+// records returns a copy of the records in the order they were added.
+func (c *errorCapture) records() []ErrorRecord {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	recs := make([]ErrorRecord, 0, len(c.recs))
+	recs = append(recs, c.recs[c.next:]...)
+	return append(recs, c.recs[:c.next]...)
+}
+
+// Errors returns the error records remembered by the handler and the handlers
+// derived from it, if enabled by WithFirstErrors or WithLastErrors.
+func (h *CLIHandler) Errors() []ErrorRecord {
+	if h.captured == nil {
+		return nil
+	}
+	return h.captured.records()
+}
+
+// capture remembers r if it is an error record and errors are captured.
+func (h *CLIHandler) capture(r slog.Record) {
+	if h.captured == nil || r.Level < slog.LevelError || h.captured.full() {
+		return
+	}
+	attrs := make([]slog.Attr, 0, r.NumAttrs())
+	r.Attrs(func(a slog.Attr) bool {
+		if h.redactor != nil {
+			a = h.redactor.redact(a, h.anyFormat != AnyFormatDefault)
+		}
+		attrs = append(attrs, a)
+		return true
+	})
+	if len(h.groups) > 0 && len(attrs) > 0 {
+		attrs = []slog.Attr{nestGroups(h.groups, attrs)}
+	}
+	h.captured.add(ErrorRecord{
+		Time:    r.Time,
+		Level:   r.Level,
+		Message: h.redactor.RedactString(r.Message),
+		Attrs:   append(slices.Clone(h.attrs), attrs...),
+	})
+}
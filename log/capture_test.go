@@ -0,0 +1,73 @@
+package log
+
+import (
+	"io"
+	"log/slog"
+	"reflect"
+	"testing"
+)
+
+func messages(recs []ErrorRecord) []string {
+	var s []string
+	for _, r := range recs {
+		s = append(s, r.Message)
+	}
+	return s
+}
+
+func TestWithFirstErrors(t *testing.T) {
+	l := NewLogger(NewCLIHandler(io.Discard, WithFirstErrors(2)))
+	l.Warn("not an error")
+	l.Error("first")
+	l.With("k", "v").Error("second")
+	l.Error("third")
+	if got, want := messages(l.Errors()), []string{"first", "second"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestWithLastErrors(t *testing.T) {
+	l := NewLogger(NewCLIHandler(io.Discard, WithLastErrors(2)))
+	if got := l.Errors(); len(got) != 0 {
+		t.Errorf("got %v before logging", got)
+	}
+	for _, msg := range []string{"first", "second", "third", "fourth", "fifth"} {
+		l.Error(msg)
+	}
+	if got, want := messages(l.Errors()), []string{"fourth", "fifth"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestErrorRecord_Attrs(t *testing.T) {
+	r := NewRedactor(WithKeyPatterns("token"))
+	l := NewLogger(NewCLIHandler(io.Discard, WithFirstErrors(1), WithRedactor(r)))
+	l.With("app", "x").WithGroup("req").Log(t.Context(), LevelFatal, "failed", "token", "secret", "id", 1)
+	recs := l.Errors()
+	if len(recs) != 1 {
+		t.Fatalf("got %d records, want 1", len(recs))
+	}
+	rec := recs[0]
+	if rec.Level != LevelFatal || rec.Message != "failed" || rec.Time.IsZero() {
+		t.Errorf("got %+v", rec)
+	}
+	want := []slog.Attr{
+		slog.String("app", "x"),
+		slog.Group("req", slog.String("token", DefaultMask), slog.Int("id", 1)),
+	}
+	if !reflect.DeepEqual(rec.Attrs, want) {
+		t.Errorf("attrs = %v, want %v", rec.Attrs, want)
+	}
+}
+
+func TestErrorsOf(t *testing.T) {
+	if got := ErrorsOf(NewCLIHandler(io.Discard)); got != nil {
+		t.Errorf("got %v, want nil when not enabled", got)
+	}
+	h := NewCLIHandler(io.Discard, WithLastErrors(1))
+	l := NewLogger(NewSamplingHandler(h))
+	l.Error("msg")
+	if got := messages(ErrorsOf(l.Handler())); !reflect.DeepEqual(got, []string{"msg"}) {
+		t.Errorf("got %q", got)
+	}
+}
@@ -0,0 +1,50 @@
+package log
+
+import "time"
+
+// Clock is a source of the current time, e.g. a frozen or manually advanced
+// clock in tests, or the clock of a simulation or a replay tool.
+type Clock interface {
+	Now() time.Time
+}
+
+// ClockFunc adapts a function to a Clock.
+type ClockFunc func() time.Time
+
+// Now implements Clock.
+func (f ClockFunc) Now() time.Time {
+	return f()
+}
+
+// WithClock returns a CLIHandlerOption that sets the clock giving the time of
+// each record, replacing the record time, and the start of the time written by
+// WithRelativeTime. The clock is read once per record, before the hooks, so
+// that they and the records returned by Errors see the time written. A frozen
+// clock makes the output byte-stable for golden-file tests. Records without a
+// time are unaffected unless ZeroTimeNow is set. A nil clock restores the
+// record time.
+//
+// Only CLIHandler, in the CLI and logfmt formats, and SamplingHandler (see
+// WithSamplingClock) take a clock: FormatJSON and the other handlers keep the
+// record time, which Logger takes from the system clock, and the elapsed time
+// of steps is measured with the system clock.
+func WithClock(clock Clock) CLIHandlerOption {
+	return func(c *CLIHandler) {
+		if clock == nil {
+			c.clock = nil
+			return
+		}
+		c.clock = clock.Now
+	}
+}
+
+// WithSamplingClock returns a SamplingHandlerOption that sets the clock the
+// sampling intervals are measured with, and the time of the records reporting
+// the dropped records. The default is the system clock.
+func WithSamplingClock(clock Clock) SamplingHandlerOption {
+	return func(h *SamplingHandler) {
+		if clock != nil {
+			h.state.now = clock.Now
+		}
+	}
+}
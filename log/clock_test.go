@@ -0,0 +1,53 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWithClock(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)}
+	buf := &bytes.Buffer{}
+	l := NewLogger(NewCLIHandler(buf, WithStyle(Style0()), WithTime(true), WithClock(clock)))
+	l.Info("first")
+	clock.Advance(1500 * time.Millisecond)
+	l.Info("second")
+	want := "[INF] first time=2025-01-02T03:04:05Z\n[INF] second time=2025-01-02T03:04:06Z\n"
+	if got := buf.String(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	buf.Reset()
+	l = NewLogger(NewCLIHandler(buf, WithStyle(Style0()), WithTime(true), WithRelativeTime(true), WithClock(clock)))
+	clock.Advance(250 * time.Millisecond)
+	l.Info("msg")
+	if want := "[INF] msg time=+0.250s\n"; buf.String() != want {
+		t.Errorf("got %q, want %q", buf.String(), want)
+	}
+
+	buf.Reset()
+	NewLogger(NewCLIHandler(buf, WithStyle(Style0()), WithTime(true), WithClock(clock), WithClock(nil))).Info("msg")
+	if got := buf.String(); strings.Contains(got, "time=2025-01-02") {
+		t.Errorf("got %q, want the record time after resetting the clock", got)
+	}
+}
+
+func TestWithClock_hooksAndErrors(t *testing.T) {
+	frozen := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	var hooked time.Time
+	h := NewCLIHandler(io.Discard, WithFirstErrors(1), WithClock(ClockFunc(func() time.Time { return frozen })),
+		WithHook(func(_ context.Context, r slog.Record) { hooked = r.Time }))
+	l := NewLogger(h)
+	l.Error("failed")
+	if !hooked.Equal(frozen) {
+		t.Errorf("hook time = %v, want %v", hooked, frozen)
+	}
+	if recs := l.Errors(); len(recs) != 1 || !recs[0].Time.Equal(frozen) {
+		t.Errorf("errors = %+v, want the time of the clock", recs)
+	}
+}
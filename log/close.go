@@ -0,0 +1,64 @@
+package log
+
+import (
+	"errors"
+	"log/slog"
+)
+
+// ErrHandlerClosed is returned when a record is handled after the handler is closed.
+var ErrHandlerClosed = errors.New("handler closed")
+
+// Flusher is implemented by handlers that buffer output.
+type Flusher interface {
+	Flush() error
+}
+
+// Closer is implemented by handlers that hold resources released on shutdown.
+type Closer interface {
+	Close() error
+}
+
+// Flush flushes handler and every handler it wraps that implements Flusher,
+// and joins their errors. Wrapped handlers are found through their
+// Unwrap() slog.Handler or Handlers() []slog.Handler methods.
+func Flush(handler slog.Handler) error {
+	return walk(handler, func(h slog.Handler) error {
+		if f, ok := h.(Flusher); ok {
+			return f.Flush()
+		}
+		return nil
+	})
+}
+
+// Close closes handler and every handler it wraps that implements Closer, and
+// joins their errors. Wrappers are closed before the handlers they wrap, so that
+// records still queued in a wrapper are written before its inner handler is closed.
+// Handlers implementing only Flusher are flushed.
+func Close(handler slog.Handler) error {
+	return walk(handler, func(h slog.Handler) error {
+		switch c := h.(type) {
+		case Closer:
+			return c.Close()
+		case Flusher:
+			return c.Flush()
+		}
+		return nil
+	})
+}
+
+// walk calls fn for handler and then for the handlers it wraps, depth first.
+func walk(handler slog.Handler, fn func(slog.Handler) error) error {
+	if handler == nil {
+		return nil
+	}
+	err := fn(handler)
+	switch u := handler.(type) {
+	case interface{ Unwrap() slog.Handler }:
+		err = errors.Join(err, walk(u.Unwrap(), fn))
+	case interface{ Handlers() []slog.Handler }:
+		for _, h := range u.Handlers() {
+			err = errors.Join(err, walk(h, fn))
+		}
+	}
+	return err
+}
@@ -0,0 +1,136 @@
+package log
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"log/slog"
+	"slices"
+	"sync"
+	"testing"
+)
+
+// closeWriter is a writer recording its flushes and closes in a shared log.
+type closeWriter struct {
+	bytes.Buffer
+	name   string
+	events *[]string
+	mu     *sync.Mutex
+	err    error
+}
+
+func (w *closeWriter) record(event string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	*w.events = append(*w.events, w.name+":"+event)
+}
+
+func (w *closeWriter) Flush() error {
+	w.record("flush")
+	return w.err
+}
+
+func (w *closeWriter) Close() error {
+	w.record("close")
+	return w.err
+}
+
+func newCloseWriters(names ...string) ([]*closeWriter, *[]string) {
+	events := &[]string{}
+	mu := &sync.Mutex{}
+	ws := make([]*closeWriter, len(names))
+	for i, name := range names {
+		ws[i] = &closeWriter{name: name, events: events, mu: mu}
+	}
+	return ws, events
+}
+
+func TestFlush(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler func(ws []*closeWriter) slog.Handler
+		want    []string
+	}{
+		{
+			name:    "nil",
+			handler: func([]*closeWriter) slog.Handler { return nil },
+			want:    nil,
+		},
+		{
+			name:    "not flusher",
+			handler: func([]*closeWriter) slog.Handler { return slog.NewTextHandler(io.Discard, nil) },
+			want:    nil,
+		},
+		{
+			name: "cli",
+			handler: func(ws []*closeWriter) slog.Handler {
+				return NewCLIHandler(ws[0])
+			},
+			want: []string{"a:flush"},
+		},
+		{
+			name: "wrapped",
+			handler: func(ws []*closeWriter) slog.Handler {
+				return NewFilterHandler(NewSamplingHandler(NewMultiHandler(
+					NewCLIHandler(ws[0]),
+					NewLogfmtHandler(ws[1]),
+				)), nil)
+			},
+			want: []string{"a:flush", "b:flush"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ws, events := newCloseWriters("a", "b")
+			if err := Flush(tt.handler(ws)); err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(*events, tt.want) {
+				t.Errorf("got %q, want %q", *events, tt.want)
+			}
+		})
+	}
+}
+
+func TestClose(t *testing.T) {
+	t.Run("order", func(t *testing.T) {
+		ws, events := newCloseWriters("a", "b")
+		inner := NewCLIHandler(ws[0], WithStyle(Style0()))
+		async := NewAsyncHandler(inner, WithFlushInterval(0))
+		h := NewMultiHandler(NewFilterHandler(async, nil), NewLogfmtHandler(ws[1]))
+		NewLogger(h).Info("msg")
+		if err := Close(h); err != nil {
+			t.Fatal(err)
+		}
+		want := []string{"a:flush", "a:flush", "a:close", "b:flush", "b:close"}
+		if !slices.Equal(*events, want) {
+			t.Errorf("got %q, want %q", *events, want)
+		}
+		if got := ws[0].String(); got != "[INF] msg\n" {
+			t.Errorf("got %q, want the queued record written before close", got)
+		}
+		if err := NewLogger(h).Handler().Handle(t.Context(), slog.Record{}); !errors.Is(err, ErrHandlerClosed) {
+			t.Errorf("err = %v, want %v", err, ErrHandlerClosed)
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		ws, _ := newCloseWriters("a", "b")
+		err1, err2 := errors.New("a"), errors.New("b")
+		ws[0].err, ws[1].err = err1, err2
+		err := Close(NewMultiHandler(NewCLIHandler(ws[0]), NewCLIHandler(ws[1])))
+		if !errors.Is(err, err1) || !errors.Is(err, err2) {
+			t.Errorf("err = %v, want both errors", err)
+		}
+	})
+
+	t.Run("flusher only", func(t *testing.T) {
+		h := &flushHandler{Handler: slog.NewTextHandler(io.Discard, nil)}
+		if err := Close(NewFilterHandler(h, nil)); err != nil {
+			t.Fatal(err)
+		}
+		if got := h.Flushes(); got != 1 {
+			t.Errorf("flushes = %v, want 1", got)
+		}
+	})
+}
@@ -2,7 +2,11 @@ package log
 
 import (
 	"bytes"
+	"fmt"
+	"io"
+	"slices"
 	"strconv"
+	"strings"
 )
 
 // SGR attributes used by Style.
@@ -55,11 +59,26 @@ const (
 	BgHiWhite   = 107
 )
 
+// ColorMode controls whether SGR sequences are emitted.
+type ColorMode int
+
+const (
+	// ColorAuto emits colors only when the output is a terminal and NO_COLOR is not set.
+	ColorAuto ColorMode = iota
+
+	// ColorAlways emits colors regardless of the output.
+	ColorAlways
+
+	// ColorNever never emits colors.
+	ColorNever
+)
+
 // Color holds SGR sequences for text styling.
 type Color struct {
-	codes  []int
-	prefix []byte
-	reset  []byte
+	codes    []int
+	prefix   []byte
+	reset    []byte
+	adaptive *AdaptiveColor
 }
 
 // NewColor returns a new Color with the given SGR codes.
@@ -78,6 +97,210 @@ func NewColor(codes ...int) *Color {
 	}
 }
 
+// NewColorRGB returns a new Color with a 24-bit foreground color followed by the given SGR codes.
+func NewColorRGB(r, g, b uint8, codes ...int) *Color {
+	return NewColor(append([]int{38, 2, int(r), int(g), int(b)}, codes...)...)
+}
+
+// NewBgColorRGB returns a new Color with a 24-bit background color followed by the given SGR codes.
+func NewBgColorRGB(r, g, b uint8, codes ...int) *Color {
+	return NewColor(append([]int{48, 2, int(r), int(g), int(b)}, codes...)...)
+}
+
+// NewColor256 returns a new Color with a 256-color foreground followed by the given SGR codes.
+func NewColor256(n uint8, codes ...int) *Color {
+	return NewColor(append([]int{38, 5, int(n)}, codes...)...)
+}
+
+// NewBgColor256 returns a new Color with a 256-color background followed by the given SGR codes.
+func NewBgColor256(n uint8, codes ...int) *Color {
+	return NewColor(append([]int{48, 5, int(n)}, codes...)...)
+}
+
+// NewColorHex returns a new Color with a 24-bit foreground color parsed from a
+// "#rrggbb" or "#rgb" string, followed by the given SGR codes.
+func NewColorHex(hex string, codes ...int) (*Color, error) {
+	r, g, b, err := parseHex(hex)
+	if err != nil {
+		return nil, err
+	}
+	return NewColorRGB(r, g, b, codes...), nil
+}
+
+// parseHex parses a "#rrggbb" or "#rgb" color string.
+func parseHex(hex string) (r, g, b uint8, err error) {
+	s := strings.TrimPrefix(hex, "#")
+	if len(s) == 3 {
+		s = string([]byte{s[0], s[0], s[1], s[1], s[2], s[2]})
+	}
+	if len(s) != 6 {
+		return 0, 0, 0, fmt.Errorf("invalid hex color: %q", hex)
+	}
+	v, err := strconv.ParseUint(s, 16, 32)
+	if err != nil {
+		return 0, 0, 0, fmt.Errorf("invalid hex color: %q", hex)
+	}
+	return uint8(v >> 16), uint8(v >> 8), uint8(v), nil
+}
+
+// colorNames maps the names accepted by ParseColor to SGR codes.
+var colorNames = func() map[string]int {
+	m := map[string]int{
+		"bold":        Bold,
+		"faint":       Faint,
+		"italic":      Italic,
+		"underline":   Underline,
+		"blink":       BlinkSlow,
+		"rapid-blink": BlinkRapid,
+		"reverse":     ReverseVideo,
+		"concealed":   Concealed,
+		"crossed-out": CrossedOut,
+	}
+	for i, name := range []string{"black", "red", "green", "yellow", "blue", "magenta", "cyan", "white"} {
+		m[name] = FgBlack + i
+		m["hi-"+name] = FgHiBlack + i
+		m["bg-"+name] = BgBlack + i
+		m["bg-hi-"+name] = BgHiBlack + i
+	}
+	return m
+}()
+
+// codeNames maps SGR codes to their names in colorNames.
+var codeNames = func() map[int]string {
+	m := make(map[int]string, len(colorNames))
+	for name, code := range colorNames {
+		m[code] = name
+	}
+	return m
+}()
+
+// ParseColor parses a Color from a list of words separated by spaces or commas.
+// A word is one of:
+//
+//   - an attribute: bold, faint, italic, underline, blink, rapid-blink, reverse,
+//     concealed or crossed-out
+//   - a basic color: black, red, green, yellow, blue, magenta, cyan or white,
+//     optionally prefixed with "hi-" for the bright variant
+//   - a 256-color index from 0 to 255
+//   - a 24-bit color in the form "#rrggbb" or "#rgb"
+//   - "sgr-" followed by a raw SGR code
+//
+// Colors prefixed with "bg-" set the background, e.g. "bg-hi-red" or "bg-#202020".
+// An empty string or "none" returns a nil Color. Two lists separated by "|" give
+// an AdaptiveColor, the first for light backgrounds and the second for dark ones,
+// e.g. "blue | hi-cyan".
+func ParseColor(s string) (*Color, error) {
+	if light, dark, ok := strings.Cut(s, "|"); ok {
+		if strings.Contains(dark, "|") {
+			return nil, fmt.Errorf("invalid color: %q: more than two variants", s)
+		}
+		l, err := ParseColor(light)
+		if err != nil {
+			return nil, err
+		}
+		d, err := ParseColor(dark)
+		if err != nil {
+			return nil, err
+		}
+		return AdaptiveColor{Light: l, Dark: d}.Color(), nil
+	}
+	words := strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return r == ' ' || r == ',' || r == '\t'
+	})
+	if len(words) == 0 || len(words) == 1 && words[0] == "none" {
+		return nil, nil
+	}
+	var codes []int
+	for _, w := range words {
+		if code, ok := colorNames[w]; ok {
+			codes = append(codes, code)
+			continue
+		}
+		ext, v := 38, w
+		if rest, ok := strings.CutPrefix(w, "bg-"); ok {
+			ext, v = 48, rest
+		}
+		switch {
+		case strings.HasPrefix(v, "#"):
+			r, g, b, err := parseHex(v)
+			if err != nil {
+				return nil, err
+			}
+			codes = append(codes, ext, 2, int(r), int(g), int(b))
+		case strings.HasPrefix(w, "sgr-"):
+			n, err := strconv.Atoi(w[len("sgr-"):])
+			if err != nil || n < 0 {
+				return nil, fmt.Errorf("invalid sgr code: %q", w)
+			}
+			codes = append(codes, n)
+		default:
+			n, err := strconv.ParseUint(v, 10, 8)
+			if err != nil {
+				return nil, fmt.Errorf("invalid color: %q", w)
+			}
+			codes = append(codes, ext, 5, int(n))
+		}
+	}
+	return NewColor(codes...), nil
+}
+
+// spec returns the words of the Color in the form parsed by ParseColor, or
+// "none" for a nil Color or a Color without codes.
+func (c *Color) spec() string {
+	if c != nil && c.adaptive != nil {
+		return c.adaptive.Light.spec() + " | " + c.adaptive.Dark.spec()
+	}
+	if c == nil || len(c.codes) == 0 {
+		return "none"
+	}
+	var words []string
+	codes := c.codes
+	for len(codes) > 0 {
+		code := codes[0]
+		bg := ""
+		if code == 48 {
+			bg = "bg-"
+		}
+		switch {
+		case (code == 38 || code == 48) && len(codes) >= 5 && codes[1] == 2:
+			words = append(words, fmt.Sprintf("%s#%02x%02x%02x", bg, codes[2], codes[3], codes[4]))
+			codes = codes[5:]
+			continue
+		case (code == 38 || code == 48) && len(codes) >= 3 && codes[1] == 5:
+			words = append(words, bg+strconv.Itoa(codes[2]))
+			codes = codes[3:]
+			continue
+		}
+		if name, ok := codeNames[code]; ok {
+			words = append(words, name)
+		} else {
+			words = append(words, "sgr-"+strconv.Itoa(code))
+		}
+		codes = codes[1:]
+	}
+	return strings.Join(words, " ")
+}
+
+// MarshalText implements encoding.TextMarshaler, returning the codes of the
+// Color in the form parsed by ParseColor.
+func (c *Color) MarshalText() ([]byte, error) {
+	return []byte(c.spec()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, parsing the Color with
+// ParseColor. An empty text or "none" results in a Color without codes.
+func (c *Color) UnmarshalText(text []byte) error {
+	p, err := ParseColor(string(text))
+	if err != nil {
+		return err
+	}
+	if p == nil {
+		p = NewColor()
+	}
+	*c = *p
+	return nil
+}
+
 // WriteString writes the string to the buffer with SGR sequences applied.
 func (c *Color) WriteString(buf *bytes.Buffer, s string) {
 	if c != nil && len(c.prefix) > 0 {
@@ -104,6 +327,94 @@ func (c *Color) WriteBytes(buf *bytes.Buffer, b []byte) {
 	}
 }
 
+// With returns a new Color with the codes of c followed by the given SGR
+// codes, leaving c unchanged. Both variants of an adaptive Color get the codes.
+func (c *Color) With(codes ...int) *Color {
+	if c != nil && c.adaptive != nil {
+		return AdaptiveColor{Light: c.adaptive.Light.With(codes...), Dark: c.adaptive.Dark.With(codes...)}.Color()
+	}
+	if c == nil {
+		return NewColor(codes...)
+	}
+	return NewColor(slices.Concat(c.codes, codes)...)
+}
+
+// Bold returns a new Color with the codes of c followed by Bold.
+func (c *Color) Bold() *Color {
+	return c.With(Bold)
+}
+
+// Faint returns a new Color with the codes of c followed by Faint.
+func (c *Color) Faint() *Color {
+	return c.With(Faint)
+}
+
+// Underline returns a new Color with the codes of c followed by Underline.
+func (c *Color) Underline() *Color {
+	return c.With(Underline)
+}
+
+// Merge returns a new Color with the codes of c followed by those of other,
+// so that other takes precedence where both set the same attribute, such as
+// the foreground color. If either is adaptive, the variants are merged for
+// each background.
+func (c *Color) Merge(other *Color) *Color {
+	if (c != nil && c.adaptive != nil) || (other != nil && other.adaptive != nil) {
+		return AdaptiveColor{
+			Light: c.variant(BackgroundLight).Merge(other.variant(BackgroundLight)),
+			Dark:  c.variant(BackgroundDark).Merge(other.variant(BackgroundDark)),
+		}.Color()
+	}
+	if other == nil {
+		return c.With()
+	}
+	return c.With(other.codes...)
+}
+
+// variant returns the variant of c for the given background, or c itself if
+// it is not adaptive.
+func (c *Color) variant(bg Background) *Color {
+	return c.adapt(func() Background { return bg })
+}
+
+// Append appends the string with SGR sequences applied to dst and returns the
+// extended slice.
+func (c *Color) Append(dst []byte, s string) []byte {
+	if c != nil {
+		dst = append(dst, c.prefix...)
+	}
+	dst = append(dst, s...)
+	if c != nil {
+		dst = append(dst, c.reset...)
+	}
+	return dst
+}
+
+// Write writes the string with SGR sequences applied to w in a single write
+// and returns the number of bytes written, sequences included. Unlike
+// handlers, it applies the color whether or not w is a terminal.
+func (c *Color) Write(w io.Writer, s string) (int, error) {
+	buf := bufPool.Get().(*bytes.Buffer)
+	defer func() {
+		buf.Reset()
+		bufPool.Put(buf)
+	}()
+	c.WriteString(buf, s)
+	return w.Write(buf.Bytes())
+}
+
+// Sprint formats the operands as fmt.Sprint does and returns the result with
+// SGR sequences applied, e.g. for usage messages and table headers.
+func (c *Color) Sprint(a ...any) string {
+	return string(c.Append(nil, fmt.Sprint(a...)))
+}
+
+// Sprintf formats according to a format specifier as fmt.Sprintf does and
+// returns the result with SGR sequences applied.
+func (c *Color) Sprintf(format string, a ...any) string {
+	return string(c.Append(nil, fmt.Sprintf(format, a...)))
+}
+
 // makeSGR builds the SGR escape sequence for the given codes.
 func makeSGR(codes []int) []byte {
 	if len(codes) == 0 {
@@ -2,6 +2,7 @@ package log
 
 import (
 	"bytes"
+	"encoding/json"
 	"reflect"
 	"testing"
 )
@@ -211,6 +212,131 @@ func TestColor_WriteBytes(t *testing.T) {
 	}
 }
 
+func TestColor_With(t *testing.T) {
+	tests := []struct {
+		name  string
+		color func() *Color
+		want  string
+	}{
+		{name: "with", color: func() *Color { return NewColor(FgRed).With(Italic, BgBlack) }, want: "red italic bg-black"},
+		{name: "bold", color: func() *Color { return NewColor(FgRed).Bold() }, want: "red bold"},
+		{name: "faint", color: func() *Color { return NewColorRGB(1, 2, 3).Faint() }, want: "#010203 faint"},
+		{name: "underline", color: func() *Color { return NewColor256(208).Underline() }, want: "208 underline"},
+		{name: "chained", color: func() *Color { return NewColor(FgCyan).Bold().Underline() }, want: "cyan bold underline"},
+		{name: "nil receiver", color: func() *Color { return (*Color)(nil).Bold() }, want: "bold"},
+		{name: "nothing", color: func() *Color { return NewColor().With() }, want: "none"},
+		{
+			name: "adaptive",
+			color: func() *Color {
+				return AdaptiveColor{Light: NewColor(FgBlue), Dark: NewColor(FgCyan)}.Color().Bold()
+			},
+			want: "blue bold | cyan bold",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.color().spec(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestColor_With_unchanged(t *testing.T) {
+	base := NewColor(FgRed)
+	_ = base.Bold()
+	_ = base.Merge(NewColor(Underline))
+	if got := base.Sprint("x"); got != "\x1b[31mx\x1b[0m" {
+		t.Errorf("base changed to %q", got)
+	}
+}
+
+func TestColor_Merge(t *testing.T) {
+	adaptive := AdaptiveColor{Light: NewColor(FgBlue), Dark: NewColor(FgCyan)}.Color()
+	tests := []struct {
+		name  string
+		color *Color
+		other *Color
+		want  string
+	}{
+		{name: "basic", color: NewColor(Bold), other: NewColor(FgRed), want: "bold red"},
+		{name: "other wins", color: NewColor(FgRed, Bold), other: NewColor(FgGreen), want: "red bold green"},
+		{name: "nil other", color: NewColor(FgRed), want: "red"},
+		{name: "nil receiver", other: NewColor(FgRed), want: "red"},
+		{name: "both nil", want: "none"},
+		{name: "adaptive receiver", color: adaptive, other: NewColor(Bold), want: "blue bold | cyan bold"},
+		{name: "adaptive other", color: NewColor(Underline), other: adaptive, want: "underline blue | underline cyan"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.color.Merge(tt.other).spec(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestColor_Append(t *testing.T) {
+	tests := []struct {
+		name  string
+		color *Color
+		dst   []byte
+		s     string
+		want  string
+	}{
+		{name: "basic", color: NewColor(FgRed), s: "hello", want: "\x1b[31mhello\x1b[0m"},
+		{name: "existing", color: NewColor(FgRed), dst: []byte("> "), s: "hello", want: "> \x1b[31mhello\x1b[0m"},
+		{name: "no color", color: NewColor(), s: "hello", want: "hello"},
+		{name: "nil receiver", s: "hello", want: "hello"},
+		{name: "adaptive", color: AdaptiveColor{Light: NewColor(FgBlue), Dark: NewColor(FgCyan)}.Color(), s: "hello", want: "\x1b[36mhello\x1b[0m"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(tt.color.Append(tt.dst, tt.s)); got != tt.want {
+				t.Errorf("Color.Append() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestColor_Write(t *testing.T) {
+	tests := []struct {
+		name  string
+		color *Color
+		s     string
+		want  string
+	}{
+		{name: "basic", color: NewColor(Bold, FgGreen), s: "hello", want: "\x1b[1;32mhello\x1b[0m"},
+		{name: "nil receiver", s: "hello", want: "hello"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			n, err := tt.color.Write(buf, tt.s)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := buf.String(); got != tt.want || n != len(tt.want) {
+				t.Errorf("Color.Write() = %q, %d, want %q, %d", got, n, tt.want, len(tt.want))
+			}
+		})
+	}
+}
+
+func TestColor_Sprint(t *testing.T) {
+	c := NewColor(FgYellow)
+	if got, want := c.Sprint("usage: ", 2, " args"), "\x1b[33musage: 2 args\x1b[0m"; got != want {
+		t.Errorf("Color.Sprint() = %q, want %q", got, want)
+	}
+	if got, want := c.Sprintf("%-6s|", "NAME"), "\x1b[33mNAME  |\x1b[0m"; got != want {
+		t.Errorf("Color.Sprintf() = %q, want %q", got, want)
+	}
+	var nc *Color
+	if got, want := nc.Sprintf("%d", 1), "1"; got != want {
+		t.Errorf("Color.Sprintf() = %q, want %q", got, want)
+	}
+}
+
 func Test_makeSGR(t *testing.T) {
 	type args struct {
 		codes []int
@@ -244,3 +370,206 @@ func Test_makeSGR(t *testing.T) {
 		})
 	}
 }
+
+func TestNewColorRGB(t *testing.T) {
+	tests := []struct {
+		name string
+		got  *Color
+		want *Color
+	}{
+		{
+			name: "foreground",
+			got:  NewColorRGB(95, 95, 255),
+			want: NewColor(38, 2, 95, 95, 255),
+		},
+		{
+			name: "foreground with attributes",
+			got:  NewColorRGB(95, 95, 255, Bold, Underline),
+			want: NewColor(38, 2, 95, 95, 255, Bold, Underline),
+		},
+		{
+			name: "background",
+			got:  NewBgColorRGB(0, 128, 255, Bold),
+			want: NewColor(48, 2, 0, 128, 255, Bold),
+		},
+		{
+			name: "256 foreground",
+			got:  NewColor256(63, Bold),
+			want: NewColor(38, 5, 63, Bold),
+		},
+		{
+			name: "256 background",
+			got:  NewBgColor256(204),
+			want: NewColor(48, 5, 204),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !reflect.DeepEqual(tt.got, tt.want) {
+				t.Errorf("got %v, want %v", tt.got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewColorHex(t *testing.T) {
+	tests := []struct {
+		name    string
+		hex     string
+		codes   []int
+		want    *Color
+		wantErr bool
+	}{
+		{
+			name: "long form",
+			hex:  "#5f5fff",
+			want: NewColor(38, 2, 95, 95, 255),
+		},
+		{
+			name:  "long form with attributes",
+			hex:   "#5F5FFF",
+			codes: []int{Bold},
+			want:  NewColor(38, 2, 95, 95, 255, Bold),
+		},
+		{
+			name: "short form",
+			hex:  "#f80",
+			want: NewColor(38, 2, 255, 136, 0),
+		},
+		{
+			name: "without hash",
+			hex:  "000000",
+			want: NewColor(38, 2, 0, 0, 0),
+		},
+		{
+			name:    "invalid length",
+			hex:     "#12345",
+			wantErr: true,
+		},
+		{
+			name:    "invalid digit",
+			hex:     "#zzzzzz",
+			wantErr: true,
+		},
+		{
+			name:    "empty",
+			hex:     "",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewColorHex(tt.hex, tt.codes...)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewColorHex() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("NewColorHex() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseColor(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		want    *Color
+		wantErr bool
+	}{
+		{name: "empty", in: "", want: nil},
+		{name: "none", in: "None", want: nil},
+		{name: "named", in: "hi-red", want: NewColor(FgHiRed)},
+		{name: "attributes", in: "bold, underline red", want: NewColor(Bold, Underline, FgRed)},
+		{name: "background", in: "bg-hi-blue white", want: NewColor(BgHiBlue, FgWhite)},
+		{name: "256 colors", in: "208 bg-17", want: NewColor(38, 5, 208, 48, 5, 17)},
+		{name: "hex", in: "#ff8000 bg-#000", want: NewColor(38, 2, 255, 128, 0, 48, 2, 0, 0, 0)},
+		{name: "sgr", in: "sgr-53", want: NewColor(53)},
+		{name: "adaptive", in: "blue | bold hi-cyan", want: AdaptiveColor{Light: NewColor(FgBlue), Dark: NewColor(Bold, FgHiCyan)}.Color()},
+		{name: "adaptive without light", in: "|red", want: AdaptiveColor{Dark: NewColor(FgRed)}.Color()},
+		{name: "three variants", in: "red|green|blue", wantErr: true},
+		{name: "invalid variant", in: "red|orange", wantErr: true},
+		{name: "unknown name", in: "orange", wantErr: true},
+		{name: "out of range", in: "256", wantErr: true},
+		{name: "invalid hex", in: "#12", wantErr: true},
+		{name: "invalid sgr", in: "sgr-x", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseColor(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseColor() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseColor() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestColor_spec(t *testing.T) {
+	tests := []struct {
+		name  string
+		color *Color
+		want  string
+	}{
+		{name: "nil", color: nil, want: "none"},
+		{name: "named", color: NewColor(Bold, FgHiGreen, BgBlack), want: "bold hi-green bg-black"},
+		{name: "256 colors", color: NewColor(38, 5, 245, 48, 5, 0), want: "245 bg-0"},
+		{name: "rgb", color: NewColor(38, 2, 1, 2, 255), want: "#0102ff"},
+		{name: "raw", color: NewColor(0, 38), want: "sgr-0 sgr-38"},
+		{name: "adaptive", color: AdaptiveColor{Light: NewColor(FgBlack), Dark: nil}.Color(), want: "black | none"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.color.spec()
+			if got != tt.want {
+				t.Errorf("spec() = %q, want %q", got, tt.want)
+			}
+			back, err := ParseColor(got)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.color != nil && !reflect.DeepEqual(back, tt.color) {
+				t.Errorf("ParseColor(%q) = %v, want %v", got, back, tt.color)
+			}
+		})
+	}
+}
+
+func TestColor_MarshalText(t *testing.T) {
+	type doc struct {
+		Color *Color `json:"color"`
+	}
+	tests := []struct {
+		name  string
+		color *Color
+		want  string
+	}{
+		{name: "nil", color: nil, want: `{"color":null}`},
+		{name: "empty", color: NewColor(), want: `{"color":"none"}`},
+		{name: "codes", color: NewColor(Bold, 38, 5, 202, BgHiBlue), want: `{"color":"bold 202 bg-hi-blue"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := json.Marshal(doc{tt.color})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(b) != tt.want {
+				t.Errorf("got %s, want %s", b, tt.want)
+			}
+			var got doc
+			if err := json.Unmarshal(b, &got); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got.Color, tt.color) {
+				t.Errorf("got %v, want %v", got.Color, tt.color)
+			}
+		})
+	}
+	var c Color
+	if err := c.UnmarshalText([]byte("orange")); err == nil {
+		t.Error("want an error for an invalid color")
+	}
+}
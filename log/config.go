@@ -0,0 +1,907 @@
+package log
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+)
+
+var (
+	_ json.Marshaler   = (*Style)(nil)
+	_ json.Unmarshaler = (*Style)(nil)
+	_ yaml.Marshaler   = (*Style)(nil)
+	_ yaml.Unmarshaler = (*Style)(nil)
+)
+
+// Config is a declarative handler configuration, as loaded by LoadConfig or
+// read from the environment by NewFromEnv. Nil and empty fields keep the
+// values of the handler options.
+type Config struct {
+	Level      *slog.Level
+	Format     string
+	Style      *Style
+	Color      *ColorMode
+	Time       *bool
+	TimeFormat string
+	Caller     *bool
+	Label      string
+	Stack      *slog.Level
+	Multiline  *bool
+	Width      int
+}
+
+// configFile is the file format of a Config.
+type configFile struct {
+	Level      string    `yaml:"level"`
+	Format     string    `yaml:"format"`
+	Style      yaml.Node `yaml:"style"`
+	Color      string    `yaml:"color"`
+	Time       *bool     `yaml:"time"`
+	TimeFormat string    `yaml:"time_format"`
+	Caller     *bool     `yaml:"caller"`
+	Label      string    `yaml:"label"`
+	Stack      string    `yaml:"stack"`
+	Multiline  *bool     `yaml:"multiline"`
+	Width      int       `yaml:"width"`
+}
+
+// LoadConfig reads a Config in YAML or JSON from r. For example:
+//
+//	level: debug
+//	format: cli          # cli, json or logfmt
+//	color: auto          # auto, always, never or a boolean
+//	time: true
+//	time_format: kitchen # a time layout or the name of a time package layout
+//	caller: true
+//	label: app
+//	stack: error         # the level from which stack traces are written
+//	multiline: false
+//	width: 100
+//	style: vivid         # a registered style name, or a style as read by LoadStyle
+//
+// Setting time_format enables the time unless time is false. LoadConfig
+// returns an error describing every invalid field.
+func LoadConfig(r io.Reader) (*Config, error) {
+	var f configFile
+	if err := yaml.NewDecoder(r).Decode(&f); err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	c := &Config{
+		Time:       f.Time,
+		TimeFormat: f.TimeFormat,
+		Caller:     f.Caller,
+		Label:      f.Label,
+		Multiline:  f.Multiline,
+		Width:      f.Width,
+	}
+	var errs []error
+	invalid := func(key, value string, err error) {
+		errs = append(errs, fmt.Errorf("invalid %s %q: %w", key, value, err))
+	}
+	if f.Level != "" {
+		level, err := parseLevel(f.Level)
+		if err != nil {
+			invalid("level", f.Level, err)
+		}
+		c.Level = &level
+	}
+	if f.Format != "" {
+		format, err := parseFormat(f.Format)
+		if err != nil {
+			invalid("format", f.Format, err)
+		}
+		c.Format = format
+	}
+	if f.Color != "" {
+		mode, err := parseColorMode(f.Color)
+		if err != nil {
+			invalid("color", f.Color, err)
+		}
+		c.Color = &mode
+	}
+	if f.Stack != "" {
+		level, err := parseLevel(f.Stack)
+		if err != nil {
+			invalid("stack", f.Stack, err)
+		}
+		c.Stack = &level
+	}
+	if c.TimeFormat != "" {
+		c.TimeFormat = timeLayout(c.TimeFormat)
+	}
+	switch f.Style.Kind {
+	case 0:
+	case yaml.ScalarNode:
+		s, ok := GetStyle(f.Style.Value)
+		if !ok {
+			invalid("style", f.Style.Value, fmt.Errorf("want one of %s", strings.Join(StyleNames(), ", ")))
+		}
+		c.Style = s
+	default:
+		s, err := decodeStyle(&f.Style)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("invalid style: %w", err))
+		}
+		c.Style = s
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return c, nil
+}
+
+// Options returns the CLIHandlerOptions applying the configuration.
+func (c *Config) Options() []CLIHandlerOption {
+	var opts []CLIHandlerOption
+	if c.Level != nil {
+		opts = append(opts, WithLevel(*c.Level))
+	}
+	if c.Style != nil {
+		opts = append(opts, WithStyle(c.Style))
+	}
+	if c.Color != nil {
+		opts = append(opts, WithColorMode(*c.Color))
+	}
+	if c.TimeFormat != "" {
+		opts = append(opts, WithTime(true), WithTimeFormat(c.TimeFormat))
+	}
+	if c.Time != nil {
+		opts = append(opts, WithTime(*c.Time))
+	}
+	if c.Caller != nil {
+		opts = append(opts, WithCaller(*c.Caller))
+	}
+	if c.Label != "" {
+		opts = append(opts, WithLabel(c.Label))
+	}
+	if c.Stack != nil {
+		opts = append(opts, WithStackTrace(*c.Stack))
+	}
+	if c.Multiline != nil {
+		opts = append(opts, WithMultiline(*c.Multiline))
+	}
+	if c.Width != 0 {
+		opts = append(opts, WithWidth(c.Width))
+	}
+	return opts
+}
+
+// NewHandler creates a new handler writing to w in the configured format. The
+// options are applied before the configuration, which takes precedence. For the
+// json format, only the level, time format and caller settings are used.
+func (c *Config) NewHandler(w io.Writer, opts ...CLIHandlerOption) slog.Handler {
+	opts = append(opts, c.Options()...)
+	if c.Format != "" {
+		if format, err := ParseFormat(c.Format); err == nil {
+			opts = append(opts, WithFormat(format))
+		}
+	}
+	return New(w, opts...)
+}
+
+// parseFormat parses an output format name as ParseFormat does, returning its
+// canonical name.
+func parseFormat(s string) (string, error) {
+	f, err := ParseFormat(s)
+	if err != nil {
+		return "", err
+	}
+	return f.String(), nil
+}
+
+// timeLayout returns the layout of the time package with the given name, or s
+// itself if there is none.
+func timeLayout(s string) string {
+	if layout, ok := timeLayouts[strings.ToLower(s)]; ok {
+		return layout
+	}
+	return s
+}
+
+// styleFile is the file format of a Style. Colors are written in the form
+// parsed by ParseColor.
+type styleFile struct {
+	Base      string               `yaml:"base,omitempty" json:"base,omitempty"`
+	Level     map[string]levelFile `yaml:"level,omitempty" json:"level,omitempty"`
+	Message   messageFile          `yaml:"message,omitempty" json:"message,omitzero"`
+	Label     labelFile            `yaml:"label,omitempty" json:"label,omitzero"`
+	Attr      attrFile             `yaml:"attr,omitempty" json:"attr,omitzero"`
+	Caller    callerFile           `yaml:"caller,omitempty" json:"caller,omitzero"`
+	Time      timeFile             `yaml:"time,omitempty" json:"time,omitzero"`
+	Stack     stackFile            `yaml:"stack,omitempty" json:"stack,omitzero"`
+	Multiline multilineFile        `yaml:"multiline,omitempty" json:"multiline,omitzero"`
+	Step      stepFile             `yaml:"step,omitempty" json:"step,omitzero"`
+	Section   sectionFile          `yaml:"section,omitempty" json:"section,omitzero"`
+}
+
+// affixFile is the file format of an AffixStyle.
+type affixFile struct {
+	Text  string `yaml:"text,omitempty" json:"text,omitempty"`
+	Color string `yaml:"color,omitempty" json:"color,omitempty"`
+}
+
+// levelFile is the file format of a LevelStyle. The alignment is one of
+// "center", "left" and "right", and the truncation one of "none", "right" and
+// "middle".
+type levelFile struct {
+	Prefix   affixFile `yaml:"prefix,omitempty" json:"prefix,omitzero"`
+	Suffix   affixFile `yaml:"suffix,omitempty" json:"suffix,omitzero"`
+	Icon     string    `yaml:"icon,omitempty" json:"icon,omitempty"`
+	Text     string    `yaml:"text" json:"text"`
+	Color    string    `yaml:"color,omitempty" json:"color,omitempty"`
+	Width    int       `yaml:"width,omitempty" json:"width,omitempty"`
+	Align    string    `yaml:"align,omitempty" json:"align,omitempty"`
+	Truncate string    `yaml:"truncate,omitempty" json:"truncate,omitempty"`
+}
+
+// messageFile is the file format of a MessageStyle.
+type messageFile struct {
+	Color  string            `yaml:"color,omitempty" json:"color,omitempty"`
+	Levels map[string]string `yaml:"levels,omitempty" json:"levels,omitempty"`
+}
+
+// labelFile is the file format of a LabelStyle. The alignment and the
+// truncation are those of a levelFile.
+type labelFile struct {
+	Prefix   affixFile `yaml:"prefix,omitempty" json:"prefix,omitzero"`
+	Suffix   affixFile `yaml:"suffix,omitempty" json:"suffix,omitzero"`
+	Color    string    `yaml:"color,omitempty" json:"color,omitempty"`
+	Width    int       `yaml:"width,omitempty" json:"width,omitempty"`
+	Align    string    `yaml:"align,omitempty" json:"align,omitempty"`
+	Truncate string    `yaml:"truncate,omitempty" json:"truncate,omitempty"`
+}
+
+// attrFile is the file format of an AttrStyle. The width is a number of
+// columns or "auto" for AttrWidthAuto.
+type attrFile struct {
+	KeyColor       string                 `yaml:"key_color,omitempty" json:"key_color,omitempty"`
+	ValueColor     string                 `yaml:"value_color,omitempty" json:"value_color,omitempty"`
+	ErrorColor     string                 `yaml:"error_color,omitempty" json:"error_color,omitempty"`
+	TraceColor     string                 `yaml:"trace_color,omitempty" json:"trace_color,omitempty"`
+	NilColor       string                 `yaml:"nil_color,omitempty" json:"nil_color,omitempty"`
+	Bytes          string                 `yaml:"bytes,omitempty" json:"bytes,omitempty"`
+	Duration       string                 `yaml:"duration,omitempty" json:"duration,omitempty"`
+	Keys           map[string]attrKeyFile `yaml:"keys,omitempty" json:"keys,omitempty"`
+	Separator      string                 `yaml:"separator" json:"separator"`
+	GroupPrefix    string                 `yaml:"group_prefix,omitempty" json:"group_prefix,omitempty"`
+	GroupSuffix    string                 `yaml:"group_suffix,omitempty" json:"group_suffix,omitempty"`
+	GroupSeparator string                 `yaml:"group_separator,omitempty" json:"group_separator,omitempty"`
+	Width          string                 `yaml:"width,omitempty" json:"width,omitempty"`
+}
+
+// attrKeyFile is the file format of an AttrKeyStyle.
+type attrKeyFile struct {
+	KeyColor   string `yaml:"key_color,omitempty" json:"key_color,omitempty"`
+	ValueColor string `yaml:"value_color,omitempty" json:"value_color,omitempty"`
+}
+
+// callerFile is the file format of a CallerStyle. The format is one of
+// "file-line", "file", "func" and "package-func".
+type callerFile struct {
+	Prefix   affixFile `yaml:"prefix,omitempty" json:"prefix,omitzero"`
+	Suffix   affixFile `yaml:"suffix,omitempty" json:"suffix,omitzero"`
+	Color    string    `yaml:"color,omitempty" json:"color,omitempty"`
+	Fullpath bool      `yaml:"fullpath,omitempty" json:"fullpath,omitempty"`
+	Format   string    `yaml:"format,omitempty" json:"format,omitempty"`
+}
+
+// timeFile is the file format of a TimeStyle.
+type timeFile struct {
+	Prefix affixFile `yaml:"prefix,omitempty" json:"prefix,omitzero"`
+	Suffix affixFile `yaml:"suffix,omitempty" json:"suffix,omitzero"`
+	Color  string    `yaml:"color,omitempty" json:"color,omitempty"`
+}
+
+// stackFile is the file format of a StackStyle.
+type stackFile struct {
+	Indent string `yaml:"indent,omitempty" json:"indent,omitempty"`
+	Color  string `yaml:"color,omitempty" json:"color,omitempty"`
+}
+
+// multilineFile is the file format of a MultilineStyle.
+type multilineFile struct {
+	Indent string    `yaml:"indent,omitempty" json:"indent,omitempty"`
+	Marker affixFile `yaml:"marker,omitempty" json:"marker,omitzero"`
+}
+
+// stepFile is the file format of a StepStyle.
+type stepFile struct {
+	Running affixFile `yaml:"running,omitempty" json:"running,omitzero"`
+	Success affixFile `yaml:"success,omitempty" json:"success,omitzero"`
+	Failed  affixFile `yaml:"failed,omitempty" json:"failed,omitzero"`
+	Skipped affixFile `yaml:"skipped,omitempty" json:"skipped,omitzero"`
+}
+
+// sectionFile is the file format of a SectionStyle. The corners are a list of
+// four strings, clockwise from the top left.
+type sectionFile struct {
+	Line       string   `yaml:"line,omitempty" json:"line,omitempty"`
+	Side       string   `yaml:"side,omitempty" json:"side,omitempty"`
+	Corners    []string `yaml:"corners,omitempty" json:"corners,omitempty"`
+	Color      string   `yaml:"color,omitempty" json:"color,omitempty"`
+	TitleColor string   `yaml:"title_color,omitempty" json:"title_color,omitempty"`
+	Width      int      `yaml:"width,omitempty" json:"width,omitempty"`
+}
+
+// callerFormats maps the file names of the caller formats to the formats.
+var callerFormats = map[string]CallerFormat{
+	"file-line":    CallerFileLine,
+	"file":         CallerFile,
+	"func":         CallerFuncName,
+	"package-func": CallerPackageFunc,
+}
+
+// alignments maps the file names of the alignments to the alignments.
+var alignments = map[string]Alignment{
+	"center": AlignCenter,
+	"left":   AlignLeft,
+	"right":  AlignRight,
+}
+
+// encodeAlignment returns the file name of a, or "" for AlignCenter.
+func encodeAlignment(a Alignment) string {
+	for name, v := range alignments {
+		if v == a && a != AlignCenter {
+			return name
+		}
+	}
+	return ""
+}
+
+// truncations maps the file names of the truncations to the truncations.
+var truncations = map[string]Truncation{
+	"none":   TruncateNone,
+	"right":  TruncateRight,
+	"middle": TruncateMiddle,
+}
+
+// encodeTruncation returns the file name of t, or "" for TruncateNone.
+func encodeTruncation(t Truncation) string {
+	for name, v := range truncations {
+		if v == t && t != TruncateNone {
+			return name
+		}
+	}
+	return ""
+}
+
+// bytesFormats maps the file names of the byte slice formats to the formats.
+var bytesFormats = map[string]BytesFormat{
+	"text":   BytesText,
+	"hex":    BytesHex,
+	"base64": BytesBase64,
+	"length": BytesLength,
+}
+
+// encodeBytesFormat returns the file name of f, or "" for BytesText.
+func encodeBytesFormat(f BytesFormat) string {
+	for name, v := range bytesFormats {
+		if v == f && f != BytesText {
+			return name
+		}
+	}
+	return ""
+}
+
+// durationFormats maps the file names of the duration formats to the formats.
+var durationFormats = map[string]DurationFormat{
+	"default": DurationDefault,
+	"millis":  DurationMillis,
+	"human":   DurationHuman,
+}
+
+// encodeDurationFormat returns the file name of f, or "" for DurationDefault.
+func encodeDurationFormat(f DurationFormat) string {
+	for name, v := range durationFormats {
+		if v == f && f != DurationDefault {
+			return name
+		}
+	}
+	return ""
+}
+
+// baseNone is the base of a style file starting from an empty Style.
+const baseNone = "none"
+
+// LoadStyle reads a Style in YAML or JSON from r. For example:
+//
+//	base: basic             # a registered style the file starts from
+//	level:
+//	  info: {text: INF, color: bold hi-green}
+//	  warn+2: {text: NTC, color: "#ffaf00"}
+//	message:
+//	  levels: {error: bold red}
+//	attr:
+//	  key_color: 245
+//	  separator: "="
+//	  width: auto
+//	  keys:
+//	    error: {value_color: bg-red white}
+//	caller:
+//	  prefix: {text: "<"}
+//	  suffix: {text: ">"}
+//	  format: func
+//
+// Colors are parsed by ParseColor and level keys by their names, such as
+// "debug", "warn+2" or "fatal". Fields missing from the file keep the values of
+// the base style, which defaults to Style0; this includes the fields of a level
+// or attribute key that is partially given. A base of "none" starts from an
+// empty Style, as in the files written by Style.MarshalYAML.
+func LoadStyle(r io.Reader) (*Style, error) {
+	var node yaml.Node
+	if err := yaml.NewDecoder(r).Decode(&node); err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	if node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		return decodeStyle(node.Content[0])
+	}
+	return decodeStyle(&node)
+}
+
+// decodeStyle decodes a style mapping over its base style.
+func decodeStyle(node *yaml.Node) (*Style, error) {
+	if node.Kind == 0 {
+		return Style0(), nil
+	}
+	var head struct {
+		Base    string               `yaml:"base"`
+		Level   map[string]yaml.Node `yaml:"level"`
+		Message struct {
+			Levels map[string]string `yaml:"levels"`
+		} `yaml:"message"`
+		Attr struct {
+			Keys map[string]yaml.Node `yaml:"keys"`
+		} `yaml:"attr"`
+	}
+	if err := node.Decode(&head); err != nil {
+		return nil, err
+	}
+	base := Style0()
+	switch head.Base {
+	case "":
+	case baseNone:
+		base = &Style{}
+	default:
+		s, ok := GetStyle(head.Base)
+		if !ok {
+			return nil, fmt.Errorf("unknown base style %q", head.Base)
+		}
+		base = s
+	}
+	f := encodeStyle(base)
+	levels, messages, keys := f.Level, f.Message.Levels, f.Attr.Keys
+	f.Level, f.Message.Levels, f.Attr.Keys = nil, nil, nil
+	if err := node.Decode(f); err != nil {
+		return nil, err
+	}
+
+	// Merge partially given levels and keys into those of the base.
+	f.Level = levels
+	for name, n := range head.Level {
+		name = canonicalLevel(name)
+		lf := f.Level[name]
+		if err := n.Decode(&lf); err != nil {
+			return nil, err
+		}
+		f.Level[name] = lf
+	}
+	f.Message.Levels = messages
+	for name, color := range head.Message.Levels {
+		f.Message.Levels[canonicalLevel(name)] = color
+	}
+	f.Attr.Keys = keys
+	for name, n := range head.Attr.Keys {
+		kf := f.Attr.Keys[name]
+		if err := n.Decode(&kf); err != nil {
+			return nil, err
+		}
+		f.Attr.Keys[name] = kf
+	}
+	return f.style(base)
+}
+
+// MarshalJSON implements json.Marshaler, encoding the Style in the format read
+// by LoadStyle. Functions such as AttrStyle.Colorizer are not encoded.
+func (s *Style) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.file())
+}
+
+// MarshalYAML implements yaml.Marshaler, encoding the Style in the format read
+// by LoadStyle. Functions such as AttrStyle.Colorizer are not encoded.
+func (s *Style) MarshalYAML() (any, error) {
+	return s.file(), nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler, decoding the Style as LoadStyle
+// does.
+func (s *Style) UnmarshalJSON(data []byte) error {
+	var v any
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	var node yaml.Node
+	if err := node.Encode(v); err != nil {
+		return err
+	}
+	return s.UnmarshalYAML(&node)
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler, decoding the Style as LoadStyle
+// does.
+func (s *Style) UnmarshalYAML(node *yaml.Node) error {
+	d, err := decodeStyle(node)
+	if err != nil {
+		return err
+	}
+	*s = *d
+	return nil
+}
+
+// file returns the file format of the Style, complete without a base style.
+func (s *Style) file() *styleFile {
+	f := encodeStyle(s)
+	f.Base = baseNone
+	return f
+}
+
+// canonicalLevel returns the name of the level of a level key as written by
+// encodeStyle, or the key itself if it is not a level.
+func canonicalLevel(name string) string {
+	level, err := parseLevel(name)
+	if err != nil {
+		return name
+	}
+	return levelName(level)
+}
+
+// levelName returns the name of a level in the form parsed by parseLevel,
+// relative to the nearest named level at or below it.
+func levelName(level slog.Level) string {
+	var name string
+	var base slog.Level
+	switch {
+	case level >= LevelFatal:
+		name, base = "fatal", LevelFatal
+	case level >= slog.LevelWarn:
+		return strings.ToLower(level.String())
+	case level >= LevelNotice:
+		name, base = "notice", LevelNotice
+	case level >= slog.LevelDebug:
+		return strings.ToLower(level.String())
+	default:
+		name, base = "trace", LevelTrace
+	}
+	if level == base {
+		return name
+	}
+	return fmt.Sprintf("%s%+d", name, level-base)
+}
+
+// encodeStyle returns the file format of a style. Functions are not encoded.
+func encodeStyle(s *Style) *styleFile {
+	f := &styleFile{
+		Level: make(map[string]levelFile, len(s.Level)),
+		Message: messageFile{
+			Color:  encodeColor(s.Message.Color),
+			Levels: make(map[string]string, len(s.Message.Levels)),
+		},
+		Label: labelFile{
+			Prefix:   encodeAffix(s.Label.Prefix),
+			Suffix:   encodeAffix(s.Label.Suffix),
+			Color:    encodeColor(s.Label.Color),
+			Width:    s.Label.Width,
+			Align:    encodeAlignment(s.Label.Align),
+			Truncate: encodeTruncation(s.Label.Truncate),
+		},
+		Attr: attrFile{
+			KeyColor:       encodeColor(s.Attr.KeyColor),
+			ValueColor:     encodeColor(s.Attr.ValueColor),
+			ErrorColor:     encodeColor(s.Attr.ErrorColor),
+			TraceColor:     encodeColor(s.Attr.TraceColor),
+			NilColor:       encodeColor(s.Attr.NilColor),
+			Bytes:          encodeBytesFormat(s.Attr.Bytes),
+			Duration:       encodeDurationFormat(s.Attr.Duration),
+			Keys:           make(map[string]attrKeyFile, len(s.Attr.Keys)),
+			Separator:      s.Attr.Separator,
+			GroupPrefix:    s.Attr.GroupPrefix,
+			GroupSuffix:    s.Attr.GroupSuffix,
+			GroupSeparator: s.Attr.GroupSeparator,
+		},
+		Caller: callerFile{
+			Prefix:   encodeAffix(s.Caller.Prefix),
+			Suffix:   encodeAffix(s.Caller.Suffix),
+			Color:    encodeColor(s.Caller.Color),
+			Fullpath: s.Caller.Fullpath,
+		},
+		Time: timeFile{
+			Prefix: encodeAffix(s.Time.Prefix),
+			Suffix: encodeAffix(s.Time.Suffix),
+			Color:  encodeColor(s.Time.Color),
+		},
+		Stack: stackFile{
+			Indent: s.Stack.Indent,
+			Color:  encodeColor(s.Stack.Color),
+		},
+		Multiline: multilineFile{
+			Indent: s.Multiline.Indent,
+			Marker: encodeAffix(s.Multiline.Marker),
+		},
+		Step: stepFile{
+			Running: encodeAffix(s.Step.Running),
+			Success: encodeAffix(s.Step.Success),
+			Failed:  encodeAffix(s.Step.Failed),
+			Skipped: encodeAffix(s.Step.Skipped),
+		},
+		Section: sectionFile{
+			Line:       s.Section.Line,
+			Side:       s.Section.Side,
+			Color:      encodeColor(s.Section.Color),
+			TitleColor: encodeColor(s.Section.TitleColor),
+			Width:      s.Section.Width,
+		},
+	}
+	if s.Section.Corners != [4]string{} {
+		f.Section.Corners = s.Section.Corners[:]
+	}
+	for level, ls := range s.Level {
+		f.Level[levelName(level)] = levelFile{
+			Prefix:   encodeAffix(ls.Prefix),
+			Suffix:   encodeAffix(ls.Suffix),
+			Icon:     ls.Icon,
+			Text:     ls.Text,
+			Color:    encodeColor(ls.Color),
+			Width:    ls.Width,
+			Align:    encodeAlignment(ls.Align),
+			Truncate: encodeTruncation(ls.Truncate),
+		}
+	}
+	for level, c := range s.Message.Levels {
+		f.Message.Levels[levelName(level)] = encodeColor(c)
+	}
+	for key, ks := range s.Attr.Keys {
+		f.Attr.Keys[key] = attrKeyFile{
+			KeyColor:   encodeColor(ks.KeyColor),
+			ValueColor: encodeColor(ks.ValueColor),
+		}
+	}
+	switch w := s.Attr.Width; {
+	case w == AttrWidthAuto:
+		f.Attr.Width = "auto"
+	case w > 0:
+		f.Attr.Width = strconv.Itoa(w)
+	}
+	for name, format := range callerFormats {
+		if format == s.Caller.Format && format != CallerFileLine {
+			f.Caller.Format = name
+		}
+	}
+	return f
+}
+
+// encodeAffix returns the file format of an AffixStyle.
+func encodeAffix(a AffixStyle) affixFile {
+	return affixFile{Text: a.Text, Color: encodeColor(a.Color)}
+}
+
+// encodeColor returns the file format of a Color, empty for no color.
+func encodeColor(c *Color) string {
+	if s := c.spec(); s != "none" {
+		return s
+	}
+	return ""
+}
+
+// style returns the Style described by the file, keeping the functions of base.
+func (f *styleFile) style(base *Style) (*Style, error) {
+	d := &styleDecoder{}
+	s := &Style{
+		Level: make(map[slog.Level]LevelStyle, len(f.Level)),
+		Message: MessageStyle{
+			Color: d.color(f.Message.Color),
+		},
+		Label: LabelStyle{
+			Prefix:   d.affix(f.Label.Prefix),
+			Suffix:   d.affix(f.Label.Suffix),
+			Color:    d.color(f.Label.Color),
+			Width:    f.Label.Width,
+			Align:    d.alignment(f.Label.Align),
+			Truncate: d.truncation(f.Label.Truncate),
+		},
+		Attr: AttrStyle{
+			KeyColor:        d.color(f.Attr.KeyColor),
+			ValueColor:      d.color(f.Attr.ValueColor),
+			ErrorColor:      d.color(f.Attr.ErrorColor),
+			TraceColor:      d.color(f.Attr.TraceColor),
+			NilColor:        d.color(f.Attr.NilColor),
+			Bytes:           d.bytesFormat(f.Attr.Bytes),
+			Duration:        d.durationFormat(f.Attr.Duration),
+			Colorizer:       base.Attr.Colorizer,
+			NumberFormatter: base.Attr.NumberFormatter,
+			Separator:       f.Attr.Separator,
+			GroupPrefix:     f.Attr.GroupPrefix,
+			GroupSuffix:     f.Attr.GroupSuffix,
+			GroupSeparator:  f.Attr.GroupSeparator,
+		},
+		Caller: CallerStyle{
+			Prefix:   d.affix(f.Caller.Prefix),
+			Suffix:   d.affix(f.Caller.Suffix),
+			Color:    d.color(f.Caller.Color),
+			Fullpath: f.Caller.Fullpath,
+		},
+		Time: TimeStyle{
+			Prefix: d.affix(f.Time.Prefix),
+			Suffix: d.affix(f.Time.Suffix),
+			Color:  d.color(f.Time.Color),
+		},
+		Stack: StackStyle{
+			Indent: f.Stack.Indent,
+			Color:  d.color(f.Stack.Color),
+		},
+		Multiline: MultilineStyle{
+			Indent: f.Multiline.Indent,
+			Marker: d.affix(f.Multiline.Marker),
+		},
+		Step: StepStyle{
+			Running: d.affix(f.Step.Running),
+			Success: d.affix(f.Step.Success),
+			Failed:  d.affix(f.Step.Failed),
+			Skipped: d.affix(f.Step.Skipped),
+		},
+		Section: SectionStyle{
+			Line:       f.Section.Line,
+			Side:       f.Section.Side,
+			Corners:    d.corners(f.Section.Corners),
+			Color:      d.color(f.Section.Color),
+			TitleColor: d.color(f.Section.TitleColor),
+			Width:      f.Section.Width,
+		},
+	}
+	for name, lf := range f.Level {
+		level, err := parseLevel(name)
+		if err != nil {
+			d.errs = append(d.errs, fmt.Errorf("invalid level %q: %w", name, err))
+			continue
+		}
+		s.Level[level] = LevelStyle{
+			Prefix:   d.affix(lf.Prefix),
+			Suffix:   d.affix(lf.Suffix),
+			Icon:     lf.Icon,
+			Text:     lf.Text,
+			Color:    d.color(lf.Color),
+			Width:    lf.Width,
+			Align:    d.alignment(lf.Align),
+			Truncate: d.truncation(lf.Truncate),
+		}
+	}
+	if len(f.Message.Levels) > 0 {
+		s.Message.Levels = make(map[slog.Level]*Color, len(f.Message.Levels))
+		for name, color := range f.Message.Levels {
+			level, err := parseLevel(name)
+			if err != nil {
+				d.errs = append(d.errs, fmt.Errorf("invalid message level %q: %w", name, err))
+				continue
+			}
+			s.Message.Levels[level] = d.color(color)
+		}
+	}
+	if len(f.Attr.Keys) > 0 {
+		s.Attr.Keys = make(map[string]AttrKeyStyle, len(f.Attr.Keys))
+		for key, kf := range f.Attr.Keys {
+			s.Attr.Keys[key] = AttrKeyStyle{
+				KeyColor:   d.color(kf.KeyColor),
+				ValueColor: d.color(kf.ValueColor),
+				Format:     base.Attr.Keys[key].Format,
+			}
+		}
+	}
+	switch w := f.Attr.Width; w {
+	case "":
+	case "auto":
+		s.Attr.Width = AttrWidthAuto
+	default:
+		n, err := strconv.Atoi(w)
+		if err != nil || n < 0 {
+			d.errs = append(d.errs, fmt.Errorf("invalid attr width %q: want a number of columns or auto", w))
+		}
+		s.Attr.Width = n
+	}
+	if f.Caller.Format != "" {
+		format, ok := callerFormats[f.Caller.Format]
+		if !ok {
+			d.errs = append(d.errs, fmt.Errorf("invalid caller format %q: want file-line, file, func or package-func", f.Caller.Format))
+		}
+		s.Caller.Format = format
+	}
+	if len(d.errs) > 0 {
+		return nil, errors.Join(d.errs...)
+	}
+	return s, nil
+}
+
+// styleDecoder parses the colors of a style file, collecting the errors.
+type styleDecoder struct {
+	errs []error
+}
+
+// color parses a color, recording an error if it is invalid.
+func (d *styleDecoder) color(s string) *Color {
+	c, err := ParseColor(s)
+	if err != nil {
+		d.errs = append(d.errs, err)
+	}
+	return c
+}
+
+// alignment parses the name of an alignment, where "" is AlignCenter.
+func (d *styleDecoder) alignment(s string) Alignment {
+	if s == "" {
+		return AlignCenter
+	}
+	a, ok := alignments[s]
+	if !ok {
+		d.errs = append(d.errs, fmt.Errorf("invalid alignment %q: want center, left or right", s))
+	}
+	return a
+}
+
+// truncation parses the name of a truncation, where "" is TruncateNone.
+func (d *styleDecoder) truncation(s string) Truncation {
+	if s == "" {
+		return TruncateNone
+	}
+	t, ok := truncations[s]
+	if !ok {
+		d.errs = append(d.errs, fmt.Errorf("invalid truncation %q: want none, right or middle", s))
+	}
+	return t
+}
+
+// bytesFormat parses the name of a byte slice format, where "" is BytesText.
+func (d *styleDecoder) bytesFormat(s string) BytesFormat {
+	if s == "" {
+		return BytesText
+	}
+	f, ok := bytesFormats[s]
+	if !ok {
+		d.errs = append(d.errs, fmt.Errorf("invalid bytes format %q: want text, hex, base64 or length", s))
+	}
+	return f
+}
+
+// durationFormat parses the name of a duration format, where "" is
+// DurationDefault.
+func (d *styleDecoder) durationFormat(s string) DurationFormat {
+	if s == "" {
+		return DurationDefault
+	}
+	f, ok := durationFormats[s]
+	if !ok {
+		d.errs = append(d.errs, fmt.Errorf("invalid duration format %q: want default, millis or human", s))
+	}
+	return f
+}
+
+// affix parses the color of an affix.
+func (d *styleDecoder) affix(f affixFile) AffixStyle {
+	return AffixStyle{Text: f.Text, Color: d.color(f.Color)}
+}
+
+// corners returns the corners of a SectionStyle, recording an error unless
+// there are none or four.
+func (d *styleDecoder) corners(s []string) [4]string {
+	var c [4]string
+	switch len(s) {
+	case 0:
+	case 4:
+		copy(c[:], s)
+	default:
+		d.errs = append(d.errs, fmt.Errorf("invalid section corners %q: want four corners", s))
+	}
+	return c
+}
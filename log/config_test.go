@@ -0,0 +1,367 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"go.yaml.in/yaml/v3"
+)
+
+func TestLoadStyle(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		check   func(t *testing.T, s *Style)
+		wantErr string
+	}{
+		{
+			name: "empty",
+			in:   "",
+			check: func(t *testing.T, s *Style) {
+				if !reflect.DeepEqual(s, Style0()) {
+					t.Errorf("got %+v, want Style0", s)
+				}
+			},
+		},
+		{
+			name: "overlay on the base",
+			in: `
+base: basic
+level:
+  INFO: {text: INFO}
+  warn+2: {text: NTC, color: "#ffaf00", width: 5}
+  debug: {text: DEBUGGING, width: 5, align: left, truncate: middle}
+label: {width: 8, align: right, truncate: right}
+message:
+  levels: {ERROR: bold red}
+attr:
+  key_color: 245
+  width: auto
+  keys:
+    error: {key_color: red}
+caller:
+  format: func
+step:
+  success: {text: OK}
+`,
+			check: func(t *testing.T, s *Style) {
+				info := s.Level[slog.LevelInfo]
+				if info.Text != "INFO" || !reflect.DeepEqual(info.Color, NewColor(Bold, FgHiGreen)) {
+					t.Errorf("info = %+v, want the text replaced and the color kept", info)
+				}
+				if got := s.Level[slog.LevelWarn+2]; got.Text != "NTC" || got.Width != 5 || !reflect.DeepEqual(got.Color, NewColor(38, 2, 255, 175, 0)) {
+					t.Errorf("warn+2 = %+v", got)
+				}
+				if got := s.Level[slog.LevelDebug]; got.Width != 5 || got.Align != AlignLeft || got.Truncate != TruncateMiddle {
+					t.Errorf("debug = %+v", got)
+				}
+				if s.Label.Width != 8 || s.Label.Align != AlignRight || s.Label.Truncate != TruncateRight {
+					t.Errorf("label = %+v", s.Label)
+				}
+				if !reflect.DeepEqual(s.Attr.KeyColor, NewColor(38, 5, 245)) || s.Attr.Width != AttrWidthAuto {
+					t.Errorf("attr = %+v", s.Attr)
+				}
+				if got := s.Attr.Keys["error"]; !reflect.DeepEqual(got.KeyColor, NewColor(FgRed)) || !reflect.DeepEqual(got.ValueColor, NewColor(FgHiRed)) {
+					t.Errorf("error key = %+v, want the key color replaced and the value color kept", got)
+				}
+				if _, ok := s.Attr.Keys["err"]; !ok {
+					t.Error("keys of the base are dropped")
+				}
+				if got := s.messageColor(LevelFatal); !reflect.DeepEqual(got, NewColor(Bold, FgRed)) {
+					t.Errorf("fatal message color = %v", got)
+				}
+				if s.Caller.Format != CallerFuncName || s.Caller.Prefix.Text != "<" {
+					t.Errorf("caller = %+v", s.Caller)
+				}
+				if s.Step.Success.Text != "OK" || !reflect.DeepEqual(s.Step.Success.Color, NewColor(Bold, FgHiGreen)) || s.Step.Failed.Text != "✗" {
+					t.Errorf("step = %+v, want the success text replaced and the rest kept", s.Step)
+				}
+			},
+		},
+		{
+			name: "json",
+			in:   `{"level": {"fatal": {"text": "FTL", "prefix": {"text": "!", "color": "bg-red"}}}, "attr": {"separator": ": ", "width": "12"}}`,
+			check: func(t *testing.T, s *Style) {
+				fatal := s.Level[LevelFatal]
+				if fatal.Text != "FTL" || fatal.Prefix.Text != "!" || !reflect.DeepEqual(fatal.Prefix.Color, NewColor(BgRed)) {
+					t.Errorf("fatal = %+v", fatal)
+				}
+				if s.Attr.Separator != ": " || s.Attr.Width != 12 {
+					t.Errorf("attr = %+v", s.Attr)
+				}
+				if s.Level[slog.LevelInfo].Text != "[INF]" {
+					t.Error("levels of the base are dropped")
+				}
+			},
+		},
+		{
+			name: "section",
+			in:   "base: vivid\nsection: {line: '=', corners: ['*', '*', '*', '*'], title_color: red}",
+			check: func(t *testing.T, s *Style) {
+				want := SectionStyle{Line: "=", Side: "│", Corners: [4]string{"*", "*", "*", "*"}, Color: NewColorRGB(95, 95, 255), TitleColor: NewColor(FgRed)}
+				if !reflect.DeepEqual(s.Section, want) {
+					t.Errorf("section = %+v, want %+v", s.Section, want)
+				}
+			},
+		},
+		{
+			name:    "section corners",
+			in:      "section: {corners: ['+']}",
+			wantErr: "want four corners",
+		},
+		{
+			name:    "unknown base",
+			in:      "base: nope",
+			wantErr: `unknown base style "nope"`,
+		},
+		{
+			name:    "invalid values",
+			in:      "level: {loud: {text: L}}\nattr: {key_color: orange, width: wide}\ncaller: {format: line}",
+			wantErr: `invalid level "loud"`,
+		},
+		{
+			name:    "syntax",
+			in:      "level: [",
+			wantErr: "yaml",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := LoadStyle(strings.NewReader(tt.in))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			tt.check(t, s)
+		})
+	}
+}
+
+func TestLoadStyle_invalid(t *testing.T) {
+	_, err := LoadStyle(strings.NewReader("label: {align: top, truncate: left}\nattr: {key_color: orange, width: wide, bytes: octal, duration: long}\ncaller: {format: line}"))
+	if err == nil {
+		t.Fatal("want an error")
+	}
+	for _, want := range []string{`"top"`, `"left"`, `"orange"`, `"wide"`, `"octal"`, `"long"`, `"line"`} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not mention %s", err, want)
+		}
+	}
+}
+
+func TestLoadStyle_registered(t *testing.T) {
+	for _, name := range StyleNames() {
+		t.Run(name, func(t *testing.T) {
+			want, _ := GetStyle(name)
+			got, err := LoadStyle(strings.NewReader("base: " + name))
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, s := range []*Style{want, got} {
+				s.Attr.Colorizer = nil
+				for k, ks := range s.Attr.Keys {
+					ks.Format = nil
+					s.Attr.Keys[k] = ks
+				}
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("got %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	c, err := LoadConfig(strings.NewReader(`
+level: debug
+format: LOGFMT
+color: never
+time_format: kitchen
+caller: false
+label: app
+stack: fatal
+multiline: true
+width: 80
+style:
+  base: plain
+  level: {debug: {text: D}}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Level == nil || *c.Level != slog.LevelDebug || c.Stack == nil || *c.Stack != LevelFatal {
+		t.Errorf("levels = %v, %v", c.Level, c.Stack)
+	}
+	if c.Format != "logfmt" || c.Color == nil || *c.Color != ColorNever || c.TimeFormat != time.Kitchen {
+		t.Errorf("got %+v", c)
+	}
+	if c.Caller == nil || *c.Caller || c.Label != "app" || c.Multiline == nil || !*c.Multiline || c.Width != 80 {
+		t.Errorf("got %+v", c)
+	}
+	if c.Style == nil || c.Style.Level[slog.LevelDebug].Text != "D" {
+		t.Errorf("style = %+v", c.Style)
+	}
+}
+
+func TestLoadConfig_invalid(t *testing.T) {
+	_, err := LoadConfig(strings.NewReader("level: loud\nformat: xml\ncolor: sometimes\nstack: high\nstyle: unknown"))
+	if err == nil {
+		t.Fatal("want an error")
+	}
+	for _, key := range []string{"level", "format", "color", "stack", "style"} {
+		if !strings.Contains(err.Error(), "invalid "+key) {
+			t.Errorf("error %q does not mention %s", err, key)
+		}
+	}
+}
+
+func TestConfig_NewHandler(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		opts []CLIHandlerOption
+		want string
+	}{
+		{
+			name: "empty",
+			in:   "color: never",
+			want: "WRN msg k=1\n",
+		},
+		{
+			name: "cli",
+			in:   "style: plain\nlabel: app\nlevel: warn",
+			opts: []CLIHandlerOption{WithLevel(slog.LevelDebug)},
+			want: "[WRN] app msg k=1\n",
+		},
+		{
+			name: "inline style",
+			in:   "color: never\nstyle: {level: {warn: {text: W}}, attr: {separator: ':'}}",
+			want: "W msg k:1\n",
+		},
+		{
+			name: "json",
+			in:   "format: json\ntime: false",
+			want: `"level":"WARN","msg":"msg","k":1}` + "\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := LoadConfig(strings.NewReader(tt.in))
+			if err != nil {
+				t.Fatal(err)
+			}
+			buf := &bytes.Buffer{}
+			l := NewLogger(c.NewHandler(buf, tt.opts...))
+			l.Info("hidden")
+			l.Warn("msg", "k", 1)
+			if got := buf.String(); !strings.HasSuffix(got, tt.want) {
+				t.Errorf("got %q, want suffix %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// funcless returns the style without its functions, which are not encoded.
+func funcless(s *Style) *Style {
+	s.Attr.Colorizer = nil
+	for k, ks := range s.Attr.Keys {
+		ks.Format = nil
+		s.Attr.Keys[k] = ks
+	}
+	return s
+}
+
+func TestStyle_Marshal(t *testing.T) {
+	custom := &Style{
+		Level: map[slog.Level]LevelStyle{
+			slog.LevelDebug - 4: {Text: "TRC", Color: NewColor256(245)},
+			slog.LevelInfo:      {Text: "", Width: 4},
+			LevelFatal + 1:      {Text: "PANIC", Color: NewColorRGB(255, 0, 0, Bold), Prefix: AffixStyle{Text: "<<", Color: NewColor(Faint)}},
+		},
+		Message: MessageStyle{
+			Color:  NewColor(FgWhite),
+			Levels: map[slog.Level]*Color{slog.LevelWarn: nil, slog.LevelError: NewColor(Bold, FgRed)},
+		},
+		Label: LabelStyle{Suffix: AffixStyle{Text: ":"}, Color: NewBgColor256(17, FgWhite), Width: 8},
+		Attr: AttrStyle{
+			KeyColor:       NewColor(FgCyan),
+			TraceColor:     NewColor(Italic, 53),
+			Keys:           map[string]AttrKeyStyle{"id": {}, "user": {ValueColor: NewBgColorRGB(1, 2, 3)}},
+			Separator:      ": ",
+			GroupPrefix:    "[",
+			GroupSuffix:    "]",
+			GroupSeparator: "/",
+			Width:          AttrWidthAuto,
+		},
+		Caller:    CallerStyle{Color: NewColor(FgHiBlack), Fullpath: true, Format: CallerPackageFunc},
+		Time:      TimeStyle{Prefix: AffixStyle{Text: "@"}},
+		Stack:     StackStyle{Indent: "\t"},
+		Multiline: MultilineStyle{Marker: AffixStyle{Text: "|", Color: NewColor(FgHiBlack)}},
+	}
+	styles := map[string]*Style{"custom": custom, "empty": {Level: map[slog.Level]LevelStyle{}}}
+	for _, name := range StyleNames() {
+		styles[name], _ = GetStyle(name)
+	}
+	for name, want := range styles {
+		want = funcless(want)
+		t.Run(name+"/yaml", func(t *testing.T) {
+			b, err := yaml.Marshal(want)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := LoadStyle(bytes.NewReader(b))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("got %+v, want %+v\n%s", got, want, b)
+			}
+		})
+		t.Run(name+"/json", func(t *testing.T) {
+			b, err := json.MarshalIndent(want, "", "\t")
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := &Style{}
+			if err := json.Unmarshal(b, got); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("got %+v, want %+v\n%s", got, want, b)
+			}
+		})
+	}
+}
+
+func TestStyle_MarshalYAML(t *testing.T) {
+	s := &Style{
+		Level: map[slog.Level]LevelStyle{slog.LevelInfo: {Text: "INF", Color: NewColor(Bold, FgHiGreen)}},
+		Attr:  AttrStyle{Separator: "=", Width: 10},
+	}
+	b, err := yaml.Marshal(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `base: none
+level:
+    info:
+        text: INF
+        color: bold hi-green
+attr:
+    separator: =
+    width: "10"
+`
+	if string(b) != want {
+		t.Errorf("got\n%s\nwant\n%s", b, want)
+	}
+}
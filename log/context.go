@@ -0,0 +1,32 @@
+package log
+
+import (
+	"context"
+	"log/slog"
+)
+
+// contextKey is the context key for attributes carried by a context.
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying the given attributes in addition
+// to those already carried by ctx. Handlers in this package append them to
+// every record logged with the returned context.
+func NewContext(ctx context.Context, attrs ...slog.Attr) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	parent := FromContext(ctx)
+	a := make([]slog.Attr, 0, len(parent)+len(attrs))
+	a = append(a, parent...)
+	a = append(a, attrs...)
+	return context.WithValue(ctx, contextKey{}, a)
+}
+
+// FromContext returns the attributes carried by ctx.
+func FromContext(ctx context.Context) []slog.Attr {
+	if ctx == nil {
+		return nil
+	}
+	a, _ := ctx.Value(contextKey{}).([]slog.Attr)
+	return a
+}
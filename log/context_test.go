@@ -0,0 +1,139 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestNewContext(t *testing.T) {
+	type args struct {
+		ctx   context.Context
+		attrs []slog.Attr
+	}
+	tests := []struct {
+		name string
+		args args
+		want []slog.Attr
+	}{
+		{
+			name: "background",
+			args: args{
+				ctx:   context.Background(),
+				attrs: []slog.Attr{slog.String("request_id", "r1")},
+			},
+			want: []slog.Attr{slog.String("request_id", "r1")},
+		},
+		{
+			name: "nil context",
+			args: args{
+				ctx:   nil,
+				attrs: []slog.Attr{slog.String("request_id", "r1")},
+			},
+			want: []slog.Attr{slog.String("request_id", "r1")},
+		},
+		{
+			name: "append to parent",
+			args: args{
+				ctx:   NewContext(context.Background(), slog.String("request_id", "r1")),
+				attrs: []slog.Attr{slog.String("user_id", "u1")},
+			},
+			want: []slog.Attr{slog.String("request_id", "r1"), slog.String("user_id", "u1")},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := FromContext(NewContext(tt.args.ctx, tt.args.attrs...))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FromContext() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewContext_parentUnchanged(t *testing.T) {
+	parent := NewContext(context.Background(), slog.String("a", "1"))
+	_ = NewContext(parent, slog.String("b", "2"))
+	_ = NewContext(parent, slog.String("c", "3"))
+	if got := FromContext(parent); len(got) != 1 {
+		t.Errorf("len(FromContext(parent)) = %v, want 1", len(got))
+	}
+}
+
+func TestFromContext(t *testing.T) {
+	tests := []struct {
+		name string
+		ctx  context.Context
+		want []slog.Attr
+	}{
+		{
+			name: "nil context",
+			ctx:  nil,
+			want: nil,
+		},
+		{
+			name: "no attrs",
+			ctx:  context.Background(),
+			want: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FromContext(tt.ctx); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FromContext() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCLIHandler_Handle_context(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler func(*bytes.Buffer) slog.Handler
+		want    string
+	}{
+		{
+			name: "cli",
+			handler: func(buf *bytes.Buffer) slog.Handler {
+				return NewCLIHandler(buf, WithStyle(Style0()))
+			},
+			want: "[INF] msg request_id=r1 user_id=u1 key=val\n",
+		},
+		{
+			name: "cli with attr handler",
+			handler: func(buf *bytes.Buffer) slog.Handler {
+				return NewCLIHandler(buf, WithStyle(Style0()), WithAttrHandler(func(a slog.Attr) slog.Attr {
+					if a.Key == "user_id" {
+						a.Value = slog.StringValue("***")
+					}
+					return a
+				}))
+			},
+			want: "[INF] msg request_id=r1 user_id=*** key=val\n",
+		},
+		{
+			name: "logfmt",
+			handler: func(buf *bytes.Buffer) slog.Handler {
+				return NewLogfmtHandler(buf)
+			},
+			want: "level=INFO msg=msg request_id=r1 user_id=u1 key=val\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			ctx := NewContext(context.Background(), slog.String("request_id", "r1"), slog.String("user_id", "u1"))
+			r := slog.NewRecord(time.Time{}, slog.LevelInfo, "msg", 0)
+			r.AddAttrs(slog.String("key", "val"))
+			if err := tt.handler(buf).Handle(ctx, r); err != nil {
+				t.Fatal(err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,29 @@
+package log
+
+import (
+	"io"
+	"log/slog"
+)
+
+// WithFormatting returns a CLIHandlerOption that makes NewDiscardHandler format
+// each record before discarding it. Other handlers ignore it.
+func WithFormatting(formatting bool) CLIHandlerOption {
+	return func(c *CLIHandler) {
+		c.formatOnly = formatting
+	}
+}
+
+// NewDiscardHandler creates a new handler that discards all records, to measure
+// the cost of logging in benchmarks. By default it is disabled at every level,
+// as slog.DiscardHandler is, so that only the calls are measured. With
+// WithFormatting(true), it formats the records as New would with the same
+// options and discards the bytes, so that formatting is measured without
+// writing:
+//
+//	h := log.NewDiscardHandler(log.WithFormatting(true), log.WithCaller(true))
+func NewDiscardHandler(opts ...CLIHandlerOption) slog.Handler {
+	if !optionsOf(opts).formatOnly {
+		return slog.DiscardHandler
+	}
+	return New(io.Discard, opts...)
+}
@@ -0,0 +1,30 @@
+package log
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+func TestNewDiscardHandler(t *testing.T) {
+	ctx := context.Background()
+	if h := NewDiscardHandler(); h != slog.DiscardHandler {
+		t.Errorf("got %T, want slog.DiscardHandler", h)
+	}
+
+	h := NewDiscardHandler(WithFormatting(true), WithLevel(slog.LevelWarn))
+	if _, ok := h.(*CLIHandler); !ok {
+		t.Fatalf("got %T, want *CLIHandler", h)
+	}
+	if h.Enabled(ctx, slog.LevelInfo) || !h.Enabled(ctx, slog.LevelWarn) {
+		t.Error("level not applied")
+	}
+	if err := h.Handle(ctx, slog.NewRecord(time.Now(), slog.LevelWarn, "msg", 0)); err != nil {
+		t.Errorf("Handle = %v", err)
+	}
+
+	if _, ok := NewDiscardHandler(WithFormatting(true), WithFormat(FormatJSON)).(*slog.JSONHandler); !ok {
+		t.Error("format not applied")
+	}
+}
@@ -0,0 +1,171 @@
+package log
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Environment variables read by NewFromEnv.
+const (
+	EnvLevel      = "LOG_LEVEL"
+	EnvFormat     = "LOG_FORMAT"
+	EnvStyle      = "LOG_STYLE"
+	EnvColor      = "LOG_COLOR"
+	EnvTimeFormat = "LOG_TIME_FORMAT"
+	EnvCaller     = "LOG_CALLER"
+)
+
+// timeLayouts maps the lowercase names of the time package layouts to the layouts.
+var timeLayouts = map[string]string{
+	"ansic":       time.ANSIC,
+	"unixdate":    time.UnixDate,
+	"rfc822":      time.RFC822,
+	"rfc1123":     time.RFC1123,
+	"rfc3339":     time.RFC3339,
+	"rfc3339nano": time.RFC3339Nano,
+	"kitchen":     time.Kitchen,
+	"stamp":       time.Stamp,
+	"stampmilli":  time.StampMilli,
+	"stampmicro":  time.StampMicro,
+	"datetime":    time.DateTime,
+	"dateonly":    time.DateOnly,
+	"timeonly":    time.TimeOnly,
+}
+
+// NewFromEnv creates a new Logger writing to w, configured by the environment:
+//
+//   - LOG_LEVEL: a level such as "trace", "debug", "info", "warn+2" or "fatal"
+//   - LOG_FORMAT: "cli" (the default), "json" or "logfmt"
+//   - LOG_STYLE: the name of a style registered with RegisterStyle
+//   - LOG_COLOR: "auto", "always" or "never", or a boolean
+//   - LOG_TIME_FORMAT: a time layout, or the name of a layout of the time
+//     package such as "rfc3339" or "kitchen"; setting it enables the time
+//   - LOG_CALLER: a boolean enabling the caller
+//
+// The options are applied before the environment, which takes precedence.
+// Unset or empty variables keep the configured values. NewFromEnv returns an
+// error describing every invalid variable.
+func NewFromEnv(w io.Writer, opts ...CLIHandlerOption) (*Logger, error) {
+	h, err := NewHandlerFromEnv(w, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return NewLogger(h), nil
+}
+
+// NewHandlerFromEnv creates a new handler writing to w, configured by the
+// environment as described in NewFromEnv.
+func NewHandlerFromEnv(w io.Writer, opts ...CLIHandlerOption) (slog.Handler, error) {
+	c, err := readEnv()
+	if err != nil {
+		return nil, err
+	}
+	return c.NewHandler(w, opts...), nil
+}
+
+// readEnv reads and validates the environment variables.
+func readEnv() (*Config, error) {
+	c := &Config{}
+	var errs []error
+	invalid := func(key, value string, err error) {
+		errs = append(errs, fmt.Errorf("invalid %s %q: %w", key, value, err))
+	}
+	if v := os.Getenv(EnvLevel); v != "" {
+		level, err := parseLevel(v)
+		if err != nil {
+			invalid(EnvLevel, v, err)
+		}
+		c.Level = &level
+	}
+	if v := os.Getenv(EnvFormat); v != "" {
+		format, err := parseFormat(v)
+		if err != nil {
+			invalid(EnvFormat, v, err)
+		}
+		c.Format = format
+	}
+	if v := os.Getenv(EnvStyle); v != "" {
+		s, ok := GetStyle(v)
+		if !ok {
+			invalid(EnvStyle, v, fmt.Errorf("want one of %s", strings.Join(StyleNames(), ", ")))
+		}
+		c.Style = s
+	}
+	if v := os.Getenv(EnvColor); v != "" {
+		mode, err := parseColorMode(v)
+		if err != nil {
+			invalid(EnvColor, v, err)
+		}
+		c.Color = &mode
+	}
+	if v := os.Getenv(EnvTimeFormat); v != "" {
+		c.TimeFormat = timeLayout(v)
+	}
+	if v := os.Getenv(EnvCaller); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			invalid(EnvCaller, v, errors.New("want a boolean"))
+		}
+		c.Caller = &b
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return c, nil
+}
+
+// namedLevels are the levels parsed by name in addition to those of slog.
+var namedLevels = map[string]slog.Level{
+	"trace":  LevelTrace,
+	"notice": LevelNotice,
+	"fatal":  LevelFatal,
+}
+
+// parseLevel parses a level name as slog.Level.UnmarshalText does, also
+// accepting "trace", "notice" and "fatal" with an optional offset.
+func parseLevel(s string) (slog.Level, error) {
+	name, offset := s, ""
+	if i := strings.IndexAny(s, "+-"); i >= 0 {
+		name, offset = s[:i], s[i:]
+	}
+	if level, ok := namedLevels[strings.ToLower(name)]; ok {
+		if offset != "" {
+			n, err := strconv.Atoi(offset)
+			if err != nil {
+				return 0, fmt.Errorf("bad level offset %q", offset)
+			}
+			level += slog.Level(n)
+		}
+		return level, nil
+	}
+	var level slog.Level
+	err := level.UnmarshalText([]byte(s))
+	return level, err
+}
+
+// parseColorMode parses "auto", "always" or "never", or a boolean selecting
+// ColorAlways or ColorNever.
+func parseColorMode(s string) (ColorMode, error) {
+	switch strings.ToLower(s) {
+	case "auto":
+		return ColorAuto, nil
+	case "always":
+		return ColorAlways, nil
+	case "never":
+		return ColorNever, nil
+	}
+	b, err := strconv.ParseBool(s)
+	if err != nil {
+		return ColorAuto, errors.New("want auto, always, never or a boolean")
+	}
+	if b {
+		return ColorAlways, nil
+	}
+	return ColorNever, nil
+}
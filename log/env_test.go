@@ -0,0 +1,211 @@
+package log
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewFromEnv(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		opts []CLIHandlerOption
+		log  func(*Logger)
+		want string
+		tail string
+	}{
+		{
+			name: "default",
+			env:  map[string]string{EnvColor: "never"},
+			log:  func(l *Logger) { l.Debug("hidden"); l.Info("msg", "k", 1) },
+			want: "INF msg k=1\n",
+		},
+		{
+			name: "level",
+			env:  map[string]string{EnvLevel: "DEBUG", EnvColor: "false"},
+			log:  func(l *Logger) { l.Debug("msg") },
+			want: "DBG msg\n",
+		},
+		{
+			name: "fatal level",
+			env:  map[string]string{EnvLevel: "fatal"},
+			log:  func(l *Logger) { l.Error("hidden") },
+			want: "",
+		},
+		{
+			name: "style",
+			env:  map[string]string{EnvStyle: "plain"},
+			log:  func(l *Logger) { l.Info("msg") },
+			want: "[INF] msg\n",
+		},
+		{
+			name: "options are overridden",
+			env:  map[string]string{EnvStyle: "plain", EnvLevel: "warn"},
+			opts: []CLIHandlerOption{WithStyle(Style2()), WithLevel(slog.LevelDebug)},
+			log:  func(l *Logger) { l.Info("hidden"); l.Warn("msg") },
+			want: "[WRN] msg\n",
+		},
+		{
+			name: "options are kept",
+			opts: []CLIHandlerOption{WithStyle(Style0()), WithLevel(slog.LevelDebug)},
+			log:  func(l *Logger) { l.Debug("msg") },
+			want: "[DBG] msg\n",
+		},
+		{
+			name: "caller",
+			env:  map[string]string{EnvStyle: "plain", EnvCaller: "true"},
+			log:  func(l *Logger) { l.Info("msg") },
+			want: "[INF] <env_test.go:",
+		},
+		{
+			name: "color",
+			env:  map[string]string{EnvColor: "always"},
+			log:  func(l *Logger) { l.Info("msg") },
+			want: "\x1b[",
+		},
+		{
+			name: "time format",
+			env:  map[string]string{EnvStyle: "plain", EnvTimeFormat: "kitchen"},
+			log:  func(l *Logger) { l.Info("msg") },
+			want: "[INF] msg time=",
+		},
+		{
+			name: "logfmt",
+			env:  map[string]string{EnvFormat: "logfmt", EnvLevel: "debug"},
+			log:  func(l *Logger) { l.Debug("msg", "k", 1) },
+			want: "time=",
+			tail: " level=DEBUG msg=msg k=1\n",
+		},
+		{
+			name: "json",
+			env:  map[string]string{EnvFormat: "JSON", EnvTimeFormat: "2006", EnvCaller: "1"},
+			log:  func(l *Logger) { l.Info("msg") },
+			want: `{"time":"` + time.Now().Format("2006") + `","level":"INFO","source":{`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, k := range []string{EnvLevel, EnvFormat, EnvStyle, EnvColor, EnvTimeFormat, EnvCaller} {
+				t.Setenv(k, tt.env[k])
+			}
+			buf := &bytes.Buffer{}
+			l, err := NewFromEnv(buf, tt.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			tt.log(l)
+			got := buf.String()
+			if !strings.HasPrefix(got, tt.want) || !strings.HasSuffix(got, tt.tail) || (tt.want == "" && got != "") {
+				t.Errorf("got %q, want prefix %q and suffix %q", got, tt.want, tt.tail)
+			}
+		})
+	}
+}
+
+func TestNewFromEnv_invalid(t *testing.T) {
+	t.Setenv(EnvLevel, "loud")
+	t.Setenv(EnvFormat, "xml")
+	t.Setenv(EnvStyle, "unknown")
+	t.Setenv(EnvColor, "sometimes")
+	t.Setenv(EnvCaller, "yes")
+	l, err := NewFromEnv(nil)
+	if l != nil || err == nil {
+		t.Fatalf("got %v, %v, want an error", l, err)
+	}
+	for _, key := range []string{EnvLevel, EnvFormat, EnvStyle, EnvColor, EnvCaller} {
+		if !strings.Contains(err.Error(), "invalid "+key) {
+			t.Errorf("error %q does not mention %s", err, key)
+		}
+	}
+}
+
+func Test_parseLevel(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    slog.Level
+		wantErr bool
+	}{
+		{in: "trace", want: LevelTrace},
+		{in: "TRACE-2", want: LevelTrace - 2},
+		{in: "debug", want: slog.LevelDebug},
+		{in: "notice", want: LevelNotice},
+		{in: "Notice+1", want: LevelNotice + 1},
+		{in: "INFO+2", want: slog.LevelInfo + 2},
+		{in: "warn-1", want: slog.LevelWarn - 1},
+		{in: "error", want: slog.LevelError},
+		{in: "Fatal", want: LevelFatal},
+		{in: "fatal+1", want: LevelFatal + 1},
+		{in: "fatal+x", wantErr: true},
+		{in: "verbose", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := parseLevel(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_levelName(t *testing.T) {
+	tests := []struct {
+		in   slog.Level
+		want string
+	}{
+		{in: LevelTrace - 2, want: "trace-2"},
+		{in: LevelTrace, want: "trace"},
+		{in: LevelTrace + 1, want: "trace+1"},
+		{in: slog.LevelDebug, want: "debug"},
+		{in: slog.LevelInfo + 1, want: "info+1"},
+		{in: LevelNotice, want: "notice"},
+		{in: LevelNotice + 1, want: "notice+1"},
+		{in: slog.LevelWarn, want: "warn"},
+		{in: slog.LevelError + 2, want: "error+2"},
+		{in: LevelFatal, want: "fatal"},
+		{in: LevelFatal + 4, want: "fatal+4"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			got := levelName(tt.in)
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+			if level, err := parseLevel(got); err != nil || level != tt.in {
+				t.Errorf("parseLevel(%q) = %v, %v, want %v", got, level, err, tt.in)
+			}
+		})
+	}
+}
+
+func Test_parseColorMode(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    ColorMode
+		wantErr bool
+	}{
+		{in: "auto", want: ColorAuto},
+		{in: "ALWAYS", want: ColorAlways},
+		{in: "never", want: ColorNever},
+		{in: "true", want: ColorAlways},
+		{in: "0", want: ColorNever},
+		{in: "maybe", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := parseColorMode(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
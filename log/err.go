@@ -0,0 +1,29 @@
+package log
+
+import "log/slog"
+
+// ErrorKey is the attr key of the errors attached by Err and Logger.Err. The
+// built-in styles write the key and the value of these attrs in red.
+const ErrorKey = "error"
+
+// Err returns the attr of err under ErrorKey, or an empty attr, which handlers
+// ignore, if err is nil:
+//
+//	logger.Error("sync failed", log.Err(err))
+func Err(err error) slog.Attr {
+	if err == nil {
+		return slog.Attr{}
+	}
+	return slog.Any(ErrorKey, err)
+}
+
+// Err returns a copy of the logger that includes err under ErrorKey in each
+// record, or the logger itself if err is nil:
+//
+//	logger.Err(err).Error("sync failed")
+func (l *Logger) Err(err error) *Logger {
+	if err == nil {
+		return l
+	}
+	return l.WithAttrs(Err(err))
+}
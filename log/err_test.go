@@ -0,0 +1,62 @@
+package log
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestErr(t *testing.T) {
+	tests := []struct {
+		name string
+		log  func(l *Logger)
+		want string
+	}{
+		{
+			name: "attr",
+			log:  func(l *Logger) { l.Error("failed", Err(errors.New("boom")), "k", "v") },
+			want: "[ERR] failed error=boom k=v\n",
+		},
+		{
+			name: "nil attr",
+			log:  func(l *Logger) { l.Error("failed", Err(nil), "k", "v") },
+			want: "[ERR] failed k=v\n",
+		},
+		{
+			name: "logger",
+			log:  func(l *Logger) { l.Err(errors.New("boom")).Error("failed", "k", "v") },
+			want: "[ERR] failed error=boom k=v\n",
+		},
+		{
+			name: "nil logger",
+			log:  func(l *Logger) { l.Err(nil).Error("failed", "k", "v") },
+			want: "[ERR] failed k=v\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			tt.log(NewLogger(NewCLIHandler(buf, WithStyle(Style0()))))
+			if got := buf.String(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestErr_style(t *testing.T) {
+	buf := &bytes.Buffer{}
+	l := NewLogger(NewCLIHandler(buf, WithStyle(Style1()), WithColorMode(ColorAlways)))
+	l.Err(errors.New("boom")).Info("failed")
+	want := "\x1b[1;92mINF\x1b[0m failed \x1b[91merror\x1b[0m\x1b[91m=\x1b[0m\x1b[91mboom\x1b[0m\n"
+	if got := buf.String(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestLogger_Err_nil(t *testing.T) {
+	l := NewLogger(NewCLIHandler(&bytes.Buffer{}))
+	if got := l.Err(nil); got != l {
+		t.Error("want the same logger for a nil error")
+	}
+}
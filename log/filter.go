@@ -0,0 +1,192 @@
+package log
+
+import (
+	"context"
+	"log/slog"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+var _ slog.Handler = (*FilterHandler)(nil)
+
+// FilterFunc reports whether a record should be passed to the inner handler.
+type FilterFunc func(ctx context.Context, r slog.Record) bool
+
+// FilterHandler is a slog.Handler that drops records failing a predicate.
+// The predicate sees the attributes and groups added via WithAttrs and WithGroup
+// as part of the record, nested the same way the inner handler renders them.
+type FilterHandler struct {
+	inner slog.Handler
+	pred  FilterFunc
+	goas  []groupOrAttrs
+}
+
+// groupOrAttrs is either a group name or a list of attributes added to a FilterHandler.
+type groupOrAttrs struct {
+	group string
+	attrs []slog.Attr
+}
+
+// NewFilterHandler creates a new FilterHandler wrapping inner.
+// A nil predicate passes every record.
+func NewFilterHandler(inner slog.Handler, pred FilterFunc) slog.Handler {
+	if inner == nil {
+		inner = NewCLIHandler(nil)
+	}
+	return &FilterHandler{inner: inner, pred: pred}
+}
+
+// Enabled reports whether the inner handler is enabled for the given level.
+func (h *FilterHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.inner.Enabled(ctx, level)
+}
+
+// Handle passes the record to the inner handler if the predicate accepts it.
+func (h *FilterHandler) Handle(ctx context.Context, r slog.Record) error {
+	if h.pred != nil && !h.pred(ctx, h.view(r)) {
+		return nil
+	}
+	return h.inner.Handle(ctx, r)
+}
+
+// WithAttrs returns a new FilterHandler whose inner handler has the given attributes.
+func (h *FilterHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	return h.with(groupOrAttrs{attrs: slices.Clone(attrs)}, h.inner.WithAttrs(attrs))
+}
+
+// WithGroup returns a new FilterHandler whose inner handler has the given group.
+func (h *FilterHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return h.with(groupOrAttrs{group: name}, h.inner.WithGroup(name))
+}
+
+// Unwrap returns the inner handler.
+func (h *FilterHandler) Unwrap() slog.Handler {
+	return h.inner
+}
+
+// with returns a copy of the handler with goa appended and the given inner handler.
+func (h *FilterHandler) with(goa groupOrAttrs, inner slog.Handler) *FilterHandler {
+	h2 := *h
+	h2.inner = inner
+	h2.goas = append(slices.Clip(h.goas), goa)
+	return &h2
+}
+
+// view returns the record as seen by the predicate, with the attributes and
+// groups of the handler prepended to the record's own attributes.
+func (h *FilterHandler) view(r slog.Record) slog.Record {
+	if len(h.goas) == 0 {
+		return r
+	}
+	attrs := make([]slog.Attr, 0, r.NumAttrs())
+	r.Attrs(func(a slog.Attr) bool {
+		attrs = append(attrs, a)
+		return true
+	})
+	for i := len(h.goas) - 1; i >= 0; i-- {
+		goa := h.goas[i]
+		if goa.group == "" {
+			attrs = append(slices.Clip(goa.attrs), attrs...)
+			continue
+		}
+		attrs = []slog.Attr{{Key: goa.group, Value: slog.GroupValue(attrs...)}}
+	}
+	v := slog.NewRecord(r.Time, r.Level, r.Message, r.PC)
+	v.AddAttrs(attrs...)
+	return v
+}
+
+// MatchMessage returns a FilterFunc that accepts records whose message matches re.
+func MatchMessage(re *regexp.Regexp) FilterFunc {
+	return func(_ context.Context, r slog.Record) bool {
+		return re.MatchString(r.Message)
+	}
+}
+
+// MatchAttr returns a FilterFunc that accepts records having an attribute at the
+// given path for which fn returns true. Groups in the path are separated by dots,
+// e.g. "req.method". A nil fn only checks that the attribute exists.
+func MatchAttr(path string, fn func(slog.Value) bool) FilterFunc {
+	keys := strings.Split(path, ".")
+	return func(_ context.Context, r slog.Record) bool {
+		found := false
+		r.Attrs(func(a slog.Attr) bool {
+			found = matchAttr(a, keys, fn)
+			return !found
+		})
+		return found
+	}
+}
+
+// matchAttr reports whether the attribute at keys below a satisfies fn.
+func matchAttr(a slog.Attr, keys []string, fn func(slog.Value) bool) bool {
+	v := a.Value.Resolve()
+	if a.Key == "" && v.Kind() == slog.KindGroup {
+		for _, ga := range v.Group() {
+			if matchAttr(ga, keys, fn) {
+				return true
+			}
+		}
+		return false
+	}
+	if a.Key != keys[0] {
+		return false
+	}
+	if len(keys) == 1 {
+		return fn == nil || fn(v)
+	}
+	if v.Kind() != slog.KindGroup {
+		return false
+	}
+	for _, ga := range v.Group() {
+		if matchAttr(ga, keys[1:], fn) {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchLevel returns a FilterFunc that accepts records whose level is between min and max inclusive.
+func MatchLevel(min, max slog.Level) FilterFunc {
+	return func(_ context.Context, r slog.Record) bool {
+		return r.Level >= min && r.Level <= max
+	}
+}
+
+// MatchNot returns a FilterFunc that accepts the records rejected by f.
+func MatchNot(f FilterFunc) FilterFunc {
+	return func(ctx context.Context, r slog.Record) bool {
+		return !f(ctx, r)
+	}
+}
+
+// MatchAll returns a FilterFunc that accepts records accepted by every one of fs.
+func MatchAll(fs ...FilterFunc) FilterFunc {
+	return func(ctx context.Context, r slog.Record) bool {
+		for _, f := range fs {
+			if !f(ctx, r) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// MatchAny returns a FilterFunc that accepts records accepted by at least one of fs.
+func MatchAny(fs ...FilterFunc) FilterFunc {
+	return func(ctx context.Context, r slog.Record) bool {
+		for _, f := range fs {
+			if f(ctx, r) {
+				return true
+			}
+		}
+		return false
+	}
+}
@@ -0,0 +1,231 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"regexp"
+	"strings"
+	"testing"
+	"testing/slogtest"
+	"time"
+)
+
+func TestNewFilterHandler(t *testing.T) {
+	inner := NewCLIHandler(io.Discard)
+	h := NewFilterHandler(inner, nil).(*FilterHandler)
+	if h.inner != inner {
+		t.Errorf("inner = %v, want %v", h.inner, inner)
+	}
+	h = NewFilterHandler(nil, nil).(*FilterHandler)
+	if h.inner == nil {
+		t.Error("inner is nil")
+	}
+}
+
+func TestFilterHandler_Enabled(t *testing.T) {
+	h := NewFilterHandler(NewCLIHandler(io.Discard, WithLevel(slog.LevelWarn)), nil)
+	if h.Enabled(context.Background(), slog.LevelInfo) {
+		t.Error("Enabled(INFO) = true, want false")
+	}
+	if !h.Enabled(context.Background(), slog.LevelWarn) {
+		t.Error("Enabled(WARN) = false, want true")
+	}
+}
+
+func TestFilterHandler_Handle(t *testing.T) {
+	isGET := func(v slog.Value) bool { return v.String() == "GET" }
+	tests := []struct {
+		name   string
+		pred   FilterFunc
+		handle func(slog.Handler) slog.Handler
+		msg    string
+		attrs  []slog.Attr
+		want   string
+	}{
+		{
+			name: "nil predicate",
+			msg:  "msg",
+			want: "[INF] msg\n",
+		},
+		{
+			name: "message match",
+			pred: MatchMessage(regexp.MustCompile(`^req`)),
+			msg:  "request done",
+			want: "[INF] request done\n",
+		},
+		{
+			name: "message mismatch",
+			pred: MatchMessage(regexp.MustCompile(`^req`)),
+			msg:  "health check",
+		},
+		{
+			name:  "record attr",
+			pred:  MatchAttr("method", isGET),
+			msg:   "msg",
+			attrs: []slog.Attr{slog.String("method", "GET")},
+			want:  "[INF] msg method=GET\n",
+		},
+		{
+			name:  "record attr mismatch",
+			pred:  MatchAttr("method", isGET),
+			msg:   "msg",
+			attrs: []slog.Attr{slog.String("method", "POST")},
+		},
+		{
+			name:  "attr exists",
+			pred:  MatchAttr("method", nil),
+			msg:   "msg",
+			attrs: []slog.Attr{slog.String("method", "POST")},
+			want:  "[INF] msg method=POST\n",
+		},
+		{
+			name:  "nested group attr",
+			pred:  MatchAttr("req.method", isGET),
+			msg:   "msg",
+			attrs: []slog.Attr{slog.Group("req", slog.String("method", "GET"))},
+			want:  "[INF] msg req.method=GET\n",
+		},
+		{
+			name: "handler attrs",
+			pred: MatchAttr("method", isGET),
+			handle: func(h slog.Handler) slog.Handler {
+				return h.WithAttrs([]slog.Attr{slog.String("method", "GET")})
+			},
+			msg:  "msg",
+			want: "[INF] msg method=GET\n",
+		},
+		{
+			name: "handler group",
+			pred: MatchAttr("req.method", isGET),
+			handle: func(h slog.Handler) slog.Handler {
+				return h.WithGroup("req")
+			},
+			msg:   "msg",
+			attrs: []slog.Attr{slog.String("method", "GET")},
+			want:  "[INF] msg req.method=GET\n",
+		},
+		{
+			name: "handler group excludes outer path",
+			pred: MatchAttr("method", isGET),
+			handle: func(h slog.Handler) slog.Handler {
+				return h.WithGroup("req")
+			},
+			msg:   "msg",
+			attrs: []slog.Attr{slog.String("method", "GET")},
+		},
+		{
+			name: "handler group and attrs",
+			pred: MatchAll(MatchAttr("req.id", nil), MatchAttr("req.method", isGET)),
+			handle: func(h slog.Handler) slog.Handler {
+				return h.WithGroup("req").WithAttrs([]slog.Attr{slog.Int("id", 1)})
+			},
+			msg:   "msg",
+			attrs: []slog.Attr{slog.String("method", "GET")},
+			want:  "[INF] msg req.id=1 req.method=GET\n",
+		},
+		{
+			name: "level",
+			pred: MatchLevel(slog.LevelWarn, slog.LevelError),
+			msg:  "msg",
+		},
+		{
+			name: "not",
+			pred: MatchNot(MatchMessage(regexp.MustCompile(`^health`))),
+			msg:  "health check",
+		},
+		{
+			name: "any",
+			pred: MatchAny(MatchLevel(slog.LevelError, slog.LevelError), MatchMessage(regexp.MustCompile(`^msg`))),
+			msg:  "msg",
+			want: "[INF] msg\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			h := NewFilterHandler(NewCLIHandler(buf, WithStyle(Style0())), tt.pred)
+			if tt.handle != nil {
+				h = tt.handle(h)
+			}
+			r := slog.NewRecord(time.Time{}, slog.LevelInfo, tt.msg, 0)
+			r.AddAttrs(tt.attrs...)
+			if err := h.Handle(context.Background(), r); err != nil {
+				t.Fatal(err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchAttr(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+		attr slog.Attr
+		want bool
+	}{
+		{name: "key", path: "a", attr: slog.Int("a", 1), want: true},
+		{name: "other key", path: "b", attr: slog.Int("a", 1), want: false},
+		{name: "group", path: "g.a", attr: slog.Group("g", slog.Int("a", 1)), want: true},
+		{name: "group not leaf", path: "g.a.b", attr: slog.Group("g", slog.Int("a", 1)), want: false},
+		{name: "inline group", path: "a", attr: slog.Group("", slog.Int("a", 1)), want: true},
+		{name: "log valuer", path: "g.a", attr: slog.Any("g", testGroupValuer{}), want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := slog.NewRecord(time.Time{}, slog.LevelInfo, "msg", 0)
+			r.AddAttrs(tt.attr)
+			if got := MatchAttr(tt.path, nil)(context.Background(), r); got != tt.want {
+				t.Errorf("MatchAttr(%q) = %v, want %v", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+// testGroupValuer is a slog.LogValuer resolving to a group.
+type testGroupValuer struct{}
+
+func (testGroupValuer) LogValue() slog.Value {
+	return slog.GroupValue(slog.Int("a", 1))
+}
+
+func TestFilterHandler_WithAttrs(t *testing.T) {
+	h := NewFilterHandler(NewCLIHandler(io.Discard), nil)
+	if got := h.WithAttrs(nil); got != h {
+		t.Error("want same handler instance for empty attrs")
+	}
+	h1 := h.WithAttrs([]slog.Attr{slog.String("a", "1")}).(*FilterHandler)
+	h2 := h1.WithAttrs([]slog.Attr{slog.String("b", "2")}).(*FilterHandler)
+	h3 := h1.WithAttrs([]slog.Attr{slog.String("c", "3")}).(*FilterHandler)
+	if len(h1.goas) != 1 || len(h2.goas) != 2 || len(h3.goas) != 2 {
+		t.Fatalf("goas = %d, %d, %d, want 1, 2, 2", len(h1.goas), len(h2.goas), len(h3.goas))
+	}
+	if got := h2.goas[1].attrs[0].Key; got != "b" {
+		t.Errorf("h2 attr = %q, want %q", got, "b")
+	}
+}
+
+func TestFilterHandler_WithGroup(t *testing.T) {
+	h := NewFilterHandler(NewCLIHandler(io.Discard), nil)
+	if got := h.WithGroup(""); got != h {
+		t.Error("want same handler instance for empty name")
+	}
+	h2 := h.WithGroup("g").(*FilterHandler)
+	if len(h2.goas) != 1 || h2.goas[0].group != "g" {
+		t.Errorf("goas = %v, want group g", h2.goas)
+	}
+}
+
+func TestFilterHandler_slogtest(t *testing.T) {
+	var buf bytes.Buffer
+	slogtest.Run(t, func(*testing.T) slog.Handler {
+		buf.Reset()
+		return NewFilterHandler(NewLogfmtHandler(&buf), MatchLevel(slog.LevelDebug, LevelFatal))
+	}, func(t *testing.T) map[string]any {
+		return parseLogfmt(t, strings.TrimSuffix(buf.String(), "\n"))
+	})
+}
@@ -0,0 +1,117 @@
+package log
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"strings"
+	"sync/atomic"
+)
+
+// Format selects the handler created by New.
+type Format int
+
+const (
+	// FormatCLI creates a CLIHandler, as NewCLIHandler does.
+	FormatCLI Format = iota
+
+	// FormatJSON creates a slog.JSONHandler applying the level, time format and
+	// caller options, indented by WithPretty.
+	FormatJSON
+
+	// FormatLogfmt creates a logfmt handler, as NewLogfmtHandler does.
+	FormatLogfmt
+)
+
+// formatNames maps the names parsed by ParseFormat to formats.
+var formatNames = map[string]Format{
+	"cli":    FormatCLI,
+	"json":   FormatJSON,
+	"logfmt": FormatLogfmt,
+}
+
+// String returns the name of the format as parsed by ParseFormat.
+func (f Format) String() string {
+	switch f {
+	case FormatJSON:
+		return "json"
+	case FormatLogfmt:
+		return "logfmt"
+	default:
+		return "cli"
+	}
+}
+
+// ParseFormat parses a format name, "cli", "json" or "logfmt", case-insensitively,
+// as read from a --log-format flag.
+func ParseFormat(s string) (Format, error) {
+	f, ok := formatNames[strings.ToLower(strings.TrimSpace(s))]
+	if !ok {
+		return FormatCLI, errors.New("want cli, json or logfmt")
+	}
+	return f, nil
+}
+
+// WithFormat returns a CLIHandlerOption that selects the handler created by New.
+// NewCLIHandler and NewLogfmtHandler ignore it.
+func WithFormat(format Format) CLIHandlerOption {
+	return func(c *CLIHandler) {
+		c.format = format
+	}
+}
+
+// New creates a new handler writing to w in the format selected by WithFormat,
+// FormatCLI by default, so that the format can be chosen by a flag:
+//
+//	format, err := log.ParseFormat(*logFormat)
+//	...
+//	h := log.New(os.Stderr, log.WithFormat(format), log.WithLevel(level))
+//
+// The other options are applied to the handler as by its constructor. For
+// FormatJSON, only the level, time format, caller, color mode and WithPretty
+// options are used.
+func New(w io.Writer, opts ...CLIHandlerOption) slog.Handler {
+	switch optionsOf(opts).format {
+	case FormatJSON:
+		return newJSONHandler(w, opts)
+	case FormatLogfmt:
+		return NewLogfmtHandler(w, opts...)
+	default:
+		return NewCLIHandler(w, opts...)
+	}
+}
+
+// optionsOf returns a handler with only the options applied, to read them
+// without creating a CLIHandler.
+func optionsOf(opts []CLIHandlerOption) *CLIHandler {
+	c := &CLIHandler{
+		level: slog.LevelInfo,
+		label: &atomic.Pointer[labelState]{},
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// newJSONHandler returns a slog.JSONHandler applying the level, time layout
+// and caller of the options.
+func newJSONHandler(w io.Writer, opts []CLIHandlerOption) slog.Handler {
+	c := optionsOf(opts)
+	ho := &slog.HandlerOptions{Level: c.level, AddSource: c.hasCaller}
+	if layout := c.timeLayout; layout != "" {
+		ho.ReplaceAttr = func(groups []string, a slog.Attr) slog.Attr {
+			if len(groups) == 0 && a.Key == slog.TimeKey && a.Value.Kind() == slog.KindTime {
+				return slog.String(a.Key, a.Value.Time().Format(layout))
+			}
+			return a
+		}
+	}
+	if w == nil {
+		w = io.Discard
+	}
+	if c.hasPretty {
+		w = newPrettyWriter(w, c.colorMode)
+	}
+	return slog.NewJSONHandler(w, ho)
+}
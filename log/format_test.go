@@ -0,0 +1,91 @@
+package log
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestParseFormat(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Format
+		wantErr bool
+	}{
+		{in: "cli", want: FormatCLI},
+		{in: "JSON", want: FormatJSON},
+		{in: " logfmt ", want: FormatLogfmt},
+		{in: "", wantErr: true},
+		{in: "xml", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseFormat(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormat_String(t *testing.T) {
+	for _, f := range []Format{FormatCLI, FormatJSON, FormatLogfmt} {
+		got, err := ParseFormat(f.String())
+		if err != nil || got != f {
+			t.Errorf("ParseFormat(%q) = %v, %v, want %v", f.String(), got, err, f)
+		}
+	}
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []CLIHandlerOption
+		want string
+	}{
+		{
+			name: "default",
+			opts: []CLIHandlerOption{WithStyle(Style0())},
+			want: "[WRN] app msg k=1\n",
+		},
+		{
+			name: "cli",
+			opts: []CLIHandlerOption{WithFormat(FormatCLI), WithStyle(Style0())},
+			want: "[WRN] app msg k=1\n",
+		},
+		{
+			name: "json",
+			opts: []CLIHandlerOption{WithFormat(FormatJSON), WithTimeFormat("2006")},
+			want: `","level":"WARN","msg":"msg","k":1}` + "\n",
+		},
+		{
+			name: "logfmt",
+			opts: []CLIHandlerOption{WithFormat(FormatLogfmt)},
+			want: " level=WARN msg=msg label=app k=1\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			opts := append([]CLIHandlerOption{WithLevel(slog.LevelWarn), WithLabel("app")}, tt.opts...)
+			l := NewLogger(New(buf, opts...))
+			l.Info("hidden")
+			l.Warn("msg", "k", 1)
+			if got := buf.String(); !strings.HasSuffix(got, tt.want) || strings.Contains(got, "hidden") {
+				t.Errorf("got %q, want suffix %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewCLIHandler_format(t *testing.T) {
+	buf := &bytes.Buffer{}
+	NewLogger(NewCLIHandler(buf, WithStyle(Style0()), WithFormat(FormatJSON))).Info("msg")
+	if got := buf.String(); got != "[INF] msg\n" {
+		t.Errorf("got %q, want %q", got, "[INF] msg\n")
+	}
+}
@@ -2,23 +2,47 @@ package log
 
 import (
 	"bytes"
+	"cmp"
 	"context"
+	"encoding/base64"
+	"encoding/hex"
 	"errors"
+	"fmt"
 	"io"
 	"log/slog"
 	"os"
 	"path/filepath"
+	"reflect"
 	"runtime"
+	"slices"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/mattn/go-colorable"
+	"github.com/mattn/go-isatty"
 	"github.com/mattn/go-runewidth"
 )
 
-var _ slog.Handler = (*CLIHandler)(nil)
+var (
+	_ slog.Handler = (*CLIHandler)(nil)
+	_ LevelSetter  = (*CLIHandler)(nil)
+	_ LabelSetter  = (*CLIHandler)(nil)
+	_ Flusher      = (*CLIHandler)(nil)
+	_ Closer       = (*CLIHandler)(nil)
+)
+
+const (
+	// ModuleKey is the attr key consulted by WithLevelOverrides.
+	ModuleKey = "module"
+
+	// CauseKey is the attr key of wrapped errors written by WithErrorUnwrap.
+	CauseKey = "cause"
+)
 
 // bufPool is a pool of bytes.Buffers for log message construction.
 var bufPool = &sync.Pool{
@@ -27,37 +51,195 @@ var bufPool = &sync.Pool{
 	},
 }
 
+// handleState is the scratch state of a Handle call. It is pooled alongside
+// the buffers so that rendering a record does not allocate.
+type handleState struct {
+	groups []string
+	nested []slog.Attr
+	attrs  []slog.Attr
+	dedup  []slog.Attr
+	index  map[string]int
+	lines  []lineAttr
+	key    []byte
+	cont   bytes.Buffer
+}
+
+// maxStateSize is the capacity above which a grown handleState is not pooled,
+// so that a single large record does not pin its memory.
+const maxStateSize = 1024
+
+// statePool is a pool of handleStates.
+var statePool = &sync.Pool{
+	New: func() any {
+		return &handleState{groups: make([]string, 0, 16), index: make(map[string]int)}
+	},
+}
+
+// newHandleState returns a handleState from the pool.
+func newHandleState() *handleState {
+	return statePool.Get().(*handleState)
+}
+
+// free clears the state and returns it to the pool.
+func (s *handleState) free() {
+	if cap(s.nested) > maxStateSize || cap(s.attrs) > maxStateSize || cap(s.dedup) > maxStateSize ||
+		len(s.index) > maxStateSize || cap(s.lines) > maxStateSize || s.cont.Cap() > maxStateSize {
+		return
+	}
+	clear(s.groups[:cap(s.groups)])
+	clear(s.nested)
+	clear(s.attrs)
+	clear(s.dedup)
+	clear(s.index)
+	clear(s.lines)
+	s.groups = s.groups[:0]
+	s.nested = s.nested[:0]
+	s.attrs = s.attrs[:0]
+	s.dedup = s.dedup[:0]
+	s.lines = s.lines[:0]
+	s.key = s.key[:0]
+	s.cont.Reset()
+	statePool.Put(s)
+}
+
+// TimePlacement selects where CLIHandler writes the record time.
+type TimePlacement int
+
+const (
+	// TimeAsAttr writes the time as the first attribute after the message.
+	TimeAsAttr TimePlacement = iota
+
+	// TimeBeforeLevel writes the time at the start of the line.
+	TimeBeforeLevel
+
+	// TimeAfterLevel writes the time between the level and the message.
+	TimeAfterLevel
+)
+
 // CLIHandler is a slog.Handler for colored CLI output.
 type CLIHandler struct {
-	w           io.Writer
-	mu          *sync.Mutex
-	level       slog.Leveler
-	prefix      string
-	attrs       []slog.Attr
-	attrsCache  []byte
-	attrHandler func(a slog.Attr) slog.Attr
-	groups      []string
-	groupsCache []string
-	pcCache     map[uintptr][]byte
-	hasCaller   bool
-	hasTime     bool
-	timeLayout  string
-	style       *Style
+	w            io.Writer
+	levelWriters []levelWriter
+	closer       io.Closer
+	closed       *atomic.Bool
+	broken       *atomic.Bool
+	counts       *levelCounts
+	captured     *errorCapture
+	mu           *sync.Mutex
+	hasParallel  bool
+	level        slog.Leveler
+	levelRef     *atomic.Pointer[slog.Leveler]
+	overrides    map[string]slog.Leveler
+	override     slog.Leveler
+	label        *atomic.Pointer[labelState]
+	levelHeaders map[slog.Level][]byte
+	attrs        []slog.Attr
+	attrsCache   []byte
+	attrHandler  func(groups []string, a slog.Attr) slog.Attr
+	groups       []string
+	groupsPrefix string
+	pcCache      *callerCache
+	hasCaller    bool
+	hasTime      bool
+	timeLayout   string
+	style        *Style
+	colorMode    ColorMode
+	colorProfile ColorProfile
+	background   Background
+	format       Format
+	hasStack     bool
+	stackLevel   slog.Level
+	hasTrace     bool
+	redactor     *Redactor
+	hasUnwrap    bool
+	hasMultiline bool
+	width        int
+	wrapIndent   string
+	term         *os.File
+	liveSteps    bool
+	lastStep     *stepLine
+	callerSkip   int
+	callerTrim   []string
+	callerLink   string
+	hasLinks     bool
+	replaceAttr  func(groups []string, a slog.Attr) slog.Attr
+	timeKey      string
+	messageKey   string
+	timePlace    TimePlacement
+	zeroTime     ZeroTimePolicy
+	emptyMsg     string
+	hasUTC       bool
+	hasRelative  bool
+	start        time.Time
+	clock        func() time.Time
+	hasFixed     bool
+	fixedRoot    string
+	keyWidth     *atomic.Int64
+	hasDedup     bool
+	attrSort     AttrSort
+	maxValueLen  int
+	maxAttrs     int
+	hasPretty    bool
+	formatOnly   bool
+	errHandler   func(err error)
+	fallback     io.Writer
+	hooks        []func(ctx context.Context, r slog.Record)
+	recHandlers  []func(ctx context.Context, r *slog.Record) error
+	anyFormat    AnyFormat
+	anyDepth     int
+	anySize      int
+}
+
+// levelWriter is a writer receiving the records from a level up.
+type levelWriter struct {
+	level  slog.Level
+	w      io.Writer
+	closer io.Closer
+	broken *atomic.Bool
 }
 
 // NewCLIHandler creates a new CLIHandler with the given options.
 func NewCLIHandler(w io.Writer, opts ...CLIHandlerOption) slog.Handler {
 	h := &CLIHandler{
 		w:          setColorable(w),
+		closer:     closerOf(w),
+		closed:     &atomic.Bool{},
+		broken:     &atomic.Bool{},
+		counts:     &levelCounts{},
 		mu:         &sync.Mutex{},
 		level:      slog.LevelInfo,
+		levelRef:   &atomic.Pointer[slog.Leveler]{},
+		label:      &atomic.Pointer[labelState]{},
 		timeLayout: time.RFC3339,
 		style:      Style1(),
-		pcCache:    make(map[uintptr][]byte),
+		pcCache:    newCallerCache(callerCacheSize),
+		wrapIndent: defaultWrapIndent,
+		term:       terminal(w),
+		lastStep:   &stepLine{},
 	}
 	for _, opt := range opts {
 		opt(h)
 	}
+	h.format = FormatCLI
+	h.liveSteps = h.term != nil && len(h.levelWriters) == 0
+	if h.hasRelative && h.clock != nil {
+		h.start = h.clock()
+	}
+	if h.style.Attr.Width == AttrWidthAuto {
+		h.keyWidth = &atomic.Int64{}
+	}
+	h.hasLinks = linksEnabled(w, h.colorMode)
+	if !colorEnabled(w, h.colorMode) {
+		h.style = h.style.mapColors(func(*Color) *Color { return nil })
+	} else {
+		h.style = h.style.adapt(sync.OnceValue(func() Background {
+			return background(w, h.background)
+		}))
+		if p := colorProfile(w, h.colorProfile); p < ProfileTrueColor {
+			h.style = h.style.Downgrade(p)
+		}
+	}
+	h.buildHeaders()
 	return h
 }
 
@@ -73,10 +255,29 @@ func WithLevel(level slog.Leveler) CLIHandlerOption {
 	}
 }
 
-// WithLabel returns a CLIHandlerOption that sets the prefix.
-func WithLabel(prefix string) CLIHandlerOption {
+// WithLevelOverrides returns a CLIHandlerOption that sets per-module minimum levels.
+// Keys are matched against the value of the ModuleKey attr and against group names;
+// a match takes precedence over the handler level.
+func WithLevelOverrides(overrides map[string]slog.Leveler) CLIHandlerOption {
+	return func(c *CLIHandler) {
+		if len(overrides) == 0 {
+			c.overrides = nil
+			return
+		}
+		c.overrides = make(map[string]slog.Leveler, len(overrides))
+		for k, v := range overrides {
+			if v != nil {
+				c.overrides[k] = v
+			}
+		}
+	}
+}
+
+// WithLabel returns a CLIHandlerOption that sets the label written after the
+// level, e.g. the name of the application. See also SetLabel and Label.
+func WithLabel(label string) CLIHandlerOption {
 	return func(c *CLIHandler) {
-		c.prefix = prefix
+		c.label.Store(&labelState{text: label})
 	}
 }
 
@@ -87,6 +288,36 @@ func WithCaller(has bool) CLIHandlerOption {
 	}
 }
 
+// WithCallerSkip returns a CLIHandlerOption that reports the caller n frames above
+// the source of each record. The frames are looked up on the stack of the logging
+// goroutine, so the skip has no effect when records are handled asynchronously.
+func WithCallerSkip(n int) CLIHandlerOption {
+	return func(c *CLIHandler) {
+		c.callerSkip = max(0, n)
+	}
+}
+
+// WithCallerTrimPrefix returns a CLIHandlerOption that removes the first matching
+// prefix from package paths and full file paths in the caller source.
+func WithCallerTrimPrefix(prefixes ...string) CLIHandlerOption {
+	return func(c *CLIHandler) {
+		c.callerTrim = slices.DeleteFunc(slices.Clone(prefixes), func(s string) bool { return s == "" })
+	}
+}
+
+// WithCallerLink returns a CLIHandlerOption that writes the caller as an OSC 8
+// hyperlink to the URL of a template, such as CallerLinkFile or
+// CallerLinkVSCode, in which {path}, {line} and {func} are replaced with the
+// absolute file path, the line and the function of the caller. Links are only
+// written along with colors to terminals that support them, as reported by
+// DetectHyperlinks; the caller is written as plain text otherwise. An empty
+// template disables the link.
+func WithCallerLink(template string) CLIHandlerOption {
+	return func(c *CLIHandler) {
+		c.callerLink = template
+	}
+}
+
 // WithTime returns a CLIHandlerOption that enables time information.
 func WithTime(has bool) CLIHandlerOption {
 	return func(c *CLIHandler) {
@@ -103,8 +334,72 @@ func WithTimeFormat(layout string) CLIHandlerOption {
 	}
 }
 
+// WithTimeKey returns a CLIHandlerOption that sets the key of the time attribute.
+// The default is slog.TimeKey.
+func WithTimeKey(key string) CLIHandlerOption {
+	return func(c *CLIHandler) {
+		c.timeKey = key
+	}
+}
+
+// WithMessageKey returns a CLIHandlerOption that sets the key of the message in
+// logfmt output. The default is slog.MessageKey.
+func WithMessageKey(key string) CLIHandlerOption {
+	return func(c *CLIHandler) {
+		c.messageKey = key
+	}
+}
+
+// WithTimePlacement returns a CLIHandlerOption that sets where the time is written.
+func WithTimePlacement(placement TimePlacement) CLIHandlerOption {
+	return func(c *CLIHandler) {
+		c.timePlace = placement
+	}
+}
+
+// WithUTC returns a CLIHandlerOption that converts the record time to UTC.
+func WithUTC(utc bool) CLIHandlerOption {
+	return func(c *CLIHandler) {
+		c.hasUTC = utc
+	}
+}
+
+// WithRelativeTime returns a CLIHandlerOption that writes the time elapsed since
+// the handler was created, e.g. +0.532s, instead of the wall-clock time.
+func WithRelativeTime(relative bool) CLIHandlerOption {
+	return func(c *CLIHandler) {
+		c.hasRelative = relative
+		if relative {
+			c.start = time.Now()
+		}
+	}
+}
+
+// WithFixedCaller returns a CLIHandlerOption that writes the files of the caller
+// and of stack traces without machine-specific directories: paths under the working
+// directory are made relative to it and other paths are reduced to the file name.
+func WithFixedCaller(fixed bool) CLIHandlerOption {
+	return func(c *CLIHandler) {
+		c.hasFixed = fixed
+		c.fixedRoot = ""
+		if fixed {
+			c.fixedRoot, _ = os.Getwd()
+		}
+	}
+}
+
 // WithAttrHandler returns a CLIHandlerOption that sets the attribute handler function.
+// It is called for every attribute, and for the members of groups after the group.
 func WithAttrHandler(fn func(a slog.Attr) slog.Attr) CLIHandlerOption {
+	return WithGroupAttrHandler(ignoreGroups(fn))
+}
+
+// WithGroupAttrHandler returns a CLIHandlerOption that sets an attribute handler
+// function that also receives the groups the attribute belongs to, outermost
+// first, so that e.g. auth.password and db.password can be told apart. It is
+// called for every attribute, and for the members of groups after the group. The
+// groups must not be retained.
+func WithGroupAttrHandler(fn func(groups []string, a slog.Attr) slog.Attr) CLIHandlerOption {
 	return func(c *CLIHandler) {
 		if fn != nil {
 			c.attrHandler = fn
@@ -112,6 +407,35 @@ func WithAttrHandler(fn func(a slog.Attr) slog.Attr) CLIHandlerOption {
 	}
 }
 
+// ignoreGroups adapts an attribute handler function to the signature taking the
+// groups, or returns nil if fn is nil.
+func ignoreGroups(fn func(a slog.Attr) slog.Attr) func(groups []string, a slog.Attr) slog.Attr {
+	if fn == nil {
+		return nil
+	}
+	return func(_ []string, a slog.Attr) slog.Attr { return fn(a) }
+}
+
+// WithReplaceAttr returns a CLIHandlerOption that rewrites attributes with the
+// semantics of slog.HandlerOptions.ReplaceAttr. The function is called for every
+// non-group attribute with the groups it belongs to, and for the built-in time,
+// level, message and source attributes and the trace_id and span_id attributes of
+// WithTraceCorrelation with nil groups. An attribute whose key is replaced by the
+// empty string is removed.
+func WithReplaceAttr(fn func(groups []string, a slog.Attr) slog.Attr) CLIHandlerOption {
+	return func(c *CLIHandler) {
+		c.replaceAttr = fn
+	}
+}
+
+// WithRedactor returns a CLIHandlerOption that masks sensitive attributes and
+// message text with the given Redactor.
+func WithRedactor(r *Redactor) CLIHandlerOption {
+	return func(c *CLIHandler) {
+		c.redactor = r
+	}
+}
+
 // WithStyle returns a CLIHandlerOption that sets the logging style.
 func WithStyle(s *Style) CLIHandlerOption {
 	return func(c *CLIHandler) {
@@ -121,289 +445,1465 @@ func WithStyle(s *Style) CLIHandlerOption {
 	}
 }
 
-// Enabled reports whether the handler is enabled for the given level.
-func (h *CLIHandler) Enabled(_ context.Context, level slog.Level) bool {
-	if h.level == nil {
-		return true
+// WithStackTrace returns a CLIHandlerOption that appends a stack trace to records
+// at or above the given level. The trace is taken from the stack of the logging
+// goroutine, so it is omitted when records are handled asynchronously.
+func WithStackTrace(level slog.Level) CLIHandlerOption {
+	return func(c *CLIHandler) {
+		c.hasStack = true
+		c.stackLevel = level
 	}
-	return level >= h.level.Level()
 }
 
-// Handle handles a log record.
-func (h *CLIHandler) Handle(_ context.Context, r slog.Record) error {
-	h.mu.Lock()
-	defer h.mu.Unlock()
-
-	level := h.style.Level
-	label := h.style.Label
-	caller := h.style.Caller
-	attr := h.style.Attr
+// WithTraceCorrelation returns a CLIHandlerOption that adds the trace_id and
+// span_id of the OpenTelemetry span carried by the context to each record. They
+// are written before the other attributes and are not qualified by groups, but
+// pass through WithReplaceAttr and the Redactor like them.
+func WithTraceCorrelation(has bool) CLIHandlerOption {
+	return func(c *CLIHandler) {
+		c.hasTrace = has
+	}
+}
 
-	// Determine log level text and color
-	var ls LevelStyle
-	switch {
-	case r.Level == slog.LevelDebug:
-		ls = level[slog.LevelDebug]
-	case r.Level == slog.LevelInfo:
-		ls = level[slog.LevelInfo]
-	case r.Level == slog.LevelWarn:
-		ls = level[slog.LevelWarn]
-	case r.Level >= slog.LevelError:
-		ls = level[slog.LevelError]
-	default:
-		return errors.New("unknown log level")
+// WithDedupAttrs returns a CLIHandlerOption that writes each attribute key once.
+// When a key is supplied several times, e.g. by WithAttrs and again at the call
+// site, the last value wins and is written at the position of the first; groups
+// with the same key are merged.
+func WithDedupAttrs(dedup bool) CLIHandlerOption {
+	return func(c *CLIHandler) {
+		c.hasDedup = dedup
 	}
+}
 
-	// Get buffer from pool for log message construction
-	buf := bufPool.Get().(*bytes.Buffer)
-	defer func() {
-		buf.Reset()
-		bufPool.Put(buf)
-	}()
+// WithErrorUnwrap returns a CLIHandlerOption that writes the chain of wrapped
+// errors after an error attr, e.g. err=... err.cause=....
+func WithErrorUnwrap(has bool) CLIHandlerOption {
+	return func(c *CLIHandler) {
+		c.hasUnwrap = has
+	}
+}
 
-	// Add log level
-	if ls.Text != "" {
-		if ls.Prefix.Text != "" {
-			ls.Prefix.Color.WriteString(buf, ls.Prefix.Text)
-		}
-		if ls.Width > 0 {
-			tmp := bufPool.Get().(*bytes.Buffer)
-			align(tmp, ls.Text, ls.Width)
-			ls.Color.WriteBytes(buf, tmp.Bytes())
-			tmp.Reset()
-			bufPool.Put(tmp)
-		} else {
-			ls.Color.WriteString(buf, ls.Text)
-		}
-		if ls.Suffix.Text != "" {
-			ls.Suffix.Color.WriteString(buf, ls.Suffix.Text)
-		}
-		buf.WriteString(" ")
+// WithMultiline returns a CLIHandlerOption that writes each attribute on its own
+// indented line beneath the message, with keys aligned and multi-line values
+// continued after the style's multiline marker.
+func WithMultiline(has bool) CLIHandlerOption {
+	return func(c *CLIHandler) {
+		c.hasMultiline = has
 	}
+}
 
-	// Add caller
-	if h.hasCaller && r.PC != 0 {
-		if b, ok := h.pcCache[r.PC]; ok {
-			h.writeCaller(buf, b, h.style)
-		} else {
-			if f := runtime.FuncForPC(r.PC); f != nil {
-				file, line := f.FileLine(r.PC)
-				path := file
-				if !caller.Fullpath {
-					path = filepath.Base(file)
-				}
-				if file != "" {
-					b = append(b, path...)
-					b = append(b, ':')
-					b = strconv.AppendInt(b, int64(line), 10)
-					h.pcCache[r.PC] = b
-					h.writeCaller(buf, b, h.style)
-				}
-			}
-		}
+// WithWidth returns a CLIHandlerOption that wraps lines wider than width columns.
+// WidthAuto wraps at the width of the terminal, and zero disables wrapping.
+func WithWidth(width int) CLIHandlerOption {
+	return func(c *CLIHandler) {
+		c.width = width
 	}
+}
 
-	// Add prefix
-	if h.prefix != "" {
-		if label.Prefix.Text != "" {
-			label.Prefix.Color.WriteString(buf, label.Prefix.Text)
-		}
-		if label.Width > 0 {
-			tmp := bufPool.Get().(*bytes.Buffer)
-			align(tmp, h.prefix, label.Width)
-			label.Color.WriteBytes(buf, tmp.Bytes())
-			tmp.Reset()
-			bufPool.Put(tmp)
-		} else {
-			label.Color.WriteString(buf, h.prefix)
-		}
-		if label.Suffix.Text != "" {
-			label.Suffix.Color.WriteString(buf, label.Suffix.Text)
-		}
-		buf.WriteString(" ")
+// WithWrapIndent returns a CLIHandlerOption that sets the hanging indent of wrapped lines.
+func WithWrapIndent(indent string) CLIHandlerOption {
+	return func(c *CLIHandler) {
+		c.wrapIndent = indent
 	}
+}
 
-	// Add message
-	buf.WriteString(r.Message)
+// WithParallelFormat returns a CLIHandlerOption that sets whether records are
+// formatted outside the lock, which is then only held to write them. This lets
+// goroutines logging at the same time format in parallel, at the cost of records
+// being written in the order they finish formatting rather than the order Handle
+// was called, and of the functions given to options such as WithReplaceAttr and
+// WithAttrHandler being called concurrently.
+func WithParallelFormat(parallel bool) CLIHandlerOption {
+	return func(c *CLIHandler) {
+		c.hasParallel = parallel
+	}
+}
 
-	// Add time
-	if h.hasTime {
-		buf.WriteString(" ")
-		attr.KeyColor.WriteString(buf, "time")
-		attr.KeyColor.WriteString(buf, attr.Separator)
-		var b [64]byte
-		attr.ValueColor.WriteBytes(buf, r.Time.AppendFormat(b[:0], h.timeLayout))
+// WithColorMode returns a CLIHandlerOption that sets when colors are emitted.
+func WithColorMode(mode ColorMode) CLIHandlerOption {
+	return func(c *CLIHandler) {
+		c.colorMode = mode
 	}
+}
 
-	// Add attributes
-	var groups []string
-	if h.groupsCache != nil {
-		groups = h.groupsCache[:0]
-	} else {
-		groups = make([]string, 0, len(h.groups))
+// WithColorProfile returns a CLIHandlerOption that sets the color profile used
+// to downgrade style colors. By default the profile is detected for terminal
+// output and colors are kept as-is for other writers.
+func WithColorProfile(profile ColorProfile) CLIHandlerOption {
+	return func(c *CLIHandler) {
+		c.colorProfile = profile
 	}
-	if len(h.groups) > 0 {
-		groups = append(groups, h.groups...)
+}
+
+// WithBackground returns a CLIHandlerOption that sets the terminal background
+// for which the variants of adaptive colors are chosen. By default the
+// background is detected with DetectBackground for terminal output, and dark
+// backgrounds are assumed for other writers.
+func WithBackground(bg Background) CLIHandlerOption {
+	return func(c *CLIHandler) {
+		c.background = bg
 	}
-	if len(h.attrsCache) > 0 {
-		buf.Write(h.attrsCache)
-	} else {
-		for _, attr := range h.attrs {
-			if attr.Key == "" {
-				continue
-			}
-			buf.WriteString(" ")
-			h.writeAttr(buf, attr, groups, h.style, h.timeLayout)
+}
+
+// WithLevelWriter returns a CLIHandlerOption that sends records to other
+// writers by level. A record is written to the writer of the highest level not
+// above its own, or to the handler writer if there is none; for example,
+// {slog.LevelWarn: os.Stderr} writes warnings and errors to stderr and the rest
+// to the handler writer. Colors and the terminal width follow the handler
+// writer, and Flush and Close apply to every writer.
+func WithLevelWriter(writers map[slog.Level]io.Writer) CLIHandlerOption {
+	return func(c *CLIHandler) {
+		c.levelWriters = make([]levelWriter, 0, len(writers))
+		for level, w := range writers {
+			c.levelWriters = append(c.levelWriters, levelWriter{level: level, w: setColorable(w), closer: closerOf(w), broken: &atomic.Bool{}})
 		}
+		slices.SortFunc(c.levelWriters, func(a, b levelWriter) int {
+			return cmp.Compare(a.level, b.level)
+		})
 	}
-	r.Attrs(func(attr slog.Attr) bool {
-		if attr.Key == "" {
-			return true
-		}
-		if h.attrHandler != nil {
-			attr = h.attrHandler(attr)
-		}
-		buf.WriteString(" ")
-		h.writeAttr(buf, attr, groups, h.style, h.timeLayout)
-		return true
-	})
-
-	// Write to output
-	buf.WriteString("\n")
-	_, err := buf.WriteTo(h.w)
-	return err
 }
 
-// WithAttrs returns a new handler with the given attributes.
-func (h *CLIHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
-	if len(attrs) == 0 {
-		return h
-	}
-	h2 := *h
-	a := make([]slog.Attr, 0, len(h.attrs)+len(attrs))
-	if h2.attrHandler == nil {
-		a = append(a, h.attrs...)
-		a = append(a, attrs...)
-	} else {
-		for _, attr := range h.attrs {
-			a = append(a, h2.attrHandler(attr))
+// WithHook returns a CLIHandlerOption that adds a function called with every
+// record that passes the level checks, before it is written. It can be given
+// more than once; hooks run in order, outside the handler's lock, and a hook
+// that panics does not prevent the record from being written. A hook that
+// retains the record must clone it.
+func WithHook(fn func(ctx context.Context, r slog.Record)) CLIHandlerOption {
+	return func(c *CLIHandler) {
+		if fn != nil {
+			c.hooks = append(c.hooks, fn)
 		}
-		for _, attr := range attrs {
-			a = append(a, h2.attrHandler(attr))
+	}
+}
+
+// ErrDropRecord is returned by a record handler to drop the record without an error.
+var ErrDropRecord = errors.New("drop record")
+
+// WithRecordHandler returns a CLIHandlerOption that adds a function called with
+// every record before the level overrides, the hooks and formatting. It may rewrite
+// the message, change the level or add attributes to the record; a record whose
+// level is lowered below the level of the handler is dropped. It may return
+// ErrDropRecord to drop it; any other error is returned by Handle and the record
+// is not written. It can be given more than once; record handlers run in order.
+func WithRecordHandler(fn func(ctx context.Context, r *slog.Record) error) CLIHandlerOption {
+	return func(c *CLIHandler) {
+		if fn != nil {
+			c.recHandlers = append(c.recHandlers, fn)
 		}
 	}
-	h2.attrs = a
-	buf := bufPool.Get().(*bytes.Buffer)
-	buf.Reset()
-	groups := make([]string, 0, len(h2.groups))
-	if len(h2.groups) > 0 {
-		groups = append(groups, h2.groups...)
+}
+
+// Enabled reports whether the handler is enabled for the given level.
+// When level overrides are configured, records below the handler level are
+// still enabled if some override could accept them; Handle makes the final call.
+func (h *CLIHandler) Enabled(_ context.Context, level slog.Level) bool {
+	if h.broken != nil && h.broken.Load() && len(h.levelWriters) == 0 {
+		return false
 	}
-	for _, attr := range h2.attrs {
-		if attr.Key == "" {
-			continue
-		}
-		buf.WriteString(" ")
-		h2.writeAttr(buf, attr, groups, h2.style, h2.timeLayout)
+	if h.override != nil {
+		return level >= h.override.Level()
 	}
-	if buf.Len() > 0 {
-		h2.attrsCache = make([]byte, buf.Len())
-		copy(h2.attrsCache, buf.Bytes())
-	} else {
-		h2.attrsCache = nil
+	l := h.leveler()
+	if l == nil || level >= l.Level() {
+		return true
 	}
-	buf.Reset()
-	bufPool.Put(buf)
-	if len(h2.groups) > 0 {
-		h2.groupsCache = append([]string(nil), h2.groups...)
-	} else {
-		h2.groupsCache = nil
+	for _, o := range h.overrides {
+		if level >= o.Level() {
+			return true
+		}
 	}
-	return &h2
+	return false
 }
 
-// WithGroup returns a new handler with the given group.
-func (h *CLIHandler) WithGroup(name string) slog.Handler {
-	if name == "" {
-		return h
+// SetLevel changes the minimum level of the handler at runtime. The change is
+// applied atomically and is shared with every handler derived from it by
+// WithAttrs or WithGroup. A nil level is ignored.
+func (h *CLIHandler) SetLevel(level slog.Leveler) {
+	if level == nil {
+		return
 	}
-	h2 := *h
-	h2.groups = make([]string, len(h.groups)+1)
-	copy(h2.groups, h.groups)
-	h2.groups[len(h.groups)] = name
-	h2.attrsCache = nil
-	h2.groupsCache = append([]string(nil), h2.groups...)
-	return &h2
+	if h.levelRef == nil {
+		h.level = level
+		return
+	}
+	h.levelRef.Store(&level)
 }
 
-// writeCaller writes the caller information to buf.
-func (h *CLIHandler) writeCaller(buf *bytes.Buffer, b []byte, style *Style) {
-	c := style.Caller
-	if c.Prefix.Text != "" {
-		c.Prefix.Color.WriteString(buf, c.Prefix.Text)
+// allowed reports whether the record passes the level overrides. The ModuleKey
+// attr of the record is consulted first, then the override resolved from the
+// handler's groups and attrs, and finally the handler level.
+func (h *CLIHandler) allowed(r slog.Record) bool {
+	var o slog.Leveler
+	r.Attrs(func(attr slog.Attr) bool {
+		if attr.Key != ModuleKey {
+			return true
+		}
+		if v, ok := h.overrides[attr.Value.String()]; ok {
+			o = v
+			return false
+		}
+		return true
+	})
+	if o == nil {
+		o = h.override
 	}
-	c.Color.WriteBytes(buf, b)
-	if c.Suffix.Text != "" {
-		c.Suffix.Color.WriteString(buf, c.Suffix.Text)
+	if o == nil {
+		o = h.leveler()
 	}
-	buf.WriteString(" ")
+	return o == nil || r.Level >= o.Level()
 }
 
-// writeAttr writes the attribute to buf, handling groups recursively.
-func (h *CLIHandler) writeAttr(buf *bytes.Buffer, attr slog.Attr, groups []string, style *Style, timeLayout string) {
-	v := attr.Value
-	if groups == nil {
-		groups = make([]string, 0, 8)
-	}
-
-	kc := style.Attr.KeyColor
-	vc := style.Attr.ValueColor
-	sp := style.Attr.Separator
-
-	if v.Kind() == slog.KindGroup {
-		if len(groups) < cap(groups) {
-			groups = groups[:len(groups)+1]
-			groups[len(groups)-1] = attr.Key
-			attrs := v.Group()
-			for i, attr := range attrs {
-				h.writeAttr(buf, attr, groups, style, timeLayout)
-				if i < len(attrs)-1 {
-					buf.WriteString(" ")
-				}
-			}
-			return
+// leveler returns the level set by SetLevel, or the configured level.
+func (h *CLIHandler) leveler() slog.Leveler {
+	if h.levelRef != nil {
+		if p := h.levelRef.Load(); p != nil {
+			return *p
 		}
-		groups := append(groups, attr.Key)
-		attrs := v.Group()
-		for i, attr := range attrs {
-			h.writeAttr(buf, attr, groups, style, timeLayout)
-			if i < len(attrs)-1 {
-				buf.WriteString(" ")
-			}
-		}
-		return
 	}
+	return h.level
+}
 
-	if len(groups) > 0 {
-		for i, key := range groups {
-			kc.WriteString(buf, key)
-			if i < len(groups)-1 {
-				kc.WriteString(buf, ".")
-			}
+// Handle handles a log record.
+func (h *CLIHandler) Handle(ctx context.Context, r slog.Record) error {
+	if len(h.recHandlers) > 0 {
+		var ok bool
+		var err error
+		if r, ok, err = h.handleRecord(ctx, r); !ok {
+			return err
 		}
-		kc.WriteString(buf, ".")
 	}
-	kc.WriteString(buf, attr.Key)
-	kc.WriteString(buf, sp)
-
-	switch v.Kind() {
-	case slog.KindString:
-		s := v.String()
-		if strings.ContainsAny(s, " \t\n") || strings.ContainsAny(s, "\\\"") {
-			vc.WriteString(buf, strconv.Quote(s))
+	if len(h.overrides) > 0 && !h.allowed(r) {
+		return nil
+	}
+	if h.callerSkip > 0 {
+		r.PC = callerPC(r.PC, h.callerSkip)
+	}
+	// The time of the clock is seen by the hooks and the error records too
+	r.Time = h.stampTime(r.Time)
+	h.runHooks(ctx, r)
+	if h.counts != nil {
+		h.counts.add(r.Level)
+	}
+	h.capture(r)
+	if _, broken := h.writer(r.Level); broken != nil && broken.Load() {
+		return nil
+	}
+
+	// Get buffer from pool for log message construction
+	buf := bufPool.Get().(*bytes.Buffer)
+	defer func() {
+		buf.Reset()
+		bufPool.Put(buf)
+	}()
+
+	// Report write failures once the lock is released
+	var failure, err error
+	defer h.reportFailure(&failure)
+
+	// Format before taking the lock in parallel mode
+	if h.hasParallel {
+		if h.closed != nil && h.closed.Load() {
+			return ErrHandlerClosed
+		}
+		step, isStep := h.formatRecord(ctx, buf, r)
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		failure, err = h.write(buf, r.Level, step, isStep)
+		return err
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.closed != nil && h.closed.Load() {
+		return ErrHandlerClosed
+	}
+	step, isStep := h.formatRecord(ctx, buf, r)
+	failure, err = h.write(buf, r.Level, step, isStep)
+	return err
+}
+
+// formatRecord writes the record to buf and returns the step it belongs to.
+// Unless the handler formats in parallel, it must be called with the mutex held.
+func (h *CLIHandler) formatRecord(ctx context.Context, buf *bytes.Buffer, r slog.Record) (stepValue, bool) {
+	if h.format == FormatLogfmt {
+		h.formatLogfmt(ctx, buf, r)
+		return stepValue{}, false
+	}
+	if kind, ok := sectionOf(r); ok {
+		h.writeSection(buf, kind, r.Message)
+		return stepValue{}, false
+	}
+
+	step, isStep := stepOf(r)
+
+	// Determine log level text and color, unless the header is cached
+	header, cached := h.levelHeaders[r.Level]
+	if h.replaceAttr != nil {
+		cached = false
+	}
+	var ls LevelStyle
+	if !cached {
+		ls = h.style.levelStyle(r.Level)
+	}
+	if h.replaceAttr != nil {
+		if a, ok := h.replaceBuiltin(slog.Any(slog.LevelKey, r.Level)); !ok {
+			ls.Icon, ls.Text = "", ""
+		} else if lv, isLevel := a.Value.Any().(slog.Level); isLevel {
+			ls = h.style.levelStyle(lv)
+		} else {
+			ls.Text = a.Value.String()
+		}
+	}
+
+	// Add time before level
+	t := h.recordTime(r.Time)
+	if h.hasTime && h.timePlace == TimeBeforeLevel && !r.Time.IsZero() {
+		h.writeTimestamp(buf, t)
+	}
+
+	// Add log level
+	if cached {
+		buf.Write(header)
+	} else {
+		writeLevel(buf, ls)
+	}
+
+	// Add time after level
+	if h.hasTime && h.timePlace == TimeAfterLevel && !r.Time.IsZero() {
+		h.writeTimestamp(buf, t)
+	}
+
+	// Add caller
+	if h.hasCaller && r.PC != 0 {
+		if b := h.source(r.PC); len(b) > 0 {
+			h.writeCaller(buf, b, h.style)
+		}
+	}
+
+	// Add label
+	if text, ok := labelOf(r); ok {
+		writeLabel(buf, text, h.style.Label)
+	} else if l := h.labelState(); l != nil && l.header != nil {
+		buf.Write(l.header)
+	} else if l != nil {
+		writeLabel(buf, l.text, h.style.Label)
+	}
+
+	// Add step mark
+	if isStep {
+		if m := h.style.stepMark(step.status); m.Text != "" {
+			m.Color.WriteString(buf, m.Text)
+			buf.WriteString(" ")
+		}
+	}
+
+	// Add message, or drop the space left for it
+	msg := ""
+	if a, ok := h.replaceBuiltin(slog.String(slog.MessageKey, r.Message)); ok {
+		msg = h.message(h.redactor.RedactString(a.Value.String()))
+	}
+	if msg != "" {
+		h.style.messageColor(r.Level).WriteString(buf, msg)
+	} else if b := buf.Bytes(); len(b) > 0 && b[len(b)-1] == ' ' {
+		buf.Truncate(len(b) - 1)
+	}
+
+	// Add time as attribute
+	if h.hasTime && h.timePlace == TimeAsAttr && !r.Time.IsZero() {
+		h.writeTime(buf, t)
+	}
+
+	// Add attributes
+	st := newHandleState()
+	defer st.free()
+	if h.hasMultiline {
+		h.writeMultiline(ctx, buf, r, st)
+	} else {
+		h.writeAttrs(ctx, buf, r, st)
+	}
+
+	// Wrap long lines
+	if width := h.lineWidth(); width > 0 {
+		wrap(buf, width, h.wrapIndent)
+	}
+
+	// Write to output
+	buf.WriteString("\n")
+
+	// Add stack trace
+	if h.hasStack && r.Level >= h.stackLevel || forceStack(ctx) {
+		writeStack(buf, r.PC, h.style, h.stackFile)
+	}
+
+	return step, isStep
+}
+
+// write writes the formatted record at level to its writer, as output does. It
+// must be called with the mutex held.
+func (h *CLIHandler) write(buf *bytes.Buffer, level slog.Level, step stepValue, isStep bool) (failure, err error) {
+	if h.closed != nil && h.closed.Load() {
+		return nil, ErrHandlerClosed
+	}
+
+	// Replace the start line of a step with its end line
+	if h.liveSteps {
+		h.updateStep(buf, step, isStep)
+	}
+
+	w, broken := h.writer(level)
+	return h.output(w, broken, buf.Bytes())
+}
+
+// stepLine is the start line of a step written last by a handler and the
+// handlers derived from it.
+type stepLine struct {
+	id   uint64
+	rows int
+}
+
+// updateStep prepends to buf the sequences moving the cursor back over the
+// start line of the step and erasing it, if that line was written last, and
+// remembers the start line of a step. It must be called with the mutex held.
+func (h *CLIHandler) updateStep(buf *bytes.Buffer, v stepValue, ok bool) {
+	last := *h.lastStep
+	*h.lastStep = stepLine{}
+	switch {
+	case !ok:
+	case v.status == StepRunning:
+		width := termWidth(h.term)
+		rows := 0
+		for line := range bytes.Lines(buf.Bytes()) {
+			rows += rowsOf(string(bytes.TrimSuffix(line, []byte("\n"))), width)
+		}
+		*h.lastStep = stepLine{id: v.id, rows: rows}
+	case last.id == v.id && last.rows > 0:
+		tmp := bufPool.Get().(*bytes.Buffer)
+		defer func() {
+			tmp.Reset()
+			bufPool.Put(tmp)
+		}()
+		tmp.WriteString("\x1b[" + strconv.Itoa(last.rows) + "A\r\x1b[J")
+		tmp.Write(buf.Bytes())
+		buf.Reset()
+		buf.Write(tmp.Bytes())
+	}
+}
+
+// writer returns the writer of the records at level, and the flag set once it
+// is found to be a broken pipe.
+func (h *CLIHandler) writer(level slog.Level) (io.Writer, *atomic.Bool) {
+	for i := len(h.levelWriters) - 1; i >= 0; i-- {
+		if level >= h.levelWriters[i].level {
+			return h.levelWriters[i].w, h.levelWriters[i].broken
+		}
+	}
+	return h.w, h.broken
+}
+
+// handleRecord calls the record handlers with a clone of r, so that attributes can
+// be added to it, and returns the result and whether it is written.
+func (h *CLIHandler) handleRecord(ctx context.Context, r slog.Record) (slog.Record, bool, error) {
+	r = r.Clone()
+	for _, fn := range h.recHandlers {
+		if err := fn(ctx, &r); errors.Is(err, ErrDropRecord) {
+			return r, false, nil
+		} else if err != nil {
+			return r, false, err
+		}
+	}
+	// A record whose level was lowered is checked against the level again;
+	// with level overrides, allowed does it.
+	if l := h.leveler(); len(h.overrides) == 0 && l != nil && r.Level < l.Level() {
+		return r, false, nil
+	}
+	return r, true, nil
+}
+
+// runHooks calls the hooks with the record, recovering from their panics.
+func (h *CLIHandler) runHooks(ctx context.Context, r slog.Record) {
+	for _, fn := range h.hooks {
+		func() {
+			defer func() { _ = recover() }()
+			fn(ctx, r)
+		}()
+	}
+}
+
+// Flush flushes the writers if they implement Flusher, as a *bufio.Writer does.
+func (h *CLIHandler) Flush() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.flush()
+}
+
+// Close flushes the writers and closes those implementing io.Closer, except
+// os.Stdout and os.Stderr. Records handled afterwards by the handler or its derived
+// handlers return ErrHandlerClosed. It is safe to call more than once.
+func (h *CLIHandler) Close() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed != nil && h.closed.Swap(true) {
+		return nil
+	}
+	err := h.flush()
+	closers := []io.Closer{h.closer}
+	for _, lw := range h.levelWriters {
+		closers = append(closers, lw.closer)
+	}
+	for i, c := range closers {
+		if c == nil || slices.ContainsFunc(closers[:i], func(p io.Closer) bool { return sameCloser(p, c) }) {
+			continue
+		}
+		err = errors.Join(err, c.Close())
+	}
+	return err
+}
+
+// flush flushes the writers. It must be called with the mutex held.
+func (h *CLIHandler) flush() error {
+	var errs []error
+	for i := -1; i < len(h.levelWriters); i++ {
+		w := h.w
+		if i >= 0 {
+			w = h.levelWriters[i].w
+		}
+		if f, ok := w.(Flusher); ok {
+			errs = append(errs, f.Flush())
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// sameCloser reports whether a and b are the same closer, so that a writer
+// given for several levels is closed once.
+func sameCloser(a, b io.Closer) bool {
+	t := reflect.TypeOf(a)
+	return t == reflect.TypeOf(b) && t.Comparable() && a == b
+}
+
+// WithAttrs returns a new handler with the given attributes.
+func (h *CLIHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	h2 := *h
+	if attrs = h2.withLabel(attrs); len(attrs) == 0 {
+		return &h2
+	}
+	for _, attr := range attrs {
+		if attr.Key != ModuleKey {
+			continue
+		}
+		if o, ok := h.overrides[attr.Value.String()]; ok {
+			h2.override = o
+		}
+	}
+	a := make([]slog.Attr, 0, len(h.attrs)+len(attrs))
+	if h2.attrHandler == nil {
+		a = append(a, h.attrs...)
+		a = append(a, attrs...)
+	} else {
+		// The existing attributes are already qualified by their groups.
+		for _, attr := range h.attrs {
+			a = append(a, h2.handleAttr(nil, attr))
+		}
+		for _, attr := range attrs {
+			a = append(a, h2.handleAttr(h.groups, attr))
+		}
+	}
+	if h2.redactor != nil {
+		for i, attr := range a[len(h.attrs):] {
+			a[len(h.attrs)+i] = h2.redactor.redact(attr, h2.anyFormat != AnyFormatDefault)
+		}
+	}
+	// Qualify the new attributes with the groups open now, so that groups
+	// opened later do not apply to them.
+	if len(h.groups) > 0 {
+		a = append(a[:len(h.attrs)], nestGroups(h.groups, slices.Clone(a[len(h.attrs):])))
+	}
+	h2.attrs = a
+	buf := bufPool.Get().(*bytes.Buffer)
+	buf.Reset()
+	// Keys padded automatically depend on the keys seen when the record is
+	// written, and collected attributes on the record, so they are not cached.
+	if h2.style.Attr.Width != AttrWidthAuto && !h2.collects() {
+		// The attributes are qualified by the groups open when they were added,
+		// so the cache of h stays valid whatever groups are opened later, and
+		// only the new attributes are rendered after it, unless the attribute
+		// handler was applied to the existing ones again.
+		from := 0
+		if h2.attrHandler == nil && len(h.attrsCache) > 0 {
+			buf.Write(h.attrsCache)
+			from = len(h.attrs)
+		}
+		for _, attr := range h2.attrs[from:] {
+			if h2.replaceAttr != nil && !isEmptyAttr(attr) {
+				attr = h2.replace(nil, attr)
+			}
+			h2.writeSpacedAttr(buf, attr, nil, h2.style, h2.timeLayout)
+		}
+	}
+	if buf.Len() > 0 {
+		h2.attrsCache = make([]byte, buf.Len())
+		copy(h2.attrsCache, buf.Bytes())
+	} else {
+		h2.attrsCache = nil
+	}
+	buf.Reset()
+	bufPool.Put(buf)
+	return &h2
+}
+
+// WithGroup returns a new handler with the given group.
+func (h *CLIHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	h2 := *h
+	h2.groups = make([]string, len(h.groups)+1)
+	copy(h2.groups, h.groups)
+	h2.groups[len(h.groups)] = name
+	h2.groupsPrefix = h.groupsPrefix + name + "."
+	if o, ok := h.overrides[name]; ok {
+		h2.override = o
+	}
+	return &h2
+}
+
+// buildHeaders renders the level of each level of the style and the label with
+// their affixes and colors, so that Handle writes them as is. It must be called
+// whenever the style or the label changes.
+func (h *CLIHandler) buildHeaders() {
+	h.levelHeaders = make(map[slog.Level][]byte, len(h.style.Level))
+	for level, ls := range h.style.Level {
+		var b bytes.Buffer
+		writeLevel(&b, ls)
+		h.levelHeaders[level] = b.Bytes()
+	}
+	if l := h.labelState(); l != nil {
+		h.SetLabel(l.text)
+	}
+}
+
+// SetLabel changes the label of the handler at runtime. The change is applied
+// atomically and is shared with every handler derived from it by WithAttrs or
+// WithGroup, except those given their own label by WithAttrs with Label. An
+// empty label removes it.
+func (h *CLIHandler) SetLabel(label string) {
+	l := &labelState{text: label}
+	if label != "" {
+		var b bytes.Buffer
+		writeLabel(&b, label, h.style.Label)
+		l.header = b.Bytes()
+	}
+	if h.label == nil {
+		h.label = &atomic.Pointer[labelState]{}
+	}
+	h.label.Store(l)
+}
+
+// labelState returns the label set by WithLabel or SetLabel, or nil.
+func (h *CLIHandler) labelState() *labelState {
+	if h.label == nil {
+		return nil
+	}
+	return h.label.Load()
+}
+
+// labelText returns the label of the handler.
+func (h *CLIHandler) labelText() string {
+	if l := h.labelState(); l != nil {
+		return l.text
+	}
+	return ""
+}
+
+// writeLevel writes the level text of ls with its affixes and a trailing space
+// to buf, or nothing if ls has no text.
+func writeLevel(buf *bytes.Buffer, ls LevelStyle) {
+	text := ls.label()
+	if text == "" {
+		return
+	}
+	if ls.Prefix.Text != "" {
+		ls.Prefix.Color.WriteString(buf, ls.Prefix.Text)
+	}
+	if ls.Width > 0 {
+		tmp := bufPool.Get().(*bytes.Buffer)
+		align(tmp, fit(text, ls.Width, ls.Truncate), ls.Width, ls.Align)
+		ls.Color.WriteBytes(buf, tmp.Bytes())
+		tmp.Reset()
+		bufPool.Put(tmp)
+	} else {
+		ls.Color.WriteString(buf, text)
+	}
+	if ls.Suffix.Text != "" {
+		ls.Suffix.Color.WriteString(buf, ls.Suffix.Text)
+	}
+	buf.WriteString(" ")
+}
+
+// writeLabel writes the label text with its affixes and a trailing space to
+// buf, or nothing if text is empty.
+func writeLabel(buf *bytes.Buffer, text string, label LabelStyle) {
+	if text == "" {
+		return
+	}
+	if label.Prefix.Text != "" {
+		label.Prefix.Color.WriteString(buf, label.Prefix.Text)
+	}
+	if label.Width > 0 {
+		tmp := bufPool.Get().(*bytes.Buffer)
+		align(tmp, fit(text, label.Width, label.Truncate), label.Width, label.Align)
+		label.Color.WriteBytes(buf, tmp.Bytes())
+		tmp.Reset()
+		bufPool.Put(tmp)
+	} else {
+		label.Color.WriteString(buf, text)
+	}
+	if label.Suffix.Text != "" {
+		label.Suffix.Color.WriteString(buf, label.Suffix.Text)
+	}
+	buf.WriteString(" ")
+}
+
+// nestGroups returns attrs nested in the given groups, outermost first.
+func nestGroups(groups []string, attrs []slog.Attr) slog.Attr {
+	a := slog.Attr{Key: groups[len(groups)-1], Value: slog.GroupValue(attrs...)}
+	for i := len(groups) - 2; i >= 0; i-- {
+		a = slog.Attr{Key: groups[i], Value: slog.GroupValue(a)}
+	}
+	return a
+}
+
+// source returns the file:line of the given program counter, caching the result.
+func (h *CLIHandler) source(pc uintptr) []byte {
+	if b, ok := h.pcCache.get(pc); ok {
+		return b
+	}
+	frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+	if h.replaceAttr != nil {
+		return h.replaceSource(frame)
+	}
+	b := h.formatCaller(frame)
+	if b == nil {
+		return nil
+	}
+	h.pcCache.put(pc, b)
+	return b
+}
+
+// recordTime returns the record time, already taken from the clock by Handle,
+// converted as configured.
+func (h *CLIHandler) recordTime(t time.Time) time.Time {
+	if h.hasUTC {
+		return t.UTC()
+	}
+	return t
+}
+
+// timeAttr returns the time attribute of the record, reporting false if the
+// ReplaceAttr function removes it.
+func (h *CLIHandler) timeAttr(t time.Time) (slog.Attr, bool) {
+	key := h.timeKey
+	if key == "" {
+		key = slog.TimeKey
+	}
+	return h.replaceBuiltin(slog.Time(key, t))
+}
+
+// writeTime writes the time as an attribute to buf.
+func (h *CLIHandler) writeTime(buf *bytes.Buffer, t time.Time) {
+	a, ok := h.timeAttr(t)
+	if !ok {
+		return
+	}
+	attr := h.style.Attr
+	vc := h.style.Time.Color
+	if vc == nil {
+		vc = attr.ValueColor
+	}
+	buf.WriteString(" ")
+	attr.KeyColor.WriteString(buf, a.Key)
+	attr.KeyColor.WriteString(buf, attr.Separator)
+	h.writeTimeValue(buf, a.Value, vc)
+}
+
+// writeTimestamp writes the time without a key, followed by a space, to buf.
+func (h *CLIHandler) writeTimestamp(buf *bytes.Buffer, t time.Time) {
+	a, ok := h.timeAttr(t)
+	if !ok {
+		return
+	}
+	ts := h.style.Time
+	if ts.Prefix.Text != "" {
+		ts.Prefix.Color.WriteString(buf, ts.Prefix.Text)
+	}
+	h.writeTimeValue(buf, a.Value, ts.Color)
+	if ts.Suffix.Text != "" {
+		ts.Suffix.Color.WriteString(buf, ts.Suffix.Text)
+	}
+	buf.WriteString(" ")
+}
+
+// writeTimeValue writes the value of the time attribute to buf.
+func (h *CLIHandler) writeTimeValue(buf *bytes.Buffer, v slog.Value, c *Color) {
+	if v.Kind() == slog.KindTime {
+		var b [64]byte
+		c.WriteBytes(buf, h.appendTime(b[:0], v.Time()))
+		return
+	}
+	writeValue(buf, v, c, h.timeLayout)
+}
+
+// appendTime appends the formatted time, or the time elapsed since the handler
+// was created in relative mode, to b.
+func (h *CLIHandler) appendTime(b []byte, t time.Time) []byte {
+	if !h.hasRelative {
+		return t.AppendFormat(b, h.timeLayout)
+	}
+	d := t.Sub(h.start)
+	if d >= 0 {
+		b = append(b, '+')
+	}
+	b = strconv.AppendFloat(b, d.Seconds(), 'f', 3, 64)
+	return append(b, 's')
+}
+
+// replaceSource returns the caller source of the frame rewritten by the ReplaceAttr function.
+// The result is not cached because the function may not be deterministic.
+func (h *CLIHandler) replaceSource(frame runtime.Frame) []byte {
+	src := &slog.Source{Function: frame.Function, File: frame.File, Line: frame.Line}
+	a, ok := h.replaceBuiltin(slog.Any(slog.SourceKey, src))
+	if !ok {
+		return nil
+	}
+	if src, isSource := a.Value.Any().(*slog.Source); isSource && src != nil {
+		return h.formatCaller(runtime.Frame{Function: src.Function, File: src.File, Line: src.Line})
+	}
+	return []byte(a.Value.String())
+}
+
+// replaceBuiltin applies the ReplaceAttr function to a built-in attribute.
+// It reports false if the attribute is removed.
+func (h *CLIHandler) replaceBuiltin(a slog.Attr) (slog.Attr, bool) {
+	if h.replaceAttr == nil {
+		return a, true
+	}
+	a = h.replaceAttr(nil, a)
+	a.Value = a.Value.Resolve()
+	return a, a.Key != ""
+}
+
+// handleAttr applies the attribute handler to attr under the given groups and,
+// if the result is a group, to its members.
+func (h *CLIHandler) handleAttr(groups []string, attr slog.Attr) slog.Attr {
+	attr = h.attrHandler(groups, attr)
+	v := attr.Value.Resolve()
+	if v.Kind() != slog.KindGroup {
+		return attr
+	}
+	if attr.Key != "" {
+		groups = append(groups[:len(groups):len(groups)], attr.Key)
+	}
+	members := v.Group()
+	out := make([]slog.Attr, len(members))
+	for i, m := range members {
+		out[i] = h.handleAttr(groups, m)
+	}
+	return slog.Attr{Key: attr.Key, Value: slog.GroupValue(out...)}
+}
+
+// replace applies the ReplaceAttr function to attr under the given groups,
+// descending into groups. Groups left without attributes are removed.
+func (h *CLIHandler) replace(groups []string, attr slog.Attr) slog.Attr {
+	v := attr.Value.Resolve()
+	if v.Kind() != slog.KindGroup {
+		attr.Value = v
+		return h.replaceAttr(groups, attr)
+	}
+	if attr.Key != "" {
+		groups = append(groups[:len(groups):len(groups)], attr.Key)
+	}
+	members := v.Group()
+	out := make([]slog.Attr, 0, len(members))
+	for _, m := range members {
+		if m = h.replace(groups, m); !isEmptyAttr(m) {
+			out = append(out, m)
+		}
+	}
+	if len(out) == 0 {
+		return slog.Attr{}
+	}
+	return slog.Attr{Key: attr.Key, Value: slog.GroupValue(out...)}
+}
+
+// isEmptyAttr reports whether attr is written as nothing: it has an empty key and
+// is not a group whose members are inlined.
+func isEmptyAttr(attr slog.Attr) bool {
+	return attr.Key == "" && attr.Value.Resolve().Kind() != slog.KindGroup
+}
+
+// formatCaller returns the caller source of the frame in the format of the caller style.
+func (h *CLIHandler) formatCaller(frame runtime.Frame) []byte {
+	c := h.style.Caller
+	name := ""
+	switch c.Format {
+	case CallerFuncName:
+		name = frame.Function
+		if i := strings.LastIndexByte(name, '/'); i >= 0 {
+			name = name[i+1:]
+		}
+		if i := strings.IndexByte(name, '.'); i >= 0 {
+			name = name[i+1:]
+		}
+	case CallerPackageFunc:
+		name = h.trimCaller(frame.Function)
+	}
+	if name == "" {
+		if frame.File == "" {
+			return nil
+		}
+		name = filepath.Base(frame.File)
+		if c.Fullpath {
+			name = h.callerFile(frame.File)
+		}
+	}
+	b := make([]byte, 0, len(name)+8)
+	b = append(b, name...)
+	if c.Format != CallerFile {
+		b = append(b, ':')
+		b = strconv.AppendInt(b, int64(frame.Line), 10)
+	}
+	if h.callerLink != "" && h.hasLinks && frame.File != "" {
+		b = appendLink(nil, callerURL(h.callerLink, frame.File, frame.Line, frame.Function), b)
+	}
+	return b
+}
+
+// stackFile returns the file of a stack frame, fixed as configured.
+func (h *CLIHandler) stackFile(file string) string {
+	if !h.hasFixed {
+		return file
+	}
+	return h.callerFile(file)
+}
+
+// callerFile returns the full path of a caller file, trimmed or fixed as configured.
+func (h *CLIHandler) callerFile(file string) string {
+	if !h.hasFixed {
+		return h.trimCaller(file)
+	}
+	if h.fixedRoot != "" {
+		if rel, err := filepath.Rel(h.fixedRoot, file); err == nil && filepath.IsLocal(rel) {
+			return filepath.ToSlash(rel)
+		}
+	}
+	return filepath.Base(file)
+}
+
+// trimCaller removes the first matching caller trim prefix from s.
+func (h *CLIHandler) trimCaller(s string) string {
+	for _, prefix := range h.callerTrim {
+		if t, ok := strings.CutPrefix(s, prefix); ok {
+			return t
+		}
+	}
+	return s
+}
+
+// callerPC returns the program counter skip frames above pc on the current stack,
+// or pc itself if it is not found there.
+func callerPC(pc uintptr, skip int) uintptr {
+	if pc == 0 || skip <= 0 {
+		return pc
+	}
+	var pcs [64]uintptr
+	n := runtime.Callers(2, pcs[:])
+	for i, p := range pcs[:n] {
+		if p == pc {
+			if i+skip < n {
+				return pcs[i+skip]
+			}
+			break
+		}
+	}
+	return pc
+}
+
+// writeCaller writes the caller information to buf.
+func (h *CLIHandler) writeCaller(buf *bytes.Buffer, b []byte, style *Style) {
+	c := style.Caller
+	if c.Prefix.Text != "" {
+		c.Prefix.Color.WriteString(buf, c.Prefix.Text)
+	}
+	c.Color.WriteBytes(buf, b)
+	if c.Suffix.Text != "" {
+		c.Suffix.Color.WriteString(buf, c.Suffix.Text)
+	}
+	buf.WriteString(" ")
+}
+
+// writeAttrs writes the handler attributes, the context attributes and the record attributes to buf.
+func (h *CLIHandler) writeAttrs(ctx context.Context, buf *bytes.Buffer, r slog.Record, st *handleState) {
+	groups := append(st.groups[:0], h.groups...)
+	// Top-level attributes open their groups in the pooled slice after the
+	// groups of the handler.
+	root := groups[len(groups):]
+	if h.hasTrace {
+		h.writeTrace(ctx, buf)
+	}
+	collect := h.collects()
+	collected := st.attrs[:0]
+	defer func() { st.attrs = collected }()
+	switch {
+	case collect:
+		for _, attr := range h.attrs {
+			if h.replaceAttr != nil && !isEmptyAttr(attr) {
+				attr = h.replace(nil, attr)
+			}
+			collected = append(collected, attr)
+		}
+	case len(h.attrsCache) > 0:
+		buf.Write(h.attrsCache)
+	default:
+		for _, attr := range h.attrs {
+			if h.replaceAttr != nil && !isEmptyAttr(attr) {
+				attr = h.replace(nil, attr)
+			}
+			h.writeSpacedAttr(buf, attr, root, h.style, h.timeLayout)
+		}
+	}
+	// With group delimiters, deduplication or sorting, the attributes under the
+	// open groups are collected and written as a single nested group.
+	nested := st.nested[:0]
+	deferred := collect || len(groups) > 0 && h.style.Attr.GroupPrefix != ""
+	write := func(attr slog.Attr) bool {
+		if _, ok := stepAttr(attr); ok || isEmptyAttr(attr) {
+			return true
+		}
+		if _, ok := labelAttr(attr); ok {
+			return true
+		}
+		if h.attrHandler != nil {
+			attr = h.handleAttr(groups, attr)
+		}
+		if h.replaceAttr != nil {
+			if attr = h.replace(groups, attr); isEmptyAttr(attr) {
+				return true
+			}
+		}
+		if h.redactor != nil {
+			attr = h.redactor.redact(attr, h.anyFormat != AnyFormatDefault)
+		}
+		if deferred {
+			nested = append(nested, attr)
+			return true
+		}
+		h.writeSpacedAttr(buf, attr, groups, h.style, h.timeLayout)
+		return true
+	}
+	for _, attr := range FromContext(ctx) {
+		write(attr)
+	}
+	r.Attrs(write)
+	st.groups, st.nested = groups, nested
+	if !collect {
+		if len(nested) > 0 {
+			h.writeSpacedAttr(buf, nestGroups(groups, nested), root, h.style, h.timeLayout)
+		}
+		return
+	}
+	if len(nested) > 0 && len(groups) > 0 {
+		collected = append(collected, nestGroups(groups, nested))
+	} else {
+		collected = append(collected, nested...)
+	}
+	if h.hasDedup {
+		st.dedup = dedupAttrs(st.dedup[:0], st.index, collected)
+	} else {
+		st.dedup = inlineGroups(st.dedup[:0], collected)
+	}
+	h.attrSort.sort(st.dedup)
+	cut := false
+	if h.hasLimits() {
+		st.dedup, cut = h.limitAttrs(st.dedup)
+	}
+	for _, attr := range st.dedup {
+		h.writeSpacedAttr(buf, attr, root, h.style, h.timeLayout)
+	}
+	if cut {
+		h.writeSpacedAttr(buf, slog.Bool(TruncatedKey, true), root, h.style, h.timeLayout)
+	}
+}
+
+// collects reports whether the attributes of a record are collected and written
+// once all are known, to deduplicate, order or limit them.
+func (h *CLIHandler) collects() bool {
+	return h.hasDedup || h.attrSort.less != nil || h.hasLimits()
+}
+
+// dedupAttrs appends attrs to dst with each key once: the members of groups with
+// the same key are merged, and other attributes with the same key are replaced by
+// the last one at the position of the first. Groups with an empty key are inlined.
+// The index is cleared and used as scratch space.
+func dedupAttrs(dst []slog.Attr, index map[string]int, attrs []slog.Attr) []slog.Attr {
+	clear(index)
+	for _, a := range attrs {
+		dst = dedupAttr(dst, index, a)
+	}
+	for i, a := range dst {
+		if a.Value.Kind() == slog.KindGroup && hasDupAttrs(index, a.Value.Group()) {
+			dst[i].Value = slog.GroupValue(dedupAttrs(nil, index, a.Value.Group())...)
+		}
+	}
+	return dst
+}
+
+// inlineGroups appends attrs to dst with the members of groups with an empty key
+// in place of the groups, as they are written.
+func inlineGroups(dst, attrs []slog.Attr) []slog.Attr {
+	for _, a := range attrs {
+		if isInlineGroup(a) {
+			dst = inlineGroups(dst, a.Value.Resolve().Group())
+			continue
+		}
+		dst = append(dst, a)
+	}
+	return dst
+}
+
+// isInlineGroup reports whether a is a group with an empty key, whose members
+// are written in its place.
+func isInlineGroup(a slog.Attr) bool {
+	return a.Key == "" && isGroupAttr(a)
+}
+
+// hasDupAttrs reports whether dedupAttrs would change attrs, so that groups
+// without duplicates are kept as they are. The index is cleared and used as
+// scratch space.
+func hasDupAttrs(index map[string]int, attrs []slog.Attr) bool {
+	clear(index)
+	for _, a := range attrs {
+		if _, ok := index[a.Key]; ok || a.Key == "" {
+			return true
+		}
+		index[a.Key] = 0
+	}
+	for _, a := range attrs {
+		if v := a.Value.Resolve(); v.Kind() == slog.KindGroup && hasDupAttrs(index, v.Group()) {
+			return true
+		}
+	}
+	return false
+}
+
+// dedupAttr adds a to dst as dedupAttrs does and returns dst.
+func dedupAttr(dst []slog.Attr, index map[string]int, a slog.Attr) []slog.Attr {
+	a.Value = a.Value.Resolve()
+	isGroup := a.Value.Kind() == slog.KindGroup
+	if a.Key == "" {
+		if isGroup {
+			for _, m := range a.Value.Group() {
+				dst = dedupAttr(dst, index, m)
+			}
+		}
+		return dst
+	}
+	i, ok := index[a.Key]
+	switch {
+	case !ok:
+		index[a.Key] = len(dst)
+		dst = append(dst, a)
+	case isGroup && dst[i].Value.Kind() == slog.KindGroup:
+		members := slices.Concat(dst[i].Value.Group(), a.Value.Group())
+		dst[i].Value = slog.GroupValue(members...)
+	default:
+		dst[i] = a
+	}
+	return dst
+}
+
+// writeSpacedAttr writes a space and the attribute to buf, or nothing if the
+// attribute is not written.
+func (h *CLIHandler) writeSpacedAttr(buf *bytes.Buffer, attr slog.Attr, groups []string, style *Style, timeLayout string) bool {
+	n := buf.Len()
+	buf.WriteString(" ")
+	if !h.writeAttr(buf, attr, groups, style, timeLayout) {
+		buf.Truncate(n)
+		return false
+	}
+	return true
+}
+
+// writeAttr writes the attribute to buf, handling groups recursively, and reports
+// whether anything was written. Attributes with an empty key and groups without
+// attributes are not written, and the members of a group with an empty key are
+// written inline.
+func (h *CLIHandler) writeAttr(buf *bytes.Buffer, attr slog.Attr, groups []string, style *Style, timeLayout string) bool {
+	v := attr.Value.Resolve()
+	if g, ok := h.flattenAny(v); ok {
+		v = g
+	}
+	if v.Kind() == slog.KindGroup {
+		if attr.Key != "" && style.Attr.GroupPrefix != "" {
+			return h.writeGroup(buf, attr.Key, v.Group(), groups, style, timeLayout)
+		}
+		if attr.Key != "" {
+			if len(groups) < cap(groups) {
+				groups = groups[:len(groups)+1]
+				groups[len(groups)-1] = attr.Key
+			} else {
+				groups = append(groups, attr.Key)
+			}
+		}
+		written := false
+		for _, attr := range v.Group() {
+			if written {
+				h.writeSpacedAttr(buf, attr, groups, style, timeLayout)
+			} else {
+				written = h.writeAttr(buf, attr, groups, style, timeLayout)
+			}
+		}
+		return written
+	}
+	if attr.Key == "" {
+		return false
+	}
+
+	start := buf.Len()
+	kc := h.writeKey(buf, attr.Key, groups, style)
+	if style.Attr.Width != 0 {
+		h.padKey(buf, start, style.Attr.Width)
+	}
+	kc.WriteString(buf, style.Attr.Separator)
+	h.writeAttrValue(buf, attr.Key, v, style, timeLayout, nil)
+	if err, ok := errorValue(v); ok && h.hasUnwrap {
+		h.writeCauses(buf, err, append(groups, attr.Key), style, timeLayout)
+	}
+	return true
+}
+
+// writeKey writes the key qualified by the groups to buf and returns its color.
+func (h *CLIHandler) writeKey(buf *bytes.Buffer, key string, groups []string, style *Style) *Color {
+	kc := style.Attr.KeyColor
+	if ks := style.Attr.Keys[key]; ks.KeyColor != nil {
+		kc = ks.KeyColor
+	}
+	if h.format == FormatLogfmt {
+		for _, g := range groups {
+			writeLogfmtKey(buf, g)
+			buf.WriteString(".")
+		}
+		writeLogfmtKey(buf, key)
+		return kc
+	}
+	for _, g := range groups {
+		kc.WriteString(buf, g)
+		kc.WriteString(buf, ".")
+	}
+	kc.WriteString(buf, key)
+	return kc
+}
+
+// writeGroup writes the group members between the group delimiters of the style
+// and reports whether anything was written. A group without members is not written.
+func (h *CLIHandler) writeGroup(buf *bytes.Buffer, key string, members []slog.Attr, groups []string, style *Style, timeLayout string) bool {
+	n := buf.Len()
+	kc := h.writeKey(buf, key, groups, style)
+	kc.WriteString(buf, style.Attr.GroupPrefix)
+	sep := style.Attr.GroupSeparator
+	if sep == "" {
+		sep = " "
+	}
+	written := false
+	for _, m := range members {
+		mn := buf.Len()
+		if written {
+			buf.WriteString(sep)
+		}
+		if h.writeAttr(buf, m, groups[len(groups):], style, timeLayout) {
+			written = true
 		} else {
-			vc.WriteString(buf, s)
+			buf.Truncate(mn)
+		}
+	}
+	if !written {
+		buf.Truncate(n)
+		return false
+	}
+	kc.WriteString(buf, style.Attr.GroupSuffix)
+	return true
+}
+
+// padKey writes spaces to buf so that the key written from start spans the
+// given width, or the widest key so far with AttrWidthAuto. The widest key is
+// shared with derived handlers.
+func (h *CLIHandler) padKey(buf *bytes.Buffer, start int, width int) {
+	n := visibleWidth(buf.Bytes()[start:])
+	if width == AttrWidthAuto {
+		if h.keyWidth == nil {
+			return
+		}
+		for {
+			w := h.keyWidth.Load()
+			if int64(n) <= w {
+				width = int(w)
+				break
+			}
+			if h.keyWidth.CompareAndSwap(w, int64(n)) {
+				width = n
+				break
+			}
+		}
+	}
+	writeSpaces(buf, width-n)
+}
+
+// writeAttrValue writes the resolved value of the attribute with the given key to buf.
+// If cont is not nil, text spanning several lines is written unquoted with cont
+// at the start of every continued line.
+func (h *CLIHandler) writeAttrValue(buf *bytes.Buffer, key string, v slog.Value, style *Style, timeLayout string, cont []byte) {
+	vc := style.Attr.ValueColor
+	ks := style.Attr.Keys[key]
+	err, isErr := errorValue(v)
+	link, isLink := hyperlinkValue(v)
+	if ec := style.Attr.ErrorColor; isErr && ec != nil {
+		vc = ec
+	}
+	if tc := style.Attr.TraceColor; tc != nil && (key == TraceIDKey || key == SpanIDKey) {
+		vc = tc
+	}
+	if ks.ValueColor != nil {
+		vc = ks.ValueColor
+	}
+	if style.Attr.Colorizer != nil {
+		if c := style.Attr.Colorizer(key, v); c != nil {
+			vc = c
 		}
+	}
+	switch {
+	case ks.Format != nil:
+		writeLines(buf, ks.Format(v), vc, cont)
+	case isNilValue(v):
+		if nc := style.Attr.NilColor; nc != nil {
+			vc = nc
+		}
+		vc.WriteString(buf, "<nil>")
+	case isErr:
+		writeLines(buf, err.Error(), vc, cont)
+	case isLink && h.hasLinks:
+		buf.WriteString(linkStart + escapeURL(link.URL, false) + linkEnd)
+		writeText(buf, link.Text, vc)
+		buf.WriteString(linkClose)
+	case v.Kind() == slog.KindString, isStringer(v):
+		writeLines(buf, v.String(), vc, cont)
+	case isBytes(v):
+		writeBytes(buf, v.Any().([]byte), style.Attr.Bytes, vc)
+	case v.Kind() == slog.KindDuration && style.Attr.Duration != DurationDefault:
+		vc.WriteString(buf, formatDuration(v.Duration(), style.Attr.Duration))
+	case style.Attr.NumberFormatter != nil && isNumber(v):
+		writeText(buf, style.Attr.NumberFormatter(v), vc)
+	default:
+		if text, ok := h.formatAny(v); ok {
+			writeText(buf, text, vc)
+			return
+		}
+		writeValue(buf, v, vc, timeLayout)
+	}
+}
+
+// errorValue returns the error held by v, if any.
+func errorValue(v slog.Value) (error, bool) {
+	if v.Kind() != slog.KindAny {
+		return nil, false
+	}
+	err, ok := v.Any().(error)
+	return err, ok
+}
+
+// writeCauses writes the errors wrapped by err as cause attrs under the given groups.
+// Errors joined with errors.Join are written as indexed causes.
+func (h *CLIHandler) writeCauses(buf *bytes.Buffer, err error, groups []string, style *Style, timeLayout string) {
+	switch u := err.(type) {
+	case interface{ Unwrap() error }:
+		if cause := u.Unwrap(); cause != nil {
+			h.writeSpacedAttr(buf, slog.Any(CauseKey, cause), groups, style, timeLayout)
+		}
+	case interface{ Unwrap() []error }:
+		groups = append(groups, CauseKey)
+		for i, cause := range u.Unwrap() {
+			if cause == nil {
+				continue
+			}
+			h.writeSpacedAttr(buf, slog.Any(strconv.Itoa(i), cause), groups, style, timeLayout)
+		}
+	}
+}
+
+// isNilValue reports whether v holds nil or a nil pointer.
+func isNilValue(v slog.Value) bool {
+	if v.Kind() != slog.KindAny {
+		return false
+	}
+	x := v.Any()
+	if x == nil {
+		return true
+	}
+	rv := reflect.ValueOf(x)
+	return rv.Kind() == reflect.Pointer && rv.IsNil()
+}
+
+// isStringer reports whether v holds a fmt.Stringer.
+func isStringer(v slog.Value) bool {
+	if v.Kind() != slog.KindAny {
+		return false
+	}
+	_, ok := v.Any().(fmt.Stringer)
+	return ok
+}
+
+// isBytes reports whether v holds a byte slice.
+func isBytes(v slog.Value) bool {
+	if v.Kind() != slog.KindAny {
+		return false
+	}
+	_, ok := v.Any().([]byte)
+	return ok
+}
+
+// writeBytes writes b to buf in the given format.
+func writeBytes(buf *bytes.Buffer, b []byte, format BytesFormat, vc *Color) {
+	switch format {
+	case BytesHex, BytesBase64:
+		vc.WriteString(buf, encodeBytes(b, format))
+	case BytesLength:
+		var tmp [32]byte
+		vc.WriteBytes(buf, append(strconv.AppendInt(append(tmp[:0], '<'), int64(len(b)), 10), 'B', '>'))
+	default:
+		writeTextBytes(buf, b, vc)
+	}
+}
+
+// encodeBytes returns b as text in the given format, or as is for BytesText
+// and BytesLength.
+func encodeBytes(b []byte, format BytesFormat) string {
+	switch format {
+	case BytesHex:
+		return hex.EncodeToString(b)
+	case BytesBase64:
+		return base64.StdEncoding.EncodeToString(b)
+	default:
+		return string(b)
+	}
+}
+
+// writeValue writes the attribute value to buf, quoting text that would be ambiguous.
+func writeValue(buf *bytes.Buffer, v slog.Value, vc *Color, timeLayout string) {
+	switch v.Kind() {
+	case slog.KindString:
+		writeText(buf, v.String(), vc)
 	case slog.KindInt64:
 		var b [32]byte
 		vc.WriteBytes(buf, strconv.AppendInt(b[:0], v.Int64(), 10))
@@ -421,33 +1921,169 @@ func (h *CLIHandler) writeAttr(buf *bytes.Buffer, attr slog.Attr, groups []strin
 		}
 	case slog.KindTime:
 		var b [64]byte
-		vc.WriteBytes(buf, v.Time().AppendFormat(b[:0], timeLayout))
+		writeTextBytes(buf, v.Time().AppendFormat(b[:0], timeLayout), vc)
 	case slog.KindDuration:
 		vc.WriteString(buf, v.Duration().String())
 	default:
-		vc.WriteString(buf, v.String())
+		writeText(buf, v.String(), vc)
 	}
 }
 
-// align centers the string s in a field of width w using spaces.
-func align(buf *bytes.Buffer, s string, w int) {
-	if w > 0 {
-		c := runewidth.StringWidth(s)
-		p := w - c
-		if p > 0 {
-			lp := p / 2
-			rp := p - lp
-			for range lp {
-				buf.WriteString(" ")
-			}
-			buf.WriteString(s)
-			for range rp {
-				buf.WriteString(" ")
-			}
-			return
+// writeText writes s to buf, quoted if needed.
+func writeText(buf *bytes.Buffer, s string, c *Color) {
+	if needsQuoting(s) {
+		var b [64]byte
+		c.WriteBytes(buf, strconv.AppendQuote(b[:0], s))
+		return
+	}
+	c.WriteString(buf, s)
+}
+
+// writeLines writes s to buf like writeText. If cont is not nil and s spans
+// several lines, the lines are written unquoted, each continued line after cont.
+func writeLines(buf *bytes.Buffer, s string, c *Color, cont []byte) {
+	if cont == nil || !strings.Contains(s, "\n") {
+		writeText(buf, s, c)
+		return
+	}
+	for i, line := range strings.Split(strings.TrimSuffix(s, "\n"), "\n") {
+		if i > 0 {
+			buf.WriteString("\n")
+			buf.Write(cont)
+		}
+		c.WriteString(buf, line)
+	}
+}
+
+// writeTextBytes writes b to buf, quoted if needed.
+func writeTextBytes(buf *bytes.Buffer, b []byte, c *Color) {
+	if needsQuoting(string(b)) {
+		c.WriteBytes(buf, strconv.AppendQuote(nil, string(b)))
+		return
+	}
+	c.WriteBytes(buf, b)
+}
+
+// needsQuoting reports whether s must be quoted to be parsed back unambiguously.
+func needsQuoting(s string) bool {
+	if s == "" {
+		return true
+	}
+	for _, r := range s {
+		if r <= ' ' || r == '=' || r == '"' || r == '\\' || r == utf8.RuneError || !unicode.IsPrint(r) {
+			return true
+		}
+	}
+	return false
+}
+
+// ellipsis marks the text cut by fit.
+const ellipsis = "…"
+
+// fit returns s cut to at most w columns as t selects, or s if it fits.
+// Escape sequences are removed from text that is cut.
+func fit(s string, w int, t Truncation) string {
+	if t == TruncateNone || w <= 0 || VisibleWidth(s) <= w {
+		return s
+	}
+	s = StripANSI(s)
+	n := max(0, w-VisibleWidth(ellipsis))
+	if t == TruncateMiddle {
+		right := n / 2
+		return head(s, n-right) + ellipsis + tail(s, right)
+	}
+	return head(s, n) + ellipsis
+}
+
+// head returns the longest prefix of s at most w columns wide.
+func head(s string, w int) string {
+	n := 0
+	for i, r := range s {
+		if n += runewidth.RuneWidth(r); n > w {
+			return s[:i]
+		}
+	}
+	return s
+}
+
+// tail returns the longest suffix of s at most w columns wide.
+func tail(s string, w int) string {
+	n := 0
+	for i := len(s); i > 0; {
+		r, size := utf8.DecodeLastRuneInString(s[:i])
+		if n += runewidth.RuneWidth(r); n > w {
+			return s[i:]
 		}
+		i -= size
+	}
+	return s
+}
+
+// align writes the string s in a field of width w using spaces, placed as the
+// alignment a selects.
+func align(buf *bytes.Buffer, s string, w int, a Alignment) {
+	p := w - VisibleWidth(s)
+	if p <= 0 {
+		buf.WriteString(s)
+		return
+	}
+	var lp int
+	switch a {
+	case AlignLeft:
+	case AlignRight:
+		lp = p
+	default:
+		lp = p / 2
 	}
+	writeSpaces(buf, lp)
 	buf.WriteString(s)
+	writeSpaces(buf, p-lp)
+}
+
+// colorEnabled reports whether colors should be emitted to w in the given mode.
+// In ColorAuto mode, NO_COLOR disables colors, CLICOLOR_FORCE enables them, and
+// otherwise files are checked for a terminal. Other writers keep colors enabled
+// because they cannot be probed.
+func colorEnabled(w io.Writer, mode ColorMode) bool {
+	switch mode {
+	case ColorAlways:
+		return true
+	case ColorNever:
+		return false
+	}
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	if v := os.Getenv("CLICOLOR_FORCE"); v != "" && v != "0" {
+		return true
+	}
+	if f, ok := w.(*os.File); ok {
+		return isatty.IsTerminal(f.Fd()) || isatty.IsCygwinTerminal(f.Fd())
+	}
+	return w != nil
+}
+
+// colorProfile returns the profile that colors written to w are downgraded to.
+// An explicit profile wins; otherwise files are probed with DetectColorProfile
+// and other writers keep full colors.
+func colorProfile(w io.Writer, profile ColorProfile) ColorProfile {
+	if profile != 0 {
+		return profile
+	}
+	if _, ok := w.(*os.File); ok {
+		return DetectColorProfile()
+	}
+	return ProfileTrueColor
+}
+
+// closerOf returns w as an io.Closer, or nil if w is a standard stream or
+// cannot be closed.
+func closerOf(w io.Writer) io.Closer {
+	if w == nil || w == os.Stdout || w == os.Stderr {
+		return nil
+	}
+	c, _ := w.(io.Closer)
+	return c
 }
 
 // setColorable wraps the given writer with colorable if it's an *os.File.
@@ -3,14 +3,20 @@ package log
 import (
 	"bytes"
 	"context"
+	"errors"
+	"fmt"
 	"io"
 	"log/slog"
 	"os"
 	"reflect"
 	"runtime"
+	"slices"
+	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
+	"testing/slogtest"
 	"time"
 )
 
@@ -30,8 +36,8 @@ func TestNewCLIHandler(t *testing.T) {
 				if h.level != slog.LevelInfo {
 					t.Errorf("level = %v, want %v", h.level, slog.LevelInfo)
 				}
-				if h.prefix != "" {
-					t.Errorf("prefix = %v, want empty", h.prefix)
+				if got := h.labelText(); got != "" {
+					t.Errorf("label = %v, want empty", got)
 				}
 				if h.hasCaller != false {
 					t.Error("hasCaller = true, want false")
@@ -59,13 +65,13 @@ func TestNewCLIHandler(t *testing.T) {
 			},
 		},
 		{
-			name: "with prefix",
+			name: "with label",
 			args: args{opts: []CLIHandlerOption{
 				WithLabel("[APP]"),
 			}},
 			check: func(t *testing.T, h *CLIHandler) {
-				if h.prefix != "[APP]" {
-					t.Errorf("prefix = %v, want [APP]", h.prefix)
+				if got := h.labelText(); got != "[APP]" {
+					t.Errorf("label = %v, want [APP]", got)
 				}
 			},
 		},
@@ -124,6 +130,35 @@ func TestNewCLIHandler(t *testing.T) {
 				}
 			},
 		},
+		{
+			name: "with color mode never",
+			args: args{opts: []CLIHandlerOption{
+				WithStyle(Style1()),
+				WithColorMode(ColorNever),
+			}},
+			check: func(t *testing.T, h *CLIHandler) {
+				if h.colorMode != ColorNever {
+					t.Errorf("colorMode = %v, want %v", h.colorMode, ColorNever)
+				}
+				if h.style.Level[slog.LevelInfo].Color != nil {
+					t.Error("level color should be stripped")
+				}
+				if h.style.Caller.Color != nil {
+					t.Error("caller color should be stripped")
+				}
+			},
+		},
+		{
+			name: "with color mode always",
+			args: args{opts: []CLIHandlerOption{
+				WithColorMode(ColorAlways),
+			}},
+			check: func(t *testing.T, h *CLIHandler) {
+				if !reflect.DeepEqual(h.style, Style1()) {
+					t.Error("style mismatch with Style1")
+				}
+			},
+		},
 		{
 			name: "all options",
 			args: args{opts: []CLIHandlerOption{
@@ -138,8 +173,8 @@ func TestNewCLIHandler(t *testing.T) {
 				if h.level != slog.LevelWarn {
 					t.Error("level mismatch")
 				}
-				if h.prefix != "TEST" {
-					t.Error("prefix mismatch")
+				if h.labelText() != "TEST" {
+					t.Error("label mismatch")
 				}
 				if !h.hasCaller {
 					t.Error("hasCaller mismatch")
@@ -170,13 +205,12 @@ func TestCLIHandler_Enabled(t *testing.T) {
 		w           io.Writer
 		mu          *sync.Mutex
 		level       slog.Leveler
-		prefix      string
+		label       string
 		attrs       []slog.Attr
 		attrsCache  []byte
 		attrHandler func(a slog.Attr) slog.Attr
 		groups      []string
-		groupsCache []string
-		pcCache     map[uintptr][]byte
+		pcCache     *callerCache
 		hasCaller   bool
 		hasTime     bool
 		timeLayout  string
@@ -243,12 +277,11 @@ func TestCLIHandler_Enabled(t *testing.T) {
 				w:           tt.fields.w,
 				mu:          tt.fields.mu,
 				level:       tt.fields.level,
-				prefix:      tt.fields.prefix,
+				label:       newLabel(tt.fields.label),
 				attrs:       tt.fields.attrs,
 				attrsCache:  tt.fields.attrsCache,
-				attrHandler: tt.fields.attrHandler,
+				attrHandler: ignoreGroups(tt.fields.attrHandler),
 				groups:      tt.fields.groups,
-				groupsCache: tt.fields.groupsCache,
 				pcCache:     tt.fields.pcCache,
 				hasCaller:   tt.fields.hasCaller,
 				hasTime:     tt.fields.hasTime,
@@ -262,18 +295,266 @@ func TestCLIHandler_Enabled(t *testing.T) {
 	}
 }
 
+// newLabel returns the label of a CLIHandler built by a struct literal.
+func newLabel(text string) *atomic.Pointer[labelState] {
+	p := &atomic.Pointer[labelState]{}
+	if text != "" {
+		p.Store(&labelState{text: text})
+	}
+	return p
+}
+
+func TestCLIHandler_SetLevel(t *testing.T) {
+	tests := []struct {
+		name  string
+		opts  []CLIHandlerOption
+		level slog.Leveler
+		want  map[slog.Level]bool
+	}{
+		{
+			name:  "lower level",
+			opts:  []CLIHandlerOption{WithLevel(slog.LevelInfo)},
+			level: slog.LevelDebug,
+			want:  map[slog.Level]bool{slog.LevelDebug: true, slog.LevelInfo: true},
+		},
+		{
+			name:  "raise level",
+			opts:  []CLIHandlerOption{WithLevel(slog.LevelInfo)},
+			level: slog.LevelError,
+			want:  map[slog.Level]bool{slog.LevelWarn: false, slog.LevelError: true},
+		},
+		{
+			name:  "nil level ignored",
+			opts:  []CLIHandlerOption{WithLevel(slog.LevelWarn)},
+			level: nil,
+			want:  map[slog.Level]bool{slog.LevelInfo: false, slog.LevelWarn: true},
+		},
+		{
+			name: "level var",
+			opts: []CLIHandlerOption{WithLevel(func() *slog.LevelVar {
+				lv := &slog.LevelVar{}
+				lv.Set(slog.LevelError)
+				return lv
+			}())},
+			level: slog.LevelInfo,
+			want:  map[slog.Level]bool{slog.LevelDebug: false, slog.LevelInfo: true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewCLIHandler(io.Discard, tt.opts...).(*CLIHandler)
+			derived := []slog.Handler{
+				h,
+				h.WithAttrs([]slog.Attr{slog.String("key", "val")}),
+				h.WithGroup("g1"),
+			}
+			h.SetLevel(tt.level)
+			for i, d := range derived {
+				for level, want := range tt.want {
+					if got := d.Enabled(context.Background(), level); got != want {
+						t.Errorf("handler[%d].Enabled(%v) = %v, want %v", i, level, got, want)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestCLIHandler_SetLevel_withoutRef(t *testing.T) {
+	h := &CLIHandler{level: slog.LevelInfo}
+	h.SetLevel(slog.LevelDebug)
+	if !h.Enabled(context.Background(), slog.LevelDebug) {
+		t.Error("Enabled(debug) = false, want true")
+	}
+}
+
+func TestCLIHandler_SetLevel_concurrent(t *testing.T) {
+	h := NewCLIHandler(io.Discard).(*CLIHandler)
+	l := NewLogger(h.WithAttrs([]slog.Attr{slog.String("key", "val")}))
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Go(func() {
+			if i%2 == 0 {
+				h.SetLevel(slog.LevelDebug)
+			} else {
+				h.SetLevel(slog.LevelError)
+			}
+			l.Info("msg")
+		})
+	}
+	wg.Wait()
+}
+
+func TestCLIHandler_buildHeaders(t *testing.T) {
+	levels := []slog.Level{slog.LevelDebug - 4, slog.LevelDebug, slog.LevelInfo, slog.LevelInfo + 2, slog.LevelWarn, slog.LevelError, slog.LevelError + 4}
+	for _, name := range StyleNames() {
+		t.Run(name, func(t *testing.T) {
+			s, _ := GetStyle(name)
+			s.Label.Width = 10
+			cached := &bytes.Buffer{}
+			uncached := &bytes.Buffer{}
+			opts := []CLIHandlerOption{WithStyle(s), WithLevel(slog.LevelDebug - 4), WithLabel("app"), WithColorMode(ColorAlways)}
+			h1 := NewCLIHandler(cached, opts...)
+			h2 := NewCLIHandler(uncached, opts...).(*CLIHandler)
+			h2.levelHeaders = nil
+			h2.label.Store(&labelState{text: "app"})
+			for _, level := range levels {
+				r := slog.NewRecord(time.Time{}, level, "msg", 0)
+				_ = h1.Handle(context.Background(), r)
+				_ = h2.Handle(context.Background(), r)
+			}
+			if cached.String() != uncached.String() {
+				t.Errorf("got %q, want %q", cached.String(), uncached.String())
+			}
+		})
+	}
+}
+
+func TestCLIHandler_parallelFormat(t *testing.T) {
+	buf := &bytes.Buffer{}
+	h := NewCLIHandler(buf,
+		WithParallelFormat(true),
+		WithCaller(true),
+		WithStyle(NewStyle(WithAttrWidth(AttrWidthAuto))),
+	)
+	l := NewLogger(h.WithGroup("g"))
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Go(func() {
+			for j := range 100 {
+				l.Info("msg", "i", i, "j", j)
+			}
+		})
+	}
+	wg.Wait()
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 800 {
+		t.Fatalf("got %d lines, want 800", len(lines))
+	}
+	for _, line := range lines {
+		if !strings.HasPrefix(line, "[INF] <handler_test.go:") || !strings.Contains(line, " msg g.i=") {
+			t.Fatalf("garbled line %q", line)
+		}
+	}
+	if err := Close(h); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.Handle(context.Background(), slog.NewRecord(time.Now(), slog.LevelInfo, "msg", 0)); !errors.Is(err, ErrHandlerClosed) {
+		t.Errorf("err = %v, want %v", err, ErrHandlerClosed)
+	}
+}
+
+func TestCLIHandler_levelOverrides(t *testing.T) {
+	overrides := map[string]slog.Leveler{
+		"db":   slog.LevelDebug,
+		"http": slog.LevelWarn,
+	}
+	tests := []struct {
+		name  string
+		setup func(slog.Handler) slog.Handler
+		level slog.Level
+		attrs []any
+		want  bool
+	}{
+		{
+			name:  "global level applies without module",
+			level: slog.LevelDebug,
+			want:  false,
+		},
+		{
+			name:  "global level passes without module",
+			level: slog.LevelInfo,
+			want:  true,
+		},
+		{
+			name:  "record module lowers level",
+			level: slog.LevelDebug,
+			attrs: []any{ModuleKey, "db"},
+			want:  true,
+		},
+		{
+			name:  "record module raises level",
+			level: slog.LevelInfo,
+			attrs: []any{ModuleKey, "http"},
+			want:  false,
+		},
+		{
+			name:  "unknown record module uses global level",
+			level: slog.LevelDebug,
+			attrs: []any{ModuleKey, "cache"},
+			want:  false,
+		},
+		{
+			name: "handler module attr",
+			setup: func(h slog.Handler) slog.Handler {
+				return h.WithAttrs([]slog.Attr{slog.String(ModuleKey, "db")})
+			},
+			level: slog.LevelDebug,
+			want:  true,
+		},
+		{
+			name: "group name",
+			setup: func(h slog.Handler) slog.Handler {
+				return h.WithGroup("http")
+			},
+			level: slog.LevelInfo,
+			want:  false,
+		},
+		{
+			name: "record module wins over group",
+			setup: func(h slog.Handler) slog.Handler {
+				return h.WithGroup("http")
+			},
+			level: slog.LevelDebug,
+			attrs: []any{ModuleKey, "db"},
+			want:  false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			var h slog.Handler = NewCLIHandler(buf, WithStyle(Style0()), WithLevelOverrides(overrides))
+			if tt.setup != nil {
+				h = tt.setup(h)
+			}
+			NewLogger(h).Log(context.Background(), tt.level, "msg", tt.attrs...)
+			if got := buf.Len() > 0; got != tt.want {
+				t.Errorf("logged = %v, want %v (output %q)", got, tt.want, buf.String())
+			}
+		})
+	}
+}
+
+func TestCLIHandler_Enabled_levelOverrides(t *testing.T) {
+	h := NewCLIHandler(io.Discard, WithLevelOverrides(map[string]slog.Leveler{
+		"db":   slog.LevelDebug,
+		"nil":  nil,
+		"http": slog.LevelError,
+	}))
+	if !h.Enabled(context.Background(), slog.LevelDebug) {
+		t.Error("Enabled(debug) = false, want true when an override accepts debug")
+	}
+	h2 := h.WithGroup("http")
+	if h2.Enabled(context.Background(), slog.LevelWarn) {
+		t.Error("Enabled(warn) = true, want false for http group")
+	}
+	h3 := NewCLIHandler(io.Discard, WithLevelOverrides(nil)).(*CLIHandler)
+	if h3.overrides != nil {
+		t.Error("overrides should be nil")
+	}
+}
+
 func TestCLIHandler_Handle(t *testing.T) {
 	type fields struct {
 		w           io.Writer
 		mu          *sync.Mutex
 		level       slog.Leveler
-		prefix      string
+		label       string
 		attrs       []slog.Attr
 		attrsCache  []byte
 		attrHandler func(a slog.Attr) slog.Attr
 		groups      []string
-		groupsCache []string
-		pcCache     map[uintptr][]byte
+		pcCache     *callerCache
 		hasCaller   bool
 		hasTime     bool
 		timeLayout  string
@@ -347,7 +628,7 @@ func TestCLIHandler_Handle(t *testing.T) {
 			wantErr: false,
 		},
 		{
-			name: "unknown level",
+			name: "custom level falls back to nearest below",
 			fields: fields{
 				w:     &bytes.Buffer{},
 				mu:    &sync.Mutex{},
@@ -358,7 +639,34 @@ func TestCLIHandler_Handle(t *testing.T) {
 				ctx: context.Background(),
 				r:   slog.NewRecord(time.Now(), slog.Level(1), "msg", 0),
 			},
-			wantErr: true,
+			wantErr: false,
+			check: func(t *testing.T, output string) {
+				if output != "[INF] msg\n" {
+					t.Errorf("got %q, want %q", output, "[INF] msg\n")
+				}
+			},
+		},
+		{
+			name: "custom level with own style",
+			fields: fields{
+				w:     &bytes.Buffer{},
+				mu:    &sync.Mutex{},
+				level: slog.LevelInfo,
+				style: NewStyle(WithLevelStyle(map[slog.Level]LevelStyle{
+					slog.Level(-8): {Text: "[TRC]"},
+					slog.Level(12): {Text: "[FTL]"},
+				})),
+			},
+			args: args{
+				ctx: context.Background(),
+				r:   slog.NewRecord(time.Now(), slog.Level(12), "msg", 0),
+			},
+			wantErr: false,
+			check: func(t *testing.T, output string) {
+				if output != "[FTL] msg\n" {
+					t.Errorf("got %q, want %q", output, "[FTL] msg\n")
+				}
+			},
 		},
 		{
 			name: "level formatting",
@@ -392,12 +700,12 @@ func TestCLIHandler_Handle(t *testing.T) {
 			},
 		},
 		{
-			name: "prefix formatting",
+			name: "label formatting",
 			fields: fields{
-				w:      &bytes.Buffer{},
-				mu:     &sync.Mutex{},
-				prefix: "APP",
-				level:  slog.LevelInfo,
+				w:     &bytes.Buffer{},
+				mu:    &sync.Mutex{},
+				label: "APP",
+				level: slog.LevelInfo,
 				style: func() *Style {
 					s := Style0()
 					s.Label.Width = 5
@@ -428,7 +736,7 @@ func TestCLIHandler_Handle(t *testing.T) {
 				mu:        &sync.Mutex{},
 				level:     slog.LevelInfo,
 				hasCaller: true,
-				pcCache:   make(map[uintptr][]byte),
+				pcCache:   newCallerCache(callerCacheSize),
 				style: func() *Style {
 					s := Style0()
 					s.Caller.Fullpath = false
@@ -459,7 +767,7 @@ func TestCLIHandler_Handle(t *testing.T) {
 				mu:        &sync.Mutex{},
 				level:     slog.LevelInfo,
 				hasCaller: true,
-				pcCache:   make(map[uintptr][]byte),
+				pcCache:   newCallerCache(callerCacheSize),
 				style: func() *Style {
 					s := Style0()
 					s.Caller.Fullpath = true
@@ -581,13 +889,13 @@ func TestCLIHandler_Handle(t *testing.T) {
 			},
 		},
 		{
-			name: "prefix simple",
+			name: "label simple",
 			fields: fields{
-				w:      &bytes.Buffer{},
-				mu:     &sync.Mutex{},
-				prefix: "SIMPLE",
-				level:  slog.LevelInfo,
-				style:  Style0(),
+				w:     &bytes.Buffer{},
+				mu:    &sync.Mutex{},
+				label: "SIMPLE",
+				level: slog.LevelInfo,
+				style: Style0(),
 			},
 			args: args{
 				ctx: context.Background(),
@@ -607,9 +915,11 @@ func TestCLIHandler_Handle(t *testing.T) {
 				mu:        &sync.Mutex{},
 				level:     slog.LevelInfo,
 				hasCaller: true,
-				pcCache: map[uintptr][]byte{
-					12345: []byte("cached.go:99"),
-				},
+				pcCache: func() *callerCache {
+					c := newCallerCache(callerCacheSize)
+					c.put(12345, []byte("cached.go:99"))
+					return c
+				}(),
 				style: Style0(),
 			},
 			args: args{
@@ -624,14 +934,13 @@ func TestCLIHandler_Handle(t *testing.T) {
 			},
 		},
 		{
-			name: "with groups and cache",
+			name: "with groups",
 			fields: fields{
-				w:           &bytes.Buffer{},
-				mu:          &sync.Mutex{},
-				level:       slog.LevelInfo,
-				style:       Style0(),
-				groups:      []string{"g1"},
-				groupsCache: []string{"g1"},
+				w:      &bytes.Buffer{},
+				mu:     &sync.Mutex{},
+				level:  slog.LevelInfo,
+				style:  Style0(),
+				groups: []string{"g1"},
 			},
 			args: args{
 				ctx: context.Background(),
@@ -655,12 +964,11 @@ func TestCLIHandler_Handle(t *testing.T) {
 				w:           tt.fields.w,
 				mu:          tt.fields.mu,
 				level:       tt.fields.level,
-				prefix:      tt.fields.prefix,
+				label:       newLabel(tt.fields.label),
 				attrs:       tt.fields.attrs,
 				attrsCache:  tt.fields.attrsCache,
-				attrHandler: tt.fields.attrHandler,
+				attrHandler: ignoreGroups(tt.fields.attrHandler),
 				groups:      tt.fields.groups,
-				groupsCache: tt.fields.groupsCache,
 				pcCache:     tt.fields.pcCache,
 				hasCaller:   tt.fields.hasCaller,
 				hasTime:     tt.fields.hasTime,
@@ -686,13 +994,12 @@ func TestCLIHandler_WithAttrs(t *testing.T) {
 		w           io.Writer
 		mu          *sync.Mutex
 		level       slog.Leveler
-		prefix      string
+		label       string
 		attrs       []slog.Attr
 		attrsCache  []byte
 		attrHandler func(a slog.Attr) slog.Attr
 		groups      []string
-		groupsCache []string
-		pcCache     map[uintptr][]byte
+		pcCache     *callerCache
 		hasCaller   bool
 		hasTime     bool
 		timeLayout  string
@@ -750,8 +1057,8 @@ func TestCLIHandler_WithAttrs(t *testing.T) {
 				if len(h2.attrsCache) == 0 {
 					t.Error("attrsCache expected to be populated")
 				}
-				if h2.groupsCache != nil {
-					t.Error("groupsCache should be nil for empty groups")
+				if len(h2.groups) != 0 {
+					t.Error("groups should be empty")
 				}
 			},
 		},
@@ -826,11 +1133,11 @@ func TestCLIHandler_WithAttrs(t *testing.T) {
 				if !ok {
 					t.Fatal("got not *CLIHandler")
 				}
-				if len(h2.groupsCache) != 1 {
-					t.Errorf("len(groupsCache) = %v, want 1", len(h2.groupsCache))
+				if len(h2.groups) != 1 {
+					t.Errorf("len(groups) = %v, want 1", len(h2.groups))
 				}
-				if h2.groupsCache[0] != "g1" {
-					t.Errorf("groupsCache[0] = %v, want g1", h2.groupsCache[0])
+				if h2.groups[0] != "g1" {
+					t.Errorf("groups[0] = %v, want g1", h2.groups[0])
 				}
 			},
 		},
@@ -879,12 +1186,11 @@ func TestCLIHandler_WithAttrs(t *testing.T) {
 				w:           tt.fields.w,
 				mu:          tt.fields.mu,
 				level:       tt.fields.level,
-				prefix:      tt.fields.prefix,
+				label:       newLabel(tt.fields.label),
 				attrs:       tt.fields.attrs,
 				attrsCache:  tt.fields.attrsCache,
-				attrHandler: tt.fields.attrHandler,
+				attrHandler: ignoreGroups(tt.fields.attrHandler),
 				groups:      tt.fields.groups,
-				groupsCache: tt.fields.groupsCache,
 				pcCache:     tt.fields.pcCache,
 				hasCaller:   tt.fields.hasCaller,
 				hasTime:     tt.fields.hasTime,
@@ -899,18 +1205,84 @@ func TestCLIHandler_WithAttrs(t *testing.T) {
 	}
 }
 
+func TestCLIHandler_WithAttrs_cache(t *testing.T) {
+	tests := []struct {
+		name  string
+		style *Style
+		with  func(h slog.Handler) slog.Handler
+		want  string
+	}{
+		{
+			name: "groups after attrs",
+			with: func(h slog.Handler) slog.Handler {
+				return h.WithAttrs([]slog.Attr{slog.Int("a", 1)}).WithGroup("g").WithGroup("h")
+			},
+			want: "[INF] msg a=1 g.h.k=v\n",
+		},
+		{
+			name: "attrs between groups",
+			with: func(h slog.Handler) slog.Handler {
+				h = h.WithAttrs([]slog.Attr{slog.Int("a", 1)}).WithGroup("g")
+				h = h.WithAttrs([]slog.Attr{slog.Int("b", 2)}).WithAttrs([]slog.Attr{slog.Int("c", 3)}).WithGroup("h")
+				return h.WithAttrs([]slog.Attr{slog.Int("d", 4)})
+			},
+			want: "[INF] msg a=1 g.b=2 g.c=3 g.h.d=4 g.h.k=v\n",
+		},
+		{
+			name:  "group delimiters",
+			style: NewStyle(WithGroupDelimiters("{", "}", "")),
+			with: func(h slog.Handler) slog.Handler {
+				h = h.WithGroup("g").WithAttrs([]slog.Attr{slog.Int("b", 2)})
+				return h.WithGroup("h").WithAttrs([]slog.Attr{slog.Int("d", 4)})
+			},
+			want: "[INF] msg g{b=2} g{h{d=4}} g{h{k=v}}\n",
+		},
+		{
+			name: "attrs rendered as nothing",
+			with: func(h slog.Handler) slog.Handler {
+				h = h.WithAttrs([]slog.Attr{slog.Group("empty")}).WithGroup("g")
+				return h.WithAttrs([]slog.Attr{slog.Int("b", 2)})
+			},
+			want: "[INF] msg g.b=2 g.k=v\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			style := tt.style
+			if style == nil {
+				style = Style0()
+			}
+			buf := &bytes.Buffer{}
+			h := NewCLIHandler(buf, WithStyle(style))
+			NewLogger(tt.with(h)).Info("msg", "k", "v")
+			if got := buf.String(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	// The cache of a handler is kept as the prefix of the caches derived from it.
+	h := NewCLIHandler(io.Discard, WithStyle(Style0())).WithAttrs([]slog.Attr{slog.Int("a", 1)}).(*CLIHandler)
+	h2 := h.WithGroup("g").WithAttrs([]slog.Attr{slog.Int("b", 2)}).(*CLIHandler)
+	if want := " a=1 g.b=2"; string(h2.attrsCache) != want {
+		t.Errorf("attrsCache = %q, want %q", h2.attrsCache, want)
+	}
+	if string(h.attrsCache) != " a=1" {
+		t.Errorf("attrsCache of the parent = %q, want it unchanged", h.attrsCache)
+	}
+}
+
 func TestCLIHandler_WithGroup(t *testing.T) {
 	type fields struct {
 		w           io.Writer
 		mu          *sync.Mutex
 		level       slog.Leveler
-		prefix      string
+		label       string
 		attrs       []slog.Attr
 		attrsCache  []byte
 		attrHandler func(a slog.Attr) slog.Attr
 		groups      []string
-		groupsCache []string
-		pcCache     map[uintptr][]byte
+		pcCache     *callerCache
 		hasCaller   bool
 		hasTime     bool
 		timeLayout  string
@@ -1000,12 +1372,11 @@ func TestCLIHandler_WithGroup(t *testing.T) {
 				w:           tt.fields.w,
 				mu:          tt.fields.mu,
 				level:       tt.fields.level,
-				prefix:      tt.fields.prefix,
+				label:       newLabel(tt.fields.label),
 				attrs:       tt.fields.attrs,
 				attrsCache:  tt.fields.attrsCache,
-				attrHandler: tt.fields.attrHandler,
+				attrHandler: ignoreGroups(tt.fields.attrHandler),
 				groups:      tt.fields.groups,
-				groupsCache: tt.fields.groupsCache,
 				pcCache:     tt.fields.pcCache,
 				hasCaller:   tt.fields.hasCaller,
 				hasTime:     tt.fields.hasTime,
@@ -1027,13 +1398,12 @@ func TestCLIHandler_writeCaller(t *testing.T) {
 		w           io.Writer
 		mu          *sync.Mutex
 		level       slog.Leveler
-		prefix      string
+		label       string
 		attrs       []slog.Attr
 		attrsCache  []byte
 		attrHandler func(a slog.Attr) slog.Attr
 		groups      []string
-		groupsCache []string
-		pcCache     map[uintptr][]byte
+		pcCache     *callerCache
 		hasCaller   bool
 		hasTime     bool
 		timeLayout  string
@@ -1110,12 +1480,11 @@ func TestCLIHandler_writeCaller(t *testing.T) {
 				w:           tt.fields.w,
 				mu:          tt.fields.mu,
 				level:       tt.fields.level,
-				prefix:      tt.fields.prefix,
+				label:       newLabel(tt.fields.label),
 				attrs:       tt.fields.attrs,
 				attrsCache:  tt.fields.attrsCache,
-				attrHandler: tt.fields.attrHandler,
+				attrHandler: ignoreGroups(tt.fields.attrHandler),
 				groups:      tt.fields.groups,
-				groupsCache: tt.fields.groupsCache,
 				pcCache:     tt.fields.pcCache,
 				hasCaller:   tt.fields.hasCaller,
 				hasTime:     tt.fields.hasTime,
@@ -1134,13 +1503,12 @@ func TestCLIHandler_writeAttr(t *testing.T) {
 		w           io.Writer
 		mu          *sync.Mutex
 		level       slog.Leveler
-		prefix      string
+		label       string
 		attrs       []slog.Attr
 		attrsCache  []byte
 		attrHandler func(a slog.Attr) slog.Attr
 		groups      []string
-		groupsCache []string
-		pcCache     map[uintptr][]byte
+		pcCache     *callerCache
 		hasCaller   bool
 		hasTime     bool
 		timeLayout  string
@@ -1179,160 +1547,316 @@ func TestCLIHandler_writeAttr(t *testing.T) {
 			want: "key=\"val 1\"",
 		},
 		{
-			name: "int attr",
+			name: "string attr empty",
 			fields: fields{
 				style: Style0(),
 			},
 			args: args{
-				attr:   slog.Int64("count", 123),
+				attr:   slog.String("key", ""),
 				groups: nil,
 			},
-			want: "count=123",
+			want: "key=\"\"",
 		},
 		{
-			name: "uint attr",
+			name: "string attr with separator",
 			fields: fields{
 				style: Style0(),
 			},
 			args: args{
-				attr:   slog.Uint64("id", 99),
+				attr:   slog.String("key", "a=b"),
 				groups: nil,
 			},
-			want: "id=99",
+			want: "key=\"a=b\"",
 		},
 		{
-			name: "float attr",
+			name: "error attr",
 			fields: fields{
 				style: Style0(),
 			},
 			args: args{
-				attr:   slog.Float64("pi", 3.14159),
+				attr:   slog.Any("err", errors.New("boom")),
 				groups: nil,
 			},
-			want: "pi=3.14159",
+			want: "err=boom",
 		},
 		{
-			name: "bool attr true",
+			name: "error attr with error color",
 			fields: fields{
-				style: Style0(),
+				style: func() *Style {
+					s := Style0()
+					s.Attr.ErrorColor = NewColor(FgRed)
+					return s
+				}(),
 			},
 			args: args{
-				attr:   slog.Bool("active", true),
+				attr:   slog.Any("err", errors.New("boom failed")),
 				groups: nil,
 			},
-			want: "active=true",
+			want: "err=\x1b[31m\"boom failed\"\x1b[0m",
 		},
 		{
-			name: "bool attr false",
+			name: "key style colors",
 			fields: fields{
-				style: Style0(),
+				style: NewStyle(WithAttrKeyStyle(map[string]AttrKeyStyle{
+					"status": {KeyColor: NewColor(FgBlue), ValueColor: NewColor(FgGreen)},
+				})),
 			},
 			args: args{
-				attr:   slog.Bool("active", false),
-				groups: nil,
+				attr:   slog.Int("status", 200),
+				groups: []string{"res"},
 			},
-			want: "active=false",
+			want: "\x1b[34mres\x1b[0m\x1b[34m.\x1b[0m\x1b[34mstatus\x1b[0m\x1b[34m=\x1b[0m\x1b[32m200\x1b[0m",
 		},
 		{
-			name: "duration attr",
+			name: "key style overrides error color",
 			fields: fields{
-				style: Style0(),
+				style: NewStyle(
+					WithAttrStyle(AttrStyle{ErrorColor: NewColor(FgRed), Separator: "="}),
+					WithAttrKeyStyle(map[string]AttrKeyStyle{"err": {ValueColor: NewColor(FgYellow)}}),
+				),
 			},
 			args: args{
-				attr:   slog.Duration("dur", time.Second),
+				attr:   slog.Any("err", errors.New("boom")),
 				groups: nil,
 			},
-			want: "dur=1s",
+			want: "err=\x1b[33mboom\x1b[0m",
 		},
 		{
-			name: "time attr",
+			name: "key style on string error",
 			fields: fields{
-				timeLayout: time.RFC3339,
-				style:      Style0(),
+				style: Style1(),
 			},
 			args: args{
-				attr:   slog.Time("t", time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)),
+				attr:   slog.String("err", "boom"),
 				groups: nil,
 			},
-			want: "t=2023-01-01T00:00:00Z",
+			want: "\x1b[91merr\x1b[0m\x1b[91m=\x1b[0m\x1b[91mboom\x1b[0m",
 		},
 		{
-			name: "any attr (struct)",
+			name: "key style format",
 			fields: fields{
-				style: Style0(),
+				style: NewStyle(WithAttrKeyStyle(map[string]AttrKeyStyle{
+					"duration": {Format: func(v slog.Value) string {
+						return strconv.FormatInt(v.Duration().Milliseconds(), 10) + " ms"
+					}},
+				})),
 			},
 			args: args{
-				attr:   slog.Any("user", struct{ Name string }{Name: "Alice"}),
+				attr:   slog.Duration("duration", 1500*time.Millisecond),
 				groups: nil,
 			},
-			want: "user={Alice}",
+			want: "duration=\"1500 ms\"",
 		},
 		{
-			name: "with groups",
+			name: "value colorizer",
 			fields: fields{
-				style: Style0(),
+				style: NewStyle(WithValueColorizer(func(key string, v slog.Value) *Color {
+					if key == "status" && v.Int64() >= 500 {
+						return NewColor(FgRed)
+					}
+					return nil
+				})),
 			},
 			args: args{
-				attr:   slog.String("key", "val"),
-				groups: []string{"g1", "g2"},
+				attr:   slog.Int("status", 500),
+				groups: nil,
 			},
-			want: "g1.g2.key=val",
+			want: "status=\x1b[31m500\x1b[0m",
 		},
 		{
-			name: "group attr simple",
+			name: "value colorizer returns nil",
 			fields: fields{
-				style: Style0(),
+				style: NewStyle(
+					WithAttrKeyStyle(map[string]AttrKeyStyle{"status": {ValueColor: NewColor(FgGreen)}}),
+					WithValueColorizer(func(string, slog.Value) *Color { return nil }),
+				),
 			},
 			args: args{
-				attr: slog.Group("g1",
-					slog.String("k1", "v1"),
-					slog.Int("k2", 2),
-				),
+				attr:   slog.Int("status", 200),
 				groups: nil,
 			},
-			want: "g1.k1=v1 g1.k2=2",
+			want: "status=\x1b[32m200\x1b[0m",
 		},
 		{
-			name: "group attr recursive",
+			name: "value colorizer overrides error color",
 			fields: fields{
-				style: Style0(),
+				style: NewStyle(
+					WithAttrStyle(AttrStyle{ErrorColor: NewColor(FgRed), Separator: "="}),
+					WithValueColorizer(func(string, slog.Value) *Color { return NewColor(FgMagenta) }),
+				),
 			},
 			args: args{
-				attr: slog.Group("parent",
-					slog.Group("child",
-						slog.String("key", "val"),
-					),
-				),
+				attr:   slog.Any("err", errors.New("boom")),
 				groups: nil,
 			},
-			want: "parent.child.key=val",
+			want: "err=\x1b[35mboom\x1b[0m",
 		},
 		{
-			name: "group with existing groups",
+			name: "key style not matched",
 			fields: fields{
-				style: Style0(),
+				style: NewStyle(WithAttrKeyStyle(map[string]AttrKeyStyle{"other": {ValueColor: NewColor(FgGreen)}})),
 			},
 			args: args{
-				attr: slog.Group("sub",
-					slog.String("k", "v"),
-				),
-				groups: []string{"base"},
+				attr:   slog.Int("status", 200),
+				groups: nil,
 			},
-			want: "base.sub.k=v",
+			want: "status=200",
 		},
 		{
-			name: "group append trigger",
+			name: "int attr",
 			fields: fields{
 				style: Style0(),
 			},
 			args: args{
-				attr:   slog.Group("g", slog.String("k", "v")),
-				groups: make([]string, 0),
+				attr:   slog.Int64("count", 123),
+				groups: nil,
 			},
-			want: "g.k=v",
+			want: "count=123",
 		},
 		{
-			name: "group append trigger multiple attrs",
+			name: "uint attr",
+			fields: fields{
+				style: Style0(),
+			},
+			args: args{
+				attr:   slog.Uint64("id", 99),
+				groups: nil,
+			},
+			want: "id=99",
+		},
+		{
+			name: "float attr",
+			fields: fields{
+				style: Style0(),
+			},
+			args: args{
+				attr:   slog.Float64("pi", 3.14159),
+				groups: nil,
+			},
+			want: "pi=3.14159",
+		},
+		{
+			name: "bool attr true",
+			fields: fields{
+				style: Style0(),
+			},
+			args: args{
+				attr:   slog.Bool("active", true),
+				groups: nil,
+			},
+			want: "active=true",
+		},
+		{
+			name: "bool attr false",
+			fields: fields{
+				style: Style0(),
+			},
+			args: args{
+				attr:   slog.Bool("active", false),
+				groups: nil,
+			},
+			want: "active=false",
+		},
+		{
+			name: "duration attr",
+			fields: fields{
+				style: Style0(),
+			},
+			args: args{
+				attr:   slog.Duration("dur", time.Second),
+				groups: nil,
+			},
+			want: "dur=1s",
+		},
+		{
+			name: "time attr",
+			fields: fields{
+				timeLayout: time.RFC3339,
+				style:      Style0(),
+			},
+			args: args{
+				attr:   slog.Time("t", time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)),
+				groups: nil,
+			},
+			want: "t=2023-01-01T00:00:00Z",
+		},
+		{
+			name: "any attr (struct)",
+			fields: fields{
+				style: Style0(),
+			},
+			args: args{
+				attr:   slog.Any("user", struct{ Name string }{Name: "Alice"}),
+				groups: nil,
+			},
+			want: "user={Alice}",
+		},
+		{
+			name: "with groups",
+			fields: fields{
+				style: Style0(),
+			},
+			args: args{
+				attr:   slog.String("key", "val"),
+				groups: []string{"g1", "g2"},
+			},
+			want: "g1.g2.key=val",
+		},
+		{
+			name: "group attr simple",
+			fields: fields{
+				style: Style0(),
+			},
+			args: args{
+				attr: slog.Group("g1",
+					slog.String("k1", "v1"),
+					slog.Int("k2", 2),
+				),
+				groups: nil,
+			},
+			want: "g1.k1=v1 g1.k2=2",
+		},
+		{
+			name: "group attr recursive",
+			fields: fields{
+				style: Style0(),
+			},
+			args: args{
+				attr: slog.Group("parent",
+					slog.Group("child",
+						slog.String("key", "val"),
+					),
+				),
+				groups: nil,
+			},
+			want: "parent.child.key=val",
+		},
+		{
+			name: "group with existing groups",
+			fields: fields{
+				style: Style0(),
+			},
+			args: args{
+				attr: slog.Group("sub",
+					slog.String("k", "v"),
+				),
+				groups: []string{"base"},
+			},
+			want: "base.sub.k=v",
+		},
+		{
+			name: "group append trigger",
+			fields: fields{
+				style: Style0(),
+			},
+			args: args{
+				attr:   slog.Group("g", slog.String("k", "v")),
+				groups: make([]string, 0),
+			},
+			want: "g.k=v",
+		},
+		{
+			name: "group append trigger multiple attrs",
 			fields: fields{
 				style: Style0(),
 			},
@@ -1360,12 +1884,11 @@ func TestCLIHandler_writeAttr(t *testing.T) {
 				w:           tt.fields.w,
 				mu:          tt.fields.mu,
 				level:       tt.fields.level,
-				prefix:      tt.fields.prefix,
+				label:       newLabel(tt.fields.label),
 				attrs:       tt.fields.attrs,
 				attrsCache:  tt.fields.attrsCache,
-				attrHandler: tt.fields.attrHandler,
+				attrHandler: ignoreGroups(tt.fields.attrHandler),
 				groups:      tt.fields.groups,
-				groupsCache: tt.fields.groupsCache,
 				pcCache:     tt.fields.pcCache,
 				hasCaller:   tt.fields.hasCaller,
 				hasTime:     tt.fields.hasTime,
@@ -1381,10 +1904,37 @@ func TestCLIHandler_writeAttr(t *testing.T) {
 	}
 }
 
+func Test_needsQuoting(t *testing.T) {
+	tests := []struct {
+		name string
+		s    string
+		want bool
+	}{
+		{name: "plain", s: "value", want: false},
+		{name: "unicode", s: "あいう", want: false},
+		{name: "empty", s: "", want: true},
+		{name: "space", s: "a b", want: true},
+		{name: "tab", s: "a\tb", want: true},
+		{name: "equals", s: "a=b", want: true},
+		{name: "quote", s: `a"b`, want: true},
+		{name: "backslash", s: `a\b`, want: true},
+		{name: "control", s: "a\x1bb", want: true},
+		{name: "invalid utf8", s: "a\xffb", want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := needsQuoting(tt.s); got != tt.want {
+				t.Errorf("needsQuoting(%q) = %v, want %v", tt.s, got, tt.want)
+			}
+		})
+	}
+}
+
 func Test_align(t *testing.T) {
 	type args struct {
 		s string
 		w int
+		a Alignment
 	}
 	tests := []struct {
 		name string
@@ -1411,6 +1961,11 @@ func Test_align(t *testing.T) {
 			args: args{s: "foo", w: 5},
 			want: " foo ",
 		},
+		{
+			name: "styled",
+			args: args{s: "\x1b[1mfoo\x1b[0m", w: 5},
+			want: " \x1b[1mfoo\x1b[0m ",
+		},
 		{
 			name: "align center odd",
 			args: args{s: "foo", w: 6},
@@ -1426,11 +1981,31 @@ func Test_align(t *testing.T) {
 			args: args{s: "あ", w: 4},
 			want: " あ ",
 		},
+		{
+			name: "align left",
+			args: args{s: "foo", w: 6, a: AlignLeft},
+			want: "foo   ",
+		},
+		{
+			name: "align right",
+			args: args{s: "foo", w: 6, a: AlignRight},
+			want: "   foo",
+		},
+		{
+			name: "align right wide chars",
+			args: args{s: "あ", w: 4, a: AlignRight},
+			want: "  あ",
+		},
+		{
+			name: "align left over width",
+			args: args{s: "foo", w: 2, a: AlignLeft},
+			want: "foo",
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			buf := &bytes.Buffer{}
-			align(buf, tt.args.s, tt.args.w)
+			align(buf, tt.args.s, tt.args.w, tt.args.a)
 			if got := buf.String(); got != tt.want {
 				t.Errorf("align() = %q, want %q", got, tt.want)
 			}
@@ -1438,6 +2013,128 @@ func Test_align(t *testing.T) {
 	}
 }
 
+func Test_fit(t *testing.T) {
+	tests := []struct {
+		name string
+		s    string
+		w    int
+		t    Truncation
+		want string
+	}{
+		{name: "none", s: "WARNING", w: 5, t: TruncateNone, want: "WARNING"},
+		{name: "fits", s: "WARN", w: 5, t: TruncateRight, want: "WARN"},
+		{name: "no width", s: "WARNING", w: 0, t: TruncateRight, want: "WARNING"},
+		{name: "right", s: "WARNING", w: 5, t: TruncateRight, want: "WARN…"},
+		{name: "middle", s: "WARNING", w: 5, t: TruncateMiddle, want: "WA…NG"},
+		{name: "middle odd", s: "WARNING", w: 4, t: TruncateMiddle, want: "WA…G"},
+		{name: "one column", s: "WARNING", w: 1, t: TruncateMiddle, want: "…"},
+		{name: "wide chars right", s: "あいうえ", w: 6, t: TruncateRight, want: "あい…"},
+		{name: "wide chars middle", s: "あいうえ", w: 6, t: TruncateMiddle, want: "あ…え"},
+		{name: "styled", s: "\x1b[1mWARNING\x1b[0m", w: 5, t: TruncateRight, want: "WARN…"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := fit(tt.s, tt.w, tt.t); got != tt.want {
+				t.Errorf("fit(%q, %d, %v) = %q, want %q", tt.s, tt.w, tt.t, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCLIHandler_Handle_truncate(t *testing.T) {
+	buf := &bytes.Buffer{}
+	style := NewStyle(
+		WithLevelStyle(map[slog.Level]LevelStyle{
+			slog.LevelInfo: {Text: "INFORMATION", Width: 6, Truncate: TruncateRight},
+		}),
+		WithLabelStyle(LabelStyle{Width: 7, Truncate: TruncateMiddle}),
+	)
+	l := NewLogger(NewCLIHandler(buf, WithStyle(style), WithLabel("subcommand")))
+	l.Info("msg")
+	if got, want := buf.String(), "INFOR… sub…and msg\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func Test_colorEnabled(t *testing.T) {
+	type args struct {
+		w    io.Writer
+		mode ColorMode
+	}
+	tests := []struct {
+		name string
+		args args
+		env  map[string]string
+		want bool
+	}{
+		{
+			name: "always",
+			args: args{w: &bytes.Buffer{}, mode: ColorAlways},
+			env:  map[string]string{"NO_COLOR": "1"},
+			want: true,
+		},
+		{
+			name: "never",
+			args: args{w: &bytes.Buffer{}, mode: ColorNever},
+			env:  map[string]string{"CLICOLOR_FORCE": "1"},
+			want: false,
+		},
+		{
+			name: "auto non-file writer",
+			args: args{w: &bytes.Buffer{}, mode: ColorAuto},
+			want: true,
+		},
+		{
+			name: "auto nil writer",
+			args: args{w: nil, mode: ColorAuto},
+			want: false,
+		},
+		{
+			name: "auto no color",
+			args: args{w: &bytes.Buffer{}, mode: ColorAuto},
+			env:  map[string]string{"NO_COLOR": "1"},
+			want: false,
+		},
+		{
+			name: "auto no color wins over force",
+			args: args{w: &bytes.Buffer{}, mode: ColorAuto},
+			env:  map[string]string{"NO_COLOR": "1", "CLICOLOR_FORCE": "1"},
+			want: false,
+		},
+		{
+			name: "auto force on non-terminal file",
+			args: args{w: os.NewFile(0, os.DevNull), mode: ColorAuto},
+			env:  map[string]string{"CLICOLOR_FORCE": "1"},
+			want: true,
+		},
+		{
+			name: "auto force zero",
+			args: args{w: func() io.Writer {
+				f, err := os.CreateTemp(t.TempDir(), "tty")
+				if err != nil {
+					t.Fatal(err)
+				}
+				t.Cleanup(func() { _ = f.Close() })
+				return f
+			}(), mode: ColorAuto},
+			env:  map[string]string{"CLICOLOR_FORCE": "0"},
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("NO_COLOR", "")
+			t.Setenv("CLICOLOR_FORCE", "")
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+			if got := colorEnabled(tt.args.w, tt.args.mode); got != tt.want {
+				t.Errorf("colorEnabled() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func Test_setColorable(t *testing.T) {
 	tests := []struct {
 		name  string
@@ -1482,3 +2179,1377 @@ func Test_setColorable(t *testing.T) {
 		})
 	}
 }
+
+type tokenValuer struct{ id int }
+
+func (v tokenValuer) LogValue() slog.Value {
+	return slog.GroupValue(slog.Int("id", v.id), slog.String("kind", "token"))
+}
+
+func TestCLIHandler_Handle_logValuer(t *testing.T) {
+	buf := &bytes.Buffer{}
+	h := NewCLIHandler(buf, WithStyle(Style0()))
+	r := slog.NewRecord(time.Time{}, slog.LevelInfo, "msg", 0)
+	r.AddAttrs(slog.Any("tok", tokenValuer{id: 7}), slog.Group("g", slog.Any("v", secretValuer("plain"))))
+	if err := h.Handle(t.Context(), r); err != nil {
+		t.Fatal(err)
+	}
+	want := "[INF] msg tok.id=7 tok.kind=token g.v=plain\n"
+	if got := buf.String(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+type nilStringer struct{ s string }
+
+func (v *nilStringer) String() string { return v.s }
+
+func TestCLIHandler_Handle_valueRendering(t *testing.T) {
+	data := []byte("a\x00b")
+	tests := []struct {
+		name string
+		opts []StyleOption
+		val  any
+		want string
+	}{
+		{name: "bytes text", val: []byte("abc"), want: "v=abc"},
+		{name: "bytes text quoted", val: data, want: `v="a\x00b"`},
+		{name: "bytes hex", opts: []StyleOption{WithBytesFormat(BytesHex)}, val: data, want: "v=610062"},
+		{name: "bytes base64", opts: []StyleOption{WithBytesFormat(BytesBase64)}, val: data, want: "v=YQBi"},
+		{name: "bytes length", opts: []StyleOption{WithBytesFormat(BytesLength)}, val: data, want: "v=<3B>"},
+		{name: "nil", val: nil, want: "v=<nil>"},
+		{name: "nil pointer", val: (*nilStringer)(nil), want: "v=<nil>"},
+		{name: "nil error", val: (*os.PathError)(nil), want: "v=<nil>"},
+		{name: "nil color", opts: []StyleOption{WithNilColor(NewColor(Faint))}, val: nil, want: "v=\x1b[2m<nil>\x1b[0m"},
+		{name: "stringer", val: &nilStringer{s: "a b"}, want: `v="a b"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			style := NewStyle(tt.opts...)
+			l := NewLogger(NewCLIHandler(buf, WithStyle(style), WithColorMode(ColorAlways)))
+			l.Info("msg", "v", tt.val)
+			want := "[INF] msg " + tt.want + "\n"
+			if got := buf.String(); got != want {
+				t.Errorf("got %q, want %q", got, want)
+			}
+		})
+	}
+
+	t.Run("multiline stringer", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		l := NewLogger(NewCLIHandler(buf, WithStyle(Style0()), WithMultiline(true)))
+		l.Info("msg", "v", &nilStringer{s: "a\nb"})
+		want := "[INF] msg\n  v=a\n   │b\n"
+		if got := buf.String(); got != want {
+			t.Errorf("got %q, want %q", got, want)
+		}
+	})
+}
+
+func TestCLIHandler_Handle_errorUnwrap(t *testing.T) {
+	base := errors.New("base")
+	wrapped := fmt.Errorf("read: %w", base)
+	tests := []struct {
+		name   string
+		unwrap bool
+		err    error
+		want   string
+	}{
+		{
+			name:   "disabled",
+			unwrap: false,
+			err:    wrapped,
+			want:   "[INF] msg err=\"read: base\"\n",
+		},
+		{
+			name:   "plain error",
+			unwrap: true,
+			err:    base,
+			want:   "[INF] msg err=base\n",
+		},
+		{
+			name:   "chain",
+			unwrap: true,
+			err:    fmt.Errorf("open: %w", wrapped),
+			want:   "[INF] msg err=\"open: read: base\" err.cause=\"read: base\" err.cause.cause=base\n",
+		},
+		{
+			name:   "joined",
+			unwrap: true,
+			err:    errors.Join(wrapped, errors.New("other")),
+			want:   "[INF] msg err=\"read: base\\nother\" err.cause.0=\"read: base\" err.cause.0.cause=base err.cause.1=other\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			h := NewCLIHandler(buf, WithStyle(Style0()), WithErrorUnwrap(tt.unwrap))
+			r := slog.NewRecord(time.Time{}, slog.LevelInfo, "msg", 0)
+			r.AddAttrs(slog.Any("err", tt.err))
+			if err := h.Handle(t.Context(), r); err != nil {
+				t.Fatal(err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCLIHandler_Handle_callerSkip(t *testing.T) {
+	buf := &bytes.Buffer{}
+	l := slog.New(NewCLIHandler(buf, WithStyle(Style0()), WithCaller(true), WithCallerSkip(1)))
+	helper := func() { l.Info("hello") }
+	_, _, line, _ := runtime.Caller(0)
+	helper()
+	want := fmt.Sprintf("[INF] <handler_test.go:%d> hello\n", line+1)
+	if got := buf.String(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func Test_callerPC(t *testing.T) {
+	var pcs [1]uintptr
+	runtime.Callers(1, pcs[:])
+	tests := []struct {
+		name string
+		pc   uintptr
+		skip int
+		want uintptr
+	}{
+		{name: "zero pc", pc: 0, skip: 1, want: 0},
+		{name: "no skip", pc: pcs[0], skip: 0, want: pcs[0]},
+		{name: "not on stack", pc: 1, skip: 1, want: 1},
+		{name: "beyond stack", pc: pcs[0], skip: 1000, want: pcs[0]},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := callerPC(tt.pc, tt.skip); got != tt.want {
+				t.Errorf("callerPC() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCLIHandler_formatCaller(t *testing.T) {
+	frame := runtime.Frame{
+		Function: "github.com/myorg/svc/db.(*Store).Get",
+		File:     "/src/github.com/myorg/svc/db/store.go",
+		Line:     42,
+	}
+	tests := []struct {
+		name   string
+		caller CallerStyle
+		trim   []string
+		frame  runtime.Frame
+		want   string
+	}{
+		{name: "file line", caller: CallerStyle{}, frame: frame, want: "store.go:42"},
+		{name: "full path", caller: CallerStyle{Fullpath: true}, frame: frame, want: "/src/github.com/myorg/svc/db/store.go:42"},
+		{name: "full path trimmed", caller: CallerStyle{Fullpath: true}, trim: []string{"/src/github.com/myorg/"}, frame: frame, want: "svc/db/store.go:42"},
+		{name: "file", caller: CallerStyle{Format: CallerFile}, frame: frame, want: "store.go"},
+		{name: "func name", caller: CallerStyle{Format: CallerFuncName}, frame: frame, want: "(*Store).Get:42"},
+		{name: "package func", caller: CallerStyle{Format: CallerPackageFunc}, frame: frame, want: "github.com/myorg/svc/db.(*Store).Get:42"},
+		{name: "package func trimmed", caller: CallerStyle{Format: CallerPackageFunc}, trim: []string{"example.com/", "github.com/myorg/"}, frame: frame, want: "svc/db.(*Store).Get:42"},
+		{name: "func name main", caller: CallerStyle{Format: CallerFuncName}, frame: runtime.Frame{Function: "main.main", File: "/app/main.go", Line: 7}, want: "main:7"},
+		{name: "no function falls back to file", caller: CallerStyle{Format: CallerFuncName}, frame: runtime.Frame{File: "/app/main.go", Line: 7}, want: "main.go:7"},
+		{name: "empty frame", caller: CallerStyle{}, frame: runtime.Frame{}, want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &CLIHandler{style: NewStyle(WithCallerStyle(tt.caller))}
+			WithCallerTrimPrefix(tt.trim...)(h)
+			if got := string(h.formatCaller(tt.frame)); got != tt.want {
+				t.Errorf("formatCaller() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCLIHandler_Handle_callerFormat(t *testing.T) {
+	buf := &bytes.Buffer{}
+	style := NewStyle(WithCallerStyle(CallerStyle{Format: CallerPackageFunc}))
+	l := slog.New(NewCLIHandler(buf, WithStyle(style), WithCaller(true), WithCallerTrimPrefix("github.com/nekrassov01/logger/")))
+	_, _, line, _ := runtime.Caller(0)
+	l.Info("hello")
+	want := fmt.Sprintf("[INF] log.TestCLIHandler_Handle_callerFormat:%d hello\n", line+1)
+	if got := buf.String(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestCLIHandler_Handle_groupAttrHandler(t *testing.T) {
+	var seen []string
+	mask := func(groups []string, a slog.Attr) slog.Attr {
+		path := strings.Join(append(slices.Clone(groups), a.Key), ".")
+		seen = append(seen, path)
+		if path == "auth.password" || path == "app.auth.password" {
+			return slog.String(a.Key, "***")
+		}
+		return a
+	}
+	tests := []struct {
+		name     string
+		log      func(l *Logger)
+		want     string
+		wantSeen []string
+	}{
+		{
+			name:     "group members",
+			log:      func(l *Logger) { l.Info("msg", slog.Group("auth", "password", "p"), slog.Group("db", "password", "p")) },
+			want:     "[INF] msg auth.password=*** db.password=p\n",
+			wantSeen: []string{"auth", "auth.password", "db", "db.password"},
+		},
+		{
+			name:     "open groups",
+			log:      func(l *Logger) { l.WithGroup("auth").Info("msg", "password", "p") },
+			want:     "[INF] msg auth.password=***\n",
+			wantSeen: []string{"auth.password"},
+		},
+		{
+			name: "handler attrs",
+			log: func(l *Logger) {
+				l.WithGroup("app").With(slog.Group("auth", "password", "p")).Info("msg")
+			},
+			want:     "[INF] msg app.auth.password=***\n",
+			wantSeen: []string{"app.auth", "app.auth.password"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			seen = nil
+			buf := &bytes.Buffer{}
+			tt.log(NewLogger(NewCLIHandler(buf, WithStyle(Style0()), WithGroupAttrHandler(mask))))
+			if got := buf.String(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+			if !slices.Equal(seen, tt.wantSeen) {
+				t.Errorf("seen %q, want %q", seen, tt.wantSeen)
+			}
+		})
+	}
+}
+
+func TestCLIHandler_Handle_attrHandler_group(t *testing.T) {
+	buf := &bytes.Buffer{}
+	l := NewLogger(NewCLIHandler(buf, WithStyle(Style0()), WithAttrHandler(func(a slog.Attr) slog.Attr {
+		if a.Key == "password" {
+			return slog.String(a.Key, "***")
+		}
+		return a
+	})))
+	l.Info("msg", slog.Group("db", "password", "p"))
+	if got, want := buf.String(), "[INF] msg db.password=***\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestCLIHandler_Handle_recordHandler(t *testing.T) {
+	errVeto := errors.New("veto")
+	tests := []struct {
+		name    string
+		fns     []func(ctx context.Context, r *slog.Record) error
+		attrs   []any
+		want    string
+		wantErr error
+	}{
+		{
+			name: "rewrite message",
+			fns: []func(ctx context.Context, r *slog.Record) error{
+				func(_ context.Context, r *slog.Record) error {
+					r.Message = strings.ToUpper(r.Message)
+					return nil
+				},
+			},
+			want: "[INF] MSG k=v\n",
+		},
+		{
+			name: "escalate level and add attr",
+			fns: []func(ctx context.Context, r *slog.Record) error{
+				func(_ context.Context, r *slog.Record) error {
+					r.Attrs(func(a slog.Attr) bool {
+						if a.Key == "k" && a.Value.String() == "v" {
+							r.Level = slog.LevelError
+						}
+						return true
+					})
+					return nil
+				},
+				func(_ context.Context, r *slog.Record) error {
+					r.AddAttrs(slog.Bool("escalated", r.Level == slog.LevelError))
+					return nil
+				},
+			},
+			want: "[ERR] msg k=v escalated=true\n",
+		},
+		{
+			name: "demote below level",
+			fns: []func(ctx context.Context, r *slog.Record) error{
+				func(_ context.Context, r *slog.Record) error {
+					r.Level = slog.LevelDebug
+					return nil
+				},
+			},
+		},
+		{
+			name: "drop",
+			fns: []func(ctx context.Context, r *slog.Record) error{
+				func(context.Context, *slog.Record) error { return ErrDropRecord },
+				func(context.Context, *slog.Record) error { panic("not reached") },
+			},
+		},
+		{
+			name: "error",
+			fns: []func(ctx context.Context, r *slog.Record) error{
+				func(context.Context, *slog.Record) error { return errVeto },
+			},
+			wantErr: errVeto,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			opts := []CLIHandlerOption{WithStyle(Style0())}
+			for _, fn := range tt.fns {
+				opts = append(opts, WithRecordHandler(fn))
+			}
+			h := NewCLIHandler(buf, opts...)
+			r := slog.NewRecord(time.Time{}, slog.LevelInfo, "msg", 0)
+			r.AddAttrs(slog.String("k", "v"))
+			if err := h.Handle(context.Background(), r); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+			if r.Message != "msg" || r.NumAttrs() != 1 {
+				t.Errorf("record of the caller modified: %v", r)
+			}
+		})
+	}
+}
+
+func TestCLIHandler_Handle_replaceAttr(t *testing.T) {
+	tm := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	tests := []struct {
+		name    string
+		replace func(groups []string, a slog.Attr) slog.Attr
+		handler func(*bytes.Buffer, ...CLIHandlerOption) slog.Handler
+		want    string
+	}{
+		{
+			name: "identity",
+			replace: func(_ []string, a slog.Attr) slog.Attr {
+				return a
+			},
+			want: "[INF] hello time=2024-01-02T03:04:05Z g.id=1 g.sub.k=v g.n=2\n",
+		},
+		{
+			name: "groups are passed",
+			replace: func(groups []string, a slog.Attr) slog.Attr {
+				if len(groups) > 0 {
+					a.Key = strings.Join(groups, "/") + ":" + a.Key
+				}
+				return a
+			},
+			want: "[INF] hello time=2024-01-02T03:04:05Z g.g:id=1 g.sub.g/sub:k=v g.g:n=2\n",
+		},
+		{
+			name: "remove attrs and empty groups",
+			replace: func(_ []string, a slog.Attr) slog.Attr {
+				if a.Key == "k" || a.Key == "n" {
+					return slog.Attr{}
+				}
+				return a
+			},
+			want: "[INF] hello time=2024-01-02T03:04:05Z g.id=1\n",
+		},
+		{
+			name: "rewrite built-ins",
+			replace: func(groups []string, a slog.Attr) slog.Attr {
+				if groups != nil {
+					return a
+				}
+				switch a.Key {
+				case slog.TimeKey:
+					return slog.String("ts", a.Value.Time().Format("15:04"))
+				case slog.LevelKey:
+					return slog.Any(a.Key, slog.LevelError)
+				case slog.MessageKey:
+					return slog.String(a.Key, strings.ToUpper(a.Value.String()))
+				}
+				return a
+			},
+			want: "[ERR] HELLO ts=03:04 g.id=1 g.sub.k=v g.n=2\n",
+		},
+		{
+			name: "level text",
+			replace: func(_ []string, a slog.Attr) slog.Attr {
+				if a.Key == slog.LevelKey {
+					return slog.String(a.Key, "<I>")
+				}
+				return a
+			},
+			want: "<I> hello time=2024-01-02T03:04:05Z g.id=1 g.sub.k=v g.n=2\n",
+		},
+		{
+			name: "remove built-ins",
+			replace: func(groups []string, a slog.Attr) slog.Attr {
+				if groups == nil && (a.Key == slog.TimeKey || a.Key == slog.LevelKey || a.Key == slog.MessageKey) {
+					return slog.Attr{}
+				}
+				return a
+			},
+			want: " g.id=1 g.sub.k=v g.n=2\n",
+		},
+		{
+			name: "logfmt",
+			replace: func(groups []string, a slog.Attr) slog.Attr {
+				if groups == nil && a.Key == slog.LevelKey {
+					return slog.String("lvl", "info")
+				}
+				return a
+			},
+			handler: func(buf *bytes.Buffer, opts ...CLIHandlerOption) slog.Handler {
+				return NewLogfmtHandler(buf, opts...)
+			},
+			want: "time=2024-01-02T03:04:05Z lvl=info msg=hello g.id=1 g.sub.k=v g.n=2\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			newHandler := tt.handler
+			if newHandler == nil {
+				newHandler = func(buf *bytes.Buffer, opts ...CLIHandlerOption) slog.Handler {
+					return NewCLIHandler(buf, opts...)
+				}
+			}
+			h := newHandler(buf, WithStyle(Style0()), WithTime(true), WithReplaceAttr(tt.replace)).
+				WithGroup("g").
+				WithAttrs([]slog.Attr{slog.Int("id", 1), slog.Group("sub", slog.String("k", "v"))})
+			r := slog.NewRecord(tm, slog.LevelInfo, "hello", 0)
+			r.AddAttrs(slog.Int("n", 2))
+			if err := h.Handle(t.Context(), r); err != nil {
+				t.Fatal(err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCLIHandler_Handle_replaceAttr_source(t *testing.T) {
+	tests := []struct {
+		name    string
+		replace func(groups []string, a slog.Attr) slog.Attr
+		want    string
+	}{
+		{
+			name: "rewrite source",
+			replace: func(_ []string, a slog.Attr) slog.Attr {
+				if src, ok := a.Value.Any().(*slog.Source); ok {
+					src.File = "/x/y/z.go"
+					src.Line = 9
+				}
+				return a
+			},
+			want: "[INF] <z.go:9> hello\n",
+		},
+		{
+			name: "source text",
+			replace: func(_ []string, a slog.Attr) slog.Attr {
+				if a.Key == slog.SourceKey {
+					return slog.String(a.Key, "here")
+				}
+				return a
+			},
+			want: "[INF] <here> hello\n",
+		},
+		{
+			name: "remove source",
+			replace: func(_ []string, a slog.Attr) slog.Attr {
+				if a.Key == slog.SourceKey {
+					return slog.Attr{}
+				}
+				return a
+			},
+			want: "[INF] hello\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			l := slog.New(NewCLIHandler(buf, WithStyle(Style0()), WithCaller(true), WithReplaceAttr(tt.replace)))
+			l.Info("hello")
+			if got := buf.String(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCLIHandler_Handle_timePlacement(t *testing.T) {
+	tm := time.Date(2024, 1, 2, 3, 4, 5, 0, time.FixedZone("JST", 9*60*60))
+	tests := []struct {
+		name string
+		opts []CLIHandlerOption
+		want string
+	}{
+		{
+			name: "as attr",
+			opts: []CLIHandlerOption{WithStyle(Style0())},
+			want: "[INF] hello time=2024-01-02T03:04:05+09:00 k=v\n",
+		},
+		{
+			name: "as attr with key and color",
+			opts: []CLIHandlerOption{
+				WithStyle(NewStyle(WithTimeStyle(TimeStyle{Color: NewColor(FgCyan)}))),
+				WithTimeKey("ts"),
+			},
+			want: "[INF] hello ts=\x1b[36m2024-01-02T03:04:05+09:00\x1b[0m k=v\n",
+		},
+		{
+			name: "before level",
+			opts: []CLIHandlerOption{
+				WithStyle(NewStyle(WithTimeStyle(TimeStyle{Prefix: AffixStyle{Text: "["}, Suffix: AffixStyle{Text: "]"}}))),
+				WithTimePlacement(TimeBeforeLevel),
+				WithTimeFormat(time.TimeOnly),
+			},
+			want: "[03:04:05] [INF] hello k=v\n",
+		},
+		{
+			name: "after level",
+			opts: []CLIHandlerOption{
+				WithStyle(Style0()),
+				WithTimePlacement(TimeAfterLevel),
+				WithTimeFormat(time.TimeOnly),
+				WithLabel("app"),
+			},
+			want: "[INF] 03:04:05 app hello k=v\n",
+		},
+		{
+			name: "utc",
+			opts: []CLIHandlerOption{WithStyle(Style0()), WithUTC(true)},
+			want: "[INF] hello time=2024-01-01T18:04:05Z k=v\n",
+		},
+		{
+			name: "removed by replace attr",
+			opts: []CLIHandlerOption{
+				WithStyle(Style0()),
+				WithTimePlacement(TimeBeforeLevel),
+				WithReplaceAttr(func(groups []string, a slog.Attr) slog.Attr {
+					if groups == nil && a.Key == slog.TimeKey {
+						return slog.Attr{}
+					}
+					return a
+				}),
+			},
+			want: "[INF] hello k=v\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			h := NewCLIHandler(buf, append([]CLIHandlerOption{WithTime(true)}, tt.opts...)...)
+			r := slog.NewRecord(tm, slog.LevelInfo, "hello", 0)
+			r.AddAttrs(slog.String("k", "v"))
+			if err := h.Handle(t.Context(), r); err != nil {
+				t.Fatal(err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCLIHandler_Handle_relativeTime(t *testing.T) {
+	tests := []struct {
+		name    string
+		elapsed time.Duration
+		handler func(*bytes.Buffer) slog.Handler
+		want    string
+	}{
+		{
+			name:    "cli",
+			elapsed: 532 * time.Millisecond,
+			handler: func(buf *bytes.Buffer) slog.Handler {
+				return NewCLIHandler(buf, WithStyle(Style0()), WithTime(true), WithRelativeTime(true))
+			},
+			want: "[INF] hello time=+0.532s\n",
+		},
+		{
+			name:    "before level",
+			elapsed: 61 * time.Second,
+			handler: func(buf *bytes.Buffer) slog.Handler {
+				return NewCLIHandler(buf, WithStyle(Style0()), WithTime(true), WithRelativeTime(true), WithTimePlacement(TimeBeforeLevel))
+			},
+			want: "+61.000s [INF] hello\n",
+		},
+		{
+			name:    "before start",
+			elapsed: -time.Second,
+			handler: func(buf *bytes.Buffer) slog.Handler {
+				return NewCLIHandler(buf, WithStyle(Style0()), WithTime(true), WithRelativeTime(true))
+			},
+			want: "[INF] hello time=-1.000s\n",
+		},
+		{
+			name:    "logfmt",
+			elapsed: 1500 * time.Millisecond,
+			handler: func(buf *bytes.Buffer) slog.Handler {
+				return NewLogfmtHandler(buf, WithRelativeTime(true))
+			},
+			want: "time=+1.500s level=INFO msg=hello\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			h := tt.handler(buf)
+			start := h.(*CLIHandler).start
+			r := slog.NewRecord(start.Add(tt.elapsed), slog.LevelInfo, "hello", 0)
+			if err := h.Handle(t.Context(), r); err != nil {
+				t.Fatal(err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithRelativeTime_disabled(t *testing.T) {
+	h := NewCLIHandler(io.Discard, WithRelativeTime(false)).(*CLIHandler)
+	if h.hasRelative || !h.start.IsZero() {
+		t.Errorf("hasRelative = %v, start = %v, want false and zero", h.hasRelative, h.start)
+	}
+}
+
+func TestCLIHandler_Handle_clock(t *testing.T) {
+	frozen := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	clock := ClockFunc(func() time.Time { return frozen })
+	tests := []struct {
+		name    string
+		handler func(*bytes.Buffer) slog.Handler
+		time    time.Time
+		want    string
+	}{
+		{
+			name: "cli",
+			handler: func(buf *bytes.Buffer) slog.Handler {
+				return NewCLIHandler(buf, WithStyle(Style0()), WithTime(true), WithClock(clock))
+			},
+			time: time.Now(),
+			want: "[INF] hello time=2025-01-02T03:04:05Z\n",
+		},
+		{
+			name: "logfmt",
+			handler: func(buf *bytes.Buffer) slog.Handler {
+				return NewLogfmtHandler(buf, WithClock(clock))
+			},
+			time: time.Now(),
+			want: "time=2025-01-02T03:04:05Z level=INFO msg=hello\n",
+		},
+		{
+			name: "zero time",
+			handler: func(buf *bytes.Buffer) slog.Handler {
+				return NewLogfmtHandler(buf, WithClock(clock))
+			},
+			want: "level=INFO msg=hello\n",
+		},
+		{
+			name: "relative",
+			handler: func(buf *bytes.Buffer) slog.Handler {
+				return NewCLIHandler(buf, WithStyle(Style0()), WithTime(true), WithRelativeTime(true), WithClock(clock))
+			},
+			time: time.Now(),
+			want: "[INF] hello time=+0.000s\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			r := slog.NewRecord(tt.time, slog.LevelInfo, "hello", 0)
+			if err := tt.handler(buf).Handle(t.Context(), r); err != nil {
+				t.Fatal(err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCLIHandler_Handle_fixedCaller(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, file, line, _ := runtime.Caller(0)
+	frame := runtime.Frame{Function: "github.com/nekrassov01/logger/log.TestCLIHandler_Handle_fixedCaller", File: file, Line: line}
+	tests := []struct {
+		name  string
+		fixed bool
+		frame runtime.Frame
+		want  string
+	}{
+		{name: "absolute", frame: frame, want: file + ":" + strconv.Itoa(line)},
+		{name: "fixed", fixed: true, frame: frame, want: "handler_test.go:" + strconv.Itoa(line)},
+		{
+			name:  "fixed outside working directory",
+			fixed: true,
+			frame: runtime.Frame{File: "/usr/local/go/src/testing/testing.go", Line: 10},
+			want:  "testing.go:10",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := Style0()
+			s.Caller.Fullpath = true
+			h := NewCLIHandler(io.Discard, WithStyle(s), WithFixedCaller(tt.fixed)).(*CLIHandler)
+			if got := string(h.formatCaller(tt.frame)); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("stack", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		NewLogger(NewCLIHandler(buf, WithStyle(Style0()), WithStackTrace(slog.LevelError), WithFixedCaller(true))).Error("failed")
+		got := buf.String()
+		if strings.Contains(got, wd) || strings.Contains(got, runtime.GOROOT()) {
+			t.Errorf("got %q, want no absolute paths", got)
+		}
+		if !strings.Contains(got, "handler_test.go:") {
+			t.Errorf("got %q, want test file in stack", got)
+		}
+	})
+}
+
+func TestCLIHandler_slogtest(t *testing.T) {
+	var buf bytes.Buffer
+	slogtest.Run(t, func(*testing.T) slog.Handler {
+		buf.Reset()
+		return NewCLIHandler(&buf, WithStyle(Style0()), WithTime(true), WithColorMode(ColorNever))
+	}, func(t *testing.T) map[string]any {
+		// The line is "[LVL] msg key=value ..."; the messages of slogtest have no spaces.
+		level, rest, _ := strings.Cut(strings.TrimSuffix(buf.String(), "\n"), " ")
+		msg, rest, _ := strings.Cut(rest, " ")
+		m := parseLogfmt(t, rest)
+		m[slog.LevelKey] = level
+		m[slog.MessageKey] = msg
+		return m
+	})
+}
+
+func TestCLIHandler_Handle_groupDelimiters(t *testing.T) {
+	tests := []struct {
+		name  string
+		style *Style
+		log   func(l *Logger)
+		want  string
+	}{
+		{
+			name:  "group",
+			style: NewStyle(WithGroupDelimiters("{", "}", "")),
+			log:   func(l *Logger) { l.Info("msg", slog.Group("g1", "k1", "v1", "k2", 2), "k3", 3) },
+			want:  "[INF] msg g1{k1=v1 k2=2} k3=3\n",
+		},
+		{
+			name:  "nested",
+			style: NewStyle(WithGroupDelimiters("{", "}", "")),
+			log:   func(l *Logger) { l.Info("msg", slog.Group("a", "x", 1, slog.Group("b", slog.Group("c", "y", 2)))) },
+			want:  "[INF] msg a{x=1 b{c{y=2}}}\n",
+		},
+		{
+			name:  "separator",
+			style: NewStyle(WithGroupDelimiters("(", ")", ", ")),
+			log:   func(l *Logger) { l.Info("msg", slog.Group("g", "a", 1, "b", 2)) },
+			want:  "[INF] msg g(a=1, b=2)\n",
+		},
+		{
+			name:  "empty and inline groups",
+			style: NewStyle(WithGroupDelimiters("{", "}", "")),
+			log: func(l *Logger) {
+				l.Info("msg", slog.Group("empty"), slog.Group("g", slog.Group("", "a", 1), slog.Attr{}))
+			},
+			want: "[INF] msg g{a=1}\n",
+		},
+		{
+			name:  "handler groups",
+			style: NewStyle(WithGroupDelimiters("{", "}", "")),
+			log: func(l *Logger) {
+				h := l.Handler().WithAttrs([]slog.Attr{slog.Int("a", 1)}).WithGroup("g").WithAttrs([]slog.Attr{slog.Int("b", 2)}).WithGroup("h")
+				NewLogger(h).Info("msg", "c", 3, "d", 4)
+			},
+			want: "[INF] msg a=1 g{b=2} g{h{c=3 d=4}}\n",
+		},
+		{
+			name:  "handler groups without record attrs",
+			style: NewStyle(WithGroupDelimiters("{", "}", "")),
+			log:   func(l *Logger) { NewLogger(l.Handler().WithGroup("g")).Info("msg") },
+			want:  "[INF] msg\n",
+		},
+		{
+			name:  "dotted",
+			style: Style0(),
+			log:   func(l *Logger) { l.Info("msg", slog.Group("g1", "k1", "v1", slog.Group("g2", "k2", 2))) },
+			want:  "[INF] msg g1.k1=v1 g1.g2.k2=2\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			tt.log(NewLogger(NewCLIHandler(buf, WithStyle(tt.style))))
+			if got := buf.String(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCLIHandler_Handle_emptyGroups(t *testing.T) {
+	upper := func(_ []string, a slog.Attr) slog.Attr {
+		a.Key = strings.ToUpper(a.Key)
+		return a
+	}
+	tests := []struct {
+		name string
+		opts []CLIHandlerOption
+		log  func(l *Logger)
+		want string
+	}{
+		{
+			name: "inline group",
+			log:  func(l *Logger) { l.Info("msg", "a", 1, slog.Group("", "b", 2, "c", 3), "d", 4) },
+			want: "[INF] msg a=1 b=2 c=3 d=4\n",
+		},
+		{
+			name: "empty group",
+			log:  func(l *Logger) { l.Info("msg", "a", 1, slog.Group("g"), slog.Group("h", slog.Group("i"))) },
+			want: "[INF] msg a=1\n",
+		},
+		{
+			name: "empty group in handler group",
+			log:  func(l *Logger) { l.WithGroup("g").With(slog.Group("h")).Info("msg") },
+			want: "[INF] msg\n",
+		},
+		{
+			name: "inline group in handler group",
+			log:  func(l *Logger) { l.WithGroup("g").With(slog.Group("", "a", 1)).Info("msg", slog.Group("", "b", 2)) },
+			want: "[INF] msg g.a=1 g.b=2\n",
+		},
+		{
+			name: "inline group replaced",
+			opts: []CLIHandlerOption{WithReplaceAttr(upper)},
+			log:  func(l *Logger) { l.With(slog.Group("", "a", 1)).Info("msg", slog.Group("", "b", 2)) },
+			want: "[INF] msg A=1 B=2\n",
+		},
+		{
+			name: "inline group replaced in multiline",
+			opts: []CLIHandlerOption{WithReplaceAttr(upper), WithMultiline(true)},
+			log:  func(l *Logger) { l.With(slog.Group("", "a", 1)).Info("msg") },
+			want: "[INF] msg\n  A=1\n",
+		},
+		{
+			name: "inline group sorted",
+			opts: []CLIHandlerOption{WithAttrSort(SortAlphabetical)},
+			log: func(l *Logger) {
+				l.With(slog.Group("", "c", 3)).Info("msg", "d", 4, slog.Group("", "b", 2), slog.Group("g", "z", 1, slog.Group("", "y", 2)), "a", 1)
+			},
+			want: "[INF] msg a=1 b=2 c=3 d=4 g.y=2 g.z=1\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			opts := append([]CLIHandlerOption{WithStyle(Style0())}, tt.opts...)
+			tt.log(NewLogger(NewCLIHandler(buf, opts...)))
+			if got := buf.String(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCLIHandler_Handle_attrWidth(t *testing.T) {
+	tests := []struct {
+		name  string
+		style *Style
+		log   func(l *Logger)
+		want  string
+	}{
+		{
+			name:  "fixed",
+			style: NewStyle(WithAttrWidth(4)),
+			log: func(l *Logger) {
+				l.Info("msg", "a", 1)
+				l.Info("msg", "bb", 22)
+				l.Info("msg", "long", "value", "c", 3)
+				l.Info("msg", "longer", 4)
+			},
+			want: "[INF] msg a   =1\n" +
+				"[INF] msg bb  =22\n" +
+				"[INF] msg long=value c   =3\n" +
+				"[INF] msg longer=4\n",
+		},
+		{
+			name:  "auto",
+			style: NewStyle(WithAttrWidth(AttrWidthAuto)),
+			log: func(l *Logger) {
+				l.Info("msg", "n", 1)
+				l.Info("msg", "file", "a.txt")
+				l.Info("msg", "n", 2, "ok", true)
+			},
+			want: "[INF] msg n=1\n" +
+				"[INF] msg file=a.txt\n" +
+				"[INF] msg n   =2 ok  =true\n",
+		},
+		{
+			name:  "handler attrs and groups",
+			style: NewStyle(WithAttrWidth(4)),
+			log: func(l *Logger) {
+				NewLogger(l.Handler().WithAttrs([]slog.Attr{slog.Int("a", 1)}).WithGroup("g")).Info("msg", "b", 2)
+			},
+			want: "[INF] msg a   =1 g.b =2\n",
+		},
+		{
+			name:  "group delimiters",
+			style: NewStyle(WithAttrWidth(2), WithGroupDelimiters("{", "}", "")),
+			log:   func(l *Logger) { l.Info("msg", slog.Group("g", "a", 1, "b", 2), "c", 3) },
+			want:  "[INF] msg g{a =1 b =2} c =3\n",
+		},
+		{
+			name: "colors",
+			style: NewStyle(WithAttrStyle(AttrStyle{
+				KeyColor:   NewColor(FgCyan),
+				ValueColor: NewColor(FgGreen),
+				Separator:  "=",
+				Width:      3,
+			})),
+			log:  func(l *Logger) { l.Info("msg", "a", 1, "b", 2) },
+			want: "[INF] msg \x1b[36ma\x1b[0m  \x1b[36m=\x1b[0m\x1b[32m1\x1b[0m \x1b[36mb\x1b[0m  \x1b[36m=\x1b[0m\x1b[32m2\x1b[0m\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			tt.log(NewLogger(NewCLIHandler(buf, WithStyle(tt.style), WithColorMode(ColorAlways))))
+			if got := buf.String(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCLIHandler_Handle_dedupAttrs(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []CLIHandlerOption
+		log  func(l *Logger)
+		want string
+	}{
+		{
+			name: "disabled",
+			log:  func(l *Logger) { NewLogger(l.Handler().WithAttrs([]slog.Attr{slog.Int("a", 1)})).Info("msg", "a", 2) },
+			want: "[INF] msg a=1 a=2\n",
+		},
+		{
+			name: "handler and record attrs",
+			opts: []CLIHandlerOption{WithDedupAttrs(true)},
+			log: func(l *Logger) {
+				NewLogger(l.Handler().WithAttrs([]slog.Attr{slog.Int("a", 1), slog.Int("b", 2)})).Info("msg", "c", 3, "a", 4)
+			},
+			want: "[INF] msg a=4 b=2 c=3\n",
+		},
+		{
+			name: "record attrs",
+			opts: []CLIHandlerOption{WithDedupAttrs(true)},
+			log:  func(l *Logger) { l.Info("msg", "a", 1, "a", 2, "a", 3) },
+			want: "[INF] msg a=3\n",
+		},
+		{
+			name: "context attrs",
+			opts: []CLIHandlerOption{WithDedupAttrs(true)},
+			log: func(l *Logger) {
+				l.InfoContext(NewContext(context.Background(), slog.String("id", "ctx")), "msg", "id", "call")
+			},
+			want: "[INF] msg id=call\n",
+		},
+		{
+			name: "groups",
+			opts: []CLIHandlerOption{WithDedupAttrs(true)},
+			log: func(l *Logger) {
+				h := l.Handler().WithGroup("g").WithAttrs([]slog.Attr{slog.Int("a", 1), slog.Int("b", 2)})
+				NewLogger(h).Info("msg", "a", 3, slog.Group("", "b", 4), slog.Group("h", "c", 5), slog.Group("h", "c", 6))
+			},
+			want: "[INF] msg g.a=3 g.b=4 g.h.c=6\n",
+		},
+		{
+			name: "group replaced by value",
+			opts: []CLIHandlerOption{WithDedupAttrs(true)},
+			log:  func(l *Logger) { l.Info("msg", slog.Group("a", "x", 1), "a", 2) },
+			want: "[INF] msg a=2\n",
+		},
+		{
+			name: "group delimiters",
+			opts: []CLIHandlerOption{WithDedupAttrs(true), WithStyle(NewStyle(WithGroupDelimiters("{", "}", "")))},
+			log: func(l *Logger) {
+				h := l.Handler().WithGroup("g").WithAttrs([]slog.Attr{slog.Int("a", 1)})
+				NewLogger(h).Info("msg", "a", 2, "b", 3)
+			},
+			want: "[INF] msg g{a=2 b=3}\n",
+		},
+		{
+			name: "multiline",
+			opts: []CLIHandlerOption{WithDedupAttrs(true), WithMultiline(true)},
+			log: func(l *Logger) {
+				NewLogger(l.Handler().WithAttrs([]slog.Attr{slog.Int("a", 1)})).Info("msg", "b", 2, "a", 3)
+			},
+			want: "[INF] msg\n  a=3\n  b=2\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			opts := append([]CLIHandlerOption{WithStyle(Style0())}, tt.opts...)
+			tt.log(NewLogger(NewCLIHandler(buf, opts...)))
+			if got := buf.String(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("logfmt", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		h := NewLogfmtHandler(buf, WithDedupAttrs(true)).WithAttrs([]slog.Attr{slog.Int("a", 1)})
+		if err := h.Handle(t.Context(), slog.NewRecord(time.Time{}, slog.LevelInfo, "msg", 0)); err != nil {
+			t.Fatal(err)
+		}
+		r := slog.NewRecord(time.Time{}, slog.LevelInfo, "msg", 0)
+		r.Add("a", 2)
+		if err := h.Handle(t.Context(), r); err != nil {
+			t.Fatal(err)
+		}
+		want := "level=INFO msg=msg a=1\nlevel=INFO msg=msg a=2\n"
+		if got := buf.String(); got != want {
+			t.Errorf("got %q, want %q", got, want)
+		}
+	})
+}
+
+func TestCLIHandler_Handle_messageStyle(t *testing.T) {
+	style := NewStyle(WithMessageStyle(MessageStyle{
+		Levels: map[slog.Level]*Color{slog.LevelError: NewColor(Bold, FgRed)},
+	}))
+	buf := &bytes.Buffer{}
+	l := NewLogger(NewCLIHandler(buf, WithStyle(style), WithColorMode(ColorAlways)))
+	l.Info("plain")
+	l.Error("failed", "k", 1)
+	l.Error("")
+	want := "[INF] plain\n[ERR] \x1b[1;31mfailed\x1b[0m k=1\n[ERR]\n"
+	if got := buf.String(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestCLIHandler_Handle_icons(t *testing.T) {
+	tests := []struct {
+		name   string
+		levels map[slog.Level]LevelStyle
+		want   string
+	}{
+		{
+			name:   "icon only",
+			levels: map[slog.Level]LevelStyle{slog.LevelInfo: {Icon: "ℹ"}, slog.LevelError: {Icon: "✖"}},
+			want:   "ℹ info\n✖ error\n",
+		},
+		{
+			name:   "icon before text",
+			levels: map[slog.Level]LevelStyle{slog.LevelInfo: {Icon: "ℹ", Text: "INF", Prefix: AffixStyle{Text: "["}, Suffix: AffixStyle{Text: "]"}}},
+			want:   "[ℹ INF] info\n[ℹ INF] error\n",
+		},
+		{
+			name: "aligned wide icons",
+			levels: map[slog.Level]LevelStyle{
+				slog.LevelInfo:  {Icon: "💡", Width: 4},
+				slog.LevelError: {Icon: "✖", Width: 4},
+			},
+			want: " 💡  info\n ✖   error\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			style := &Style{Level: tt.levels}
+			l := NewLogger(NewCLIHandler(buf, WithStyle(style), WithColorMode(ColorNever)))
+			l.Info("info")
+			l.Error("error")
+			if got := buf.String(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCLIHandler_Handle_hooks(t *testing.T) {
+	buf := &bytes.Buffer{}
+	var got []string
+	counts := map[slog.Level]int{}
+	h := NewCLIHandler(buf,
+		WithStyle(Style0()),
+		WithLevelOverrides(map[string]slog.Leveler{"db": slog.LevelError}),
+		WithHook(func(_ context.Context, r slog.Record) { got = append(got, "first:"+r.Message) }),
+		WithHook(nil),
+		WithHook(func(context.Context, slog.Record) { panic("hook failed") }),
+		WithHook(func(_ context.Context, r slog.Record) { counts[r.Level]++ }),
+	)
+	l := NewLogger(h)
+	l.Info("a")
+	l.Debug("disabled")
+	l.Warn("b", ModuleKey, "db")
+	l.With("k", 1).Error("c")
+	if want := []string{"first:a", "first:c"}; !slices.Equal(got, want) {
+		t.Errorf("hooks got %q, want %q", got, want)
+	}
+	if want := map[slog.Level]int{slog.LevelInfo: 1, slog.LevelError: 1}; !reflect.DeepEqual(counts, want) {
+		t.Errorf("counts = %v, want %v", counts, want)
+	}
+	if want := "[INF] a\n[ERR] c k=1\n"; buf.String() != want {
+		t.Errorf("got %q, want %q", buf.String(), want)
+	}
+}
+
+func TestCLIHandler_Close(t *testing.T) {
+	ws, events := newCloseWriters("w")
+	h := NewCLIHandler(ws[0], WithStyle(Style0()))
+	derived := h.WithAttrs([]slog.Attr{slog.Int("a", 1)})
+	l := NewLogger(derived)
+	l.Info("before")
+	if err := h.(*CLIHandler).Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.(*CLIHandler).Close(); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"w:flush", "w:close"}; !slices.Equal(*events, want) {
+		t.Errorf("events = %q, want %q", *events, want)
+	}
+	r := slog.NewRecord(time.Time{}, slog.LevelInfo, "after", 0)
+	if err := derived.Handle(t.Context(), r); !errors.Is(err, ErrHandlerClosed) {
+		t.Errorf("err = %v, want %v", err, ErrHandlerClosed)
+	}
+	if got, want := ws[0].String(), "[INF] before a=1\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestCLIHandler_levelWriter(t *testing.T) {
+	tests := []struct {
+		name    string
+		writers func(out, err *bytes.Buffer) map[slog.Level]io.Writer
+		want    string
+		wantOut string
+		wantErr string
+	}{
+		{
+			name:    "none",
+			writers: func(out, err *bytes.Buffer) map[slog.Level]io.Writer { return nil },
+			want:    "[DBG] d\n[INF] i\n[WRN] w\n[ERR] e\n",
+		},
+		{
+			name: "warn and above",
+			writers: func(out, err *bytes.Buffer) map[slog.Level]io.Writer {
+				return map[slog.Level]io.Writer{slog.LevelWarn: err}
+			},
+			want:    "[DBG] d\n[INF] i\n",
+			wantErr: "[WRN] w\n[ERR] e\n",
+		},
+		{
+			name: "ranges",
+			writers: func(out, err *bytes.Buffer) map[slog.Level]io.Writer {
+				return map[slog.Level]io.Writer{slog.LevelError: err, slog.LevelInfo: out, slog.LevelWarn: nil}
+			},
+			want:    "[DBG] d\n",
+			wantOut: "[INF] i\n",
+			wantErr: "[ERR] e\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, out, errw := &bytes.Buffer{}, &bytes.Buffer{}, &bytes.Buffer{}
+			l := NewLogger(NewCLIHandler(w, WithStyle(Style0()), WithLevel(slog.LevelDebug), WithLevelWriter(tt.writers(out, errw))))
+			l.Debug("d")
+			l.Info("i")
+			l.Warn("w")
+			l.Error("e")
+			for _, c := range []struct{ name, got, want string }{
+				{"handler writer", w.String(), tt.want},
+				{"out", out.String(), tt.wantOut},
+				{"err", errw.String(), tt.wantErr},
+			} {
+				if c.got != c.want {
+					t.Errorf("%s = %q, want %q", c.name, c.got, c.want)
+				}
+			}
+		})
+	}
+}
+
+func TestCLIHandler_levelWriter_close(t *testing.T) {
+	ws, events := newCloseWriters("w", "err")
+	h := NewCLIHandler(ws[0], WithLevelWriter(map[slog.Level]io.Writer{
+		slog.LevelWarn:  ws[1],
+		slog.LevelError: ws[1],
+		LevelFatal:      os.Stderr,
+	})).(*CLIHandler)
+	if err := h.Close(); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"w:flush", "err:flush", "err:flush", "w:close", "err:close"}
+	if !slices.Equal(*events, want) {
+		t.Errorf("events = %q, want %q", *events, want)
+	}
+}
+
+func TestCLIHandler_Close_std(t *testing.T) {
+	for _, w := range []io.Writer{os.Stdout, os.Stderr} {
+		if c := closerOf(w); c != nil {
+			t.Errorf("closerOf(%v) = %v, want nil", w, c)
+		}
+	}
+	f, err := os.CreateTemp(t.TempDir(), "log")
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := NewCLIHandler(f).(*CLIHandler)
+	if err := h.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.Write([]byte("x")); !errors.Is(err, os.ErrClosed) {
+		t.Errorf("err = %v, want %v", err, os.ErrClosed)
+	}
+}
+
+func TestCLIHandler_Handle_allocs(t *testing.T) {
+	if raceEnabled {
+		t.Skip("allocation counts are unreliable with the race detector")
+	}
+	tests := []struct {
+		name   string
+		opts   []CLIHandlerOption
+		groups []string
+	}{
+		{name: "default"},
+		{name: "groups", groups: []string{"g1", "g2"}},
+		{name: "group delimiters", opts: []CLIHandlerOption{WithStyle(NewStyle(WithGroupDelimiters("{", "}", " ")))}, groups: []string{"g1"}},
+		{name: "attr width", opts: []CLIHandlerOption{WithStyle(NewStyle(WithAttrWidth(AttrWidthAuto)))}},
+		{name: "dedup", opts: []CLIHandlerOption{WithDedupAttrs(true)}},
+	}
+	attrs := []slog.Attr{
+		slog.String("str", "value"),
+		slog.Int("int", 42),
+		slog.Group("group", slog.Bool("bool", true), slog.Group("nested", slog.Int("n", 1))),
+	}
+	ctx := context.Background()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewCLIHandler(io.Discard, tt.opts...)
+			h = h.WithAttrs([]slog.Attr{slog.Group("app", slog.String("version", "1.0.0"))})
+			for _, g := range tt.groups {
+				h = h.WithGroup(g)
+			}
+			r := slog.NewRecord(time.Time{}, slog.LevelInfo, "message", 0)
+			r.AddAttrs(attrs...)
+			_ = h.Handle(ctx, r)
+			if n := testing.AllocsPerRun(100, func() { _ = h.Handle(ctx, r) }); n != 0 {
+				t.Errorf("allocs = %v, want 0", n)
+			}
+		})
+	}
+}
+
+func BenchmarkCLIHandler_Handle(b *testing.B) {
+	benchmarks := []struct {
+		name   string
+		opts   []CLIHandlerOption
+		groups []string
+		logfmt bool
+	}{
+		{name: "default"},
+		{name: "time and caller", opts: []CLIHandlerOption{WithTime(true), WithCaller(true)}},
+		{name: "groups", groups: []string{"g1", "g2"}},
+		{name: "group delimiters", opts: []CLIHandlerOption{WithStyle(NewStyle(WithGroupDelimiters("{", "}", " ")))}, groups: []string{"g1"}},
+		{name: "attr width", opts: []CLIHandlerOption{WithStyle(NewStyle(WithAttrWidth(AttrWidthAuto)))}, groups: []string{"g1"}},
+		{name: "multiline", opts: []CLIHandlerOption{WithMultiline(true)}, groups: []string{"g1"}},
+		{name: "dedup", opts: []CLIHandlerOption{WithDedupAttrs(true)}},
+		{name: "logfmt", logfmt: true},
+	}
+	attrs := []slog.Attr{
+		slog.String("str", "value"),
+		slog.Int("int", 42),
+		slog.Duration("dur", time.Second),
+		slog.Any("err", errors.New("failed")),
+		slog.Group("group", slog.Bool("bool", true)),
+	}
+	ctx := context.Background()
+	for _, bm := range benchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			newHandler := NewCLIHandler
+			if bm.logfmt {
+				newHandler = NewLogfmtHandler
+			}
+			h := newHandler(io.Discard, append([]CLIHandlerOption{WithColorMode(ColorAlways)}, bm.opts...)...)
+			h = h.WithAttrs([]slog.Attr{slog.String("version", "1.0.0")})
+			for _, g := range bm.groups {
+				h = h.WithGroup(g)
+			}
+			b.ReportAllocs()
+			for b.Loop() {
+				r := slog.NewRecord(time.Now(), slog.LevelInfo, "message", 0)
+				r.AddAttrs(attrs...)
+				_ = h.Handle(ctx, r)
+			}
+		})
+	}
+}
+
+func BenchmarkCLIHandler_Handle_header(b *testing.B) {
+	for _, cached := range []bool{true, false} {
+		b.Run(fmt.Sprintf("cached %t", cached), func(b *testing.B) {
+			h := NewCLIHandler(io.Discard, WithColorMode(ColorAlways), WithLabel("app"), WithStyle(Style5())).(*CLIHandler)
+			if !cached {
+				h.levelHeaders = nil
+				h.label.Store(&labelState{text: "app"})
+			}
+			ctx := context.Background()
+			b.ReportAllocs()
+			for b.Loop() {
+				_ = h.Handle(ctx, slog.NewRecord(time.Time{}, slog.LevelInfo, "message", 0))
+			}
+		})
+	}
+}
+
+func BenchmarkCLIHandler_Handle_parallel(b *testing.B) {
+	for _, parallel := range []bool{false, true} {
+		b.Run(fmt.Sprintf("parallel format %t", parallel), func(b *testing.B) {
+			h := NewCLIHandler(io.Discard, WithColorMode(ColorAlways), WithCaller(true), WithParallelFormat(parallel))
+			ctx := context.Background()
+			var pcs [1]uintptr
+			runtime.Callers(1, pcs[:])
+			b.ReportAllocs()
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					r := slog.NewRecord(time.Now(), slog.LevelInfo, "message", pcs[0])
+					r.AddAttrs(slog.String("str", "value"), slog.Int("int", 42), slog.Duration("dur", time.Second))
+					_ = h.Handle(ctx, r)
+				}
+			})
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package log
+
+import (
+	"log/slog"
+	"strconv"
+	"time"
+)
+
+// DurationFormat selects how durations in attribute values are written.
+type DurationFormat int
+
+const (
+	// DurationDefault writes durations as time.Duration.String does, e.g. 1.234567s.
+	DurationDefault DurationFormat = iota
+
+	// DurationMillis writes durations rounded to milliseconds, e.g. 1.235s.
+	DurationMillis
+
+	// DurationHuman writes durations with a single decimal in their largest
+	// unit, or in two units from a minute up, e.g. 1.2s, 350ms or 3m12s.
+	DurationHuman
+)
+
+// ByteSize is a number of bytes that is written in binary units, e.g. 4.2 MiB.
+// Handlers that encode values, such as slog.JSONHandler, write the number.
+type ByteSize int64
+
+// byteUnits are the units of ByteSize from bytes up.
+var byteUnits = [...]string{"B", "KiB", "MiB", "GiB", "TiB", "PiB", "EiB"}
+
+// String returns the size in the largest unit it reaches, with a single decimal.
+func (b ByteSize) String() string {
+	n := int64(b)
+	sign := ""
+	if n < 0 {
+		sign, n = "-", -n
+	}
+	if n < 1024 {
+		return sign + strconv.FormatInt(n, 10) + " B"
+	}
+	v := float64(n)
+	i := 0
+	for v >= 1024 && i < len(byteUnits)-1 {
+		v /= 1024
+		i++
+	}
+	return sign + formatDecimal(v) + " " + byteUnits[i]
+}
+
+// Bytes returns an attribute whose value is the ByteSize n:
+//
+//	logger.Info("downloaded", log.Bytes("size", 4404019)) // size="4.2 MiB"
+func Bytes(key string, n int64) slog.Attr {
+	return slog.Any(key, ByteSize(n))
+}
+
+// WithDurationFormat returns a StyleOption that sets how durations in attribute
+// values are written. The default is DurationDefault.
+func WithDurationFormat(format DurationFormat) StyleOption {
+	return func(s *Style) {
+		s.Attr.Duration = format
+	}
+}
+
+// formatDuration returns d as text in the given format.
+func formatDuration(d time.Duration, format DurationFormat) string {
+	switch format {
+	case DurationMillis:
+		return d.Round(time.Millisecond).String()
+	case DurationHuman:
+		return humanizeDuration(d)
+	default:
+		return d.String()
+	}
+}
+
+// humanizeDuration returns d with a single decimal in its largest unit below a
+// minute, and in hours and minutes or minutes and seconds above.
+func humanizeDuration(d time.Duration) string {
+	sign := ""
+	if d < 0 {
+		sign, d = "-", -d
+	}
+	switch {
+	case d < time.Microsecond:
+		return sign + strconv.FormatInt(int64(d), 10) + "ns"
+	case d < time.Millisecond:
+		return sign + formatDecimal(float64(d)/float64(time.Microsecond)) + "µs"
+	case d < time.Second:
+		return sign + formatDecimal(float64(d)/float64(time.Millisecond)) + "ms"
+	case d < time.Minute:
+		return sign + formatDecimal(d.Seconds()) + "s"
+	case d < time.Hour:
+		d = d.Round(time.Second)
+		return sign + strconv.Itoa(int(d/time.Minute)) + "m" + strconv.Itoa(int(d%time.Minute/time.Second)) + "s"
+	default:
+		d = d.Round(time.Minute)
+		return sign + strconv.Itoa(int(d/time.Hour)) + "h" + strconv.Itoa(int(d%time.Hour/time.Minute)) + "m"
+	}
+}
+
+// formatDecimal returns v with a single decimal, dropped if it is zero.
+func formatDecimal(v float64) string {
+	b := strconv.AppendFloat(nil, v, 'f', 1, 64)
+	if n := len(b); b[n-1] == '0' {
+		b = b[:n-2]
+	}
+	return string(b)
+}
@@ -0,0 +1,88 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+func TestByteSize_String(t *testing.T) {
+	tests := []struct {
+		n    int64
+		want string
+	}{
+		{n: 0, want: "0 B"},
+		{n: 1023, want: "1023 B"},
+		{n: 1024, want: "1 KiB"},
+		{n: 1536, want: "1.5 KiB"},
+		{n: 4404019, want: "4.2 MiB"},
+		{n: 5 << 30, want: "5 GiB"},
+		{n: -2048, want: "-2 KiB"},
+	}
+	for _, tt := range tests {
+		if got := ByteSize(tt.n).String(); got != tt.want {
+			t.Errorf("ByteSize(%d) = %q, want %q", tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestBytes(t *testing.T) {
+	buf := &bytes.Buffer{}
+	NewLogger(NewCLIHandler(buf, WithStyle(Style0()))).Info("msg", Bytes("size", 4404019))
+	if want := "[INF] msg size=\"4.2 MiB\"\n"; buf.String() != want {
+		t.Errorf("got %q, want %q", buf.String(), want)
+	}
+
+	buf.Reset()
+	slog.New(slog.NewJSONHandler(buf, nil)).Info("msg", Bytes("size", 1024))
+	var m map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &m); err != nil {
+		t.Fatal(err)
+	}
+	if m["size"] != float64(1024) {
+		t.Errorf("size = %v, want the number of bytes", m["size"])
+	}
+}
+
+func Test_humanizeDuration(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{d: 0, want: "0ns"},
+		{d: 850, want: "850ns"},
+		{d: 12340, want: "12.3µs"},
+		{d: 350 * time.Millisecond, want: "350ms"},
+		{d: 1234567 * time.Microsecond, want: "1.2s"},
+		{d: 3*time.Minute + 12400*time.Millisecond, want: "3m12s"},
+		{d: 2*time.Hour + 5*time.Minute + 40*time.Second, want: "2h6m"},
+		{d: -1500 * time.Millisecond, want: "-1.5s"},
+	}
+	for _, tt := range tests {
+		if got := humanizeDuration(tt.d); got != tt.want {
+			t.Errorf("humanizeDuration(%v) = %q, want %q", tt.d, got, tt.want)
+		}
+	}
+}
+
+func TestWithDurationFormat(t *testing.T) {
+	d := 1234567 * time.Microsecond
+	tests := []struct {
+		format DurationFormat
+		want   string
+	}{
+		{format: DurationDefault, want: "d=1.234567s"},
+		{format: DurationMillis, want: "d=1.235s"},
+		{format: DurationHuman, want: "d=1.2s"},
+	}
+	for _, tt := range tests {
+		buf := &bytes.Buffer{}
+		style := NewStyle(WithDurationFormat(tt.format))
+		NewLogger(NewCLIHandler(buf, WithStyle(style))).Info("msg", "d", d)
+		if want := "[INF] msg " + tt.want + "\n"; buf.String() != want {
+			t.Errorf("format %d: got %q, want %q", tt.format, buf.String(), want)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package log
+
+import (
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Caller link templates for WithCallerLink.
+const (
+	// CallerLinkFile links the caller to its file with a file:// URL.
+	CallerLinkFile = "file://{path}"
+
+	// CallerLinkVSCode opens the caller at its line in Visual Studio Code.
+	CallerLinkVSCode = "vscode://file{path}:{line}"
+)
+
+// Hyperlink is an attribute value that CLIHandler writes as a clickable OSC 8
+// hyperlink to URL showing Text, in terminals that support them, and as Text
+// otherwise.
+type Hyperlink struct {
+	Text string
+	URL  string
+}
+
+// String returns the text of the hyperlink.
+func (l Hyperlink) String() string {
+	return l.Text
+}
+
+// Link returns an attribute whose value is a Hyperlink to url showing text.
+func Link(key, text, url string) slog.Attr {
+	return slog.Any(key, Hyperlink{Text: text, URL: url})
+}
+
+// DetectHyperlinks reports whether the current terminal is known to support
+// OSC 8 hyperlinks. FORCE_HYPERLINK overrides the detection when set, with "0"
+// disabling them; otherwise the variables set by terminals such as Windows
+// Terminal, iTerm2, WezTerm, kitty, VS Code and VTE-based terminals are checked.
+func DetectHyperlinks() bool {
+	if v := os.Getenv("FORCE_HYPERLINK"); v != "" {
+		return v != "0"
+	}
+	for _, key := range []string{"WT_SESSION", "KITTY_WINDOW_ID", "WEZTERM_EXECUTABLE", "DOMTERM"} {
+		if os.Getenv(key) != "" {
+			return true
+		}
+	}
+	switch os.Getenv("TERM_PROGRAM") {
+	case "iTerm.app", "WezTerm", "vscode", "Hyper", "ghostty", "Tabby":
+		return true
+	}
+	if v, err := strconv.Atoi(os.Getenv("VTE_VERSION")); err == nil && v >= 5000 {
+		return true
+	}
+	term := os.Getenv("TERM")
+	for _, name := range []string{"kitty", "foot", "alacritty", "ghostty", "wezterm"} {
+		if strings.Contains(term, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// linksEnabled reports whether hyperlinks should be written to w. Links need
+// colors to be enabled; files are probed with DetectHyperlinks, and other
+// writers keep links, as they keep colors, because they cannot be probed.
+func linksEnabled(w io.Writer, mode ColorMode) bool {
+	if !colorEnabled(w, mode) {
+		return false
+	}
+	if _, ok := w.(*os.File); ok {
+		return DetectHyperlinks()
+	}
+	return true
+}
+
+// callerURL expands the placeholders of a caller link template: {path} is the
+// absolute file path with forward slashes and a leading slash, {line} the line
+// and {func} the function name.
+func callerURL(template, file string, line int, function string) string {
+	path := filepath.ToSlash(file)
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	return strings.NewReplacer(
+		"{path}", escapeURL(path, true),
+		"{line}", strconv.Itoa(line),
+		"{func}", escapeURL(function, true),
+	).Replace(template)
+}
+
+// escapeURL percent-encodes the bytes of s that cannot appear in an OSC 8 URI,
+// and percent signs if literal is true.
+func escapeURL(s string, literal bool) string {
+	const hex = "0123456789ABCDEF"
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c <= ' ' || c > '~' || c == '%' && literal {
+			b.WriteByte('%')
+			b.WriteByte(hex[c>>4])
+			b.WriteByte(hex[c&0xf])
+			continue
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
+
+// hyperlinkValue returns the Hyperlink held by v, if any.
+func hyperlinkValue(v slog.Value) (Hyperlink, bool) {
+	if v.Kind() != slog.KindAny {
+		return Hyperlink{}, false
+	}
+	l, ok := v.Any().(Hyperlink)
+	return l, ok
+}
+
+// OSC 8 sequences around the text of a hyperlink.
+const (
+	linkStart = "\x1b]8;;"
+	linkEnd   = "\x1b\\"
+	linkClose = linkStart + linkEnd
+)
+
+// appendLink appends text as an OSC 8 hyperlink to url.
+func appendLink(b []byte, url string, text []byte) []byte {
+	b = append(b, linkStart...)
+	b = append(b, url...)
+	b = append(b, linkEnd...)
+	b = append(b, text...)
+	return append(b, linkClose...)
+}
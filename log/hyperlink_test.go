@@ -0,0 +1,146 @@
+package log
+
+import (
+	"bytes"
+	"log/slog"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestDetectHyperlinks(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		want bool
+	}{
+		{name: "unknown", env: map[string]string{"TERM": "xterm-256color"}, want: false},
+		{name: "forced", env: map[string]string{"FORCE_HYPERLINK": "1"}, want: true},
+		{name: "forced off", env: map[string]string{"FORCE_HYPERLINK": "0", "WT_SESSION": "x"}, want: false},
+		{name: "windows terminal", env: map[string]string{"WT_SESSION": "x"}, want: true},
+		{name: "iterm", env: map[string]string{"TERM_PROGRAM": "iTerm.app"}, want: true},
+		{name: "new vte", env: map[string]string{"VTE_VERSION": "6003"}, want: true},
+		{name: "old vte", env: map[string]string{"VTE_VERSION": "4205"}, want: false},
+		{name: "kitty", env: map[string]string{"TERM": "xterm-kitty"}, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, k := range []string{"FORCE_HYPERLINK", "WT_SESSION", "KITTY_WINDOW_ID", "WEZTERM_EXECUTABLE", "DOMTERM", "TERM_PROGRAM", "VTE_VERSION", "TERM"} {
+				t.Setenv(k, tt.env[k])
+			}
+			if got := DetectHyperlinks(); got != tt.want {
+				t.Errorf("DetectHyperlinks() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_callerURL(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		file     string
+		want     string
+	}{
+		{name: "file", template: CallerLinkFile, file: "/src/app/main.go", want: "file:///src/app/main.go"},
+		{name: "vscode", template: CallerLinkVSCode, file: "/src/app/main.go", want: "vscode://file/src/app/main.go:42"},
+		{name: "windows path", template: CallerLinkFile, file: "C:/src/main.go", want: "file:///C:/src/main.go"},
+		{name: "escaped", template: "{path}#{func}", file: "/my src/100%.go", want: "/my%20src/100%25.go#main.main"},
+		{name: "custom", template: "https://git.example.com/blob/main{path}#L{line}", file: "/x.go", want: "https://git.example.com/blob/main/x.go#L42"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := callerURL(tt.template, tt.file, 42, "main.main"); got != tt.want {
+				t.Errorf("callerURL() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCLIHandler_callerLink(t *testing.T) {
+	_, file, _, _ := runtime.Caller(0)
+	url := "file://" + escapeURL(filepath.ToSlash(file), true)
+	tests := []struct {
+		name string
+		opts []CLIHandlerOption
+		want string
+	}{
+		{
+			name: "link",
+			opts: []CLIHandlerOption{WithCallerLink(CallerLinkFile), WithColorMode(ColorAlways)},
+			want: "[INF] <" + linkStart + url + linkEnd + "hyperlink_test.go:LINE" + linkClose + "> msg\n",
+		},
+		{
+			name: "no colors",
+			opts: []CLIHandlerOption{WithCallerLink(CallerLinkFile), WithColorMode(ColorNever)},
+			want: "[INF] <hyperlink_test.go:LINE> msg\n",
+		},
+		{
+			name: "disabled",
+			opts: []CLIHandlerOption{WithCallerLink(""), WithColorMode(ColorAlways)},
+			want: "[INF] <hyperlink_test.go:LINE> msg\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			opts := append([]CLIHandlerOption{WithStyle(Style0()), WithCaller(true)}, tt.opts...)
+			l := NewLogger(NewCLIHandler(buf, opts...))
+			_, _, line, _ := runtime.Caller(0)
+			l.Info("msg")
+			want := strings.ReplaceAll(tt.want, "LINE", strconv.Itoa(line+1))
+			if got := buf.String(); got != want {
+				t.Errorf("got %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestCLIHandler_Link(t *testing.T) {
+	tests := []struct {
+		name string
+		mode ColorMode
+		want string
+	}{
+		{
+			name: "link",
+			mode: ColorAlways,
+			want: "[INF] msg pr=" + linkStart + "https://example.com/pr/1?q=a%20b" + linkEnd + "\"#1 fix\"" + linkClose + " n=1\n",
+		},
+		{
+			name: "fallback",
+			mode: ColorNever,
+			want: "[INF] msg pr=\"#1 fix\" n=1\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			l := NewLogger(NewCLIHandler(buf, WithStyle(Style0()), WithColorMode(tt.mode)))
+			l.Info("msg", Link("pr", "#1 fix", "https://example.com/pr/1?q=a b"), "n", 1)
+			if got := buf.String(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHyperlink_String(t *testing.T) {
+	buf := &bytes.Buffer{}
+	slog.New(slog.NewTextHandler(buf, nil)).Info("msg", Link("doc", "docs", "https://example.com"))
+	if !strings.Contains(buf.String(), "doc=docs") {
+		t.Errorf("got %q, want the text of the link", buf.String())
+	}
+}
+
+func TestCLIHandler_Link_width(t *testing.T) {
+	buf := &bytes.Buffer{}
+	l := NewLogger(NewCLIHandler(buf, WithStyle(NewStyle(WithAttrWidth(3))), WithColorMode(ColorAlways)))
+	l.Info("msg", Link("a", "b", "https://example.com"), "c", 1)
+	want := "[INF] msg a  =" + linkStart + "https://example.com" + linkEnd + "b" + linkClose + " c  =1\n"
+	if got := buf.String(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
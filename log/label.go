@@ -0,0 +1,90 @@
+package log
+
+import (
+	"log/slog"
+	"slices"
+)
+
+// LabelKey is the key of the label in logfmt output and of the attribute
+// returned by Label.
+const LabelKey = "label"
+
+// LabelSetter is implemented by handlers whose label can be changed at runtime.
+type LabelSetter interface {
+	SetLabel(label string)
+}
+
+// labelState is the label of a CLIHandler and its rendered header, shared with
+// the handlers derived from it.
+type labelState struct {
+	text   string
+	header []byte
+}
+
+// labelValue is the value of the LabelKey attribute returned by Label. Handlers
+// other than CLIHandler write it as the text.
+type labelValue string
+
+// LogValue implements slog.LogValuer.
+func (v labelValue) LogValue() slog.Value {
+	return slog.StringValue(string(v))
+}
+
+// Label returns an attribute that replaces the label of the handler for a single
+// record, e.g. to annotate the output of a subcommand:
+//
+//	logger.Info("synced", log.Label("sync"))
+//
+// CLIHandler writes it in place of the label rather than as an attribute. Given
+// to With, it sets the label of the derived logger:
+//
+//	sub := logger.With(log.Label("sync"))
+func Label(text string) slog.Attr {
+	return slog.Any(LabelKey, labelValue(text))
+}
+
+// labelOf returns the text of the label attribute of the record written by Label.
+func labelOf(r slog.Record) (string, bool) {
+	var text string
+	var ok bool
+	r.Attrs(func(a slog.Attr) bool {
+		text, ok = labelAttr(a)
+		return !ok
+	})
+	return text, ok
+}
+
+// labelAttr returns the text of attr if it is the attribute written by Label.
+func labelAttr(attr slog.Attr) (string, bool) {
+	if attr.Key != LabelKey || attr.Value.Kind() != slog.KindLogValuer {
+		return "", false
+	}
+	v, ok := attr.Value.Any().(labelValue)
+	return string(v), ok
+}
+
+// withLabel sets the label of h to the text of the last label attribute in
+// attrs, if any, and returns the other attributes. The label is the handler's
+// own: SetLabel on h or the handlers derived from it does not change the label
+// of the handler it was derived from.
+func (h *CLIHandler) withLabel(attrs []slog.Attr) []slog.Attr {
+	if !slices.ContainsFunc(attrs, isLabelAttr) {
+		return attrs
+	}
+	rest := make([]slog.Attr, 0, len(attrs)-1)
+	for _, a := range attrs {
+		if text, ok := labelAttr(a); ok {
+			h.label = nil
+			h.SetLabel(text)
+			continue
+		}
+		rest = append(rest, a)
+	}
+	return rest
+}
+
+// isLabelAttr reports whether attr is the attribute written by Label.
+func isLabelAttr(attr slog.Attr) bool {
+	_, ok := labelAttr(attr)
+	return ok
+}
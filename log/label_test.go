@@ -0,0 +1,143 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+func TestCLIHandler_SetLabel(t *testing.T) {
+	buf := &bytes.Buffer{}
+	h := NewCLIHandler(buf, WithStyle(Style0()), WithLabel("app"))
+	l := NewLogger(h)
+	child := l.With("k", "v")
+	l.Info("a")
+	if !l.SetLabel("sync") {
+		t.Fatal("SetLabel() = false, want true")
+	}
+	child.Info("b")
+	l.SetLabel("")
+	l.Info("c")
+	want := "[INF] app a\n[INF] sync b k=v\n[INF] c\n"
+	if got := buf.String(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestLabel(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []CLIHandlerOption
+		args []any
+		want string
+	}{
+		{
+			name: "replaces the label",
+			opts: []CLIHandlerOption{WithLabel("app")},
+			args: []any{Label("sync"), "k", "v"},
+			want: "[INF] sync msg k=v\n",
+		},
+		{
+			name: "without a label",
+			args: []any{"k", "v", Label("sync")},
+			want: "[INF] sync msg k=v\n",
+		},
+		{
+			name: "plain attr",
+			opts: []CLIHandlerOption{WithLabel("app")},
+			args: []any{LabelKey, "sync"},
+			want: "[INF] app msg label=sync\n",
+		},
+		{
+			name: "multiline",
+			opts: []CLIHandlerOption{WithLabel("app"), WithMultiline(true)},
+			args: []any{Label("sync"), "k", "v"},
+			want: "[INF] sync msg\n  k=v\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			l := NewLogger(NewCLIHandler(buf, append([]CLIHandlerOption{WithStyle(Style0())}, tt.opts...)...))
+			l.Info("msg", tt.args...)
+			if got := buf.String(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLabel_with(t *testing.T) {
+	buf := &bytes.Buffer{}
+	l := NewLogger(NewCLIHandler(buf, WithStyle(Style0()), WithLabel("app")))
+	sub := l.With(Label("sub"), "k", "v")
+	only := l.With(Label("only"))
+	l.Info("a")
+	sub.Info("b")
+	only.WithGroup("g").Info("c", "x", 1)
+	sub.Info("d", Label("rec"))
+	sub.SetLabel("changed")
+	sub.Info("e")
+	l.Info("f")
+	want := "[INF] app a\n[INF] sub b k=v\n[INF] only c g.x=1\n[INF] rec d k=v\n[INF] changed e k=v\n[INF] app f\n"
+	if got := buf.String(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	buf.Reset()
+	h := NewLogfmtHandler(buf).WithAttrs([]slog.Attr{Label("sub")})
+	_ = h.Handle(context.Background(), slog.NewRecord(time.Time{}, slog.LevelInfo, "msg", 0))
+	if want := "level=INFO msg=msg label=sub\n"; buf.String() != want {
+		t.Errorf("got %q, want %q", buf.String(), want)
+	}
+}
+
+func TestLabel_logfmt(t *testing.T) {
+	buf := &bytes.Buffer{}
+	h := NewLogfmtHandler(buf, WithLabel("app"))
+	_ = h.Handle(context.Background(), slog.NewRecord(time.Time{}, slog.LevelInfo, "a", 0))
+	r := slog.NewRecord(time.Time{}, slog.LevelInfo, "b", 0)
+	r.AddAttrs(Label("sync"))
+	_ = h.Handle(context.Background(), r)
+	want := "level=INFO msg=a label=app\nlevel=INFO msg=b label=sync\n"
+	if got := buf.String(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestLabel_otherHandlers(t *testing.T) {
+	buf := &bytes.Buffer{}
+	slog.New(slog.NewJSONHandler(buf, &slog.HandlerOptions{
+		ReplaceAttr: func(_ []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	})).InfoContext(context.Background(), "msg", Label("sync"))
+	var got map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got[LabelKey] != "sync" {
+		t.Errorf("label = %v, want sync", got[LabelKey])
+	}
+}
+
+func TestMultiHandler_SetLabel(t *testing.T) {
+	buf1, buf2 := &bytes.Buffer{}, &bytes.Buffer{}
+	l := NewLogger(NewMultiHandler(
+		NewCLIHandler(buf1, WithStyle(Style0())),
+		NewCLIHandler(buf2, WithStyle(Style0())),
+	))
+	l.SetLabel("app")
+	l.Info("msg")
+	for _, buf := range []*bytes.Buffer{buf1, buf2} {
+		if got := buf.String(); got != "[INF] app msg\n" {
+			t.Errorf("got %q, want %q", got, "[INF] app msg\n")
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package log
+
+import (
+	"flag"
+	"fmt"
+	"log/slog"
+	"strings"
+)
+
+var _ flag.Value = (*LevelFlag)(nil)
+
+// ParseLevel parses a level name such as "debug", "INFO", "warn+2", "trace" or
+// "fatal", case-insensitively, as read from a flag or an environment variable.
+func ParseLevel(s string) (slog.Leveler, error) {
+	level, err := parseLevel(strings.TrimSpace(s))
+	if err != nil {
+		return nil, fmt.Errorf("invalid level %q: %w", s, err)
+	}
+	return level, nil
+}
+
+// VerbosityLevel returns the minimum level for a verbosity count as set by the
+// -q and -v flags of a command: a negative count, as for -q, is slog.LevelError,
+// zero is slog.LevelInfo, one, as for -v, is slog.LevelDebug, and two or more,
+// as for -vv, is LevelTrace.
+func VerbosityLevel(n int) slog.Level {
+	switch {
+	case n < 0:
+		return slog.LevelError
+	case n == 0:
+		return slog.LevelInfo
+	case n == 1:
+		return slog.LevelDebug
+	default:
+		return LevelTrace
+	}
+}
+
+// WithVerbosity returns a CLIHandlerOption that sets the minimum level for a
+// verbosity count as VerbosityLevel does, e.g. the number of -v flags, or -1
+// for -q.
+func WithVerbosity(n int) CLIHandlerOption {
+	return WithLevel(VerbosityLevel(n))
+}
+
+// LevelFlag is a level that implements flag.Value and the Type method of
+// pflag.Value, so that a --log-level flag can be passed to WithLevel directly:
+//
+//	var level log.LevelFlag
+//	flag.Var(&level, "log-level", "minimum level")
+//	flag.Parse()
+//	h := log.NewCLIHandler(os.Stderr, log.WithLevel(&level))
+//
+// As a slog.Leveler it reports the level last set, slog.LevelInfo by default,
+// so a handler created before the flags are parsed follows it.
+type LevelFlag struct {
+	v slog.LevelVar
+}
+
+// NewLevelFlag creates a new LevelFlag with the given default level.
+func NewLevelFlag(level slog.Level) *LevelFlag {
+	f := &LevelFlag{}
+	f.v.Set(level)
+	return f
+}
+
+// Level implements slog.Leveler.
+func (f *LevelFlag) Level() slog.Level {
+	return f.v.Level()
+}
+
+// String implements flag.Value, returning the level in the form parsed by Set.
+func (f *LevelFlag) String() string {
+	if f == nil {
+		return levelName(slog.LevelInfo)
+	}
+	return levelName(f.v.Level())
+}
+
+// Set implements flag.Value, parsing the level as ParseLevel does.
+func (f *LevelFlag) Set(s string) error {
+	level, err := ParseLevel(s)
+	if err != nil {
+		return err
+	}
+	f.v.Set(level.Level())
+	return nil
+}
+
+// Type implements pflag.Value.
+func (f *LevelFlag) Type() string {
+	return "level"
+}
@@ -0,0 +1,124 @@
+package log
+
+import (
+	"bytes"
+	"flag"
+	"io"
+	"log/slog"
+	"testing"
+)
+
+func TestParseLevel(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    slog.Level
+		wantErr bool
+	}{
+		{in: "debug", want: slog.LevelDebug},
+		{in: "INFO", want: slog.LevelInfo},
+		{in: "warn", want: slog.LevelWarn},
+		{in: "error", want: slog.LevelError},
+		{in: "trace", want: LevelTrace},
+		{in: " notice ", want: LevelNotice},
+		{in: "fatal", want: LevelFatal},
+		{in: "warn+2", want: slog.LevelWarn + 2},
+		{in: "", wantErr: true},
+		{in: "verbose", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseLevel(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got.Level() != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLevelFlag(t *testing.T) {
+	var level LevelFlag
+	if got := level.Level(); got != slog.LevelInfo {
+		t.Errorf("default = %v, want %v", got, slog.LevelInfo)
+	}
+	buf := &bytes.Buffer{}
+	l := NewLogger(NewCLIHandler(buf, WithStyle(Style0()), WithLevel(&level)))
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	fs.Var(&level, "log-level", "minimum level")
+	if err := fs.Parse([]string{"--log-level", "trace"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := level.String(); got != "trace" {
+		t.Errorf("String() = %q, want %q", got, "trace")
+	}
+	l.Trace("msg")
+	if got := buf.String(); got != "[TRC] msg\n" {
+		t.Errorf("got %q, want %q", got, "[TRC] msg\n")
+	}
+
+	if err := fs.Parse([]string{"--log-level", "verbose"}); err == nil {
+		t.Error("want error for an invalid level")
+	}
+	if got := level.Level(); got != LevelTrace {
+		t.Errorf("level = %v after invalid value, want %v", got, LevelTrace)
+	}
+	if got := level.Type(); got != "level" {
+		t.Errorf("Type() = %q, want %q", got, "level")
+	}
+}
+
+func TestNewLevelFlag(t *testing.T) {
+	f := NewLevelFlag(slog.LevelWarn)
+	if got := f.String(); got != "warn" {
+		t.Errorf("String() = %q, want %q", got, "warn")
+	}
+	if got := (*LevelFlag)(nil).String(); got != "info" {
+		t.Errorf("nil String() = %q, want %q", got, "info")
+	}
+}
+
+func TestVerbosityLevel(t *testing.T) {
+	tests := []struct {
+		n    int
+		want slog.Level
+	}{
+		{n: -2, want: slog.LevelError},
+		{n: -1, want: slog.LevelError},
+		{n: 0, want: slog.LevelInfo},
+		{n: 1, want: slog.LevelDebug},
+		{n: 2, want: LevelTrace},
+		{n: 5, want: LevelTrace},
+	}
+	for _, tt := range tests {
+		if got := VerbosityLevel(tt.n); got != tt.want {
+			t.Errorf("VerbosityLevel(%d) = %v, want %v", tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestWithVerbosity(t *testing.T) {
+	tests := []struct {
+		n    int
+		want string
+	}{
+		{n: -1, want: "[ERR] error\n"},
+		{n: 0, want: "[INF] info\n[ERR] error\n"},
+		{n: 1, want: "[DBG] debug\n[INF] info\n[ERR] error\n"},
+		{n: 2, want: "[TRC] trace\n[DBG] debug\n[INF] info\n[ERR] error\n"},
+	}
+	for _, tt := range tests {
+		buf := &bytes.Buffer{}
+		l := NewLogger(NewCLIHandler(buf, WithStyle(Style0()), WithVerbosity(tt.n)))
+		l.Trace("trace")
+		l.Debug("debug")
+		l.Info("info")
+		l.Error("error")
+		if got := buf.String(); got != tt.want {
+			t.Errorf("WithVerbosity(%d): got %q, want %q", tt.n, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package log
+
+import (
+	"errors"
+	"log/slog"
+	"slices"
+	"unicode/utf8"
+)
+
+// TruncatedKey is the attribute key that marks the records whose attributes were
+// cut by WithMaxAttrValueLen or WithMaxAttrs.
+const TruncatedKey = "truncated"
+
+// WithMaxAttrValueLen returns a CLIHandlerOption that cuts attribute values
+// longer than n characters to n, marked by an ellipsis, and marks the record with
+// truncated=true. Strings, byte slices, errors and other values are cut as they
+// would be written. Zero disables the limit.
+func WithMaxAttrValueLen(n int) CLIHandlerOption {
+	return func(c *CLIHandler) {
+		c.maxValueLen = max(0, n)
+	}
+}
+
+// WithMaxAttrs returns a CLIHandlerOption that writes at most n attributes of a
+// record, counting the members of groups, and marks the record with
+// truncated=true if any are dropped. The handler attributes come first, then the
+// context attributes and the record attributes. Zero disables the limit.
+func WithMaxAttrs(n int) CLIHandlerOption {
+	return func(c *CLIHandler) {
+		c.maxAttrs = max(0, n)
+	}
+}
+
+// hasLimits reports whether the attributes of a record are limited.
+func (h *CLIHandler) hasLimits() bool {
+	return h.maxValueLen > 0 || h.maxAttrs > 0
+}
+
+// limitAttrs applies the limits of the handler to attrs and reports whether
+// anything was cut. attrs is changed in place; the members of groups are copied.
+func (h *CLIHandler) limitAttrs(attrs []slog.Attr) ([]slog.Attr, bool) {
+	cut := false
+	if h.maxAttrs > 0 {
+		n := h.maxAttrs
+		attrs, cut = takeAttrs(attrs, &n)
+	}
+	if h.maxValueLen > 0 {
+		for i, a := range attrs {
+			if v, ok := h.limitValue(a.Value); ok {
+				attrs[i].Value = v
+				cut = true
+			}
+		}
+	}
+	return attrs, cut
+}
+
+// takeAttrs returns the first *n leaves of attrs, keeping the groups they are
+// in, and reports whether any were dropped. *n is decreased by the leaves taken.
+// attrs is not changed.
+func takeAttrs(attrs []slog.Attr, n *int) ([]slog.Attr, bool) {
+	for i, a := range attrs {
+		if *n == 0 {
+			return attrs[:i], true
+		}
+		v := a.Value.Resolve()
+		if v.Kind() != slog.KindGroup {
+			*n--
+			continue
+		}
+		members, cut := takeAttrs(v.Group(), n)
+		if !cut {
+			continue
+		}
+		if len(members) == 0 {
+			return attrs[:i], true
+		}
+		// attrs may be the members of a group of the caller, so the cut
+		// group is set in a copy.
+		attrs = slices.Clone(attrs[:i+1])
+		attrs[i].Value = slog.GroupValue(members...)
+		return attrs, true
+	}
+	return attrs, false
+}
+
+// limitValue returns v cut to the length limit of the handler, and reports
+// whether it was cut.
+func (h *CLIHandler) limitValue(v slog.Value) (slog.Value, bool) {
+	v = v.Resolve()
+	n := h.maxValueLen
+	switch v.Kind() {
+	case slog.KindString:
+		if s, ok := shorten(v.String(), n); ok {
+			return slog.StringValue(s), true
+		}
+	case slog.KindGroup:
+		var out []slog.Attr
+		for i, m := range v.Group() {
+			mv, ok := h.limitValue(m.Value)
+			if !ok {
+				continue
+			}
+			if out == nil {
+				out = slices.Clone(v.Group())
+			}
+			out[i].Value = mv
+		}
+		if out != nil {
+			return slog.GroupValue(out...), true
+		}
+	case slog.KindAny:
+		if _, ok := hyperlinkValue(v); ok {
+			return v, false
+		}
+		if fv, ok := h.flattenAny(v); ok {
+			return h.limitValue(fv)
+		}
+		switch x := v.Any().(type) {
+		case []byte:
+			if f := h.style.Attr.Bytes; f != BytesLength && len(x) > n/2 {
+				if s, ok := shorten(encodeBytes(x, f), n); ok {
+					return slog.StringValue(s), true
+				}
+			}
+		case error:
+			if s, ok := shorten(x.Error(), n); ok {
+				return slog.AnyValue(errors.New(s)), true
+			}
+		default:
+			text, ok := h.formatAny(v)
+			if !ok {
+				text = v.String()
+			}
+			if s, ok := shorten(text, n); ok {
+				return slog.StringValue(s), true
+			}
+		}
+	}
+	return v, false
+}
+
+// shorten returns s cut to n characters followed by an ellipsis, and reports
+// whether s was longer than n characters.
+func shorten(s string, n int) (string, bool) {
+	if len(s) <= n || utf8.RuneCountInString(s) <= n {
+		return s, false
+	}
+	i := 0
+	for j := range s {
+		if i == n {
+			return s[:j] + ellipsis, true
+		}
+		i++
+	}
+	return s, false
+}
+
+// limitLines applies the limits of the handler to the attributes of multiline
+// output and reports whether anything was cut.
+func (h *CLIHandler) limitLines(attrs []lineAttr) ([]lineAttr, bool) {
+	cut := false
+	if h.maxAttrs > 0 && len(attrs) > h.maxAttrs {
+		attrs, cut = attrs[:h.maxAttrs], true
+	}
+	if h.maxValueLen > 0 {
+		for i, a := range attrs {
+			if v, ok := h.limitValue(a.attr.Value); ok {
+				attrs[i].attr.Value = v
+				cut = true
+			}
+		}
+	}
+	return attrs, cut
+}
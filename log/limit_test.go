@@ -0,0 +1,151 @@
+package log
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestCLIHandler_Handle_limits(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []CLIHandlerOption
+		log  func(l *Logger)
+		want string
+	}{
+		{
+			name: "value within limit",
+			opts: []CLIHandlerOption{WithMaxAttrValueLen(5)},
+			log:  func(l *Logger) { l.Info("msg", "s", "hello", "n", 1234567) },
+			want: "[INF] msg s=hello n=1234567\n",
+		},
+		{
+			name: "string",
+			opts: []CLIHandlerOption{WithMaxAttrValueLen(5)},
+			log:  func(l *Logger) { l.Info("msg", "s", "hello world") },
+			want: "[INF] msg s=hello… truncated=true\n",
+		},
+		{
+			name: "wide characters",
+			opts: []CLIHandlerOption{WithMaxAttrValueLen(2)},
+			log:  func(l *Logger) { l.Info("msg", "s", "日本語") },
+			want: "[INF] msg s=日本… truncated=true\n",
+		},
+		{
+			name: "bytes",
+			opts: []CLIHandlerOption{WithMaxAttrValueLen(3)},
+			log:  func(l *Logger) { l.Info("msg", "b", []byte("abcdef")) },
+			want: "[INF] msg b=abc… truncated=true\n",
+		},
+		{
+			name: "error",
+			opts: []CLIHandlerOption{WithMaxAttrValueLen(4)},
+			log:  func(l *Logger) { l.Info("msg", "err", errors.New("failed")) },
+			want: "[INF] msg err=fail… truncated=true\n",
+		},
+		{
+			name: "any",
+			opts: []CLIHandlerOption{WithMaxAttrValueLen(4)},
+			log:  func(l *Logger) { l.Info("msg", "v", []int{1, 2, 3}) },
+			want: "[INF] msg v=\"[1 2…\" truncated=true\n",
+		},
+		{
+			name: "group",
+			opts: []CLIHandlerOption{WithMaxAttrValueLen(2)},
+			log:  func(l *Logger) { l.Info("msg", slog.Group("g", "a", "xyz", "b", "x")) },
+			want: "[INF] msg g.a=xy… g.b=x truncated=true\n",
+		},
+		{
+			name: "attrs within limit",
+			opts: []CLIHandlerOption{WithMaxAttrs(2)},
+			log:  func(l *Logger) { l.Info("msg", "a", 1, "b", 2) },
+			want: "[INF] msg a=1 b=2\n",
+		},
+		{
+			name: "attrs",
+			opts: []CLIHandlerOption{WithMaxAttrs(2)},
+			log: func(l *Logger) {
+				NewLogger(l.Handler().WithAttrs([]slog.Attr{slog.Int("a", 1)})).Info("msg", "b", 2, "c", 3)
+			},
+			want: "[INF] msg a=1 b=2 truncated=true\n",
+		},
+		{
+			name: "attrs in groups",
+			opts: []CLIHandlerOption{WithMaxAttrs(2)},
+			log:  func(l *Logger) { l.Info("msg", "a", 1, slog.Group("g", "b", 2, "c", 3), "d", 4) },
+			want: "[INF] msg a=1 g.b=2 truncated=true\n",
+		},
+		{
+			name: "attrs in open groups",
+			opts: []CLIHandlerOption{WithMaxAttrs(1)},
+			log:  func(l *Logger) { NewLogger(l.Handler().WithGroup("g")).Info("msg", "a", 1, "b", 2) },
+			want: "[INF] msg g.a=1 truncated=true\n",
+		},
+		{
+			name: "multiline",
+			opts: []CLIHandlerOption{WithMaxAttrs(1), WithMaxAttrValueLen(3), WithMultiline(true)},
+			log:  func(l *Logger) { l.Info("msg", "a", "abcd", "b", 2) },
+			want: "[INF] msg\n  a        =abc…\n  truncated=true\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			opts := append([]CLIHandlerOption{WithStyle(Style0())}, tt.opts...)
+			tt.log(NewLogger(NewCLIHandler(buf, opts...)))
+			if got := buf.String(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCLIHandler_Handle_limitsLargeValue(t *testing.T) {
+	buf := &bytes.Buffer{}
+	l := NewLogger(NewCLIHandler(buf, WithStyle(Style0()), WithMaxAttrValueLen(16)))
+	l.Info("msg", "payload", strings.Repeat("x", 1<<20))
+	if buf.Len() > 64 {
+		t.Errorf("got %d bytes, want value cut", buf.Len())
+	}
+}
+
+func TestCLIHandler_Handle_limitsSharedGroup(t *testing.T) {
+	shared := slog.Group("g", "a", 1, "b", 2, slog.Group("h", "c", 3, "d", 4))
+	buf := &bytes.Buffer{}
+	NewLogger(NewCLIHandler(buf, WithStyle(Style0()), WithMaxAttrs(3))).Info("msg", shared)
+	if want := "[INF] msg g.a=1 g.b=2 g.h.c=3 truncated=true\n"; buf.String() != want {
+		t.Errorf("got %q, want %q", buf.String(), want)
+	}
+	if got, want := shared.String(), "g=[a=1 b=2 h=[c=3 d=4]]"; got != want {
+		t.Errorf("group = %s after logging, want %s", got, want)
+	}
+
+	buf.Reset()
+	NewLogger(NewCLIHandler(buf, WithStyle(Style0()))).Info("msg", shared)
+	if want := "[INF] msg g.a=1 g.b=2 g.h.c=3 g.h.d=4\n"; buf.String() != want {
+		t.Errorf("got %q, want %q", buf.String(), want)
+	}
+}
+
+func Test_shorten(t *testing.T) {
+	tests := []struct {
+		s    string
+		n    int
+		want string
+		cut  bool
+	}{
+		{s: "", n: 1, want: ""},
+		{s: "abc", n: 3, want: "abc"},
+		{s: "abcd", n: 3, want: "abc…", cut: true},
+		{s: "日本語", n: 3, want: "日本語"},
+		{s: "日本語", n: 1, want: "日…", cut: true},
+	}
+	for _, tt := range tests {
+		got, cut := shorten(tt.s, tt.n)
+		if got != tt.want || cut != tt.cut {
+			t.Errorf("shorten(%q, %d) = %q, %v, want %q, %v", tt.s, tt.n, got, cut, tt.want, tt.cut)
+		}
+	}
+}
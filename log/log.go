@@ -1,19 +1,308 @@
 package log
 
 import (
+	"context"
+	"fmt"
 	"io"
 	"log/slog"
+	"os"
+	"runtime"
+	"time"
+)
+
+// Levels in addition to those of slog, named "trace", "notice" and "fatal" by
+// level parsing and given their own style by the built-in styles.
+const (
+	// LevelTrace is the level used by Logger.Trace. It is below slog.LevelDebug.
+	LevelTrace = slog.Level(-8)
+
+	// LevelNotice is the level used by Logger.Notice. It is between
+	// slog.LevelInfo and slog.LevelWarn.
+	LevelNotice = slog.Level(2)
+
+	// LevelFatal is the level used by Logger.Fatal. It is above slog.LevelError.
+	LevelFatal = slog.Level(12)
 )
 
 // Logger is a logger for the application.
 type Logger struct {
 	*slog.Logger
+	exit func(code int)
+	skip int
 }
 
 // NewLogger creates a new logger for the application.
-func NewLogger(handler slog.Handler) *Logger {
+func NewLogger(handler slog.Handler, opts ...LoggerOption) *Logger {
 	if handler == nil {
 		handler = NewCLIHandler(io.Discard)
 	}
-	return &Logger{slog.New(handler)}
+	l := &Logger{Logger: slog.New(handler)}
+	for _, opt := range opts {
+		opt(l)
+	}
+	return l
+}
+
+// LoggerOption defines a function type for configuring a Logger.
+type LoggerOption func(*Logger)
+
+// WithExitFunc returns a LoggerOption that sets the function called by Fatal.
+// The default is os.Exit.
+func WithExitFunc(fn func(code int)) LoggerOption {
+	return func(l *Logger) {
+		if fn != nil {
+			l.exit = fn
+		}
+	}
+}
+
+// WithCallerSkip returns a copy of the logger that reports the caller n frames
+// above the caller of its logging methods. Use it in helper functions that wrap
+// the logger so that records point at the code calling the helper.
+func (l *Logger) WithCallerSkip(n int) *Logger {
+	l2 := *l
+	l2.skip = max(0, l.skip+n)
+	return &l2
+}
+
+// With returns a copy of the logger that includes the given attributes in each
+// record, with the semantics of slog.Logger.With.
+func (l *Logger) With(args ...any) *Logger {
+	if len(args) == 0 {
+		return l
+	}
+	l2 := *l
+	l2.Logger = l.Logger.With(args...)
+	return &l2
+}
+
+// WithAttrs returns a copy of the logger that includes the given attributes in each record.
+func (l *Logger) WithAttrs(attrs ...slog.Attr) *Logger {
+	if len(attrs) == 0 {
+		return l
+	}
+	l2 := *l
+	l2.Logger = slog.New(l.Handler().WithAttrs(attrs))
+	return &l2
+}
+
+// WithGroup returns a copy of the logger that starts a group, with the
+// semantics of slog.Logger.WithGroup.
+func (l *Logger) WithGroup(name string) *Logger {
+	if name == "" {
+		return l
+	}
+	l2 := *l
+	l2.Logger = l.Logger.WithGroup(name)
+	return &l2
+}
+
+// LevelSetter is implemented by handlers whose minimum level can be changed at runtime.
+type LevelSetter interface {
+	SetLevel(level slog.Leveler)
+}
+
+// SetLevel changes the minimum level of the underlying handler at runtime.
+// It reports whether the handler implements LevelSetter.
+func (l *Logger) SetLevel(level slog.Leveler) bool {
+	s, ok := l.Handler().(LevelSetter)
+	if ok {
+		s.SetLevel(level)
+	}
+	return ok
+}
+
+// SetLabel changes the label of the underlying handler at runtime.
+// It reports whether the handler implements LabelSetter.
+func (l *Logger) SetLabel(label string) bool {
+	s, ok := l.Handler().(LabelSetter)
+	if ok {
+		s.SetLabel(label)
+	}
+	return ok
+}
+
+// Flush flushes the underlying handler and the handlers it wraps. See Flush.
+func (l *Logger) Flush() error {
+	return Flush(l.Handler())
+}
+
+// Close closes the underlying handler and the handlers it wraps. Call it before
+// the program exits so that buffered records are written. See Close.
+func (l *Logger) Close() error {
+	return Close(l.Handler())
+}
+
+// Trace logs at LevelTrace.
+func (l *Logger) Trace(msg string, args ...any) {
+	l.log(context.Background(), LevelTrace, msg, args...)
+}
+
+// TraceContext logs at LevelTrace with the given context.
+func (l *Logger) TraceContext(ctx context.Context, msg string, args ...any) {
+	l.log(ctx, LevelTrace, msg, args...)
+}
+
+// Debug logs at slog.LevelDebug.
+func (l *Logger) Debug(msg string, args ...any) {
+	l.log(context.Background(), slog.LevelDebug, msg, args...)
+}
+
+// DebugContext logs at slog.LevelDebug with the given context.
+func (l *Logger) DebugContext(ctx context.Context, msg string, args ...any) {
+	l.log(ctx, slog.LevelDebug, msg, args...)
+}
+
+// Info logs at slog.LevelInfo.
+func (l *Logger) Info(msg string, args ...any) {
+	l.log(context.Background(), slog.LevelInfo, msg, args...)
+}
+
+// InfoContext logs at slog.LevelInfo with the given context.
+func (l *Logger) InfoContext(ctx context.Context, msg string, args ...any) {
+	l.log(ctx, slog.LevelInfo, msg, args...)
+}
+
+// Notice logs at LevelNotice.
+func (l *Logger) Notice(msg string, args ...any) {
+	l.log(context.Background(), LevelNotice, msg, args...)
+}
+
+// NoticeContext logs at LevelNotice with the given context.
+func (l *Logger) NoticeContext(ctx context.Context, msg string, args ...any) {
+	l.log(ctx, LevelNotice, msg, args...)
+}
+
+// Warn logs at slog.LevelWarn.
+func (l *Logger) Warn(msg string, args ...any) {
+	l.log(context.Background(), slog.LevelWarn, msg, args...)
+}
+
+// WarnContext logs at slog.LevelWarn with the given context.
+func (l *Logger) WarnContext(ctx context.Context, msg string, args ...any) {
+	l.log(ctx, slog.LevelWarn, msg, args...)
+}
+
+// Error logs at slog.LevelError.
+func (l *Logger) Error(msg string, args ...any) {
+	l.log(context.Background(), slog.LevelError, msg, args...)
+}
+
+// ErrorContext logs at slog.LevelError with the given context.
+func (l *Logger) ErrorContext(ctx context.Context, msg string, args ...any) {
+	l.log(ctx, slog.LevelError, msg, args...)
+}
+
+// Log logs at the given level with the given context.
+func (l *Logger) Log(ctx context.Context, level slog.Level, msg string, args ...any) {
+	l.log(ctx, level, msg, args...)
+}
+
+// LogAttrs logs attributes at the given level with the given context.
+func (l *Logger) LogAttrs(ctx context.Context, level slog.Level, msg string, attrs ...slog.Attr) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if !l.Enabled(ctx, level) {
+		return
+	}
+	var pcs [1]uintptr
+	runtime.Callers(2+l.skip, pcs[:]) // skip [Callers, LogAttrs]
+	r := slog.NewRecord(time.Now(), level, msg, pcs[0])
+	r.AddAttrs(attrs...)
+	_ = l.Handler().Handle(ctx, r)
+}
+
+// Tracef logs a formatted message at LevelTrace.
+func (l *Logger) Tracef(format string, args ...any) {
+	l.logf(context.Background(), LevelTrace, format, args...)
+}
+
+// Debugf logs a formatted message at slog.LevelDebug.
+func (l *Logger) Debugf(format string, args ...any) {
+	l.logf(context.Background(), slog.LevelDebug, format, args...)
+}
+
+// Infof logs a formatted message at slog.LevelInfo.
+func (l *Logger) Infof(format string, args ...any) {
+	l.logf(context.Background(), slog.LevelInfo, format, args...)
+}
+
+// Noticef logs a formatted message at LevelNotice.
+func (l *Logger) Noticef(format string, args ...any) {
+	l.logf(context.Background(), LevelNotice, format, args...)
+}
+
+// Warnf logs a formatted message at slog.LevelWarn.
+func (l *Logger) Warnf(format string, args ...any) {
+	l.logf(context.Background(), slog.LevelWarn, format, args...)
+}
+
+// Errorf logs a formatted message at slog.LevelError.
+func (l *Logger) Errorf(format string, args ...any) {
+	l.logf(context.Background(), slog.LevelError, format, args...)
+}
+
+// Fatal logs at LevelFatal, flushes the handler and then exits with status 1.
+func (l *Logger) Fatal(msg string, args ...any) {
+	l.log(context.Background(), LevelFatal, msg, args...)
+	_ = l.Flush()
+	l.exitFunc()(1)
+}
+
+// Fatalf logs a formatted message at LevelFatal, flushes the handler and then
+// exits with status 1.
+func (l *Logger) Fatalf(format string, args ...any) {
+	l.logf(context.Background(), LevelFatal, format, args...)
+	_ = l.Flush()
+	l.exitFunc()(1)
+}
+
+// Panic logs at slog.LevelError and then panics with the message.
+func (l *Logger) Panic(msg string, args ...any) {
+	l.log(context.Background(), slog.LevelError, msg, args...)
+	panic(msg)
+}
+
+// Panicf logs a formatted message at slog.LevelError and then panics with the message.
+func (l *Logger) Panicf(format string, args ...any) {
+	msg := fmt.Sprintf(format, args...)
+	l.log(context.Background(), slog.LevelError, msg)
+	panic(msg)
+}
+
+// exitFunc returns the function called by Fatal.
+func (l *Logger) exitFunc() func(int) {
+	if l.exit != nil {
+		return l.exit
+	}
+	return os.Exit
+}
+
+// log emits a record with the caller of the exported method, adjusted by the
+// caller skip, as its source.
+func (l *Logger) log(ctx context.Context, level slog.Level, msg string, args ...any) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if !l.Enabled(ctx, level) {
+		return
+	}
+	var pcs [1]uintptr
+	runtime.Callers(3+l.skip, pcs[:]) // skip [Callers, log, exported method]
+	r := slog.NewRecord(time.Now(), level, msg, pcs[0])
+	r.Add(args...)
+	_ = l.Handler().Handle(ctx, r)
+}
+
+// logf formats and emits a record with the caller of the exported method as its
+// source. The message is only formatted if the level is enabled.
+func (l *Logger) logf(ctx context.Context, level slog.Level, format string, args ...any) {
+	if !l.Enabled(ctx, level) {
+		return
+	}
+	var pcs [1]uintptr
+	runtime.Callers(3+l.skip, pcs[:]) // skip [Callers, logf, exported method]
+	r := slog.NewRecord(time.Now(), level, fmt.Sprintf(format, args...), pcs[0])
+	_ = l.Handler().Handle(ctx, r)
 }
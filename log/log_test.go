@@ -1,9 +1,15 @@
 package log
 
 import (
+	"bytes"
+	"context"
+	"fmt"
 	"io"
 	"log/slog"
 	"reflect"
+	"runtime"
+	"slices"
+	"strings"
 	"testing"
 )
 
@@ -25,7 +31,7 @@ func TestNewLogger(t *testing.T) {
 				}(),
 			},
 			want: &Logger{
-				slog.New(
+				Logger: slog.New(
 					func() slog.Handler {
 						h := NewCLIHandler(io.Discard)
 						return h
@@ -39,7 +45,7 @@ func TestNewLogger(t *testing.T) {
 				handler: nil,
 			},
 			want: &Logger{
-				slog.New(
+				Logger: slog.New(
 					func() slog.Handler {
 						h := NewCLIHandler(io.Discard)
 						return h
@@ -52,14 +58,14 @@ func TestNewLogger(t *testing.T) {
 			args: args{
 				handler: slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}),
 			},
-			want: &Logger{slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))},
+			want: &Logger{Logger: slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))},
 		},
 		{
 			name: "slog json handler",
 			args: args{
 				handler: slog.NewJSONHandler(io.Discard, &slog.HandlerOptions{}),
 			},
-			want: &Logger{slog.New(slog.NewJSONHandler(io.Discard, &slog.HandlerOptions{}))},
+			want: &Logger{Logger: slog.New(slog.NewJSONHandler(io.Discard, &slog.HandlerOptions{}))},
 		},
 	}
 	for _, tt := range tests {
@@ -70,3 +76,341 @@ func TestNewLogger(t *testing.T) {
 		})
 	}
 }
+
+func TestLogger_SetLevel(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler slog.Handler
+		want    bool
+		enabled bool
+	}{
+		{
+			name:    "cli handler",
+			handler: NewCLIHandler(io.Discard, WithLevel(slog.LevelInfo)),
+			want:    true,
+			enabled: true,
+		},
+		{
+			name: "multi handler",
+			handler: NewMultiHandler(
+				NewCLIHandler(io.Discard, WithLevel(slog.LevelInfo)),
+				slog.NewJSONHandler(io.Discard, nil),
+			),
+			want:    true,
+			enabled: true,
+		},
+		{
+			name:    "unsupported handler",
+			handler: slog.NewJSONHandler(io.Discard, nil),
+			want:    false,
+			enabled: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := NewLogger(tt.handler)
+			if got := l.SetLevel(slog.LevelDebug); got != tt.want {
+				t.Errorf("Logger.SetLevel() = %v, want %v", got, tt.want)
+			}
+			if got := l.Enabled(context.Background(), slog.LevelDebug); got != tt.enabled {
+				t.Errorf("Logger.Enabled() = %v, want %v", got, tt.enabled)
+			}
+		})
+	}
+}
+
+func TestNewLogger_options(t *testing.T) {
+	var code int
+	l := NewLogger(nil, WithExitFunc(func(c int) { code = c }), WithExitFunc(nil))
+	l.exitFunc()(2)
+	if code != 2 {
+		t.Errorf("exit code = %v, want 2", code)
+	}
+	if NewLogger(nil).exitFunc() == nil {
+		t.Error("default exit func is nil")
+	}
+}
+
+func TestLogger_Fatal(t *testing.T) {
+	tests := []struct {
+		name string
+		log  func(*Logger)
+		want string
+	}{
+		{
+			name: "fatal",
+			log:  func(l *Logger) { l.Fatal("failed", "key", "val") },
+			want: "[FTL] <log_test.go:",
+		},
+		{
+			name: "fatalf",
+			log:  func(l *Logger) { l.Fatalf("failed: %d", 1) },
+			want: "[FTL] <log_test.go:",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			code := -1
+			l := NewLogger(
+				NewCLIHandler(buf, WithStyle(Style0()), WithCaller(true)),
+				WithExitFunc(func(c int) { code = c }),
+			)
+			tt.log(l)
+			if code != 1 {
+				t.Errorf("exit code = %v, want 1", code)
+			}
+			if got := buf.String(); !strings.HasPrefix(got, tt.want) {
+				t.Errorf("got %q, want prefix %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLogger_Fatal_disabled(t *testing.T) {
+	buf := &bytes.Buffer{}
+	code := -1
+	h := NewCLIHandler(buf, WithLevel(LevelFatal+1))
+	NewLogger(h, WithExitFunc(func(c int) { code = c })).Fatal("failed")
+	if buf.Len() != 0 {
+		t.Errorf("got %q, want empty", buf.String())
+	}
+	if code != 1 {
+		t.Errorf("exit code = %v, want 1", code)
+	}
+}
+
+func TestLogger_Fatal_flush(t *testing.T) {
+	ws, events := newCloseWriters("w")
+	l := NewLogger(NewCLIHandler(ws[0]), WithExitFunc(func(int) {
+		*events = append(*events, "exit")
+	}))
+	l.Fatal("failed")
+	if want := []string{"w:flush", "exit"}; !slices.Equal(*events, want) {
+		t.Errorf("events = %q, want %q", *events, want)
+	}
+}
+
+func TestLogger_Close(t *testing.T) {
+	ws, events := newCloseWriters("w")
+	l := NewLogger(NewCLIHandler(ws[0]))
+	if err := l.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"w:flush", "w:flush", "w:close"}; !slices.Equal(*events, want) {
+		t.Errorf("events = %q, want %q", *events, want)
+	}
+}
+
+func TestLogger_Panic(t *testing.T) {
+	tests := []struct {
+		name      string
+		log       func(*Logger)
+		wantPanic string
+		want      string
+	}{
+		{
+			name:      "panic",
+			log:       func(l *Logger) { l.Panic("broken", "key", "val") },
+			wantPanic: "broken",
+			want:      "[ERR] broken key=val\n",
+		},
+		{
+			name:      "panicf",
+			log:       func(l *Logger) { l.Panicf("broken: %d", 1) },
+			wantPanic: "broken: 1",
+			want:      "[ERR] broken: 1\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			l := NewLogger(NewCLIHandler(buf, WithStyle(Style0())))
+			defer func() {
+				if got := recover(); got != tt.wantPanic {
+					t.Errorf("recover() = %v, want %v", got, tt.wantPanic)
+				}
+				if got := buf.String(); got != tt.want {
+					t.Errorf("got %q, want %q", got, tt.want)
+				}
+			}()
+			tt.log(l)
+		})
+	}
+}
+
+func TestLogger_printf(t *testing.T) {
+	tests := []struct {
+		name string
+		log  func(*Logger)
+		want string
+	}{
+		{
+			name: "tracef",
+			log:  func(l *Logger) { l.Tracef("count: %d", 1) },
+			want: "[TRC] <log_test.go:",
+		},
+		{
+			name: "debugf",
+			log:  func(l *Logger) { l.Debugf("count: %d", 1) },
+			want: "[DBG] <log_test.go:",
+		},
+		{
+			name: "infof",
+			log:  func(l *Logger) { l.Infof("name: %s", "foo") },
+			want: "[INF] <log_test.go:",
+		},
+		{
+			name: "noticef",
+			log:  func(l *Logger) { l.Noticef("name: %s", "foo") },
+			want: "[NTC] <log_test.go:",
+		},
+		{
+			name: "warnf",
+			log:  func(l *Logger) { l.Warnf("ratio: %.1f", 0.5) },
+			want: "[WRN] <log_test.go:",
+		},
+		{
+			name: "errorf",
+			log:  func(l *Logger) { l.Errorf("err: %v", io.EOF) },
+			want: "[ERR] <log_test.go:",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			l := NewLogger(NewCLIHandler(buf, WithStyle(Style0()), WithCaller(true), WithLevel(LevelTrace)))
+			tt.log(l)
+			if got := buf.String(); !strings.HasPrefix(got, tt.want) {
+				t.Errorf("got %q, want prefix %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLogger_printf_message(t *testing.T) {
+	buf := &bytes.Buffer{}
+	l := NewLogger(NewCLIHandler(buf, WithStyle(Style0())))
+	l.Infof("%s=%d", "count", 3)
+	l.Debugf("%s", "skipped")
+	if got := buf.String(); got != "[INF] count=3\n" {
+		t.Errorf("got %q, want %q", got, "[INF] count=3\n")
+	}
+}
+
+// logHelper wraps a logger like an application helper function.
+func logHelper(l *Logger, msg string) {
+	l.WithCallerSkip(1).Info(msg)
+}
+
+func TestLogger_WithCallerSkip(t *testing.T) {
+	buf := &bytes.Buffer{}
+	l := NewLogger(NewCLIHandler(buf, WithStyle(Style0()), WithCaller(true)))
+	_, _, line, _ := runtime.Caller(0)
+	logHelper(l, "hello")
+	want := fmt.Sprintf("[INF] <log_test.go:%d> hello\n", line+1)
+	if got := buf.String(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if l.skip != 0 {
+		t.Errorf("original skip = %d, want 0", l.skip)
+	}
+	if got := l.WithCallerSkip(2).WithCallerSkip(-5).skip; got != 0 {
+		t.Errorf("skip = %d, want 0", got)
+	}
+}
+
+func TestLogger_With(t *testing.T) {
+	tests := []struct {
+		name string
+		log  func(*Logger)
+		want string
+	}{
+		{
+			name: "with",
+			log:  func(l *Logger) { l.With("a", 1).With().Info("m") },
+			want: "[INF] m a=1\n",
+		},
+		{
+			name: "with attrs",
+			log:  func(l *Logger) { l.WithAttrs(slog.Int("a", 1)).WithAttrs().Info("m", "b", 2) },
+			want: "[INF] m a=1 b=2\n",
+		},
+		{
+			name: "with group",
+			log:  func(l *Logger) { l.WithGroup("g").WithGroup("").With("a", 1).Info("m") },
+			want: "[INF] m g.a=1\n",
+		},
+		{
+			name: "chain",
+			log: func(l *Logger) {
+				l.With("a", 1).WithGroup("g").WithAttrs(slog.Int("b", 2)).Infof("%d", 3)
+			},
+			want: "[INF] 3 a=1 g.b=2\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			tt.log(NewLogger(NewCLIHandler(buf, WithStyle(Style0()))))
+			if got := buf.String(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("options", func(t *testing.T) {
+		code := -1
+		l := NewLogger(nil, WithExitFunc(func(c int) { code = c })).WithCallerSkip(1)
+		l2 := l.With("a", 1).WithGroup("g").WithAttrs(slog.Int("b", 2))
+		if l2.skip != 1 {
+			t.Errorf("skip = %d, want 1", l2.skip)
+		}
+		l2.Fatal("m")
+		if code != 1 {
+			t.Errorf("exit code = %v, want 1", code)
+		}
+		if l.Handler() == l2.Handler() {
+			t.Error("handler of the original logger changed")
+		}
+	})
+}
+
+func TestLogger_methods(t *testing.T) {
+	tests := []struct {
+		name string
+		log  func(*Logger)
+		want string
+	}{
+		{name: "trace", log: func(l *Logger) { l.Trace("m", "k", 1) }, want: "[TRC] <log_test.go:%d> m k=1\n"},
+		{name: "trace context", log: func(l *Logger) { l.TraceContext(context.Background(), "m") }, want: "[TRC] <log_test.go:%d> m\n"},
+		{name: "debug", log: func(l *Logger) { l.Debug("m", "k", 1) }, want: "[DBG] <log_test.go:%d> m k=1\n"},
+		{name: "debug context", log: func(l *Logger) { l.DebugContext(context.Background(), "m") }, want: "[DBG] <log_test.go:%d> m\n"},
+		{name: "info", log: func(l *Logger) { l.Info("m") }, want: "[INF] <log_test.go:%d> m\n"},
+		{name: "info context", log: func(l *Logger) { l.InfoContext(context.Background(), "m") }, want: "[INF] <log_test.go:%d> m\n"},
+		{name: "notice", log: func(l *Logger) { l.Notice("m") }, want: "[NTC] <log_test.go:%d> m\n"},
+		{name: "notice context", log: func(l *Logger) { l.NoticeContext(context.Background(), "m") }, want: "[NTC] <log_test.go:%d> m\n"},
+		{name: "warn", log: func(l *Logger) { l.Warn("m") }, want: "[WRN] <log_test.go:%d> m\n"},
+		{name: "warn context", log: func(l *Logger) { l.WarnContext(context.Background(), "m") }, want: "[WRN] <log_test.go:%d> m\n"},
+		{name: "error", log: func(l *Logger) { l.Error("m") }, want: "[ERR] <log_test.go:%d> m\n"},
+		{name: "error context", log: func(l *Logger) { l.ErrorContext(context.Background(), "m") }, want: "[ERR] <log_test.go:%d> m\n"},
+		{name: "log", log: func(l *Logger) { l.Log(context.Background(), slog.LevelWarn, "m") }, want: "[WRN] <log_test.go:%d> m\n"},
+		{name: "log attrs", log: func(l *Logger) { l.LogAttrs(context.Background(), slog.LevelInfo, "m", slog.Int("k", 1)) }, want: "[INF] <log_test.go:%d> m k=1\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			l := NewLogger(NewCLIHandler(buf, WithStyle(Style0()), WithCaller(true), WithLevel(LevelTrace)))
+			tt.log(l)
+			pc := reflect.ValueOf(tt.log).Pointer()
+			_, line := runtime.FuncForPC(pc).FileLine(pc)
+			want := fmt.Sprintf(tt.want, line)
+			if got := buf.String(); got != want {
+				t.Errorf("got %q, want %q", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// NewLogfmtHandler creates a new handler that writes canonical logfmt output
+// (time, level and msg keys first) with the given options. It shares levels,
+// attributes and groups handling with CLIHandler; style and color options are ignored.
+func NewLogfmtHandler(w io.Writer, opts ...CLIHandlerOption) slog.Handler {
+	h := NewCLIHandler(w, opts...).(*CLIHandler)
+	h.format = FormatLogfmt
+	h.style = logfmtStyle()
+	return h
+}
+
+// logfmtStyle returns the style used to render logfmt attributes.
+func logfmtStyle() *Style {
+	s := Style0()
+	s.Attr.Separator = "="
+	return s
+}
+
+// formatLogfmt writes the record to buf in logfmt format. Unless the handler
+// formats in parallel, it must be called with the mutex held.
+func (h *CLIHandler) formatLogfmt(ctx context.Context, buf *bytes.Buffer, r slog.Record) {
+	sep := ""
+	if !r.Time.IsZero() {
+		if a, ok := h.timeAttr(h.recordTime(r.Time)); ok {
+			writeLogfmtKey(buf, a.Key)
+			buf.WriteString("=")
+			if a.Value.Kind() == slog.KindTime {
+				var b [64]byte
+				writeTextBytes(buf, h.appendTime(b[:0], a.Value.Time()), nil)
+			} else {
+				writeValue(buf, a.Value, nil, h.timeLayout)
+			}
+			sep = " "
+		}
+	}
+	if a, ok := h.replaceBuiltin(slog.Any(slog.LevelKey, r.Level)); ok {
+		buf.WriteString(sep)
+		writeLogfmtKey(buf, a.Key)
+		buf.WriteString("=")
+		if lv, ok := a.Value.Any().(slog.Level); ok {
+			writeText(buf, lv.String(), nil)
+		} else {
+			writeText(buf, a.Value.String(), nil)
+		}
+		sep = " "
+	}
+	msgKey := h.messageKey
+	if msgKey == "" {
+		msgKey = slog.MessageKey
+	}
+	if a, ok := h.replaceBuiltin(slog.String(msgKey, r.Message)); ok {
+		buf.WriteString(sep)
+		writeLogfmtKey(buf, a.Key)
+		buf.WriteString("=")
+		writeText(buf, h.message(h.redactor.RedactString(a.Value.String())), nil)
+	}
+	label, ok := labelOf(r)
+	if !ok {
+		label = h.labelText()
+	}
+	if label != "" {
+		buf.WriteString(" ")
+		buf.WriteString(LabelKey)
+		buf.WriteString("=")
+		writeText(buf, label, nil)
+	}
+	if h.hasCaller && r.PC != 0 {
+		if b := h.source(r.PC); len(b) > 0 {
+			buf.WriteString(" ")
+			buf.WriteString(slog.SourceKey)
+			buf.WriteString("=")
+			writeTextBytes(buf, b, nil)
+		}
+	}
+	st := newHandleState()
+	defer st.free()
+	h.writeAttrs(ctx, buf, r, st)
+	buf.WriteString("\n")
+}
+
+// writeLogfmtKey writes key to buf with the characters that would end a logfmt
+// key, i.e. spaces, '=', '"' and control characters, replaced by '_'.
+func writeLogfmtKey(buf *bytes.Buffer, key string) {
+	if !strings.ContainsFunc(key, isLogfmtKeyBreak) {
+		buf.WriteString(key)
+		return
+	}
+	for _, r := range key {
+		if isLogfmtKeyBreak(r) {
+			r = '_'
+		}
+		buf.WriteRune(r)
+	}
+}
+
+// isLogfmtKeyBreak reports whether r cannot appear in a logfmt key.
+func isLogfmtKeyBreak(r rune) bool {
+	return r <= ' ' || r == '=' || r == '"' || r == utf8.RuneError || !unicode.IsPrint(r)
+}
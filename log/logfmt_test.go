@@ -0,0 +1,267 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"reflect"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+	"testing/slogtest"
+	"time"
+)
+
+func TestNewLogfmtHandler(t *testing.T) {
+	h := NewLogfmtHandler(&bytes.Buffer{}, WithLevel(slog.LevelDebug), WithStyle(Style4())).(*CLIHandler)
+	if h.format != FormatLogfmt {
+		t.Errorf("format = %v, want %v", h.format, FormatLogfmt)
+	}
+	if h.level != slog.LevelDebug {
+		t.Errorf("level = %v, want %v", h.level, slog.LevelDebug)
+	}
+	if !reflect.DeepEqual(h.style, logfmtStyle()) {
+		t.Error("style mismatch with logfmtStyle")
+	}
+}
+
+func TestLogfmtHandler_Handle(t *testing.T) {
+	ts := time.Date(2025, time.April, 1, 12, 30, 0, 0, time.UTC)
+	tests := []struct {
+		name  string
+		opts  []CLIHandlerOption
+		setup func(slog.Handler) slog.Handler
+		r     func() slog.Record
+		want  string
+	}{
+		{
+			name: "basic",
+			r: func() slog.Record {
+				return slog.NewRecord(ts, slog.LevelInfo, "hello", 0)
+			},
+			want: "time=2025-04-01T12:30:00Z level=INFO msg=hello\n",
+		},
+		{
+			name: "zero time omitted",
+			r: func() slog.Record {
+				return slog.NewRecord(time.Time{}, slog.LevelWarn, "hello", 0)
+			},
+			want: "level=WARN msg=hello\n",
+		},
+		{
+			name: "custom level",
+			r: func() slog.Record {
+				return slog.NewRecord(time.Time{}, slog.LevelInfo+2, "hello", 0)
+			},
+			want: "level=INFO+2 msg=hello\n",
+		},
+		{
+			name: "quoted message and values",
+			r: func() slog.Record {
+				r := slog.NewRecord(time.Time{}, slog.LevelError, "hello world", 0)
+				r.AddAttrs(
+					slog.String("empty", ""),
+					slog.String("eq", "a=b"),
+					slog.String("quote", `say "hi"`),
+					slog.String("newline", "a\nb"),
+					slog.Int("n", 1),
+				)
+				return r
+			},
+			want: `level=ERROR msg="hello world" empty="" eq="a=b" quote="say \"hi\"" newline="a\nb" n=1` + "\n",
+		},
+		{
+			name: "quoted time layout",
+			opts: []CLIHandlerOption{WithTimeFormat(time.ANSIC)},
+			r: func() slog.Record {
+				return slog.NewRecord(ts, slog.LevelInfo, "hello", 0)
+			},
+			want: `time="Tue Apr  1 12:30:00 2025" level=INFO msg=hello` + "\n",
+		},
+		{
+			name: "label",
+			opts: []CLIHandlerOption{WithLabel("APP")},
+			r: func() slog.Record {
+				return slog.NewRecord(time.Time{}, slog.LevelInfo, "hello", 0)
+			},
+			want: "level=INFO msg=hello label=APP\n",
+		},
+		{
+			name: "groups and attrs",
+			setup: func(h slog.Handler) slog.Handler {
+				return h.WithGroup("req").WithAttrs([]slog.Attr{slog.String("app", "cli")})
+			},
+			r: func() slog.Record {
+				r := slog.NewRecord(time.Time{}, slog.LevelInfo, "hello", 0)
+				r.AddAttrs(slog.Group("user", slog.Int("id", 1)))
+				return r
+			},
+			want: "level=INFO msg=hello req.app=cli req.user.id=1\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			h := NewLogfmtHandler(buf, tt.opts...)
+			if tt.setup != nil {
+				h = tt.setup(h)
+			}
+			if err := h.Handle(context.Background(), tt.r()); err != nil {
+				t.Fatal(err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLogfmtHandler_Handle_keyEscaping(t *testing.T) {
+	tests := []struct {
+		name  string
+		opts  []CLIHandlerOption
+		setup func(slog.Handler) slog.Handler
+		attrs []slog.Attr
+		want  string
+	}{
+		{
+			name:  "space",
+			attrs: []slog.Attr{slog.String("a b", "x")},
+			want:  "level=INFO msg=hello a_b=x\n",
+		},
+		{
+			name:  "equals and quote",
+			attrs: []slog.Attr{slog.Int("k=v", 1), slog.Int(`say"hi"`, 2)},
+			want:  "level=INFO msg=hello k_v=1 say_hi_=2\n",
+		},
+		{
+			name:  "control characters",
+			attrs: []slog.Attr{slog.Int("a\nb\tc", 1)},
+			want:  "level=INFO msg=hello a_b_c=1\n",
+		},
+		{
+			name:  "unicode kept",
+			attrs: []slog.Attr{slog.Int("ключ", 1)},
+			want:  "level=INFO msg=hello ключ=1\n",
+		},
+		{
+			name: "groups",
+			setup: func(h slog.Handler) slog.Handler {
+				return h.WithGroup("my req")
+			},
+			attrs: []slog.Attr{slog.Group("a=b", slog.Int("c d", 1))},
+			want:  "level=INFO msg=hello my_req.a_b.c_d=1\n",
+		},
+		{
+			name: "builtin keys",
+			opts: []CLIHandlerOption{WithMessageKey("the msg")},
+			want: "level=INFO the_msg=hello\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			h := NewLogfmtHandler(buf, tt.opts...)
+			if tt.setup != nil {
+				h = tt.setup(h)
+			}
+			r := slog.NewRecord(time.Time{}, slog.LevelInfo, "hello", 0)
+			r.AddAttrs(tt.attrs...)
+			if err := h.Handle(context.Background(), r); err != nil {
+				t.Fatal(err)
+			}
+			got := buf.String()
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+			parseLogfmt(t, strings.TrimSuffix(got, "\n"))
+		})
+	}
+}
+
+func TestLogfmtHandler_Handle_levelWriter(t *testing.T) {
+	out, errw := &bytes.Buffer{}, &bytes.Buffer{}
+	l := NewLogger(NewLogfmtHandler(out, WithLevelWriter(map[slog.Level]io.Writer{slog.LevelWarn: errw})))
+	l.Info("info")
+	l.Error("error")
+	if got := out.String(); !strings.Contains(got, "msg=info") || strings.Contains(got, "msg=error") {
+		t.Errorf("out = %q", got)
+	}
+	if got := errw.String(); !strings.Contains(got, "msg=error") || strings.Contains(got, "msg=info") {
+		t.Errorf("err = %q", got)
+	}
+}
+
+func TestLogfmtHandler_Handle_caller(t *testing.T) {
+	buf := &bytes.Buffer{}
+	h := NewLogfmtHandler(buf, WithCaller(true))
+	pc, _, _, _ := runtime.Caller(0)
+	if err := h.Handle(context.Background(), slog.NewRecord(time.Time{}, slog.LevelInfo, "hello", pc)); err != nil {
+		t.Fatal(err)
+	}
+	if got := buf.String(); !strings.HasPrefix(got, "level=INFO msg=hello source=logfmt_test.go:") {
+		t.Errorf("got %q, want source attr", got)
+	}
+}
+
+func TestLogfmtHandler_keys(t *testing.T) {
+	buf := &bytes.Buffer{}
+	h := NewLogfmtHandler(buf, WithTimeKey("ts"), WithMessageKey("message"), WithUTC(true))
+	r := slog.NewRecord(time.Date(2024, 1, 2, 3, 4, 5, 0, time.FixedZone("JST", 9*60*60)), slog.LevelInfo, "hello", 0)
+	if err := h.Handle(t.Context(), r); err != nil {
+		t.Fatal(err)
+	}
+	want := "ts=2024-01-01T18:04:05Z level=INFO message=hello\n"
+	if got := buf.String(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestLogfmtHandler_slogtest(t *testing.T) {
+	var buf bytes.Buffer
+	slogtest.Run(t, func(*testing.T) slog.Handler {
+		buf.Reset()
+		return NewLogfmtHandler(&buf)
+	}, func(t *testing.T) map[string]any {
+		return parseLogfmt(t, strings.TrimSuffix(buf.String(), "\n"))
+	})
+}
+
+// parseLogfmt parses a logfmt line into a map, nesting the members of dotted keys.
+func parseLogfmt(t *testing.T, line string) map[string]any {
+	t.Helper()
+	m := map[string]any{}
+	for line != "" {
+		key, rest, ok := strings.Cut(line, "=")
+		if !ok {
+			t.Fatalf("missing '=' in %q", line)
+		}
+		var val string
+		if strings.HasPrefix(rest, `"`) {
+			q, err := strconv.QuotedPrefix(rest)
+			if err != nil {
+				t.Fatal(err)
+			}
+			val, _ = strconv.Unquote(q)
+			rest = rest[len(q):]
+		} else {
+			val, rest, _ = strings.Cut(rest, " ")
+			rest = " " + rest
+		}
+		line = strings.TrimPrefix(rest, " ")
+		keys := strings.Split(key, ".")
+		cur := m
+		for _, k := range keys[:len(keys)-1] {
+			next, ok := cur[k].(map[string]any)
+			if !ok {
+				next = map[string]any{}
+				cur[k] = next
+			}
+			cur = next
+		}
+		cur[keys[len(keys)-1]] = val
+	}
+	return m
+}
@@ -0,0 +1,239 @@
+// Package logtest provides a slog.Handler recording records in memory, with
+// assertions for testing the logging of a program.
+package logtest
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+var _ slog.Handler = (*Recorder)(nil)
+
+// Entry is a recorded record. Attributes of the handler and the record are
+// flattened: group members have keys joined with "." and LogValuers are resolved.
+type Entry struct {
+	Time    time.Time
+	Level   slog.Level
+	Message string
+	PC      uintptr
+	Attrs   []slog.Attr
+}
+
+// Attr returns the value of the attribute with the given flattened key.
+func (e Entry) Attr(key string) (slog.Value, bool) {
+	for _, a := range e.Attrs {
+		if a.Key == key {
+			return a.Value, true
+		}
+	}
+	return slog.Value{}, false
+}
+
+// String returns the entry in a plain text form, for failure messages.
+func (e Entry) String() string {
+	var b strings.Builder
+	b.WriteString(e.Level.String())
+	b.WriteByte(' ')
+	b.WriteString(e.Message)
+	for _, a := range e.Attrs {
+		b.WriteByte(' ')
+		b.WriteString(a.String())
+	}
+	return b.String()
+}
+
+// Recorder is a slog.Handler that records every record in memory. Handlers
+// derived with WithAttrs and WithGroup record into the same entries.
+type Recorder struct {
+	prefix string
+	attrs  []slog.Attr
+	state  *state
+}
+
+// state is the state shared by a Recorder and its derived handlers.
+type state struct {
+	mu      sync.Mutex
+	level   slog.Leveler
+	entries []Entry
+}
+
+// RecorderOption defines a function type for configuring a Recorder.
+type RecorderOption func(*state)
+
+// WithLevel returns a RecorderOption that sets the minimum level recorded.
+// All levels are recorded by default.
+func WithLevel(level slog.Leveler) RecorderOption {
+	return func(s *state) {
+		s.level = level
+	}
+}
+
+// NewRecorder creates a new Recorder.
+func NewRecorder(opts ...RecorderOption) *Recorder {
+	s := &state{}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return &Recorder{state: s}
+}
+
+// Enabled reports whether the level is at least the minimum level.
+func (h *Recorder) Enabled(_ context.Context, level slog.Level) bool {
+	return h.state.level == nil || level >= h.state.level.Level()
+}
+
+// Handle records the record.
+func (h *Recorder) Handle(_ context.Context, r slog.Record) error {
+	e := Entry{
+		Time:    r.Time,
+		Level:   r.Level,
+		Message: r.Message,
+		PC:      r.PC,
+		Attrs:   append([]slog.Attr(nil), h.attrs...),
+	}
+	r.Attrs(func(a slog.Attr) bool {
+		e.Attrs = flatten(e.Attrs, h.prefix, a)
+		return true
+	})
+	h.state.mu.Lock()
+	defer h.state.mu.Unlock()
+	h.state.entries = append(h.state.entries, e)
+	return nil
+}
+
+// WithAttrs returns a new Recorder with the given attributes.
+func (h *Recorder) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	h2 := *h
+	h2.attrs = append([]slog.Attr(nil), h.attrs...)
+	for _, a := range attrs {
+		h2.attrs = flatten(h2.attrs, h.prefix, a)
+	}
+	return &h2
+}
+
+// WithGroup returns a new Recorder with the given group.
+func (h *Recorder) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	h2 := *h
+	h2.prefix = h.prefix + name + "."
+	return &h2
+}
+
+// flatten appends a to attrs with its key prefixed, expanding groups.
+func flatten(attrs []slog.Attr, prefix string, a slog.Attr) []slog.Attr {
+	a.Value = a.Value.Resolve()
+	if a.Value.Kind() != slog.KindGroup {
+		if a.Equal(slog.Attr{}) {
+			return attrs
+		}
+		return append(attrs, slog.Attr{Key: prefix + a.Key, Value: a.Value})
+	}
+	if a.Key != "" {
+		prefix += a.Key + "."
+	}
+	for _, ga := range a.Value.Group() {
+		attrs = flatten(attrs, prefix, ga)
+	}
+	return attrs
+}
+
+// Entries returns a copy of the recorded entries.
+func (h *Recorder) Entries() []Entry {
+	h.state.mu.Lock()
+	defer h.state.mu.Unlock()
+	return append([]Entry(nil), h.state.entries...)
+}
+
+// Last returns the last recorded entry, or false if there is none.
+func (h *Recorder) Last() (Entry, bool) {
+	h.state.mu.Lock()
+	defer h.state.mu.Unlock()
+	if len(h.state.entries) == 0 {
+		return Entry{}, false
+	}
+	return h.state.entries[len(h.state.entries)-1], true
+}
+
+// Len returns the number of recorded entries.
+func (h *Recorder) Len() int {
+	h.state.mu.Lock()
+	defer h.state.mu.Unlock()
+	return len(h.state.entries)
+}
+
+// Reset discards the recorded entries.
+func (h *Recorder) Reset() {
+	h.state.mu.Lock()
+	defer h.state.mu.Unlock()
+	h.state.entries = nil
+}
+
+// AssertContains fails the test unless an entry at level has a message containing substr.
+func (h *Recorder) AssertContains(t testing.TB, level slog.Level, substr string) {
+	t.Helper()
+	entries := h.Entries()
+	for _, e := range entries {
+		if e.Level == level && strings.Contains(e.Message, substr) {
+			return
+		}
+	}
+	t.Errorf("logtest: no %s entry containing %q in:%s", level, substr, dump(entries))
+}
+
+// AssertAttr fails the test unless an entry has an attribute with the given
+// flattened key and value. Values are compared after conversion with slog.AnyValue,
+// so an int matches an int64 attribute.
+func (h *Recorder) AssertAttr(t testing.TB, key string, value any) {
+	t.Helper()
+	want := slog.AnyValue(value).Resolve()
+	entries := h.Entries()
+	for _, e := range entries {
+		if v, ok := e.Attr(key); ok && equal(v, want) {
+			return
+		}
+	}
+	t.Errorf("logtest: no entry with %s=%v in:%s", key, value, dump(entries))
+}
+
+// AssertEmpty fails the test if any entry was recorded.
+func (h *Recorder) AssertEmpty(t testing.TB) {
+	t.Helper()
+	if entries := h.Entries(); len(entries) > 0 {
+		t.Errorf("logtest: want no entries, got:%s", dump(entries))
+	}
+}
+
+// equal reports whether two resolved values are equal, comparing values of
+// kind slog.KindAny deeply.
+func equal(a, b slog.Value) bool {
+	if a.Kind() != b.Kind() {
+		return false
+	}
+	if a.Kind() == slog.KindAny {
+		return reflect.DeepEqual(a.Any(), b.Any())
+	}
+	return a.Equal(b)
+}
+
+// dump formats entries for failure messages.
+func dump(entries []Entry) string {
+	if len(entries) == 0 {
+		return " (no entries)"
+	}
+	var b strings.Builder
+	for _, e := range entries {
+		fmt.Fprintf(&b, "\n\t%s", e)
+	}
+	return b.String()
+}
@@ -0,0 +1,219 @@
+package logtest
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/slogtest"
+	"time"
+)
+
+// fakeT is a testing.TB recording failures.
+type fakeT struct {
+	testing.TB
+	errs []string
+}
+
+func (t *fakeT) Helper() {}
+
+func (t *fakeT) Errorf(format string, args ...any) {
+	t.errs = append(t.errs, fmt.Sprintf(format, args...))
+}
+
+// token is a LogValuer.
+type token string
+
+func (t token) LogValue() slog.Value { return slog.StringValue("<" + string(t) + ">") }
+
+func TestRecorder_Entries(t *testing.T) {
+	tests := []struct {
+		name string
+		log  func(l *slog.Logger)
+		want []slog.Attr
+	}{
+		{
+			name: "attrs",
+			log:  func(l *slog.Logger) { l.Info("msg", "a", 1, "b", "x") },
+			want: []slog.Attr{slog.Int("a", 1), slog.String("b", "x")},
+		},
+		{
+			name: "handler attrs and groups",
+			log:  func(l *slog.Logger) { l.With("a", 1).WithGroup("g").With("b", 2).Info("msg", "c", 3) },
+			want: []slog.Attr{slog.Int("a", 1), slog.Int("g.b", 2), slog.Int("g.c", 3)},
+		},
+		{
+			name: "group attr",
+			log:  func(l *slog.Logger) { l.Info("msg", slog.Group("g", "a", 1, slog.Group("h", "b", 2))) },
+			want: []slog.Attr{slog.Int("g.a", 1), slog.Int("g.h.b", 2)},
+		},
+		{
+			name: "inline group and empty attr",
+			log:  func(l *slog.Logger) { l.Info("msg", slog.Group("", "a", 1), slog.Attr{}) },
+			want: []slog.Attr{slog.Int("a", 1)},
+		},
+		{
+			name: "log valuer",
+			log:  func(l *slog.Logger) { l.Info("msg", "t", token("x")) },
+			want: []slog.Attr{slog.String("t", "<x>")},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewRecorder()
+			tt.log(slog.New(r))
+			entries := r.Entries()
+			if len(entries) != 1 {
+				t.Fatalf("got %d entries, want 1", len(entries))
+			}
+			e := entries[0]
+			if e.Level != slog.LevelInfo || e.Message != "msg" || e.Time.IsZero() {
+				t.Errorf("got %v, want INFO msg with time", e)
+			}
+			if !reflect.DeepEqual(e.Attrs, tt.want) {
+				t.Errorf("got %v, want %v", e.Attrs, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecorder_Last(t *testing.T) {
+	r := NewRecorder()
+	if _, ok := r.Last(); ok {
+		t.Error("got entry, want none")
+	}
+	l := slog.New(r)
+	l.Info("first")
+	l.WithGroup("g").Warn("second")
+	e, ok := r.Last()
+	if !ok || e.Message != "second" || e.Level != slog.LevelWarn {
+		t.Errorf("got %v, %v, want WARN second", e, ok)
+	}
+	if r.Len() != 2 {
+		t.Errorf("got %d, want 2", r.Len())
+	}
+	r.Reset()
+	if r.Len() != 0 {
+		t.Errorf("got %d after reset, want 0", r.Len())
+	}
+}
+
+func TestRecorder_Enabled(t *testing.T) {
+	r := NewRecorder(WithLevel(slog.LevelWarn))
+	l := slog.New(r)
+	l.Info("skipped")
+	l.Warn("kept")
+	if r.Len() != 1 {
+		t.Errorf("got %d entries, want 1", r.Len())
+	}
+	if !NewRecorder().Enabled(context.Background(), slog.LevelDebug-4) {
+		t.Error("got disabled, want enabled")
+	}
+}
+
+func TestRecorder_AssertContains(t *testing.T) {
+	r := NewRecorder()
+	slog.New(r).Warn("disk almost full", "pct", 95)
+	tests := []struct {
+		name   string
+		level  slog.Level
+		substr string
+		fail   bool
+	}{
+		{name: "match", level: slog.LevelWarn, substr: "almost"},
+		{name: "wrong level", level: slog.LevelInfo, substr: "almost", fail: true},
+		{name: "wrong message", level: slog.LevelWarn, substr: "empty", fail: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ft := &fakeT{TB: t}
+			r.AssertContains(ft, tt.level, tt.substr)
+			if got := len(ft.errs) > 0; got != tt.fail {
+				t.Errorf("got failure %v, want %v: %v", got, tt.fail, ft.errs)
+			}
+		})
+	}
+}
+
+func TestRecorder_AssertAttr(t *testing.T) {
+	r := NewRecorder()
+	slog.New(r).WithGroup("req").Info("done",
+		"status", 200,
+		"path", "/",
+		"took", time.Second,
+		"tags", []string{"a", "b"},
+	)
+	tests := []struct {
+		name  string
+		key   string
+		value any
+		fail  bool
+	}{
+		{name: "int", key: "req.status", value: 200},
+		{name: "string", key: "req.path", value: "/"},
+		{name: "duration", key: "req.took", value: time.Second},
+		{name: "slice", key: "req.tags", value: []string{"a", "b"}},
+		{name: "wrong value", key: "req.status", value: 500, fail: true},
+		{name: "wrong kind", key: "req.status", value: "200", fail: true},
+		{name: "missing key", key: "status", value: 200, fail: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ft := &fakeT{TB: t}
+			r.AssertAttr(ft, tt.key, tt.value)
+			if got := len(ft.errs) > 0; got != tt.fail {
+				t.Errorf("got failure %v, want %v: %v", got, tt.fail, ft.errs)
+			}
+		})
+	}
+}
+
+func TestRecorder_AssertEmpty(t *testing.T) {
+	r := NewRecorder()
+	ft := &fakeT{TB: t}
+	r.AssertEmpty(ft)
+	if len(ft.errs) != 0 {
+		t.Errorf("got %v, want no failure", ft.errs)
+	}
+	slog.New(r).Info("msg", "a", 1)
+	r.AssertEmpty(ft)
+	if len(ft.errs) != 1 || !strings.Contains(ft.errs[0], "INFO msg a=1") {
+		t.Errorf("got %v, want failure listing the entry", ft.errs)
+	}
+}
+
+func TestRecorder_slogtest(t *testing.T) {
+	var r *Recorder
+	slogtest.Run(t, func(*testing.T) slog.Handler {
+		r = NewRecorder()
+		return r
+	}, func(t *testing.T) map[string]any {
+		e, ok := r.Last()
+		if !ok {
+			t.Fatal("no entry")
+		}
+		m := map[string]any{
+			slog.LevelKey:   e.Level,
+			slog.MessageKey: e.Message,
+		}
+		if !e.Time.IsZero() {
+			m[slog.TimeKey] = e.Time
+		}
+		for _, a := range e.Attrs {
+			keys := strings.Split(a.Key, ".")
+			cur := m
+			for _, k := range keys[:len(keys)-1] {
+				next, ok := cur[k].(map[string]any)
+				if !ok {
+					next = map[string]any{}
+					cur[k] = next
+				}
+				cur = next
+			}
+			cur[keys[len(keys)-1]] = a.Value.Any()
+		}
+		return m
+	})
+}
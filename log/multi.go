@@ -0,0 +1,102 @@
+package log
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"slices"
+)
+
+var (
+	_ slog.Handler = (*MultiHandler)(nil)
+	_ LevelSetter  = (*MultiHandler)(nil)
+	_ LabelSetter  = (*MultiHandler)(nil)
+)
+
+// MultiHandler is a slog.Handler that dispatches records to multiple handlers.
+type MultiHandler struct {
+	handlers []slog.Handler
+}
+
+// NewMultiHandler creates a new MultiHandler with the given handlers.
+// Nil handlers are ignored.
+func NewMultiHandler(handlers ...slog.Handler) slog.Handler {
+	hs := make([]slog.Handler, 0, len(handlers))
+	for _, h := range handlers {
+		if h != nil {
+			hs = append(hs, h)
+		}
+	}
+	return &MultiHandler{handlers: hs}
+}
+
+// Enabled reports whether any of the handlers is enabled for the given level.
+func (h *MultiHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	for _, handler := range h.handlers {
+		if handler.Enabled(ctx, level) {
+			return true
+		}
+	}
+	return false
+}
+
+// Handle dispatches the record to every enabled handler and joins their errors.
+func (h *MultiHandler) Handle(ctx context.Context, r slog.Record) error {
+	var errs []error
+	for _, handler := range h.handlers {
+		if !handler.Enabled(ctx, r.Level) {
+			continue
+		}
+		if err := handler.Handle(ctx, r.Clone()); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// WithAttrs returns a new MultiHandler whose handlers have the given attributes.
+func (h *MultiHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	hs := make([]slog.Handler, len(h.handlers))
+	for i, handler := range h.handlers {
+		hs[i] = handler.WithAttrs(attrs)
+	}
+	return &MultiHandler{handlers: hs}
+}
+
+// WithGroup returns a new MultiHandler whose handlers have the given group.
+func (h *MultiHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	hs := make([]slog.Handler, len(h.handlers))
+	for i, handler := range h.handlers {
+		hs[i] = handler.WithGroup(name)
+	}
+	return &MultiHandler{handlers: hs}
+}
+
+// Handlers returns a copy of the handlers records are dispatched to.
+func (h *MultiHandler) Handlers() []slog.Handler {
+	return slices.Clone(h.handlers)
+}
+
+// SetLevel changes the minimum level of every handler that implements LevelSetter.
+func (h *MultiHandler) SetLevel(level slog.Leveler) {
+	for _, handler := range h.handlers {
+		if s, ok := handler.(LevelSetter); ok {
+			s.SetLevel(level)
+		}
+	}
+}
+
+// SetLabel changes the label of every handler that implements LabelSetter.
+func (h *MultiHandler) SetLabel(label string) {
+	for _, handler := range h.handlers {
+		if s, ok := handler.(LabelSetter); ok {
+			s.SetLabel(label)
+		}
+	}
+}
@@ -0,0 +1,202 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+type errHandler struct {
+	slog.Handler
+	err error
+}
+
+func (h *errHandler) Handle(context.Context, slog.Record) error {
+	return h.err
+}
+
+func TestNewMultiHandler(t *testing.T) {
+	tests := []struct {
+		name     string
+		handlers []slog.Handler
+		want     int
+	}{
+		{
+			name:     "no handlers",
+			handlers: nil,
+			want:     0,
+		},
+		{
+			name: "skip nil handlers",
+			handlers: []slog.Handler{
+				NewCLIHandler(&bytes.Buffer{}),
+				nil,
+				slog.NewJSONHandler(&bytes.Buffer{}, nil),
+			},
+			want: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NewMultiHandler(tt.handlers...).(*MultiHandler)
+			if len(got.handlers) != tt.want {
+				t.Errorf("len(handlers) = %v, want %v", len(got.handlers), tt.want)
+			}
+		})
+	}
+}
+
+func TestMultiHandler_Enabled(t *testing.T) {
+	tests := []struct {
+		name     string
+		handlers []slog.Handler
+		level    slog.Level
+		want     bool
+	}{
+		{
+			name:     "no handlers",
+			handlers: nil,
+			level:    slog.LevelError,
+			want:     false,
+		},
+		{
+			name: "any enabled",
+			handlers: []slog.Handler{
+				NewCLIHandler(&bytes.Buffer{}, WithLevel(slog.LevelError)),
+				NewCLIHandler(&bytes.Buffer{}, WithLevel(slog.LevelDebug)),
+			},
+			level: slog.LevelDebug,
+			want:  true,
+		},
+		{
+			name: "none enabled",
+			handlers: []slog.Handler{
+				NewCLIHandler(&bytes.Buffer{}, WithLevel(slog.LevelError)),
+				NewCLIHandler(&bytes.Buffer{}, WithLevel(slog.LevelWarn)),
+			},
+			level: slog.LevelInfo,
+			want:  false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewMultiHandler(tt.handlers...)
+			if got := h.Enabled(context.Background(), tt.level); got != tt.want {
+				t.Errorf("MultiHandler.Enabled() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMultiHandler_Handle(t *testing.T) {
+	err1 := errors.New("err1")
+	err2 := errors.New("err2")
+	tests := []struct {
+		name    string
+		level   slog.Level
+		opts    [2][]CLIHandlerOption
+		errs    []error
+		want    [2]string
+		wantErr []error
+	}{
+		{
+			name:  "dispatch to all",
+			level: slog.LevelInfo,
+			want:  [2]string{"[INF] msg key=val\n", "[INF] msg key=val\n"},
+		},
+		{
+			name:  "dispatch to enabled only",
+			level: slog.LevelInfo,
+			opts:  [2][]CLIHandlerOption{nil, {WithLevel(slog.LevelError)}},
+			want:  [2]string{"[INF] msg key=val\n", ""},
+		},
+		{
+			name:    "aggregate errors",
+			level:   slog.LevelInfo,
+			errs:    []error{err1, err2},
+			want:    [2]string{"[INF] msg key=val\n", "[INF] msg key=val\n"},
+			wantErr: []error{err1, err2},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var bufs [2]bytes.Buffer
+			handlers := make([]slog.Handler, 0, 2+len(tt.errs))
+			for i := range bufs {
+				opts := append([]CLIHandlerOption{WithStyle(Style0())}, tt.opts[i]...)
+				handlers = append(handlers, NewCLIHandler(&bufs[i], opts...))
+			}
+			for _, err := range tt.errs {
+				handlers = append(handlers, &errHandler{Handler: NewCLIHandler(nil), err: err})
+			}
+			r := slog.NewRecord(time.Time{}, tt.level, "msg", 0)
+			r.AddAttrs(slog.String("key", "val"))
+			err := NewMultiHandler(handlers...).Handle(context.Background(), r)
+			if len(tt.wantErr) == 0 && err != nil {
+				t.Errorf("MultiHandler.Handle() error = %v, want nil", err)
+			}
+			for _, want := range tt.wantErr {
+				if !errors.Is(err, want) {
+					t.Errorf("MultiHandler.Handle() error = %v, want %v", err, want)
+				}
+			}
+			for i := range bufs {
+				if got := bufs[i].String(); got != tt.want[i] {
+					t.Errorf("handler[%d] got %q, want %q", i, got, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestMultiHandler_WithAttrs(t *testing.T) {
+	var buf1, buf2 bytes.Buffer
+	h := NewMultiHandler(
+		NewCLIHandler(&buf1, WithStyle(Style0())),
+		NewCLIHandler(&buf2, WithStyle(Style0())),
+	)
+	if got := h.WithAttrs(nil); got != h {
+		t.Error("want same handler instance for empty attrs")
+	}
+	h2 := h.WithAttrs([]slog.Attr{slog.String("key", "val")})
+	if h2 == h {
+		t.Error("want new handler instance")
+	}
+	if err := h2.Handle(context.Background(), slog.NewRecord(time.Time{}, slog.LevelInfo, "msg", 0)); err != nil {
+		t.Fatal(err)
+	}
+	for i, buf := range []*bytes.Buffer{&buf1, &buf2} {
+		if !strings.Contains(buf.String(), "key=val") {
+			t.Errorf("handler[%d] got %q, want contain %q", i, buf.String(), "key=val")
+		}
+	}
+}
+
+func TestMultiHandler_WithGroup(t *testing.T) {
+	var buf1, buf2 bytes.Buffer
+	h := NewMultiHandler(
+		NewCLIHandler(&buf1, WithStyle(Style0())),
+		NewCLIHandler(&buf2, WithStyle(Style0())),
+	)
+	if got := h.WithGroup(""); got != h {
+		t.Error("want same handler instance for empty name")
+	}
+	h2 := h.WithGroup("g1")
+	if h2 == h {
+		t.Error("want new handler instance")
+	}
+	r := slog.NewRecord(time.Time{}, slog.LevelInfo, "msg", 0)
+	r.AddAttrs(slog.String("key", "val"))
+	if err := h2.Handle(context.Background(), r); err != nil {
+		t.Fatal(err)
+	}
+	for i, buf := range []*bytes.Buffer{&buf1, &buf2} {
+		if !strings.Contains(buf.String(), "g1.key=val") {
+			t.Errorf("handler[%d] got %q, want contain %q", i, buf.String(), "g1.key=val")
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strconv"
+)
+
+// lineAttr is an attribute written on its own line in multiline mode. Its key
+// is the attribute key qualified by the prefix.
+type lineAttr struct {
+	prefix string
+	attr   slog.Attr
+}
+
+// key returns the qualified key of the attribute.
+func (a lineAttr) key() string {
+	return a.prefix + a.attr.Key
+}
+
+// keyWidth returns the display width of the qualified key of the attribute.
+func (a lineAttr) keyWidth() int {
+	return VisibleWidth(a.prefix) + VisibleWidth(a.attr.Key)
+}
+
+// writeMultiline writes the attributes of the record to buf, one per line.
+func (h *CLIHandler) writeMultiline(ctx context.Context, buf *bytes.Buffer, r slog.Record, st *handleState) {
+	prefix := h.groupsPrefix
+	attrs := st.lines[:0]
+	defer func() { st.lines = attrs }()
+	if h.hasTrace {
+		for _, attr := range h.traceAttrs(ctx) {
+			attrs = h.collectAttr(attrs, attr, "")
+		}
+	}
+	// Trace attributes are written first regardless of the order.
+	traced := len(attrs)
+	for _, attr := range h.attrs {
+		if h.replaceAttr != nil && !isEmptyAttr(attr) {
+			attr = h.replace(nil, attr)
+		}
+		attrs = h.collectAttr(attrs, attr, "")
+	}
+	collect := func(attr slog.Attr) bool {
+		if _, ok := stepAttr(attr); ok || isEmptyAttr(attr) {
+			return true
+		}
+		if _, ok := labelAttr(attr); ok {
+			return true
+		}
+		if h.attrHandler != nil {
+			attr = h.handleAttr(h.groups, attr)
+		}
+		if h.replaceAttr != nil {
+			if attr = h.replace(h.groups, attr); isEmptyAttr(attr) {
+				return true
+			}
+		}
+		if h.redactor != nil {
+			attr = h.redactor.redact(attr, h.anyFormat != AnyFormatDefault)
+		}
+		attrs = h.collectAttr(attrs, attr, prefix)
+		return true
+	}
+	for _, attr := range FromContext(ctx) {
+		collect(attr)
+	}
+	r.Attrs(collect)
+	if h.hasDedup {
+		attrs = dedupLines(attrs)
+	}
+	h.attrSort.sortLines(attrs[traced:])
+	if h.hasLimits() {
+		lines, cut := h.limitLines(attrs[traced:])
+		attrs = attrs[:traced+len(lines)]
+		if cut {
+			attrs = append(attrs, lineAttr{attr: slog.Bool(TruncatedKey, true)})
+		}
+	}
+	if len(attrs) == 0 {
+		return
+	}
+
+	width := 0
+	for _, a := range attrs {
+		width = max(width, a.keyWidth())
+	}
+	ms := h.style.Multiline
+	cont := &st.cont
+	cont.WriteString(ms.Indent)
+	writeSpaces(cont, width)
+	ms.Marker.Color.WriteString(cont, ms.Marker.Text)
+
+	for _, a := range attrs {
+		kc := h.style.Attr.KeyColor
+		if ks := h.style.Attr.Keys[a.attr.Key]; ks.KeyColor != nil {
+			kc = ks.KeyColor
+		}
+		buf.WriteString("\n")
+		buf.WriteString(ms.Indent)
+		st.key = append(append(st.key[:0], a.prefix...), a.attr.Key...)
+		kc.WriteBytes(buf, st.key)
+		writeSpaces(buf, width-a.keyWidth())
+		kc.WriteString(buf, h.style.Attr.Separator)
+		h.writeAttrValue(buf, a.attr.Key, a.attr.Value, h.style, h.timeLayout, cont.Bytes())
+	}
+}
+
+// collectAttr appends the leaves of attr to dst with keys qualified by prefix.
+func (h *CLIHandler) collectAttr(dst []lineAttr, attr slog.Attr, prefix string) []lineAttr {
+	v := attr.Value.Resolve()
+	if g, ok := h.flattenAny(v); ok {
+		v = g
+	}
+	if v.Kind() == slog.KindGroup {
+		if attr.Key != "" {
+			prefix += attr.Key + "."
+		}
+		for _, a := range v.Group() {
+			dst = h.collectAttr(dst, a, prefix)
+		}
+		return dst
+	}
+	if attr.Key == "" {
+		return dst
+	}
+	dst = append(dst, lineAttr{prefix: prefix, attr: slog.Attr{Key: attr.Key, Value: v}})
+	if err, ok := errorValue(v); ok && h.hasUnwrap {
+		dst = h.collectCauses(dst, err, prefix+attr.Key+".")
+	}
+	return dst
+}
+
+// collectCauses appends the errors wrapped by err like writeCauses.
+func (h *CLIHandler) collectCauses(dst []lineAttr, err error, prefix string) []lineAttr {
+	switch u := err.(type) {
+	case interface{ Unwrap() error }:
+		if cause := u.Unwrap(); cause != nil {
+			dst = h.collectAttr(dst, slog.Any(CauseKey, cause), prefix)
+		}
+	case interface{ Unwrap() []error }:
+		for i, cause := range u.Unwrap() {
+			if cause != nil {
+				dst = h.collectAttr(dst, slog.Any(strconv.Itoa(i), cause), prefix+CauseKey+".")
+			}
+		}
+	}
+	return dst
+}
+
+// dedupLines returns attrs with each key once, the last value replacing the
+// first at its position.
+func dedupLines(attrs []lineAttr) []lineAttr {
+	out := attrs[:0]
+	index := make(map[string]int, len(attrs))
+	for _, a := range attrs {
+		key := a.key()
+		if i, ok := index[key]; ok {
+			out[i] = a
+			continue
+		}
+		index[key] = len(out)
+		out = append(out, a)
+	}
+	return out
+}
+
+// writeSpaces writes n spaces to buf.
+func writeSpaces(buf *bytes.Buffer, n int) {
+	for ; n > 0; n-- {
+		buf.WriteByte(' ')
+	}
+}
@@ -0,0 +1,102 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestCLIHandler_Handle_multiline(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler func(*bytes.Buffer) slog.Handler
+		ctx     context.Context
+		attrs   []slog.Attr
+		want    string
+	}{
+		{
+			name: "no attrs",
+			handler: func(buf *bytes.Buffer) slog.Handler {
+				return NewCLIHandler(buf, WithStyle(Style0()), WithMultiline(true))
+			},
+			want: "[INF] msg\n",
+		},
+		{
+			name: "aligned keys",
+			handler: func(buf *bytes.Buffer) slog.Handler {
+				return NewCLIHandler(buf, WithStyle(Style0()), WithMultiline(true))
+			},
+			attrs: []slog.Attr{slog.Int("n", 1), slog.String("name", "a b"), slog.Group("g", slog.Bool("ok", true))},
+			want:  "[INF] msg\n  n   =1\n  name=\"a b\"\n  g.ok=true\n",
+		},
+		{
+			name: "groups and handler attrs",
+			handler: func(buf *bytes.Buffer) slog.Handler {
+				return NewCLIHandler(buf, WithStyle(Style0()), WithMultiline(true)).
+					WithGroup("req").
+					WithAttrs([]slog.Attr{slog.String("id", "x1")})
+			},
+			ctx:   NewContext(context.Background(), slog.Int("user", 7)),
+			attrs: []slog.Attr{slog.Int("status", 200)},
+			want:  "[INF] msg\n  req.id    =x1\n  req.user  =7\n  req.status=200\n",
+		},
+		{
+			name: "multi-line values",
+			handler: func(buf *bytes.Buffer) slog.Handler {
+				return NewCLIHandler(buf, WithStyle(Style0()), WithMultiline(true))
+			},
+			attrs: []slog.Attr{slog.String("body", "line1\nline2\n"), slog.Any("err", errors.Join(errors.New("a"), errors.New("b")))},
+			want:  "[INF] msg\n  body=line1\n      │line2\n  err =a\n      │b\n",
+		},
+		{
+			name: "error unwrap",
+			handler: func(buf *bytes.Buffer) slog.Handler {
+				return NewCLIHandler(buf, WithStyle(Style0()), WithMultiline(true), WithErrorUnwrap(true))
+			},
+			attrs: []slog.Attr{slog.Any("err", fmt.Errorf("open: %w", errors.New("denied")))},
+			want:  "[INF] msg\n  err      =\"open: denied\"\n  err.cause=denied\n",
+		},
+		{
+			name: "trace",
+			handler: func(buf *bytes.Buffer) slog.Handler {
+				return NewCLIHandler(buf, WithStyle(Style0()), WithMultiline(true), WithTraceCorrelation(true))
+			},
+			ctx: trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+				TraceID: trace.TraceID{1},
+				SpanID:  trace.SpanID{2},
+			})),
+			want: "[INF] msg\n  trace_id=01000000000000000000000000000000\n  span_id =0200000000000000\n",
+		},
+		{
+			name: "colors",
+			handler: func(buf *bytes.Buffer) slog.Handler {
+				return NewCLIHandler(buf, WithStyle(Style1()), WithMultiline(true))
+			},
+			attrs: []slog.Attr{slog.String("err", "x\ny")},
+			want:  "\x1b[1;92mINF\x1b[0m msg\n  \x1b[91merr\x1b[0m\x1b[91m=\x1b[0m\x1b[91mx\x1b[0m\n     \x1b[90m│\x1b[0m\x1b[91my\x1b[0m\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			ctx := tt.ctx
+			if ctx == nil {
+				ctx = context.Background()
+			}
+			r := slog.NewRecord(time.Time{}, slog.LevelInfo, "msg", 0)
+			r.AddAttrs(tt.attrs...)
+			if err := tt.handler(buf).Handle(ctx, r); err != nil {
+				t.Fatal(err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package log
+
+import (
+	"log/slog"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// WithNumberFormatter returns a StyleOption that sets a function writing the
+// integers and floats in attribute values, e.g. Thousands(","). A nil function
+// writes them as is. Handlers that encode values, such as slog.JSONHandler and
+// the logfmt handler, keep writing the raw numbers.
+func WithNumberFormatter(fn func(v slog.Value) string) StyleOption {
+	return func(s *Style) {
+		s.Attr.NumberFormatter = fn
+	}
+}
+
+// Thousands returns a number formatter for WithNumberFormatter that groups the
+// digits of integers and of the integral part of floats by thousands with sep,
+// e.g. 1,234,567 and 1,234.5 for ",". Floats written in exponent form are kept.
+func Thousands(sep string) func(v slog.Value) string {
+	return func(v slog.Value) string {
+		var s string
+		switch v.Kind() {
+		case slog.KindInt64:
+			s = strconv.FormatInt(v.Int64(), 10)
+		case slog.KindUint64:
+			s = strconv.FormatUint(v.Uint64(), 10)
+		case slog.KindFloat64:
+			f := v.Float64()
+			if math.IsNaN(f) || math.IsInf(f, 0) || math.Abs(f) >= 1e21 {
+				return strconv.FormatFloat(f, 'g', -1, 64)
+			}
+			s = strconv.FormatFloat(f, 'f', -1, 64)
+		default:
+			return v.String()
+		}
+		return groupDigits(s, sep)
+	}
+}
+
+// isNumber reports whether v holds an integer or a float.
+func isNumber(v slog.Value) bool {
+	switch v.Kind() {
+	case slog.KindInt64, slog.KindUint64, slog.KindFloat64:
+		return true
+	default:
+		return false
+	}
+}
+
+// groupDigits inserts sep between the thousands of the integral part of the
+// decimal number s.
+func groupDigits(s, sep string) string {
+	sign := ""
+	if strings.HasPrefix(s, "-") {
+		sign, s = "-", s[1:]
+	}
+	frac := ""
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		s, frac = s[:i], s[i:]
+	}
+	if len(s) <= 3 {
+		return sign + s + frac
+	}
+	var b strings.Builder
+	b.WriteString(sign)
+	head := len(s) % 3
+	if head > 0 {
+		b.WriteString(s[:head])
+	}
+	for i := head; i < len(s); i += 3 {
+		if i > 0 {
+			b.WriteString(sep)
+		}
+		b.WriteString(s[i : i+3])
+	}
+	b.WriteString(frac)
+	return b.String()
+}
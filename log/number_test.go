@@ -0,0 +1,49 @@
+package log
+
+import (
+	"bytes"
+	"log/slog"
+	"math"
+	"testing"
+)
+
+func TestThousands(t *testing.T) {
+	tests := []struct {
+		v    slog.Value
+		want string
+	}{
+		{v: slog.Int64Value(0), want: "0"},
+		{v: slog.Int64Value(999), want: "999"},
+		{v: slog.Int64Value(1000), want: "1,000"},
+		{v: slog.Int64Value(1234567), want: "1,234,567"},
+		{v: slog.Int64Value(-123456), want: "-123,456"},
+		{v: slog.Uint64Value(math.MaxUint64), want: "18,446,744,073,709,551,615"},
+		{v: slog.Float64Value(1234.5), want: "1,234.5"},
+		{v: slog.Float64Value(-0.25), want: "-0.25"},
+		{v: slog.Float64Value(1e21), want: "1e+21"},
+		{v: slog.Float64Value(math.Inf(1)), want: "+Inf"},
+	}
+	f := Thousands(",")
+	for _, tt := range tests {
+		if got := f(tt.v); got != tt.want {
+			t.Errorf("Thousands(%v) = %q, want %q", tt.v, got, tt.want)
+		}
+	}
+}
+
+func TestWithNumberFormatter(t *testing.T) {
+	buf := &bytes.Buffer{}
+	style := NewStyle(WithNumberFormatter(Thousands(",")))
+	l := NewLogger(NewCLIHandler(buf, WithStyle(style)))
+	l.Info("msg", "n", 1234567, "u", uint64(1000), "f", 12345.5, "s", "1234")
+	if want := "[INF] msg n=1,234,567 u=1,000 f=12,345.5 s=1234\n"; buf.String() != want {
+		t.Errorf("got %q, want %q", buf.String(), want)
+	}
+
+	buf.Reset()
+	style = NewStyle(WithNumberFormatter(Thousands(" ")))
+	NewLogger(NewCLIHandler(buf, WithStyle(style))).Info("msg", "n", 1234)
+	if want := "[INF] msg n=\"1 234\"\n"; buf.String() != want {
+		t.Errorf("got %q, want %q", buf.String(), want)
+	}
+}
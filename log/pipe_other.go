@@ -0,0 +1,18 @@
+//go:build !(darwin || dragonfly || freebsd || linux || netbsd || openbsd)
+
+package log
+
+import (
+	"errors"
+	"io"
+)
+
+// IgnoreSIGPIPE does nothing on this platform, where writes to a broken pipe
+// return errors.
+func IgnoreSIGPIPE() {}
+
+// isBrokenPipe reports whether err is the error of writing to a closed pipe, as
+// returned by io.Pipe.
+func isBrokenPipe(err error) bool {
+	return errors.Is(err, io.ErrClosedPipe)
+}
@@ -0,0 +1,25 @@
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd
+
+package log
+
+import (
+	"errors"
+	"io"
+	"os/signal"
+	"syscall"
+)
+
+// IgnoreSIGPIPE makes writes to a broken pipe on standard output or standard
+// error fail with EPIPE instead of terminating the program with SIGPIPE, so that
+// a CLIHandler writing to them falls silent when their reader exits, e.g. in
+// mycli | head. It applies to the whole program, so other writes to the broken
+// pipe return errors too.
+func IgnoreSIGPIPE() {
+	signal.Ignore(syscall.SIGPIPE)
+}
+
+// isBrokenPipe reports whether err is the error of writing to a pipe whose
+// reader has gone, as in mycli | head.
+func isBrokenPipe(err error) bool {
+	return errors.Is(err, syscall.EPIPE) || errors.Is(err, io.ErrClosedPipe)
+}
@@ -0,0 +1,51 @@
+package log
+
+import (
+	"io"
+	"log/slog"
+	"time"
+)
+
+// RFC3339Milli is the RFC 3339 time layout with milliseconds, as used by
+// NewDevelopment.
+const RFC3339Milli = "2006-01-02T15:04:05.000Z07:00"
+
+// Sampling of NewProduction: the first productionBurst records with the same
+// level and message are written each productionInterval.
+const (
+	productionBurst    = 100
+	productionInterval = time.Second
+)
+
+// NewDevelopment creates a new Logger for local development, writing to w in
+// the CLI format from slog.LevelDebug, with the time in RFC3339Milli and the
+// caller. Colors are written to terminals. The options are applied after these,
+// so they can be overridden:
+//
+//	logger := log.NewDevelopment(os.Stderr, log.WithLabel("app"))
+func NewDevelopment(w io.Writer, opts ...CLIHandlerOption) *Logger {
+	opts = append([]CLIHandlerOption{
+		WithLevel(slog.LevelDebug),
+		WithTime(true),
+		WithTimeFormat(RFC3339Milli),
+		WithCaller(true),
+	}, opts...)
+	return NewLogger(NewCLIHandler(w, opts...))
+}
+
+// NewProduction creates a new Logger for production, writing JSON to w from
+// slog.LevelInfo. Records repeated with the same level and message are sampled
+// to 100 per second, with the number dropped reported under SuppressedKey;
+// errors are never dropped. The options are applied after these, so they can
+// override the level and the other options used by FormatJSON:
+//
+//	logger := log.NewProduction(os.Stdout)
+func NewProduction(w io.Writer, opts ...CLIHandlerOption) *Logger {
+	opts = append([]CLIHandlerOption{WithLevel(slog.LevelInfo)}, opts...)
+	h := newJSONHandler(w, opts)
+	return NewLogger(NewSamplingHandler(h,
+		WithBurst(productionBurst),
+		WithPerInterval(productionInterval),
+		WithLevelExempt(slog.LevelError),
+	))
+}
@@ -0,0 +1,50 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestNewDevelopment(t *testing.T) {
+	buf := &bytes.Buffer{}
+	l := NewDevelopment(buf, WithStyle(Style0()))
+	l.Debug("msg", "k", "v")
+	re := regexp.MustCompile(`^\[DBG\] <preset_test\.go:\d+> msg time=\d{4}-\d\d-\d\dT\d\d:\d\d:\d\d\.\d{3}(Z|[+-]\d\d:\d\d) k=v\n$`)
+	if got := buf.String(); !re.MatchString(got) {
+		t.Errorf("got %q, want debug record with time in milliseconds and caller", got)
+	}
+
+	buf.Reset()
+	NewDevelopment(buf, WithStyle(Style0()), WithLevel(slog.LevelInfo)).Debug("msg")
+	if buf.Len() != 0 {
+		t.Errorf("got %q, want level overridden", buf.String())
+	}
+}
+
+func TestNewProduction(t *testing.T) {
+	buf := &bytes.Buffer{}
+	l := NewProduction(buf)
+	l.Debug("hidden")
+	for range productionBurst + 10 {
+		l.Info("repeated")
+	}
+	l.Error("failed")
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != productionBurst+1 {
+		t.Fatalf("got %d records, want %d", len(lines), productionBurst+1)
+	}
+	var m map[string]any
+	if err := json.Unmarshal([]byte(lines[len(lines)-1]), &m); err != nil {
+		t.Fatal(err)
+	}
+	if m["level"] != "ERROR" || m["msg"] != "failed" {
+		t.Errorf("last record = %v, want the error", m)
+	}
+	if _, ok := l.Handler().(*SamplingHandler); !ok {
+		t.Errorf("handler = %T, want *SamplingHandler", l.Handler())
+	}
+}
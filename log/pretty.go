@@ -0,0 +1,94 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+)
+
+// Colors of pretty JSON output, as jq writes them.
+var (
+	jsonKeyColor    = NewColor(Bold, FgBlue)
+	jsonStringColor = NewColor(FgGreen)
+	jsonNullColor   = NewColor(FgHiBlack)
+)
+
+// WithPretty returns a CLIHandlerOption that makes New write FormatJSON records
+// indented over several lines, for reading during development. Keys and values
+// are colored as jq colors them unless colors are disabled by WithColorMode or
+// the writer. Other formats ignore it.
+func WithPretty(pretty bool) CLIHandlerOption {
+	return func(c *CLIHandler) {
+		c.hasPretty = pretty
+	}
+}
+
+// prettyWriter indents the JSON records written to it before writing them to w.
+// Each call to Write must hold whole records, as slog.JSONHandler writes them.
+type prettyWriter struct {
+	w     io.Writer
+	color bool
+}
+
+// newPrettyWriter returns a prettyWriter writing to w in the given color mode.
+func newPrettyWriter(w io.Writer, mode ColorMode) *prettyWriter {
+	return &prettyWriter{w: setColorable(w), color: colorEnabled(w, mode)}
+}
+
+// Write implements io.Writer. Input that is not valid JSON is written as is.
+func (p *prettyWriter) Write(b []byte) (int, error) {
+	buf := bufPool.Get().(*bytes.Buffer)
+	buf.Reset()
+	defer bufPool.Put(buf)
+	if err := json.Indent(buf, b, "", "  "); err != nil {
+		return p.w.Write(b)
+	}
+	if p.color {
+		tmp := bufPool.Get().(*bytes.Buffer)
+		tmp.Reset()
+		defer bufPool.Put(tmp)
+		colorJSON(tmp, buf.Bytes())
+		buf = tmp
+	}
+	if _, err := p.w.Write(buf.Bytes()); err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
+
+// colorJSON writes the valid JSON b to buf with keys, strings and null colored.
+func colorJSON(buf *bytes.Buffer, b []byte) {
+	for i := 0; i < len(b); {
+		switch c := b[i]; {
+		case c == '"':
+			j := i + 1
+			for ; j < len(b) && b[j] != '"'; j++ {
+				if b[j] == '\\' {
+					j++
+				}
+			}
+			j++
+			color := jsonStringColor
+			if k := skipSpace(b, j); k < len(b) && b[k] == ':' {
+				color = jsonKeyColor
+			}
+			color.WriteBytes(buf, b[i:j])
+			i = j
+		case c == 'n' && bytes.HasPrefix(b[i:], []byte("null")):
+			jsonNullColor.WriteString(buf, "null")
+			i += len("null")
+		default:
+			buf.WriteByte(c)
+			i++
+		}
+	}
+}
+
+// skipSpace returns the index of the first byte of b from i that is not JSON
+// white space.
+func skipSpace(b []byte, i int) int {
+	for i < len(b) && (b[i] == ' ' || b[i] == '\t' || b[i] == '\n' || b[i] == '\r') {
+		i++
+	}
+	return i
+}
@@ -0,0 +1,46 @@
+package log
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestWithPretty(t *testing.T) {
+	buf := &bytes.Buffer{}
+	l := NewLogger(New(buf, WithFormat(FormatJSON), WithPretty(true), WithColorMode(ColorNever)))
+	l.Info("msg", "n", 1, "v", nil)
+	got := buf.String()
+	if !strings.HasPrefix(got, "{\n  \"time\": ") || !strings.HasSuffix(got, "  \"n\": 1,\n  \"v\": null\n}\n") {
+		t.Errorf("got %q, want indented JSON", got)
+	}
+}
+
+func TestWithPretty_compact(t *testing.T) {
+	buf := &bytes.Buffer{}
+	l := NewLogger(New(buf, WithFormat(FormatJSON), WithPretty(false)))
+	l.Info("msg")
+	if bytes.Count(buf.Bytes(), []byte("\n")) != 1 {
+		t.Errorf("got %q, want a single line", buf.String())
+	}
+}
+
+func Test_colorJSON(t *testing.T) {
+	buf := &bytes.Buffer{}
+	colorJSON(buf, []byte(`{"k": "v\"x", "n": null, "b": true}`))
+	want := "{\x1b[1;34m\"k\"\x1b[0m: \x1b[32m\"v\\\"x\"\x1b[0m, \x1b[1;34m\"n\"\x1b[0m: \x1b[90mnull\x1b[0m, \x1b[1;34m\"b\"\x1b[0m: true}"
+	if got := buf.String(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestPrettyWriter_invalid(t *testing.T) {
+	buf := &bytes.Buffer{}
+	p := newPrettyWriter(buf, ColorAlways)
+	if n, err := p.Write([]byte("not json\n")); err != nil || n != 9 {
+		t.Fatalf("Write = %d, %v", n, err)
+	}
+	if got := buf.String(); got != "not json\n" {
+		t.Errorf("got %q, want input as is", got)
+	}
+}
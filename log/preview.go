@@ -0,0 +1,121 @@
+package log
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"reflect"
+	"runtime"
+	"slices"
+	"strings"
+	"time"
+)
+
+// previewTime is the time of the sample records, fixed so that previews are
+// reproducible.
+var previewTime = time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC)
+
+// previewSamples are the messages and attributes of the sample records.
+var previewSamples = map[slog.Level]struct {
+	msg  string
+	args []any
+}{
+	LevelTrace:      {"reading config", []any{"path", "config.yaml"}},
+	slog.LevelDebug: {"connecting to database", []any{"host", "localhost", "port", 5432}},
+	slog.LevelInfo:  {"server started", []any{"addr", ":8080", slog.Group("tls", "enabled", true)}},
+	LevelNotice:     {"config reloaded", []any{"changes", 2}},
+	slog.LevelWarn:  {"slow request", []any{"path", "/api/users", "duration", 1200 * time.Millisecond}},
+	slog.LevelError: {"request failed", []any{"error", errors.New("connection refused"), "retry", 3}},
+	LevelFatal:      {"shutting down", []any{"error", errors.New("disk full")}},
+}
+
+// Preview writes a sample record at each of the debug, info, warn and error
+// levels, and at the other levels the style defines, to w in the style, with
+// the time, the caller of Preview, a label and attributes. It lets theme authors
+// review a style and CLI applications list their styles. The options are
+// applied after the defaults, e.g. WithColorMode(ColorAlways) to keep colors
+// when w is not a terminal.
+func (s *Style) Preview(w io.Writer, opts ...CLIHandlerOption) error {
+	var pcs [1]uintptr
+	runtime.Callers(2, pcs[:]) // skip [Callers, Preview]
+	return s.preview(w, pcs[0], opts...)
+}
+
+// PreviewStyles writes the names of the registered styles, each followed by
+// its preview as written by Style.Preview. Names of identical styles, such as
+// "plain" and "style0", are written together.
+func PreviewStyles(w io.Writer, opts ...CLIHandlerOption) error {
+	var pcs [1]uintptr
+	runtime.Callers(2, pcs[:]) // skip [Callers, PreviewStyles]
+	type entry struct {
+		names []string
+		style *Style
+	}
+	var entries []*entry
+	for _, name := range StyleNames() {
+		s, ok := GetStyle(name)
+		if !ok {
+			continue
+		}
+		f := encodeStyle(s)
+		i := slices.IndexFunc(entries, func(e *entry) bool {
+			return reflect.DeepEqual(encodeStyle(e.style), f)
+		})
+		if i < 0 {
+			entries = append(entries, &entry{style: s})
+			i = len(entries) - 1
+		}
+		entries[i].names = append(entries[i].names, name)
+	}
+	for i, e := range entries {
+		if i > 0 {
+			if _, err := io.WriteString(w, "\n"); err != nil {
+				return err
+			}
+		}
+		if _, err := fmt.Fprintf(w, "%s:\n", strings.Join(e.names, ", ")); err != nil {
+			return err
+		}
+		if err := e.style.preview(w, pcs[0], opts...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// preview writes the sample records with the given caller.
+func (s *Style) preview(w io.Writer, pc uintptr, opts ...CLIHandlerOption) error {
+	levels := []slog.Level{slog.LevelDebug, slog.LevelInfo, slog.LevelWarn, slog.LevelError}
+	for level := range s.Level {
+		if !slices.Contains(levels, level) {
+			levels = append(levels, level)
+		}
+	}
+	slices.Sort(levels)
+	h := NewCLIHandler(w, append([]CLIHandlerOption{
+		WithStyle(s),
+		WithLevel(levels[0]),
+		WithLabel("preview"),
+		WithTime(true),
+		WithTimeFormat(time.TimeOnly),
+		WithCaller(true),
+	}, opts...)...)
+	ctx := context.Background()
+	for _, level := range levels {
+		if !h.Enabled(ctx, level) {
+			continue
+		}
+		sample, ok := previewSamples[level]
+		if !ok {
+			sample.msg = "message at " + levelName(level)
+		}
+		r := slog.NewRecord(previewTime, level, sample.msg, pc)
+		r.Add(sample.args...)
+		if err := h.Handle(ctx, r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package log
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestStyle_Preview(t *testing.T) {
+	tests := []struct {
+		name  string
+		style *Style
+		opts  []CLIHandlerOption
+		want  string
+	}{
+		{
+			name:  "levels",
+			style: Style0(),
+			opts:  []CLIHandlerOption{WithCaller(false)},
+			want: "[TRC] preview reading config time=15:04:05 path=config.yaml\n" +
+				"[DBG] preview connecting to database time=15:04:05 host=localhost port=5432\n" +
+				"[INF] preview server started time=15:04:05 addr=:8080 tls.enabled=true\n" +
+				"[NTC] preview config reloaded time=15:04:05 changes=2\n" +
+				"[WRN] preview slow request time=15:04:05 path=/api/users duration=1.2s\n" +
+				"[ERR] preview request failed time=15:04:05 error=\"connection refused\" retry=3\n" +
+				"[FTL] preview shutting down time=15:04:05 error=\"disk full\"\n",
+		},
+		{
+			name:  "custom levels",
+			style: NewStyle(WithLevelStyle(map[slog.Level]LevelStyle{LevelFatal: {Text: "[FTL]"}, slog.LevelDebug - 4: {Text: "[TRC]"}})),
+			opts:  []CLIHandlerOption{WithCaller(false), WithTime(false), WithLevel(slog.LevelError)},
+			want: "[ERR] preview request failed error=\"connection refused\" retry=3\n" +
+				"[FTL] preview shutting down error=\"disk full\"\n",
+		},
+		{
+			name:  "lowest custom level",
+			style: NewStyle(WithLevelStyle(map[slog.Level]LevelStyle{LevelTrace - 1: {Text: "[TRC]"}})),
+			opts:  []CLIHandlerOption{WithCaller(false), WithTime(false), WithLabel("")},
+			want: "[TRC] message at trace-1\n" +
+				"[TRC] reading config path=config.yaml\n" +
+				"[DBG] connecting to database host=localhost port=5432\n" +
+				"[INF] server started addr=:8080 tls.enabled=true\n" +
+				"[NTC] config reloaded changes=2\n" +
+				"[WRN] slow request path=/api/users duration=1.2s\n" +
+				"[ERR] request failed error=\"connection refused\" retry=3\n" +
+				"[FTL] shutting down error=\"disk full\"\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			if err := tt.style.Preview(buf, tt.opts...); err != nil {
+				t.Fatal(err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStyle_Preview_caller(t *testing.T) {
+	buf := &bytes.Buffer{}
+	if err := Style0().Preview(buf); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(buf.String(), "[TRC] <preview_test.go:") {
+		t.Errorf("got %q, want the caller of Preview", buf.String())
+	}
+}
+
+func TestPreviewStyles(t *testing.T) {
+	RegisterStyle("custom-preview", NewStyle(WithLevelStyle(map[slog.Level]LevelStyle{slog.LevelInfo: {Text: "[I]"}})))
+	defer RegisterStyle("custom-preview", nil)
+	buf := &bytes.Buffer{}
+	if err := PreviewStyles(buf, WithColorMode(ColorNever), WithCaller(false), WithTime(false)); err != nil {
+		t.Fatal(err)
+	}
+	got := buf.String()
+	for _, want := range []string{
+		"basic, style1:\nTRC preview reading config",
+		"\n\ncustom-preview:\n[TRC] preview reading config path=config.yaml\n[DBG] preview connecting to database host=localhost port=5432\n[I] preview server started",
+		"\n\nicons, style5:\n· preview",
+		"\n\nplain, style0:\n[TRC] preview",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("got %q, want it to contain %q", got, want)
+		}
+	}
+	if strings.Contains(got, "\nstyle0") {
+		t.Errorf("got %q, want identical styles listed together", got)
+	}
+}
@@ -0,0 +1,199 @@
+package log
+
+import (
+	"os"
+	"strings"
+)
+
+// ColorProfile describes the range of colors a terminal can display.
+type ColorProfile int
+
+const (
+	// ProfileANSI supports the 16 basic and bright SGR colors.
+	ProfileANSI ColorProfile = iota + 1
+
+	// ProfileANSI256 supports the 256-color palette.
+	ProfileANSI256
+
+	// ProfileTrueColor supports 24-bit RGB colors.
+	ProfileTrueColor
+)
+
+// String returns the name of the profile.
+func (p ColorProfile) String() string {
+	switch p {
+	case ProfileANSI:
+		return "ansi"
+	case ProfileANSI256:
+		return "ansi256"
+	case ProfileTrueColor:
+		return "truecolor"
+	default:
+		return "unknown"
+	}
+}
+
+// DetectColorProfile reports the color profile of the current terminal.
+// COLORTERM and TERM are consulted first; on Windows the console version
+// decides between the legacy 16-color console and virtual terminal support.
+func DetectColorProfile() ColorProfile {
+	switch strings.ToLower(os.Getenv("COLORTERM")) {
+	case "truecolor", "24bit":
+		return ProfileTrueColor
+	}
+	term := strings.ToLower(os.Getenv("TERM"))
+	switch {
+	case strings.Contains(term, "truecolor"), strings.Contains(term, "24bit"), strings.Contains(term, "direct"):
+		return ProfileTrueColor
+	case strings.Contains(term, "256color"):
+		return ProfileANSI256
+	}
+	if p, ok := platformColorProfile(); ok {
+		return p
+	}
+	return ProfileANSI
+}
+
+// xterm default RGB values for the 16 basic colors.
+var ansiPalette = [16][3]int{
+	{0, 0, 0}, {205, 0, 0}, {0, 205, 0}, {205, 205, 0},
+	{0, 0, 238}, {205, 0, 205}, {0, 205, 205}, {229, 229, 229},
+	{127, 127, 127}, {255, 0, 0}, {0, 255, 0}, {255, 255, 0},
+	{92, 92, 255}, {255, 0, 255}, {0, 255, 255}, {255, 255, 255},
+}
+
+// cubeLevels are the channel values of the 6x6x6 color cube.
+var cubeLevels = [6]int{0, 95, 135, 175, 215, 255}
+
+// Downgrade returns a copy of the Style with the 24-bit and 256-color codes of
+// every Color converted to the nearest colors of the profile, so that the style
+// stays legible on terminals with fewer colors. Both variants of adaptive colors
+// are converted. ProfileTrueColor, or a zero profile, keeps the colors as they
+// are. CLIHandler downgrades its style automatically; see WithColorProfile.
+func (s *Style) Downgrade(profile ColorProfile) *Style {
+	return s.mapColors(func(c *Color) *Color {
+		return c.downgrade(profile)
+	})
+}
+
+// downgrade returns the Color with extended color sequences converted to the
+// profile. Colors that need no conversion are returned unchanged.
+func (c *Color) downgrade(profile ColorProfile) *Color {
+	if c != nil && c.adaptive != nil && profile > 0 && profile < ProfileTrueColor {
+		a := *c.adaptive
+		if a.Light.downgrade(profile) == a.Light && a.Dark.downgrade(profile) == a.Dark {
+			return c
+		}
+		return AdaptiveColor{Light: a.Light.downgrade(profile), Dark: a.Dark.downgrade(profile)}.Color()
+	}
+	if c == nil || len(c.codes) == 0 || profile == 0 || profile >= ProfileTrueColor {
+		return c
+	}
+	codes := make([]int, 0, len(c.codes))
+	changed := false
+	for i := 0; i < len(c.codes); i++ {
+		code := c.codes[i]
+		if (code != 38 && code != 48) || i+1 >= len(c.codes) {
+			codes = append(codes, code)
+			continue
+		}
+		switch {
+		case c.codes[i+1] == 2 && i+4 < len(c.codes):
+			r, g, b := c.codes[i+2], c.codes[i+3], c.codes[i+4]
+			if profile == ProfileANSI256 {
+				codes = append(codes, code, 5, rgbTo256(r, g, b))
+			} else {
+				codes = append(codes, ansiCode(code, rgbToANSI(r, g, b)))
+			}
+			i += 4
+			changed = true
+		case c.codes[i+1] == 5 && i+2 < len(c.codes) && profile == ProfileANSI:
+			codes = append(codes, ansiCode(code, ansi256ToANSI(c.codes[i+2])))
+			i += 2
+			changed = true
+		default:
+			codes = append(codes, code)
+		}
+	}
+	if !changed {
+		return c
+	}
+	return NewColor(codes...)
+}
+
+// ansiCode returns the foreground or background SGR code for the basic color n.
+func ansiCode(ext, n int) int {
+	base := FgBlack
+	if n >= 8 {
+		base, n = FgHiBlack, n-8
+	}
+	if ext == 48 {
+		base += BgBlack - FgBlack
+	}
+	return base + n
+}
+
+// rgbTo256 returns the nearest 256-color palette index for the RGB value.
+func rgbTo256(r, g, b int) int {
+	ri, gi, bi := cubeIndex(r), cubeIndex(g), cubeIndex(b)
+	cube := 16 + 36*ri + 6*gi + bi
+	cd := distance(r, g, b, cubeLevels[ri], cubeLevels[gi], cubeLevels[bi])
+
+	avg := (r + g + b) / 3
+	gray := 23
+	if avg < 238 {
+		gray = max(0, (avg-3)/10)
+	}
+	gv := 8 + gray*10
+	if distance(r, g, b, gv, gv, gv) < cd {
+		return 232 + gray
+	}
+	return cube
+}
+
+// cubeIndex returns the nearest color cube level for a channel value.
+func cubeIndex(v int) int {
+	switch {
+	case v < 48:
+		return 0
+	case v < 115:
+		return 1
+	default:
+		return min(5, (v-35)/40)
+	}
+}
+
+// ansi256ToANSI returns the nearest basic color for a 256-color palette index.
+func ansi256ToANSI(n int) int {
+	switch {
+	case n < 0:
+		return 0
+	case n < 16:
+		return n
+	case n < 232:
+		n -= 16
+		return rgbToANSI(cubeLevels[n/36], cubeLevels[n/6%6], cubeLevels[n%6])
+	case n < 256:
+		v := 8 + (n-232)*10
+		return rgbToANSI(v, v, v)
+	default:
+		return 15
+	}
+}
+
+// rgbToANSI returns the nearest basic color for the RGB value.
+func rgbToANSI(r, g, b int) int {
+	best, bestDist := 0, -1
+	for i, c := range ansiPalette {
+		if d := distance(r, g, b, c[0], c[1], c[2]); bestDist < 0 || d < bestDist {
+			best, bestDist = i, d
+		}
+	}
+	return best
+}
+
+// distance returns the squared euclidean distance between two RGB values.
+func distance(r1, g1, b1, r2, g2, b2 int) int {
+	dr, dg, db := r1-r2, g1-g2, b1-b2
+	return dr*dr + dg*dg + db*db
+}
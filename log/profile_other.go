@@ -0,0 +1,8 @@
+//go:build !windows
+
+package log
+
+// platformColorProfile has no platform-specific probe outside Windows.
+func platformColorProfile() (ColorProfile, bool) {
+	return 0, false
+}
@@ -0,0 +1,221 @@
+package log
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"os"
+	"reflect"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestColorProfile_String(t *testing.T) {
+	tests := []struct {
+		profile ColorProfile
+		want    string
+	}{
+		{ProfileANSI, "ansi"},
+		{ProfileANSI256, "ansi256"},
+		{ProfileTrueColor, "truecolor"},
+		{ColorProfile(0), "unknown"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			if got := tt.profile.String(); got != tt.want {
+				t.Errorf("String() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDetectColorProfile(t *testing.T) {
+	tests := []struct {
+		name      string
+		colorterm string
+		term      string
+		want      ColorProfile
+	}{
+		{name: "colorterm truecolor", colorterm: "truecolor", want: ProfileTrueColor},
+		{name: "colorterm 24bit", colorterm: "24bit", term: "xterm", want: ProfileTrueColor},
+		{name: "term direct", term: "xterm-direct", want: ProfileTrueColor},
+		{name: "term 256color", term: "xterm-256color", want: ProfileANSI256},
+		{name: "term basic", term: "xterm", want: ProfileANSI},
+	}
+	if runtime.GOOS == "windows" {
+		t.Skip("console version takes part in detection on windows")
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("COLORTERM", tt.colorterm)
+			t.Setenv("TERM", tt.term)
+			if got := DetectColorProfile(); got != tt.want {
+				t.Errorf("DetectColorProfile() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_colorProfile(t *testing.T) {
+	t.Setenv("COLORTERM", "truecolor")
+	tests := []struct {
+		name    string
+		w       io.Writer
+		profile ColorProfile
+		want    ColorProfile
+	}{
+		{name: "explicit", w: os.Stdout, profile: ProfileANSI, want: ProfileANSI},
+		{name: "file detects", w: os.Stdout, want: ProfileTrueColor},
+		{name: "non-file writer", w: &bytes.Buffer{}, want: ProfileTrueColor},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := colorProfile(tt.w, tt.profile); got != tt.want {
+				t.Errorf("colorProfile() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestColor_downgrade(t *testing.T) {
+	tests := []struct {
+		name    string
+		color   *Color
+		profile ColorProfile
+		want    []int
+	}{
+		{name: "nil", color: nil, profile: ProfileANSI, want: nil},
+		{name: "truecolor kept", color: NewColor(38, 2, 95, 95, 255, Bold), profile: ProfileTrueColor, want: []int{38, 2, 95, 95, 255, Bold}},
+		{name: "basic kept", color: NewColor(Bold, FgHiRed), profile: ProfileANSI, want: []int{Bold, FgHiRed}},
+		{name: "rgb to 256", color: NewColor(38, 2, 95, 95, 255, Bold), profile: ProfileANSI256, want: []int{38, 5, 63, Bold}},
+		{name: "rgb bg to 256", color: NewColor(48, 2, 255, 95, 135), profile: ProfileANSI256, want: []int{48, 5, 204}},
+		{name: "rgb to ansi", color: NewColor(38, 2, 95, 95, 255, Bold), profile: ProfileANSI, want: []int{FgHiBlue, Bold}},
+		{name: "rgb bg to ansi", color: NewColor(48, 2, 95, 255, 215, Bold), profile: ProfileANSI, want: []int{BgHiCyan, Bold}},
+		{name: "256 kept", color: NewColor(38, 5, 200), profile: ProfileANSI256, want: []int{38, 5, 200}},
+		{name: "256 to ansi", color: NewColor(38, 5, 9), profile: ProfileANSI, want: []int{FgHiRed}},
+		{name: "256 gray to ansi", color: NewColor(48, 5, 232), profile: ProfileANSI, want: []int{BgBlack}},
+		{name: "truncated sequence", color: NewColor(38, 2, 1), profile: ProfileANSI, want: []int{38, 2, 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.color.downgrade(tt.profile)
+			if got == nil {
+				if tt.want != nil {
+					t.Fatalf("downgrade() = nil, want %v", tt.want)
+				}
+				return
+			}
+			if !reflect.DeepEqual(got.codes, tt.want) {
+				t.Errorf("downgrade() = %v, want %v", got.codes, tt.want)
+			}
+			if !reflect.DeepEqual(got.prefix, makeSGR(tt.want)) {
+				t.Errorf("prefix = %q, want %q", got.prefix, makeSGR(tt.want))
+			}
+		})
+	}
+}
+
+func Test_rgbTo256(t *testing.T) {
+	tests := []struct {
+		r, g, b int
+		want    int
+	}{
+		{0, 0, 0, 16},
+		{255, 255, 255, 231},
+		{95, 255, 215, 86},
+		{128, 128, 128, 244},
+		{238, 238, 238, 255},
+	}
+	for _, tt := range tests {
+		if got := rgbTo256(tt.r, tt.g, tt.b); got != tt.want {
+			t.Errorf("rgbTo256(%d, %d, %d) = %d, want %d", tt.r, tt.g, tt.b, got, tt.want)
+		}
+	}
+}
+
+func Test_ansi256ToANSI(t *testing.T) {
+	tests := []struct {
+		n    int
+		want int
+	}{
+		{-1, 0},
+		{3, 3},
+		{196, 9},
+		{231, 15},
+		{244, 8},
+		{300, 15},
+	}
+	for _, tt := range tests {
+		if got := ansi256ToANSI(tt.n); got != tt.want {
+			t.Errorf("ansi256ToANSI(%d) = %d, want %d", tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestColor_downgrade_adaptive(t *testing.T) {
+	c := AdaptiveColor{Light: NewColor(38, 5, 9), Dark: NewColor(Bold)}.Color()
+	got := c.downgrade(ProfileANSI)
+	if got.adaptive == nil {
+		t.Fatal("downgrade() lost the adaptive variants")
+	}
+	if !reflect.DeepEqual(got.adaptive.Light, NewColor(FgHiRed)) || got.adaptive.Dark != c.adaptive.Dark {
+		t.Errorf("downgrade() = %+v", got.adaptive)
+	}
+	if c.downgrade(ProfileANSI256) != c {
+		t.Error("downgrade() replaced a color needing no conversion")
+	}
+}
+
+func TestStyle_Downgrade(t *testing.T) {
+	tests := []struct {
+		name    string
+		style   *Style
+		profile ColorProfile
+		want    *Color
+	}{
+		{name: "ansi", style: Style4(), profile: ProfileANSI, want: NewColor(BgHiCyan, Bold)},
+		{name: "ansi256", style: Style4(), profile: ProfileANSI256, want: NewColor(48, 5, 86, Bold)},
+		{name: "truecolor", style: Style4(), profile: ProfileTrueColor, want: NewColor(48, 2, 95, 255, 215, Bold)},
+		{name: "zero profile", style: Style4(), profile: 0, want: NewColor(48, 2, 95, 255, 215, Bold)},
+		{name: "basic colors", style: Style1(), profile: ProfileANSI, want: NewColor(Bold, FgHiGreen)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.style.Downgrade(tt.profile)
+			if c := got.Level[slog.LevelInfo].Color; !reflect.DeepEqual(c, tt.want) {
+				t.Errorf("info color = %v, want %v", c, tt.want)
+			}
+			if got == tt.style {
+				t.Error("Downgrade() did not return a copy")
+			}
+		})
+	}
+	if got := (*Style)(nil).Downgrade(ProfileANSI); got != nil {
+		t.Errorf("Downgrade() = %v, want nil", got)
+	}
+}
+
+func TestCLIHandler_colorProfile(t *testing.T) {
+	tests := []struct {
+		name    string
+		profile ColorProfile
+		want    string
+	}{
+		{name: "truecolor", profile: ProfileTrueColor, want: "\x1b[38;2;95;255;215;1mINF\x1b[0m hello\n"},
+		{name: "ansi256", profile: ProfileANSI256, want: "\x1b[38;5;86;1mINF\x1b[0m hello\n"},
+		{name: "ansi", profile: ProfileANSI, want: "\x1b[96;1mINF\x1b[0m hello\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			h := NewCLIHandler(buf, WithStyle(Style2()), WithColorProfile(tt.profile))
+			if err := h.Handle(t.Context(), slog.NewRecord(time.Time{}, slog.LevelInfo, "hello", 0)); err != nil {
+				t.Fatal(err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
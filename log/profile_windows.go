@@ -0,0 +1,26 @@
+package log
+
+import (
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// platformColorProfile reports the profile of the Windows console. Windows
+// Terminal supports 24-bit colors; the classic console gained 256 colors with
+// virtual terminal processing in build 10586 and 24-bit colors in build 14931.
+func platformColorProfile() (ColorProfile, bool) {
+	if os.Getenv("WT_SESSION") != "" {
+		return ProfileTrueColor, true
+	}
+	major, _, build := windows.RtlGetNtVersionNumbers()
+	build &= 0xffff
+	switch {
+	case major > 10 || major == 10 && build >= 14931:
+		return ProfileTrueColor, true
+	case major == 10 && build >= 10586:
+		return ProfileANSI256, true
+	default:
+		return ProfileANSI, true
+	}
+}
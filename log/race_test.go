@@ -0,0 +1,7 @@
+//go:build race
+
+package log
+
+// raceEnabled reports whether the race detector is enabled, which drops pooled
+// values at random and so makes allocation counts unreliable.
+const raceEnabled = true
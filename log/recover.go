@@ -0,0 +1,148 @@
+package log
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// PanicKey is the attribute key of the value of a recovered panic.
+const PanicKey = "panic"
+
+// RecoverOption defines a function type for configuring Recover and RecoverMiddleware.
+type RecoverOption func(*recoverOptions)
+
+// recoverOptions holds the configuration of Recover and RecoverMiddleware.
+type recoverOptions struct {
+	level   slog.Level
+	repanic bool
+}
+
+// WithRecoverLevel returns a RecoverOption that sets the level recovered panics
+// are logged at. The default is slog.LevelError. At LevelFatal or above, the
+// handler is flushed and the program exits with status 1 after logging.
+func WithRecoverLevel(level slog.Level) RecoverOption {
+	return func(o *recoverOptions) {
+		o.level = level
+	}
+}
+
+// WithRepanic returns a RecoverOption that panics again with the recovered value
+// after it is logged.
+func WithRepanic() RecoverOption {
+	return func(o *recoverOptions) {
+		o.repanic = true
+	}
+}
+
+// newRecoverOptions returns the configuration built from opts.
+func newRecoverOptions(opts []RecoverOption) *recoverOptions {
+	o := &recoverOptions{level: slog.LevelError}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// Recover recovers a panic of the calling goroutine and logs it with the stack
+// trace of the panicking function. It must be deferred directly:
+//
+//	defer logger.Recover(ctx)
+//
+// The record has the panicking function as its source. CLIHandler writes the
+// stack trace regardless of WithStackTrace.
+func (l *Logger) Recover(ctx context.Context, opts ...RecoverOption) {
+	v := recover()
+	if v == nil {
+		return
+	}
+	l.logPanic(ctx, v, newRecoverOptions(opts))
+}
+
+// RecoverMiddleware returns an HTTP middleware that recovers panics of the
+// wrapped handler and logs them like Recover, with the method and path of the
+// request. Unless WithRepanic is given, it responds with 500 Internal Server
+// Error. http.ErrAbortHandler is passed through without logging.
+func RecoverMiddleware(l *Logger, opts ...RecoverOption) func(http.Handler) http.Handler {
+	o := newRecoverOptions(opts)
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			defer func() {
+				v := recover()
+				if v == nil {
+					return
+				}
+				if v == http.ErrAbortHandler {
+					panic(v)
+				}
+				l.With("method", r.Method, "path", r.URL.Path).logPanic(r.Context(), v, o)
+				w.WriteHeader(http.StatusInternalServerError)
+			}()
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// logPanic logs the recovered value v, then exits or panics again as o requests.
+func (l *Logger) logPanic(ctx context.Context, v any, o *recoverOptions) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if l.Enabled(ctx, o.level) {
+		r := slog.NewRecord(time.Now(), o.level, "panic recovered", panicPC())
+		r.AddAttrs(slog.Any(PanicKey, v))
+		_ = l.Handler().Handle(withStack(ctx), r)
+	}
+	if o.level >= LevelFatal {
+		_ = l.Flush()
+		l.exitFunc()(1)
+		return
+	}
+	if o.repanic {
+		panic(v)
+	}
+}
+
+// panicPC returns the program counter of the function that panicked, found as
+// the first frame outside the runtime below runtime.gopanic. It returns 0 if the
+// goroutine is not panicking.
+func panicPC() uintptr {
+	var pcs [maxStackDepth]uintptr
+	n := runtime.Callers(2, pcs[:]) // skip [Callers, panicPC]
+	panicking := false
+	for _, pc := range pcs[:n] {
+		fn := runtime.FuncForPC(pc - 1)
+		if fn == nil {
+			continue
+		}
+		name := fn.Name()
+		switch {
+		case name == "runtime.gopanic":
+			panicking = true
+		case panicking && !strings.HasPrefix(name, "runtime."):
+			return pc
+		}
+	}
+	return 0
+}
+
+// stackKey is the context key that makes CLIHandler write the stack trace of a record.
+type stackKey struct{}
+
+// withStack returns a copy of ctx that makes CLIHandler write the stack trace
+// of the record regardless of WithStackTrace.
+func withStack(ctx context.Context) context.Context {
+	return context.WithValue(ctx, stackKey{}, true)
+}
+
+// forceStack reports whether ctx was returned by withStack.
+func forceStack(ctx context.Context) bool {
+	if ctx == nil {
+		return false
+	}
+	b, _ := ctx.Value(stackKey{}).(bool)
+	return b
+}
@@ -0,0 +1,148 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func panics() {
+	panic("boom")
+}
+
+func TestLogger_Recover(t *testing.T) {
+	buf := &bytes.Buffer{}
+	l := NewLogger(NewCLIHandler(buf, WithStyle(Style0())))
+	func() {
+		defer l.Recover(context.Background())
+		panics()
+	}()
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if want := "[ERR] panic recovered panic=boom"; lines[0] != want {
+		t.Errorf("first line = %q, want %q", lines[0], want)
+	}
+	if len(lines) < 3 {
+		t.Fatalf("got %q, want stack trace", buf.String())
+	}
+	if !strings.Contains(lines[1], "log.panics") {
+		t.Errorf("first frame = %q, want panicking function", lines[1])
+	}
+	if !strings.Contains(lines[2], "recover_test.go:") {
+		t.Errorf("first frame file = %q, want panicking file", lines[2])
+	}
+	if strings.Contains(buf.String(), "runtime.gopanic") {
+		t.Errorf("got %q, want runtime frames skipped", buf.String())
+	}
+}
+
+func TestLogger_Recover_noPanic(t *testing.T) {
+	buf := &bytes.Buffer{}
+	l := NewLogger(NewCLIHandler(buf, WithStyle(Style0())))
+	func() {
+		defer l.Recover(context.Background())
+	}()
+	if buf.Len() != 0 {
+		t.Errorf("got %q, want no output", buf.String())
+	}
+}
+
+func TestLogger_Recover_repanic(t *testing.T) {
+	buf := &bytes.Buffer{}
+	l := NewLogger(NewCLIHandler(buf, WithStyle(Style0())))
+	defer func() {
+		if v := recover(); v != "boom" {
+			t.Errorf("recovered %v, want %q", v, "boom")
+		}
+		if !strings.HasPrefix(buf.String(), "[ERR] panic recovered panic=boom\n") {
+			t.Errorf("got %q, want panic logged", buf.String())
+		}
+	}()
+	defer l.Recover(context.Background(), WithRepanic())
+	panics()
+}
+
+func TestLogger_Recover_fatal(t *testing.T) {
+	buf := &bytes.Buffer{}
+	code := -1
+	l := NewLogger(NewCLIHandler(buf, WithStyle(Style0())), WithExitFunc(func(c int) { code = c }))
+	func() {
+		defer l.Recover(context.Background(), WithRecoverLevel(LevelFatal), WithRepanic())
+		panics()
+	}()
+	if code != 1 {
+		t.Errorf("exit code = %d, want 1", code)
+	}
+	if !strings.Contains(buf.String(), " panic recovered panic=boom\n") {
+		t.Errorf("got %q, want panic logged", buf.String())
+	}
+}
+
+func TestLogger_Recover_disabled(t *testing.T) {
+	buf := &bytes.Buffer{}
+	l := NewLogger(NewCLIHandler(buf, WithStyle(Style0()), WithLevel(LevelFatal)))
+	func() {
+		defer l.Recover(context.Background())
+		panics()
+	}()
+	if buf.Len() != 0 {
+		t.Errorf("got %q, want no output", buf.String())
+	}
+}
+
+func TestRecoverMiddleware(t *testing.T) {
+	buf := &bytes.Buffer{}
+	l := NewLogger(NewCLIHandler(buf, WithStyle(Style0())))
+	h := RecoverMiddleware(l)(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		panics()
+	}))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/path", nil))
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusInternalServerError)
+	}
+	lines := strings.Split(buf.String(), "\n")
+	if want := "[ERR] panic recovered method=GET path=/path panic=boom"; lines[0] != want {
+		t.Errorf("first line = %q, want %q", lines[0], want)
+	}
+	if len(lines) < 2 || !strings.Contains(lines[1], "log.panics") {
+		t.Errorf("got %q, want stack trace from panicking function", buf.String())
+	}
+}
+
+func TestRecoverMiddleware_abort(t *testing.T) {
+	buf := &bytes.Buffer{}
+	l := NewLogger(NewCLIHandler(buf, WithStyle(Style0())))
+	h := RecoverMiddleware(l)(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		panic(http.ErrAbortHandler)
+	}))
+	defer func() {
+		if v := recover(); v != http.ErrAbortHandler {
+			t.Errorf("recovered %v, want http.ErrAbortHandler", v)
+		}
+		if buf.Len() != 0 {
+			t.Errorf("got %q, want no output", buf.String())
+		}
+	}()
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+}
+
+func TestRecoverMiddleware_repanic(t *testing.T) {
+	buf := &bytes.Buffer{}
+	l := NewLogger(NewCLIHandler(buf, WithStyle(Style0())))
+	h := RecoverMiddleware(l, WithRepanic(), WithRecoverLevel(slog.LevelWarn))(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		panics()
+	}))
+	defer func() {
+		if v := recover(); v != "boom" {
+			t.Errorf("recovered %v, want %q", v, "boom")
+		}
+		if !strings.HasPrefix(buf.String(), "[WRN] panic recovered") {
+			t.Errorf("got %q, want panic logged at warn", buf.String())
+		}
+	}()
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+}
@@ -0,0 +1,248 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// DefaultMask is the replacement text used by a Redactor unless WithMask is given.
+const DefaultMask = "***"
+
+// Redactor masks sensitive attribute values and message text. Attributes whose
+// key matches a key pattern are masked entirely, and substrings of string values,
+// error messages, the text of other values and messages matching a value pattern
+// are replaced. Group members are scanned recursively.
+type Redactor struct {
+	keys   []string
+	values []*regexp.Regexp
+	mask   string
+}
+
+// NewRedactor creates a new Redactor with the given options.
+func NewRedactor(opts ...RedactorOption) *Redactor {
+	r := &Redactor{mask: DefaultMask}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// RedactorOption defines a function type for configuring a Redactor.
+type RedactorOption func(*Redactor)
+
+// WithKeyPatterns returns a RedactorOption that adds case-insensitive key patterns.
+// Patterns use path.Match syntax, e.g. "password" or "*_secret".
+func WithKeyPatterns(patterns ...string) RedactorOption {
+	return func(r *Redactor) {
+		for _, p := range patterns {
+			r.keys = append(r.keys, strings.ToLower(p))
+		}
+	}
+}
+
+// WithValueRegexps returns a RedactorOption that adds patterns matched against
+// string values and message text.
+func WithValueRegexps(res ...*regexp.Regexp) RedactorOption {
+	return func(r *Redactor) {
+		for _, re := range res {
+			if re != nil {
+				r.values = append(r.values, re)
+			}
+		}
+	}
+}
+
+// WithMask returns a RedactorOption that sets the replacement text.
+func WithMask(mask string) RedactorOption {
+	return func(r *Redactor) {
+		r.mask = mask
+	}
+}
+
+// RedactAttr returns the attribute with sensitive values masked.
+func (r *Redactor) RedactAttr(a slog.Attr) slog.Attr {
+	return r.redact(a, false)
+}
+
+// redact returns the attribute with sensitive values masked. If keepComplex is
+// true, maps, slices, arrays and structs are returned as is, for the handler to
+// redact once they are flattened or rendered by its AnyFormat.
+func (r *Redactor) redact(a slog.Attr, keepComplex bool) slog.Attr {
+	if r == nil {
+		return a
+	}
+	if r.matchKey(a.Key) {
+		return slog.String(a.Key, r.mask)
+	}
+	switch a.Value.Kind() {
+	case slog.KindGroup:
+		return slog.Attr{Key: a.Key, Value: slog.GroupValue(r.redactGroup(a.Value.Group(), keepComplex)...)}
+	case slog.KindString:
+		return slog.String(a.Key, r.RedactString(a.Value.String()))
+	case slog.KindLogValuer:
+		return r.redact(slog.Attr{Key: a.Key, Value: a.Value.Resolve()}, keepComplex)
+	case slog.KindAny:
+		if len(r.values) == 0 || keepComplex && isComplex(a.Value) {
+			return a
+		}
+		// Errors stay errors so that they are still styled as such, but their
+		// wrapped errors are dropped along with the unmasked text.
+		if err, ok := a.Value.Any().(error); ok {
+			if s := err.Error(); r.RedactString(s) != s {
+				return slog.Any(a.Key, errors.New(r.RedactString(s)))
+			}
+			return a
+		}
+		if s := a.Value.String(); r.RedactString(s) != s {
+			return slog.String(a.Key, r.RedactString(s))
+		}
+	}
+	return a
+}
+
+// redactGroup returns the group members with sensitive values masked.
+func (r *Redactor) redactGroup(attrs []slog.Attr, keepComplex bool) []slog.Attr {
+	redacted := make([]slog.Attr, len(attrs))
+	for i, attr := range attrs {
+		redacted[i] = r.redact(attr, keepComplex)
+	}
+	return redacted
+}
+
+// RedactString returns s with substrings matching the value patterns masked.
+func (r *Redactor) RedactString(s string) string {
+	if r == nil {
+		return s
+	}
+	for _, re := range r.values {
+		s = re.ReplaceAllLiteralString(s, r.mask)
+	}
+	return s
+}
+
+// ReplaceAttr masks attributes for use as slog.HandlerOptions.ReplaceAttr,
+// so the same rules can be applied to handlers outside this package.
+func (r *Redactor) ReplaceAttr(_ []string, a slog.Attr) slog.Attr {
+	if a.Key == slog.MessageKey {
+		return slog.String(a.Key, r.RedactString(a.Value.String()))
+	}
+	return r.RedactAttr(a)
+}
+
+// matchKey reports whether key matches any key pattern.
+func (r *Redactor) matchKey(key string) bool {
+	if len(r.keys) == 0 {
+		return false
+	}
+	key = strings.ToLower(key)
+	for _, p := range r.keys {
+		if ok, _ := path.Match(p, key); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// redactJSON returns the JSON document b with the values of object members whose
+// key matches a key pattern replaced by the mask, and the value patterns applied
+// to the other strings. The order of the members is kept.
+func (r *Redactor) redactJSON(b []byte) ([]byte, error) {
+	if r == nil || len(r.keys) == 0 && len(r.values) == 0 {
+		return b, nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	type container struct {
+		object bool
+		n      int
+	}
+	var stack []container
+	out := make([]byte, 0, len(b))
+	masked := false
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			return out, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if d, ok := tok.(json.Delim); ok && (d == '}' || d == ']') {
+			stack = stack[:len(stack)-1]
+			out = append(out, byte(d))
+			continue
+		}
+		isKey := false
+		if n := len(stack); n > 0 {
+			c := &stack[n-1]
+			isKey = c.object && c.n%2 == 0
+			switch {
+			case c.n == 0:
+			case c.object && !isKey:
+				out = append(out, ':')
+			default:
+				out = append(out, ',')
+			}
+			c.n++
+		}
+		if masked {
+			masked = false
+			out = appendJSONString(out, r.mask)
+			if _, ok := tok.(json.Delim); ok {
+				if err := skipJSON(dec); err != nil {
+					return nil, err
+				}
+			}
+			continue
+		}
+		switch t := tok.(type) {
+		case json.Delim:
+			stack = append(stack, container{object: t == '{'})
+			out = append(out, byte(t))
+		case string:
+			if isKey {
+				masked = r.matchKey(t)
+				out = appendJSONString(out, t)
+			} else {
+				out = appendJSONString(out, r.RedactString(t))
+			}
+		case json.Number:
+			out = append(out, t...)
+		case bool:
+			out = strconv.AppendBool(out, t)
+		case nil:
+			out = append(out, "null"...)
+		}
+	}
+}
+
+// skipJSON reads the tokens of dec up to the end of the object or array just opened.
+func skipJSON(dec *json.Decoder) error {
+	for depth := 1; depth > 0; {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		if d, ok := tok.(json.Delim); ok {
+			if d == '{' || d == '[' {
+				depth++
+			} else {
+				depth--
+			}
+		}
+	}
+	return nil
+}
+
+// appendJSONString appends s encoded as a JSON string to b.
+func appendJSONString(b []byte, s string) []byte {
+	q, _ := json.Marshal(s)
+	return append(b, q...)
+}
@@ -0,0 +1,261 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+type secretValuer string
+
+func (s secretValuer) LogValue() slog.Value {
+	return slog.StringValue(string(s))
+}
+
+func TestNewRedactor(t *testing.T) {
+	tests := []struct {
+		name  string
+		opts  []RedactorOption
+		check func(*testing.T, *Redactor)
+	}{
+		{
+			name: "default",
+			check: func(t *testing.T, r *Redactor) {
+				if r.mask != DefaultMask {
+					t.Errorf("mask = %q, want %q", r.mask, DefaultMask)
+				}
+				if len(r.keys) != 0 || len(r.values) != 0 {
+					t.Error("want no patterns")
+				}
+			},
+		},
+		{
+			name: "all options",
+			opts: []RedactorOption{
+				WithKeyPatterns("Password", "*_secret"),
+				WithValueRegexps(regexp.MustCompile(`\d{4}`), nil),
+				WithMask("[REDACTED]"),
+			},
+			check: func(t *testing.T, r *Redactor) {
+				if !reflect.DeepEqual(r.keys, []string{"password", "*_secret"}) {
+					t.Errorf("keys = %v", r.keys)
+				}
+				if len(r.values) != 1 {
+					t.Errorf("len(values) = %v, want 1", len(r.values))
+				}
+				if r.mask != "[REDACTED]" {
+					t.Errorf("mask = %q, want %q", r.mask, "[REDACTED]")
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.check(t, NewRedactor(tt.opts...))
+		})
+	}
+}
+
+func TestRedactor_RedactAttr(t *testing.T) {
+	r := NewRedactor(
+		WithKeyPatterns("password", "token", "*_secret"),
+		WithValueRegexps(regexp.MustCompile(`Bearer \S+`)),
+	)
+	tests := []struct {
+		name     string
+		redactor *Redactor
+		attr     slog.Attr
+		want     slog.Attr
+	}{
+		{
+			name:     "nil redactor",
+			redactor: nil,
+			attr:     slog.String("password", "p@ss"),
+			want:     slog.String("password", "p@ss"),
+		},
+		{
+			name:     "key match",
+			redactor: r,
+			attr:     slog.String("password", "p@ss"),
+			want:     slog.String("password", "***"),
+		},
+		{
+			name:     "key match case insensitive",
+			redactor: r,
+			attr:     slog.Int("TOKEN", 1234),
+			want:     slog.String("TOKEN", "***"),
+		},
+		{
+			name:     "key glob",
+			redactor: r,
+			attr:     slog.String("client_secret", "abc"),
+			want:     slog.String("client_secret", "***"),
+		},
+		{
+			name:     "no match",
+			redactor: r,
+			attr:     slog.Int("count", 1),
+			want:     slog.Int("count", 1),
+		},
+		{
+			name:     "value regexp",
+			redactor: r,
+			attr:     slog.String("header", "Authorization: Bearer abc.def"),
+			want:     slog.String("header", "Authorization: ***"),
+		},
+		{
+			name:     "log valuer",
+			redactor: r,
+			attr:     slog.Any("header", secretValuer("Bearer xyz")),
+			want:     slog.String("header", "***"),
+		},
+		{
+			name:     "nested group",
+			redactor: r,
+			attr: slog.Group("auth",
+				slog.String("user", "alice"),
+				slog.Group("db", slog.String("password", "p@ss")),
+			),
+			want: slog.Group("auth",
+				slog.String("user", "alice"),
+				slog.Group("db", slog.String("password", "***")),
+			),
+		},
+		{
+			name:     "group key match",
+			redactor: r,
+			attr:     slog.Group("token", slog.String("value", "abc")),
+			want:     slog.String("token", "***"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.redactor.RedactAttr(tt.attr); !got.Equal(tt.want) {
+				t.Errorf("RedactAttr() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRedactor_RedactAttr_any(t *testing.T) {
+	r := NewRedactor(WithValueRegexps(regexp.MustCompile(`sk-\w+`)))
+	wrapped := fmt.Errorf("token sk-abc: %w", errors.New("cause"))
+	tests := []struct {
+		name    string
+		attr    slog.Attr
+		want    string
+		isError bool
+	}{
+		{name: "error", attr: slog.Any("e", errors.New("token sk-abc")), want: "token ***", isError: true},
+		{name: "wrapped error", attr: slog.Any("e", wrapped), want: "token ***: cause", isError: true},
+		{name: "error without match", attr: slog.Any("e", errors.New("boom")), want: "boom", isError: true},
+		{name: "stringer", attr: slog.Any("v", []string{"sk-abc", "x"}), want: "[*** x]"},
+		{name: "struct", attr: slog.Any("v", struct{ Key string }{"sk-abc"}), want: "{***}"},
+		{name: "int", attr: slog.Int("n", 1), want: "1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := r.RedactAttr(tt.attr)
+			if s := got.Value.String(); s != tt.want {
+				t.Errorf("RedactAttr() = %q, want %q", s, tt.want)
+			}
+			err, isError := got.Value.Any().(error)
+			if isError != tt.isError {
+				t.Errorf("RedactAttr() is error = %v, want %v", isError, tt.isError)
+			}
+			if isError && errors.Unwrap(err) != nil && strings.Contains(tt.want, "***") {
+				t.Error("RedactAttr() kept the wrapped errors of a masked error")
+			}
+		})
+	}
+}
+
+func TestRedactor_RedactString(t *testing.T) {
+	r := NewRedactor(
+		WithValueRegexps(regexp.MustCompile(`\d{4}-\d{4}`), regexp.MustCompile(`key=\w+`)),
+		WithMask("$1"),
+	)
+	tests := []struct {
+		name     string
+		redactor *Redactor
+		s        string
+		want     string
+	}{
+		{name: "nil redactor", redactor: nil, s: "1234-5678", want: "1234-5678"},
+		{name: "no match", redactor: r, s: "hello", want: "hello"},
+		{name: "literal mask", redactor: r, s: "card 1234-5678 key=abc", want: "card $1 $1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.redactor.RedactString(tt.s); got != tt.want {
+				t.Errorf("RedactString() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRedactor_ReplaceAttr(t *testing.T) {
+	buf := &bytes.Buffer{}
+	r := NewRedactor(WithKeyPatterns("password"), WithValueRegexps(regexp.MustCompile(`secret`)))
+	l := slog.New(slog.NewTextHandler(buf, &slog.HandlerOptions{ReplaceAttr: r.ReplaceAttr}))
+	l.Info("the secret is out", "password", "p@ss")
+	got := buf.String()
+	if !strings.Contains(got, `msg="the *** is out" password=***`) {
+		t.Errorf("got %q", got)
+	}
+}
+
+func TestCLIHandler_Handle_redactor(t *testing.T) {
+	r := NewRedactor(WithKeyPatterns("password"), WithValueRegexps(regexp.MustCompile(`tok_\w+`)))
+	tests := []struct {
+		name    string
+		handler func(*bytes.Buffer) slog.Handler
+		want    string
+	}{
+		{
+			name: "cli",
+			handler: func(buf *bytes.Buffer) slog.Handler {
+				return NewCLIHandler(buf, WithStyle(Style0()), WithRedactor(r)).
+					WithAttrs([]slog.Attr{slog.String("password", "p1")})
+			},
+			want: "[INF] using *** password=*** req=*** db.password=***\n",
+		},
+		{
+			name: "error value",
+			handler: func(buf *bytes.Buffer) slog.Handler {
+				return NewCLIHandler(buf, WithStyle(Style0()), WithRedactor(r)).
+					WithAttrs([]slog.Attr{slog.Any("e", errors.New("token tok_abc"))})
+			},
+			want: "[INF] using *** e=\"token ***\" req=*** db.password=***\n",
+		},
+		{
+			name: "logfmt",
+			handler: func(buf *bytes.Buffer) slog.Handler {
+				return NewLogfmtHandler(buf, WithRedactor(r)).
+					WithAttrs([]slog.Attr{slog.String("password", "p1")})
+			},
+			want: "level=INFO msg=\"using ***\" password=*** req=*** db.password=***\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			ctx := NewContext(context.Background(), slog.String("req", "tok_abc"))
+			rec := slog.NewRecord(time.Time{}, slog.LevelInfo, "using tok_123", 0)
+			rec.AddAttrs(slog.Group("db", slog.String("password", "p2")))
+			if err := tt.handler(buf).Handle(ctx, rec); err != nil {
+				t.Fatal(err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package log
+
+import (
+	"slices"
+	"strings"
+	"sync"
+)
+
+// styles holds the registered styles by lowercase name.
+var styles = struct {
+	mu sync.RWMutex
+	m  map[string]func() *Style
+}{
+	m: map[string]func() *Style{
+		"plain":    Style0,
+		"basic":    Style1,
+		"vivid":    Style2,
+		"bg":       Style3,
+		"vivid-bg": Style4,
+		"icons":    Style5,
+		"style0":   Style0,
+		"style1":   Style1,
+		"style2":   Style2,
+		"style3":   Style3,
+		"style4":   Style4,
+		"style5":   Style5,
+	},
+}
+
+// RegisterStyle registers the style under the given name so it can be looked up
+// with GetStyle. Names are case-insensitive, and registering an existing name
+// replaces it. A nil style removes the name.
+func RegisterStyle(name string, s *Style) {
+	name = strings.ToLower(name)
+	styles.mu.Lock()
+	defer styles.mu.Unlock()
+	if s == nil {
+		delete(styles.m, name)
+		return
+	}
+	s = s.Clone()
+	styles.m[name] = s.Clone
+}
+
+// GetStyle returns a copy of the style registered under the given name.
+// The built-in styles are registered as "plain", "basic", "vivid", "bg",
+// "vivid-bg" and "icons", and as "style0" through "style5".
+func GetStyle(name string) (*Style, bool) {
+	styles.mu.RLock()
+	fn, ok := styles.m[strings.ToLower(name)]
+	styles.mu.RUnlock()
+	if !ok {
+		return nil, false
+	}
+	return fn(), true
+}
+
+// StyleNames returns the sorted names of all registered styles.
+func StyleNames() []string {
+	styles.mu.RLock()
+	defer styles.mu.RUnlock()
+	names := make([]string, 0, len(styles.m))
+	for name := range styles.m {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
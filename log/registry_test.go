@@ -0,0 +1,79 @@
+package log
+
+import (
+	"log/slog"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+func TestGetStyle(t *testing.T) {
+	tests := []struct {
+		name   string
+		want   *Style
+		wantOK bool
+	}{
+		{name: "plain", want: Style0(), wantOK: true},
+		{name: "basic", want: Style1(), wantOK: true},
+		{name: "vivid", want: Style2(), wantOK: true},
+		{name: "bg", want: Style3(), wantOK: true},
+		{name: "vivid-bg", want: Style4(), wantOK: true},
+		{name: "style0", want: Style0(), wantOK: true},
+		{name: "Style4", want: Style4(), wantOK: true},
+		{name: "icons", want: Style5(), wantOK: true},
+		{name: "unknown", want: nil, wantOK: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := GetStyle(tt.name)
+			if ok != tt.wantOK {
+				t.Fatalf("GetStyle() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetStyle() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRegisterStyle(t *testing.T) {
+	s := NewStyle(WithLabelStyle(LabelStyle{Width: 10}))
+	RegisterStyle("MyTheme", s)
+	t.Cleanup(func() { RegisterStyle("mytheme", nil) })
+
+	s.Label.Width = 20
+	got, ok := GetStyle("mytheme")
+	if !ok {
+		t.Fatal("style not registered")
+	}
+	if got.Label.Width != 10 {
+		t.Errorf("Label.Width = %d, want 10", got.Label.Width)
+	}
+
+	got.Level[slog.LevelInfo] = LevelStyle{Text: "changed"}
+	again, _ := GetStyle("mytheme")
+	if again.Level[slog.LevelInfo].Text != "[INF]" {
+		t.Errorf("registered style was mutated: %q", again.Level[slog.LevelInfo].Text)
+	}
+
+	if !slices.Contains(StyleNames(), "mytheme") {
+		t.Errorf("StyleNames() = %v, want mytheme", StyleNames())
+	}
+
+	RegisterStyle("mytheme", nil)
+	if _, ok := GetStyle("mytheme"); ok {
+		t.Error("style not removed")
+	}
+}
+
+func TestStyleNames(t *testing.T) {
+	got := StyleNames()
+	if !slices.IsSorted(got) {
+		t.Errorf("StyleNames() not sorted: %v", got)
+	}
+	for _, name := range []string{"plain", "basic", "vivid", "bg", "vivid-bg", "icons", "style0", "style5"} {
+		if !slices.Contains(got, name) {
+			t.Errorf("StyleNames() missing %q", name)
+		}
+	}
+}
@@ -0,0 +1,348 @@
+package log
+
+import (
+	"compress/gzip"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var _ io.WriteCloser = (*RotatingWriter)(nil)
+
+const (
+	defaultMaxSize     = 100 * 1024 * 1024
+	backupTimeLayout   = "2006-01-02T15-04-05.000"
+	compressSuffix     = ".gz"
+	rotatingFileMode   = 0o600
+	rotatingFolderMode = 0o750
+)
+
+// RotatingWriter is an io.WriteCloser that writes to a file and rotates it
+// when it grows beyond the configured size.
+type RotatingWriter struct {
+	path       string
+	maxSize    int64
+	maxAge     time.Duration
+	maxBackups int
+	compress   bool
+	mu         sync.Mutex
+	file       *os.File
+	size       int64
+	now        func() time.Time
+	cleanMu    sync.Mutex
+	cleanWG    sync.WaitGroup
+	cleanErr   error
+}
+
+// NewRotatingWriter creates a new RotatingWriter for the given path with the given options.
+// The file is opened lazily on the first write.
+func NewRotatingWriter(path string, opts ...RotatingWriterOption) *RotatingWriter {
+	w := &RotatingWriter{
+		path:    path,
+		maxSize: defaultMaxSize,
+		now:     time.Now,
+	}
+	for _, opt := range opts {
+		opt(w)
+	}
+	return w
+}
+
+// RotatingWriterOption defines a function type for configuring a RotatingWriter.
+type RotatingWriterOption func(*RotatingWriter)
+
+// WithMaxSize returns a RotatingWriterOption that sets the maximum size in bytes
+// of the file before it is rotated.
+func WithMaxSize(size int64) RotatingWriterOption {
+	return func(w *RotatingWriter) {
+		if size > 0 {
+			w.maxSize = size
+		}
+	}
+}
+
+// WithMaxAge returns a RotatingWriterOption that sets the maximum age of backups to retain.
+// Zero retains backups regardless of age.
+func WithMaxAge(age time.Duration) RotatingWriterOption {
+	return func(w *RotatingWriter) {
+		if age >= 0 {
+			w.maxAge = age
+		}
+	}
+}
+
+// WithMaxBackups returns a RotatingWriterOption that sets the maximum number of backups to retain.
+// Zero retains all backups.
+func WithMaxBackups(n int) RotatingWriterOption {
+	return func(w *RotatingWriter) {
+		if n >= 0 {
+			w.maxBackups = n
+		}
+	}
+}
+
+// WithCompress returns a RotatingWriterOption that enables gzip compression of backups.
+func WithCompress(compress bool) RotatingWriterOption {
+	return func(w *RotatingWriter) {
+		w.compress = compress
+	}
+}
+
+// Write writes p to the file, rotating it first if the write would exceed the maximum size.
+func (w *RotatingWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.file == nil {
+		if err := w.open(); err != nil {
+			return 0, err
+		}
+	}
+	if w.size > 0 && w.size+int64(len(p)) > w.maxSize {
+		if err := w.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := w.file.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+// Rotate closes the current file, moves it aside as a backup, and opens a new file.
+func (w *RotatingWriter) Rotate() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.rotate()
+}
+
+// Close closes the current file and waits for the backups being compressed or
+// removed in the background. It returns the first error of that cleanup.
+func (w *RotatingWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	err := w.close()
+	w.cleanWG.Wait()
+	w.cleanMu.Lock()
+	err = errors.Join(err, w.cleanErr)
+	w.cleanErr = nil
+	w.cleanMu.Unlock()
+	return err
+}
+
+// open opens the current file for appending, creating it if necessary.
+func (w *RotatingWriter) open() error {
+	if err := os.MkdirAll(filepath.Dir(w.path), rotatingFolderMode); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(w.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, rotatingFileMode)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return err
+	}
+	w.file = f
+	w.size = info.Size()
+	return nil
+}
+
+// close closes the current file if it is open.
+func (w *RotatingWriter) close() error {
+	if w.file == nil {
+		return nil
+	}
+	err := w.file.Close()
+	w.file = nil
+	w.size = 0
+	return err
+}
+
+// rotate moves the current file to a timestamped backup and opens a new file.
+func (w *RotatingWriter) rotate() error {
+	if err := w.close(); err != nil {
+		return err
+	}
+	if _, err := os.Stat(w.path); err == nil {
+		if err := os.Rename(w.path, w.backupName(w.now())); err != nil {
+			return err
+		}
+	}
+	if err := w.open(); err != nil {
+		return err
+	}
+	w.startCleanup()
+	return nil
+}
+
+// startCleanup compresses and removes backups in the background, so that writers
+// do not wait for the compression. Cleanups run one at a time, and the first
+// error is kept for Close. It must be called with the mutex held.
+func (w *RotatingWriter) startCleanup() {
+	if !w.compress && w.maxBackups == 0 && w.maxAge == 0 {
+		return
+	}
+	cutoff := w.now().Add(-w.maxAge)
+	w.cleanWG.Add(1)
+	go func() {
+		defer w.cleanWG.Done()
+		w.cleanMu.Lock()
+		defer w.cleanMu.Unlock()
+		if err := w.cleanup(cutoff); err != nil && w.cleanErr == nil {
+			w.cleanErr = err
+		}
+	}()
+}
+
+// backupName returns the backup file name for the given time. A counter is
+// appended to the time if a backup of the same time exists, so that rotations
+// within the same millisecond do not overwrite each other.
+func (w *RotatingWriter) backupName(t time.Time) string {
+	dir := filepath.Dir(w.path)
+	base := filepath.Base(w.path)
+	ext := filepath.Ext(base)
+	name := strings.TrimSuffix(base, ext) + "-" + t.Format(backupTimeLayout)
+	for i := 0; ; i++ {
+		stamp := name
+		if i > 0 {
+			stamp += "-" + strconv.Itoa(i)
+		}
+		path := filepath.Join(dir, stamp+ext)
+		if !fileExists(path) && !fileExists(path+compressSuffix) {
+			return path
+		}
+	}
+}
+
+// fileExists reports whether a file exists at path.
+func fileExists(path string) bool {
+	_, err := os.Lstat(path)
+	return err == nil
+}
+
+// backup holds a backup file path, its rotation time and its counter within
+// that time.
+type backup struct {
+	path string
+	time time.Time
+	seq  int
+}
+
+// parseBackupStamp parses the rotation time and the optional counter of a backup name.
+func parseBackupStamp(s string) (time.Time, int, bool) {
+	if t, err := time.ParseInLocation(backupTimeLayout, s, time.Local); err == nil {
+		return t, 0, true
+	}
+	i := strings.LastIndexByte(s, '-')
+	if i < 0 {
+		return time.Time{}, 0, false
+	}
+	seq, err := strconv.Atoi(s[i+1:])
+	if err != nil || seq <= 0 {
+		return time.Time{}, 0, false
+	}
+	t, err := time.ParseInLocation(backupTimeLayout, s[:i], time.Local)
+	if err != nil {
+		return time.Time{}, 0, false
+	}
+	return t, seq, true
+}
+
+// backups returns the existing backups ordered from newest to oldest.
+func (w *RotatingWriter) backups() ([]backup, error) {
+	dir := filepath.Dir(w.path)
+	base := filepath.Base(w.path)
+	ext := filepath.Ext(base)
+	prefix := strings.TrimSuffix(base, ext) + "-"
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var bs []backup
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		name := e.Name()
+		ts := strings.TrimSuffix(name, compressSuffix)
+		if !strings.HasPrefix(ts, prefix) || !strings.HasSuffix(ts, ext) {
+			continue
+		}
+		ts = strings.TrimSuffix(strings.TrimPrefix(ts, prefix), ext)
+		t, seq, ok := parseBackupStamp(ts)
+		if !ok {
+			continue
+		}
+		bs = append(bs, backup{path: filepath.Join(dir, name), time: t, seq: seq})
+	}
+	sort.Slice(bs, func(i, j int) bool {
+		if !bs[i].time.Equal(bs[j].time) {
+			return bs[i].time.After(bs[j].time)
+		}
+		return bs[i].seq > bs[j].seq
+	})
+	return bs, nil
+}
+
+// cleanup compresses backups and removes those exceeding the retention limits,
+// i.e. beyond the maximum number or rotated before cutoff.
+func (w *RotatingWriter) cleanup(cutoff time.Time) error {
+	bs, err := w.backups()
+	if err != nil {
+		return err
+	}
+	for i, b := range bs {
+		if (w.maxBackups > 0 && i >= w.maxBackups) || (w.maxAge > 0 && b.time.Before(cutoff)) {
+			if err := os.Remove(b.path); err != nil {
+				return err
+			}
+			continue
+		}
+		if w.compress && !strings.HasSuffix(b.path, compressSuffix) {
+			if err := compressFile(b.path); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// compressFile gzips the file at path into path.gz and removes the original.
+func compressFile(path string) error {
+	src, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		return err
+	}
+	err = writeGzip(path+compressSuffix, src)
+	if cerr := src.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Remove(path)
+}
+
+// writeGzip writes the gzip-compressed contents of r to a new file at path.
+func writeGzip(path string, r io.Reader) error {
+	dst, err := os.OpenFile(filepath.Clean(path), os.O_CREATE|os.O_WRONLY|os.O_TRUNC, rotatingFileMode)
+	if err != nil {
+		return err
+	}
+	zw := gzip.NewWriter(dst)
+	_, err = io.Copy(zw, r)
+	if cerr := zw.Close(); err == nil {
+		err = cerr
+	}
+	if cerr := dst.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
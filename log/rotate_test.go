@@ -0,0 +1,345 @@
+package log
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewRotatingWriter(t *testing.T) {
+	type args struct {
+		opts []RotatingWriterOption
+	}
+	tests := []struct {
+		name  string
+		args  args
+		check func(*testing.T, *RotatingWriter)
+	}{
+		{
+			name: "default",
+			args: args{opts: nil},
+			check: func(t *testing.T, w *RotatingWriter) {
+				if w.maxSize != defaultMaxSize {
+					t.Errorf("maxSize = %v, want %v", w.maxSize, defaultMaxSize)
+				}
+				if w.maxAge != 0 {
+					t.Errorf("maxAge = %v, want 0", w.maxAge)
+				}
+				if w.maxBackups != 0 {
+					t.Errorf("maxBackups = %v, want 0", w.maxBackups)
+				}
+				if w.compress {
+					t.Error("compress = true, want false")
+				}
+			},
+		},
+		{
+			name: "all options",
+			args: args{opts: []RotatingWriterOption{
+				WithMaxSize(1024),
+				WithMaxAge(time.Hour),
+				WithMaxBackups(3),
+				WithCompress(true),
+			}},
+			check: func(t *testing.T, w *RotatingWriter) {
+				if w.maxSize != 1024 {
+					t.Errorf("maxSize = %v, want 1024", w.maxSize)
+				}
+				if w.maxAge != time.Hour {
+					t.Errorf("maxAge = %v, want %v", w.maxAge, time.Hour)
+				}
+				if w.maxBackups != 3 {
+					t.Errorf("maxBackups = %v, want 3", w.maxBackups)
+				}
+				if !w.compress {
+					t.Error("compress = false, want true")
+				}
+			},
+		},
+		{
+			name: "invalid values ignored",
+			args: args{opts: []RotatingWriterOption{
+				WithMaxSize(0),
+				WithMaxAge(-time.Hour),
+				WithMaxBackups(-1),
+			}},
+			check: func(t *testing.T, w *RotatingWriter) {
+				if w.maxSize != defaultMaxSize {
+					t.Errorf("maxSize = %v, want %v", w.maxSize, defaultMaxSize)
+				}
+				if w.maxAge != 0 {
+					t.Errorf("maxAge = %v, want 0", w.maxAge)
+				}
+				if w.maxBackups != 0 {
+					t.Errorf("maxBackups = %v, want 0", w.maxBackups)
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NewRotatingWriter(filepath.Join(t.TempDir(), "app.log"), tt.args.opts...)
+			tt.check(t, got)
+		})
+	}
+}
+
+// newTestRotatingWriter returns a RotatingWriter whose clock advances one second per call.
+func newTestRotatingWriter(t *testing.T, opts ...RotatingWriterOption) (*RotatingWriter, string) {
+	t.Helper()
+	dir := t.TempDir()
+	w := NewRotatingWriter(filepath.Join(dir, "app.log"), opts...)
+	now := time.Date(2025, time.April, 1, 0, 0, 0, 0, time.Local)
+	w.now = func() time.Time {
+		now = now.Add(time.Second)
+		return now
+	}
+	t.Cleanup(func() { _ = w.Close() })
+	return w, dir
+}
+
+// listDir returns the sorted file names in dir.
+func listDir(t *testing.T, dir string) []string {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := make([]string, 0, len(entries))
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestRotatingWriter_Write(t *testing.T) {
+	tests := []struct {
+		name   string
+		opts   []RotatingWriterOption
+		writes []string
+		want   []string
+		check  func(*testing.T, string)
+	}{
+		{
+			name:   "no rotation",
+			opts:   []RotatingWriterOption{WithMaxSize(10)},
+			writes: []string{"12345", "67890"},
+			want:   []string{"app.log"},
+			check: func(t *testing.T, dir string) {
+				b, err := os.ReadFile(filepath.Join(dir, "app.log"))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if string(b) != "1234567890" {
+					t.Errorf("got %q, want %q", string(b), "1234567890")
+				}
+			},
+		},
+		{
+			name:   "rotate on size",
+			opts:   []RotatingWriterOption{WithMaxSize(10)},
+			writes: []string{"12345", "67890", "abc"},
+			want:   []string{"app-2025-04-01T00-00-01.000.log", "app.log"},
+			check: func(t *testing.T, dir string) {
+				b, err := os.ReadFile(filepath.Join(dir, "app.log"))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if string(b) != "abc" {
+					t.Errorf("got %q, want %q", string(b), "abc")
+				}
+			},
+		},
+		{
+			name:   "oversized write to empty file",
+			opts:   []RotatingWriterOption{WithMaxSize(3)},
+			writes: []string{"12345"},
+			want:   []string{"app.log"},
+		},
+		{
+			name:   "max backups",
+			opts:   []RotatingWriterOption{WithMaxSize(1), WithMaxBackups(2)},
+			writes: []string{"1", "2", "3", "4"},
+			want: []string{
+				"app-2025-04-01T00-00-03.000.log",
+				"app-2025-04-01T00-00-05.000.log",
+				"app.log",
+			},
+		},
+		{
+			name:   "max age",
+			opts:   []RotatingWriterOption{WithMaxSize(1), WithMaxAge(1500 * time.Millisecond)},
+			writes: []string{"1", "2", "3", "4"},
+			want: []string{
+				"app-2025-04-01T00-00-05.000.log",
+				"app.log",
+			},
+		},
+		{
+			name:   "compress",
+			opts:   []RotatingWriterOption{WithMaxSize(5), WithCompress(true)},
+			writes: []string{"12345", "67890"},
+			want:   []string{"app-2025-04-01T00-00-01.000.log.gz", "app.log"},
+			check: func(t *testing.T, dir string) {
+				f, err := os.Open(filepath.Join(dir, "app-2025-04-01T00-00-01.000.log.gz"))
+				if err != nil {
+					t.Fatal(err)
+				}
+				defer f.Close()
+				zr, err := gzip.NewReader(f)
+				if err != nil {
+					t.Fatal(err)
+				}
+				b, err := io.ReadAll(zr)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if string(b) != "12345" {
+					t.Errorf("got %q, want %q", string(b), "12345")
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, dir := newTestRotatingWriter(t, tt.opts...)
+			for _, s := range tt.writes {
+				n, err := w.Write([]byte(s))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if n != len(s) {
+					t.Errorf("n = %v, want %v", n, len(s))
+				}
+			}
+			if err := w.Close(); err != nil {
+				t.Fatal(err)
+			}
+			if got := listDir(t, dir); strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("files = %v, want %v", got, tt.want)
+			}
+			if tt.check != nil {
+				tt.check(t, dir)
+			}
+		})
+	}
+}
+
+func TestRotatingWriter_Write_append(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	if err := os.WriteFile(path, []byte("existing\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	w := NewRotatingWriter(path)
+	if _, err := w.Write([]byte("new\n")); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != "existing\nnew\n" {
+		t.Errorf("got %q, want %q", string(b), "existing\nnew\n")
+	}
+}
+
+func TestRotatingWriter_Rotate(t *testing.T) {
+	w, dir := newTestRotatingWriter(t)
+	if _, err := w.Write([]byte("before")); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Rotate(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write([]byte("after")); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"app-2025-04-01T00-00-01.000.log", "app.log"}
+	if got := listDir(t, dir); strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("files = %v, want %v", got, want)
+	}
+}
+
+func TestRotatingWriter_Rotate_sameTime(t *testing.T) {
+	w, dir := newTestRotatingWriter(t, WithMaxBackups(2), WithCompress(true))
+	now := time.Date(2025, time.April, 1, 0, 0, 0, 0, time.Local)
+	w.now = func() time.Time { return now }
+	for _, s := range []string{"1", "2", "3", "4"} {
+		if _, err := w.Write([]byte(s)); err != nil {
+			t.Fatal(err)
+		}
+		if err := w.Rotate(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"app-2025-04-01T00-00-00.000-2.log.gz",
+		"app-2025-04-01T00-00-00.000-3.log.gz",
+		"app.log",
+	}
+	if got := listDir(t, dir); strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("files = %v, want %v", got, want)
+	}
+	for i, name := range want[:2] {
+		f, err := os.Open(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		zr, err := gzip.NewReader(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := io.ReadAll(zr)
+		_ = f.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := strconv.Itoa(i + 3); string(b) != want {
+			t.Errorf("%s = %q, want %q", name, b, want)
+		}
+	}
+}
+
+func TestRotatingWriter_Close(t *testing.T) {
+	w, _ := newTestRotatingWriter(t)
+	if err := w.Close(); err != nil {
+		t.Errorf("Close() on unopened writer error = %v", err)
+	}
+	if _, err := w.Write([]byte("msg")); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Errorf("Close() error = %v", err)
+	}
+	if w.file != nil {
+		t.Error("file should be nil after Close")
+	}
+}
+
+func TestRotatingWriter_CLIHandler(t *testing.T) {
+	w, dir := newTestRotatingWriter(t, WithMaxSize(16))
+	l := NewLogger(NewCLIHandler(w, WithStyle(Style0())))
+	l.Info("first")
+	l.Info("second")
+	b, err := os.ReadFile(filepath.Join(dir, "app.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != "[INF] second\n" {
+		t.Errorf("got %q, want %q", string(b), "[INF] second\n")
+	}
+}
@@ -0,0 +1,245 @@
+package log
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	_ slog.Handler = (*SamplingHandler)(nil)
+	_ Flusher      = (*SamplingHandler)(nil)
+)
+
+// SuppressedKey is the attr key holding the number of records dropped by a SamplingHandler.
+const SuppressedKey = "suppressed"
+
+// SamplingHandler is a slog.Handler that limits repeated records. Records are
+// grouped by level and message; within each interval only the first burst
+// records are passed to the inner handler and the rest are dropped. Once the
+// interval has elapsed, a summary record with the number of dropped records is
+// emitted with the next record, or by Flush.
+type SamplingHandler struct {
+	inner     slog.Handler
+	burst     int
+	interval  time.Duration
+	hasExempt bool
+	exempt    slog.Level
+	state     *samplingState
+}
+
+// samplingState is the state shared by a SamplingHandler and its derived handlers.
+type samplingState struct {
+	mu        sync.Mutex
+	entries   map[samplingKey]*samplingEntry
+	nextSweep time.Time
+	dropped   atomic.Uint64
+	now       func() time.Time
+}
+
+// samplingKey identifies records considered repeated.
+type samplingKey struct {
+	level slog.Level
+	msg   string
+}
+
+// samplingEntry counts the records of a key within the current interval.
+type samplingEntry struct {
+	start   time.Time
+	count   int
+	dropped int
+	handler slog.Handler
+}
+
+// sample is a pending summary of dropped records.
+type sample struct {
+	key     samplingKey
+	dropped int
+	handler slog.Handler
+}
+
+// NewSamplingHandler creates a new SamplingHandler wrapping inner with the given options.
+func NewSamplingHandler(inner slog.Handler, opts ...SamplingHandlerOption) slog.Handler {
+	if inner == nil {
+		inner = NewCLIHandler(nil)
+	}
+	h := &SamplingHandler{
+		inner:    inner,
+		burst:    1,
+		interval: time.Second,
+		state: &samplingState{
+			entries: make(map[samplingKey]*samplingEntry),
+			now:     time.Now,
+		},
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
+}
+
+// SamplingHandlerOption defines a function type for configuring a SamplingHandler.
+type SamplingHandlerOption func(*SamplingHandler)
+
+// WithBurst returns a SamplingHandlerOption that sets the number of repeated
+// records passed per interval.
+func WithBurst(n int) SamplingHandlerOption {
+	return func(h *SamplingHandler) {
+		if n > 0 {
+			h.burst = n
+		}
+	}
+}
+
+// WithPerInterval returns a SamplingHandlerOption that sets the sampling interval.
+func WithPerInterval(d time.Duration) SamplingHandlerOption {
+	return func(h *SamplingHandler) {
+		if d > 0 {
+			h.interval = d
+		}
+	}
+}
+
+// WithLevelExempt returns a SamplingHandlerOption that passes records at or
+// above the given level without sampling.
+func WithLevelExempt(level slog.Level) SamplingHandlerOption {
+	return func(h *SamplingHandler) {
+		h.hasExempt = true
+		h.exempt = level
+	}
+}
+
+// Enabled reports whether the inner handler is enabled for the given level.
+func (h *SamplingHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.inner.Enabled(ctx, level)
+}
+
+// Handle passes the record to the inner handler unless it exceeds the burst,
+// emitting summaries of records dropped in elapsed intervals first.
+func (h *SamplingHandler) Handle(ctx context.Context, r slog.Record) error {
+	if h.hasExempt && r.Level >= h.exempt {
+		return h.inner.Handle(ctx, r)
+	}
+	now := h.state.now()
+	samples, pass := h.observe(samplingKey{level: r.Level, msg: r.Message}, now)
+	err := emitSamples(ctx, samples, now)
+	if pass {
+		err = errors.Join(err, h.inner.Handle(ctx, r))
+	}
+	return err
+}
+
+// Flush emits the summaries of the records dropped so far without waiting for
+// their intervals to elapse, and flushes the inner handler if it implements
+// Flusher. Calling it on shutdown, directly or through Close, reports the records
+// dropped in a burst that no later record followed. Records seen since the start
+// of an interval still count towards its burst.
+func (h *SamplingHandler) Flush() error {
+	now := h.state.now()
+	err := emitSamples(context.Background(), h.state.drain(), now)
+	if f, ok := h.inner.(Flusher); ok {
+		err = errors.Join(err, f.Flush())
+	}
+	return err
+}
+
+// WithAttrs returns a new SamplingHandler whose inner handler has the given attributes.
+// The sampling state is shared with the receiver.
+func (h *SamplingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	h2 := *h
+	h2.inner = h.inner.WithAttrs(attrs)
+	return &h2
+}
+
+// WithGroup returns a new SamplingHandler whose inner handler has the given group.
+// The sampling state is shared with the receiver.
+func (h *SamplingHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	h2 := *h
+	h2.inner = h.inner.WithGroup(name)
+	return &h2
+}
+
+// Unwrap returns the inner handler.
+func (h *SamplingHandler) Unwrap() slog.Handler {
+	return h.inner
+}
+
+// Dropped returns the total number of records dropped by the handler and its derived handlers.
+func (h *SamplingHandler) Dropped() uint64 {
+	return h.state.dropped.Load()
+}
+
+// emitSamples writes a summary record for each sample to the handler that dropped its records.
+func emitSamples(ctx context.Context, samples []sample, now time.Time) error {
+	var errs []error
+	for _, s := range samples {
+		sr := slog.NewRecord(now, s.key.level, s.key.msg, 0)
+		sr.AddAttrs(slog.Int(SuppressedKey, s.dropped))
+		if err := s.handler.Handle(ctx, sr); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// drain returns the summaries of every key with dropped records and resets
+// their counts of dropped records.
+func (st *samplingState) drain() []sample {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	var samples []sample
+	for k, e := range st.entries {
+		if e.dropped > 0 {
+			samples = append(samples, sample{key: k, dropped: e.dropped, handler: e.handler})
+			e.dropped = 0
+		}
+	}
+	return samples
+}
+
+// observe counts the record and reports whether it should pass, along with
+// summaries of keys whose interval has elapsed.
+func (h *SamplingHandler) observe(key samplingKey, now time.Time) ([]sample, bool) {
+	st := h.state
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	var samples []sample
+	if !now.Before(st.nextSweep) {
+		for k, e := range st.entries {
+			if now.Sub(e.start) < h.interval {
+				continue
+			}
+			if e.dropped > 0 {
+				samples = append(samples, sample{key: k, dropped: e.dropped, handler: e.handler})
+			}
+			delete(st.entries, k)
+		}
+		st.nextSweep = now.Add(h.interval)
+	}
+	e, ok := st.entries[key]
+	if !ok || now.Sub(e.start) >= h.interval {
+		if ok && e.dropped > 0 {
+			samples = append(samples, sample{key: key, dropped: e.dropped, handler: e.handler})
+		}
+		e = &samplingEntry{start: now}
+		st.entries[key] = e
+	}
+	e.count++
+	if e.count <= h.burst {
+		return samples, true
+	}
+	e.dropped++
+	e.handler = h.inner
+	st.dropped.Add(1)
+	return samples, false
+}
@@ -0,0 +1,336 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestNewSamplingHandler(t *testing.T) {
+	type args struct {
+		inner slog.Handler
+		opts  []SamplingHandlerOption
+	}
+	tests := []struct {
+		name  string
+		args  args
+		check func(*testing.T, *SamplingHandler)
+	}{
+		{
+			name: "default",
+			args: args{inner: NewCLIHandler(io.Discard)},
+			check: func(t *testing.T, h *SamplingHandler) {
+				if h.burst != 1 {
+					t.Errorf("burst = %v, want 1", h.burst)
+				}
+				if h.interval != time.Second {
+					t.Errorf("interval = %v, want %v", h.interval, time.Second)
+				}
+				if h.hasExempt {
+					t.Error("hasExempt = true, want false")
+				}
+			},
+		},
+		{
+			name: "nil inner",
+			args: args{inner: nil},
+			check: func(t *testing.T, h *SamplingHandler) {
+				if h.inner == nil {
+					t.Error("inner is nil")
+				}
+			},
+		},
+		{
+			name: "all options",
+			args: args{
+				inner: NewCLIHandler(io.Discard),
+				opts: []SamplingHandlerOption{
+					WithBurst(5),
+					WithPerInterval(time.Minute),
+					WithLevelExempt(slog.LevelError),
+				},
+			},
+			check: func(t *testing.T, h *SamplingHandler) {
+				if h.burst != 5 {
+					t.Errorf("burst = %v, want 5", h.burst)
+				}
+				if h.interval != time.Minute {
+					t.Errorf("interval = %v, want %v", h.interval, time.Minute)
+				}
+				if !h.hasExempt || h.exempt != slog.LevelError {
+					t.Errorf("exempt = %v, want %v", h.exempt, slog.LevelError)
+				}
+			},
+		},
+		{
+			name: "invalid values ignored",
+			args: args{
+				inner: NewCLIHandler(io.Discard),
+				opts:  []SamplingHandlerOption{WithBurst(0), WithPerInterval(-time.Second)},
+			},
+			check: func(t *testing.T, h *SamplingHandler) {
+				if h.burst != 1 {
+					t.Errorf("burst = %v, want 1", h.burst)
+				}
+				if h.interval != time.Second {
+					t.Errorf("interval = %v, want %v", h.interval, time.Second)
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NewSamplingHandler(tt.args.inner, tt.args.opts...).(*SamplingHandler)
+			tt.check(t, got)
+		})
+	}
+}
+
+// fakeClock is a manually advanced time source for tests.
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func newTestSamplingHandler(buf *bytes.Buffer, opts ...SamplingHandlerOption) (*SamplingHandler, *fakeClock) {
+	clock := &fakeClock{now: time.Date(2025, time.April, 1, 0, 0, 0, 0, time.UTC)}
+	opts = append([]SamplingHandlerOption{WithSamplingClock(clock)}, opts...)
+	h := NewSamplingHandler(NewCLIHandler(buf, WithStyle(Style0()), WithLevel(slog.LevelDebug)), opts...).(*SamplingHandler)
+	return h, clock
+}
+
+// sortedLines returns the lines of s in sorted order.
+func sortedLines(s string) []string {
+	lines := strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+	sort.Strings(lines)
+	return lines
+}
+
+func TestSamplingHandler_Handle(t *testing.T) {
+	type step struct {
+		advance time.Duration
+		level   slog.Level
+		msg     string
+	}
+	tests := []struct {
+		name        string
+		opts        []SamplingHandlerOption
+		steps       []step
+		want        []string
+		wantDropped uint64
+	}{
+		{
+			name: "burst passes",
+			opts: []SamplingHandlerOption{WithBurst(2)},
+			steps: []step{
+				{level: slog.LevelInfo, msg: "a"},
+				{level: slog.LevelInfo, msg: "a"},
+			},
+			want: []string{"[INF] a", "[INF] a"},
+		},
+		{
+			name: "drop beyond burst",
+			opts: []SamplingHandlerOption{WithBurst(1)},
+			steps: []step{
+				{level: slog.LevelInfo, msg: "a"},
+				{level: slog.LevelInfo, msg: "a"},
+				{level: slog.LevelInfo, msg: "a"},
+				{level: slog.LevelInfo, msg: "b"},
+			},
+			want:        []string{"[INF] a", "[INF] b"},
+			wantDropped: 2,
+		},
+		{
+			name: "level is part of key",
+			steps: []step{
+				{level: slog.LevelInfo, msg: "a"},
+				{level: slog.LevelWarn, msg: "a"},
+			},
+			want: []string{"[INF] a", "[WRN] a"},
+		},
+		{
+			name: "summary on next interval",
+			opts: []SamplingHandlerOption{WithPerInterval(time.Second)},
+			steps: []step{
+				{level: slog.LevelInfo, msg: "a"},
+				{level: slog.LevelInfo, msg: "a"},
+				{level: slog.LevelInfo, msg: "a"},
+				{advance: time.Second, level: slog.LevelInfo, msg: "a"},
+			},
+			want:        []string{"[INF] a", "[INF] a", "[INF] a suppressed=2"},
+			wantDropped: 2,
+		},
+		{
+			name: "summary on sweep by other key",
+			opts: []SamplingHandlerOption{WithPerInterval(time.Second)},
+			steps: []step{
+				{level: slog.LevelInfo, msg: "a"},
+				{level: slog.LevelInfo, msg: "a"},
+				{advance: 2 * time.Second, level: slog.LevelInfo, msg: "b"},
+			},
+			want:        []string{"[INF] a", "[INF] a suppressed=1", "[INF] b"},
+			wantDropped: 1,
+		},
+		{
+			name: "exempt level",
+			opts: []SamplingHandlerOption{WithLevelExempt(slog.LevelError)},
+			steps: []step{
+				{level: slog.LevelError, msg: "a"},
+				{level: slog.LevelError, msg: "a"},
+				{level: slog.LevelWarn, msg: "a"},
+				{level: slog.LevelWarn, msg: "a"},
+			},
+			want:        []string{"[ERR] a", "[ERR] a", "[WRN] a"},
+			wantDropped: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			h, clock := newTestSamplingHandler(buf, tt.opts...)
+			for _, s := range tt.steps {
+				clock.Advance(s.advance)
+				if err := h.Handle(context.Background(), slog.NewRecord(time.Time{}, s.level, s.msg, 0)); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want := append([]string(nil), tt.want...)
+			sort.Strings(want)
+			if got := sortedLines(buf.String()); strings.Join(got, "\n") != strings.Join(want, "\n") {
+				t.Errorf("got %q, want %q", got, want)
+			}
+			if got := h.Dropped(); got != tt.wantDropped {
+				t.Errorf("Dropped() = %v, want %v", got, tt.wantDropped)
+			}
+		})
+	}
+}
+
+func TestSamplingHandler_Flush(t *testing.T) {
+	buf := &bytes.Buffer{}
+	h, clock := newTestSamplingHandler(buf, WithPerInterval(time.Second))
+	l := NewLogger(h)
+	for range 3 {
+		l.Info("a")
+	}
+	l.With("k", "v").Warn("b")
+	l.With("k", "v").Warn("b")
+	if err := h.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"[INF] a", "[INF] a suppressed=2", "[WRN] b k=v", "[WRN] b k=v suppressed=1"}
+	if got := sortedLines(buf.String()); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	// Nothing is pending after a flush, and the burst still applies within the interval.
+	buf.Reset()
+	l.Info("a")
+	if err := h.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if got := buf.String(); got != "[INF] a suppressed=1\n" {
+		t.Errorf("got %q, want %q", got, "[INF] a suppressed=1\n")
+	}
+
+	// The next interval starts afresh.
+	buf.Reset()
+	clock.Advance(time.Second)
+	l.Info("a")
+	if err := h.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if got := buf.String(); got != "[INF] a\n" {
+		t.Errorf("got %q, want %q", got, "[INF] a\n")
+	}
+
+	// Closing reports a burst followed by silence.
+	buf.Reset()
+	l.Info("a")
+	if err := Close(h); err != nil {
+		t.Fatal(err)
+	}
+	if got := buf.String(); got != "[INF] a suppressed=1\n" {
+		t.Errorf("got %q, want %q", got, "[INF] a suppressed=1\n")
+	}
+}
+
+func TestSamplingHandler_Enabled(t *testing.T) {
+	h := NewSamplingHandler(NewCLIHandler(io.Discard, WithLevel(slog.LevelWarn)))
+	if h.Enabled(context.Background(), slog.LevelInfo) {
+		t.Error("Enabled(info) = true, want false")
+	}
+	if !h.Enabled(context.Background(), slog.LevelWarn) {
+		t.Error("Enabled(warn) = false, want true")
+	}
+}
+
+func TestSamplingHandler_WithAttrs(t *testing.T) {
+	buf := &bytes.Buffer{}
+	h, _ := newTestSamplingHandler(buf)
+	if got := h.WithAttrs(nil); got != h {
+		t.Error("want same handler instance for empty attrs")
+	}
+	h2 := h.WithAttrs([]slog.Attr{slog.String("key", "val")}).(*SamplingHandler)
+	if h2.state != h.state {
+		t.Error("want shared state")
+	}
+	_ = h.Handle(context.Background(), slog.NewRecord(time.Time{}, slog.LevelInfo, "a", 0))
+	_ = h2.Handle(context.Background(), slog.NewRecord(time.Time{}, slog.LevelInfo, "a", 0))
+	if got := buf.String(); got != "[INF] a\n" {
+		t.Errorf("got %q, want %q", got, "[INF] a\n")
+	}
+}
+
+func TestSamplingHandler_WithGroup(t *testing.T) {
+	buf := &bytes.Buffer{}
+	h, _ := newTestSamplingHandler(buf)
+	if got := h.WithGroup(""); got != h {
+		t.Error("want same handler instance for empty name")
+	}
+	h2 := h.WithGroup("g1").(*SamplingHandler)
+	if h2.state != h.state {
+		t.Error("want shared state")
+	}
+	r := slog.NewRecord(time.Time{}, slog.LevelInfo, "a", 0)
+	r.AddAttrs(slog.String("key", "val"))
+	_ = h2.Handle(context.Background(), r)
+	if got := buf.String(); got != "[INF] a g1.key=val\n" {
+		t.Errorf("got %q, want %q", got, "[INF] a g1.key=val\n")
+	}
+}
+
+func TestSamplingHandler_concurrent(t *testing.T) {
+	h := NewSamplingHandler(NewCLIHandler(io.Discard), WithBurst(10), WithPerInterval(time.Hour)).(*SamplingHandler)
+	l := NewLogger(h)
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Go(func() {
+			for range 100 {
+				l.Info("msg")
+			}
+		})
+	}
+	wg.Wait()
+	if got := h.Dropped(); got != 790 {
+		t.Errorf("Dropped() = %v, want 790", got)
+	}
+}
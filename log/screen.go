@@ -0,0 +1,202 @@
+package log
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var (
+	_ io.WriteCloser = (*ScreenWriter)(nil)
+	_ Flusher        = (*ScreenWriter)(nil)
+)
+
+// ScreenWriter is an io.Writer for terminals that show a sticky area, such as
+// a progress bar or a spinner, below the log. Lines written to it are inserted
+// above the sticky area, which is cleared before and redrawn after them, so
+// that logs and progress do not garble each other. A trailing partial line is
+// kept until the next newline or until Flush or Close is called.
+//
+// Progress libraries set the content of the sticky area with SetStickyLines,
+// or call Suspend and Resume around drawing it themselves.
+type ScreenWriter struct {
+	mu        sync.Mutex
+	w         io.Writer
+	term      *os.File
+	force     bool
+	sticky    []string
+	rows      int
+	suspended bool
+	closed    bool
+	buf       []byte
+}
+
+// ScreenOption defines a function type for configuring a ScreenWriter.
+type ScreenOption func(*ScreenWriter)
+
+// WithForceScreen returns a ScreenOption that sets whether the sticky area is
+// drawn even if the writer is not a terminal. By default, it is only drawn to
+// terminals, and other writers receive the log lines alone.
+func WithForceScreen(force bool) ScreenOption {
+	return func(s *ScreenWriter) {
+		s.force = force
+	}
+}
+
+// NewScreenWriter returns a ScreenWriter writing to w, typically os.Stderr.
+func NewScreenWriter(w io.Writer, opts ...ScreenOption) *ScreenWriter {
+	s := &ScreenWriter{
+		w:    w,
+		term: terminal(w),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Write writes the complete lines in p above the sticky area and keeps a
+// trailing partial line.
+func (s *ScreenWriter) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.buf = append(s.buf, p...)
+	i := bytes.LastIndexByte(s.buf, '\n')
+	if i < 0 {
+		return len(p), nil
+	}
+	if err := s.print(s.buf[:i+1]); err != nil {
+		return 0, err
+	}
+	s.buf = append(s.buf[:0], s.buf[i+1:]...)
+	return len(p), nil
+}
+
+// SetStickyLines replaces the content of the sticky area with lines and
+// redraws it. Lines should not contain newlines. Calling it without lines
+// removes the sticky area.
+func (s *ScreenWriter) SetStickyLines(lines ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sticky = slices.Clone(lines)
+	return s.print(nil)
+}
+
+// Suspend clears the sticky area and stops drawing it until Resume is called,
+// so that the terminal can be used by something else. Lines written in the
+// meantime are written as is.
+func (s *ScreenWriter) Suspend() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.suspended = true
+	return s.print(nil)
+}
+
+// Resume redraws the sticky area after Suspend.
+func (s *ScreenWriter) Resume() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.suspended = false
+	return s.print(nil)
+}
+
+// Flush writes the partial line, terminated by a newline, above the sticky
+// area, and flushes the writer if it implements Flusher.
+func (s *ScreenWriter) Flush() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.flush()
+}
+
+// Close flushes the partial line and clears the sticky area for good. It does
+// not close the underlying writer.
+func (s *ScreenWriter) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	return s.flush()
+}
+
+// flush writes the partial line and flushes the writer. It must be called
+// with the mutex held.
+func (s *ScreenWriter) flush() error {
+	var line []byte
+	if len(s.buf) > 0 {
+		line = append(s.buf, '\n')
+		s.buf = nil
+	}
+	if err := s.print(line); err != nil {
+		return err
+	}
+	if f, ok := s.w.(Flusher); ok {
+		return f.Flush()
+	}
+	return nil
+}
+
+// print clears the sticky area, writes p and redraws the sticky area in a
+// single write. It must be called with the mutex held.
+func (s *ScreenWriter) print(p []byte) error {
+	if len(p) == 0 && s.rows == 0 && !s.visible() {
+		return nil
+	}
+	b := bufPool.Get().(*bytes.Buffer)
+	defer func() {
+		b.Reset()
+		bufPool.Put(b)
+	}()
+	s.clear(b)
+	b.Write(p)
+	s.draw(b)
+	if b.Len() == 0 {
+		return nil
+	}
+	_, err := s.w.Write(b.Bytes())
+	return err
+}
+
+// visible reports whether the sticky area is drawn.
+func (s *ScreenWriter) visible() bool {
+	return (s.term != nil || s.force) && !s.suspended && !s.closed && len(s.sticky) > 0
+}
+
+// clear moves the cursor to the first row of the sticky area and erases the
+// screen from there.
+func (s *ScreenWriter) clear(b *bytes.Buffer) {
+	if s.rows == 0 {
+		return
+	}
+	b.WriteByte('\r')
+	if s.rows > 1 {
+		b.WriteString("\x1b[" + strconv.Itoa(s.rows-1) + "A")
+	}
+	b.WriteString("\x1b[J")
+	s.rows = 0
+}
+
+// draw writes the sticky area, leaving the cursor at the end of its last line
+// so that a progress bar can keep redrawing that line with a carriage return.
+func (s *ScreenWriter) draw(b *bytes.Buffer) {
+	if !s.visible() {
+		return
+	}
+	width := termWidth(s.term)
+	b.WriteString(strings.Join(s.sticky, "\n"))
+	for _, line := range s.sticky {
+		s.rows += rowsOf(line, width)
+	}
+}
+
+// rowsOf returns the number of terminal rows a line occupies at the given
+// width, counting one row per line if the width is unknown.
+func rowsOf(line string, width int) int {
+	n := VisibleWidth(line)
+	if width <= 0 || n <= width {
+		return 1
+	}
+	return (n + width - 1) / width
+}
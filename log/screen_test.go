@@ -0,0 +1,128 @@
+package log
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestScreenWriter(t *testing.T) {
+	tests := []struct {
+		name  string
+		force bool
+		run   func(*ScreenWriter)
+		want  string
+	}{
+		{
+			name: "no sticky area",
+			run: func(s *ScreenWriter) {
+				_, _ = s.Write([]byte("a\n"))
+			},
+			want: "a\n",
+		},
+		{
+			name: "not a terminal",
+			run: func(s *ScreenWriter) {
+				_ = s.SetStickyLines("bar")
+				_, _ = s.Write([]byte("a\n"))
+			},
+			want: "a\n",
+		},
+		{
+			name:  "sticky line",
+			force: true,
+			run: func(s *ScreenWriter) {
+				_ = s.SetStickyLines("bar 1")
+				_, _ = s.Write([]byte("a\n"))
+				_ = s.SetStickyLines("bar 2")
+			},
+			want: "bar 1" + "\r\x1b[J" + "a\n" + "bar 1" + "\r\x1b[J" + "bar 2",
+		},
+		{
+			name:  "sticky lines",
+			force: true,
+			run: func(s *ScreenWriter) {
+				_ = s.SetStickyLines("one", "two")
+				_, _ = s.Write([]byte("a\nb\n"))
+				_ = s.SetStickyLines()
+			},
+			want: "one\ntwo" + "\r\x1b[1A\x1b[J" + "a\nb\n" + "one\ntwo" + "\r\x1b[1A\x1b[J",
+		},
+		{
+			name:  "partial line",
+			force: true,
+			run: func(s *ScreenWriter) {
+				_ = s.SetStickyLines("bar")
+				_, _ = s.Write([]byte("a"))
+				_, _ = s.Write([]byte("b\nc"))
+				_ = s.Flush()
+			},
+			want: "bar" + "\r\x1b[J" + "ab\n" + "bar" + "\r\x1b[J" + "c\n" + "bar",
+		},
+		{
+			name:  "suspend",
+			force: true,
+			run: func(s *ScreenWriter) {
+				_ = s.SetStickyLines("bar")
+				_ = s.Suspend()
+				_, _ = s.Write([]byte("a\n"))
+				_ = s.Resume()
+			},
+			want: "bar" + "\r\x1b[J" + "a\n" + "bar",
+		},
+		{
+			name:  "close",
+			force: true,
+			run: func(s *ScreenWriter) {
+				_ = s.SetStickyLines("bar")
+				_, _ = s.Write([]byte("a"))
+				_ = s.Close()
+				_, _ = s.Write([]byte("b\n"))
+			},
+			want: "bar" + "\r\x1b[J" + "a\n" + "b\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			s := NewScreenWriter(buf, WithForceScreen(tt.force))
+			tt.run(s)
+			if got := buf.String(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScreenWriter_handler(t *testing.T) {
+	buf := &bytes.Buffer{}
+	s := NewScreenWriter(buf, WithForceScreen(true))
+	l := NewLogger(NewCLIHandler(s, WithColorMode(ColorNever)))
+	_ = s.SetStickyLines("50%")
+	l.Info("msg")
+	want := "50%" + "\r\x1b[J" + "INF msg\n" + "50%"
+	if got := buf.String(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func Test_rowsOf(t *testing.T) {
+	tests := []struct {
+		line  string
+		width int
+		want  int
+	}{
+		{line: "", width: 10, want: 1},
+		{line: "abc", width: 0, want: 1},
+		{line: "abcdefghij", width: 10, want: 1},
+		{line: "abcdefghijk", width: 10, want: 2},
+		{line: "\x1b[31mabc\x1b[0m", width: 3, want: 1},
+		{line: "日本語", width: 4, want: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			if got := rowsOf(tt.line, tt.width); got != tt.want {
+				t.Errorf("got %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
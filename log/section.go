@@ -0,0 +1,149 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// SectionKey is the attribute key that marks the records of Logger.Section and
+// Logger.Banner.
+const SectionKey = "section"
+
+// defaultSectionWidth is the width of dividers when neither the handler nor the
+// style sets one.
+const defaultSectionWidth = 80
+
+// sectionKind is the value of the SectionKey attribute. Handlers other than
+// CLIHandler write it as "divider" or "banner".
+type sectionKind int
+
+const (
+	sectionDivider sectionKind = iota
+	sectionBanner
+)
+
+// LogValue implements slog.LogValuer.
+func (k sectionKind) LogValue() slog.Value {
+	if k == sectionBanner {
+		return slog.StringValue("banner")
+	}
+	return slog.StringValue("divider")
+}
+
+// Section logs a divider with the title at slog.LevelInfo, to segment the
+// output of long runs. CLIHandler writes it as a line drawn with SectionStyle
+// in place of the record, e.g. "── title ──────".
+func (l *Logger) Section(title string) {
+	l.logSection(sectionDivider, title)
+}
+
+// Banner logs msg in a box at slog.LevelInfo. CLIHandler draws the box with
+// SectionStyle in place of the record, one line of the box per line of msg.
+func (l *Logger) Banner(msg string) {
+	l.logSection(sectionBanner, msg)
+}
+
+// logSection emits a record of the given kind with the caller of the exported
+// method as its source.
+func (l *Logger) logSection(kind sectionKind, msg string) {
+	ctx := context.Background()
+	if !l.Enabled(ctx, slog.LevelInfo) {
+		return
+	}
+	var pcs [1]uintptr
+	runtime.Callers(3+l.skip, pcs[:]) // skip [Callers, logSection, exported method]
+	r := slog.NewRecord(time.Now(), slog.LevelInfo, msg, pcs[0])
+	r.AddAttrs(slog.Any(SectionKey, kind))
+	_ = l.Handler().Handle(ctx, r)
+}
+
+// sectionOf returns the kind of the record written by Section or Banner. The
+// SectionKey attribute is their first attribute.
+func sectionOf(r slog.Record) (sectionKind, bool) {
+	var k sectionKind
+	var ok bool
+	r.Attrs(func(a slog.Attr) bool {
+		if a.Key == SectionKey && a.Value.Kind() == slog.KindLogValuer {
+			k, ok = a.Value.Any().(sectionKind)
+		}
+		return false
+	})
+	return k, ok
+}
+
+// writeSection writes the divider or the box of a section record to buf.
+func (h *CLIHandler) writeSection(buf *bytes.Buffer, kind sectionKind, msg string) {
+	msg = h.redactor.RedactString(msg)
+	ss := h.style.Section
+	if ss.Line == "" {
+		ss.Line = "-"
+	}
+	if ss.Side == "" {
+		ss.Side = "|"
+	}
+	for i, c := range ss.Corners {
+		if c == "" {
+			ss.Corners[i] = "+"
+		}
+	}
+	width := h.lineWidth()
+	if kind == sectionDivider {
+		if width == 0 {
+			width = ss.Width
+		}
+		if width <= 0 {
+			width = defaultSectionWidth
+		}
+		writeDivider(buf, ss, msg, width)
+		return
+	}
+	writeBanner(buf, ss, msg, width)
+}
+
+// writeDivider writes a divider line of the given width with the title to buf.
+func writeDivider(buf *bytes.Buffer, ss SectionStyle, title string, width int) {
+	lw := max(1, VisibleWidth(ss.Line))
+	if title == "" {
+		ss.Color.WriteString(buf, strings.Repeat(ss.Line, max(1, width/lw)))
+		buf.WriteString("\n")
+		return
+	}
+	ss.Color.WriteString(buf, ss.Line+ss.Line)
+	buf.WriteString(" ")
+	ss.TitleColor.WriteString(buf, title)
+	buf.WriteString(" ")
+	rest := width - 2*lw - 2 - VisibleWidth(title)
+	ss.Color.WriteString(buf, strings.Repeat(ss.Line, max(2, rest/lw)))
+	buf.WriteString("\n")
+}
+
+// writeBanner writes the lines of msg in a box to buf. The box spans width
+// columns if they fit, and fits the lines otherwise.
+func writeBanner(buf *bytes.Buffer, ss SectionStyle, msg string, width int) {
+	lines := strings.Split(msg, "\n")
+	inner := 0
+	for _, line := range lines {
+		inner = max(inner, VisibleWidth(line))
+	}
+	sw := VisibleWidth(ss.Side)
+	inner = max(inner+2, width-2*sw)
+	lw := max(1, VisibleWidth(ss.Line))
+	border := strings.Repeat(ss.Line, (inner+lw-1)/lw)
+
+	ss.Color.WriteString(buf, ss.Corners[0]+border+ss.Corners[1])
+	buf.WriteString("\n")
+	for _, line := range lines {
+		ss.Color.WriteString(buf, ss.Side)
+		buf.WriteString(" ")
+		ss.TitleColor.WriteString(buf, line)
+		writeSpaces(buf, inner-1-VisibleWidth(line))
+		ss.Color.WriteString(buf, ss.Side)
+		buf.WriteString("\n")
+	}
+	ss.Color.WriteString(buf, ss.Corners[3]+border+ss.Corners[2])
+	buf.WriteString("\n")
+}
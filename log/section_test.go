@@ -0,0 +1,130 @@
+package log
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestLogger_Section(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []CLIHandlerOption
+		log  func(*Logger)
+		want string
+	}{
+		{
+			name: "divider",
+			opts: []CLIHandlerOption{WithWidth(20)},
+			log:  func(l *Logger) { l.Section("Build") },
+			want: "-- Build -----------\n",
+		},
+		{
+			name: "divider without title",
+			opts: []CLIHandlerOption{WithWidth(10)},
+			log:  func(l *Logger) { l.Section("") },
+			want: "----------\n",
+		},
+		{
+			name: "divider wider than width",
+			opts: []CLIHandlerOption{WithWidth(8)},
+			log:  func(l *Logger) { l.Section("Deploy") },
+			want: "-- Deploy --\n",
+		},
+		{
+			name: "default width",
+			log:  func(l *Logger) { l.Section("Build") },
+			want: "-- Build " + strings.Repeat("-", 71) + "\n",
+		},
+		{
+			name: "style width",
+			opts: []CLIHandlerOption{WithStyle(NewStyle(WithSectionStyle(SectionStyle{Line: "=", Width: 12})))},
+			log:  func(l *Logger) { l.Section("a") },
+			want: "== a =======\n",
+		},
+		{
+			name: "banner",
+			log:  func(l *Logger) { l.Banner("Release v1.2.0\nall checks passed") },
+			want: "+-------------------+\n" +
+				"| Release v1.2.0    |\n" +
+				"| all checks passed |\n" +
+				"+-------------------+\n",
+		},
+		{
+			name: "banner with width",
+			opts: []CLIHandlerOption{WithWidth(12)},
+			log:  func(l *Logger) { l.Banner("done") },
+			want: "+----------+\n" +
+				"| done     |\n" +
+				"+----------+\n",
+		},
+		{
+			name: "rounded banner",
+			opts: []CLIHandlerOption{WithStyle(Style1()), WithColorMode(ColorNever)},
+			log:  func(l *Logger) { l.Banner("done") },
+			want: "╭──────╮\n" +
+				"│ done │\n" +
+				"╰──────╯\n",
+		},
+		{
+			name: "between records",
+			opts: []CLIHandlerOption{WithWidth(40), WithLabel("app"), WithCaller(true)},
+			log: func(l *Logger) {
+				l.Info("before")
+				l.Section("x")
+				l.Info("after")
+			},
+			want: "[INF] <section_test.go:",
+		},
+		{
+			name: "disabled",
+			opts: []CLIHandlerOption{WithLevel(slog.LevelWarn)},
+			log: func(l *Logger) {
+				l.Section("Build")
+				l.Banner("done")
+			},
+			want: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			opts := append([]CLIHandlerOption{WithStyle(Style0())}, tt.opts...)
+			tt.log(NewLogger(NewCLIHandler(buf, opts...)))
+			got := buf.String()
+			if tt.name == "between records" {
+				if lines := strings.Split(got, "\n"); len(lines) != 4 || lines[1] != "-- x "+strings.Repeat("-", 35) || !strings.HasPrefix(lines[2], tt.want) {
+					t.Errorf("got %q, want the divider alone between the records", got)
+				}
+				return
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLogger_Section_color(t *testing.T) {
+	buf := &bytes.Buffer{}
+	l := NewLogger(NewCLIHandler(buf, WithStyle(Style1()), WithColorMode(ColorAlways), WithWidth(12)))
+	l.Section("x")
+	gray, bold := NewColor(FgHiBlack), NewColor(Bold)
+	want := string(gray.Append(nil, "──")) + " " + string(bold.Append(nil, "x")) + " " + string(gray.Append(nil, "───────")) + "\n"
+	if got := buf.String(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestLogger_Section_logfmt(t *testing.T) {
+	buf := &bytes.Buffer{}
+	l := NewLogger(NewLogfmtHandler(buf, WithTime(false)))
+	l.Section("Build")
+	l.Banner("done")
+	for _, want := range []string{" level=INFO msg=Build section=divider\n", " level=INFO msg=done section=banner\n"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("got %q, want it to contain %q", buf.String(), want)
+		}
+	}
+}
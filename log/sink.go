@@ -0,0 +1,67 @@
+package log
+
+import (
+	"errors"
+	"io"
+	"sync/atomic"
+)
+
+// WithErrorHandler returns a CLIHandlerOption that sets a function called with
+// the errors of writing records, e.g. when the disk is full or a pipe is closed,
+// so that applications can react to the failure of their log sink. It is called
+// after the handler is unlocked, so it may log to another handler.
+//
+// A writer that turns out to be a broken pipe, e.g. when the reader of mycli |
+// head exits, is not written to again: the error is reported once, and the
+// records for that writer are dropped without error. See also IgnoreSIGPIPE.
+func WithErrorHandler(fn func(err error)) CLIHandlerOption {
+	return func(c *CLIHandler) {
+		c.errHandler = fn
+	}
+}
+
+// WithFallbackWriter returns a CLIHandlerOption that sets a writer taking the
+// records that fail to be written to their writer, e.g. os.Stderr. The failure
+// is still reported to the function set by WithErrorHandler, but Handle returns
+// an error only if the fallback writer fails too.
+func WithFallbackWriter(w io.Writer) CLIHandlerOption {
+	return func(c *CLIHandler) {
+		if w == nil {
+			c.fallback = nil
+			return
+		}
+		c.fallback = setColorable(w)
+	}
+}
+
+// output writes b to w, or to the fallback writer if that fails. It returns the
+// failure reported to the error handler, and the error returned by Handle, which
+// is nil if the fallback writer took the record. If w is a broken pipe, broken
+// is set and b is dropped, so that the records written to w afterwards are
+// dropped without error.
+func (h *CLIHandler) output(w io.Writer, broken *atomic.Bool, b []byte) (failure, err error) {
+	if _, err = w.Write(b); err == nil {
+		return nil, nil
+	}
+	failure = err
+	if isBrokenPipe(err) && broken != nil {
+		broken.Store(true)
+		return failure, nil
+	}
+	if h.fallback != nil {
+		_, ferr := h.fallback.Write(b)
+		if ferr == nil {
+			return failure, nil
+		}
+		failure = errors.Join(failure, ferr)
+		err = failure
+	}
+	return failure, err
+}
+
+// reportFailure calls the error handler with *failure unless it is nil.
+func (h *CLIHandler) reportFailure(failure *error) {
+	if *failure != nil && h.errHandler != nil {
+		h.errHandler(*failure)
+	}
+}
@@ -0,0 +1,120 @@
+package log
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"runtime"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// failWriter fails every write with err.
+type failWriter struct{ err error }
+
+func (w failWriter) Write([]byte) (int, error) {
+	return 0, w.err
+}
+
+func TestWithErrorHandler(t *testing.T) {
+	errFull := errors.New("disk full")
+	var got []error
+	h := NewCLIHandler(failWriter{errFull}, WithStyle(Style0()), WithErrorHandler(func(err error) {
+		got = append(got, err)
+	}))
+	l := NewLogger(h)
+	l.Info("msg")
+	NewLogger(h.WithAttrs(nil)).With("k", "v").Info("msg")
+	if len(got) != 2 || !errors.Is(got[0], errFull) || !errors.Is(got[1], errFull) {
+		t.Errorf("got %v, want %v reported twice", got, errFull)
+	}
+}
+
+func TestWithErrorHandler_unlocked(t *testing.T) {
+	var logged bool
+	var l *Logger
+	l = NewLogger(NewCLIHandler(failWriter{errors.New("closed pipe")}, WithErrorHandler(func(error) {
+		if logged {
+			return
+		}
+		logged = true
+		l.Info("reported") // must not deadlock
+	})))
+	l.Info("msg")
+	if !logged {
+		t.Error("error handler not called")
+	}
+}
+
+func TestWithFallbackWriter(t *testing.T) {
+	errFull := errors.New("disk full")
+	buf := &bytes.Buffer{}
+	var got error
+	h := NewCLIHandler(failWriter{errFull}, WithStyle(Style0()), WithFallbackWriter(buf), WithErrorHandler(func(err error) {
+		got = err
+	}))
+	if err := NewLogger(h).Handler().Handle(t.Context(), slog.NewRecord(time.Time{}, slog.LevelInfo, "msg", 0)); err != nil {
+		t.Errorf("Handle = %v, want nil", err)
+	}
+	if want := "[INF] msg\n"; buf.String() != want {
+		t.Errorf("fallback got %q, want %q", buf.String(), want)
+	}
+	if !errors.Is(got, errFull) {
+		t.Errorf("reported %v, want %v", got, errFull)
+	}
+
+	errBroken := errors.New("broken")
+	h = NewCLIHandler(failWriter{errFull}, WithFallbackWriter(failWriter{errBroken}))
+	if err := h.Handle(t.Context(), slog.NewRecord(time.Time{}, slog.LevelInfo, "msg", 0)); !errors.Is(err, errFull) || !errors.Is(err, errBroken) {
+		t.Errorf("Handle = %v, want both errors", err)
+	}
+}
+
+func TestCLIHandler_Handle_brokenPipe(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("writes to a closed pipe do not fail with EPIPE on windows")
+	}
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	r.Close()
+	var got []error
+	h := NewCLIHandler(w, WithErrorHandler(func(err error) {
+		got = append(got, err)
+	}))
+	ctx := t.Context()
+	for range 3 {
+		if err := h.Handle(ctx, slog.NewRecord(time.Time{}, slog.LevelInfo, "msg", 0)); err != nil {
+			t.Errorf("Handle = %v, want nil", err)
+		}
+	}
+	if len(got) != 1 || !errors.Is(got[0], syscall.EPIPE) {
+		t.Errorf("reported %v, want EPIPE once", got)
+	}
+	if h.Enabled(ctx, slog.LevelError) {
+		t.Error("Enabled = true, want false after broken pipe")
+	}
+	if h.WithAttrs([]slog.Attr{slog.Int("k", 1)}).Enabled(ctx, slog.LevelError) {
+		t.Error("derived handler Enabled = true, want false after broken pipe")
+	}
+}
+
+func TestCLIHandler_Handle_brokenLevelWriter(t *testing.T) {
+	buf := &bytes.Buffer{}
+	h := NewCLIHandler(failWriter{io.ErrClosedPipe}, WithStyle(Style0()), WithLevelWriter(map[slog.Level]io.Writer{slog.LevelWarn: buf}))
+	l := NewLogger(h)
+	l.Info("dropped")
+	l.Info("dropped")
+	l.Warn("written")
+	if want := "[WRN] written\n"; buf.String() != want {
+		t.Errorf("got %q, want %q", buf.String(), want)
+	}
+	if !h.Enabled(t.Context(), slog.LevelInfo) {
+		t.Error("Enabled = false, want true with a level writer")
+	}
+}
@@ -0,0 +1,113 @@
+package log
+
+import (
+	"log/slog"
+	"slices"
+)
+
+// AttrSort orders the attributes written by CLIHandler. The zero value is
+// SortInsertion.
+type AttrSort struct {
+	less func(a, b slog.Attr) bool
+}
+
+var (
+	// SortInsertion writes the attributes in the order they were added.
+	SortInsertion = AttrSort{}
+
+	// SortAlphabetical writes the attributes ordered by key.
+	SortAlphabetical = SortCustom(func(a, b slog.Attr) bool { return a.Key < b.Key })
+)
+
+// SortCustom returns an AttrSort that orders the attributes by less, which
+// reports whether a is written before b. Attributes less orders neither way keep
+// the order they were added in.
+func SortCustom(less func(a, b slog.Attr) bool) AttrSort {
+	return AttrSort{less: less}
+}
+
+// SortPinned returns an AttrSort that writes the attributes with the given keys
+// first, in the order of keys, and the others after them in the order they were
+// added, e.g. SortPinned("err", "duration").
+func SortPinned(keys ...string) AttrSort {
+	rank := make(map[string]int, len(keys))
+	for i, k := range keys {
+		if _, ok := rank[k]; !ok {
+			rank[k] = i
+		}
+	}
+	pos := func(a slog.Attr) int {
+		if i, ok := rank[a.Key]; ok {
+			return i
+		}
+		return len(keys)
+	}
+	return SortCustom(func(a, b slog.Attr) bool { return pos(a) < pos(b) })
+}
+
+// WithAttrSort returns a CLIHandlerOption that sets the order in which the
+// attributes of a record are written, so that the output does not depend on the
+// order of the call site. The handler, context and record attributes are ordered
+// together, and the members of groups among themselves. The default is
+// SortInsertion.
+func WithAttrSort(sort AttrSort) CLIHandlerOption {
+	return func(c *CLIHandler) {
+		c.attrSort = sort
+	}
+}
+
+// compare returns the result of less as a comparison function.
+func (s AttrSort) compare(a, b slog.Attr) int {
+	switch {
+	case s.less(a, b):
+		return -1
+	case s.less(b, a):
+		return 1
+	}
+	return 0
+}
+
+// sort orders attrs in place and the members of their groups in copies, as
+// groups may be shared with other records.
+func (s AttrSort) sort(attrs []slog.Attr) {
+	if s.less == nil {
+		return
+	}
+	slices.SortStableFunc(attrs, s.compare)
+	for i, a := range attrs {
+		if v := a.Value.Resolve(); v.Kind() == slog.KindGroup {
+			attrs[i].Value = s.sortGroup(v.Group())
+		}
+	}
+}
+
+// sortGroup returns a group value with the members ordered, and the members of
+// inline groups ordered among them. The members are copied only if they are not
+// ordered yet.
+func (s AttrSort) sortGroup(members []slog.Attr) slog.Value {
+	switch {
+	case slices.ContainsFunc(members, isInlineGroup):
+		members = inlineGroups(nil, members)
+	case slices.IsSortedFunc(members, s.compare) && !slices.ContainsFunc(members, isGroupAttr):
+		return slog.GroupValue(members...)
+	default:
+		members = slices.Clone(members)
+	}
+	s.sort(members)
+	return slog.GroupValue(members...)
+}
+
+// sortLines orders the attributes of multiline output by their qualified keys.
+func (s AttrSort) sortLines(attrs []lineAttr) {
+	if s.less == nil {
+		return
+	}
+	slices.SortStableFunc(attrs, func(a, b lineAttr) int {
+		return s.compare(slog.Attr{Key: a.key(), Value: a.attr.Value}, slog.Attr{Key: b.key(), Value: b.attr.Value})
+	})
+}
+
+// isGroupAttr reports whether a is a group.
+func isGroupAttr(a slog.Attr) bool {
+	return a.Value.Resolve().Kind() == slog.KindGroup
+}
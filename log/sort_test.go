@@ -0,0 +1,95 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+)
+
+func TestCLIHandler_Handle_attrSort(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []CLIHandlerOption
+		log  func(l *Logger)
+		want string
+	}{
+		{
+			name: "insertion",
+			opts: []CLIHandlerOption{WithAttrSort(SortInsertion)},
+			log:  func(l *Logger) { l.Info("msg", "b", 1, "a", 2) },
+			want: "[INF] msg b=1 a=2\n",
+		},
+		{
+			name: "alphabetical",
+			opts: []CLIHandlerOption{WithAttrSort(SortAlphabetical)},
+			log: func(l *Logger) {
+				h := l.Handler().WithAttrs([]slog.Attr{slog.Int("d", 1)})
+				NewLogger(h).InfoContext(NewContext(context.Background(), slog.Int("c", 2)), "msg", "b", 3, "a", 4)
+			},
+			want: "[INF] msg a=4 b=3 c=2 d=1\n",
+		},
+		{
+			name: "alphabetical groups",
+			opts: []CLIHandlerOption{WithAttrSort(SortAlphabetical)},
+			log:  func(l *Logger) { l.Info("msg", "z", 1, slog.Group("g", "y", 2, "x", 3)) },
+			want: "[INF] msg g.x=3 g.y=2 z=1\n",
+		},
+		{
+			name: "alphabetical open groups",
+			opts: []CLIHandlerOption{WithAttrSort(SortAlphabetical)},
+			log: func(l *Logger) {
+				h := l.Handler().WithAttrs([]slog.Attr{slog.Int("b", 1)}).WithGroup("g")
+				NewLogger(h).Info("msg", "y", 2, "x", 3)
+			},
+			want: "[INF] msg b=1 g.x=3 g.y=2\n",
+		},
+		{
+			name: "pinned",
+			opts: []CLIHandlerOption{WithAttrSort(SortPinned("err", "duration"))},
+			log:  func(l *Logger) { l.Info("msg", "a", 1, "duration", "1s", "b", 2, "err", "failed") },
+			want: "[INF] msg err=failed duration=1s a=1 b=2\n",
+		},
+		{
+			name: "custom",
+			opts: []CLIHandlerOption{WithAttrSort(SortCustom(func(a, b slog.Attr) bool { return a.Key > b.Key }))},
+			log:  func(l *Logger) { l.Info("msg", "a", 1, "c", 2, "b", 3) },
+			want: "[INF] msg c=2 b=3 a=1\n",
+		},
+		{
+			name: "dedup",
+			opts: []CLIHandlerOption{WithAttrSort(SortAlphabetical), WithDedupAttrs(true)},
+			log: func(l *Logger) {
+				NewLogger(l.Handler().WithAttrs([]slog.Attr{slog.Int("b", 1)})).Info("msg", "a", 2, "b", 3)
+			},
+			want: "[INF] msg a=2 b=3\n",
+		},
+		{
+			name: "multiline",
+			opts: []CLIHandlerOption{WithAttrSort(SortAlphabetical), WithMultiline(true)},
+			log: func(l *Logger) {
+				NewLogger(l.Handler().WithAttrs([]slog.Attr{slog.Int("c", 1)})).Info("msg", "b", 2, slog.Group("a", "y", 3, "x", 4))
+			},
+			want: "[INF] msg\n  a.x=4\n  a.y=3\n  b  =2\n  c  =1\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			opts := append([]CLIHandlerOption{WithStyle(Style0())}, tt.opts...)
+			tt.log(NewLogger(NewCLIHandler(buf, opts...)))
+			if got := buf.String(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAttrSort_sharedGroups(t *testing.T) {
+	members := []slog.Attr{slog.Int("b", 1), slog.Int("a", 2)}
+	h := NewCLIHandler(&bytes.Buffer{}, WithStyle(Style0()), WithAttrSort(SortAlphabetical))
+	NewLogger(h).Info("msg", slog.Attr{Key: "g", Value: slog.GroupValue(members...)})
+	if members[0].Key != "b" || members[1].Key != "a" {
+		t.Errorf("members = %v, want unchanged", members)
+	}
+}
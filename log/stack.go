@@ -0,0 +1,49 @@
+package log
+
+import (
+	"bytes"
+	"runtime"
+	"slices"
+	"strconv"
+)
+
+// maxStackDepth is the maximum number of frames captured for a stack trace.
+const maxStackDepth = 64
+
+// writeStack writes the stack trace starting at the frame of pc to buf, passing
+// each file through file. The stack is captured from the current goroutine, so pc
+// must belong to it; if it is not found, e.g. when the record is handled by
+// another goroutine as with AsyncHandler, nothing is written rather than the
+// stack of that goroutine.
+func writeStack(buf *bytes.Buffer, pc uintptr, style *Style, file func(string) string) {
+	if pc == 0 {
+		return
+	}
+	var pcs [maxStackDepth]uintptr
+	n := runtime.Callers(2, pcs[:])
+	i := slices.Index(pcs[:n], pc)
+	if i < 0 {
+		return
+	}
+	stack := pcs[i:n]
+	st := style.Stack
+	frames := runtime.CallersFrames(stack)
+	for {
+		f, more := frames.Next()
+		if f.Function != "" {
+			var b [16]byte
+			buf.WriteString(st.Indent)
+			st.Color.WriteString(buf, f.Function)
+			buf.WriteString("\n")
+			buf.WriteString(st.Indent)
+			buf.WriteString(st.Indent)
+			st.Color.WriteString(buf, file(f.File))
+			st.Color.WriteString(buf, ":")
+			st.Color.WriteBytes(buf, strconv.AppendInt(b[:0], int64(f.Line), 10))
+			buf.WriteString("\n")
+		}
+		if !more {
+			break
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package log
+
+import (
+	"bytes"
+	"log/slog"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestCLIHandler_stackTrace(t *testing.T) {
+	tests := []struct {
+		name  string
+		opts  []CLIHandlerOption
+		log   func(*Logger)
+		check func(*testing.T, string)
+	}{
+		{
+			name: "disabled",
+			opts: nil,
+			log:  func(l *Logger) { l.Error("msg") },
+			check: func(t *testing.T, got string) {
+				if got != "[ERR] msg\n" {
+					t.Errorf("got %q, want %q", got, "[ERR] msg\n")
+				}
+			},
+		},
+		{
+			name: "below level",
+			opts: []CLIHandlerOption{WithStackTrace(slog.LevelError)},
+			log:  func(l *Logger) { l.Warn("msg") },
+			check: func(t *testing.T, got string) {
+				if got != "[WRN] msg\n" {
+					t.Errorf("got %q, want %q", got, "[WRN] msg\n")
+				}
+			},
+		},
+		{
+			name: "at level",
+			opts: []CLIHandlerOption{WithStackTrace(slog.LevelError)},
+			log:  func(l *Logger) { l.Error("msg") },
+			check: func(t *testing.T, got string) {
+				lines := strings.Split(strings.TrimSuffix(got, "\n"), "\n")
+				if lines[0] != "[ERR] msg" {
+					t.Errorf("first line = %q, want %q", lines[0], "[ERR] msg")
+				}
+				if len(lines) < 3 {
+					t.Fatalf("got %q, want stack trace", got)
+				}
+				if !strings.HasPrefix(lines[1], "  ") || !strings.Contains(lines[1], "TestCLIHandler_stackTrace") {
+					t.Errorf("first frame = %q, want caller function", lines[1])
+				}
+				if !strings.HasPrefix(lines[2], "    ") || !strings.Contains(lines[2], "stack_test.go:") {
+					t.Errorf("first frame file = %q, want caller file", lines[2])
+				}
+				if strings.Contains(got, "log/slog.") {
+					t.Errorf("got %q, want slog frames skipped", got)
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			opts := append([]CLIHandlerOption{WithStyle(Style0())}, tt.opts...)
+			tt.log(NewLogger(NewCLIHandler(buf, opts...)))
+			tt.check(t, buf.String())
+		})
+	}
+}
+
+func TestCLIHandler_stackTrace_async(t *testing.T) {
+	buf := &bytes.Buffer{}
+	h := NewAsyncHandler(NewCLIHandler(buf, WithStyle(Style0()), WithStackTrace(slog.LevelError)))
+	NewLogger(h).Error("msg")
+	if err := h.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if got := buf.String(); got != "[ERR] msg\n" {
+		t.Errorf("got %q, want %q", got, "[ERR] msg\n")
+	}
+}
+
+func Test_writeStack(t *testing.T) {
+	s := Style0()
+	s.Stack.Color = NewColor(Faint)
+	file := func(f string) string { return f }
+
+	buf := &bytes.Buffer{}
+	func() {
+		var pcs [1]uintptr
+		runtime.Callers(2, pcs[:])
+		writeStack(buf, pcs[0], s, file)
+	}()
+	got := buf.String()
+	if !strings.HasPrefix(got, "  \x1b[2mgithub.com/nekrassov01/logger/log.Test_writeStack\x1b[0m\n") {
+		t.Errorf("got %q, want colored test frame first", got)
+	}
+
+	for _, pc := range []uintptr{0, 1} {
+		buf.Reset()
+		writeStack(buf, pc, s, file)
+		if buf.Len() != 0 {
+			t.Errorf("pc %d: got %q, want nothing for a pc not on the stack", pc, buf.String())
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package log
+
+import (
+	"context"
+	stdlog "log"
+	"log/slog"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// stdLevels maps the level prefixes recognized in standard log messages to levels.
+var stdLevels = map[string]slog.Level{
+	"TRACE":   LevelTrace,
+	"TRC":     LevelTrace,
+	"DEBUG":   slog.LevelDebug,
+	"DBG":     slog.LevelDebug,
+	"INFO":    slog.LevelInfo,
+	"INF":     slog.LevelInfo,
+	"NOTICE":  LevelNotice,
+	"NTC":     LevelNotice,
+	"WARN":    slog.LevelWarn,
+	"WARNING": slog.LevelWarn,
+	"WRN":     slog.LevelWarn,
+	"ERROR":   slog.LevelError,
+	"ERR":     slog.LevelError,
+	"FATAL":   LevelFatal,
+	"FTL":     LevelFatal,
+}
+
+// NewStdLogger creates a standard library logger that writes each message as a
+// record to handler. Messages starting with a level prefix such as "[WARN] " or
+// "ERROR: " are logged at that level without the prefix; others are logged at level.
+func NewStdLogger(handler slog.Handler, level slog.Level) *stdlog.Logger {
+	return stdlog.New(newStdWriter(handler, level), "", 0)
+}
+
+// RedirectStdLog routes the output of the standard library's default logger to
+// handler at slog.LevelInfo, parsing level prefixes as NewStdLogger does. It
+// returns a function restoring the previous output, prefix and flags.
+func RedirectStdLog(handler slog.Handler) func() {
+	w, prefix, flags := stdlog.Writer(), stdlog.Prefix(), stdlog.Flags()
+	stdlog.SetOutput(newStdWriter(handler, slog.LevelInfo))
+	stdlog.SetPrefix("")
+	stdlog.SetFlags(0)
+	return func() {
+		stdlog.SetOutput(w)
+		stdlog.SetPrefix(prefix)
+		stdlog.SetFlags(flags)
+	}
+}
+
+// stdWriter is an io.Writer passing standard log messages to a handler.
+type stdWriter struct {
+	handler slog.Handler
+	level   slog.Level
+}
+
+// newStdWriter creates a new stdWriter. A nil handler discards messages.
+func newStdWriter(handler slog.Handler, level slog.Level) *stdWriter {
+	if handler == nil {
+		handler = NewCLIHandler(nil)
+	}
+	return &stdWriter{handler: handler, level: level}
+}
+
+// Write logs p as a record, reporting the caller of the standard logger.
+func (w *stdWriter) Write(p []byte) (int, error) {
+	msg := strings.TrimSuffix(string(p), "\n")
+	level, msg := parseStdLevel(msg, w.level)
+	ctx := context.Background()
+	if !w.handler.Enabled(ctx, level) {
+		return len(p), nil
+	}
+	r := slog.NewRecord(time.Now(), level, msg, stdCaller())
+	if err := w.handler.Handle(ctx, r); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// stdCaller returns the PC of the first caller outside the standard log package.
+func stdCaller() uintptr {
+	var pcs [16]uintptr
+	n := runtime.Callers(3, pcs[:])
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		f, more := frames.Next()
+		if !strings.HasPrefix(f.Function, "log.") {
+			return f.PC + 1
+		}
+		if !more {
+			return 0
+		}
+	}
+}
+
+// parseStdLevel returns the level of the prefix of msg and msg without it, or
+// def and msg if msg has no level prefix.
+func parseStdLevel(msg string, def slog.Level) (slog.Level, string) {
+	var word, rest string
+	switch {
+	case strings.HasPrefix(msg, "["):
+		i := strings.IndexByte(msg, ']')
+		if i < 0 {
+			return def, msg
+		}
+		word, rest = msg[1:i], msg[i+1:]
+	default:
+		i := strings.IndexByte(msg, ':')
+		if i < 0 {
+			return def, msg
+		}
+		word, rest = msg[:i], msg[i+1:]
+	}
+	level, ok := stdLevels[strings.ToUpper(word)]
+	if !ok {
+		return def, msg
+	}
+	return level, strings.TrimLeft(rest, " ")
+}
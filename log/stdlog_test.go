@@ -0,0 +1,106 @@
+package log
+
+import (
+	"bytes"
+	"io"
+	stdlog "log"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestParseStdLevel(t *testing.T) {
+	tests := []struct {
+		msg       string
+		wantLevel slog.Level
+		wantMsg   string
+	}{
+		{msg: "hello", wantLevel: slog.LevelInfo, wantMsg: "hello"},
+		{msg: "[WARN] disk low", wantLevel: slog.LevelWarn, wantMsg: "disk low"},
+		{msg: "[debug]x", wantLevel: slog.LevelDebug, wantMsg: "x"},
+		{msg: "ERROR: failed", wantLevel: slog.LevelError, wantMsg: "failed"},
+		{msg: "fatal: boom", wantLevel: LevelFatal, wantMsg: "boom"},
+		{msg: "[TRACE] boom", wantLevel: LevelTrace, wantMsg: "boom"},
+		{msg: "NOTICE: boom", wantLevel: LevelNotice, wantMsg: "boom"},
+		{msg: "[main] started", wantLevel: slog.LevelInfo, wantMsg: "[main] started"},
+		{msg: "[WARN unclosed", wantLevel: slog.LevelInfo, wantMsg: "[WARN unclosed"},
+		{msg: "error opening file", wantLevel: slog.LevelInfo, wantMsg: "error opening file"},
+		{msg: "key: value", wantLevel: slog.LevelInfo, wantMsg: "key: value"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.msg, func(t *testing.T) {
+			level, msg := parseStdLevel(tt.msg, slog.LevelInfo)
+			if level != tt.wantLevel || msg != tt.wantMsg {
+				t.Errorf("parseStdLevel(%q) = %v, %q, want %v, %q", tt.msg, level, msg, tt.wantLevel, tt.wantMsg)
+			}
+		})
+	}
+}
+
+func TestNewStdLogger(t *testing.T) {
+	tests := []struct {
+		name  string
+		level slog.Level
+		print func(*stdlog.Logger)
+		want  string
+	}{
+		{
+			name:  "default level",
+			level: slog.LevelWarn,
+			print: func(l *stdlog.Logger) { l.Print("hello") },
+			want:  "[WRN] hello\n",
+		},
+		{
+			name:  "level prefix",
+			level: slog.LevelInfo,
+			print: func(l *stdlog.Logger) { l.Printf("[ERROR] code=%d", 1) },
+			want:  "[ERR] code=1\n",
+		},
+		{
+			name:  "below handler level",
+			level: slog.LevelInfo,
+			print: func(l *stdlog.Logger) { l.Println("DEBUG: hidden") },
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			l := NewStdLogger(NewCLIHandler(buf, WithStyle(Style0())), tt.level)
+			tt.print(l)
+			if got := buf.String(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewStdLogger_caller(t *testing.T) {
+	buf := &bytes.Buffer{}
+	l := NewStdLogger(NewCLIHandler(buf, WithStyle(Style0()), WithCaller(true)), slog.LevelInfo)
+	l.Print("hello")
+	if got := buf.String(); !strings.Contains(got, "stdlog_test.go:") {
+		t.Errorf("got %q, want caller in stdlog_test.go", got)
+	}
+}
+
+func TestRedirectStdLog(t *testing.T) {
+	defer func(w io.Writer, prefix string, flags int) {
+		stdlog.SetOutput(w)
+		stdlog.SetPrefix(prefix)
+		stdlog.SetFlags(flags)
+	}(stdlog.Writer(), stdlog.Prefix(), stdlog.Flags())
+	buf := &bytes.Buffer{}
+	prev := &bytes.Buffer{}
+	stdlog.SetOutput(prev)
+	stdlog.SetFlags(stdlog.LstdFlags)
+	stdlog.SetPrefix("app: ")
+	restore := RedirectStdLog(NewCLIHandler(buf, WithStyle(Style0())))
+	stdlog.Print("[WARN] redirected")
+	restore()
+	if got, want := buf.String(), "[WRN] redirected\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if stdlog.Writer() != prev || stdlog.Prefix() != "app: " || stdlog.Flags() != stdlog.LstdFlags {
+		t.Error("standard logger not restored")
+	}
+}
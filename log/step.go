@@ -0,0 +1,173 @@
+package log
+
+import (
+	"context"
+	"log/slog"
+	"runtime"
+	"sync/atomic"
+	"time"
+)
+
+// Attribute keys of the records logged by Step.
+const (
+	StepKey    = "step"
+	ElapsedKey = "elapsed"
+)
+
+// StepStatus is the status of a Step.
+type StepStatus int
+
+const (
+	// StepRunning is the status of a started step.
+	StepRunning StepStatus = iota
+
+	// StepSuccess is the status of a step ended by Success.
+	StepSuccess
+
+	// StepFailed is the status of a step ended by Fail.
+	StepFailed
+
+	// StepSkipped is the status of a step ended by Skip.
+	StepSkipped
+)
+
+// String returns the name of the status as written to the StepKey attribute.
+func (s StepStatus) String() string {
+	switch s {
+	case StepRunning:
+		return "running"
+	case StepSuccess:
+		return "success"
+	case StepFailed:
+		return "failed"
+	case StepSkipped:
+		return "skipped"
+	}
+	return "unknown"
+}
+
+// stepIDs generates the IDs that tie the records of a step together.
+var stepIDs atomic.Uint64
+
+// stepValue is the value of the StepKey attribute. Handlers other than
+// CLIHandler write it as the name of the status.
+type stepValue struct {
+	id     uint64
+	status StepStatus
+}
+
+// LogValue implements slog.LogValuer.
+func (v stepValue) LogValue() slog.Value {
+	return slog.StringValue(v.status.String())
+}
+
+// Step is a unit of work of a CLI workflow, such as a deployment stage. It is
+// logged once when it starts and once when it ends with Success, Fail or Skip.
+// CLIHandler writes the mark of the status from StepStyle before the message,
+// and on terminals replaces the start line with the end line if nothing was
+// written in between.
+type Step struct {
+	l     *Logger
+	id    uint64
+	name  string
+	start time.Time
+	done  atomic.Bool
+}
+
+// Step logs the start of a step named name at slog.LevelInfo with the given
+// attributes, which are also added to the end record, and returns the Step.
+func (l *Logger) Step(name string, args ...any) *Step {
+	s := &Step{
+		l:     l.With(args...),
+		id:    stepIDs.Add(1),
+		name:  name,
+		start: time.Now(),
+	}
+	s.log(3, slog.LevelInfo, StepRunning) // skip [Callers, log, Step]
+	return s
+}
+
+// Success logs the successful end of the step at slog.LevelInfo with the
+// elapsed time and the given attributes.
+func (s *Step) Success(args ...any) {
+	s.end(slog.LevelInfo, StepSuccess, args...)
+}
+
+// Fail logs the failed end of the step at slog.LevelError with the elapsed
+// time, err and the given attributes.
+func (s *Step) Fail(err error, args ...any) {
+	if err != nil {
+		args = append([]any{Err(err)}, args...)
+	}
+	s.end(slog.LevelError, StepFailed, args...)
+}
+
+// Skip logs the skipped end of the step at slog.LevelInfo with the given
+// attributes.
+func (s *Step) Skip(args ...any) {
+	s.end(slog.LevelInfo, StepSkipped, args...)
+}
+
+// Elapsed returns the time since the step started.
+func (s *Step) Elapsed() time.Duration {
+	return time.Since(s.start)
+}
+
+// end logs the end of the step once; later calls do nothing.
+func (s *Step) end(level slog.Level, status StepStatus, args ...any) {
+	if s.done.Swap(true) {
+		return
+	}
+	if status != StepSkipped {
+		args = append([]any{slog.Duration(ElapsedKey, roundElapsed(s.Elapsed()))}, args...)
+	}
+	s.log(4, level, status, args...) // skip [Callers, log, end, exported method]
+}
+
+// log emits a record of the step with the caller skip frames up, adjusted by
+// the caller skip of the logger, as its source.
+func (s *Step) log(skip int, level slog.Level, status StepStatus, args ...any) {
+	ctx := context.Background()
+	if !s.l.Enabled(ctx, level) {
+		return
+	}
+	var pcs [1]uintptr
+	runtime.Callers(skip+s.l.skip, pcs[:])
+	r := slog.NewRecord(time.Now(), level, s.name, pcs[0])
+	r.AddAttrs(slog.Any(StepKey, stepValue{id: s.id, status: status}))
+	r.Add(args...)
+	_ = s.l.Handler().Handle(ctx, r)
+}
+
+// roundElapsed rounds d to a precision that suits its magnitude.
+func roundElapsed(d time.Duration) time.Duration {
+	switch {
+	case d >= time.Minute:
+		return d.Round(time.Second)
+	case d >= time.Second:
+		return d.Round(10 * time.Millisecond)
+	case d >= time.Millisecond:
+		return d.Round(time.Millisecond)
+	}
+	return d.Round(time.Microsecond)
+}
+
+// stepOf returns the value of the StepKey attribute of the record written by a Step.
+func stepOf(r slog.Record) (stepValue, bool) {
+	var v stepValue
+	var ok bool
+	r.Attrs(func(a slog.Attr) bool {
+		v, ok = stepAttr(a)
+		return !ok
+	})
+	return v, ok
+}
+
+// stepAttr returns the value of attr if it is the StepKey attribute written by a Step.
+func stepAttr(attr slog.Attr) (stepValue, bool) {
+	if attr.Key != StepKey || attr.Value.Kind() != slog.KindLogValuer {
+		return stepValue{}, false
+	}
+	v, ok := attr.Value.Any().(stepValue)
+	return v, ok
+}
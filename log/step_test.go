@@ -0,0 +1,172 @@
+package log
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+// elapsedPattern matches the elapsed time of a step, which varies between runs.
+var elapsedPattern = regexp.MustCompile(`elapsed=[^ \n]+`)
+
+func TestLogger_Step(t *testing.T) {
+	tests := []struct {
+		name string
+		run  func(*Logger)
+		want string
+	}{
+		{
+			name: "success",
+			run: func(l *Logger) {
+				l.Step("deploy", "env", "prod").Success("version", 2)
+			},
+			want: "[INF] • deploy env=prod\n[INF] ✓ deploy env=prod elapsed=X version=2\n",
+		},
+		{
+			name: "fail",
+			run: func(l *Logger) {
+				l.Step("deploy").Fail(errors.New("boom"))
+			},
+			want: "[INF] • deploy\n[ERR] ✗ deploy elapsed=X error=boom\n",
+		},
+		{
+			name: "skip",
+			run: func(l *Logger) {
+				l.Step("deploy").Skip("reason", "unchanged")
+			},
+			want: "[INF] • deploy\n[INF] ○ deploy reason=unchanged\n",
+		},
+		{
+			name: "ended once",
+			run: func(l *Logger) {
+				s := l.Step("deploy")
+				s.Success()
+				s.Fail(errors.New("boom"))
+				s.Skip()
+			},
+			want: "[INF] • deploy\n[INF] ✓ deploy elapsed=X\n",
+		},
+		{
+			name: "disabled start",
+			run: func(l *Logger) {
+				l2 := NewLogger(NewCLIHandler(&bytes.Buffer{}, WithLevel(slog.LevelError)))
+				l2.Step("deploy").Success()
+				l.Info("msg")
+			},
+			want: "[INF] msg\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			tt.run(NewLogger(NewCLIHandler(buf, WithStyle(Style0()))))
+			if got := elapsedPattern.ReplaceAllString(buf.String(), "elapsed=X"); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLogger_Step_live(t *testing.T) {
+	tests := []struct {
+		name string
+		run  func(*Logger)
+		want string
+	}{
+		{
+			name: "replaced",
+			run: func(l *Logger) {
+				l.Step("deploy").Success()
+			},
+			want: "[INF] • deploy\n\x1b[1A\r\x1b[J[INF] ✓ deploy elapsed=X\n",
+		},
+		{
+			name: "interleaved",
+			run: func(l *Logger) {
+				s := l.Step("deploy")
+				l.Info("msg")
+				s.Success()
+			},
+			want: "[INF] • deploy\n[INF] msg\n[INF] ✓ deploy elapsed=X\n",
+		},
+		{
+			name: "nested",
+			run: func(l *Logger) {
+				outer := l.Step("outer")
+				inner := l.With("k", 1).Step("inner")
+				inner.Success()
+				outer.Success()
+			},
+			want: "[INF] • outer\n[INF] • inner k=1\n\x1b[1A\r\x1b[J[INF] ✓ inner k=1 elapsed=X\n[INF] ✓ outer elapsed=X\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			h := NewCLIHandler(buf, WithStyle(Style0())).(*CLIHandler)
+			h.liveSteps = true
+			tt.run(NewLogger(h))
+			if got := elapsedPattern.ReplaceAllString(buf.String(), "elapsed=X"); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLogger_Step_json(t *testing.T) {
+	buf := &bytes.Buffer{}
+	l := NewLogger(slog.NewJSONHandler(buf, nil))
+	l.Step("deploy").Fail(errors.New("boom"))
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines, want 2: %q", len(lines), buf.String())
+	}
+	for i, want := range []string{`"msg":"deploy","step":"running"}`, `"msg":"deploy","step":"failed","elapsed":`} {
+		if !strings.Contains(lines[i], want) {
+			t.Errorf("line %d = %q, want it to contain %q", i, lines[i], want)
+		}
+	}
+}
+
+func TestStepStatus_String(t *testing.T) {
+	tests := []struct {
+		status StepStatus
+		want   string
+	}{
+		{status: StepRunning, want: "running"},
+		{status: StepSuccess, want: "success"},
+		{status: StepFailed, want: "failed"},
+		{status: StepSkipped, want: "skipped"},
+		{status: StepStatus(-1), want: "unknown"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			if got := tt.status.String(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_roundElapsed(t *testing.T) {
+	tests := []struct {
+		in   time.Duration
+		want time.Duration
+	}{
+		{in: 1234 * time.Nanosecond, want: time.Microsecond},
+		{in: 12345678 * time.Nanosecond, want: 12 * time.Millisecond},
+		{in: 1234567890 * time.Nanosecond, want: 1230 * time.Millisecond},
+		{in: 61500 * time.Millisecond, want: 62 * time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in.String(), func(t *testing.T) {
+			if got := roundElapsed(tt.in); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -7,34 +7,138 @@ import (
 
 // Style holds style configuration for logging output.
 type Style struct {
-	Level  map[slog.Level]LevelStyle
-	Label  LabelStyle
-	Attr   AttrStyle
-	Caller CallerStyle
+	Level     map[slog.Level]LevelStyle
+	Message   MessageStyle
+	Label     LabelStyle
+	Attr      AttrStyle
+	Caller    CallerStyle
+	Time      TimeStyle
+	Stack     StackStyle
+	Multiline MultilineStyle
+	Step      StepStyle
+	Section   SectionStyle
 }
 
 // LevelStyle config for a log level.
+// A non-empty Icon is written before the text, separated by a space, or instead
+// of it if Text is empty. Width applies to both and counts the display width of
+// wide characters such as emoji. Narrower text is placed as Align selects, and
+// text wider than Width is cut as Truncate selects.
 type LevelStyle struct {
-	Prefix AffixStyle
-	Suffix AffixStyle
-	Text   string
+	Prefix   AffixStyle
+	Suffix   AffixStyle
+	Icon     string
+	Text     string
+	Color    *Color
+	Width    int
+	Align    Alignment
+	Truncate Truncation
+}
+
+// MessageStyle config for the record message.
+// The message is written in the color of the nearest level at or below the record
+// level in Levels, or in Color if there is none.
+type MessageStyle struct {
 	Color  *Color
-	Width  int
+	Levels map[slog.Level]*Color
 }
 
-// LabelStyle config for the prefix.
+// LabelStyle config for the label.
+// A label narrower than Width is placed as Align selects, and a wider label is
+// cut as Truncate selects.
 type LabelStyle struct {
-	Prefix AffixStyle
-	Suffix AffixStyle
-	Color  *Color
-	Width  int
+	Prefix   AffixStyle
+	Suffix   AffixStyle
+	Color    *Color
+	Width    int
+	Align    Alignment
+	Truncate Truncation
 }
 
+// Alignment selects where text narrower than the Width of its style is placed.
+type Alignment int
+
+const (
+	// AlignCenter centers the text, with the extra space on the right.
+	AlignCenter Alignment = iota
+
+	// AlignLeft writes the text at the left of the field.
+	AlignLeft
+
+	// AlignRight writes the text at the right of the field.
+	AlignRight
+)
+
+// Truncation selects how text wider than the Width of its style is cut.
+type Truncation int
+
+const (
+	// TruncateNone writes the text in full, overflowing the width.
+	TruncateNone Truncation = iota
+
+	// TruncateRight cuts the end of the text and marks the cut with an ellipsis.
+	TruncateRight
+
+	// TruncateMiddle cuts the middle of the text and marks the cut with an
+	// ellipsis, keeping both ends.
+	TruncateMiddle
+)
+
 // AttrStyle config for attributes.
+// When GroupPrefix is set, groups are written as key{k1=v1 k2=2} with the prefix
+// and suffix around their members, separated by GroupSeparator or a space if it is
+// empty; otherwise each member is written with a dotted key, e.g. key.k1=v1.
+// A positive Width pads every key to that many columns before the separator, so
+// that the values of the keys line up across lines, and AttrWidthAuto pads every
+// key to the widest key written so far.
+// Byte slices are written as Bytes selects, durations as Duration selects,
+// integers and floats by NumberFormatter if it is set, and nil values as <nil> in
+// NilColor.
 type AttrStyle struct {
+	KeyColor        *Color
+	ValueColor      *Color
+	ErrorColor      *Color
+	TraceColor      *Color
+	NilColor        *Color
+	Bytes           BytesFormat
+	Duration        DurationFormat
+	NumberFormatter func(v slog.Value) string
+	Keys            map[string]AttrKeyStyle
+	Colorizer       func(key string, v slog.Value) *Color
+	Separator       string
+	GroupPrefix     string
+	GroupSuffix     string
+	GroupSeparator  string
+	Width           int
+}
+
+// AttrWidthAuto is the AttrStyle.Width that pads every key to the widest key
+// written so far by the handler.
+const AttrWidthAuto = -1
+
+// BytesFormat selects how byte slices in attribute values are written.
+type BytesFormat int
+
+const (
+	// BytesText writes byte slices as text, quoted if needed, e.g. "a\x00b".
+	BytesText BytesFormat = iota
+
+	// BytesHex writes byte slices in hexadecimal, e.g. 610062.
+	BytesHex
+
+	// BytesBase64 writes byte slices in standard base64, e.g. YQBi.
+	BytesBase64
+
+	// BytesLength writes the length of byte slices only, e.g. <3B>.
+	BytesLength
+)
+
+// AttrKeyStyle config for attributes with a specific key.
+// Nil colors fall back to the AttrStyle colors, and a nil Format renders the value as usual.
+type AttrKeyStyle struct {
 	KeyColor   *Color
 	ValueColor *Color
-	Separator  string
+	Format     func(v slog.Value) string
 }
 
 // CallerStyle config for caller source.
@@ -43,6 +147,67 @@ type CallerStyle struct {
 	Suffix   AffixStyle
 	Color    *Color
 	Fullpath bool
+	Format   CallerFormat
+}
+
+// CallerFormat selects what the caller source shows.
+type CallerFormat int
+
+const (
+	// CallerFileLine shows the file and line, e.g. handler.go:42.
+	CallerFileLine CallerFormat = iota
+
+	// CallerFile shows the file only, e.g. handler.go.
+	CallerFile
+
+	// CallerFuncName shows the function and line, e.g. (*Store).Get:42.
+	CallerFuncName
+
+	// CallerPackageFunc shows the package path, function and line, e.g.
+	// github.com/myorg/svc/db.(*Store).Get:42.
+	CallerPackageFunc
+)
+
+// TimeStyle config for the record time.
+type TimeStyle struct {
+	Prefix AffixStyle
+	Suffix AffixStyle
+	Color  *Color
+}
+
+// StackStyle config for stack traces.
+type StackStyle struct {
+	Indent string
+	Color  *Color
+}
+
+// MultilineStyle config for multiline output.
+type MultilineStyle struct {
+	Indent string
+	Marker AffixStyle
+}
+
+// StepStyle config for the records of Logger.Step.
+// The mark of the step status is written before the message.
+type StepStyle struct {
+	Running AffixStyle
+	Success AffixStyle
+	Failed  AffixStyle
+	Skipped AffixStyle
+}
+
+// SectionStyle config for the dividers written by Logger.Section and the boxes
+// written by Logger.Banner. Line is repeated to draw dividers and the top and
+// bottom of boxes, Side draws the sides of boxes and Corners their corners,
+// clockwise from the top left. Dividers span the line width of the handler, or
+// Width if lines are not wrapped; boxes span the line width or fit the message.
+type SectionStyle struct {
+	Line       string
+	Side       string
+	Corners    [4]string
+	Color      *Color
+	TitleColor *Color
+	Width      int
 }
 
 // AffixStyle config for text affixes.
@@ -63,7 +228,9 @@ func NewStyle(opts ...StyleOption) *Style {
 // StyleOption defines a function type for configuring a Style.
 type StyleOption func(*Style)
 
-// WithLevelStyle returns a StyleOption that sets the logging level.
+// WithLevelStyle returns a StyleOption that sets the styles of the given levels.
+// Arbitrary levels can be registered; records at levels without their own style
+// use the style of the nearest defined level below them.
 func WithLevelStyle(levels map[slog.Level]LevelStyle) StyleOption {
 	return func(s *Style) {
 		if s.Level == nil {
@@ -73,6 +240,13 @@ func WithLevelStyle(levels map[slog.Level]LevelStyle) StyleOption {
 	}
 }
 
+// WithMessageStyle returns a StyleOption that sets the message style.
+func WithMessageStyle(message MessageStyle) StyleOption {
+	return func(s *Style) {
+		s.Message = message
+	}
+}
+
 // WithLabelStyle returns a StyleOption that sets the label style.
 func WithLabelStyle(label LabelStyle) StyleOption {
 	return func(s *Style) {
@@ -87,6 +261,96 @@ func WithAttrStyle(attr AttrStyle) StyleOption {
 	}
 }
 
+// WithAttrKeyStyle returns a StyleOption that sets the styles of the given attribute keys.
+func WithAttrKeyStyle(keys map[string]AttrKeyStyle) StyleOption {
+	return func(s *Style) {
+		if s.Attr.Keys == nil {
+			s.Attr.Keys = make(map[string]AttrKeyStyle)
+		}
+		maps.Copy(s.Attr.Keys, keys)
+	}
+}
+
+// WithValueColorizer returns a StyleOption that sets a hook choosing the value color
+// from the attribute key and value. A non-nil result takes precedence over all other
+// value colors, and a nil result keeps them.
+func WithValueColorizer(fn func(key string, v slog.Value) *Color) StyleOption {
+	return func(s *Style) {
+		s.Attr.Colorizer = fn
+	}
+}
+
+// WithGroupDelimiters returns a StyleOption that writes groups between prefix
+// and suffix, with members separated by separator, e.g. "{", "}" and " " for
+// group{k1=v1 k2=2}. An empty prefix restores dotted keys.
+func WithGroupDelimiters(prefix, suffix, separator string) StyleOption {
+	return func(s *Style) {
+		s.Attr.GroupPrefix = prefix
+		s.Attr.GroupSuffix = suffix
+		s.Attr.GroupSeparator = separator
+	}
+}
+
+// WithBytesFormat returns a StyleOption that sets how byte slices in attribute
+// values are written. The default is BytesText.
+func WithBytesFormat(format BytesFormat) StyleOption {
+	return func(s *Style) {
+		s.Attr.Bytes = format
+	}
+}
+
+// WithNilColor returns a StyleOption that sets the color of nil attribute
+// values, which are written as <nil>.
+func WithNilColor(c *Color) StyleOption {
+	return func(s *Style) {
+		s.Attr.NilColor = c
+	}
+}
+
+// WithAttrWidth returns a StyleOption that pads every key to width columns before
+// the separator, or to the widest key so far with AttrWidthAuto. A zero width
+// disables the padding.
+func WithAttrWidth(width int) StyleOption {
+	return func(s *Style) {
+		s.Attr.Width = max(width, AttrWidthAuto)
+	}
+}
+
+// WithTimeStyle returns a StyleOption that sets the time style.
+func WithTimeStyle(t TimeStyle) StyleOption {
+	return func(s *Style) {
+		s.Time = t
+	}
+}
+
+// WithStackStyle returns a StyleOption that sets the stack trace style.
+func WithStackStyle(stack StackStyle) StyleOption {
+	return func(s *Style) {
+		s.Stack = stack
+	}
+}
+
+// WithMultilineStyle returns a StyleOption that sets the multiline style.
+func WithMultilineStyle(multiline MultilineStyle) StyleOption {
+	return func(s *Style) {
+		s.Multiline = multiline
+	}
+}
+
+// WithStepStyle returns a StyleOption that sets the step style.
+func WithStepStyle(step StepStyle) StyleOption {
+	return func(s *Style) {
+		s.Step = step
+	}
+}
+
+// WithSectionStyle returns a StyleOption that sets the section style.
+func WithSectionStyle(section SectionStyle) StyleOption {
+	return func(s *Style) {
+		s.Section = section
+	}
+}
+
 // WithCallerStyle returns a StyleOption that sets the caller style.
 func WithCallerStyle(caller CallerStyle) StyleOption {
 	return func(s *Style) {
@@ -98,18 +362,27 @@ func WithCallerStyle(caller CallerStyle) StyleOption {
 func Style0() *Style {
 	return &Style{
 		Level: map[slog.Level]LevelStyle{
+			LevelTrace: {
+				Text: "[TRC]",
+			},
 			slog.LevelDebug: {
 				Text: "[DBG]",
 			},
 			slog.LevelInfo: {
 				Text: "[INF]",
 			},
+			LevelNotice: {
+				Text: "[NTC]",
+			},
 			slog.LevelWarn: {
 				Text: "[WRN]",
 			},
 			slog.LevelError: {
 				Text: "[ERR]",
 			},
+			LevelFatal: {
+				Text: "[FTL]",
+			},
 		},
 		Attr: AttrStyle{
 			Separator: "=",
@@ -122,6 +395,34 @@ func Style0() *Style {
 				Text: ">",
 			},
 		},
+		Stack: StackStyle{
+			Indent: "  ",
+		},
+		Multiline: MultilineStyle{
+			Indent: "  ",
+			Marker: AffixStyle{
+				Text: "│",
+			},
+		},
+		Step: StepStyle{
+			Running: AffixStyle{
+				Text: "•",
+			},
+			Success: AffixStyle{
+				Text: "✓",
+			},
+			Failed: AffixStyle{
+				Text: "✗",
+			},
+			Skipped: AffixStyle{
+				Text: "○",
+			},
+		},
+		Section: SectionStyle{
+			Line:    "-",
+			Side:    "|",
+			Corners: [4]string{"+", "+", "+", "+"},
+		},
 	}
 }
 
@@ -129,6 +430,10 @@ func Style0() *Style {
 func Style1() *Style {
 	return &Style{
 		Level: map[slog.Level]LevelStyle{
+			LevelTrace: {
+				Text:  "TRC",
+				Color: NewColor(Bold, FgHiBlack),
+			},
 			slog.LevelDebug: {
 				Text:  "DBG",
 				Color: NewColor(Bold, FgHiMagenta),
@@ -137,6 +442,10 @@ func Style1() *Style {
 				Text:  "INF",
 				Color: NewColor(Bold, FgHiGreen),
 			},
+			LevelNotice: {
+				Text:  "NTC",
+				Color: NewColor(Bold, FgHiCyan),
+			},
 			slog.LevelWarn: {
 				Text:  "WRN",
 				Color: NewColor(Bold, FgHiYellow),
@@ -145,12 +454,22 @@ func Style1() *Style {
 				Text:  "ERR",
 				Color: NewColor(Bold, FgHiRed),
 			},
+			LevelFatal: {
+				Text:  "FTL",
+				Color: NewColor(Bold, FgHiWhite, BgRed),
+			},
 		},
 		Label: LabelStyle{
 			Color: NewColor(FgHiBlack, Bold),
 		},
 		Attr: AttrStyle{
-			KeyColor:  NewColor(FgHiBlack),
+			KeyColor:   NewColor(FgHiBlack),
+			ErrorColor: NewColor(FgHiRed),
+			NilColor:   NewColor(Faint),
+			Keys: map[string]AttrKeyStyle{
+				"err":   {KeyColor: NewColor(FgHiRed), ValueColor: NewColor(FgHiRed)},
+				"error": {KeyColor: NewColor(FgHiRed), ValueColor: NewColor(FgHiRed)},
+			},
 			Separator: "=",
 		},
 		Caller: CallerStyle{
@@ -164,6 +483,42 @@ func Style1() *Style {
 			},
 			Color: NewColor(FgHiBlack, Underline),
 		},
+		Stack: StackStyle{
+			Indent: "  ",
+			Color:  NewColor(Faint),
+		},
+		Multiline: MultilineStyle{
+			Indent: "  ",
+			Marker: AffixStyle{
+				Text:  "│",
+				Color: NewColor(FgHiBlack),
+			},
+		},
+		Step: StepStyle{
+			Running: AffixStyle{
+				Text:  "•",
+				Color: NewColor(FgHiCyan),
+			},
+			Success: AffixStyle{
+				Text:  "✓",
+				Color: NewColor(Bold, FgHiGreen),
+			},
+			Failed: AffixStyle{
+				Text:  "✗",
+				Color: NewColor(Bold, FgHiRed),
+			},
+			Skipped: AffixStyle{
+				Text:  "○",
+				Color: NewColor(FgHiBlack),
+			},
+		},
+		Section: SectionStyle{
+			Line:       "─",
+			Side:       "│",
+			Corners:    [4]string{"╭", "╮", "╯", "╰"},
+			Color:      NewColor(FgHiBlack),
+			TitleColor: NewColor(Bold),
+		},
 	}
 }
 
@@ -171,28 +526,46 @@ func Style1() *Style {
 func Style2() *Style {
 	return &Style{
 		Level: map[slog.Level]LevelStyle{
+			LevelTrace: {
+				Text:  "TRC",
+				Color: NewColorRGB(138, 138, 138, Bold),
+			},
 			slog.LevelDebug: {
 				Text:  "DBG",
-				Color: NewColor(38, 2, 95, 95, 255, Bold),
+				Color: NewColorRGB(95, 95, 255, Bold),
 			},
 			slog.LevelInfo: {
 				Text:  "INF",
-				Color: NewColor(38, 2, 95, 255, 215, Bold),
+				Color: NewColorRGB(95, 255, 215, Bold),
+			},
+			LevelNotice: {
+				Text:  "NTC",
+				Color: NewColorRGB(95, 215, 255, Bold),
 			},
 			slog.LevelWarn: {
 				Text:  "WRN",
-				Color: NewColor(38, 2, 215, 255, 135, Bold),
+				Color: NewColorRGB(215, 255, 135, Bold),
 			},
 			slog.LevelError: {
 				Text:  "ERR",
-				Color: NewColor(38, 2, 255, 95, 135, Bold),
+				Color: NewColorRGB(255, 95, 135, Bold),
+			},
+			LevelFatal: {
+				Text:  "FTL",
+				Color: NewBgColorRGB(255, 0, 95, Bold),
 			},
 		},
 		Label: LabelStyle{
 			Color: NewColor(FgHiBlack, Bold),
 		},
 		Attr: AttrStyle{
-			KeyColor:  NewColor(FgHiBlack),
+			KeyColor:   NewColor(FgHiBlack),
+			ErrorColor: NewColorRGB(255, 95, 135),
+			NilColor:   NewColor(Faint),
+			Keys: map[string]AttrKeyStyle{
+				"err":   {KeyColor: NewColorRGB(255, 95, 135), ValueColor: NewColorRGB(255, 95, 135)},
+				"error": {KeyColor: NewColorRGB(255, 95, 135), ValueColor: NewColorRGB(255, 95, 135)},
+			},
 			Separator: "=",
 		},
 		Caller: CallerStyle{
@@ -206,6 +579,42 @@ func Style2() *Style {
 			},
 			Color: NewColor(FgHiBlack, Underline),
 		},
+		Stack: StackStyle{
+			Indent: "  ",
+			Color:  NewColor(Faint),
+		},
+		Multiline: MultilineStyle{
+			Indent: "  ",
+			Marker: AffixStyle{
+				Text:  "│",
+				Color: NewColor(FgHiBlack),
+			},
+		},
+		Step: StepStyle{
+			Running: AffixStyle{
+				Text:  "•",
+				Color: NewColorRGB(95, 95, 255),
+			},
+			Success: AffixStyle{
+				Text:  "✓",
+				Color: NewColorRGB(95, 255, 215, Bold),
+			},
+			Failed: AffixStyle{
+				Text:  "✗",
+				Color: NewColorRGB(255, 95, 135, Bold),
+			},
+			Skipped: AffixStyle{
+				Text:  "○",
+				Color: NewColor(FgHiBlack),
+			},
+		},
+		Section: SectionStyle{
+			Line:       "─",
+			Side:       "│",
+			Corners:    [4]string{"╭", "╮", "╯", "╰"},
+			Color:      NewColorRGB(95, 95, 255),
+			TitleColor: NewColor(Bold),
+		},
 	}
 }
 
@@ -213,6 +622,11 @@ func Style2() *Style {
 func Style3() *Style {
 	return &Style{
 		Level: map[slog.Level]LevelStyle{
+			LevelTrace: {
+				Text:  "TRC",
+				Color: NewColor(Bold, BgHiBlack),
+				Width: 5,
+			},
 			slog.LevelDebug: {
 				Text:  "DBG",
 				Color: NewColor(Bold, BgMagenta),
@@ -223,6 +637,11 @@ func Style3() *Style {
 				Color: NewColor(Bold, BgGreen),
 				Width: 5,
 			},
+			LevelNotice: {
+				Text:  "NTC",
+				Color: NewColor(Bold, BgCyan),
+				Width: 5,
+			},
 			slog.LevelWarn: {
 				Text:  "WRN",
 				Color: NewColor(Bold, BgYellow),
@@ -233,12 +652,23 @@ func Style3() *Style {
 				Color: NewColor(Bold, BgRed),
 				Width: 5,
 			},
+			LevelFatal: {
+				Text:  "FTL",
+				Color: NewColor(Bold, BgHiRed),
+				Width: 5,
+			},
 		},
 		Label: LabelStyle{
 			Color: NewColor(FgHiBlack, Bold),
 		},
 		Attr: AttrStyle{
-			KeyColor:  NewColor(FgHiBlack),
+			KeyColor:   NewColor(FgHiBlack),
+			ErrorColor: NewColor(FgHiRed),
+			NilColor:   NewColor(Faint),
+			Keys: map[string]AttrKeyStyle{
+				"err":   {KeyColor: NewColor(FgHiRed), ValueColor: NewColor(FgHiRed)},
+				"error": {KeyColor: NewColor(FgHiRed), ValueColor: NewColor(FgHiRed)},
+			},
 			Separator: "=",
 		},
 		Caller: CallerStyle{
@@ -252,6 +682,42 @@ func Style3() *Style {
 			},
 			Color: NewColor(FgHiBlack, Underline),
 		},
+		Stack: StackStyle{
+			Indent: "  ",
+			Color:  NewColor(Faint),
+		},
+		Multiline: MultilineStyle{
+			Indent: "  ",
+			Marker: AffixStyle{
+				Text:  "│",
+				Color: NewColor(FgHiBlack),
+			},
+		},
+		Step: StepStyle{
+			Running: AffixStyle{
+				Text:  "•",
+				Color: NewColor(FgHiCyan),
+			},
+			Success: AffixStyle{
+				Text:  "✓",
+				Color: NewColor(Bold, FgHiGreen),
+			},
+			Failed: AffixStyle{
+				Text:  "✗",
+				Color: NewColor(Bold, FgHiRed),
+			},
+			Skipped: AffixStyle{
+				Text:  "○",
+				Color: NewColor(FgHiBlack),
+			},
+		},
+		Section: SectionStyle{
+			Line:       "─",
+			Side:       "│",
+			Corners:    [4]string{"╭", "╮", "╯", "╰"},
+			Color:      NewColor(FgHiBlack),
+			TitleColor: NewColor(Bold),
+		},
 	}
 }
 
@@ -259,24 +725,39 @@ func Style3() *Style {
 func Style4() *Style {
 	return &Style{
 		Level: map[slog.Level]LevelStyle{
+			LevelTrace: {
+				Text:  "TRC",
+				Color: NewBgColorRGB(138, 138, 138, Bold),
+				Width: 5,
+			},
 			slog.LevelDebug: {
 				Text:  "DBG",
-				Color: NewColor(48, 2, 95, 95, 255, Bold),
+				Color: NewBgColorRGB(95, 95, 255, Bold),
 				Width: 5,
 			},
 			slog.LevelInfo: {
 				Text:  "INF",
-				Color: NewColor(48, 2, 95, 255, 215, Bold),
+				Color: NewBgColorRGB(95, 255, 215, Bold),
+				Width: 5,
+			},
+			LevelNotice: {
+				Text:  "NTC",
+				Color: NewBgColorRGB(95, 215, 255, Bold),
 				Width: 5,
 			},
 			slog.LevelWarn: {
 				Text:  "WRN",
-				Color: NewColor(48, 2, 215, 255, 135, Bold),
+				Color: NewBgColorRGB(215, 255, 135, Bold),
 				Width: 5,
 			},
 			slog.LevelError: {
 				Text:  "ERR",
-				Color: NewColor(48, 2, 255, 95, 135, Bold),
+				Color: NewBgColorRGB(255, 95, 135, Bold),
+				Width: 5,
+			},
+			LevelFatal: {
+				Text:  "FTL",
+				Color: NewBgColorRGB(255, 0, 95, Bold),
 				Width: 5,
 			},
 		},
@@ -284,7 +765,13 @@ func Style4() *Style {
 			Color: NewColor(FgHiBlack, Bold),
 		},
 		Attr: AttrStyle{
-			KeyColor:  NewColor(FgHiBlack),
+			KeyColor:   NewColor(FgHiBlack),
+			ErrorColor: NewColorRGB(255, 95, 135),
+			NilColor:   NewColor(Faint),
+			Keys: map[string]AttrKeyStyle{
+				"err":   {KeyColor: NewColorRGB(255, 95, 135), ValueColor: NewColorRGB(255, 95, 135)},
+				"error": {KeyColor: NewColorRGB(255, 95, 135), ValueColor: NewColorRGB(255, 95, 135)},
+			},
 			Separator: "=",
 		},
 		Caller: CallerStyle{
@@ -298,6 +785,138 @@ func Style4() *Style {
 			},
 			Color: NewColor(FgHiBlack, Underline),
 		},
+		Stack: StackStyle{
+			Indent: "  ",
+			Color:  NewColor(Faint),
+		},
+		Multiline: MultilineStyle{
+			Indent: "  ",
+			Marker: AffixStyle{
+				Text:  "│",
+				Color: NewColor(FgHiBlack),
+			},
+		},
+		Step: StepStyle{
+			Running: AffixStyle{
+				Text:  "•",
+				Color: NewColorRGB(95, 95, 255),
+			},
+			Success: AffixStyle{
+				Text:  "✓",
+				Color: NewColorRGB(95, 255, 215, Bold),
+			},
+			Failed: AffixStyle{
+				Text:  "✗",
+				Color: NewColorRGB(255, 95, 135, Bold),
+			},
+			Skipped: AffixStyle{
+				Text:  "○",
+				Color: NewColor(FgHiBlack),
+			},
+		},
+		Section: SectionStyle{
+			Line:       "─",
+			Side:       "│",
+			Corners:    [4]string{"╭", "╮", "╯", "╰"},
+			Color:      NewColorRGB(95, 95, 255),
+			TitleColor: NewColor(Bold),
+		},
+	}
+}
+
+// Style5 returns a logging style with colored icons in place of the level texts.
+func Style5() *Style {
+	return &Style{
+		Level: map[slog.Level]LevelStyle{
+			LevelTrace: {
+				Icon:  "·",
+				Color: NewColorRGB(138, 138, 138, Bold),
+			},
+			slog.LevelDebug: {
+				Icon:  "●",
+				Color: NewColorRGB(95, 95, 255, Bold),
+			},
+			slog.LevelInfo: {
+				Icon:  "ℹ",
+				Color: NewColorRGB(95, 255, 215, Bold),
+			},
+			LevelNotice: {
+				Icon:  "◆",
+				Color: NewColorRGB(95, 215, 255, Bold),
+			},
+			slog.LevelWarn: {
+				Icon:  "⚠",
+				Color: NewColorRGB(215, 255, 135, Bold),
+			},
+			slog.LevelError: {
+				Icon:  "✖",
+				Color: NewColorRGB(255, 95, 135, Bold),
+			},
+			LevelFatal: {
+				Icon:  "✘",
+				Color: NewBgColorRGB(255, 0, 95, Bold),
+			},
+		},
+		Label: LabelStyle{
+			Color: NewColor(FgHiBlack, Bold),
+		},
+		Attr: AttrStyle{
+			KeyColor:   NewColor(FgHiBlack),
+			ErrorColor: NewColorRGB(255, 95, 135),
+			NilColor:   NewColor(Faint),
+			Keys: map[string]AttrKeyStyle{
+				"err":   {KeyColor: NewColorRGB(255, 95, 135), ValueColor: NewColorRGB(255, 95, 135)},
+				"error": {KeyColor: NewColorRGB(255, 95, 135), ValueColor: NewColorRGB(255, 95, 135)},
+			},
+			Separator: "=",
+		},
+		Caller: CallerStyle{
+			Prefix: AffixStyle{
+				Text:  "<",
+				Color: NewColor(FgHiBlack),
+			},
+			Suffix: AffixStyle{
+				Text:  ">",
+				Color: NewColor(FgHiBlack),
+			},
+			Color: NewColor(FgHiBlack, Underline),
+		},
+		Stack: StackStyle{
+			Indent: "  ",
+			Color:  NewColor(Faint),
+		},
+		Multiline: MultilineStyle{
+			Indent: "  ",
+			Marker: AffixStyle{
+				Text:  "│",
+				Color: NewColor(FgHiBlack),
+			},
+		},
+		Step: StepStyle{
+			Running: AffixStyle{
+				Text:  "•",
+				Color: NewColorRGB(95, 95, 255),
+			},
+			Success: AffixStyle{
+				Text:  "✓",
+				Color: NewColorRGB(95, 255, 215, Bold),
+			},
+			Failed: AffixStyle{
+				Text:  "✗",
+				Color: NewColorRGB(255, 95, 135, Bold),
+			},
+			Skipped: AffixStyle{
+				Text:  "○",
+				Color: NewColor(FgHiBlack),
+			},
+		},
+		Section: SectionStyle{
+			Line:       "─",
+			Side:       "│",
+			Corners:    [4]string{"╭", "╮", "╯", "╰"},
+			Color:      NewColorRGB(95, 95, 255),
+			TitleColor: NewColor(Bold),
+		},
 	}
 }
 
@@ -311,5 +930,133 @@ func (s *Style) Clone() *Style {
 		n.Level = make(map[slog.Level]LevelStyle, len(s.Level))
 		maps.Copy(n.Level, s.Level)
 	}
+	if s.Message.Levels != nil {
+		n.Message.Levels = maps.Clone(s.Message.Levels)
+	}
+	if s.Attr.Keys != nil {
+		n.Attr.Keys = make(map[string]AttrKeyStyle, len(s.Attr.Keys))
+		maps.Copy(n.Attr.Keys, s.Attr.Keys)
+	}
 	return &n
 }
+
+// label returns the icon and text of the level as written by the handler.
+func (ls LevelStyle) label() string {
+	switch {
+	case ls.Icon == "":
+		return ls.Text
+	case ls.Text == "":
+		return ls.Icon
+	default:
+		return ls.Icon + " " + ls.Text
+	}
+}
+
+// levelStyle returns the LevelStyle for the given level. Levels without their
+// own style fall back to the nearest defined level below them, or to the lowest
+// defined level if there is none below.
+func (s *Style) levelStyle(level slog.Level) LevelStyle {
+	if ls, ok := s.Level[level]; ok {
+		return ls
+	}
+	var (
+		floor, lowest       slog.Level
+		hasFloor, hasLowest bool
+	)
+	for l := range s.Level {
+		if l < level && (!hasFloor || l > floor) {
+			floor, hasFloor = l, true
+		}
+		if !hasLowest || l < lowest {
+			lowest, hasLowest = l, true
+		}
+	}
+	if hasFloor {
+		return s.Level[floor]
+	}
+	return s.Level[lowest]
+}
+
+// messageColor returns the color of the message at the given level.
+func (s *Style) messageColor(level slog.Level) *Color {
+	if c, ok := s.Message.Levels[level]; ok {
+		return c
+	}
+	var (
+		floor    slog.Level
+		hasFloor bool
+	)
+	for l := range s.Message.Levels {
+		if l < level && (!hasFloor || l > floor) {
+			floor, hasFloor = l, true
+		}
+	}
+	if hasFloor {
+		return s.Message.Levels[floor]
+	}
+	return s.Message.Color
+}
+
+// stepMark returns the mark of the step status.
+func (s *Style) stepMark(status StepStatus) AffixStyle {
+	switch status {
+	case StepSuccess:
+		return s.Step.Success
+	case StepFailed:
+		return s.Step.Failed
+	case StepSkipped:
+		return s.Step.Skipped
+	}
+	return s.Step.Running
+}
+
+// mapColors returns a copy of the Style with fn applied to every Color.
+func (s *Style) mapColors(fn func(*Color) *Color) *Style {
+	n := s.Clone()
+	if n == nil {
+		return nil
+	}
+	for k, ls := range n.Level {
+		ls.Prefix.Color = fn(ls.Prefix.Color)
+		ls.Suffix.Color = fn(ls.Suffix.Color)
+		ls.Color = fn(ls.Color)
+		n.Level[k] = ls
+	}
+	n.Message.Color = fn(n.Message.Color)
+	for k, c := range n.Message.Levels {
+		n.Message.Levels[k] = fn(c)
+	}
+	n.Label.Prefix.Color = fn(n.Label.Prefix.Color)
+	n.Label.Suffix.Color = fn(n.Label.Suffix.Color)
+	n.Label.Color = fn(n.Label.Color)
+	n.Attr.KeyColor = fn(n.Attr.KeyColor)
+	n.Attr.ValueColor = fn(n.Attr.ValueColor)
+	n.Attr.ErrorColor = fn(n.Attr.ErrorColor)
+	n.Attr.TraceColor = fn(n.Attr.TraceColor)
+	n.Attr.NilColor = fn(n.Attr.NilColor)
+	for k, ks := range n.Attr.Keys {
+		ks.KeyColor = fn(ks.KeyColor)
+		ks.ValueColor = fn(ks.ValueColor)
+		n.Attr.Keys[k] = ks
+	}
+	if colorize := n.Attr.Colorizer; colorize != nil {
+		n.Attr.Colorizer = func(key string, v slog.Value) *Color {
+			return fn(colorize(key, v))
+		}
+	}
+	n.Caller.Prefix.Color = fn(n.Caller.Prefix.Color)
+	n.Caller.Suffix.Color = fn(n.Caller.Suffix.Color)
+	n.Caller.Color = fn(n.Caller.Color)
+	n.Time.Prefix.Color = fn(n.Time.Prefix.Color)
+	n.Time.Suffix.Color = fn(n.Time.Suffix.Color)
+	n.Time.Color = fn(n.Time.Color)
+	n.Stack.Color = fn(n.Stack.Color)
+	n.Multiline.Marker.Color = fn(n.Multiline.Marker.Color)
+	n.Step.Running.Color = fn(n.Step.Running.Color)
+	n.Step.Success.Color = fn(n.Step.Success.Color)
+	n.Step.Failed.Color = fn(n.Step.Failed.Color)
+	n.Step.Skipped.Color = fn(n.Step.Skipped.Color)
+	n.Section.Color = fn(n.Section.Color)
+	n.Section.TitleColor = fn(n.Section.TitleColor)
+	return n
+}
@@ -4,6 +4,7 @@ import (
 	"log/slog"
 	"reflect"
 	"testing"
+	"time"
 )
 
 func TestNewStyle(t *testing.T) {
@@ -292,6 +293,85 @@ func TestWithAttrStyle(t *testing.T) {
 	}
 }
 
+func TestWithAttrKeyStyle(t *testing.T) {
+	tests := []struct {
+		name  string
+		style *Style
+		keys  map[string]AttrKeyStyle
+		check func(*testing.T, *Style)
+	}{
+		{
+			name:  "set on style without keys",
+			style: Style0(),
+			keys:  map[string]AttrKeyStyle{"status": {ValueColor: NewColor(FgGreen)}},
+			check: func(t *testing.T, s *Style) {
+				if len(s.Attr.Keys) != 1 {
+					t.Errorf("want 1 key, got %d", len(s.Attr.Keys))
+				}
+				if !reflect.DeepEqual(s.Attr.Keys["status"].ValueColor, NewColor(FgGreen)) {
+					t.Errorf("want ValueColor %+v, got %+v", NewColor(FgGreen), s.Attr.Keys["status"].ValueColor)
+				}
+			},
+		},
+		{
+			name:  "merge with existing keys",
+			style: Style1(),
+			keys:  map[string]AttrKeyStyle{"err": {ValueColor: NewColor(FgYellow)}},
+			check: func(t *testing.T, s *Style) {
+				if len(s.Attr.Keys) != 2 {
+					t.Errorf("want 2 keys, got %d", len(s.Attr.Keys))
+				}
+				if !reflect.DeepEqual(s.Attr.Keys["err"].ValueColor, NewColor(FgYellow)) {
+					t.Errorf("want ValueColor %+v, got %+v", NewColor(FgYellow), s.Attr.Keys["err"].ValueColor)
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			WithAttrKeyStyle(tt.keys)(tt.style)
+			tt.check(t, tt.style)
+		})
+	}
+}
+
+func TestWithValueColorizer(t *testing.T) {
+	s := Style0()
+	WithValueColorizer(func(key string, v slog.Value) *Color {
+		if v.Duration() > time.Second {
+			return NewColor(FgYellow)
+		}
+		return nil
+	})(s)
+	if s.Attr.Colorizer == nil {
+		t.Fatal("Colorizer not set")
+	}
+	if got := s.Attr.Colorizer("elapsed", slog.DurationValue(2*time.Second)); !reflect.DeepEqual(got, NewColor(FgYellow)) {
+		t.Errorf("Colorizer() = %+v, want %+v", got, NewColor(FgYellow))
+	}
+	if got := s.Attr.Colorizer("elapsed", slog.DurationValue(time.Millisecond)); got != nil {
+		t.Errorf("Colorizer() = %+v, want nil", got)
+	}
+}
+
+func TestWithMultilineStyle(t *testing.T) {
+	ms := MultilineStyle{Indent: "    ", Marker: AffixStyle{Text: "|", Color: NewColor(FgBlue)}}
+	s := Style0()
+	WithMultilineStyle(ms)(s)
+	if !reflect.DeepEqual(s.Multiline, ms) {
+		t.Errorf("want Multiline %+v, got %+v", ms, s.Multiline)
+	}
+}
+
+func TestWithTimeStyle(t *testing.T) {
+	ts := TimeStyle{Prefix: AffixStyle{Text: "["}, Suffix: AffixStyle{Text: "]"}, Color: NewColor(FgCyan)}
+	s := Style0()
+	WithTimeStyle(ts)(s)
+	if !reflect.DeepEqual(s.Time, ts) {
+		t.Errorf("want Time %+v, got %+v", ts, s.Time)
+	}
+}
+
 func TestWithCallerStyle(t *testing.T) {
 	tests := []struct {
 		name   string
@@ -350,10 +430,13 @@ func TestStyles(t *testing.T) {
 	t.Run("Style0", func(t *testing.T) {
 		want := &Style{
 			Level: map[slog.Level]LevelStyle{
+				LevelTrace:      {Text: "[TRC]", Color: nil},
 				slog.LevelDebug: {Text: "[DBG]", Color: nil},
 				slog.LevelInfo:  {Text: "[INF]", Color: nil},
+				LevelNotice:     {Text: "[NTC]", Color: nil},
 				slog.LevelWarn:  {Text: "[WRN]", Color: nil},
 				slog.LevelError: {Text: "[ERR]", Color: nil},
+				LevelFatal:      {Text: "[FTL]", Color: nil},
 			},
 			Label: LabelStyle{
 				Color: nil,
@@ -369,22 +452,49 @@ func TestStyles(t *testing.T) {
 				Color:    nil,
 				Fullpath: false,
 			},
+			Stack: StackStyle{
+				Indent: "  ",
+			},
+			Multiline: MultilineStyle{
+				Indent: "  ",
+				Marker: AffixStyle{Text: "│"},
+			},
+			Step: StepStyle{
+				Running: AffixStyle{Text: "•"},
+				Success: AffixStyle{Text: "✓"},
+				Failed:  AffixStyle{Text: "✗"},
+				Skipped: AffixStyle{Text: "○"},
+			},
+			Section: SectionStyle{
+				Line:    "-",
+				Side:    "|",
+				Corners: [4]string{"+", "+", "+", "+"},
+			},
 		}
 		check(t, Style0(), want)
 	})
 	t.Run("Style1", func(t *testing.T) {
 		want := &Style{
 			Level: map[slog.Level]LevelStyle{
+				LevelTrace:      {Text: "TRC", Color: NewColor(Bold, FgHiBlack)},
 				slog.LevelDebug: {Text: "DBG", Color: NewColor(Bold, FgHiMagenta)},
 				slog.LevelInfo:  {Text: "INF", Color: NewColor(Bold, FgHiGreen)},
+				LevelNotice:     {Text: "NTC", Color: NewColor(Bold, FgHiCyan)},
 				slog.LevelWarn:  {Text: "WRN", Color: NewColor(Bold, FgHiYellow)},
 				slog.LevelError: {Text: "ERR", Color: NewColor(Bold, FgHiRed)},
+				LevelFatal:      {Text: "FTL", Color: NewColor(Bold, FgHiWhite, BgRed)},
 			},
 			Label: LabelStyle{
 				Color: NewColor(FgHiBlack, Bold),
 			},
 			Attr: AttrStyle{
-				KeyColor:  NewColor(FgHiBlack),
+				KeyColor:   NewColor(FgHiBlack),
+				ErrorColor: NewColor(FgHiRed),
+				NilColor:   NewColor(Faint),
+				Keys: map[string]AttrKeyStyle{
+					"err":   {KeyColor: NewColor(FgHiRed), ValueColor: NewColor(FgHiRed)},
+					"error": {KeyColor: NewColor(FgHiRed), ValueColor: NewColor(FgHiRed)},
+				},
 				Separator: "=",
 			},
 			Caller: CallerStyle{
@@ -393,22 +503,52 @@ func TestStyles(t *testing.T) {
 				Color:    NewColor(FgHiBlack, Underline),
 				Fullpath: false,
 			},
+			Stack: StackStyle{
+				Indent: "  ",
+				Color:  NewColor(Faint),
+			},
+			Multiline: MultilineStyle{
+				Indent: "  ",
+				Marker: AffixStyle{Text: "│", Color: NewColor(FgHiBlack)},
+			},
+			Step: StepStyle{
+				Running: AffixStyle{Text: "•", Color: NewColor(FgHiCyan)},
+				Success: AffixStyle{Text: "✓", Color: NewColor(Bold, FgHiGreen)},
+				Failed:  AffixStyle{Text: "✗", Color: NewColor(Bold, FgHiRed)},
+				Skipped: AffixStyle{Text: "○", Color: NewColor(FgHiBlack)},
+			},
+			Section: SectionStyle{
+				Line:       "─",
+				Side:       "│",
+				Corners:    [4]string{"╭", "╮", "╯", "╰"},
+				Color:      NewColor(FgHiBlack),
+				TitleColor: NewColor(Bold),
+			},
 		}
 		check(t, Style1(), want)
 	})
 	t.Run("Style2", func(t *testing.T) {
 		want := &Style{
 			Level: map[slog.Level]LevelStyle{
+				LevelTrace:      {Text: "TRC", Color: NewColor(38, 2, 138, 138, 138, Bold)},
 				slog.LevelDebug: {Text: "DBG", Color: NewColor(38, 2, 95, 95, 255, Bold)},
 				slog.LevelInfo:  {Text: "INF", Color: NewColor(38, 2, 95, 255, 215, Bold)},
+				LevelNotice:     {Text: "NTC", Color: NewColor(38, 2, 95, 215, 255, Bold)},
 				slog.LevelWarn:  {Text: "WRN", Color: NewColor(38, 2, 215, 255, 135, Bold)},
 				slog.LevelError: {Text: "ERR", Color: NewColor(38, 2, 255, 95, 135, Bold)},
+				LevelFatal:      {Text: "FTL", Color: NewColor(48, 2, 255, 0, 95, Bold)},
 			},
 			Label: LabelStyle{
 				Color: NewColor(FgHiBlack, Bold),
 			},
 			Attr: AttrStyle{
-				KeyColor:  NewColor(FgHiBlack),
+				KeyColor:   NewColor(FgHiBlack),
+				ErrorColor: NewColor(38, 2, 255, 95, 135),
+				NilColor:   NewColor(Faint),
+				Keys: map[string]AttrKeyStyle{
+					"err":   {KeyColor: NewColor(38, 2, 255, 95, 135), ValueColor: NewColor(38, 2, 255, 95, 135)},
+					"error": {KeyColor: NewColor(38, 2, 255, 95, 135), ValueColor: NewColor(38, 2, 255, 95, 135)},
+				},
 				Separator: "=",
 			},
 			Caller: CallerStyle{
@@ -417,22 +557,52 @@ func TestStyles(t *testing.T) {
 				Color:    NewColor(FgHiBlack, Underline),
 				Fullpath: false,
 			},
+			Stack: StackStyle{
+				Indent: "  ",
+				Color:  NewColor(Faint),
+			},
+			Multiline: MultilineStyle{
+				Indent: "  ",
+				Marker: AffixStyle{Text: "│", Color: NewColor(FgHiBlack)},
+			},
+			Step: StepStyle{
+				Running: AffixStyle{Text: "•", Color: NewColor(38, 2, 95, 95, 255)},
+				Success: AffixStyle{Text: "✓", Color: NewColor(38, 2, 95, 255, 215, Bold)},
+				Failed:  AffixStyle{Text: "✗", Color: NewColor(38, 2, 255, 95, 135, Bold)},
+				Skipped: AffixStyle{Text: "○", Color: NewColor(FgHiBlack)},
+			},
+			Section: SectionStyle{
+				Line:       "─",
+				Side:       "│",
+				Corners:    [4]string{"╭", "╮", "╯", "╰"},
+				Color:      NewColor(38, 2, 95, 95, 255),
+				TitleColor: NewColor(Bold),
+			},
 		}
 		check(t, Style2(), want)
 	})
 	t.Run("Style3", func(t *testing.T) {
 		want := &Style{
 			Level: map[slog.Level]LevelStyle{
+				LevelTrace:      {Text: "TRC", Color: NewColor(Bold, BgHiBlack), Width: 5},
 				slog.LevelDebug: {Text: "DBG", Color: NewColor(Bold, BgMagenta), Width: 5},
 				slog.LevelInfo:  {Text: "INF", Color: NewColor(Bold, BgGreen), Width: 5},
+				LevelNotice:     {Text: "NTC", Color: NewColor(Bold, BgCyan), Width: 5},
 				slog.LevelWarn:  {Text: "WRN", Color: NewColor(Bold, BgYellow), Width: 5},
 				slog.LevelError: {Text: "ERR", Color: NewColor(Bold, BgRed), Width: 5},
+				LevelFatal:      {Text: "FTL", Color: NewColor(Bold, BgHiRed), Width: 5},
 			},
 			Label: LabelStyle{
 				Color: NewColor(FgHiBlack, Bold),
 			},
 			Attr: AttrStyle{
-				KeyColor:  NewColor(FgHiBlack),
+				KeyColor:   NewColor(FgHiBlack),
+				ErrorColor: NewColor(FgHiRed),
+				NilColor:   NewColor(Faint),
+				Keys: map[string]AttrKeyStyle{
+					"err":   {KeyColor: NewColor(FgHiRed), ValueColor: NewColor(FgHiRed)},
+					"error": {KeyColor: NewColor(FgHiRed), ValueColor: NewColor(FgHiRed)},
+				},
 				Separator: "=",
 			},
 			Caller: CallerStyle{
@@ -441,22 +611,52 @@ func TestStyles(t *testing.T) {
 				Color:    NewColor(FgHiBlack, Underline),
 				Fullpath: false,
 			},
+			Stack: StackStyle{
+				Indent: "  ",
+				Color:  NewColor(Faint),
+			},
+			Multiline: MultilineStyle{
+				Indent: "  ",
+				Marker: AffixStyle{Text: "│", Color: NewColor(FgHiBlack)},
+			},
+			Step: StepStyle{
+				Running: AffixStyle{Text: "•", Color: NewColor(FgHiCyan)},
+				Success: AffixStyle{Text: "✓", Color: NewColor(Bold, FgHiGreen)},
+				Failed:  AffixStyle{Text: "✗", Color: NewColor(Bold, FgHiRed)},
+				Skipped: AffixStyle{Text: "○", Color: NewColor(FgHiBlack)},
+			},
+			Section: SectionStyle{
+				Line:       "─",
+				Side:       "│",
+				Corners:    [4]string{"╭", "╮", "╯", "╰"},
+				Color:      NewColor(FgHiBlack),
+				TitleColor: NewColor(Bold),
+			},
 		}
 		check(t, Style3(), want)
 	})
 	t.Run("Style4", func(t *testing.T) {
 		want := &Style{
 			Level: map[slog.Level]LevelStyle{
+				LevelTrace:      {Text: "TRC", Color: NewColor(48, 2, 138, 138, 138, Bold), Width: 5},
 				slog.LevelDebug: {Text: "DBG", Color: NewColor(48, 2, 95, 95, 255, Bold), Width: 5},
 				slog.LevelInfo:  {Text: "INF", Color: NewColor(48, 2, 95, 255, 215, Bold), Width: 5},
+				LevelNotice:     {Text: "NTC", Color: NewColor(48, 2, 95, 215, 255, Bold), Width: 5},
 				slog.LevelWarn:  {Text: "WRN", Color: NewColor(48, 2, 215, 255, 135, Bold), Width: 5},
 				slog.LevelError: {Text: "ERR", Color: NewColor(48, 2, 255, 95, 135, Bold), Width: 5},
+				LevelFatal:      {Text: "FTL", Color: NewColor(48, 2, 255, 0, 95, Bold), Width: 5},
 			},
 			Label: LabelStyle{
 				Color: NewColor(FgHiBlack, Bold),
 			},
 			Attr: AttrStyle{
-				KeyColor:  NewColor(FgHiBlack),
+				KeyColor:   NewColor(FgHiBlack),
+				ErrorColor: NewColor(38, 2, 255, 95, 135),
+				NilColor:   NewColor(Faint),
+				Keys: map[string]AttrKeyStyle{
+					"err":   {KeyColor: NewColor(38, 2, 255, 95, 135), ValueColor: NewColor(38, 2, 255, 95, 135)},
+					"error": {KeyColor: NewColor(38, 2, 255, 95, 135), ValueColor: NewColor(38, 2, 255, 95, 135)},
+				},
 				Separator: "=",
 			},
 			Caller: CallerStyle{
@@ -465,9 +665,63 @@ func TestStyles(t *testing.T) {
 				Color:    NewColor(FgHiBlack, Underline),
 				Fullpath: false,
 			},
+			Stack: StackStyle{
+				Indent: "  ",
+				Color:  NewColor(Faint),
+			},
+			Multiline: MultilineStyle{
+				Indent: "  ",
+				Marker: AffixStyle{Text: "│", Color: NewColor(FgHiBlack)},
+			},
+			Step: StepStyle{
+				Running: AffixStyle{Text: "•", Color: NewColor(38, 2, 95, 95, 255)},
+				Success: AffixStyle{Text: "✓", Color: NewColor(38, 2, 95, 255, 215, Bold)},
+				Failed:  AffixStyle{Text: "✗", Color: NewColor(38, 2, 255, 95, 135, Bold)},
+				Skipped: AffixStyle{Text: "○", Color: NewColor(FgHiBlack)},
+			},
+			Section: SectionStyle{
+				Line:       "─",
+				Side:       "│",
+				Corners:    [4]string{"╭", "╮", "╯", "╰"},
+				Color:      NewColor(38, 2, 95, 95, 255),
+				TitleColor: NewColor(Bold),
+			},
 		}
 		check(t, Style4(), want)
 	})
+	t.Run("Style5", func(t *testing.T) {
+		want := Style2()
+		want.Level = map[slog.Level]LevelStyle{
+			LevelTrace:      {Icon: "·", Color: NewColor(38, 2, 138, 138, 138, Bold)},
+			slog.LevelDebug: {Icon: "●", Color: NewColor(38, 2, 95, 95, 255, Bold)},
+			slog.LevelInfo:  {Icon: "ℹ", Color: NewColor(38, 2, 95, 255, 215, Bold)},
+			LevelNotice:     {Icon: "◆", Color: NewColor(38, 2, 95, 215, 255, Bold)},
+			slog.LevelWarn:  {Icon: "⚠", Color: NewColor(38, 2, 215, 255, 135, Bold)},
+			slog.LevelError: {Icon: "✖", Color: NewColor(38, 2, 255, 95, 135, Bold)},
+			LevelFatal:      {Icon: "✘", Color: NewColor(48, 2, 255, 0, 95, Bold)},
+		}
+		check(t, Style5(), want)
+	})
+}
+
+func TestLevelStyle_label(t *testing.T) {
+	tests := []struct {
+		name string
+		ls   LevelStyle
+		want string
+	}{
+		{name: "text", ls: LevelStyle{Text: "INF"}, want: "INF"},
+		{name: "icon", ls: LevelStyle{Icon: "ℹ"}, want: "ℹ"},
+		{name: "icon and text", ls: LevelStyle{Icon: "✖", Text: "ERR"}, want: "✖ ERR"},
+		{name: "empty", ls: LevelStyle{}, want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.ls.label(); got != tt.want {
+				t.Errorf("label() = %q, want %q", got, tt.want)
+			}
+		})
+	}
 }
 
 func TestStyle_Clone(t *testing.T) {
@@ -502,8 +756,9 @@ func TestStyle_Clone(t *testing.T) {
 		{
 			name: "deep copy independence",
 			setup: func() *Style {
-				original := Style0()
+				original := Style1()
 				original.Level[slog.LevelInfo] = LevelStyle{Text: "ORIGINAL"}
+				original.Message.Levels = map[slog.Level]*Color{slog.LevelError: NewColor(FgRed)}
 				original.Label.Width = 99
 				return original
 			},
@@ -516,6 +771,14 @@ func TestStyle_Clone(t *testing.T) {
 				if original.Label.Width == 100 {
 					t.Error("Clone() did not copy Label struct; modification leaked")
 				}
+				cloned.Message.Levels[slog.LevelError] = nil
+				if original.Message.Levels[slog.LevelError] == nil {
+					t.Error("Clone() did not deep copy Message.Levels map; modification leaked to original")
+				}
+				cloned.Attr.Keys["err"] = AttrKeyStyle{}
+				if original.Attr.Keys["err"].ValueColor == nil {
+					t.Error("Clone() did not deep copy Attr.Keys map; modification leaked to original")
+				}
 			},
 		},
 	}
@@ -527,3 +790,172 @@ func TestStyle_Clone(t *testing.T) {
 		})
 	}
 }
+
+func TestStyle_mapColors(t *testing.T) {
+	tests := []struct {
+		name  string
+		style *Style
+		fn    func(*Color) *Color
+		check func(*testing.T, *Style, *Style)
+	}{
+		{
+			name:  "nil receiver",
+			style: nil,
+			fn:    func(c *Color) *Color { return c },
+			check: func(t *testing.T, _ *Style, got *Style) {
+				if got != nil {
+					t.Errorf("mapColors() = %v, want nil", got)
+				}
+			},
+		},
+		{
+			name:  "strip colors",
+			style: Style4(),
+			fn:    func(*Color) *Color { return nil },
+			check: func(t *testing.T, original *Style, got *Style) {
+				for level, ls := range got.Level {
+					if ls.Color != nil || ls.Prefix.Color != nil || ls.Suffix.Color != nil {
+						t.Errorf("level %v color not stripped", level)
+					}
+					if ls.Text != original.Level[level].Text {
+						t.Errorf("level %v text = %q, want %q", level, ls.Text, original.Level[level].Text)
+					}
+				}
+				if got.Label.Color != nil || got.Attr.KeyColor != nil || got.Caller.Color != nil {
+					t.Error("colors not stripped")
+				}
+				for key, ks := range got.Attr.Keys {
+					if ks.KeyColor != nil || ks.ValueColor != nil {
+						t.Errorf("key %q color not stripped", key)
+					}
+				}
+				if got.Caller.Prefix.Text != "<" {
+					t.Errorf("caller prefix = %q, want %q", got.Caller.Prefix.Text, "<")
+				}
+				if !reflect.DeepEqual(original, Style4()) {
+					t.Error("original style was modified")
+				}
+			},
+		},
+		{
+			name: "message colors",
+			style: NewStyle(WithMessageStyle(MessageStyle{
+				Color:  NewColor(FgWhite),
+				Levels: map[slog.Level]*Color{slog.LevelError: NewColor(FgRed)},
+			})),
+			fn: func(c *Color) *Color { return NewColor(Bold) },
+			check: func(t *testing.T, original *Style, got *Style) {
+				if !reflect.DeepEqual(got.Message.Color, NewColor(Bold)) || !reflect.DeepEqual(got.Message.Levels[slog.LevelError], NewColor(Bold)) {
+					t.Errorf("message = %+v, want mapped colors", got.Message)
+				}
+				if !reflect.DeepEqual(original.Message.Levels[slog.LevelError], NewColor(FgRed)) {
+					t.Error("original style was modified")
+				}
+			},
+		},
+		{
+			name:  "wrap colorizer",
+			style: NewStyle(WithValueColorizer(func(string, slog.Value) *Color { return NewColor(FgRed) })),
+			fn:    func(*Color) *Color { return nil },
+			check: func(t *testing.T, original *Style, got *Style) {
+				if c := got.Attr.Colorizer("status", slog.IntValue(500)); c != nil {
+					t.Errorf("colorizer color = %+v, want nil", c)
+				}
+				if c := original.Attr.Colorizer("status", slog.IntValue(500)); c == nil {
+					t.Error("original colorizer was modified")
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.style.mapColors(tt.fn)
+			tt.check(t, tt.style, got)
+		})
+	}
+}
+
+func TestStyle_levelStyle(t *testing.T) {
+	custom := NewStyle(WithLevelStyle(map[slog.Level]LevelStyle{
+		slog.Level(-8): {Text: "[TRC]"},
+		slog.Level(12): {Text: "[FTL]"},
+	}))
+	tests := []struct {
+		name  string
+		style *Style
+		level slog.Level
+		want  string
+	}{
+		{name: "exact", style: Style0(), level: slog.LevelWarn, want: "[WRN]"},
+		{name: "between levels", style: Style0(), level: slog.LevelInfo + 1, want: "[INF]"},
+		{name: "above highest", style: Style0(), level: slog.Level(16), want: "[FTL]"},
+		{name: "below lowest", style: Style0(), level: slog.Level(-12), want: "[TRC]"},
+		{name: "custom trace", style: custom, level: slog.Level(-8), want: "[TRC]"},
+		{name: "custom between", style: custom, level: slog.Level(-6), want: "[TRC]"},
+		{name: "custom fatal", style: custom, level: slog.Level(16), want: "[FTL]"},
+		{name: "no levels", style: &Style{}, level: slog.LevelInfo, want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.style.levelStyle(tt.level).Text; got != tt.want {
+				t.Errorf("levelStyle(%v).Text = %q, want %q", tt.level, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStyle_messageColor(t *testing.T) {
+	red, bold, white := NewColor(FgRed), NewColor(Bold), NewColor(FgWhite)
+	tests := []struct {
+		name    string
+		message MessageStyle
+		level   slog.Level
+		want    *Color
+	}{
+		{name: "none", level: slog.LevelError, want: nil},
+		{name: "default", message: MessageStyle{Color: white}, level: slog.LevelInfo, want: white},
+		{name: "exact", message: MessageStyle{Color: white, Levels: map[slog.Level]*Color{slog.LevelError: red}}, level: slog.LevelError, want: red},
+		{name: "above", message: MessageStyle{Levels: map[slog.Level]*Color{slog.LevelWarn: bold, slog.LevelError: red}}, level: LevelFatal, want: red},
+		{name: "between", message: MessageStyle{Levels: map[slog.Level]*Color{slog.LevelWarn: bold, slog.LevelError: red}}, level: slog.LevelWarn + 2, want: bold},
+		{name: "below", message: MessageStyle{Color: white, Levels: map[slog.Level]*Color{slog.LevelWarn: bold}}, level: slog.LevelInfo, want: white},
+		{name: "nil level color", message: MessageStyle{Color: white, Levels: map[slog.Level]*Color{slog.LevelInfo: nil}}, level: slog.LevelInfo, want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewStyle(WithMessageStyle(tt.message))
+			if got := s.messageColor(tt.level); got != tt.want {
+				t.Errorf("messageColor(%v) = %v, want %v", tt.level, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithGroupDelimiters(t *testing.T) {
+	s := Style0()
+	WithGroupDelimiters("{", "}", ",")(s)
+	if s.Attr.GroupPrefix != "{" || s.Attr.GroupSuffix != "}" || s.Attr.GroupSeparator != "," {
+		t.Errorf("got %q %q %q, want { } ,", s.Attr.GroupPrefix, s.Attr.GroupSuffix, s.Attr.GroupSeparator)
+	}
+}
+
+func TestWithAttrWidth(t *testing.T) {
+	tests := []struct {
+		name  string
+		width int
+		want  int
+	}{
+		{name: "fixed", width: 12, want: 12},
+		{name: "auto", width: AttrWidthAuto, want: AttrWidthAuto},
+		{name: "below auto", width: -5, want: AttrWidthAuto},
+		{name: "disabled", width: 0, want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := Style0()
+			WithAttrWidth(tt.width)(s)
+			if s.Attr.Width != tt.want {
+				t.Errorf("got %d, want %d", s.Attr.Width, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,141 @@
+package log
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+// Summary holds the number of records handled per level. Each level counts the
+// records from it up to the next, e.g. Warn counts slog.LevelWarn+2 as well.
+type Summary struct {
+	Trace  int
+	Debug  int
+	Info   int
+	Notice int
+	Warn   int
+	Error  int
+	Fatal  int
+}
+
+// Summarizer is implemented by handlers that count the records they handle, as
+// CLIHandler does.
+type Summarizer interface {
+	Summary() Summary
+}
+
+// String returns the warnings and errors of the summary, e.g. "3 warnings, 1
+// error", or "no warnings or errors".
+func (s Summary) String() string {
+	var parts []string
+	for _, c := range []struct {
+		n    int
+		name string
+	}{
+		{s.Warn, "warning"},
+		{s.Error, "error"},
+		{s.Fatal, "fatal error"},
+	} {
+		switch c.n {
+		case 0:
+		case 1:
+			parts = append(parts, "1 "+c.name)
+		default:
+			parts = append(parts, strconv.Itoa(c.n)+" "+c.name+"s")
+		}
+	}
+	if len(parts) == 0 {
+		return "no warnings or errors"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// ExitCode returns the exit code suggested by the summary: 1 if any errors were
+// logged, and 0 otherwise.
+func (s Summary) ExitCode() int {
+	if s.Error > 0 || s.Fatal > 0 {
+		return 1
+	}
+	return 0
+}
+
+// SummaryOf returns the summary of the first handler implementing Summarizer
+// among handler and the handlers it wraps, found as by Flush. It reports false if
+// there is none.
+func SummaryOf(handler slog.Handler) (Summary, bool) {
+	var s Summary
+	found := errors.New("found")
+	err := walk(handler, func(h slog.Handler) error {
+		if sh, ok := h.(Summarizer); ok {
+			s = sh.Summary()
+			return found
+		}
+		return nil
+	})
+	return s, errors.Is(err, found)
+}
+
+// Report writes the summary of handler to w, e.g. "3 warnings, 1 error", and
+// returns the exit code it suggests, for use at the end of a program:
+//
+//	defer func() { os.Exit(log.Report(os.Stderr, logger.Handler())) }()
+func Report(w io.Writer, handler slog.Handler) int {
+	s, _ := SummaryOf(handler)
+	fmt.Fprintln(w, s)
+	return s.ExitCode()
+}
+
+// Summary returns the number of records handled per level by the underlying
+// handler. See SummaryOf.
+func (l *Logger) Summary() Summary {
+	s, _ := SummaryOf(l.Handler())
+	return s
+}
+
+// Report writes the summary of the underlying handler to w and returns the exit
+// code it suggests. See Report.
+func (l *Logger) Report(w io.Writer) int {
+	return Report(w, l.Handler())
+}
+
+// levelCounts counts the records handled by a handler and the handlers derived
+// from it, per level of Summary.
+type levelCounts [7]atomic.Int64
+
+// add counts a record at level.
+func (c *levelCounts) add(level slog.Level) {
+	i := 0
+	for _, l := range []slog.Level{slog.LevelDebug, slog.LevelInfo, LevelNotice, slog.LevelWarn, slog.LevelError, LevelFatal} {
+		if level < l {
+			break
+		}
+		i++
+	}
+	c[i].Add(1)
+}
+
+// summary returns the counts as a Summary.
+func (c *levelCounts) summary() Summary {
+	return Summary{
+		Trace:  int(c[0].Load()),
+		Debug:  int(c[1].Load()),
+		Info:   int(c[2].Load()),
+		Notice: int(c[3].Load()),
+		Warn:   int(c[4].Load()),
+		Error:  int(c[5].Load()),
+		Fatal:  int(c[6].Load()),
+	}
+}
+
+// Summary returns the number of records handled per level by the handler and
+// the handlers derived from it, including records that failed to be written.
+func (h *CLIHandler) Summary() Summary {
+	if h.counts == nil {
+		return Summary{}
+	}
+	return h.counts.summary()
+}
@@ -0,0 +1,75 @@
+package log
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"testing"
+)
+
+func TestSummary_String(t *testing.T) {
+	tests := []struct {
+		s    Summary
+		want string
+		code int
+	}{
+		{s: Summary{}, want: "no warnings or errors", code: 0},
+		{s: Summary{Info: 5}, want: "no warnings or errors", code: 0},
+		{s: Summary{Warn: 3, Error: 1}, want: "3 warnings, 1 error", code: 1},
+		{s: Summary{Warn: 1}, want: "1 warning", code: 0},
+		{s: Summary{Error: 2, Fatal: 1}, want: "2 errors, 1 fatal error", code: 1},
+	}
+	for _, tt := range tests {
+		if got := tt.s.String(); got != tt.want {
+			t.Errorf("%+v.String() = %q, want %q", tt.s, got, tt.want)
+		}
+		if got := tt.s.ExitCode(); got != tt.code {
+			t.Errorf("%+v.ExitCode() = %d, want %d", tt.s, got, tt.code)
+		}
+	}
+}
+
+func TestLogger_Summary(t *testing.T) {
+	l := NewLogger(NewCLIHandler(io.Discard, WithLevel(LevelTrace)))
+	child := l.With("k", "v").WithGroup("g")
+	l.Log(t.Context(), LevelTrace, "msg")
+	l.Debug("msg")
+	l.Info("msg")
+	l.Log(t.Context(), LevelNotice, "msg")
+	child.Warn("msg")
+	l.Log(t.Context(), slog.LevelWarn+2, "msg")
+	child.Error("msg")
+	l.Log(t.Context(), LevelFatal, "msg")
+	l.Info("hidden by level")
+	want := Summary{Trace: 1, Debug: 1, Info: 2, Notice: 1, Warn: 2, Error: 1, Fatal: 1}
+	if got := l.Summary(); got != want {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+
+	buf := &bytes.Buffer{}
+	if code := l.Report(buf); code != 1 {
+		t.Errorf("exit code = %d, want 1", code)
+	}
+	if want := "2 warnings, 1 error, 1 fatal error\n"; buf.String() != want {
+		t.Errorf("got %q, want %q", buf.String(), want)
+	}
+}
+
+func TestSummaryOf(t *testing.T) {
+	h := NewCLIHandler(io.Discard, WithLevel(slog.LevelWarn))
+	l := NewLogger(NewSamplingHandler(h))
+	l.Info("dropped")
+	l.Warn("msg")
+	s, ok := SummaryOf(l.Handler())
+	if !ok || s != (Summary{Warn: 1}) {
+		t.Errorf("got %+v, %v, want one warning", s, ok)
+	}
+
+	if _, ok := SummaryOf(slog.NewJSONHandler(io.Discard, nil)); ok {
+		t.Error("got a summary from a handler not counting records")
+	}
+	buf := &bytes.Buffer{}
+	if code := Report(buf, slog.DiscardHandler); code != 0 || buf.String() != "no warnings or errors\n" {
+		t.Errorf("got %q, %d", buf.String(), code)
+	}
+}
@@ -0,0 +1,141 @@
+package log
+
+import (
+	"bytes"
+	"io"
+)
+
+// TableStyle config for the tables written by Table. Cells are separated by
+// Separator, and the header is underlined by Rule repeated to the width of each
+// column, unless Rule is empty. Align sets the alignment of each column; columns
+// beyond it are aligned left.
+type TableStyle struct {
+	HeaderColor *Color
+	CellColor   *Color
+	Rule        string
+	RuleColor   *Color
+	Separator   string
+	Align       []Alignment
+}
+
+// DefaultTableStyle returns the default style of Table.
+func DefaultTableStyle() TableStyle {
+	return TableStyle{
+		HeaderColor: NewColor(Bold),
+		Rule:        "─",
+		RuleColor:   NewColor(FgHiBlack),
+		Separator:   "  ",
+	}
+}
+
+// TableOption defines a function type for configuring Table.
+type TableOption func(*tableConfig)
+
+// tableConfig holds the options of Table.
+type tableConfig struct {
+	style     TableStyle
+	colorMode ColorMode
+}
+
+// WithTableStyle returns a TableOption that sets the table style. The default
+// is DefaultTableStyle.
+func WithTableStyle(style TableStyle) TableOption {
+	return func(c *tableConfig) {
+		c.style = style
+	}
+}
+
+// WithTableColorMode returns a TableOption that sets when colors are written,
+// as WithColorMode does for CLIHandler. The default is ColorAuto.
+func WithTableColorMode(mode ColorMode) TableOption {
+	return func(c *tableConfig) {
+		c.colorMode = mode
+	}
+}
+
+// Table writes rows as a table with aligned columns to w, headed by headers
+// unless there are none. Columns are as wide as their widest cell, counting
+// the display width of wide characters and ignoring SGR sequences, so cells
+// may be colored. Rows may have fewer or more cells than headers.
+func Table(w io.Writer, headers []string, rows [][]string, opts ...TableOption) error {
+	c := &tableConfig{style: DefaultTableStyle()}
+	for _, opt := range opts {
+		opt(c)
+	}
+	ts := c.style
+	if !colorEnabled(w, c.colorMode) {
+		ts.HeaderColor, ts.CellColor, ts.RuleColor = nil, nil, nil
+	}
+
+	widths := make([]int, len(headers))
+	measure := func(cells []string) {
+		for i, cell := range cells {
+			if i == len(widths) {
+				widths = append(widths, 0)
+			}
+			widths[i] = max(widths[i], VisibleWidth(cell))
+		}
+	}
+	measure(headers)
+	for _, row := range rows {
+		measure(row)
+	}
+
+	buf := &bytes.Buffer{}
+	if len(headers) > 0 {
+		ts.writeRow(buf, headers, widths, ts.HeaderColor)
+		if ts.Rule != "" {
+			rw := max(1, VisibleWidth(ts.Rule))
+			for i, width := range widths {
+				if i > 0 {
+					buf.WriteString(ts.Separator)
+				}
+				n := (width + rw - 1) / rw
+				for range n {
+					ts.RuleColor.WriteString(buf, ts.Rule)
+				}
+			}
+			buf.WriteString("\n")
+		}
+	}
+	for _, row := range rows {
+		ts.writeRow(buf, row, widths, ts.CellColor)
+	}
+	_, err := setColorable(w).Write(buf.Bytes())
+	return err
+}
+
+// writeRow writes the cells of a row padded to the column widths to buf.
+// Trailing spaces are not written.
+func (ts TableStyle) writeRow(buf *bytes.Buffer, cells []string, widths []int, color *Color) {
+	start := buf.Len()
+	for i, width := range widths {
+		if i > 0 {
+			buf.WriteString(ts.Separator)
+		}
+		var cell string
+		if i < len(cells) {
+			cell = cells[i]
+		}
+		a := AlignLeft
+		if i < len(ts.Align) {
+			a = ts.Align[i]
+		}
+		pad := width - VisibleWidth(cell)
+		var lp int
+		switch a {
+		case AlignRight:
+			lp = pad
+		case AlignCenter:
+			lp = pad / 2
+		}
+		writeSpaces(buf, lp)
+		if cell != "" {
+			color.WriteString(buf, cell)
+		}
+		writeSpaces(buf, pad-lp)
+	}
+	b := bytes.TrimRight(buf.Bytes()[start:], " ")
+	buf.Truncate(start + len(b))
+	buf.WriteString("\n")
+}
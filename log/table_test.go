@@ -0,0 +1,91 @@
+package log
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestTable(t *testing.T) {
+	headers := []string{"NAME", "STATUS", "TIME"}
+	rows := [][]string{
+		{"build", "ok", "1.2s"},
+		{"deploy", "failed", "12s"},
+		{"通知", "skipped"},
+	}
+	tests := []struct {
+		name    string
+		headers []string
+		rows    [][]string
+		opts    []TableOption
+		want    string
+	}{
+		{
+			name:    "default",
+			headers: headers,
+			rows:    rows,
+			want: "NAME    STATUS   TIME\n" +
+				"──────  ───────  ────\n" +
+				"build   ok       1.2s\n" +
+				"deploy  failed   12s\n" +
+				"通知    skipped\n",
+		},
+		{
+			name:    "style",
+			headers: headers,
+			rows:    rows,
+			opts:    []TableOption{WithTableStyle(TableStyle{Separator: " | ", Rule: "=", Align: []Alignment{AlignLeft, AlignCenter, AlignRight}})},
+			want: "NAME   | STATUS  | TIME\n" +
+				"====== | ======= | ====\n" +
+				"build  |   ok    | 1.2s\n" +
+				"deploy | failed  |  12s\n" +
+				"通知   | skipped |\n",
+		},
+		{
+			name: "no headers",
+			rows: [][]string{{"a", "b"}, {"ccc", "d", "e"}},
+			want: "a    b\n" +
+				"ccc  d  e\n",
+		},
+		{
+			name:    "no rows",
+			headers: []string{"A", "B"},
+			want:    "A  B\n─  ─\n",
+		},
+		{
+			name:    "color",
+			headers: []string{"A"},
+			rows:    [][]string{{"x"}},
+			opts:    []TableOption{WithTableColorMode(ColorAlways), WithTableStyle(TableStyle{HeaderColor: NewColor(Bold), CellColor: NewColor(FgRed)})},
+			want:    "\x1b[1mA\x1b[0m\n\x1b[31mx\x1b[0m\n",
+		},
+		{
+			name:    "colored cells",
+			headers: []string{"A", "B"},
+			rows:    [][]string{{string(NewColor(FgRed).Append(nil, "xx")), "y"}},
+			opts:    []TableOption{WithTableStyle(TableStyle{Separator: " "})},
+			want:    "A  B\n\x1b[31mxx\x1b[0m y\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			if err := Table(buf, tt.headers, tt.rows, append([]TableOption{WithTableColorMode(ColorNever)}, tt.opts...)...); err != nil {
+				t.Fatal(err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) { return 0, errors.New("write failed") }
+
+func TestTable_error(t *testing.T) {
+	if err := Table(failingWriter{}, []string{"A"}, nil); err == nil {
+		t.Error("want the write error")
+	}
+}
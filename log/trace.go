@@ -0,0 +1,57 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Attribute keys used for trace correlation.
+const (
+	TraceIDKey = "trace_id"
+	SpanIDKey  = "span_id"
+)
+
+// writeTrace writes the trace and span IDs of the span carried by ctx to buf.
+func (h *CLIHandler) writeTrace(ctx context.Context, buf *bytes.Buffer) {
+	for _, attr := range h.traceAttrs(ctx) {
+		h.writeSpacedAttr(buf, attr, nil, h.style, h.timeLayout)
+	}
+}
+
+// traceAttrs returns the trace and span ID attrs of the span carried by ctx,
+// rewritten by the ReplaceAttr function and the Redactor. They are top-level
+// attributes, not qualified by the groups of the handler.
+func (h *CLIHandler) traceAttrs(ctx context.Context) []slog.Attr {
+	sc, ok := spanContext(ctx)
+	if !ok {
+		return nil
+	}
+	attrs := make([]slog.Attr, 0, 2)
+	for _, attr := range [...]slog.Attr{
+		slog.String(TraceIDKey, sc.TraceID().String()),
+		slog.String(SpanIDKey, sc.SpanID().String()),
+	} {
+		if h.replaceAttr != nil {
+			if attr = h.replace(nil, attr); isEmptyAttr(attr) {
+				continue
+			}
+		}
+		if h.redactor != nil {
+			attr = h.redactor.redact(attr, h.anyFormat != AnyFormatDefault)
+		}
+		attrs = append(attrs, attr)
+	}
+	return attrs
+}
+
+// spanContext returns the valid span context carried by ctx.
+func spanContext(ctx context.Context) (trace.SpanContext, bool) {
+	if ctx == nil {
+		return trace.SpanContext{}, false
+	}
+	sc := trace.SpanContextFromContext(ctx)
+	return sc, sc.IsValid()
+}
@@ -0,0 +1,122 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestCLIHandler_Handle_trace(t *testing.T) {
+	sc := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: trace.TraceID{0x4b, 0xf9, 0x2f, 0x35, 0x77, 0xb3, 0x4d, 0xa6, 0xa3, 0xce, 0x92, 0x9d, 0x0e, 0x0e, 0x47, 0x36},
+		SpanID:  trace.SpanID{0x00, 0xf0, 0x67, 0xaa, 0x0b, 0xa9, 0x02, 0xb7},
+	})
+	spanCtx := trace.ContextWithSpanContext(context.Background(), sc)
+	tests := []struct {
+		name    string
+		handler func(*bytes.Buffer) slog.Handler
+		ctx     context.Context
+		want    string
+	}{
+		{
+			name: "disabled",
+			handler: func(buf *bytes.Buffer) slog.Handler {
+				return NewCLIHandler(buf, WithStyle(Style0()))
+			},
+			ctx:  spanCtx,
+			want: "[INF] msg key=val\n",
+		},
+		{
+			name: "no span",
+			handler: func(buf *bytes.Buffer) slog.Handler {
+				return NewCLIHandler(buf, WithStyle(Style0()), WithTraceCorrelation(true))
+			},
+			ctx:  context.Background(),
+			want: "[INF] msg key=val\n",
+		},
+		{
+			name: "nil context",
+			handler: func(buf *bytes.Buffer) slog.Handler {
+				return NewCLIHandler(buf, WithStyle(Style0()), WithTraceCorrelation(true))
+			},
+			ctx:  nil,
+			want: "[INF] msg key=val\n",
+		},
+		{
+			name: "span",
+			handler: func(buf *bytes.Buffer) slog.Handler {
+				return NewCLIHandler(buf, WithStyle(Style0()), WithTraceCorrelation(true))
+			},
+			ctx:  spanCtx,
+			want: "[INF] msg trace_id=4bf92f3577b34da6a3ce929d0e0e4736 span_id=00f067aa0ba902b7 key=val\n",
+		},
+		{
+			name: "span with trace color",
+			handler: func(buf *bytes.Buffer) slog.Handler {
+				s := Style0()
+				s.Attr.TraceColor = NewColor(FgBlue)
+				return NewCLIHandler(buf, WithStyle(s), WithTraceCorrelation(true))
+			},
+			ctx:  spanCtx,
+			want: "[INF] msg trace_id=\x1b[34m4bf92f3577b34da6a3ce929d0e0e4736\x1b[0m span_id=\x1b[34m00f067aa0ba902b7\x1b[0m key=val\n",
+		},
+		{
+			name: "replace attr",
+			handler: func(buf *bytes.Buffer) slog.Handler {
+				return NewCLIHandler(buf, WithStyle(Style0()), WithTraceCorrelation(true), WithReplaceAttr(func(groups []string, a slog.Attr) slog.Attr {
+					switch {
+					case groups != nil:
+					case a.Key == SpanIDKey:
+						return slog.Attr{}
+					case a.Key == TraceIDKey:
+						a.Key = "trace"
+					}
+					return a
+				}))
+			},
+			ctx:  spanCtx,
+			want: "[INF] msg trace=4bf92f3577b34da6a3ce929d0e0e4736 key=val\n",
+		},
+		{
+			name: "redactor",
+			handler: func(buf *bytes.Buffer) slog.Handler {
+				return NewCLIHandler(buf, WithStyle(Style0()), WithTraceCorrelation(true), WithRedactor(NewRedactor(WithKeyPatterns(SpanIDKey))))
+			},
+			ctx:  spanCtx,
+			want: "[INF] msg trace_id=4bf92f3577b34da6a3ce929d0e0e4736 span_id=*** key=val\n",
+		},
+		{
+			name: "not grouped",
+			handler: func(buf *bytes.Buffer) slog.Handler {
+				return NewCLIHandler(buf, WithStyle(Style0()), WithTraceCorrelation(true)).WithGroup("g")
+			},
+			ctx:  spanCtx,
+			want: "[INF] msg trace_id=4bf92f3577b34da6a3ce929d0e0e4736 span_id=00f067aa0ba902b7 g.key=val\n",
+		},
+		{
+			name: "logfmt span",
+			handler: func(buf *bytes.Buffer) slog.Handler {
+				return NewLogfmtHandler(buf, WithTraceCorrelation(true))
+			},
+			ctx:  spanCtx,
+			want: "level=INFO msg=msg trace_id=4bf92f3577b34da6a3ce929d0e0e4736 span_id=00f067aa0ba902b7 key=val\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			r := slog.NewRecord(time.Time{}, slog.LevelInfo, "msg", 0)
+			r.AddAttrs(slog.String("key", "val"))
+			if err := tt.handler(buf).Handle(tt.ctx, r); err != nil {
+				t.Fatal(err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package log
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"unicode/utf8"
+
+	"github.com/mattn/go-runewidth"
+	"golang.org/x/term"
+)
+
+// WidthAuto makes WithWidth wrap lines at the width of the terminal.
+const WidthAuto = -1
+
+// defaultWrapIndent is the hanging indent of wrapped lines.
+const defaultWrapIndent = "  "
+
+// lineWidth returns the column at which lines are wrapped, or 0 if they are not.
+func (h *CLIHandler) lineWidth() int {
+	if h.width != WidthAuto {
+		return max(0, h.width)
+	}
+	return termWidth(h.term)
+}
+
+// termWidth returns the width of the terminal f, or zero if f is nil or its
+// width is unknown.
+func termWidth(f *os.File) int {
+	if f == nil {
+		return 0
+	}
+	w, _, err := term.GetSize(int(f.Fd()))
+	if err != nil {
+		return 0
+	}
+	return w
+}
+
+// terminal returns w as a file if it is a terminal.
+func terminal(w io.Writer) *os.File {
+	f, ok := w.(*os.File)
+	if !ok || !term.IsTerminal(int(f.Fd())) {
+		return nil
+	}
+	return f
+}
+
+// wrap rewrites every line in buf so that no line is wider than width columns.
+// Lines are broken at spaces where possible and continued after indent. SGR
+// and OSC sequences do not count towards the width, and colors active at a break are
+// reset before it and restored after the indent.
+func wrap(buf *bytes.Buffer, width int, indent string) {
+	tmp := bufPool.Get().(*bytes.Buffer)
+	defer func() {
+		tmp.Reset()
+		bufPool.Put(tmp)
+	}()
+	tmp.Write(buf.Bytes())
+	buf.Reset()
+	w := &wrapper{buf: buf, width: width, indent: indent, indentWidth: VisibleWidth(indent)}
+	lines := bytes.Split(tmp.Bytes(), []byte("\n"))
+	for i, line := range lines {
+		if i > 0 {
+			buf.WriteByte('\n')
+		}
+		w.line(line)
+	}
+}
+
+// wrapper holds the state of wrapping a single line.
+type wrapper struct {
+	buf         *bytes.Buffer
+	width       int
+	indent      string
+	indentWidth int
+	col         int
+	active      []byte
+}
+
+// line writes a line without its newline, breaking it as needed.
+func (w *wrapper) line(line []byte) {
+	w.col = 0
+	w.active = w.active[:0]
+	for i, word := range bytes.Split(line, []byte(" ")) {
+		ww := visibleWidth(word)
+		if i > 0 {
+			if w.col+1+ww > w.width && w.col > w.indentWidth && w.indentWidth+ww <= w.width {
+				w.newline()
+			} else {
+				w.buf.WriteByte(' ')
+				w.col++
+			}
+		}
+		w.word(word)
+	}
+}
+
+// word writes a word, breaking it if it does not fit on a line of its own.
+func (w *wrapper) word(word []byte) {
+	for len(word) > 0 {
+		if n := sgrLen(word); n > 0 {
+			w.sgr(word[:n])
+			word = word[n:]
+			continue
+		}
+		if n := oscLen(word); n > 0 {
+			w.buf.Write(word[:n])
+			word = word[n:]
+			continue
+		}
+		r, size := utf8.DecodeRune(word)
+		rw := runewidth.RuneWidth(r)
+		if w.col+rw > w.width && w.col > w.indentWidth {
+			w.newline()
+		}
+		w.buf.Write(word[:size])
+		w.col += rw
+		word = word[size:]
+	}
+}
+
+// sgr writes an escape sequence and tracks the colors it activates.
+func (w *wrapper) sgr(seq []byte) {
+	w.buf.Write(seq)
+	if bytes.Equal(seq, []byte("\x1b[0m")) || bytes.Equal(seq, []byte("\x1b[m")) {
+		w.active = w.active[:0]
+		return
+	}
+	w.active = append(w.active, seq...)
+}
+
+// newline breaks the line and writes the hanging indent.
+func (w *wrapper) newline() {
+	if len(w.active) > 0 {
+		w.buf.WriteString("\x1b[0m")
+	}
+	w.buf.WriteByte('\n')
+	w.buf.WriteString(w.indent)
+	w.buf.Write(w.active)
+	w.col = w.indentWidth
+}
@@ -0,0 +1,156 @@
+package log
+
+import (
+	"bytes"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+)
+
+func Test_wrap(t *testing.T) {
+	tests := []struct {
+		name   string
+		in     string
+		width  int
+		indent string
+		want   string
+	}{
+		{
+			name:   "fits",
+			in:     "[INF] hello world",
+			width:  20,
+			indent: "  ",
+			want:   "[INF] hello world",
+		},
+		{
+			name:   "break at spaces",
+			in:     "[INF] hello world key=value",
+			width:  12,
+			indent: "  ",
+			want:   "[INF] hello\n  world\n  key=value",
+		},
+		{
+			name:   "hyperlinks",
+			in:     "[INF] \x1b]8;;file:///main.go\x1b\\main.go\x1b]8;;\x1b\\ key=value",
+			width:  16,
+			indent: "  ",
+			want:   "[INF] \x1b]8;;file:///main.go\x1b\\main.go\x1b]8;;\x1b\\\n  key=value",
+		},
+		{
+			name:   "hard break long word",
+			in:     "abcdefghij",
+			width:  4,
+			indent: " ",
+			want:   "abcd\n efg\n hij",
+		},
+		{
+			name:   "long word after short word",
+			in:     "ab cdefghij",
+			width:  6,
+			indent: "",
+			want:   "ab cde\nfghij",
+		},
+		{
+			name:   "colors are not counted and are restored",
+			in:     "\x1b[31mred text here\x1b[0m tail",
+			width:  8,
+			indent: "> ",
+			want:   "\x1b[31mred text\x1b[0m\n> \x1b[31mhere\x1b[0m\n> tail",
+		},
+		{
+			name:   "wide runes",
+			in:     "日本 日本語",
+			width:  6,
+			indent: "",
+			want:   "日本\n日本語",
+		},
+		{
+			name:   "each line wrapped",
+			in:     "aaa bbb\nccc ddd",
+			width:  4,
+			indent: "",
+			want:   "aaa\nbbb\nccc\nddd",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := bytes.NewBufferString(tt.in)
+			wrap(buf, tt.width, tt.indent)
+			if got := buf.String(); got != tt.want {
+				t.Errorf("wrap() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCLIHandler_lineWidth(t *testing.T) {
+	tests := []struct {
+		name string
+		h    *CLIHandler
+		want int
+	}{
+		{name: "disabled", h: &CLIHandler{}, want: 0},
+		{name: "explicit", h: &CLIHandler{width: 80}, want: 80},
+		{name: "negative", h: &CLIHandler{width: -5}, want: 0},
+		{name: "auto without terminal", h: &CLIHandler{width: WidthAuto}, want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.h.lineWidth(); got != tt.want {
+				t.Errorf("lineWidth() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_terminal(t *testing.T) {
+	if got := terminal(&bytes.Buffer{}); got != nil {
+		t.Errorf("terminal() = %v, want nil", got)
+	}
+	f, err := os.CreateTemp(t.TempDir(), "out")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if got := terminal(f); got != nil {
+		t.Errorf("terminal() = %v, want nil", got)
+	}
+}
+
+func TestCLIHandler_Handle_width(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []CLIHandlerOption
+		want string
+	}{
+		{
+			name: "no wrapping",
+			want: "[INF] a long message key=value\n",
+		},
+		{
+			name: "wrap",
+			opts: []CLIHandlerOption{WithWidth(16)},
+			want: "[INF] a long\n  message\n  key=value\n",
+		},
+		{
+			name: "wrap indent",
+			opts: []CLIHandlerOption{WithWidth(16), WithWrapIndent("    ")},
+			want: "[INF] a long\n    message\n    key=value\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			h := NewCLIHandler(buf, append([]CLIHandlerOption{WithStyle(Style0())}, tt.opts...)...)
+			r := slog.NewRecord(time.Time{}, slog.LevelInfo, "a long message", 0)
+			r.AddAttrs(slog.String("key", "value"))
+			if err := h.Handle(t.Context(), r); err != nil {
+				t.Fatal(err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,129 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+var _ io.WriteCloser = (*LineWriter)(nil)
+
+// defaultMaxLineSize is the default number of bytes after which a line without
+// a newline is logged as is.
+const defaultMaxLineSize = 64 * 1024
+
+// LineWriter is an io.Writer that splits the bytes written to it on newlines
+// and logs each non-empty line as a record. A trailing partial line is kept
+// until the next newline or until Flush or Close is called.
+type LineWriter struct {
+	mu          sync.Mutex
+	handler     slog.Handler
+	level       slog.Level
+	attrs       []slog.Attr
+	parseLevel  bool
+	maxLineSize int
+	buf         []byte
+}
+
+// WriterOption defines a function type for configuring a LineWriter.
+type WriterOption func(*LineWriter)
+
+// WithWriterAttrs returns a WriterOption that adds attributes to every record.
+func WithWriterAttrs(attrs ...slog.Attr) WriterOption {
+	return func(w *LineWriter) {
+		w.attrs = append(w.attrs, attrs...)
+	}
+}
+
+// WithParseLevel returns a WriterOption that sets whether level prefixes such
+// as "[WARN] " or "ERROR: " select the level of a line, as NewStdLogger does.
+func WithParseLevel(parse bool) WriterOption {
+	return func(w *LineWriter) {
+		w.parseLevel = parse
+	}
+}
+
+// WithMaxLineSize returns a WriterOption that sets the number of bytes after
+// which a line without a newline is logged as is. The default is 64 KiB.
+func WithMaxLineSize(n int) WriterOption {
+	return func(w *LineWriter) {
+		if n > 0 {
+			w.maxLineSize = n
+		}
+	}
+}
+
+// Writer returns a LineWriter logging each line written to it at level, e.g.
+// to capture the output of an exec.Cmd.
+func (l *Logger) Writer(level slog.Level, opts ...WriterOption) *LineWriter {
+	w := &LineWriter{
+		handler:     l.Handler(),
+		level:       level,
+		maxLineSize: defaultMaxLineSize,
+	}
+	for _, opt := range opts {
+		opt(w)
+	}
+	return w
+}
+
+// Write logs every complete line of p and keeps the trailing partial line.
+func (w *LineWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			break
+		}
+		w.emit(w.buf[:i])
+		w.buf = w.buf[i+1:]
+	}
+	for len(w.buf) >= w.maxLineSize {
+		w.emit(w.buf[:w.maxLineSize])
+		w.buf = w.buf[w.maxLineSize:]
+	}
+	if len(w.buf) == 0 {
+		w.buf = nil
+	}
+	return len(p), nil
+}
+
+// Flush logs the trailing partial line, if any.
+func (w *LineWriter) Flush() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if len(w.buf) > 0 {
+		w.emit(w.buf)
+		w.buf = nil
+	}
+	return nil
+}
+
+// Close logs the trailing partial line, if any.
+func (w *LineWriter) Close() error {
+	return w.Flush()
+}
+
+// emit logs a line. It must be called with the mutex held.
+func (w *LineWriter) emit(line []byte) {
+	line = bytes.TrimSuffix(line, []byte("\r"))
+	if len(bytes.TrimSpace(line)) == 0 {
+		return
+	}
+	level, msg := w.level, string(line)
+	if w.parseLevel {
+		level, msg = parseStdLevel(msg, level)
+	}
+	ctx := context.Background()
+	if !w.handler.Enabled(ctx, level) {
+		return
+	}
+	r := slog.NewRecord(time.Now(), level, msg, 0)
+	r.AddAttrs(w.attrs...)
+	_ = w.handler.Handle(ctx, r)
+}
@@ -0,0 +1,112 @@
+package log
+
+import (
+	"bytes"
+	"log/slog"
+	"os/exec"
+	"runtime"
+	"testing"
+)
+
+func TestLogger_Writer(t *testing.T) {
+	tests := []struct {
+		name   string
+		level  slog.Level
+		opts   []WriterOption
+		writes []string
+		flush  bool
+		want   string
+	}{
+		{
+			name:   "lines",
+			level:  slog.LevelInfo,
+			writes: []string{"a\nb\n"},
+			want:   "[INF] a\n[INF] b\n",
+		},
+		{
+			name:   "partial lines",
+			level:  slog.LevelWarn,
+			writes: []string{"hel", "lo\nwor", "ld\r\n"},
+			want:   "[WRN] hello\n[WRN] world\n",
+		},
+		{
+			name:   "trailing line kept",
+			level:  slog.LevelInfo,
+			writes: []string{"a\nb"},
+			want:   "[INF] a\n",
+		},
+		{
+			name:   "trailing line flushed",
+			level:  slog.LevelInfo,
+			writes: []string{"a\nb"},
+			flush:  true,
+			want:   "[INF] a\n[INF] b\n",
+		},
+		{
+			name:   "empty lines skipped",
+			level:  slog.LevelInfo,
+			writes: []string{"\n \n\na\n"},
+			want:   "[INF] a\n",
+		},
+		{
+			name:   "attrs",
+			level:  slog.LevelInfo,
+			opts:   []WriterOption{WithWriterAttrs(slog.String("stream", "stdout"))},
+			writes: []string{"a\n"},
+			want:   "[INF] a stream=stdout\n",
+		},
+		{
+			name:   "parse level",
+			level:  slog.LevelInfo,
+			opts:   []WriterOption{WithParseLevel(true)},
+			writes: []string{"[ERROR] bad\nDEBUG: hidden\nplain\n"},
+			want:   "[ERR] bad\n[INF] plain\n",
+		},
+		{
+			name:   "max line size",
+			level:  slog.LevelInfo,
+			opts:   []WriterOption{WithMaxLineSize(3)},
+			writes: []string{"abcdefg"},
+			want:   "[INF] abc\n[INF] def\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			l := NewLogger(NewCLIHandler(buf, WithStyle(Style0())))
+			w := l.Writer(tt.level, tt.opts...)
+			for _, s := range tt.writes {
+				n, err := w.Write([]byte(s))
+				if err != nil || n != len(s) {
+					t.Fatalf("Write() = %d, %v, want %d, nil", n, err, len(s))
+				}
+			}
+			if tt.flush {
+				if err := w.Close(); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLogger_Writer_exec(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires sh")
+	}
+	buf := &bytes.Buffer{}
+	l := NewLogger(NewCLIHandler(buf, WithStyle(Style0())))
+	stdout := l.Writer(slog.LevelInfo)
+	cmd := exec.Command("sh", "-c", "printf 'one\\ntwo'")
+	cmd.Stdout = stdout
+	if err := cmd.Run(); err != nil {
+		t.Skip(err)
+	}
+	stdout.Close()
+	if got, want := buf.String(), "[INF] one\n[INF] two\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
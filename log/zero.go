@@ -0,0 +1,60 @@
+package log
+
+import "time"
+
+// ZeroTimePolicy selects how CLIHandler handles records with a zero time.
+type ZeroTimePolicy int
+
+const (
+	// ZeroTimeSkip writes no time for records with a zero time, as slog
+	// handlers do.
+	ZeroTimeSkip ZeroTimePolicy = iota
+
+	// ZeroTimeNow writes the time the record is handled for records with a
+	// zero time, e.g. those built by libraries with slog.NewRecord(time.Time{}, ...).
+	ZeroTimeNow
+)
+
+// WithZeroTime returns a CLIHandlerOption that sets how records with a zero
+// time are handled. The default is ZeroTimeSkip. FormatJSON ignores it.
+func WithZeroTime(policy ZeroTimePolicy) CLIHandlerOption {
+	return func(c *CLIHandler) {
+		c.zeroTime = policy
+	}
+}
+
+// WithEmptyMessage returns a CLIHandlerOption that sets the text written in place
+// of empty messages, e.g. "-", so that records of libraries logging only
+// attributes are not mistaken for a message made of their first attribute. The
+// default is "", which writes nothing, as slog handlers do. The text is written
+// if the message is still empty after ReplaceAttr, but not if the message is
+// removed by it. FormatJSON ignores it.
+func WithEmptyMessage(placeholder string) CLIHandlerOption {
+	return func(c *CLIHandler) {
+		c.emptyMsg = placeholder
+	}
+}
+
+// stampTime returns the record time t replaced by the time of the clock set by
+// WithClock. A zero t is kept unless ZeroTimeNow is set, in which case the time
+// of the clock, or the current time, is taken.
+func (h *CLIHandler) stampTime(t time.Time) time.Time {
+	if t.IsZero() && h.zeroTime != ZeroTimeNow {
+		return t
+	}
+	if h.clock != nil {
+		return h.clock()
+	}
+	if t.IsZero() {
+		return time.Now()
+	}
+	return t
+}
+
+// message returns the message to write in place of msg.
+func (h *CLIHandler) message(msg string) string {
+	if msg == "" {
+		return h.emptyMsg
+	}
+	return msg
+}
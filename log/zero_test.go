@@ -0,0 +1,92 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"regexp"
+	"testing"
+	"time"
+)
+
+func TestWithZeroTime(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy ZeroTimePolicy
+		want   *regexp.Regexp
+	}{
+		{name: "skip", policy: ZeroTimeSkip, want: regexp.MustCompile(`^\[INF\] msg\n$`)},
+		{name: "now", policy: ZeroTimeNow, want: regexp.MustCompile(`^\[INF\] msg time=\d{4}-\d\d-\d\dT\S+\n$`)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			h := NewCLIHandler(buf, WithStyle(Style0()), WithTime(true), WithZeroTime(tt.policy))
+			if err := h.Handle(context.Background(), slog.NewRecord(time.Time{}, slog.LevelInfo, "msg", 0)); err != nil {
+				t.Fatal(err)
+			}
+			if got := buf.String(); !tt.want.MatchString(got) {
+				t.Errorf("got %q, want match of %s", got, tt.want)
+			}
+		})
+	}
+
+	buf := &bytes.Buffer{}
+	h := NewLogfmtHandler(buf, WithZeroTime(ZeroTimeNow), WithClock(ClockFunc(func() time.Time {
+		return time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	})))
+	if err := h.Handle(context.Background(), slog.NewRecord(time.Time{}, slog.LevelInfo, "msg", 0)); err != nil {
+		t.Fatal(err)
+	}
+	if want := "time=2025-01-02T03:04:05Z level=INFO msg=msg\n"; buf.String() != want {
+		t.Errorf("got %q, want %q", buf.String(), want)
+	}
+}
+
+func TestWithEmptyMessage(t *testing.T) {
+	dropTime := func(groups []string, a slog.Attr) slog.Attr {
+		if len(groups) == 0 && a.Key == slog.TimeKey {
+			return slog.Attr{}
+		}
+		return a
+	}
+	tests := []struct {
+		name   string
+		opts   []CLIHandlerOption
+		logfmt bool
+		want   string
+	}{
+		{name: "default", want: "[INF] k=v\n"},
+		{name: "placeholder", opts: []CLIHandlerOption{WithEmptyMessage("-")}, want: "[INF] - k=v\n"},
+		{name: "logfmt", opts: []CLIHandlerOption{WithEmptyMessage("-"), WithReplaceAttr(dropTime)}, logfmt: true, want: "level=INFO msg=- k=v\n"},
+		{
+			name: "message removed",
+			opts: []CLIHandlerOption{WithEmptyMessage("-"), WithReplaceAttr(func(groups []string, a slog.Attr) slog.Attr {
+				if len(groups) == 0 && a.Key == slog.MessageKey {
+					return slog.Attr{}
+				}
+				return a
+			})},
+			want: "[INF] k=v\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			opts := append([]CLIHandlerOption{WithStyle(Style0())}, tt.opts...)
+			newHandler := NewCLIHandler
+			if tt.logfmt {
+				newHandler = NewLogfmtHandler
+			}
+			NewLogger(newHandler(buf, opts...)).Info("", "k", "v")
+			if got := buf.String(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+			buf.Reset()
+			NewLogger(newHandler(buf, opts...)).Info("msg")
+			if buf.Len() == 0 || bytes.Contains(buf.Bytes(), []byte("-")) {
+				t.Errorf("got %q for a message", buf.String())
+			}
+		})
+	}
+}